@@ -32,6 +32,12 @@ const (
 	ConditionActive ConditionType = "Active"
 	// ConditionFallback specifies that the resource has a fallback active.
 	ConditionFallback ConditionType = "Fallback"
+	// ConditionCapped specifies that the spec of the resource is clamped to the caps of the scaling defaults.
+	// It is only added to the resources once they are capped.
+	ConditionCapped ConditionType = "Capped"
+	// ConditionCircuitBreakerOpen specifies that the creation of new jobs of the ScaledJob is paused by the failure circuit breaker.
+	// It is only added to the ScaledJobs with a scalingStrategy.failureCircuitBreaker.
+	ConditionCircuitBreakerOpen ConditionType = "CircuitBreakerOpen"
 )
 
 const (
@@ -39,6 +45,28 @@ const (
 	ScaledObjectConditionReadySucccesReason = "ScaledObjectReady"
 	// ScaledObjectConditionReadySuccessMessage defines the default Message for correct ScaledObject
 	ScaledObjectConditionReadySuccessMessage = "ScaledObject is defined correctly and is ready for scaling"
+	// ScaledObjectConditionReadyPartialReason defines the Reason for a ScaledObject scaled on part of its triggers
+	ScaledObjectConditionReadyPartialReason = "ScaledObjectReadyWithFailedTriggers"
+)
+
+const (
+	// TriggerAuthenticationConditionReadySuccessReason defines the Reason for a trigger authentication referencing existing secrets
+	TriggerAuthenticationConditionReadySuccessReason = "TriggerAuthenticationReady"
+	// TriggerAuthenticationConditionReadySuccessMessage defines the Message for a trigger authentication referencing existing secrets
+	TriggerAuthenticationConditionReadySuccessMessage = "Referenced secrets exist"
+	// TriggerAuthenticationConditionSecretsNotFoundReason defines the Reason for a trigger authentication referencing missing secrets
+	TriggerAuthenticationConditionSecretsNotFoundReason = "SecretsNotFound"
+)
+
+const (
+	// ScaledJobConditionCircuitBreakerOpenReason defines the Reason for a ScaledJob with too many failed jobs within the window
+	ScaledJobConditionCircuitBreakerOpenReason = "FailureThresholdExceeded"
+	// ScaledJobConditionCircuitBreakerCooldownElapsedReason defines the Reason for a ScaledJob creating jobs again after the cooldown
+	ScaledJobConditionCircuitBreakerCooldownElapsedReason = "CooldownElapsed"
+	// ScaledJobConditionCircuitBreakerJobSucceededReason defines the Reason for a ScaledJob creating jobs again after a job succeeded
+	ScaledJobConditionCircuitBreakerJobSucceededReason = "JobSucceeded"
+	// ScaledJobConditionCircuitBreakerDisabledReason defines the Reason for a ScaledJob whose failure circuit breaker was removed
+	ScaledJobConditionCircuitBreakerDisabledReason = "FailureCircuitBreakerDisabled"
 )
 
 // Condition to store the condition state
@@ -147,6 +175,22 @@ func (c *Conditions) SetFallbackCondition(status metav1.ConditionStatus, reason
 	c.setCondition(ConditionFallback, status, reason, message)
 }
 
+// SetCappedCondition modifies Capped Condition according to input parameters, the condition is added if it's missing
+func (c *Conditions) SetCappedCondition(status metav1.ConditionStatus, reason string, message string) {
+	if c.getCondition(ConditionCapped).Type == "" {
+		*c = append(*c, Condition{Type: ConditionCapped})
+	}
+	c.setCondition(ConditionCapped, status, reason, message)
+}
+
+// SetCircuitBreakerOpenCondition modifies CircuitBreakerOpen Condition according to input parameters, the condition is added if it's missing
+func (c *Conditions) SetCircuitBreakerOpenCondition(status metav1.ConditionStatus, reason string, message string) {
+	if c.getCondition(ConditionCircuitBreakerOpen).Type == "" {
+		*c = append(*c, Condition{Type: ConditionCircuitBreakerOpen})
+	}
+	c.setCondition(ConditionCircuitBreakerOpen, status, reason, message)
+}
+
 // GetActiveCondition returns Condition of type Active
 func (c *Conditions) GetActiveCondition() Condition {
 	if *c == nil {
@@ -171,6 +215,22 @@ func (c *Conditions) GetFallbackCondition() Condition {
 	return c.getCondition(ConditionFallback)
 }
 
+// GetCappedCondition returns Condition of type Capped, it has no type when the resource was never capped
+func (c *Conditions) GetCappedCondition() Condition {
+	if *c == nil {
+		return Condition{}
+	}
+	return c.getCondition(ConditionCapped)
+}
+
+// GetCircuitBreakerOpenCondition returns Condition of type CircuitBreakerOpen, it has no type when the ScaledJob never had a failure circuit breaker
+func (c *Conditions) GetCircuitBreakerOpenCondition() Condition {
+	if *c == nil {
+		return Condition{}
+	}
+	return c.getCondition(ConditionCircuitBreakerOpen)
+}
+
 func (c Conditions) getCondition(conditionType ConditionType) Condition {
 	for i := range c {
 		if c[i].Type == conditionType {
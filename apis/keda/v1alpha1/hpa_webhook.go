@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var hpaownershiplog = logf.Log.WithName("hpa-ownership-validation-webhook")
+
+const hpaOwnershipWebhookPath = "/validate-autoscaling-v2-horizontalpodautoscaler"
+
+// HPAOwnershipValidator rejects changes to the HPAs generated for ScaledObjects which aren't made by the KEDA operator,
+// KEDA would revert them anyway
+type HPAOwnershipValidator struct {
+	// operatorUsernames are the users allowed to change the HPAs, i.e. the service account of the operator
+	operatorUsernames []string
+	decoder           *admission.Decoder
+}
+
+// NewHPAOwnershipValidator creates a validator allowing only the given users to change the HPAs generated for ScaledObjects
+func NewHPAOwnershipValidator(scheme *runtime.Scheme, operatorUsernames []string) *HPAOwnershipValidator {
+	return &HPAOwnershipValidator{
+		operatorUsernames: operatorUsernames,
+		decoder:           admission.NewDecoder(scheme),
+	}
+}
+
+// SetupHPAOwnershipWebhookWithManager registers the HPA ownership webhook in the webhook server of the manager
+func SetupHPAOwnershipWebhookWithManager(mgr ctrl.Manager, operatorUsernames []string) {
+	mgr.GetWebhookServer().Register(hpaOwnershipWebhookPath, &webhook.Admission{Handler: NewHPAOwnershipValidator(mgr.GetScheme(), operatorUsernames)})
+}
+
+// +kubebuilder:webhook:path=/validate-autoscaling-v2-horizontalpodautoscaler,mutating=false,failurePolicy=ignore,sideEffects=None,groups=autoscaling,resources=horizontalpodautoscalers,verbs=update,versions=v2,name=vhpaownership.kb.io,admissionReviewVersions=v1
+
+// Handle rejects the update of an HPA owned by a ScaledObject if it changes the spec or removes the ownership label,
+// other metadata changes, e.g. by the garbage collector, are allowed
+func (v *HPAOwnershipValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	if err := v.decoder.Decode(req, hpa); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	oldHpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	if err := v.decoder.DecodeRaw(req.OldObject, oldHpa); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if oldHpa.Labels[HPAOwnershipLabel] != "true" {
+		return admission.Allowed("")
+	}
+	for _, username := range v.operatorUsernames {
+		if req.UserInfo.Username == username {
+			return admission.Allowed("")
+		}
+	}
+
+	changedFields := getHPAChangedFields(oldHpa, hpa)
+	if len(changedFields) == 0 {
+		return admission.Allowed("")
+	}
+
+	scaledObjectName := oldHpa.Name
+	for _, owner := range oldHpa.OwnerReferences {
+		if owner.Kind == "ScaledObject" {
+			scaledObjectName = owner.Name
+			break
+		}
+	}
+	err := fmt.Errorf("the HPA '%s' is managed by the ScaledObject '%s', change the ScaledObject instead of %s", hpa.Name, scaledObjectName, strings.Join(changedFields, ", "))
+	hpaownershiplog.Error(err, "validation error", "username", req.UserInfo.Username)
+	return admission.Denied(err.Error())
+}
+
+// getHPAChangedFields returns the fields managed by KEDA which are changed by the update of the HPA
+func getHPAChangedFields(oldHpa, hpa *autoscalingv2.HorizontalPodAutoscaler) []string {
+	var fields []string
+	if !equality.Semantic.DeepEqual(oldHpa.Spec.ScaleTargetRef, hpa.Spec.ScaleTargetRef) {
+		fields = append(fields, "spec.scaleTargetRef")
+	}
+	if !equality.Semantic.DeepEqual(oldHpa.Spec.MinReplicas, hpa.Spec.MinReplicas) {
+		fields = append(fields, "spec.minReplicas")
+	}
+	if oldHpa.Spec.MaxReplicas != hpa.Spec.MaxReplicas {
+		fields = append(fields, "spec.maxReplicas")
+	}
+	if !equality.Semantic.DeepEqual(oldHpa.Spec.Metrics, hpa.Spec.Metrics) {
+		fields = append(fields, "spec.metrics")
+	}
+	if !equality.Semantic.DeepEqual(oldHpa.Spec.Behavior, hpa.Spec.Behavior) {
+		fields = append(fields, "spec.behavior")
+	}
+	if hpa.Labels[HPAOwnershipLabel] != "true" {
+		fields = append(fields, fmt.Sprintf("label %s", HPAOwnershipLabel))
+	}
+	return fields
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
+)
+
+const testOperatorUsername = "system:serviceaccount:keda:keda-operator"
+
+type hpaOwnershipTestData struct {
+	name     string
+	username string
+	owned    bool
+	update   func(hpa *autoscalingv2.HorizontalPodAutoscaler)
+	allowed  bool
+	// fields expected in the denial message
+	fields []string
+}
+
+var hpaOwnershipTestDataset = []hpaOwnershipTestData{
+	{
+		name:     "manual change of max replicas",
+		username: "sre",
+		owned:    true,
+		update:   func(hpa *autoscalingv2.HorizontalPodAutoscaler) { hpa.Spec.MaxReplicas = 50 },
+		fields:   []string{"spec.maxReplicas"},
+	},
+	{
+		name:     "manual change of min replicas and behavior",
+		username: "sre",
+		owned:    true,
+		update: func(hpa *autoscalingv2.HorizontalPodAutoscaler) {
+			minReplicas := int32(2)
+			hpa.Spec.MinReplicas = &minReplicas
+			hpa.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+		},
+		fields: []string{"spec.minReplicas", "spec.behavior"},
+	},
+	{
+		name:     "manual change of metrics",
+		username: "sre",
+		owned:    true,
+		update:   func(hpa *autoscalingv2.HorizontalPodAutoscaler) { hpa.Spec.Metrics = nil },
+		fields:   []string{"spec.metrics"},
+	},
+	{
+		name:     "removal of the ownership label",
+		username: "sre",
+		owned:    true,
+		update:   func(hpa *autoscalingv2.HorizontalPodAutoscaler) { delete(hpa.Labels, HPAOwnershipLabel) },
+		fields:   []string{HPAOwnershipLabel},
+	},
+	{
+		name:     "change by the operator",
+		username: testOperatorUsername,
+		owned:    true,
+		update:   func(hpa *autoscalingv2.HorizontalPodAutoscaler) { hpa.Spec.MaxReplicas = 50 },
+		allowed:  true,
+	},
+	{
+		name:     "metadata change",
+		username: "system:serviceaccount:kube-system:generic-garbage-collector",
+		owned:    true,
+		update:   func(hpa *autoscalingv2.HorizontalPodAutoscaler) { hpa.Finalizers = nil },
+		allowed:  true,
+	},
+	{
+		name:     "change of an HPA not owned by a ScaledObject",
+		username: "sre",
+		update:   func(hpa *autoscalingv2.HorizontalPodAutoscaler) { hpa.Spec.MaxReplicas = 50 },
+		allowed:  true,
+	},
+}
+
+func TestHPAOwnershipValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	validator := NewHPAOwnershipValidator(scheme, []string{testOperatorUsername})
+
+	for _, testData := range hpaOwnershipTestDataset {
+		t.Run(testData.name, func(t *testing.T) {
+			minReplicas := int32(1)
+			oldHpa := &autoscalingv2.HorizontalPodAutoscaler{
+				TypeMeta: metav1.TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "keda-hpa-so",
+					Namespace:  "default",
+					Finalizers: []string{"foregroundDeletion"},
+				},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Name: "deployment", Kind: "Deployment", APIVersion: "apps/v1"},
+					MinReplicas:    &minReplicas,
+					MaxReplicas:    10,
+					Metrics:        []autoscalingv2.MetricSpec{{Type: autoscalingv2.ExternalMetricSourceType, External: &autoscalingv2.ExternalMetricSource{Metric: autoscalingv2.MetricIdentifier{Name: "s0-metric"}}}},
+				},
+			}
+			if testData.owned {
+				oldHpa.Labels = map[string]string{HPAOwnershipLabel: "true"}
+				oldHpa.OwnerReferences = []metav1.OwnerReference{{APIVersion: "keda.sh/v1alpha1", Kind: "ScaledObject", Name: "so"}}
+			}
+			hpa := oldHpa.DeepCopy()
+			testData.update(hpa)
+
+			response := validator.Handle(context.Background(), newHPAUpdateRequest(t, oldHpa, hpa, testData.username))
+			if response.Allowed != testData.allowed {
+				t.Fatalf("expected allowed %v but got %v: %s", testData.allowed, response.Allowed, response.Result.Message)
+			}
+			for _, field := range testData.fields {
+				if !strings.Contains(response.Result.Message, field) {
+					t.Errorf("expected %s in the message %q", field, response.Result.Message)
+				}
+			}
+			if !testData.allowed && !strings.Contains(response.Result.Message, "ScaledObject 'so'") {
+				t.Errorf("expected the ScaledObject in the message %q", response.Result.Message)
+			}
+		})
+	}
+}
+
+// the webhook is only called by the API server if it's registered in the ValidatingWebhookConfiguration
+func TestHPAOwnershipWebhookManifest(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "..", "config", "webhooks", "validation_webhooks.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration := admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := yaml.Unmarshal(content, &configuration); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, webhook := range configuration.Webhooks {
+		if webhook.Name != "vhpaownership.kb.io" {
+			continue
+		}
+		if webhook.ClientConfig.Service == nil || webhook.ClientConfig.Service.Path == nil || *webhook.ClientConfig.Service.Path != hpaOwnershipWebhookPath {
+			t.Errorf("expected the webhook to call %s, got %+v", hpaOwnershipWebhookPath, webhook.ClientConfig.Service)
+		}
+		expectedRules := []admissionregistrationv1.RuleWithOperations{{
+			Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Update},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{"autoscaling"},
+				APIVersions: []string{"v2"},
+				Resources:   []string{"horizontalpodautoscalers"},
+			},
+		}}
+		if !equality.Semantic.DeepEqual(webhook.Rules, expectedRules) {
+			t.Errorf("expected the rules %+v, got %+v", expectedRules, webhook.Rules)
+		}
+		return
+	}
+	t.Error("vhpaownership.kb.io isn't registered in the ValidatingWebhookConfiguration")
+}
+
+func newHPAUpdateRequest(t *testing.T, oldHpa, hpa *autoscalingv2.HorizontalPodAutoscaler, username string) admission.Request {
+	oldRaw, err := json.Marshal(oldHpa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(hpa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Update,
+		UserInfo:  authenticationv1.UserInfo{Username: username},
+		Object:    runtime.RawExtension{Raw: raw},
+		OldObject: runtime.RawExtension{Raw: oldRaw},
+	}}
+}
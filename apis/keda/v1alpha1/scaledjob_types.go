@@ -17,13 +17,19 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
-	defaultScaledJobMaxReplicaCount = 100
 	defaultScaledJobMinReplicaCount = 0
+
+	// jobNameRandomSuffixLength is the length of the random suffix the API server adds to generated names
+	jobNameRandomSuffixLength = 5
 )
 
 // +genclient
@@ -51,6 +57,12 @@ type ScaledJob struct {
 type ScaledJobSpec struct {
 	JobTargetRef *batchv1.JobSpec `json:"jobTargetRef"`
 	// +optional
+	JobNamePrefix string `json:"jobNamePrefix,omitempty"`
+	// +optional
+	JobLabels map[string]string `json:"jobLabels,omitempty"`
+	// +optional
+	JobAnnotations map[string]string `json:"jobAnnotations,omitempty"`
+	// +optional
 	PollingInterval *int32 `json:"pollingInterval,omitempty"`
 	// +optional
 	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
@@ -101,6 +113,24 @@ type ScalingStrategy struct {
 	PendingPodConditions []string `json:"pendingPodConditions,omitempty"`
 	// +optional
 	MultipleScalersCalculation string `json:"multipleScalersCalculation,omitempty"`
+	// FailureCircuitBreaker pauses the creation of new jobs while the jobs of the ScaledJob keep failing
+	// +optional
+	FailureCircuitBreaker *FailureCircuitBreaker `json:"failureCircuitBreaker,omitempty"`
+}
+
+// FailureCircuitBreaker defines when the creation of new jobs is paused and resumed. A job counts as failed once it
+// has the Failed condition, i.e. after its backoffLimit is exhausted, the failed pods of a job still retried don't count
+type FailureCircuitBreaker struct {
+	// FailureThreshold is the number of jobs failed within the window the failures must exceed to open the breaker
+	// +kubebuilder:validation:Minimum=0
+	FailureThreshold int32 `json:"failureThreshold"`
+	// Window is the period the failed jobs are counted over, 10 minutes by default
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+	// Cooldown is how long no new job is created once the breaker opened, 5 minutes by default. The breaker closes
+	// earlier when a job succeeds
+	// +optional
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
 }
 
 // Rollout defines the strategy for job rollouts
@@ -125,7 +155,7 @@ func (s ScaledJob) MaxReplicaCount() int64 {
 		return int64(*s.Spec.MaxReplicaCount) - s.MinReplicaCount()
 	}
 
-	return defaultScaledJobMaxReplicaCount
+	return DefaultMaxReplicaCount
 }
 
 // MinReplicaCount returns MinReplicaCount
@@ -139,3 +169,29 @@ func (s ScaledJob) MinReplicaCount() int64 {
 	}
 	return defaultScaledJobMinReplicaCount
 }
+
+// JobGenerateName returns the prefix of the names of the Jobs created for the ScaledJob,
+// the API server completes it with a random suffix
+func (s ScaledJob) JobGenerateName() string {
+	prefix := s.Spec.JobNamePrefix
+	if prefix == "" {
+		prefix = s.Name
+	}
+	if !strings.HasSuffix(prefix, "-") {
+		prefix += "-"
+	}
+	return prefix
+}
+
+// ValidateJobNamePrefix checks that the Jobs generated with JobNamePrefix get valid names,
+// Job names are used as label values by the Job controller so they have to be DNS-1123 labels
+func (s ScaledJob) ValidateJobNamePrefix() error {
+	if s.Spec.JobNamePrefix == "" {
+		return nil
+	}
+	name := s.JobGenerateName() + strings.Repeat("x", jobNameRandomSuffixLength)
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("jobNamePrefix %q is not valid: %s", s.Spec.JobNamePrefix, strings.Join(errs, ", "))
+	}
+	return nil
+}
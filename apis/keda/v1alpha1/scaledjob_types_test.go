@@ -17,7 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strings"
 	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestScaledJob(t *testing.T) {
@@ -51,14 +54,14 @@ func TestScaledJob(t *testing.T) {
 		},
 		{
 			name:        "MaxReplicaCount is nil and MinReplicaCount is set to 1",
-			expectedMax: defaultScaledJobMaxReplicaCount,
+			expectedMax: DefaultMaxReplicaCount,
 			expectedMin: 1,
 			maxReplicas: nil,
 			minReplicas: int32Ptr(1),
 		},
 		{
 			name:        "MaxReplicaCount is nil and MinReplicaCount nil",
-			expectedMax: defaultScaledJobMaxReplicaCount,
+			expectedMax: DefaultMaxReplicaCount,
 			expectedMin: defaultScaledJobMinReplicaCount,
 			maxReplicas: nil,
 			minReplicas: nil,
@@ -96,3 +99,41 @@ func TestScaledJob(t *testing.T) {
 func int32Ptr(i int32) *int32 {
 	return &i
 }
+
+func TestScaledJobValidateJobNamePrefix(t *testing.T) {
+	tests := []struct {
+		prefix               string
+		expectedGenerateName string
+		isError              bool
+	}{
+		{prefix: "", expectedGenerateName: "consumer-"},
+		{prefix: "worker", expectedGenerateName: "worker-"},
+		{prefix: "worker-", expectedGenerateName: "worker-"},
+		{prefix: "queue-worker-1", expectedGenerateName: "queue-worker-1-"},
+		{prefix: "Worker", isError: true},
+		{prefix: "-worker", isError: true},
+		{prefix: "queue.worker", isError: true},
+		{prefix: strings.Repeat("w", 57), expectedGenerateName: strings.Repeat("w", 57) + "-"},
+		{prefix: strings.Repeat("w", 58), isError: true},
+	}
+
+	for _, test := range tests {
+		scaledJob := ScaledJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "consumer"},
+			Spec:       ScaledJobSpec{JobNamePrefix: test.prefix},
+		}
+		err := scaledJob.ValidateJobNamePrefix()
+		if test.isError {
+			if err == nil {
+				t.Errorf("prefix %q: expected error but got success", test.prefix)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("prefix %q: expected success but got error %s", test.prefix, err)
+		}
+		if generateName := scaledJob.JobGenerateName(); generateName != test.expectedGenerateName {
+			t.Errorf("prefix %q: expected generate name %q but got %q", test.prefix, test.expectedGenerateName, generateName)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -48,12 +49,31 @@ type ScaledObject struct {
 
 const ScaledObjectOwnerAnnotation = "scaledobject.keda.sh/name"
 
+// HPAOwnershipLabel is set to "true" on the HPAs generated for ScaledObjects, the spec of these HPAs is managed by KEDA
+const HPAOwnershipLabel = "validations.keda.sh/hpa-ownership"
+
 // HealthStatus is the status for a ScaledObject's health
 type HealthStatus struct {
 	// +optional
 	NumberOfFailures *int32 `json:"numberOfFailures,omitempty"`
 	// +optional
 	Status HealthStatusType `json:"status,omitempty"`
+	// ErrorType is the dominant type of the errors since the last successful sample (auth, timeout, backend, config or unknown)
+	// +optional
+	ErrorType string `json:"errorType,omitempty"`
+	// ErrorTypes counts the failures by error type since the last successful sample
+	// +optional
+	ErrorTypes map[string]int32 `json:"errorTypes,omitempty"`
+	// FallbackStartTime is when the trigger started falling back
+	// +optional
+	FallbackStartTime *metav1.Time `json:"fallbackStartTime,omitempty"`
+	// FallbackReplicas is the replica count the fallback of the trigger currently targets,
+	// decayed toward fallback.safeReplicaCount once the trigger falls back for longer than fallback.maxDuration
+	// +optional
+	FallbackReplicas *int32 `json:"fallbackReplicas,omitempty"`
+	// FallbackDecayRemaining is the time left until the fallback replicas reach fallback.safeReplicaCount
+	// +optional
+	FallbackDecayRemaining *metav1.Duration `json:"fallbackDecayRemaining,omitempty"`
 }
 
 // HealthStatusType is an indication of whether the health status is happy or failing
@@ -92,6 +112,17 @@ type ScaledObjectSpec struct {
 type Fallback struct {
 	FailureThreshold int32 `json:"failureThreshold"`
 	Replicas         int32 `json:"replicas"`
+	// MaxDuration is how long a trigger falls back to Replicas before they decay toward SafeReplicaCount,
+	// the replicas are held as long as the trigger fails if it isn't set
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+	// DecayDuration is how long the replicas take to decay linearly from Replicas to SafeReplicaCount,
+	// defaults to MaxDuration
+	// +optional
+	DecayDuration *metav1.Duration `json:"decayDuration,omitempty"`
+	// SafeReplicaCount is the replica count the fallback decays to, defaults to 0, the minimum replicas of the HPA
+	// +optional
+	SafeReplicaCount *int32 `json:"safeReplicaCount,omitempty"`
 }
 
 // AdvancedConfig specifies advance scaling options
@@ -100,6 +131,56 @@ type AdvancedConfig struct {
 	HorizontalPodAutoscalerConfig *HorizontalPodAutoscalerConfig `json:"horizontalPodAutoscalerConfig,omitempty"`
 	// +optional
 	RestoreToOriginalReplicaCount bool `json:"restoreToOriginalReplicaCount,omitempty"`
+	// +optional
+	ScaleDownTrendGuard *ScaleDownTrendGuard `json:"scaleDownTrendGuard,omitempty"`
+	// ActivationReplicaCount is the replica count the scale target is activated to from zero, bounded by
+	// maxReplicaCount and by the replica count needed by the metric values, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActivationReplicaCount *int32 `json:"activationReplicaCount,omitempty"`
+	// ActivationOnly scales the scale target between 0 and activationReplicaCount depending on the activity of the
+	// triggers, without creating an HPA, for the resources without replica semantics
+	// +optional
+	ActivationOnly bool `json:"activationOnly,omitempty"`
+	// CooldownPolicy is how the cooldownPeriod applies before scaling to zero, global from the last time any trigger
+	// was active or perTrigger from the last active time of each trigger in status.triggersLastActiveTime with the
+	// cooldownPeriod of the trigger, defaults to global
+	// +kubebuilder:validation:Enum=global;perTrigger
+	// +optional
+	CooldownPolicy CooldownPolicy `json:"cooldownPolicy,omitempty"`
+	// PauseDuringRollout holds the scale target at its replica count instead of scaling it to zero or activating it
+	// while its Deployment or StatefulSet is being rolled out, the HPA keeps scaling it
+	// +optional
+	PauseDuringRollout bool `json:"pauseDuringRollout,omitempty"`
+	// ActivationFailureGracePeriod is how long the scale target isn't scaled back to zero after its activation while
+	// none of its pods is ready, e.g. when the startup of the workload is longer than the cooldownPeriod, the pods
+	// are found with the selector of the scale subresource
+	// +optional
+	ActivationFailureGracePeriod *metav1.Duration `json:"activationFailureGracePeriod,omitempty"`
+}
+
+// CooldownPolicy is how the cooldownPeriod of a ScaledObject applies
+type CooldownPolicy string
+
+const (
+	// CooldownPolicyGlobal starts the cooldown when the ScaledObject was last active
+	CooldownPolicyGlobal CooldownPolicy = "global"
+
+	// CooldownPolicyPerTrigger ends the cooldown once every trigger has been inactive for its own cooldownPeriod,
+	// a trigger stops holding the replicas once its cooldown has passed. The triggers removed from the ScaledObject
+	// aren't considered
+	CooldownPolicyPerTrigger CooldownPolicy = "perTrigger"
+)
+
+// ScaleDownTrendGuard holds the replicas while the composite value of the ScaledObject, the highest ratio of its
+// metric values to their targets, is still rising over the last polls
+type ScaleDownTrendGuard struct {
+	// Window is the number of last polls used to compute the trend of the composite value
+	// +kubebuilder:validation:Minimum=2
+	Window int32 `json:"window"`
+	// MinSlope is the increase of the composite value per poll above which the trend is rising, defaults to 0
+	// +optional
+	MinSlope *resource.Quantity `json:"minSlope,omitempty"`
 }
 
 // HorizontalPodAutoscalerConfig specifies horizontal scale config
@@ -134,6 +215,11 @@ type ScaleTriggers struct {
 	AuthenticationRef *ScaledObjectAuthRef `json:"authenticationRef,omitempty"`
 	// +optional
 	MetricType autoscalingv2.MetricTargetType `json:"metricType,omitempty"`
+	// CooldownPeriod is the cooldown period of the trigger with the perTrigger cooldownPolicy of a ScaledObject,
+	// defaults to the cooldownPeriod of the ScaledObject
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -149,6 +235,10 @@ type ScaledObjectStatus struct {
 	OriginalReplicaCount *int32 `json:"originalReplicaCount,omitempty"`
 	// +optional
 	LastActiveTime *metav1.Time `json:"lastActiveTime,omitempty"`
+	// TriggersLastActiveTime is the last time each trigger was active by metric name, set when the trigger
+	// becomes active or inactive, only tracked with the perTrigger cooldownPolicy
+	// +optional
+	TriggersLastActiveTime map[string]metav1.Time `json:"triggersLastActiveTime,omitempty"`
 	// +optional
 	ExternalMetricNames []string `json:"externalMetricNames,omitempty"`
 	// +optional
@@ -161,6 +251,37 @@ type ScaledObjectStatus struct {
 	PausedReplicaCount *int32 `json:"pausedReplicaCount,omitempty"`
 	// +optional
 	HpaName string `json:"hpaName,omitempty"`
+	// RolloutPause is whether the scaling to and from zero is paused during a rollout of the scale target, only
+	// set with pauseDuringRollout
+	// +optional
+	RolloutPause *RolloutPauseStatus `json:"rolloutPause,omitempty"`
+	// DrivingTrigger is the trigger with the highest ratio of its value to its target among the metrics served
+	// to the HPA, the one the scaling currently follows
+	// +optional
+	DrivingTrigger *DrivingTriggerStatus `json:"drivingTrigger,omitempty"`
+}
+
+// DrivingTriggerStatus is the trigger whose metric drives the scaling of a ScaledObject
+type DrivingTriggerStatus struct {
+	// Type of the trigger
+	Type string `json:"type"`
+	// Name of the trigger, if it's named
+	// +optional
+	Name string `json:"name,omitempty"`
+	// MetricName is the external metric of the trigger served to the HPA
+	MetricName string `json:"metricName"`
+	// LastTransitionTime is the last time another trigger started driving the scaling
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// RolloutPauseStatus is the state of the pause of a ScaledObject during the rollouts of its scale target
+type RolloutPauseStatus struct {
+	// Active is true while the scale target is being rolled out
+	Active bool `json:"active"`
+	// LastTransitionTime is the last time the pause was activated or deactivated
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -189,3 +310,21 @@ func init() {
 func (so *ScaledObject) GenerateIdentifier() string {
 	return GenerateIdentifier("ScaledObject", so.Namespace, so.Name)
 }
+
+// IsPausedDuringRollout returns true if the scaling to and from zero is paused during the rollouts of the scale target
+func (so *ScaledObject) IsPausedDuringRollout() bool {
+	return so.Spec.Advanced != nil && so.Spec.Advanced.PauseDuringRollout
+}
+
+// IsActivationOnly returns true if the scale target is only activated and deactivated, without an HPA
+func (so *ScaledObject) IsActivationOnly() bool {
+	return so.Spec.Advanced != nil && so.Spec.Advanced.ActivationOnly
+}
+
+// GetMaxReplicaCount returns the maxReplicaCount of the ScaledObject, or the default one if it isn't defined
+func (so *ScaledObject) GetMaxReplicaCount() int32 {
+	if so.Spec.MaxReplicaCount != nil {
+		return *so.Spec.MaxReplicaCount
+	}
+	return DefaultMaxReplicaCount
+}
@@ -106,7 +106,25 @@ func validateWorkload(so *ScaledObject, action string) (admission.Warnings, erro
 	}
 
 	scaledobjectlog.V(1).Info(fmt.Sprintf("scaledobject %s is valid", so.Name))
-	return nil, nil
+	return getActivationOnlyWarnings(so), nil
+}
+
+// getActivationOnlyWarnings warns about the triggers of an activation only ScaledObject which only make sense with
+// an HPA, they are still valid but only their activity is used
+func getActivationOnlyWarnings(so *ScaledObject) admission.Warnings {
+	if !so.IsActivationOnly() {
+		return nil
+	}
+	var warnings admission.Warnings
+	for i, trigger := range so.Spec.Triggers {
+		switch {
+		case trigger.Type == cpuString || trigger.Type == memoryString:
+			warnings = append(warnings, fmt.Sprintf("trigger %d of type %s is only used by the HPA, which isn't created with advanced.activationOnly", i, trigger.Type))
+		case trigger.MetricType != "":
+			warnings = append(warnings, fmt.Sprintf("metricType %s of trigger %d is ignored with advanced.activationOnly, only the activity of the trigger is used", trigger.MetricType, i))
+		}
+	}
+	return warnings
 }
 
 func verifyHpas(incomingSo *ScaledObject, action string) error {
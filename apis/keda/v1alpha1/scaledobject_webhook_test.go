@@ -732,3 +732,21 @@ func createScaledObjectSTZ(name string, namespace string, targetName string, min
 		},
 	}
 }
+
+func TestActivationOnlyWarnings(t *testing.T) {
+	g := NewWithT(t)
+
+	so := createScaledObject(soName, "default", workloadName, "apps/v1", "Deployment", false)
+	so.Spec.Triggers = []ScaleTriggers{
+		{Type: "cpu", Metadata: map[string]string{"value": "50"}},
+		{Type: "kafka", MetricType: v2.ValueMetricType},
+		{Type: "cron"},
+	}
+	g.Expect(getActivationOnlyWarnings(so)).To(BeEmpty())
+
+	so.Spec.Advanced = &AdvancedConfig{ActivationOnly: true}
+	warnings := getActivationOnlyWarnings(so)
+	g.Expect(warnings).To(HaveLen(2))
+	g.Expect(warnings[0]).To(ContainSubstring("trigger 0 of type cpu"))
+	g.Expect(warnings[1]).To(ContainSubstring("metricType Value of trigger 1"))
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"sort"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,15 +29,19 @@ import (
 // +genclient
 // +genclient:nonNamespaced
 // +kubebuilder:resource:path=clustertriggerauthentications,scope=Cluster,shortName=cta;clustertriggerauth
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="PodIdentity",type="string",JSONPath=".spec.podIdentity.provider"
 // +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".spec.secretTargetRef[*].name"
 // +kubebuilder:printcolumn:name="Env",type="string",JSONPath=".spec.env[*].name"
 // +kubebuilder:printcolumn:name="VaultAddress",type="string",JSONPath=".spec.hashiCorpVault.address"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 type ClusterTriggerAuthentication struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec TriggerAuthenticationSpec `json:"spec"`
+	// +optional
+	Status TriggerAuthenticationStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -51,15 +58,19 @@ type ClusterTriggerAuthenticationList struct {
 // TriggerAuthentication defines how a trigger can authenticate
 // +genclient
 // +kubebuilder:resource:path=triggerauthentications,scope=Namespaced,shortName=ta;triggerauth
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="PodIdentity",type="string",JSONPath=".spec.podIdentity.provider"
 // +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".spec.secretTargetRef[*].name"
 // +kubebuilder:printcolumn:name="Env",type="string",JSONPath=".spec.env[*].name"
 // +kubebuilder:printcolumn:name="VaultAddress",type="string",JSONPath=".spec.hashiCorpVault.address"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 type TriggerAuthentication struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec TriggerAuthenticationSpec `json:"spec"`
+	// +optional
+	Status TriggerAuthenticationStatus `json:"status,omitempty"`
 }
 
 // TriggerAuthenticationSpec defines the various ways to authenticate
@@ -80,6 +91,82 @@ type TriggerAuthenticationSpec struct {
 	AzureKeyVault *AzureKeyVault `json:"azureKeyVault,omitempty"`
 }
 
+// TriggerAuthenticationStatus defines the observed state of TriggerAuthentication and ClusterTriggerAuthentication,
+// it holds the names of the parameters but never their values
+type TriggerAuthenticationStatus struct {
+	// Providers are the ways of authenticating configured in the spec
+	// +optional
+	Providers []string `json:"providers,omitempty"`
+	// Parameters are the names of the parameters resolved by the providers, sorted
+	// +optional
+	Parameters []string `json:"parameters,omitempty"`
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
+}
+
+// Names of the providers in the status of TriggerAuthentication and ClusterTriggerAuthentication
+const (
+	TriggerAuthenticationProviderPodIdentity     = "podIdentity"
+	TriggerAuthenticationProviderSecretTargetRef = "secretTargetRef"
+	TriggerAuthenticationProviderEnv             = "env"
+	TriggerAuthenticationProviderHashiCorpVault  = "hashiCorpVault"
+	TriggerAuthenticationProviderAzureKeyVault   = "azureKeyVault"
+)
+
+// GenerateStatus returns the status of a trigger authentication with this spec, it is ready when none of the
+// referenced secrets is missing
+func (s *TriggerAuthenticationSpec) GenerateStatus(missingSecrets []string) TriggerAuthenticationStatus {
+	status := TriggerAuthenticationStatus{}
+	parameters := map[string]bool{}
+
+	if s.PodIdentity != nil && s.PodIdentity.Provider != "" && s.PodIdentity.Provider != PodIdentityProviderNone {
+		status.Providers = append(status.Providers, fmt.Sprintf("%s:%s", TriggerAuthenticationProviderPodIdentity, s.PodIdentity.Provider))
+	}
+	if len(s.SecretTargetRef) > 0 {
+		status.Providers = append(status.Providers, TriggerAuthenticationProviderSecretTargetRef)
+		for _, e := range s.SecretTargetRef {
+			parameters[e.Parameter] = true
+		}
+	}
+	if len(s.Env) > 0 {
+		status.Providers = append(status.Providers, TriggerAuthenticationProviderEnv)
+		for _, e := range s.Env {
+			parameters[e.Parameter] = true
+		}
+	}
+	if s.HashiCorpVault != nil {
+		status.Providers = append(status.Providers, TriggerAuthenticationProviderHashiCorpVault)
+		for _, e := range s.HashiCorpVault.Secrets {
+			parameters[e.Parameter] = true
+		}
+	}
+	if s.AzureKeyVault != nil {
+		status.Providers = append(status.Providers, TriggerAuthenticationProviderAzureKeyVault)
+		for _, e := range s.AzureKeyVault.Secrets {
+			parameters[e.Parameter] = true
+			if e.Extract == AzureKeyVaultExtractBoth && e.KeyParameter != "" {
+				parameters[e.KeyParameter] = true
+			}
+		}
+	}
+
+	for parameter := range parameters {
+		if parameter != "" {
+			status.Parameters = append(status.Parameters, parameter)
+		}
+	}
+	sort.Strings(status.Parameters)
+
+	if len(missingSecrets) > 0 {
+		status.Conditions = Conditions{{Type: ConditionReady, Status: metav1.ConditionFalse, Reason: TriggerAuthenticationConditionSecretsNotFoundReason,
+			Message: fmt.Sprintf("Referenced secrets not found: %q", missingSecrets)}}
+	} else {
+		status.Conditions = Conditions{{Type: ConditionReady, Status: metav1.ConditionTrue, Reason: TriggerAuthenticationConditionReadySuccessReason,
+			Message: TriggerAuthenticationConditionReadySuccessMessage}}
+	}
+	return status
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // TriggerAuthenticationList contains a list of TriggerAuthentication
@@ -102,6 +189,9 @@ const (
 	PodIdentityProviderSpiffe        PodIdentityProvider = "spiffe"
 	PodIdentityProviderAwsEKS        PodIdentityProvider = "aws-eks"
 	PodIdentityProviderAwsKiam       PodIdentityProvider = "aws-kiam"
+	// PodIdentityProviderAwsEKSPodIdentity gets the credentials of KEDA from EKS Pod Identity,
+	// the identityId is an optional role assumed with them
+	PodIdentityProviderAwsEKSPodIdentity PodIdentityProvider = "aws-eks-pod-identity"
 )
 
 // PodIdentityAnnotationEKS specifies aws role arn for aws-eks Identity Provider
@@ -217,8 +307,21 @@ type AzureKeyVaultSecret struct {
 	Name      string `json:"name"`
 	// +optional
 	Version string `json:"version,omitempty"`
+	// Extract the PEM encoded certificate, private key or both from a certificate secret (PKCS12 or PEM content type),
+	// the certificate is stored in Parameter and the private key in Parameter or KeyParameter when both are extracted
+	// +kubebuilder:validation:Enum=cert;key;both
+	// +optional
+	Extract string `json:"extract,omitempty"`
+	// +optional
+	KeyParameter string `json:"keyParameter,omitempty"`
 }
 
+const (
+	AzureKeyVaultExtractCert = "cert"
+	AzureKeyVaultExtractKey  = "key"
+	AzureKeyVaultExtractBoth = "both"
+)
+
 type AzureKeyVaultCloudInfo struct {
 	Type string `json:"type"`
 	// +optional
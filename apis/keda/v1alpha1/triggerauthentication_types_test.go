@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTriggerAuthenticationGenerateStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		spec               TriggerAuthenticationSpec
+		missingSecrets     []string
+		expectedProviders  []string
+		expectedParameters []string
+	}{
+		{
+			name:               "empty spec",
+			spec:               TriggerAuthenticationSpec{},
+			expectedProviders:  nil,
+			expectedParameters: nil,
+		},
+		{
+			name:               "pod identity",
+			spec:               TriggerAuthenticationSpec{PodIdentity: &AuthPodIdentity{Provider: PodIdentityProviderAzureWorkload, IdentityID: "id"}},
+			expectedProviders:  []string{"podIdentity:azure-workload"},
+			expectedParameters: nil,
+		},
+		{
+			name:               "pod identity none",
+			spec:               TriggerAuthenticationSpec{PodIdentity: &AuthPodIdentity{Provider: PodIdentityProviderNone}},
+			expectedProviders:  nil,
+			expectedParameters: nil,
+		},
+		{
+			name: "secret target ref",
+			spec: TriggerAuthenticationSpec{SecretTargetRef: []AuthSecretTargetRef{
+				{Parameter: "password", Name: "creds", Key: "password"},
+				{Parameter: "host", Name: "creds", Key: "host"},
+			}},
+			expectedProviders:  []string{"secretTargetRef"},
+			expectedParameters: []string{"host", "password"},
+		},
+		{
+			name:               "env",
+			spec:               TriggerAuthenticationSpec{Env: []AuthEnvironment{{Parameter: "connection", Name: "CONNECTION"}}},
+			expectedProviders:  []string{"env"},
+			expectedParameters: []string{"connection"},
+		},
+		{
+			name: "hashicorp vault",
+			spec: TriggerAuthenticationSpec{HashiCorpVault: &HashiCorpVault{
+				Address:        "http://vault:8200",
+				Authentication: VaultAuthenticationToken,
+				Secrets:        []VaultSecret{{Parameter: "token", Path: "secret/data/app", Key: "token"}},
+			}},
+			expectedProviders:  []string{"hashiCorpVault"},
+			expectedParameters: []string{"token"},
+		},
+		{
+			name: "azure key vault",
+			spec: TriggerAuthenticationSpec{AzureKeyVault: &AzureKeyVault{
+				VaultURI: "https://vault.vault.azure.net",
+				Secrets: []AzureKeyVaultSecret{
+					{Parameter: "connection", Name: "connection"},
+					{Parameter: "cert", Name: "tls", Extract: AzureKeyVaultExtractBoth, KeyParameter: "key"},
+				},
+			}},
+			expectedProviders:  []string{"azureKeyVault"},
+			expectedParameters: []string{"cert", "connection", "key"},
+		},
+		{
+			name: "several providers with missing secrets",
+			spec: TriggerAuthenticationSpec{
+				PodIdentity:     &AuthPodIdentity{Provider: PodIdentityProviderAwsEKS},
+				SecretTargetRef: []AuthSecretTargetRef{{Parameter: "password", Name: "creds", Key: "password"}},
+				Env:             []AuthEnvironment{{Parameter: "password", Name: "PASSWORD"}},
+			},
+			missingSecrets:     []string{"creds"},
+			expectedProviders:  []string{"podIdentity:aws-eks", "secretTargetRef", "env"},
+			expectedParameters: []string{"password"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			status := test.spec.GenerateStatus(test.missingSecrets)
+			if !reflect.DeepEqual(status.Providers, test.expectedProviders) {
+				t.Errorf("Expected providers %v, got %v", test.expectedProviders, status.Providers)
+			}
+			if !reflect.DeepEqual(status.Parameters, test.expectedParameters) {
+				t.Errorf("Expected parameters %v, got %v", test.expectedParameters, status.Parameters)
+			}
+
+			ready := status.Conditions.GetReadyCondition()
+			if len(test.missingSecrets) > 0 {
+				if !ready.IsFalse() || ready.Reason != TriggerAuthenticationConditionSecretsNotFoundReason {
+					t.Errorf("Expected the Ready condition to be False with reason %s, got %v", TriggerAuthenticationConditionSecretsNotFoundReason, ready)
+				}
+				for _, name := range test.missingSecrets {
+					if !strings.Contains(ready.Message, name) {
+						t.Errorf("Expected the Ready condition message to contain %s, got %s", name, ready.Message)
+					}
+				}
+			} else if ready.Status != metav1.ConditionTrue {
+				t.Errorf("Expected the Ready condition to be True, got %v", ready)
+			}
+		})
+	}
+}
+
+func TestTriggerAuthenticationGenerateStatusWithoutSecretValues(t *testing.T) {
+	spec := TriggerAuthenticationSpec{
+		SecretTargetRef: []AuthSecretTargetRef{{Parameter: "password", Name: "creds", Key: "password-key"}},
+		HashiCorpVault: &HashiCorpVault{
+			Address:    "http://vault:8200",
+			Credential: &Credential{Token: "vault-token-value"},
+			Secrets:    []VaultSecret{{Parameter: "token", Path: "secret/data/app", Key: "token-key"}},
+		},
+		AzureKeyVault: &AzureKeyVault{
+			Credentials: &AzureKeyVaultCredentials{ClientID: "client-id-value", TenantID: "tenant-id-value"},
+			Secrets:     []AzureKeyVaultSecret{{Parameter: "connection", Name: "connection-name"}},
+		},
+	}
+
+	status := spec.GenerateStatus(nil)
+	generated := strings.Join(append(status.Providers, status.Parameters...), ",")
+	for _, condition := range status.Conditions {
+		generated += "," + condition.Message
+	}
+	for _, value := range []string{"vault-token-value", "client-id-value", "tenant-id-value", "password-key", "token-key", "connection-name"} {
+		if strings.Contains(generated, value) {
+			t.Errorf("Expected the status not to contain %s, got %v", value, status)
+		}
+	}
+}
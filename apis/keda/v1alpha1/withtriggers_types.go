@@ -27,8 +27,11 @@ import (
 )
 
 const (
-	// Default polling interval for a ScaledObject triggers if no pollingInterval is defined.
-	defaultPollingInterval = 30
+	// DefaultPollingInterval is the polling interval of the triggers of a ScaledObject or ScaledJob if no pollingInterval is defined.
+	DefaultPollingInterval = 30
+
+	// DefaultMaxReplicaCount is the maximum replica count of a ScaledObject or ScaledJob if no maxReplicaCount is defined.
+	DefaultMaxReplicaCount = 100
 )
 
 // +kubebuilder:object:root=true
@@ -86,7 +89,7 @@ func (t *WithTriggers) GetPollingInterval() time.Duration {
 		return time.Second * time.Duration(*t.Spec.PollingInterval)
 	}
 
-	return time.Second * time.Duration(defaultPollingInterval)
+	return time.Second * time.Duration(DefaultPollingInterval)
 }
 
 // GenerateIdentifier returns identifier for the object in for "kind.namespace.name"
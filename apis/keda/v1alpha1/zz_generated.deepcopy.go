@@ -24,6 +24,7 @@ package v1alpha1
 import (
 	"k8s.io/api/autoscaling/v2"
 	"k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -35,6 +36,21 @@ func (in *AdvancedConfig) DeepCopyInto(out *AdvancedConfig) {
 		*out = new(HorizontalPodAutoscalerConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ScaleDownTrendGuard != nil {
+		in, out := &in.ScaleDownTrendGuard, &out.ScaleDownTrendGuard
+		*out = new(ScaleDownTrendGuard)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActivationReplicaCount != nil {
+		in, out := &in.ActivationReplicaCount, &out.ActivationReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActivationFailureGracePeriod != nil {
+		in, out := &in.ActivationFailureGracePeriod, &out.ActivationFailureGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedConfig.
@@ -199,6 +215,7 @@ func (in *ClusterTriggerAuthentication) DeepCopyInto(out *ClusterTriggerAuthenti
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTriggerAuthentication.
@@ -300,9 +317,65 @@ func (in *Credential) DeepCopy() *Credential {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrivingTriggerStatus) DeepCopyInto(out *DrivingTriggerStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrivingTriggerStatus.
+func (in *DrivingTriggerStatus) DeepCopy() *DrivingTriggerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrivingTriggerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureCircuitBreaker) DeepCopyInto(out *FailureCircuitBreaker) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Cooldown != nil {
+		in, out := &in.Cooldown, &out.Cooldown
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureCircuitBreaker.
+func (in *FailureCircuitBreaker) DeepCopy() *FailureCircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureCircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Fallback) DeepCopyInto(out *Fallback) {
 	*out = *in
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DecayDuration != nil {
+		in, out := &in.DecayDuration, &out.DecayDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SafeReplicaCount != nil {
+		in, out := &in.SafeReplicaCount, &out.SafeReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Fallback.
@@ -363,6 +436,27 @@ func (in *HealthStatus) DeepCopyInto(out *HealthStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ErrorTypes != nil {
+		in, out := &in.ErrorTypes, &out.ErrorTypes
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FallbackStartTime != nil {
+		in, out := &in.FallbackStartTime, &out.FallbackStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FallbackReplicas != nil {
+		in, out := &in.FallbackReplicas, &out.FallbackReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FallbackDecayRemaining != nil {
+		in, out := &in.FallbackDecayRemaining, &out.FallbackDecayRemaining
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthStatus.
@@ -410,6 +504,42 @@ func (in *Rollout) DeepCopy() *Rollout {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutPauseStatus) DeepCopyInto(out *RolloutPauseStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutPauseStatus.
+func (in *RolloutPauseStatus) DeepCopy() *RolloutPauseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutPauseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleDownTrendGuard) DeepCopyInto(out *ScaleDownTrendGuard) {
+	*out = *in
+	if in.MinSlope != nil {
+		in, out := &in.MinSlope, &out.MinSlope
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleDownTrendGuard.
+func (in *ScaleDownTrendGuard) DeepCopy() *ScaleDownTrendGuard {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleDownTrendGuard)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScaleTarget) DeepCopyInto(out *ScaleTarget) {
 	*out = *in
@@ -440,6 +570,11 @@ func (in *ScaleTriggers) DeepCopyInto(out *ScaleTriggers) {
 		*out = new(ScaledObjectAuthRef)
 		**out = **in
 	}
+	if in.CooldownPeriod != nil {
+		in, out := &in.CooldownPeriod, &out.CooldownPeriod
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleTriggers.
@@ -519,6 +654,20 @@ func (in *ScaledJobSpec) DeepCopyInto(out *ScaledJobSpec) {
 		*out = new(v1.JobSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.JobLabels != nil {
+		in, out := &in.JobLabels, &out.JobLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.JobAnnotations != nil {
+		in, out := &in.JobAnnotations, &out.JobAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.PollingInterval != nil {
 		in, out := &in.PollingInterval, &out.PollingInterval
 		*out = new(int32)
@@ -711,7 +860,7 @@ func (in *ScaledObjectSpec) DeepCopyInto(out *ScaledObjectSpec) {
 	if in.Fallback != nil {
 		in, out := &in.Fallback, &out.Fallback
 		*out = new(Fallback)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -742,6 +891,13 @@ func (in *ScaledObjectStatus) DeepCopyInto(out *ScaledObjectStatus) {
 		in, out := &in.LastActiveTime, &out.LastActiveTime
 		*out = (*in).DeepCopy()
 	}
+	if in.TriggersLastActiveTime != nil {
+		in, out := &in.TriggersLastActiveTime, &out.TriggersLastActiveTime
+		*out = make(map[string]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.ExternalMetricNames != nil {
 		in, out := &in.ExternalMetricNames, &out.ExternalMetricNames
 		*out = make([]string, len(*in))
@@ -769,6 +925,16 @@ func (in *ScaledObjectStatus) DeepCopyInto(out *ScaledObjectStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.RolloutPause != nil {
+		in, out := &in.RolloutPause, &out.RolloutPause
+		*out = new(RolloutPauseStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DrivingTrigger != nil {
+		in, out := &in.DrivingTrigger, &out.DrivingTrigger
+		*out = new(DrivingTriggerStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectStatus.
@@ -794,6 +960,11 @@ func (in *ScalingStrategy) DeepCopyInto(out *ScalingStrategy) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.FailureCircuitBreaker != nil {
+		in, out := &in.FailureCircuitBreaker, &out.FailureCircuitBreaker
+		*out = new(FailureCircuitBreaker)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingStrategy.
@@ -827,6 +998,7 @@ func (in *TriggerAuthentication) DeepCopyInto(out *TriggerAuthentication) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerAuthentication.
@@ -919,6 +1091,36 @@ func (in *TriggerAuthenticationSpec) DeepCopy() *TriggerAuthenticationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerAuthenticationStatus) DeepCopyInto(out *TriggerAuthenticationStatus) {
+	*out = *in
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(Conditions, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerAuthenticationStatus.
+func (in *TriggerAuthenticationStatus) DeepCopy() *TriggerAuthenticationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerAuthenticationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValueFromSecret) DeepCopyInto(out *ValueFromSecret) {
 	*out = *in
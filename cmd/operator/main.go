@@ -20,29 +20,42 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"runtime"
 	"time"
 
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/transport"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollers "github.com/kedacore/keda/v2/controllers/keda"
 	"github.com/kedacore/keda/v2/pkg/certificates"
 	"github.com/kedacore/keda/v2/pkg/k8s"
 	"github.com/kedacore/keda/v2/pkg/metricsservice"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/scaling"
+	scalingcache "github.com/kedacore/keda/v2/pkg/scaling/cache"
+	"github.com/kedacore/keda/v2/pkg/scaling/executor"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
+	"github.com/kedacore/keda/v2/version"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -83,6 +96,28 @@ func main() {
 	var webhooksServiceName string
 	var enableCertRotation bool
 	var validatingWebhookName string
+	var scalersHTTPProxy string
+	var caCertDirs []string
+	var enableScaledObjectGeneration bool
+	var enableMetricsUIDLabel bool
+	var enableScalerConfigDump bool
+	var scalerConfigDumpAddr string
+	var metricsLabelValueMaxLength int
+	var scalersMaxConcurrentQueries int
+	var scalersSharedMetricsTTL time.Duration
+	var metricsFilePath string
+	var metricsFileInterval time.Duration
+	var namespaceReplicasInterval time.Duration
+	var scaleTargetConflictsInterval time.Duration
+	var selfThrottlingThreshold float64
+	var selfThrottlingInterval time.Duration
+	var logScalerCalls bool
+	var allowPartialHPA bool
+	var enableScalingDefaults bool
+	var metricsRegion string
+	var firstPodReadyTimeout time.Duration
+	var eventsPerObjectPerMinute float64
+	var eventsBurst int
 	pflag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	pflag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	pflag.StringVar(&metricsServiceAddr, "metrics-service-bind-address", ":9666", "The address the gRPRC Metrics Service endpoint binds to.")
@@ -99,6 +134,28 @@ func main() {
 	pflag.StringVar(&webhooksServiceName, "webhooks-service-name", "keda-admission-webhooks", "Webhook service name. Defaults to keda-admission-webhooks")
 	pflag.BoolVar(&enableCertRotation, "enable-cert-rotation", false, "enable automatic generation and rotation of TLS certificates/keys")
 	pflag.StringVar(&validatingWebhookName, "validating-webhook-name", "keda-admission", "ValidatingWebhookConfiguration name. Defaults to keda-admission")
+	pflag.StringVar(&scalersHTTPProxy, "scalers-http-proxy", "", "Proxy used by the scalers for outgoing HTTP and gRPC connections, unless the trigger sets its own proxy. Defaults to the proxy from environment")
+	pflag.StringArrayVar(&caCertDirs, "ca-cert-dir", nil, "Directory of PEM files added to the root CAs of the scalers, next to /custom/ca. Can be set several times. The directories are watched and the scalers are rebuilt with the rotated CAs. Defaults to none")
+	pflag.BoolVar(&enableScaledObjectGeneration, "enable-scaledobject-generation", false, "Enable the generation of ScaledObjects from the keda.sh/* annotations of Deployments and StatefulSets")
+	pflag.IntVar(&scalersMaxConcurrentQueries, "scalers-max-concurrent-queries", 0, "Maximum number of scaler queries running at the same time across all ScaledObjects and ScaledJobs. Defaults to 0, no limit")
+	pflag.DurationVar(&scalersSharedMetricsTTL, "scalers-shared-metrics-ttl", 0, "Time the result of a trigger is shared with the identical triggers, same type, metadata and authentication, of other ScaledObjects. Only used by ScaledObjects with a longer pollingInterval. Defaults to 0, disabled")
+	pflag.StringVar(&metricsFilePath, "metrics-file-path", "", "Path of a file the metrics are periodically written to in the Prometheus text format, for clusters where the metrics can't be scraped. Defaults to empty, disabled")
+	pflag.DurationVar(&metricsFileInterval, "metrics-file-interval", time.Minute, "Interval between two writes of the metrics file. Defaults to 1m")
+	pflag.DurationVar(&namespaceReplicasInterval, "namespace-replicas-interval", time.Minute, "Interval between two aggregations of the current and maximum replicas of the ScaledObjects per namespace. Set to 0 to disable. Defaults to 1m")
+	pflag.DurationVar(&scaleTargetConflictsInterval, "scale-target-conflicts-interval", time.Minute, "Interval between two counts of the ScaledObjects referencing each scale target, reported in keda_scaletarget_scaledobject_count. Set to 0 to disable. Defaults to 1m")
+	pflag.Float64Var(&selfThrottlingThreshold, "self-throttling-threshold", 0, "Ratio of the cgroup memory or CPU limit of the operator above which it is reported under resource pressure in keda_operator_self_throttling. Defaults to 0, disabled")
+	pflag.DurationVar(&selfThrottlingInterval, "self-throttling-interval", 10*time.Second, "Interval between two checks of the memory and CPU usage of the operator for keda_operator_self_throttling. Defaults to 10s")
+	pflag.DurationVar(&firstPodReadyTimeout, "first-pod-ready-timeout", 0, "Time to wait for the first ready pod of an activated scale target, reported in keda_scaledobject_first_pod_ready_seconds. The operator watches all the pods when it's set. Set to 0 to disable. Defaults to 0")
+	pflag.IntVar(&metricsLabelValueMaxLength, "metrics-label-value-max-length", 0, "Maximum length of the label values of the scaler metrics, longer values are truncated and suffixed with a hash of the full value. Defaults to 0, no truncation")
+	pflag.BoolVar(&enableScalerConfigDump, "enable-scaler-config-dump", false, "Serve the effective scaler configuration of a ScaledObject, with the secrets redacted, on /debug/scaler-config?namespace=<namespace>&name=<name> of --scaler-config-dump-bind-address")
+	pflag.StringVar(&scalerConfigDumpAddr, "scaler-config-dump-bind-address", "127.0.0.1:8082", "The loopback address the scaler config dump endpoint binds to, it's reached with a port-forward to the operator pod. Defaults to 127.0.0.1:8082")
+	pflag.BoolVar(&enableMetricsUIDLabel, "enable-metrics-uid-label", false, "Add the uid label, the metadata.uid of the ScaledObject, to the scaler metrics. It increases the cardinality as every recreated ScaledObject gets new series")
+	pflag.BoolVar(&logScalerCalls, "log-scaler-calls", false, "Log every metrics query of the scalers with the ScaledObject, trigger, metric name, duration and the correlation ID shared with the logs of the metrics server")
+	pflag.BoolVar(&allowPartialHPA, "allow-partial-hpa", false, "Scale a ScaledObject on its remaining triggers when some of them fail to build, instead of failing its HPA. The failed triggers are reported in its Ready condition and events. Defaults to false")
+	pflag.BoolVar(&enableScalingDefaults, "enable-scaling-defaults", false, "Apply the defaults and caps of pollingInterval, cooldownPeriod and maxReplicaCount of the keda-scaling-defaults ConfigMaps, of the namespace of each ScaledObject and ScaledJob and of the KEDA namespace. The clamped specs get a Capped condition. Defaults to false")
+	pflag.StringVar(&metricsRegion, "metrics-region", "", "Value of the region label of the replica gauges, to tell apart the operators of several regions. Defaults to the topology.kubernetes.io/region label of the node from the NODE_NAME environment variable, if set")
+	pflag.Float64Var(&eventsPerObjectPerMinute, "events-per-object-per-minute", 0, "Maximum rate of the events emitted for an object with the same reason, the suppressed events are reported in a summary event every minute. Ready transitions are never suppressed. Defaults to 0, no rate limit")
+	pflag.IntVar(&eventsBurst, "events-burst", 20, "Number of events emitted for an object with the same reason before --events-per-object-per-minute applies. Defaults to 20")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
@@ -112,6 +169,39 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := kedautil.SetGlobalHTTPProxy(scalersHTTPProxy); err != nil {
+		setupLog.Error(err, "invalid scalers-http-proxy")
+		os.Exit(1)
+	}
+
+	kedautil.SetHTTPMetricsRecorder(prommetrics.ScalerHTTPMetricsRecorder{})
+
+	if err := kedautil.SetCACertDirs(caCertDirs); err != nil {
+		setupLog.Error(err, "invalid ca-cert-dir")
+		os.Exit(1)
+	}
+
+	if err := scalingcache.SetMaxConcurrentQueries(scalersMaxConcurrentQueries); err != nil {
+		setupLog.Error(err, "invalid scalers-max-concurrent-queries")
+		os.Exit(1)
+	}
+	if err := scalingcache.SetSharedMetricsTTL(scalersSharedMetricsTTL); err != nil {
+		setupLog.Error(err, "invalid scalers-shared-metrics-ttl")
+		os.Exit(1)
+	}
+
+	scaling.SetLogScalerCalls(logScalerCalls)
+	scaling.SetAllowPartialHPA(allowPartialHPA)
+
+	if err := prommetrics.SetUIDLabelEnabled(enableMetricsUIDLabel); err != nil {
+		setupLog.Error(err, "unable to set the uid label of the scaler metrics")
+		os.Exit(1)
+	}
+	if err := prommetrics.SetLabelValueMaxLength(metricsLabelValueMaxLength); err != nil {
+		setupLog.Error(err, "invalid metrics-label-value-max-length")
+		os.Exit(1)
+	}
+
 	leaseDuration, err := kedautil.ResolveOsEnvDuration("KEDA_OPERATOR_LEADER_ELECTION_LEASE_DURATION")
 	if err != nil {
 		setupLog.Error(err, "invalid KEDA_OPERATOR_LEADER_ELECTION_LEASE_DURATION")
@@ -130,13 +220,29 @@ func main() {
 		os.Exit(1)
 	}
 
+	prommetrics.RegisterAPIServerThrottling()
 	cfg := ctrl.GetConfigOrDie()
 	cfg.QPS = adapterClientRequestQPS
 	cfg.Burst = adapterClientRequestBurst
 	cfg.DisableCompression = disableCompression
+	watchResets := k8s.NewWatchResetsRecorder()
+	cfg.WrapTransport = transport.Wrappers(cfg.WrapTransport, watchResets.Wrap)
+
+	cacheOptions := ctrlcache.Options{}
+	clientOptions := client.Options{}
+	if enableScalingDefaults {
+		// the scaling defaults are watched without caching all the ConfigMaps of the cluster,
+		// the other ConfigMaps, like the ones referenced by the triggers, are read from the API
+		cacheOptions.ByObject = map[client.Object]ctrlcache.ByObject{
+			&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", scaling.ScalingDefaultsConfigMapName)},
+		}
+		clientOptions.Cache = &client.CacheOptions{DisableFor: []client.Object{&corev1.ConfigMap{}}}
+	}
 
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions,
+		Client:                 clientOptions,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
@@ -152,6 +258,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	if nodeName := os.Getenv("NODE_NAME"); metricsRegion == "" && nodeName != "" {
+		// the manager cache isn't started yet, the node is read directly
+		metricsRegion, err = k8s.GetNodeRegion(ctx, mgr.GetAPIReader(), nodeName)
+		if err != nil {
+			setupLog.Error(err, "unable to get the region of the node, the metrics won't have a region label")
+			metricsRegion = ""
+		}
+	}
+	prommetrics.SetRegion(metricsRegion)
+	// the scaling metrics are only exported once the replica is elected
+	if err := mgr.Add(prommetrics.LeaderRecorder{}); err != nil {
+		setupLog.Error(err, "unable to set up leader metrics")
+		os.Exit(1)
+	}
+
 	// default to 3 seconds if they don't pass the env var
 	globalHTTPTimeoutMS, err := kedautil.ResolveOsEnvInt("KEDA_HTTP_DEFAULT_TIMEOUT", 3000)
 	if err != nil {
@@ -172,7 +293,18 @@ func main() {
 	}
 
 	globalHTTPTimeout := time.Duration(globalHTTPTimeoutMS) * time.Millisecond
-	eventRecorder := mgr.GetEventRecorderFor("keda-operator")
+	var eventRecorder record.EventRecorder = mgr.GetEventRecorderFor("keda-operator")
+	if eventsPerObjectPerMinute > 0 {
+		limitedRecorder, err := k8s.NewRateLimitedEventRecorder(eventRecorder, eventsPerObjectPerMinute, eventsBurst, time.Minute, ctrl.Log.WithName("events"))
+		if err == nil {
+			err = mgr.Add(limitedRecorder)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up rate limiting of the events")
+			os.Exit(1)
+		}
+		eventRecorder = limitedRecorder
+	}
 
 	kubeClientset, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
@@ -184,6 +316,25 @@ func main() {
 		setupLog.Error(err, "Unable to get cluster object namespace")
 		os.Exit(1)
 	}
+	if enableScalingDefaults {
+		scaling.EnableScalingDefaults(objectNamespace, mgr.GetCache())
+		configMapInformer, err := mgr.GetCache().GetInformer(ctx, &corev1.ConfigMap{})
+		if err == nil {
+			_, err = configMapInformer.AddEventHandler(scaling.ScalingDefaultsEventHandler())
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to watch the scaling defaults")
+			os.Exit(1)
+		}
+	}
+	// the manager cache isn't started yet, the ConfigMap is read directly. The count only feeds a metric so an error
+	// doesn't stop the operator
+	restarts, err := k8s.IncrementRestartCount(ctx, mgr.GetAPIReader(), mgr.GetClient(), objectNamespace)
+	if err != nil {
+		setupLog.Error(err, "unable to count the restart of the operator")
+	} else {
+		prommetrics.RecordOperatorRestarts(restarts)
+	}
 	// the namespaced kubeInformerFactory is used to restrict secret informer to only list/watch secrets in KEDA cluster object namespace,
 	// refer to https://github.com/kedacore/keda/issues/3668
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClientset, 1*time.Hour, kubeinformers.WithNamespace(objectNamespace))
@@ -195,6 +346,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		setupLog.Error(err, "Unable to create dynamic client")
+		os.Exit(1)
+	}
+	scalingcache.SetResourceInformersClient(dynamicClient, mgr.GetRESTMapper())
+	watchResets.SetRESTMapper(mgr.GetRESTMapper())
+
 	scaledHandler := scaling.NewScaleHandler(mgr.GetClient(), scaleClient, mgr.GetScheme(), globalHTTPTimeout, eventRecorder, secretInformer.Lister())
 
 	if err = (&kedacontrollers.ScaledObjectReconciler{
@@ -227,6 +386,8 @@ func main() {
 	if err = (&kedacontrollers.TriggerAuthenticationReconciler{
 		Client:        mgr.GetClient(),
 		EventRecorder: eventRecorder,
+		SecretsLister: secretInformer.Lister(),
+		APIReader:     mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TriggerAuthentication")
 		os.Exit(1)
@@ -234,10 +395,22 @@ func main() {
 	if err = (&kedacontrollers.ClusterTriggerAuthenticationReconciler{
 		Client:        mgr.GetClient(),
 		EventRecorder: eventRecorder,
+		SecretsLister: secretInformer.Lister(),
+		APIReader:     mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterTriggerAuthentication")
 		os.Exit(1)
 	}
+	if enableScaledObjectGeneration {
+		if err = (&kedacontrollers.ScaledObjectGeneratorReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Recorder: eventRecorder,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ScaledObjectGenerator")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -278,6 +451,124 @@ func main() {
 		os.Exit(1)
 	}
 
+	autoscalingAPI, err := k8s.DetectAutoscalingAPIVersion(kubeClientset.Discovery())
+	if err != nil {
+		setupLog.Error(err, "unable to detect the autoscaling API version")
+	}
+	runtimeInfo := prommetrics.RuntimeInfo{
+		Version:                version.Version,
+		GitCommit:              version.GitCommit,
+		GoVersion:              runtime.Version(),
+		CertRotation:           enableCertRotation,
+		ScaledObjectGeneration: enableScaledObjectGeneration,
+		KubernetesVersion:      kubeVersion.PrettyVersion,
+		AutoscalingAPI:         autoscalingAPI,
+	}
+	prommetrics.RecordRuntimeInfo(runtimeInfo)
+	// the health probe server of the manager can't be extended, so the version is served next to the metrics
+	if err := mgr.AddMetricsExtraHandler("/version", kedautil.NewVersionHandler(runtimeInfo)); err != nil {
+		setupLog.Error(err, "unable to set up version endpoint")
+		os.Exit(1)
+	}
+	if enableScalerConfigDump {
+		dumpServer, err := scaling.NewScalerConfigDumpServer(scalerConfigDumpAddr, mgr.GetClient(), scaledHandler, ctrl.Log.WithName("scaler-config-dump"))
+		if err == nil {
+			err = mgr.Add(dumpServer)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up scaler config dump endpoint")
+			os.Exit(1)
+		}
+	}
+
+	if metricsFilePath != "" {
+		fileExporter, err := prommetrics.NewFileExporter(metrics.Registry, metricsFilePath, metricsFileInterval, ctrl.Log.WithName("metrics-file"))
+		if err == nil {
+			err = mgr.Add(fileExporter)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up metrics file export")
+			os.Exit(1)
+		}
+	}
+
+	if namespaceReplicasInterval > 0 {
+		aggregator, err := scaling.NewNamespaceReplicasAggregator(mgr.GetClient(), namespaceReplicasInterval, ctrl.Log.WithName("namespace-replicas"))
+		if err == nil {
+			err = mgr.Add(aggregator)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up namespace replicas aggregation")
+			os.Exit(1)
+		}
+	}
+
+	if scaleTargetConflictsInterval > 0 {
+		detector, err := scaling.NewScaleTargetConflictDetector(mgr.GetClient(), scaleTargetConflictsInterval, ctrl.Log.WithName("scale-target-conflicts"))
+		if err == nil {
+			err = mgr.Add(detector)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up scale target conflict detection")
+			os.Exit(1)
+		}
+	}
+
+	if len(caCertDirs) > 0 {
+		watcher, err := kedautil.NewCACertDirsWatcher(ctrl.Log.WithName("ca-cert-dirs"), prommetrics.RecordOperatorConfigReload)
+		if err == nil {
+			err = mgr.Add(watcher)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up the CA cert dirs watcher")
+			os.Exit(1)
+		}
+	}
+
+	if selfThrottlingThreshold > 0 {
+		detector, err := scaling.NewResourcePressureDetector(scaling.DefaultCgroupRoot, selfThrottlingThreshold, selfThrottlingInterval, ctrl.Log.WithName("resource-pressure"))
+		if err == nil {
+			err = mgr.Add(detector)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up the resource pressure detector")
+			os.Exit(1)
+		}
+	}
+
+	if firstPodReadyTimeout > 0 {
+		tracker, err := executor.NewFirstPodReadyTracker(firstPodReadyTimeout, ctrl.Log.WithName("first-pod-ready"))
+		if err == nil {
+			var podInformer ctrlcache.Informer
+			if podInformer, err = mgr.GetCache().GetInformer(ctx, &corev1.Pod{}); err == nil {
+				_, err = podInformer.AddEventHandler(tracker.PodEventHandler())
+			}
+		}
+		if err == nil {
+			err = mgr.Add(tracker)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up first ready pod tracking")
+			os.Exit(1)
+		}
+		executor.SetFirstPodReadyTracker(tracker)
+	}
+
+	if err := k8s.RecordInformerSyncs(ctx, secretInformer.Informer(), "Secret"); err != nil {
+		setupLog.Error(err, "unable to set up informer sync metrics", "kind", "Secret")
+		os.Exit(1)
+	}
+	for kind, obj := range map[string]client.Object{"ScaledObject": &kedav1alpha1.ScaledObject{}, "ScaledJob": &kedav1alpha1.ScaledJob{}} {
+		informer, err := mgr.GetCache().GetInformer(ctx, obj)
+		if err == nil {
+			err = k8s.RecordInformerSyncs(ctx, informer, kind)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up informer sync metrics", "kind", kind)
+			os.Exit(1)
+		}
+	}
+
 	kedautil.PrintWelcome(setupLog, kubeVersion, "manager")
 
 	kubeInformerFactory.Start(ctx.Done())
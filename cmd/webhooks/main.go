@@ -55,12 +55,16 @@ func main() {
 	var webhooksClientRequestBurst int
 	var certDir string
 	var tlsMinVersion string
+	var enableHPAOwnershipWebhook bool
+	var hpaOwnershipOperatorUsernames []string
 	pflag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	pflag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	pflag.Float32Var(&webhooksClientRequestQPS, "kube-api-qps", 20.0, "Set the QPS rate for throttling requests sent to the apiserver")
 	pflag.IntVar(&webhooksClientRequestBurst, "kube-api-burst", 30, "Set the burst for throttling requests sent to the apiserver")
 	pflag.StringVar(&certDir, "cert-dir", "/certs", "Webhook certificates dir to use. Defaults to /certs")
 	pflag.StringVar(&tlsMinVersion, "tls-min-version", "1.3", "Minimum TLS version")
+	pflag.BoolVar(&enableHPAOwnershipWebhook, "enable-hpa-ownership-webhook", false, "Reject changes to the HPAs generated for ScaledObjects which aren't made by the KEDA operator")
+	pflag.StringSliceVar(&hpaOwnershipOperatorUsernames, "hpa-ownership-operator-usernames", []string{"system:serviceaccount:keda:keda-operator"}, "Users allowed to change the HPAs generated for ScaledObjects, used by the HPA ownership webhook")
 
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
@@ -98,7 +102,7 @@ func main() {
 
 	kedautil.PrintWelcome(setupLog, kubeVersion, "admission webhooks")
 
-	setupWebhook(mgr, tlsMinVersion)
+	setupWebhook(mgr, tlsMinVersion, enableHPAOwnershipWebhook, hpaOwnershipOperatorUsernames)
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -115,12 +119,16 @@ func main() {
 	}
 }
 
-func setupWebhook(mgr manager.Manager, tlsMinVersion string) {
+func setupWebhook(mgr manager.Manager, tlsMinVersion string, enableHPAOwnershipWebhook bool, hpaOwnershipOperatorUsernames []string) {
 	// setup webhooks
 	if err := (&kedav1alpha1.ScaledObject{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "ScaledObject")
 		os.Exit(1)
 	}
+	if enableHPAOwnershipWebhook {
+		setupLog.Info("setting up HPA ownership webhook", "operatorUsernames", hpaOwnershipOperatorUsernames)
+		kedav1alpha1.SetupHPAOwnershipWebhookWithManager(mgr, hpaOwnershipOperatorUsernames)
+	}
 
 	setupLog.V(1).Info("setting up webhook server")
 	hookServer := mgr.GetWebhookServer()
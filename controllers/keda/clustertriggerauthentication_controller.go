@@ -22,22 +22,29 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/prommetrics"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 // ClusterTriggerAuthenticationReconciler reconciles a ClusterTriggerAuthentication object
 type ClusterTriggerAuthenticationReconciler struct {
 	client.Client
 	record.EventRecorder
+	SecretsLister corev1listers.SecretLister
+	// APIReader reads the referenced secrets without caching the Secrets of the cluster
+	APIReader client.Reader
 }
 
 type clusterTriggerAuthMetricsData struct {
@@ -82,6 +89,21 @@ func (r *ClusterTriggerAuthenticationReconciler) Reconcile(ctx context.Context,
 	if clusterTriggerAuthentication.ObjectMeta.Generation == 1 {
 		r.EventRecorder.Event(clusterTriggerAuthentication, corev1.EventTypeNormal, eventreason.ClusterTriggerAuthenticationAdded, "New ClusterTriggerAuthentication configured")
 	}
+
+	// the secrets of ClusterTriggerAuthentications are in the cluster object namespace
+	clusterObjectNamespace, err := kedautil.GetClusterObjectNamespace()
+	if err != nil {
+		reqLogger.Error(err, "Failed to get the cluster object namespace")
+		return ctrl.Result{}, err
+	}
+	missingRefs, err := checkTriggerAuthSecretRefs(ctx, reqLogger, r.APIReader, r.SecretsLister, &clusterTriggerAuthentication.Spec,
+		clusterObjectNamespace, getTriggerAuthMissingRefsKey(prommetrics.ClusterTriggerAuthenticationResource, req.NamespacedName.String()))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := updateTriggerAuthStatus(ctx, reqLogger, r.Client, clusterTriggerAuthentication, &clusterTriggerAuthentication.Spec, &clusterTriggerAuthentication.Status, missingRefs); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -89,9 +111,34 @@ func (r *ClusterTriggerAuthenticationReconciler) Reconcile(ctx context.Context,
 func (r *ClusterTriggerAuthenticationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kedav1alpha1.ClusterTriggerAuthentication{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// only the metadata of the Secrets is watched, their data isn't cached
+		WatchesMetadata(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.clusterTriggerAuthsReferencingSecret), builder.WithPredicates(secretExistencePredicate)).
 		Complete(r)
 }
 
+// clusterTriggerAuthsReferencingSecret returns the ClusterTriggerAuthentications which reference the Secret,
+// only the Secrets of the cluster object namespace are referenced by them
+func (r *ClusterTriggerAuthenticationReconciler) clusterTriggerAuthsReferencingSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	clusterObjectNamespace, err := kedautil.GetClusterObjectNamespace()
+	if err != nil || secret.GetNamespace() != clusterObjectNamespace {
+		return nil
+	}
+
+	clusterTriggerAuths := &kedav1alpha1.ClusterTriggerAuthenticationList{}
+	if err := r.Client.List(ctx, clusterTriggerAuths); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list the ClusterTriggerAuthentications")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range clusterTriggerAuths.Items {
+		if referencesSecret(&clusterTriggerAuths.Items[i].Spec, secret.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterTriggerAuths.Items[i])})
+		}
+	}
+	return requests
+}
+
 func (r *ClusterTriggerAuthenticationReconciler) updatePromMetrics(clusterTriggerAuth *kedav1alpha1.ClusterTriggerAuthentication, namespacedName string) {
 	clusterTriggerAuthPromMetricsLock.Lock()
 	defer clusterTriggerAuthPromMetricsLock.Unlock()
@@ -114,4 +161,5 @@ func (r *ClusterTriggerAuthenticationReconciler) UpdatePromMetricsOnDelete(names
 	}
 
 	delete(clusterTriggerAuthPromMetricsMap, namespacedName)
+	updateTriggerAuthMissingRefs(getTriggerAuthMissingRefsKey(prommetrics.ClusterTriggerAuthenticationResource, namespacedName), false)
 }
@@ -18,19 +18,27 @@ package keda
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"unicode"
 
 	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/scaling/executor"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	version "github.com/kedacore/keda/v2/version"
@@ -38,7 +46,6 @@ import (
 
 const (
 	defaultHPAMinReplicas int32 = 1
-	defaultHPAMaxReplicas int32 = 100
 )
 
 // createAndDeployNewHPA creates and deploy HPA in the cluster for specified ScaledObject
@@ -56,6 +63,9 @@ func (r *ScaledObjectReconciler) createAndDeployNewHPA(ctx context.Context, logg
 		logger.Error(err, "Failed to create new HPA in cluster", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", hpaName)
 		return err
 	}
+	// the generations of a new HPA start over
+	r.forgetHPAGeneration(scaledObject)
+	r.storeHPAGeneration(scaledObject, hpa)
 
 	// store hpaName in the ScaledObject
 	status := scaledObject.Status.DeepCopy()
@@ -101,6 +111,7 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 	for key, value := range scaledObject.ObjectMeta.Labels {
 		labels[key] = value
 	}
+	labels[kedav1alpha1.HPAOwnershipLabel] = "true"
 
 	minReplicas := getHPAMinReplicas(scaledObject)
 	maxReplicas := getHPAMaxReplicas(scaledObject)
@@ -148,7 +159,8 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 	return hpa, nil
 }
 
-// updateHPAIfNeeded checks whether update of HPA is needed
+// updateHPAIfNeeded checks whether update of HPA is needed, changes made to the HPA outside of KEDA are reverted.
+// The HPA isn't updated when it already matches the ScaledObject, so its resourceVersion only changes with the ScaledObject
 func (r *ScaledObjectReconciler) updateHPAIfNeeded(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, foundHpa *autoscalingv2.HorizontalPodAutoscaler, gvkr *kedav1alpha1.GroupVersionKindResource) error {
 	hpa, err := r.newHPAForScaledObject(ctx, logger, scaledObject, gvkr)
 	if err != nil {
@@ -156,31 +168,131 @@ func (r *ScaledObjectReconciler) updateHPAIfNeeded(ctx context.Context, logger l
 		return err
 	}
 
-	// DeepDerivative ignores extra entries in arrays which makes removing the last trigger not update things, so trigger and update any time the metrics count is different.
-	if len(hpa.Spec.Metrics) != len(foundHpa.Spec.Metrics) || !equality.Semantic.DeepDerivative(hpa.Spec, foundHpa.Spec) {
-		logger.V(1).Info("Found difference in the HPA spec accordint to ScaledObject", "currentHPA", foundHpa.Spec, "newHPA", hpa.Spec)
-		if err = r.Client.Update(ctx, hpa); err != nil {
-			foundHpa.Spec = hpa.Spec
-			logger.Error(err, "Failed to update HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
-			return err
-		}
+	driftedFields := getHPADrift(hpa, foundHpa)
+	if len(driftedFields) == 0 {
+		r.storeHPAGeneration(scaledObject, foundHpa)
+		prommetrics.RecordScaledObjectHPAUpdate(scaledObject.Namespace, scaledObject.Name, prommetrics.HPAUpdateResultSkipped)
+		return nil
+	}
 
-		logger.Info("Updated HPA according to ScaledObject", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+	logger.V(1).Info("Found difference in the HPA according to ScaledObject", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name,
+		"fields", driftedFields, "diff", getHPADiff(hpa, foundHpa))
+	// the generation of the HPA changes with its spec, a new generation KEDA hasn't written means the HPA was edited directly
+	changedOutsideKEDA := r.hpaGenerationChanged(scaledObject, foundHpa)
+	if err = r.Client.Update(ctx, hpa); err != nil {
+		foundHpa.Spec = hpa.Spec
+		foundHpa.ObjectMeta.Labels = hpa.ObjectMeta.Labels
+		logger.Error(err, "Failed to update HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+		recordHPAImmutableError(scaledObject, err)
+		return err
 	}
+	r.storeHPAGeneration(scaledObject, hpa)
+	prommetrics.RecordScaledObjectHPAUpdate(scaledObject.Namespace, scaledObject.Name, prommetrics.HPAUpdateResultPerformed)
 
-	if !equality.Semantic.DeepDerivative(hpa.ObjectMeta.Labels, foundHpa.ObjectMeta.Labels) {
-		logger.V(1).Info("Found difference in the HPA labels accordint to ScaledObject", "currentHPA", foundHpa.ObjectMeta.Labels, "newHPA", hpa.ObjectMeta.Labels)
-		if err = r.Client.Update(ctx, hpa); err != nil {
-			foundHpa.ObjectMeta.Labels = hpa.ObjectMeta.Labels
-			logger.Error(err, "Failed to update HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
-			return err
-		}
+	if changedOutsideKEDA {
+		logger.Info("Reverted changes made to the HPA outside of the ScaledObject", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name, "fields", driftedFields)
+		r.Recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.HPAChangesReverted, "Reverted changes of %s made to HPA %s/%s outside of the ScaledObject", strings.Join(driftedFields, ", "), foundHpa.Namespace, foundHpa.Name)
+	} else {
 		logger.Info("Updated HPA according to ScaledObject", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
 	}
 
 	return nil
 }
 
+// recordHPAImmutableError counts the failed HPA updates rejected by the API server for changing an immutable field,
+// these keep failing on every reconcile until the ScaledObject or the HPA is fixed
+func recordHPAImmutableError(scaledObject *kedav1alpha1.ScaledObject, err error) {
+	if !isImmutableFieldError(err) {
+		return
+	}
+	prommetrics.RecordScaledObjectHPAImmutableError(scaledObject.Namespace, scaledObject.Name)
+}
+
+func isImmutableFieldError(err error) bool {
+	if !apierrors.IsInvalid(err) {
+		return false
+	}
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) || statusErr.Status().Details == nil {
+		return false
+	}
+	for _, cause := range statusErr.Status().Details.Causes {
+		if strings.Contains(cause.Message, apimachineryvalidation.FieldImmutableErrorMsg) {
+			return true
+		}
+	}
+	return false
+}
+
+// getHPASpecDrift returns the fields of the found HPA spec which differ from the spec generated for the ScaledObject.
+// Fields left empty in the generated spec and defaulted by the API server aren't a drift, but a behavior added
+// to an HPA generated without one is.
+func getHPASpecDrift(desired, found autoscalingv2.HorizontalPodAutoscalerSpec) []string {
+	var fields []string
+	if !equality.Semantic.DeepDerivative(desired.ScaleTargetRef, found.ScaleTargetRef) {
+		fields = append(fields, "spec.scaleTargetRef")
+	}
+	if !equality.Semantic.DeepDerivative(desired.MinReplicas, found.MinReplicas) {
+		fields = append(fields, "spec.minReplicas")
+	}
+	if desired.MaxReplicas != found.MaxReplicas {
+		fields = append(fields, "spec.maxReplicas")
+	}
+	// DeepDerivative ignores extra entries in arrays which makes removing the last trigger not update things, so compare the metrics count too
+	if len(desired.Metrics) != len(found.Metrics) || !equality.Semantic.DeepDerivative(desired.Metrics, found.Metrics) {
+		fields = append(fields, "spec.metrics")
+	}
+	if (desired.Behavior == nil && found.Behavior != nil) || !equality.Semantic.DeepDerivative(desired.Behavior, found.Behavior) {
+		fields = append(fields, "spec.behavior")
+	}
+	return fields
+}
+
+// getHPADrift returns the fields of the found HPA which differ from the HPA generated for the ScaledObject. Only the
+// spec and the labels are compared, the fields populated by the API server like the status, the managed fields or the
+// resourceVersion never are, and the quantities are compared by value so 500m and 0.5 are the same target
+func getHPADrift(desired, found *autoscalingv2.HorizontalPodAutoscaler) []string {
+	fields := getHPASpecDrift(desired.Spec, found.Spec)
+	if !equality.Semantic.DeepDerivative(desired.ObjectMeta.Labels, found.ObjectMeta.Labels) {
+		fields = append(fields, "metadata.labels")
+	}
+	return fields
+}
+
+// getHPADiff returns the difference between the spec and the labels of the found and the desired HPA for the debug logs
+func getHPADiff(desired, found *autoscalingv2.HorizontalPodAutoscaler) string {
+	return cmp.Diff(found.Spec, desired.Spec) + cmp.Diff(found.ObjectMeta.Labels, desired.ObjectMeta.Labels)
+}
+
+// hpaGenerationChanged returns true if the HPA has a newer generation than the one KEDA last wrote or checked, an older
+// one comes from a stale cache. It's false when the generation isn't known yet, e.g. after a restart of the operator
+func (r *ScaledObjectReconciler) hpaGenerationChanged(scaledObject *kedav1alpha1.ScaledObject, hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	key, err := cache.MetaNamespaceKeyFunc(scaledObject)
+	if err != nil {
+		return false
+	}
+	value, loaded := r.hpaGenerations.Load(key)
+	return loaded && hpa.Generation > value.(int64)
+}
+
+// storeHPAGeneration stores the generation of the HPA unless a newer one is already stored
+func (r *ScaledObjectReconciler) storeHPAGeneration(scaledObject *kedav1alpha1.ScaledObject, hpa *autoscalingv2.HorizontalPodAutoscaler) {
+	key, err := cache.MetaNamespaceKeyFunc(scaledObject)
+	if err != nil {
+		return
+	}
+	if value, loaded := r.hpaGenerations.Load(key); loaded && value.(int64) > hpa.Generation {
+		return
+	}
+	r.hpaGenerations.Store(key, hpa.Generation)
+}
+
+func (r *ScaledObjectReconciler) forgetHPAGeneration(scaledObject *kedav1alpha1.ScaledObject) {
+	if key, err := cache.MetaNamespaceKeyFunc(scaledObject); err == nil {
+		r.hpaGenerations.Delete(key)
+	}
+}
+
 // deleteAndCreateHpa delete old HPA and create new one
 func (r *ScaledObjectReconciler) renameHPA(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, foundHpa *autoscalingv2.HorizontalPodAutoscaler, gvkr *kedav1alpha1.GroupVersionKindResource) error {
 	logger.Info("Deleting old HPA", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", foundHpa.Name)
@@ -282,8 +394,5 @@ func getHPAMinReplicas(scaledObject *kedav1alpha1.ScaledObject) *int32 {
 
 // getHPAMaxReplicas returns MaxReplicas based on definition in ScaledObject or default value if not defined
 func getHPAMaxReplicas(scaledObject *kedav1alpha1.ScaledObject) int32 {
-	if scaledObject.Spec.MaxReplicaCount != nil {
-		return *scaledObject.Spec.MaxReplicaCount
-	}
-	return defaultHPAMaxReplicas
+	return scaledObject.GetMaxReplicaCount()
 }
@@ -18,19 +18,34 @@ package keda
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	v2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
 	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_scaling"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling"
 	"github.com/kedacore/keda/v2/pkg/scaling/cache"
 )
 
@@ -118,8 +133,306 @@ var _ = Describe("hpa", func() {
 		Expect(capturedScaledObject.Status.Health).To(Equal(expectedHealth))
 	})
 
+	Context("HPA changes made outside of KEDA", func() {
+		var (
+			recorder *record.FakeRecorder
+			gvkr     *v1alpha1.GroupVersionKindResource
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			recorder = record.NewFakeRecorder(1)
+			reconciler.Scheme = scheme
+			reconciler.Recorder = recorder
+			reconciler.hpaGenerations = &sync.Map{}
+			gvkr = &v1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}
+			client.EXPECT().Status().Return(statusWriter).AnyTimes()
+			statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		})
+
+		newFoundHPA := func(scaledObject *v1alpha1.ScaledObject, generation int64) *v2.HorizontalPodAutoscaler {
+			hpa, err := reconciler.newHPAForScaledObject(context.Background(), logger, scaledObject, gvkr)
+			Expect(err).ToNot(HaveOccurred())
+			hpa.Generation = generation
+			scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{{External: &v2.ExternalMetricSource{Metric: v2.MetricIdentifier{Name: "some metric name"}}}})
+			scaleHandler.EXPECT().GetScalersCache(gomock.Any(), gomock.Any()).Return(&cache.ScalersCache{Scalers: []cache.ScalerBuilder{{Scaler: scaler}}}, nil)
+			return hpa
+		}
+
+		It("reverts a manual change of the HPA and emits an event naming the fields", func() {
+			scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+			scaledObject.Spec.ScaleTargetRef = &v1alpha1.ScaleTarget{Name: "some deployment name"}
+			reconciler.storeHPAGeneration(scaledObject, &v2.HorizontalPodAutoscaler{ObjectMeta: v1.ObjectMeta{Generation: 2}})
+			foundHpa := newFoundHPA(scaledObject, 3)
+			foundHpa.Spec.MaxReplicas = 5
+
+			var updatedHpa *v2.HorizontalPodAutoscaler
+			client.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj runtimeclient.Object, _ ...runtimeclient.UpdateOption) error {
+				updatedHpa = obj.(*v2.HorizontalPodAutoscaler)
+				updatedHpa.Generation = 4
+				return nil
+			})
+
+			Expect(reconciler.updateHPAIfNeeded(context.Background(), logger, scaledObject, foundHpa, gvkr)).To(Succeed())
+			Expect(updatedHpa.Spec.MaxReplicas).To(Equal(int32(100)))
+			Expect(recorder.Events).To(Receive(ContainSubstring("spec.maxReplicas")))
+			Expect(reconciler.hpaGenerationChanged(scaledObject, updatedHpa)).To(BeFalse())
+		})
+
+		It("updates the HPA for a ScaledObject change without an event", func() {
+			scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+			scaledObject.Spec.ScaleTargetRef = &v1alpha1.ScaleTarget{Name: "some deployment name"}
+			foundHpa := newFoundHPA(scaledObject, 2)
+			reconciler.storeHPAGeneration(scaledObject, foundHpa)
+			maxReplicaCount := int32(10)
+			scaledObject.Spec.MaxReplicaCount = &maxReplicaCount
+
+			client.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+			Expect(reconciler.updateHPAIfNeeded(context.Background(), logger, scaledObject, foundHpa, gvkr)).To(Succeed())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("skips the update of an HPA already matching the ScaledObject", func() {
+			scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+			scaledObject.Namespace = "hpa-unchanged"
+			scaledObject.Spec.ScaleTargetRef = &v1alpha1.ScaleTarget{Name: "some deployment name"}
+			foundHpa := newFoundHPA(scaledObject, 2)
+			foundHpa.ResourceVersion = "4242"
+			foundHpa.ManagedFields = []v1.ManagedFieldsEntry{{Manager: "keda", Operation: v1.ManagedFieldsOperationUpdate}}
+			foundHpa.Status = v2.HorizontalPodAutoscalerStatus{CurrentReplicas: 1, DesiredReplicas: 1}
+
+			// the mock client fails the test on any Update
+			Expect(reconciler.updateHPAIfNeeded(context.Background(), logger, scaledObject, foundHpa, gvkr)).To(Succeed())
+
+			maxReplicaCount := int32(10)
+			scaledObject.Spec.MaxReplicaCount = &maxReplicaCount
+			scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{{External: &v2.ExternalMetricSource{Metric: v2.MetricIdentifier{Name: "some metric name"}}}})
+			scaleHandler.EXPECT().GetScalersCache(gomock.Any(), gomock.Any()).Return(&cache.ScalersCache{Scalers: []cache.ScalerBuilder{{Scaler: scaler}}}, nil)
+			client.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			Expect(reconciler.updateHPAIfNeeded(context.Background(), logger, scaledObject, foundHpa, gvkr)).To(Succeed())
+
+			Expect(hpaUpdateCount("hpa-unchanged", prommetrics.HPAUpdateResultSkipped)).To(Equal(float64(1)))
+			Expect(hpaUpdateCount("hpa-unchanged", prommetrics.HPAUpdateResultPerformed)).To(Equal(float64(1)))
+		})
+
+		It("counts the HPA updates rejected for changing an immutable field", func() {
+			scaledObject := setupTest(map[string]v1alpha1.HealthStatus{}, scaler, scaleHandler)
+			scaledObject.Namespace = "hpa-immutable"
+			scaledObject.Spec.ScaleTargetRef = &v1alpha1.ScaleTarget{Name: "some deployment name"}
+			hpaGroupKind := schema.GroupKind{Group: "autoscaling", Kind: "HorizontalPodAutoscaler"}
+			errs := []error{
+				apierrors.NewInvalid(hpaGroupKind, "keda-hpa", field.ErrorList{field.Invalid(field.NewPath("spec", "scaleTargetRef", "kind"), "StatefulSet", apimachineryvalidation.FieldImmutableErrorMsg)}),
+				apierrors.NewInvalid(hpaGroupKind, "keda-hpa", field.ErrorList{field.Invalid(field.NewPath("spec", "maxReplicas"), -1, "must be greater than 0")}),
+				apierrors.NewConflict(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "keda-hpa", errors.New("the object has been modified")),
+			}
+			foundHpa := newFoundHPA(scaledObject, 2)
+			foundHpa.Spec.MaxReplicas = 5
+			for i, err := range errs {
+				if i > 0 {
+					scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{{External: &v2.ExternalMetricSource{Metric: v2.MetricIdentifier{Name: "some metric name"}}}})
+					scaleHandler.EXPECT().GetScalersCache(gomock.Any(), gomock.Any()).Return(&cache.ScalersCache{Scalers: []cache.ScalerBuilder{{Scaler: scaler}}}, nil)
+				}
+				client.EXPECT().Update(gomock.Any(), gomock.Any()).Return(err)
+				Expect(reconciler.updateHPAIfNeeded(context.Background(), logger, scaledObject, foundHpa.DeepCopy(), gvkr)).To(MatchError(err))
+			}
+
+			expected := `
+# HELP keda_scaledobject_hpa_immutable_errors_total Total number of HPA updates of the scaled object rejected because they changed an immutable field
+# TYPE keda_scaledobject_hpa_immutable_errors_total counter
+keda_scaledobject_hpa_immutable_errors_total{namespace="hpa-immutable",scaledObject="some scaled object name"} 1
+`
+			Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_hpa_immutable_errors_total")).To(Succeed())
+		})
+	})
+
+	Context("getHPASpecDrift", func() {
+		var desired v2.HorizontalPodAutoscalerSpec
+
+		BeforeEach(func() {
+			minReplicas := int32(1)
+			stabilizationWindow := int32(60)
+			desired = v2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: v2.CrossVersionObjectReference{Name: "deployment", Kind: "Deployment", APIVersion: "apps/v1"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    10,
+				Metrics: []v2.MetricSpec{{
+					Type: v2.ExternalMetricSourceType,
+					External: &v2.ExternalMetricSource{
+						Metric: v2.MetricIdentifier{Name: "s0-metric"},
+						Target: v2.MetricTarget{Type: v2.AverageValueMetricType},
+					},
+				}},
+				Behavior: &v2.HorizontalPodAutoscalerBehavior{
+					ScaleDown: &v2.HPAScalingRules{StabilizationWindowSeconds: &stabilizationWindow},
+				},
+			}
+		})
+
+		It("doesn't report fields defaulted by the API server", func() {
+			found := desired.DeepCopy()
+			selectPolicy := v2.MaxChangePolicySelect
+			found.Behavior.ScaleDown.SelectPolicy = &selectPolicy
+			found.Behavior.ScaleUp = &v2.HPAScalingRules{SelectPolicy: &selectPolicy}
+
+			Expect(getHPASpecDrift(desired, *found)).To(BeEmpty())
+		})
+
+		It("reports changed min and max replicas", func() {
+			found := desired.DeepCopy()
+			minReplicas := int32(3)
+			found.MinReplicas = &minReplicas
+			found.MaxReplicas = 20
+
+			Expect(getHPASpecDrift(desired, *found)).To(Equal([]string{"spec.minReplicas", "spec.maxReplicas"}))
+		})
+
+		It("reports changed, added and removed metrics", func() {
+			changed := desired.DeepCopy()
+			changed.Metrics[0].External.Metric.Name = "s0-other"
+			Expect(getHPASpecDrift(desired, *changed)).To(Equal([]string{"spec.metrics"}))
+
+			added := desired.DeepCopy()
+			added.Metrics = append(added.Metrics, v2.MetricSpec{Type: v2.ResourceMetricSourceType, Resource: &v2.ResourceMetricSource{Name: "cpu"}})
+			Expect(getHPASpecDrift(desired, *added)).To(Equal([]string{"spec.metrics"}))
+
+			removed := desired.DeepCopy()
+			removed.Metrics = nil
+			Expect(getHPASpecDrift(desired, *removed)).To(Equal([]string{"spec.metrics"}))
+		})
+
+		It("reports changed, added and removed behavior", func() {
+			changed := desired.DeepCopy()
+			stabilizationWindow := int32(0)
+			changed.Behavior.ScaleDown.StabilizationWindowSeconds = &stabilizationWindow
+			Expect(getHPASpecDrift(desired, *changed)).To(Equal([]string{"spec.behavior"}))
+
+			removed := desired.DeepCopy()
+			removed.Behavior = nil
+			Expect(getHPASpecDrift(desired, *removed)).To(Equal([]string{"spec.behavior"}))
+
+			withoutBehavior := desired.DeepCopy()
+			withoutBehavior.Behavior = nil
+			Expect(getHPASpecDrift(*withoutBehavior, desired)).To(Equal([]string{"spec.behavior"}))
+		})
+
+		It("compares the metric targets by value whatever their format", func() {
+			for _, target := range [][2]string{{"500m", "0.5"}, {"1", "1000m"}, {"1500", "1.5k"}, {"2Gi", "2147483648"}} {
+				desiredTarget := resource.MustParse(target[0])
+				desired.Metrics[0].External.Target.AverageValue = &desiredTarget
+				found := desired.DeepCopy()
+				foundTarget := resource.MustParse(target[1])
+				found.Metrics[0].External.Target.AverageValue = &foundTarget
+
+				Expect(getHPASpecDrift(desired, *found)).To(BeEmpty(), "%s and %s are the same target", target[0], target[1])
+			}
+
+			desiredTarget := resource.MustParse("500m")
+			desired.Metrics[0].External.Target.AverageValue = &desiredTarget
+			changed := desired.DeepCopy()
+			changedTarget := resource.MustParse("0.6")
+			changed.Metrics[0].External.Target.AverageValue = &changedTarget
+			Expect(getHPASpecDrift(desired, *changed)).To(Equal([]string{"spec.metrics"}))
+		})
+	})
+
+	Context("getHPADrift", func() {
+		var desired *v2.HorizontalPodAutoscaler
+
+		BeforeEach(func() {
+			target := resource.MustParse("500m")
+			desired = &v2.HorizontalPodAutoscaler{
+				ObjectMeta: v1.ObjectMeta{
+					Name:   "keda-hpa-orders",
+					Labels: map[string]string{"app.kubernetes.io/managed-by": "keda-operator"},
+				},
+				Spec: v2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: v2.CrossVersionObjectReference{Name: "orders", Kind: "Deployment", APIVersion: "apps/v1"},
+					MaxReplicas:    10,
+					Metrics: []v2.MetricSpec{{
+						Type: v2.ExternalMetricSourceType,
+						External: &v2.ExternalMetricSource{
+							Metric: v2.MetricIdentifier{Name: "s0-metric"},
+							Target: v2.MetricTarget{Type: v2.AverageValueMetricType, AverageValue: &target},
+						},
+					}},
+				},
+			}
+		})
+
+		It("ignores the fields populated by the API server", func() {
+			found := desired.DeepCopy()
+			found.ResourceVersion = "4242"
+			found.Generation = 7
+			found.UID = "uid"
+			found.ManagedFields = []v1.ManagedFieldsEntry{{Manager: "keda", Operation: v1.ManagedFieldsOperationUpdate}}
+			found.Labels["added-by-another-controller"] = "true"
+			found.Status = v2.HorizontalPodAutoscalerStatus{CurrentReplicas: 3, DesiredReplicas: 4}
+			target := resource.MustParse("0.5")
+			found.Spec.Metrics[0].External.Target.AverageValue = &target
+
+			Expect(getHPADrift(desired, found)).To(BeEmpty())
+		})
+
+		It("reports changed labels and spec fields together", func() {
+			found := desired.DeepCopy()
+			found.Labels["app.kubernetes.io/managed-by"] = "someone-else"
+			found.Spec.MaxReplicas = 5
+
+			Expect(getHPADrift(desired, found)).To(Equal([]string{"spec.maxReplicas", "metadata.labels"}))
+			Expect(getHPADiff(desired, found)).To(And(ContainSubstring("MaxReplicas"), ContainSubstring("someone-else")))
+		})
+	})
+
+	Context("ScaledObject with a failed trigger", func() {
+		var scaledObject *v1alpha1.ScaledObject
+
+		BeforeEach(func() {
+			scaledObject = &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "partial"}}
+			scaleHandler.EXPECT().GetScalersCache(gomock.Any(), gomock.Any()).Return(&cache.ScalersCache{
+				Scalers:        []cache.ScalerBuilder{{Scaler: scaler}},
+				FailedTriggers: []cache.FailedTrigger{{Index: 1, Type: "prometheus", Err: errors.New("no serverAddress given")}},
+			}, nil).AnyTimes()
+		})
+
+		AfterEach(func() {
+			scaling.SetAllowPartialHPA(false)
+		})
+
+		It("reports the failed trigger with partial HPAs", func() {
+			scaling.SetAllowPartialHPA(true)
+			Expect(reconciler.getFailedTriggersMessage(context.Background(), scaledObject)).To(
+				Equal("ScaledObject is ready for scaling on part of its triggers, failed trigger 1 (prometheus): no serverAddress given"))
+		})
+
+		It("doesn't report anything without partial HPAs", func() {
+			Expect(reconciler.getFailedTriggersMessage(context.Background(), scaledObject)).To(BeEmpty())
+		})
+	})
 })
 
+// hpaUpdateCount returns the number of skipped or performed HPA updates of the scaled objects of the namespace
+func hpaUpdateCount(namespace, result string) float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_hpa_updates_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["result"] == result {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
 func setupTest(health map[string]v1alpha1.HealthStatus, scaler *mock_scalers.MockScaler, scaleHandler *mock_scaling.MockScaleHandler) *v1alpha1.ScaledObject {
 	scaledObject := &v1alpha1.ScaledObject{
 		ObjectMeta: v1.ObjectMeta{
@@ -41,6 +41,7 @@ import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/prommetrics"
+	"github.com/kedacore/keda/v2/pkg/scalers"
 	"github.com/kedacore/keda/v2/pkg/scaling"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
@@ -80,6 +81,8 @@ func init() {
 func (r *ScaledJobReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	r.scaleHandler = scaling.NewScaleHandler(mgr.GetClient(), nil, mgr.GetScheme(), r.GlobalHTTPTimeout, mgr.GetEventRecorderFor("scale-handler"), r.SecretsLister)
 	r.scaledJobGenerations = &sync.Map{}
+	// the ScaledJobs aren't reconciled on changes of the scaling defaults, as a reconcile deletes the jobs of the
+	// previous version. Their scale loop applies the new defaults on the next poll, but for the pollingInterval
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
 		// Ignore updates to ScaledJob Status (in this case metadata.Generation does not change)
@@ -166,6 +169,12 @@ func (r *ScaledJobReconciler) reconcileScaledJob(ctx context.Context, logger log
 		return msg, err
 	}
 
+	clamps, err := scaling.ApplyScalingDefaults(ctx, scaledJob)
+	if err != nil {
+		return "ScaledJob scaling defaults can't be applied", err
+	}
+	setCappedCondition(&scaledJob.Status.Conditions, clamps)
+
 	// Check ScaledJob is Ready or not
 	_, err = r.scaleHandler.GetScalersCache(ctx, scaledJob)
 	if err != nil {
@@ -184,6 +193,11 @@ func (r *ScaledJobReconciler) reconcileScaledJob(ctx context.Context, logger log
 		}
 	}
 
+	if err := scaledJob.ValidateJobNamePrefix(); err != nil {
+		logger.Error(err, "jobNamePrefix of the ScaledJob is not valid")
+		return "ScaledJob.Spec.JobNamePrefix is not valid", err
+	}
+
 	// scaledJob was created or modified - let's start a new ScaleLoop
 	err = r.requestScaleLoop(ctx, logger, scaledJob)
 	if err != nil {
@@ -293,6 +307,9 @@ func (r *ScaledJobReconciler) updatePromMetrics(scaledJob *kedav1alpha1.ScaledJo
 	triggerTypes := make([]string, len(scaledJob.Spec.Triggers))
 	for _, trigger := range scaledJob.Spec.Triggers {
 		prommetrics.IncrementTriggerTotal(trigger.Type)
+		for _, field := range scalers.GetDeprecatedTriggerFields(trigger.Type, trigger.Metadata) {
+			prommetrics.RecordTriggerDeprecatedFieldUsage(trigger.Type, field)
+		}
 		triggerTypes = append(triggerTypes, trigger.Type)
 	}
 	metricsData.triggerTypes = triggerTypes
@@ -25,6 +25,7 @@ import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/controllers/keda/util"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 )
 
 const (
@@ -51,6 +52,7 @@ func (r *ScaledJobReconciler) finalizeScaledJob(ctx context.Context, logger logr
 		}
 
 		r.updatePromMetricsOnDelete(namespacedName)
+		prommetrics.DeleteScaledJobAccurateBacklog(scaledJob.Namespace, scaledJob.Name)
 	}
 
 	logger.Info("Successfully finalized ScaledJob")
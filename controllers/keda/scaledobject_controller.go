@@ -19,6 +19,7 @@ package keda
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/go-logr/logr"
@@ -38,14 +39,19 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/fallback"
 	"github.com/kedacore/keda/v2/pkg/prommetrics"
+	"github.com/kedacore/keda/v2/pkg/scalers"
 	"github.com/kedacore/keda/v2/pkg/scaling"
+	"github.com/kedacore/keda/v2/pkg/scaling/executor"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
@@ -55,6 +61,8 @@ import (
 // +kubebuilder:rbac:groups="",resources=pods;services;services;secrets;external,verbs=get;list;watch
 // +kubebuilder:rbac:groups="*",resources="*/scale",verbs="*"
 // +kubebuilder:rbac:groups="",resources="serviceaccounts",verbs=list;watch
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs=list;watch
+// +kubebuilder:rbac:groups="",resources="nodes",verbs=get
 // +kubebuilder:rbac:groups="*",resources="*",verbs=get
 // +kubebuilder:rbac:groups="apps",resources=deployments;statefulsets,verbs=list;watch
 // +kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs="*"
@@ -69,11 +77,20 @@ type ScaledObjectReconciler struct {
 
 	restMapper               meta.RESTMapper
 	scaledObjectsGenerations *sync.Map
+	// scaledObjectsPollingIntervals stores the polling interval of the scale loop of each ScaledObject, the scaling
+	// defaults can change it without a new generation
+	scaledObjectsPollingIntervals *sync.Map
+	// hpaGenerations stores the generation of the HPA of each ScaledObject last written or checked by KEDA
+	hpaGenerations *sync.Map
+	// fallbacksValid stores whether the fallback of each ScaledObject was valid in its last reconcile
+	fallbacksValid *sync.Map
 }
 
 type scaledObjectMetricsData struct {
 	namespace    string
 	triggerTypes []string
+	// pausedReplicas is the replica count of the paused-replicas annotation, nil if the ScaledObject isn't paused
+	pausedReplicas *int32
 }
 
 var (
@@ -98,6 +115,9 @@ func init() {
 func (r *ScaledObjectReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	r.restMapper = mgr.GetRESTMapper()
 	r.scaledObjectsGenerations = &sync.Map{}
+	r.scaledObjectsPollingIntervals = &sync.Map{}
+	r.hpaGenerations = &sync.Map{}
+	r.fallbacksValid = &sync.Map{}
 
 	if r.ScaleHandler == nil {
 		return fmt.Errorf("ScaledObjectReconciler.ScaleHandler is not initialized")
@@ -115,7 +135,7 @@ func (r *ScaledObjectReconciler) SetupWithManager(mgr ctrl.Manager, options cont
 		return fmt.Errorf("ScaledObjectReconciler.Recorder is not initialized")
 	}
 	// Start controller
-	return ctrl.NewControllerManagedBy(mgr).
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
 		// predicate.GenerationChangedPredicate{} ignore updates to ScaledObject Status
 		// (in this case metadata.Generation does not change)
@@ -123,12 +143,19 @@ func (r *ScaledObjectReconciler) SetupWithManager(mgr ctrl.Manager, options cont
 		For(&kedav1alpha1.ScaledObject{}, builder.WithPredicates(
 			predicate.Or(
 				kedacontrollerutil.PausedReplicasPredicate{},
+				kedacontrollerutil.DryRunPredicate{},
 				kedacontrollerutil.ScaleObjectReadyConditionPredicate{},
 				predicate.GenerationChangedPredicate{},
 			),
 		)).
-		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
-		Complete(r)
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{})
+	if scaling.GetScalingDefaultsNamespace() != "" {
+		// the cache of the manager only holds the scaling defaults ConfigMaps when they are enabled
+		controllerBuilder = controllerBuilder.Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			return scalingDefaultsRequests(ctx, r.Client, obj)
+		}))
+	}
+	return controllerBuilder.Complete(r)
 }
 
 // Reconcile performs reconciliation on the identified ScaledObject resource based on the request information passed, returns the result and an error (if any).
@@ -186,12 +213,17 @@ func (r *ScaledObjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			r.Recorder.Event(scaledObject, corev1.EventTypeNormal, eventreason.ScaledObjectReady, "ScaledObject is ready for scaling")
 		}
 		reqLogger.V(1).Info(msg)
-		conditions.SetReadyCondition(metav1.ConditionTrue, kedav1alpha1.ScaledObjectConditionReadySucccesReason, msg)
+		if failedMsg := r.getFailedTriggersMessage(ctx, scaledObject); failedMsg != "" {
+			conditions.SetReadyCondition(metav1.ConditionTrue, kedav1alpha1.ScaledObjectConditionReadyPartialReason, failedMsg)
+		} else {
+			conditions.SetReadyCondition(metav1.ConditionTrue, kedav1alpha1.ScaledObjectConditionReadySucccesReason, msg)
+		}
 	}
 
 	if err := kedautil.SetStatusConditions(ctx, r.Client, reqLogger, scaledObject, &conditions); err != nil {
 		return ctrl.Result{}, err
 	}
+	r.updateConditionPromMetrics(scaledObject, &conditions, req.NamespacedName.String())
 
 	return ctrl.Result{}, err
 }
@@ -210,22 +242,48 @@ func (r *ScaledObjectReconciler) reconcileScaledObject(ctx context.Context, logg
 		return "Failed to update ScaledObject with scaledObjectName label", err
 	}
 
+	// the defaults are applied once the ScaledObject isn't updated anymore, they must not be stored in its spec
+	clamps, err := scaling.ApplyScalingDefaults(ctx, scaledObject)
+	if err != nil {
+		return "ScaledObject scaling defaults can't be applied", err
+	}
+	setCappedCondition(&scaledObject.Status.Conditions, clamps)
+
 	// Check if resource targeted for scaling exists and exposes /scale subresource
 	gvkr, err := r.checkTargetResourceIsScalable(ctx, logger, scaledObject)
 	if err != nil {
 		return "ScaledObject doesn't have correct scaleTargetRef specification", err
 	}
+	prommetrics.RecordScaledObjectTargetKind(scaledObject.Namespace, scaledObject.Name, gvkr.Kind)
 
 	err = r.checkReplicaCountBoundsAreValid(scaledObject)
 	if err != nil {
 		return "ScaledObject doesn't have correct Idle/Min/Max Replica Counts specification", err
 	}
+	prommetrics.RecordScaledObjectIdleReplicas(scaledObject.Namespace, scaledObject.Name, scaledObject.Spec.IdleReplicaCount)
 
 	err = r.checkTriggers(logger, scaledObject)
 	if err != nil {
 		return "ScaledObject doesn't have correct triggers specification", err
 	}
 
+	if valid, changed := r.updateFallbackValidity(scaledObject); changed {
+		if valid {
+			logger.Info("ScaledObject fallback is valid again")
+		} else {
+			logger.Info("ScaledObject fallback is invalid and will be ignored, it requires non-negative failureThreshold and replicas and triggers with metricType AverageValue")
+		}
+	}
+
+	if kedacontrollerutil.IsDryRun(scaledObject) {
+		return r.reconcileScaledObjectWithoutHPA(ctx, logger, scaledObject, eventreason.DryRunHPADeleted, "dry-run mode")
+	}
+	prommetrics.DeleteScaledObjectDryRunDesiredReplicas(scaledObject.Namespace, scaledObject.Name)
+
+	if scaledObject.IsActivationOnly() {
+		return r.reconcileScaledObjectWithoutHPA(ctx, logger, scaledObject, eventreason.ActivationOnlyHPADeleted, "activation only mode")
+	}
+
 	// Create a new HPA or update existing one according to ScaledObject
 	newHPACreated, err := r.ensureHPAForScaledObjectExists(ctx, logger, scaledObject, &gvkr)
 	if err != nil {
@@ -252,6 +310,43 @@ func (r *ScaledObjectReconciler) reconcileScaledObject(ctx context.Context, logg
 	return kedav1alpha1.ScaledObjectConditionReadySuccessMessage, nil
 }
 
+// getFailedTriggersMessage returns the message of the Ready condition of a ScaledObject scaled on part of its
+// triggers, listing the indexes of the failed triggers, or an empty string if all of them are scaled on
+func (r *ScaledObjectReconciler) getFailedTriggersMessage(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) string {
+	if !scaling.IsPartialHPAAllowed() {
+		return ""
+	}
+	scalersCache, err := r.ScaleHandler.GetScalersCache(ctx, scaledObject.DeepCopy())
+	if err != nil || len(scalersCache.FailedTriggers) == 0 {
+		return ""
+	}
+	failed := make([]string, 0, len(scalersCache.FailedTriggers))
+	for _, trigger := range scalersCache.FailedTriggers {
+		failed = append(failed, fmt.Sprintf("trigger %d (%s): %s", trigger.Index, trigger.Type, trigger.Err))
+	}
+	return fmt.Sprintf("ScaledObject is ready for scaling on part of its triggers, failed %s", strings.Join(failed, "; "))
+}
+
+// reconcileScaledObjectWithoutHPA makes sure there is no HPA for the ScaledObject in dry-run or activation only mode,
+// the scale loop still runs to report what KEDA would do or to activate and deactivate the scale target
+func (r *ScaledObjectReconciler) reconcileScaledObjectWithoutHPA(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, hpaDeletedReason, mode string) (string, error) {
+	if err := r.ensureHPAForScaledObjectDeleted(ctx, logger, scaledObject, hpaDeletedReason, mode); err != nil {
+		return fmt.Sprintf("Failed to delete HPA of ScaledObject in %s", mode), err
+	}
+
+	scaleObjectSpecChanged, err := r.scaledObjectGenerationChanged(logger, scaledObject)
+	if err != nil {
+		return "Failed to check whether ScaledObject's Generation was changed", err
+	}
+	if scaleObjectSpecChanged {
+		if err := r.requestScaleLoop(ctx, logger, scaledObject); err != nil {
+			return "Failed to start a new scale loop with scaling logic", err
+		}
+		logger.Info(fmt.Sprintf("Initializing Scaling logic according to ScaledObject Specification in %s", mode))
+	}
+	return kedav1alpha1.ScaledObjectConditionReadySuccessMessage, nil
+}
+
 // ensureScaledObjectLabel ensures that scaledobject.keda.sh/name=<scaledObject.Name> label exist in the ScaledObject
 // This is how the MetricsAdapter will know which ScaledObject a metric is for when the HPA queries it.
 func (r *ScaledObjectReconciler) ensureScaledObjectLabel(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) error {
@@ -274,6 +369,9 @@ func (r *ScaledObjectReconciler) checkTargetResourceIsScalable(ctx context.Conte
 	gvkr, err := kedav1alpha1.ParseGVKR(r.restMapper, scaledObject.Spec.ScaleTargetRef.APIVersion, scaledObject.Spec.ScaleTargetRef.Kind)
 	if err != nil {
 		logger.Error(err, "Failed to parse Group, Version, Kind, Resource", "apiVersion", scaledObject.Spec.ScaleTargetRef.APIVersion, "kind", scaledObject.Spec.ScaleTargetRef.Kind)
+		if meta.IsNoMatchError(err) {
+			prommetrics.RecordScaledObjectReconcileDeferred(scaledObject.Namespace, prommetrics.ReconcileDeferredReasonScaleTargetKindNotFound)
+		}
 		return gvkr, err
 	}
 	gvkString := gvkr.GVKString()
@@ -300,6 +398,9 @@ func (r *ScaledObjectReconciler) checkTargetResourceIsScalable(ctx context.Conte
 			if err := r.Client.Get(ctx, client.ObjectKey{Namespace: scaledObject.Namespace, Name: scaledObject.Spec.ScaleTargetRef.Name}, unstruct); err != nil {
 				// resource doesn't exist
 				logger.Error(err, "Target resource doesn't exist", "resource", gvkString, "name", scaledObject.Spec.ScaleTargetRef.Name)
+				if errors.IsNotFound(err) {
+					prommetrics.RecordScaledObjectReconcileDeferred(scaledObject.Namespace, prommetrics.ReconcileDeferredReasonScaleTargetNotFound)
+				}
 				return gvkr, err
 			}
 			// resource exist but doesn't expose /scale subresource
@@ -438,6 +539,33 @@ func (r *ScaledObjectReconciler) ensureHPAForScaledObjectExists(ctx context.Cont
 	return false, nil
 }
 
+// ensureHPAForScaledObjectDeleted deletes the HPA of the ScaledObject if there is one, the event with the given reason
+// tells the mode the ScaledObject doesn't need an HPA in
+func (r *ScaledObjectReconciler) ensureHPAForScaledObjectDeleted(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, reason, mode string) error {
+	hpaName := scaledObject.Status.HpaName
+	if hpaName == "" {
+		hpaName = getHPAName(scaledObject)
+	}
+	foundHpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: hpaName, Namespace: scaledObject.Namespace}, foundHpa)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		logger.Error(err, "Failed to get HPA from cluster")
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Deleting HPA of ScaledObject in %s", mode), "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+	if err := r.Client.Delete(ctx, foundHpa); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to delete HPA", "HPA.Namespace", foundHpa.Namespace, "HPA.Name", foundHpa.Name)
+		return err
+	}
+	r.forgetHPAGeneration(scaledObject)
+	r.Recorder.Eventf(scaledObject, corev1.EventTypeNormal, reason, "Deleted HPA %s/%s, it isn't used in %s", foundHpa.Namespace, foundHpa.Name, mode)
+	return nil
+}
+
 func isHpaRenamed(scaledObject *kedav1alpha1.ScaledObject, foundHpa *autoscalingv2.HorizontalPodAutoscaler) bool {
 	// if HPA name defined in SO -> check if equals to the found HPA
 	if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig != nil && scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Name != "" {
@@ -463,6 +591,7 @@ func (r *ScaledObjectReconciler) requestScaleLoop(ctx context.Context, logger lo
 
 	// store ScaledObject's current Generation
 	r.scaledObjectsGenerations.Store(key, scaledObject.Generation)
+	r.scaledObjectsPollingIntervals.Store(key, getPollingIntervalSeconds(scaledObject))
 
 	return nil
 }
@@ -480,10 +609,26 @@ func (r *ScaledObjectReconciler) stopScaleLoop(ctx context.Context, logger logr.
 	}
 	// delete ScaledObject's current Generation
 	r.scaledObjectsGenerations.Delete(key)
+	r.scaledObjectsPollingIntervals.Delete(key)
+	r.hpaGenerations.Delete(key)
+	r.fallbacksValid.Delete(key)
 	return nil
 }
 
-// scaledObjectGenerationChanged returns true if ScaledObject's Generation was changed, ie. ScaledObject.Spec was changed
+// updateFallbackValidity records whether the fallback of the ScaledObject is valid, changed is true when the validity
+// differs from the last reconcile or when the first reconcile finds it invalid
+func (r *ScaledObjectReconciler) updateFallbackValidity(scaledObject *kedav1alpha1.ScaledObject) (valid bool, changed bool) {
+	valid = fallback.CheckFallbackValid(scaledObject)
+	prommetrics.RecordScaledObjectFallbackInvalid(scaledObject.Namespace, scaledObject.Name, !valid)
+
+	previous, loaded := r.fallbacksValid.Swap(scaledObject.Namespace+"/"+scaledObject.Name, valid)
+	if !loaded {
+		return valid, !valid
+	}
+	return valid, previous.(bool) != valid
+}
+
+// scaledObjectGenerationChanged returns true if ScaledObject's Generation was changed, ie. ScaledObject.Spec was changed, or if the scaling defaults changed its polling interval
 func (r *ScaledObjectReconciler) scaledObjectGenerationChanged(logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) (bool, error) {
 	key, err := cache.MetaNamespaceKeyFunc(scaledObject)
 	if err != nil {
@@ -495,12 +640,21 @@ func (r *ScaledObjectReconciler) scaledObjectGenerationChanged(logger logr.Logge
 	if loaded {
 		generation := value.(int64)
 		if generation == scaledObject.Generation {
-			return false, nil
+			pollingInterval, _ := r.scaledObjectsPollingIntervals.Load(key)
+			return pollingInterval != getPollingIntervalSeconds(scaledObject), nil
 		}
 	}
 	return true, nil
 }
 
+// getPollingIntervalSeconds returns the pollingInterval of the ScaledObject, 0 when it isn't set
+func getPollingIntervalSeconds(scaledObject *kedav1alpha1.ScaledObject) int32 {
+	if scaledObject.Spec.PollingInterval == nil {
+		return 0
+	}
+	return *scaledObject.Spec.PollingInterval
+}
+
 func (r *ScaledObjectReconciler) updatePromMetrics(scaledObject *kedav1alpha1.ScaledObject, namespacedName string) {
 	scaledObjectPromMetricsLock.Lock()
 	defer scaledObjectPromMetricsLock.Unlock()
@@ -520,11 +674,17 @@ func (r *ScaledObjectReconciler) updatePromMetrics(scaledObject *kedav1alpha1.Sc
 	triggerTypes := make([]string, len(scaledObject.Spec.Triggers))
 	for _, trigger := range scaledObject.Spec.Triggers {
 		prommetrics.IncrementTriggerTotal(trigger.Type)
+		for _, field := range scalers.GetDeprecatedTriggerFields(trigger.Type, trigger.Metadata) {
+			prommetrics.RecordTriggerDeprecatedFieldUsage(trigger.Type, field)
+		}
 		triggerTypes = append(triggerTypes, trigger.Type)
 	}
 	metricsData.triggerTypes = triggerTypes
+	// an invalid annotation is reported by the reconciliation, the ScaledObject isn't counted as paused meanwhile
+	metricsData.pausedReplicas, _ = executor.GetPausedReplicaCount(scaledObject)
 
 	scaledObjectPromMetricsMap[namespacedName] = metricsData
+	prommetrics.RecordScaledObjectsPausedAtReplicas(getPausedReplicas(scaledObjectPromMetricsMap))
 }
 
 func (r *ScaledObjectReconciler) updatePromMetricsOnDelete(namespacedName string) {
@@ -539,4 +699,29 @@ func (r *ScaledObjectReconciler) updatePromMetricsOnDelete(namespacedName string
 	}
 
 	delete(scaledObjectPromMetricsMap, namespacedName)
+	prommetrics.RecordScaledObjectsPausedAtReplicas(getPausedReplicas(scaledObjectPromMetricsMap))
+}
+
+// updateConditionPromMetrics records the Ready and Active condition reasons of the ScaledObject, the scale loop
+// records the changes of the conditions it makes between the reconciliations
+func (r *ScaledObjectReconciler) updateConditionPromMetrics(scaledObject *kedav1alpha1.ScaledObject, conditions *kedav1alpha1.Conditions, namespacedName string) {
+	scaledObjectPromMetricsLock.Lock()
+	defer scaledObjectPromMetricsLock.Unlock()
+
+	// the ScaledObject being deleted isn't recorded again
+	if _, ok := scaledObjectPromMetricsMap[namespacedName]; !ok {
+		return
+	}
+	prommetrics.RecordScaledObjectConditions(scaledObject.Namespace, scaledObject.Name,
+		conditions.GetReadyCondition().Reason, conditions.GetActiveCondition().Reason)
+}
+
+func getPausedReplicas(metricsMap map[string]scaledObjectMetricsData) []int32 {
+	var replicas []int32
+	for _, metricsData := range metricsMap {
+		if metricsData.pausedReplicas != nil {
+			replicas = append(replicas, *metricsData.pausedReplicas)
+		}
+	}
+	return replicas
 }
@@ -19,12 +19,15 @@ package keda
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
@@ -33,10 +36,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_scaling"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/scalers"
 	"github.com/kedacore/keda/v2/pkg/scaling/cache"
 )
@@ -231,6 +236,68 @@ var _ = Describe("ScaledObjectController", func() {
 		})
 	})
 
+	Describe("Condition metrics", func() {
+		It("collects the replica counts of the paused ScaledObjects", func() {
+			paused := func(replicas int32) *int32 { return &replicas }
+			metricsMap := map[string]scaledObjectMetricsData{
+				"default/paused-zero": {pausedReplicas: paused(0)},
+				"default/paused-two":  {pausedReplicas: paused(2)},
+				"other/paused-two":    {pausedReplicas: paused(2)},
+				"other/paused-ten":    {pausedReplicas: paused(10)},
+				"default/running":     {namespace: "default"},
+			}
+
+			Expect(getPausedReplicas(metricsMap)).To(ConsistOf(int32(0), int32(2), int32(2), int32(10)))
+
+			delete(metricsMap, "other/paused-ten")
+			Expect(getPausedReplicas(metricsMap)).To(ConsistOf(int32(0), int32(2), int32(2)))
+		})
+	})
+
+	Describe("Fallback validity", func() {
+		It("reports the validity only when it changes", func() {
+			reconciler := ScaledObjectReconciler{fallbacksValid: &sync.Map{}}
+			so := &kedav1alpha1.ScaledObject{
+				ObjectMeta: metav1.ObjectMeta{Name: "fallback-validity", Namespace: "default"},
+				Spec: kedav1alpha1.ScaledObjectSpec{
+					Fallback: &kedav1alpha1.Fallback{FailureThreshold: -1, Replicas: 2},
+					Triggers: []kedav1alpha1.ScaleTriggers{{Type: "prometheus"}},
+				},
+			}
+			gatherFallbackInvalid := func(value int) error {
+				expected := fmt.Sprintf(`
+# HELP keda_scaledobject_fallback_invalid Whether the fallback configuration of the scaled object is present but invalid (1) or not (0)
+# TYPE keda_scaledobject_fallback_invalid gauge
+keda_scaledobject_fallback_invalid{namespace="default",scaledObject="fallback-validity"} %d
+`, value)
+				return testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_fallback_invalid")
+			}
+
+			valid, changed := reconciler.updateFallbackValidity(so)
+			Expect(valid).To(BeFalse())
+			Expect(changed).To(BeTrue())
+			Expect(gatherFallbackInvalid(1)).To(Succeed())
+
+			_, changed = reconciler.updateFallbackValidity(so)
+			Expect(changed).To(BeFalse())
+
+			so.Spec.Fallback.FailureThreshold = 3
+			valid, changed = reconciler.updateFallbackValidity(so)
+			Expect(valid).To(BeTrue())
+			Expect(changed).To(BeTrue())
+			Expect(gatherFallbackInvalid(0)).To(Succeed())
+
+			_, changed = reconciler.updateFallbackValidity(so)
+			Expect(changed).To(BeFalse())
+
+			// a valid fallback isn't reported on the first reconcile
+			other := so.DeepCopy()
+			other.Name = "fallback-validity-other"
+			_, changed = reconciler.updateFallbackValidity(other)
+			Expect(changed).To(BeFalse())
+		})
+	})
+
 	Describe("functional tests", func() {
 		It("cleans up a deleted trigger from the HPA", func() {
 			// Create the scaling target.
@@ -485,6 +552,192 @@ var _ = Describe("ScaledObjectController", func() {
 			}, 20*time.Second).Should(Equal(metav1.ConditionTrue))
 		})
 
+		It("counts the reconciles deferred until the scale target exists", func() {
+			deploymentName := "deferred"
+			soName := "so-" + deploymentName
+			deferred := reconcileDeferredCount("default", prommetrics.ReconcileDeferredReasonScaleTargetNotFound)
+
+			// Create the ScaledObject before its scaling target
+			so := &kedav1alpha1.ScaledObject{
+				ObjectMeta: metav1.ObjectMeta{Name: soName, Namespace: "default"},
+				Spec: kedav1alpha1.ScaledObjectSpec{
+					ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+						Name: deploymentName,
+					},
+					Triggers: []kedav1alpha1.ScaleTriggers{
+						{
+							Type: "cron",
+							Metadata: map[string]string{
+								"timezone":        "UTC",
+								"start":           "0 * * * *",
+								"end":             "1 * * * *",
+								"desiredReplicas": "1",
+							},
+						},
+					},
+				},
+			}
+			err := k8sClient.Create(context.Background(), so)
+			Ω(err).ToNot(HaveOccurred())
+
+			Eventually(func() float64 {
+				return reconcileDeferredCount("default", prommetrics.ReconcileDeferredReasonScaleTargetNotFound)
+			}, 20*time.Second).Should(BeNumerically(">", deferred))
+
+			Eventually(func() metav1.ConditionStatus {
+				err = k8sClient.Get(context.Background(), types.NamespacedName{Name: soName, Namespace: "default"}, so)
+				Ω(err).ToNot(HaveOccurred())
+				return so.Status.Conditions.GetReadyCondition().Status
+			}, 20*time.Second).Should(Equal(metav1.ConditionFalse))
+
+			// Create the scaling target, the deferred reconcile succeeds
+			err = k8sClient.Create(context.Background(), generateDeployment(deploymentName))
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() metav1.ConditionStatus {
+				err = k8sClient.Get(context.Background(), types.NamespacedName{Name: soName, Namespace: "default"}, so)
+				Ω(err).ToNot(HaveOccurred())
+				return so.Status.Conditions.GetReadyCondition().Status
+			}, 60*time.Second).Should(Equal(metav1.ConditionTrue))
+		})
+
+		It("doesn't create HPA in dry-run mode and creates it once dry-run is switched off", func() {
+			deploymentName := "dry-run"
+			soName := "so-" + deploymentName
+
+			// Create the scaling target.
+			err := k8sClient.Create(context.Background(), generateDeployment(deploymentName))
+			Expect(err).ToNot(HaveOccurred())
+
+			// Create the ScaledObject in dry-run mode
+			so := &kedav1alpha1.ScaledObject{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        soName,
+					Namespace:   "default",
+					Annotations: map[string]string{"autoscaling.keda.sh/dry-run": "true"},
+				},
+				Spec: kedav1alpha1.ScaledObjectSpec{
+					ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+						Name: deploymentName,
+					},
+					Triggers: []kedav1alpha1.ScaleTriggers{
+						{
+							Type: "cron",
+							Metadata: map[string]string{
+								"timezone":        "UTC",
+								"start":           "0 * * * *",
+								"end":             "1 * * * *",
+								"desiredReplicas": "1",
+							},
+						},
+					},
+				},
+			}
+			err = k8sClient.Create(context.Background(), so)
+			Ω(err).ToNot(HaveOccurred())
+
+			Eventually(func() metav1.ConditionStatus {
+				err = k8sClient.Get(context.Background(), types.NamespacedName{Name: soName, Namespace: "default"}, so)
+				Ω(err).ToNot(HaveOccurred())
+				return so.Status.Conditions.GetReadyCondition().Status
+			}, 20*time.Second).Should(Equal(metav1.ConditionTrue))
+
+			// the HPA isn't created in dry-run mode
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			Consistently(func() bool {
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: "keda-hpa-" + soName, Namespace: "default"}, hpa)
+				return errors.IsNotFound(err)
+			}, 2*time.Second).Should(BeTrue())
+
+			// Switch dry-run off
+			Eventually(func() error {
+				err = k8sClient.Get(context.Background(), types.NamespacedName{Name: soName, Namespace: "default"}, so)
+				Ω(err).ToNot(HaveOccurred())
+				delete(so.Annotations, "autoscaling.keda.sh/dry-run")
+				return k8sClient.Update(context.Background(), so)
+			}).ShouldNot(HaveOccurred())
+
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: "keda-hpa-" + soName, Namespace: "default"}, hpa)
+			}).ShouldNot(HaveOccurred())
+		})
+
+		It("doesn't create HPA with activationOnly and deletes it once activationOnly is switched on", func() {
+			deploymentName := "activation-only"
+			soName := "so-" + deploymentName
+
+			// Create the scaling target.
+			err := k8sClient.Create(context.Background(), generateDeployment(deploymentName))
+			Expect(err).ToNot(HaveOccurred())
+
+			// Create the activation only ScaledObject
+			so := &kedav1alpha1.ScaledObject{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      soName,
+					Namespace: "default",
+				},
+				Spec: kedav1alpha1.ScaledObjectSpec{
+					ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+						Name: deploymentName,
+					},
+					Advanced: &kedav1alpha1.AdvancedConfig{
+						ActivationOnly: true,
+					},
+					Triggers: []kedav1alpha1.ScaleTriggers{
+						{
+							Type: "cron",
+							Metadata: map[string]string{
+								"timezone":        "UTC",
+								"start":           "0 * * * *",
+								"end":             "1 * * * *",
+								"desiredReplicas": "1",
+							},
+						},
+					},
+				},
+			}
+			err = k8sClient.Create(context.Background(), so)
+			Ω(err).ToNot(HaveOccurred())
+
+			Eventually(func() metav1.ConditionStatus {
+				err = k8sClient.Get(context.Background(), types.NamespacedName{Name: soName, Namespace: "default"}, so)
+				Ω(err).ToNot(HaveOccurred())
+				return so.Status.Conditions.GetReadyCondition().Status
+			}, 20*time.Second).Should(Equal(metav1.ConditionTrue))
+
+			// the HPA is never created with activationOnly
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			Consistently(func() bool {
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: "keda-hpa-" + soName, Namespace: "default"}, hpa)
+				return errors.IsNotFound(err)
+			}, 2*time.Second).Should(BeTrue())
+
+			// Switch activationOnly off, the HPA is created
+			Eventually(func() error {
+				err = k8sClient.Get(context.Background(), types.NamespacedName{Name: soName, Namespace: "default"}, so)
+				Ω(err).ToNot(HaveOccurred())
+				so.Spec.Advanced.ActivationOnly = false
+				return k8sClient.Update(context.Background(), so)
+			}).ShouldNot(HaveOccurred())
+
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), types.NamespacedName{Name: "keda-hpa-" + soName, Namespace: "default"}, hpa)
+			}).ShouldNot(HaveOccurred())
+
+			// Switch activationOnly back on, the HPA is deleted
+			Eventually(func() error {
+				err = k8sClient.Get(context.Background(), types.NamespacedName{Name: soName, Namespace: "default"}, so)
+				Ω(err).ToNot(HaveOccurred())
+				so.Spec.Advanced.ActivationOnly = true
+				return k8sClient.Update(context.Background(), so)
+			}).ShouldNot(HaveOccurred())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), types.NamespacedName{Name: "keda-hpa-" + soName, Namespace: "default"}, hpa)
+				return errors.IsNotFound(err)
+			}, 20*time.Second).Should(BeTrue())
+		})
+
 		It("deploys ScaledObject and creates HPA, when metadata.Annotations is configured", func() {
 
 			deploymentName := "annotations"
@@ -870,3 +1123,24 @@ func generateDeployment(name string) *appsv1.Deployment {
 		},
 	}
 }
+
+// reconcileDeferredCount returns the number of reconciles deferred in the namespace for the reason
+func reconcileDeferredCount(namespace, reason string) float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_reconcile_deferred_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["reason"] == reason {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
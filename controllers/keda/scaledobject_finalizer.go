@@ -27,6 +27,7 @@ import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/controllers/keda/util"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 )
 
 const (
@@ -80,6 +81,13 @@ func (r *ScaledObjectReconciler) finalizeScaledObject(ctx context.Context, logge
 		}
 
 		r.updatePromMetricsOnDelete(namespacedName)
+		prommetrics.DeleteScaledObjectConditions(scaledObject.Namespace, scaledObject.Name)
+		prommetrics.DeleteScaledObjectFallbackInvalid(scaledObject.Namespace, scaledObject.Name)
+		prommetrics.DeleteScaledObjectDegradedMetrics(scaledObject.Namespace, scaledObject.Name)
+		prommetrics.DeleteScaledObjectTargetKind(scaledObject.Namespace, scaledObject.Name)
+		prommetrics.DeleteScaledObjectIdleReplicas(scaledObject.Namespace, scaledObject.Name)
+		prommetrics.DeleteScalerMetrics(scaledObject.Namespace, scaledObject.Name)
+		prommetrics.DeleteScaledObjectDryRunDesiredReplicas(scaledObject.Namespace, scaledObject.Name)
 	}
 
 	logger.Info("Successfully finalized ScaledObject")
@@ -0,0 +1,321 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+// Annotations of Deployments and StatefulSets used to generate their ScaledObject
+const (
+	GenerateScaledObjectAnnotation       = "keda.sh/enabled"
+	GeneratorQueueTriggerAnnotation      = "keda.sh/queue-trigger"
+	GeneratorQueueNameAnnotation         = "keda.sh/queue-name"
+	GeneratorQueueLengthAnnotation       = "keda.sh/queue-length"
+	GeneratorMinReplicasAnnotation       = "keda.sh/min-replicas"
+	GeneratorMaxReplicasAnnotation       = "keda.sh/max-replicas"
+	GeneratorAuthenticationRefAnnotation = "keda.sh/trigger-authentication"
+
+	// GeneratedScaledObjectLabel marks the ScaledObjects generated from workload annotations
+	GeneratedScaledObjectLabel = "keda.sh/generated-from-annotations"
+)
+
+// generatorQueueTrigger holds the metadata keys of a supported queue trigger
+type generatorQueueTrigger struct {
+	queueNameKey   string
+	queueLengthKey string
+	extraMetadata  map[string]string
+}
+
+var generatorQueueTriggers = map[string]generatorQueueTrigger{
+	"rabbitmq":      {queueNameKey: "queueName", queueLengthKey: "value", extraMetadata: map[string]string{"mode": "QueueLength"}},
+	"azure-queue":   {queueNameKey: "queueName", queueLengthKey: "queueLength"},
+	"aws-sqs-queue": {queueNameKey: "queueURL", queueLengthKey: "queueLength"},
+	"redis":         {queueNameKey: "listName", queueLengthKey: "listLength"},
+}
+
+// ScaledObjectGeneratorReconciler generates a ScaledObject for the Deployments and StatefulSets
+// opted in with the keda.sh/enabled annotation, keeps it in sync with their annotations and
+// deletes it when the annotation is removed. A hand-written ScaledObject targeting the same
+// workload takes precedence, the conflict is reported with an event on the workload
+type ScaledObjectGeneratorReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// SetupWithManager sets up one controller per supported workload kind with the Manager.
+func (r *ScaledObjectGeneratorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	workloads := map[string]client.Object{
+		"Deployment":  &appsv1.Deployment{},
+		"StatefulSet": &appsv1.StatefulSet{},
+	}
+	for kind, workload := range workloads {
+		kind := kind
+		err := ctrl.NewControllerManagedBy(mgr).
+			Named(fmt.Sprintf("scaledobject-generator-%s", strings.ToLower(kind))).
+			// the generated ScaledObject only depends on the annotations of the workload
+			For(workload, builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+			Watches(&kedav1alpha1.ScaledObject{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []reconcile.Request {
+				return scaledObjectTargetRequests(obj, kind)
+			}), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+			Complete(reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				return r.reconcileWorkload(ctx, req, kind)
+			}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scaledObjectTargetRequests maps a ScaledObject to its scale target, if it's of the given kind
+func scaledObjectTargetRequests(obj client.Object, kind string) []reconcile.Request {
+	scaledObject, ok := obj.(*kedav1alpha1.ScaledObject)
+	if !ok || scaledObject.Spec.ScaleTargetRef == nil || scaleTargetKind(scaledObject) != kind {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: scaledObject.Namespace, Name: scaledObject.Spec.ScaleTargetRef.Name}}}
+}
+
+func scaleTargetKind(scaledObject *kedav1alpha1.ScaledObject) string {
+	if scaledObject.Spec.ScaleTargetRef.Kind == "" {
+		return "Deployment"
+	}
+	return scaledObject.Spec.ScaleTargetRef.Kind
+}
+
+func generatedScaledObjectName(kind, workloadName string) string {
+	return fmt.Sprintf("%s-%s", strings.ToLower(kind), workloadName)
+}
+
+func (r *ScaledObjectGeneratorReconciler) reconcileWorkload(ctx context.Context, req reconcile.Request, kind string) (reconcile.Result, error) {
+	reqLogger := log.FromContext(ctx).WithValues("kind", kind)
+
+	workload, err := r.getWorkload(ctx, req.NamespacedName, kind)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// the generated ScaledObject is garbage collected with its owner
+			return ctrl.Result{}, nil
+		}
+		reqLogger.Error(err, "Failed to get workload")
+		return ctrl.Result{}, err
+	}
+
+	generated := &kedav1alpha1.ScaledObject{}
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: generatedScaledObjectName(kind, req.Name)}, generated)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to get generated ScaledObject")
+			return ctrl.Result{}, err
+		}
+		generated = nil
+	} else if !metav1.IsControlledBy(generated, workload) {
+		// a ScaledObject with the same name which isn't ours is never touched
+		generated = nil
+	}
+
+	if workload.GetAnnotations()[GenerateScaledObjectAnnotation] != "true" || workload.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, r.deleteGeneratedScaledObject(ctx, workload, generated, "annotation "+GenerateScaledObjectAnnotation+" removed")
+	}
+
+	conflicting, err := r.findConflictingScaledObject(ctx, workload, kind)
+	if err != nil {
+		reqLogger.Error(err, "Failed to list ScaledObjects")
+		return ctrl.Result{}, err
+	}
+	if conflicting != "" {
+		msg := fmt.Sprintf("ScaledObject %s already targets this %s, it takes precedence over the annotations", conflicting, kind)
+		r.Recorder.Event(workload, corev1.EventTypeWarning, eventreason.ScaledObjectGenerationConflict, msg)
+		return ctrl.Result{}, r.deleteGeneratedScaledObject(ctx, workload, generated, "conflicting ScaledObject "+conflicting)
+	}
+
+	desired, err := buildScaledObjectFromAnnotations(workload, kind)
+	if err != nil {
+		// the annotations have to be fixed by the user, so there is no point in requeueing
+		r.Recorder.Event(workload, corev1.EventTypeWarning, eventreason.ScaledObjectGenerationFailed, err.Error())
+		return ctrl.Result{}, nil
+	}
+	if err := controllerutil.SetControllerReference(workload, desired, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if generated == nil {
+		if err := r.Client.Create(ctx, desired); err != nil {
+			reqLogger.Error(err, "Failed to create generated ScaledObject")
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Event(workload, corev1.EventTypeNormal, eventreason.ScaledObjectGenerated, fmt.Sprintf("ScaledObject %s generated from annotations", desired.Name))
+		return ctrl.Result{}, nil
+	}
+
+	if equality.Semantic.DeepEqual(generated.Spec, desired.Spec) {
+		return ctrl.Result{}, nil
+	}
+	generated.Spec = desired.Spec
+	if err := r.Client.Update(ctx, generated); err != nil {
+		reqLogger.Error(err, "Failed to update generated ScaledObject")
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Event(workload, corev1.EventTypeNormal, eventreason.ScaledObjectGenerated, fmt.Sprintf("ScaledObject %s updated from annotations", generated.Name))
+	return ctrl.Result{}, nil
+}
+
+func (r *ScaledObjectGeneratorReconciler) getWorkload(ctx context.Context, name types.NamespacedName, kind string) (client.Object, error) {
+	var workload client.Object
+	switch kind {
+	case "Deployment":
+		workload = &appsv1.Deployment{}
+	case "StatefulSet":
+		workload = &appsv1.StatefulSet{}
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %s", kind)
+	}
+	if err := r.Client.Get(ctx, name, workload); err != nil {
+		return nil, err
+	}
+	return workload, nil
+}
+
+// findConflictingScaledObject returns the name of a ScaledObject, not generated for the workload, which targets it
+func (r *ScaledObjectGeneratorReconciler) findConflictingScaledObject(ctx context.Context, workload client.Object, kind string) (string, error) {
+	scaledObjects := &kedav1alpha1.ScaledObjectList{}
+	if err := r.Client.List(ctx, scaledObjects, client.InNamespace(workload.GetNamespace())); err != nil {
+		return "", err
+	}
+	for i := range scaledObjects.Items {
+		scaledObject := &scaledObjects.Items[i]
+		if scaledObject.Spec.ScaleTargetRef == nil || metav1.IsControlledBy(scaledObject, workload) {
+			continue
+		}
+		if scaledObject.Spec.ScaleTargetRef.Name == workload.GetName() && scaleTargetKind(scaledObject) == kind {
+			return scaledObject.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func (r *ScaledObjectGeneratorReconciler) deleteGeneratedScaledObject(ctx context.Context, workload client.Object, generated *kedav1alpha1.ScaledObject, reason string) error {
+	if generated == nil {
+		return nil
+	}
+	if err := r.Client.Delete(ctx, generated); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	r.Recorder.Event(workload, corev1.EventTypeNormal, eventreason.GeneratedScaledObjectDeleted, fmt.Sprintf("ScaledObject %s deleted, %s", generated.Name, reason))
+	return nil
+}
+
+// buildScaledObjectFromAnnotations returns the ScaledObject described by the annotations of the workload
+func buildScaledObjectFromAnnotations(workload client.Object, kind string) (*kedav1alpha1.ScaledObject, error) {
+	annotations := workload.GetAnnotations()
+
+	triggerType := annotations[GeneratorQueueTriggerAnnotation]
+	queueTrigger, ok := generatorQueueTriggers[triggerType]
+	if !ok {
+		return nil, fmt.Errorf("annotation %s must be one of rabbitmq, azure-queue, aws-sqs-queue or redis, got %q", GeneratorQueueTriggerAnnotation, triggerType)
+	}
+	queueName := annotations[GeneratorQueueNameAnnotation]
+	if queueName == "" {
+		return nil, fmt.Errorf("annotation %s is required", GeneratorQueueNameAnnotation)
+	}
+
+	metadata := map[string]string{queueTrigger.queueNameKey: queueName}
+	for key, value := range queueTrigger.extraMetadata {
+		metadata[key] = value
+	}
+	if queueLength, ok := annotations[GeneratorQueueLengthAnnotation]; ok {
+		if _, err := strconv.ParseInt(queueLength, 10, 64); err != nil {
+			return nil, fmt.Errorf("annotation %s must be an integer: %w", GeneratorQueueLengthAnnotation, err)
+		}
+		metadata[queueTrigger.queueLengthKey] = queueLength
+	}
+
+	minReplicas, err := parseReplicasAnnotation(annotations, GeneratorMinReplicasAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	maxReplicas, err := parseReplicasAnnotation(annotations, GeneratorMaxReplicasAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	if minReplicas != nil && maxReplicas != nil && *minReplicas > *maxReplicas {
+		return nil, fmt.Errorf("annotation %s must be greater or equal to %s", GeneratorMaxReplicasAnnotation, GeneratorMinReplicasAnnotation)
+	}
+
+	trigger := kedav1alpha1.ScaleTriggers{
+		Type:     triggerType,
+		Metadata: metadata,
+	}
+	if authenticationRef := annotations[GeneratorAuthenticationRefAnnotation]; authenticationRef != "" {
+		trigger.AuthenticationRef = &kedav1alpha1.ScaledObjectAuthRef{Name: authenticationRef}
+	}
+
+	return &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      generatedScaledObjectName(kind, workload.GetName()),
+			Namespace: workload.GetNamespace(),
+			Labels:    map[string]string{GeneratedScaledObjectLabel: "true"},
+		},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       kind,
+				Name:       workload.GetName(),
+			},
+			MinReplicaCount: minReplicas,
+			MaxReplicaCount: maxReplicas,
+			Triggers:        []kedav1alpha1.ScaleTriggers{trigger},
+		},
+	}, nil
+}
+
+func parseReplicasAnnotation(annotations map[string]string, annotation string) (*int32, error) {
+	val, ok := annotations[annotation]
+	if !ok {
+		return nil, nil
+	}
+	replicas, err := strconv.ParseInt(val, 10, 32)
+	if err != nil || replicas < 0 {
+		return nil, fmt.Errorf("annotation %s must be a non negative integer, got %q", annotation, val)
+	}
+	result := int32(replicas)
+	return &result, nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+var _ = Describe("ScaledObjectGenerator", func() {
+	var (
+		reconciler *ScaledObjectGeneratorReconciler
+		recorder   *record.FakeRecorder
+		fakeClient client.Client
+		deployment *appsv1.Deployment
+		request    reconcile.Request
+	)
+
+	generatedName := types.NamespacedName{Namespace: "default", Name: "deployment-orders-consumer"}
+
+	getGenerated := func() (*kedav1alpha1.ScaledObject, error) {
+		scaledObject := &kedav1alpha1.ScaledObject{}
+		err := fakeClient.Get(context.Background(), generatedName, scaledObject)
+		return scaledObject, err
+	}
+
+	updateAnnotations := func(annotations map[string]string) {
+		Expect(fakeClient.Get(context.Background(), request.NamespacedName, deployment)).To(Succeed())
+		deployment.Annotations = annotations
+		Expect(fakeClient.Update(context.Background(), deployment)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "orders-consumer",
+				Namespace: "default",
+				UID:       "orders-consumer-uid",
+				Annotations: map[string]string{
+					GenerateScaledObjectAnnotation:       "true",
+					GeneratorQueueTriggerAnnotation:      "rabbitmq",
+					GeneratorQueueNameAnnotation:         "orders",
+					GeneratorMaxReplicasAnnotation:       "20",
+					GeneratorAuthenticationRefAnnotation: "rabbitmq-auth",
+				},
+			},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ScaledObjectGeneratorReconciler{
+			Client:   fakeClient,
+			Scheme:   scheme,
+			Recorder: recorder,
+		}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "orders-consumer"}}
+	})
+
+	It("should generate the ScaledObject from the annotations", func() {
+		_, err := reconciler.reconcileWorkload(context.Background(), request, "Deployment")
+		Expect(err).ToNot(HaveOccurred())
+
+		scaledObject, err := getGenerated()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scaledObject.Labels).To(HaveKeyWithValue(GeneratedScaledObjectLabel, "true"))
+		Expect(metav1.IsControlledBy(scaledObject, deployment)).To(BeTrue())
+		Expect(scaledObject.Spec.ScaleTargetRef.Kind).To(Equal("Deployment"))
+		Expect(scaledObject.Spec.ScaleTargetRef.Name).To(Equal("orders-consumer"))
+		Expect(*scaledObject.Spec.MaxReplicaCount).To(Equal(int32(20)))
+		Expect(scaledObject.Spec.MinReplicaCount).To(BeNil())
+		Expect(scaledObject.Spec.Triggers).To(HaveLen(1))
+		Expect(scaledObject.Spec.Triggers[0].Type).To(Equal("rabbitmq"))
+		Expect(scaledObject.Spec.Triggers[0].Metadata).To(Equal(map[string]string{"queueName": "orders", "mode": "QueueLength"}))
+		Expect(scaledObject.Spec.Triggers[0].AuthenticationRef.Name).To(Equal("rabbitmq-auth"))
+		Expect(recorder.Events).To(Receive(ContainSubstring(eventreason.ScaledObjectGenerated)))
+	})
+
+	It("should update the ScaledObject when the annotations change", func() {
+		_, err := reconciler.reconcileWorkload(context.Background(), request, "Deployment")
+		Expect(err).ToNot(HaveOccurred())
+
+		updateAnnotations(map[string]string{
+			GenerateScaledObjectAnnotation:  "true",
+			GeneratorQueueTriggerAnnotation: "azure-queue",
+			GeneratorQueueNameAnnotation:    "payments",
+			GeneratorQueueLengthAnnotation:  "5",
+			GeneratorMinReplicasAnnotation:  "1",
+			GeneratorMaxReplicasAnnotation:  "10",
+		})
+		_, err = reconciler.reconcileWorkload(context.Background(), request, "Deployment")
+		Expect(err).ToNot(HaveOccurred())
+
+		scaledObject, err := getGenerated()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*scaledObject.Spec.MinReplicaCount).To(Equal(int32(1)))
+		Expect(*scaledObject.Spec.MaxReplicaCount).To(Equal(int32(10)))
+		Expect(scaledObject.Spec.Triggers[0].Type).To(Equal("azure-queue"))
+		Expect(scaledObject.Spec.Triggers[0].Metadata).To(Equal(map[string]string{"queueName": "payments", "queueLength": "5"}))
+		Expect(scaledObject.Spec.Triggers[0].AuthenticationRef).To(BeNil())
+	})
+
+	It("should delete the ScaledObject when the annotation is removed", func() {
+		_, err := reconciler.reconcileWorkload(context.Background(), request, "Deployment")
+		Expect(err).ToNot(HaveOccurred())
+
+		updateAnnotations(map[string]string{GeneratorQueueTriggerAnnotation: "rabbitmq"})
+		_, err = reconciler.reconcileWorkload(context.Background(), request, "Deployment")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = getGenerated()
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring(eventreason.ScaledObjectGenerated)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(eventreason.GeneratedScaledObjectDeleted)))
+	})
+
+	It("should report invalid annotations without generating a ScaledObject", func() {
+		updateAnnotations(map[string]string{
+			GenerateScaledObjectAnnotation:  "true",
+			GeneratorQueueTriggerAnnotation: "kafka",
+			GeneratorQueueNameAnnotation:    "orders",
+		})
+		_, err := reconciler.reconcileWorkload(context.Background(), request, "Deployment")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = getGenerated()
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring(eventreason.ScaledObjectGenerationFailed)))
+	})
+
+	It("should report a conflict with a hand-written ScaledObject and let it take precedence", func() {
+		_, err := reconciler.reconcileWorkload(context.Background(), request, "Deployment")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.Events).To(Receive(ContainSubstring(eventreason.ScaledObjectGenerated)))
+
+		handWritten := &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"},
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "orders-consumer"},
+				Triggers:       []kedav1alpha1.ScaleTriggers{{Type: "cpu", Metadata: map[string]string{"value": "50"}}},
+			},
+		}
+		Expect(fakeClient.Create(context.Background(), handWritten)).To(Succeed())
+		Expect(scaledObjectTargetRequests(handWritten, "Deployment")).To(ConsistOf(request))
+		Expect(scaledObjectTargetRequests(handWritten, "StatefulSet")).To(BeEmpty())
+
+		_, err = reconciler.reconcileWorkload(context.Background(), request, "Deployment")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = getGenerated()
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring(eventreason.ScaledObjectGenerationConflict)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(eventreason.GeneratedScaledObjectDeleted)))
+		Expect(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(handWritten), handWritten)).To(Succeed())
+	})
+})
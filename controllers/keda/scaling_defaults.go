@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling"
+)
+
+// setCappedCondition reports the fields of the spec clamped to the caps of the scaling defaults in the Capped condition,
+// the condition is only added once the spec is capped
+func setCappedCondition(conditions *kedav1alpha1.Conditions, clamps []string) {
+	switch {
+	case len(clamps) > 0:
+		conditions.SetCappedCondition(metav1.ConditionTrue, "SpecCapped", "The spec exceeds the caps of the scaling defaults: "+strings.Join(clamps, ", "))
+	case conditions.GetCappedCondition().Type != "":
+		conditions.SetCappedCondition(metav1.ConditionFalse, "SpecWithinCaps", "The spec is within the caps of the scaling defaults")
+	}
+}
+
+// scalingDefaultsRequests returns a request for each ScaledObject affected by a change of the scaling defaults
+// ConfigMap, the ones of its namespace or of all the namespaces for the cluster scaling defaults
+func scalingDefaultsRequests(ctx context.Context, c client.Client, configMap client.Object) []reconcile.Request {
+	if configMap.GetName() != scaling.ScalingDefaultsConfigMapName {
+		return nil
+	}
+	var opts []client.ListOption
+	if configMap.GetNamespace() != scaling.GetScalingDefaultsNamespace() {
+		opts = append(opts, client.InNamespace(configMap.GetNamespace()))
+	}
+	scaledObjects := &kedav1alpha1.ScaledObjectList{}
+	if err := c.List(ctx, scaledObjects, opts...); err != nil {
+		log.FromContext(ctx).Error(err, "error listing the ScaledObjects affected by the scaling defaults", "namespace", configMap.GetNamespace())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(scaledObjects.Items))
+	for _, scaledObject := range scaledObjects.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: scaledObject.Namespace, Name: scaledObject.Name}})
+	}
+	return requests
+}
@@ -35,6 +35,7 @@ import (
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/k8s"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/scaling"
 	//+kubebuilder:scaffold:imports
 )
@@ -57,6 +58,8 @@ func TestAPIs(t *testing.T) {
 
 var _ = BeforeSuite(func() {
 	logf.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
+	// the scaling metrics are only gathered on the leader
+	prommetrics.RecordOperatorLeader(true)
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
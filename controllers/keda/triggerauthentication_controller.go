@@ -20,24 +20,42 @@ import (
 	"context"
 	"sync"
 
+	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/prommetrics"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
+// secretExistencePredicate only passes the creation and deletion of Secrets, the updates don't change the missing refs
+// of the trigger authentications
+var secretExistencePredicate = predicate.Funcs{
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
 // TriggerAuthenticationReconciler reconciles a TriggerAuthentication object
 type TriggerAuthenticationReconciler struct {
 	client.Client
 	record.EventRecorder
+	SecretsLister corev1listers.SecretLister
+	// APIReader reads the referenced secrets without caching the Secrets of the cluster
+	APIReader client.Reader
 }
 
 type triggerAuthMetricsData struct {
@@ -47,11 +65,17 @@ type triggerAuthMetricsData struct {
 var (
 	triggerAuthPromMetricsMap  map[string]triggerAuthMetricsData
 	triggerAuthPromMetricsLock *sync.Mutex
+
+	// triggerAuthMissingRefsMap holds the TriggerAuthentications and ClusterTriggerAuthentications referencing missing secrets
+	triggerAuthMissingRefsMap  map[string]bool
+	triggerAuthMissingRefsLock *sync.Mutex
 )
 
 func init() {
 	triggerAuthPromMetricsMap = make(map[string]triggerAuthMetricsData)
 	triggerAuthPromMetricsLock = &sync.Mutex{}
+	triggerAuthMissingRefsMap = make(map[string]bool)
+	triggerAuthMissingRefsLock = &sync.Mutex{}
 }
 
 // +kubebuilder:rbac:groups=keda.sh,resources=triggerauthentications;triggerauthentications/status,verbs="*"
@@ -83,6 +107,15 @@ func (r *TriggerAuthenticationReconciler) Reconcile(ctx context.Context, req ctr
 		r.EventRecorder.Event(triggerAuthentication, corev1.EventTypeNormal, eventreason.TriggerAuthenticationAdded, "New TriggerAuthentication configured")
 	}
 
+	missingRefs, err := checkTriggerAuthSecretRefs(ctx, reqLogger, r.APIReader, r.SecretsLister, &triggerAuthentication.Spec,
+		triggerAuthentication.Namespace, getTriggerAuthMissingRefsKey(prommetrics.TriggerAuthenticationResource, req.NamespacedName.String()))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := updateTriggerAuthStatus(ctx, reqLogger, r.Client, triggerAuthentication, &triggerAuthentication.Spec, &triggerAuthentication.Status, missingRefs); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -90,9 +123,28 @@ func (r *TriggerAuthenticationReconciler) Reconcile(ctx context.Context, req ctr
 func (r *TriggerAuthenticationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kedav1alpha1.TriggerAuthentication{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// only the metadata of the Secrets is watched, their data isn't cached
+		WatchesMetadata(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.triggerAuthsReferencingSecret), builder.WithPredicates(secretExistencePredicate)).
 		Complete(r)
 }
 
+// triggerAuthsReferencingSecret returns the TriggerAuthentications in the namespace of the Secret which reference it
+func (r *TriggerAuthenticationReconciler) triggerAuthsReferencingSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	triggerAuths := &kedav1alpha1.TriggerAuthenticationList{}
+	if err := r.Client.List(ctx, triggerAuths, client.InNamespace(secret.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list the TriggerAuthentications", "namespace", secret.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range triggerAuths.Items {
+		if referencesSecret(&triggerAuths.Items[i].Spec, secret.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&triggerAuths.Items[i])})
+		}
+	}
+	return requests
+}
+
 func (r *TriggerAuthenticationReconciler) updatePromMetrics(triggerAuth *kedav1alpha1.TriggerAuthentication, namespacedName string) {
 	triggerAuthPromMetricsLock.Lock()
 	defer triggerAuthPromMetricsLock.Unlock()
@@ -115,4 +167,58 @@ func (r *TriggerAuthenticationReconciler) UpdatePromMetricsOnDelete(namespacedNa
 	}
 
 	delete(triggerAuthPromMetricsMap, namespacedName)
+	updateTriggerAuthMissingRefs(getTriggerAuthMissingRefsKey(prommetrics.TriggerAuthenticationResource, namespacedName), false)
+}
+
+// checkTriggerAuthSecretRefs checks whether the secrets referenced by the trigger authentication exist and updates
+// keda_trigger_auth_missing_refs accordingly, it returns the names of the missing ones
+func checkTriggerAuthSecretRefs(ctx context.Context, logger logr.Logger, apiReader client.Reader, secretsLister corev1listers.SecretLister,
+	triggerAuthSpec *kedav1alpha1.TriggerAuthenticationSpec, namespace string, key string) ([]string, error) {
+	missing, err := resolver.GetMissingAuthSecrets(ctx, apiReader, logger, triggerAuthSpec, namespace, secretsLister)
+	if err != nil {
+		logger.Error(err, "Failed to check the secrets referenced by the trigger authentication")
+		return nil, err
+	}
+	if len(missing) > 0 {
+		logger.Info("Trigger authentication references secrets which don't exist", "secrets", missing, "namespace", namespace)
+	}
+	updateTriggerAuthMissingRefs(key, len(missing) > 0)
+	return missing, nil
+}
+
+// updateTriggerAuthStatus patches the status of the trigger authentication when it differs from the one generated
+// from its spec and the missing secrets
+func updateTriggerAuthStatus(ctx context.Context, logger logr.Logger, c client.Client, triggerAuth client.Object,
+	triggerAuthSpec *kedav1alpha1.TriggerAuthenticationSpec, currentStatus *kedav1alpha1.TriggerAuthenticationStatus, missing []string) error {
+	status := triggerAuthSpec.GenerateStatus(missing)
+	if equality.Semantic.DeepEqual(*currentStatus, status) {
+		return nil
+	}
+	return kedautil.UpdateTriggerAuthenticationStatus(ctx, c, logger, triggerAuth, &status)
+}
+
+func updateTriggerAuthMissingRefs(key string, missing bool) {
+	triggerAuthMissingRefsLock.Lock()
+	defer triggerAuthMissingRefsLock.Unlock()
+
+	if missing {
+		triggerAuthMissingRefsMap[key] = true
+	} else {
+		delete(triggerAuthMissingRefsMap, key)
+	}
+	prommetrics.RecordTriggerAuthMissingRefs(len(triggerAuthMissingRefsMap))
+}
+
+// referencesSecret returns whether the trigger authentication references the secret
+func referencesSecret(triggerAuthSpec *kedav1alpha1.TriggerAuthenticationSpec, name string) bool {
+	for _, secretName := range resolver.GetAuthSecretNames(triggerAuthSpec) {
+		if secretName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func getTriggerAuthMissingRefsKey(resource string, namespacedName string) string {
+	return resource + "/" + namespacedName
 }
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+var _ = Describe("TriggerAuthenticationMissingRefs", func() {
+	var (
+		reconciler *TriggerAuthenticationReconciler
+		fakeClient client.Client
+		request    reconcile.Request
+	)
+
+	expectMissingRefs := func(count string) {
+		expected := `
+# HELP keda_trigger_auth_missing_refs Number of TriggerAuthentications and ClusterTriggerAuthentications referencing secrets which don't exist
+# TYPE keda_trigger_auth_missing_refs gauge
+keda_trigger_auth_missing_refs ` + count + `
+`
+		Expect(testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_trigger_auth_missing_refs")).To(Succeed())
+	}
+
+	expectReady := func(status metav1.ConditionStatus) {
+		triggerAuth := &kedav1alpha1.TriggerAuthentication{}
+		Expect(fakeClient.Get(context.Background(), request.NamespacedName, triggerAuth)).To(Succeed())
+		Expect(triggerAuth.Status.Providers).To(Equal([]string{kedav1alpha1.TriggerAuthenticationProviderSecretTargetRef}))
+		Expect(triggerAuth.Status.Parameters).To(Equal([]string{"password"}))
+		Expect(triggerAuth.Status.Conditions.GetReadyCondition().Status).To(Equal(status))
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(kedav1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		triggerAuth := &kedav1alpha1.TriggerAuthentication{
+			ObjectMeta: metav1.ObjectMeta{Name: "dangling-auth", Namespace: "default"},
+			Spec: kedav1alpha1.TriggerAuthenticationSpec{
+				SecretTargetRef: []kedav1alpha1.AuthSecretTargetRef{
+					{Parameter: "password", Name: "dangling-secret", Key: "password"},
+				},
+			},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(triggerAuth).WithStatusSubresource(triggerAuth).Build()
+		reconciler = &TriggerAuthenticationReconciler{
+			Client:        fakeClient,
+			EventRecorder: record.NewFakeRecorder(10),
+			APIReader:     fakeClient,
+		}
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "dangling-auth"}}
+	})
+
+	AfterEach(func() {
+		reconciler.UpdatePromMetricsOnDelete(request.NamespacedName.String())
+	})
+
+	It("counts the TriggerAuthentication until the referenced secret is created", func() {
+		result, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		expectMissingRefs("1")
+		expectReady(metav1.ConditionFalse)
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "dangling-secret", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		}
+		Expect(fakeClient.Create(context.Background(), secret)).To(Succeed())
+
+		result, err = reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		expectMissingRefs("0")
+		expectReady(metav1.ConditionTrue)
+	})
+
+	It("enqueues the TriggerAuthentications referencing a created or deleted secret", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "dangling-secret", Namespace: "default"}}
+		Expect(reconciler.triggerAuthsReferencingSecret(context.Background(), secret)).To(Equal([]reconcile.Request{request}))
+
+		secret.Namespace = "other"
+		Expect(reconciler.triggerAuthsReferencingSecret(context.Background(), secret)).To(BeEmpty())
+
+		secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unrelated-secret", Namespace: "default"}}
+		Expect(reconciler.triggerAuthsReferencingSecret(context.Background(), secret)).To(BeEmpty())
+	})
+
+	It("stops counting a deleted TriggerAuthentication", func() {
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).ToNot(HaveOccurred())
+		expectMissingRefs("1")
+
+		reconciler.UpdatePromMetricsOnDelete(request.NamespacedName.String())
+		expectMissingRefs("0")
+	})
+})
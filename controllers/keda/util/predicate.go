@@ -1,13 +1,27 @@
 package util
 
 import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 )
 
-const PausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+const (
+	PausedReplicasAnnotation = "autoscaling.keda.sh/paused-replicas"
+
+	// DryRunAnnotation set to "true" makes KEDA evaluate the ScaledObject without creating the HPA or scaling the scale target
+	DryRunAnnotation = "autoscaling.keda.sh/dry-run"
+)
+
+// IsDryRun returns true if the object has the dry-run annotation set to true
+func IsDryRun(object metav1.Object) bool {
+	dryRun, err := strconv.ParseBool(object.GetAnnotations()[DryRunAnnotation])
+	return err == nil && dryRun
+}
 
 type PausedReplicasPredicate struct {
 	predicate.Funcs
@@ -31,6 +45,18 @@ func (PausedReplicasPredicate) Update(e event.UpdateEvent) bool {
 	return false
 }
 
+// DryRunPredicate triggers a reconcile when the dry-run mode is switched on or off
+type DryRunPredicate struct {
+	predicate.Funcs
+}
+
+func (DryRunPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+	return IsDryRun(e.ObjectOld) != IsDryRun(e.ObjectNew)
+}
+
 type ScaleObjectReadyConditionPredicate struct {
 	predicate.Funcs
 }
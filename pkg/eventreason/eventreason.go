@@ -56,9 +56,36 @@ const (
 	// KEDAScaleTargetDeactivationFailed is for event when the deactivation of the scale target for ScaledObject fails
 	KEDAScaleTargetDeactivationFailed = "KEDAScaleTargetDeactivationFailed"
 
+	// KEDAScaleTargetActivationGracePeriodExceeded is for event when no pod of the scale target for ScaledObject became ready within the activationFailureGracePeriod
+	KEDAScaleTargetActivationGracePeriodExceeded = "KEDAScaleTargetActivationGracePeriodExceeded"
+
+	// DryRunScaleTarget is for event when the scale target of a ScaledObject in dry-run mode would have been scaled
+	DryRunScaleTarget = "DryRunScaleTarget"
+
+	// DryRunHPADeleted is for event when the HPA of a ScaledObject is deleted because of the dry-run mode
+	DryRunHPADeleted = "DryRunHPADeleted"
+
+	// ActivationOnlyHPADeleted is for event when the HPA of a ScaledObject is deleted because of advanced.activationOnly
+	ActivationOnlyHPADeleted = "ActivationOnlyHPADeleted"
+
+	// RolloutPauseStarted is for event when the scaling to and from zero of a ScaledObject is paused during a rollout of its scale target
+	RolloutPauseStarted = "RolloutPauseStarted"
+
+	// RolloutPauseEnded is for event when the scaling to and from zero of a ScaledObject resumes after a rollout of its scale target
+	RolloutPauseEnded = "RolloutPauseEnded"
+
+	// HPAChangesReverted is for event when changes made to the HPA of a ScaledObject outside of KEDA are reverted
+	HPAChangesReverted = "HPAChangesReverted"
+
 	// KEDAJobsCreated is for event when jobs for ScaledJob are created
 	KEDAJobsCreated = "KEDAJobsCreated"
 
+	// ScaledJobCircuitBreakerOpened is for event when the creation of jobs for ScaledJob is paused after too many failed jobs
+	ScaledJobCircuitBreakerOpened = "ScaledJobCircuitBreakerOpened"
+
+	// ScaledJobCircuitBreakerClosed is for event when the creation of jobs for ScaledJob resumes
+	ScaledJobCircuitBreakerClosed = "ScaledJobCircuitBreakerClosed"
+
 	// TriggerAuthenticationDeleted is for event when a TriggerAuthentication is deleted
 	TriggerAuthenticationDeleted = "TriggerAuthenticationDeleted"
 
@@ -70,4 +97,16 @@ const (
 
 	// ClusterTriggerAuthenticationAdded is for event when a ClusterTriggerAuthentication is added
 	ClusterTriggerAuthenticationAdded = "ClusterTriggerAuthenticationAdded"
+
+	// ScaledObjectGenerated is for event when a ScaledObject is generated or updated from workload annotations
+	ScaledObjectGenerated = "ScaledObjectGenerated"
+
+	// ScaledObjectGenerationFailed is for event when the workload annotations don't describe a valid ScaledObject
+	ScaledObjectGenerationFailed = "ScaledObjectGenerationFailed"
+
+	// ScaledObjectGenerationConflict is for event when another ScaledObject already targets the annotated workload
+	ScaledObjectGenerationConflict = "ScaledObjectGenerationConflict"
+
+	// GeneratedScaledObjectDeleted is for event when a ScaledObject generated from workload annotations is deleted
+	GeneratedScaledObjectDeleted = "GeneratedScaledObjectDeleted"
 )
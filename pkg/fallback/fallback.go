@@ -18,6 +18,7 @@ package fallback
 
 import (
 	"context"
+	"time"
 
 	v2 "k8s.io/api/autoscaling/v2"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -27,6 +28,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
 )
 
 var log = logf.Log.WithName("fallback")
@@ -54,6 +56,12 @@ func GetMetricsWithFallback(ctx context.Context, client runtimeclient.Client, me
 		zero := int32(0)
 		healthStatus.NumberOfFailures = &zero
 		healthStatus.Status = kedav1alpha1.HealthStatusHappy
+		healthStatus.ErrorType = ""
+		healthStatus.ErrorTypes = nil
+		// the first healthy sample ends the fallback and its decay
+		healthStatus.FallbackStartTime = nil
+		healthStatus.FallbackReplicas = nil
+		healthStatus.FallbackDecayRemaining = nil
 		status.Health[metricName] = *healthStatus
 
 		updateStatus(ctx, client, scaledObject, status, metricSpec)
@@ -61,22 +69,88 @@ func GetMetricsWithFallback(ctx context.Context, client runtimeclient.Client, me
 	}
 
 	healthStatus.Status = kedav1alpha1.HealthStatusFailing
+	errorType := scalers.GetErrorType(suppressedError)
+	if healthStatus.ErrorTypes == nil {
+		healthStatus.ErrorTypes = map[string]int32{}
+	}
+	healthStatus.ErrorTypes[errorType]++
+	if healthStatus.ErrorType == "" {
+		healthStatus.ErrorType = errorType
+	}
+	healthStatus.ErrorType = getDominantErrorType(healthStatus.ErrorTypes, healthStatus.ErrorType)
 	*healthStatus.NumberOfFailures++
-	status.Health[metricName] = *healthStatus
-
-	updateStatus(ctx, client, scaledObject, status, metricSpec)
 
+	fallingBack := false
 	switch {
 	case !isFallbackEnabled(scaledObject, metricSpec):
-		return nil, suppressedError
 	case !validateFallback(scaledObject):
-		log.Info("Failed to validate ScaledObject Spec. Please check that parameters are positive integers", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
-		return nil, suppressedError
+		log.Info("Failed to validate ScaledObject Spec. Please check that parameters are positive integers and safeReplicaCount isn't above replicas", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
 	case *healthStatus.NumberOfFailures > scaledObject.Spec.Fallback.FailureThreshold:
-		return doFallback(scaledObject, metricSpec, metricName, suppressedError), nil
-	default:
+		fallingBack = true
+		now := time.Now()
+		if healthStatus.FallbackStartTime == nil {
+			startTime := metav1.NewTime(now)
+			healthStatus.FallbackStartTime = &startTime
+		}
+		replicas, remaining := getFallbackReplicas(scaledObject.Spec.Fallback, healthStatus.FallbackStartTime.Time, now)
+		healthStatus.FallbackReplicas = &replicas
+		healthStatus.FallbackDecayRemaining = nil
+		if remaining != nil {
+			healthStatus.FallbackDecayRemaining = &metav1.Duration{Duration: *remaining}
+		}
+	}
+	status.Health[metricName] = *healthStatus
+
+	updateStatus(ctx, client, scaledObject, status, metricSpec)
+
+	if !fallingBack {
 		return nil, suppressedError
 	}
+	return doFallback(scaledObject, metricSpec, metricName, *healthStatus.FallbackReplicas, suppressedError), nil
+}
+
+// getDominantErrorType returns the most frequent error type, the current one is kept on a tie
+// so the status doesn't flap between types failing as often
+func getDominantErrorType(errorTypes map[string]int32, current string) string {
+	dominant := current
+	for errorType, count := range errorTypes {
+		if count > errorTypes[dominant] {
+			dominant = errorType
+		}
+	}
+	return dominant
+}
+
+// getFallbackReplicas returns the replicas of a fallback started at startTime and the time left until they reach
+// fallback.safeReplicaCount. They decay linearly over fallback.decayDuration once the fallback lasts longer than
+// fallback.maxDuration, and are held as long as the trigger fails if it isn't set.
+func getFallbackReplicas(fallback *kedav1alpha1.Fallback, startTime, now time.Time) (int32, *time.Duration) {
+	if fallback.MaxDuration == nil {
+		return fallback.Replicas, nil
+	}
+
+	safeReplicas := int32(0)
+	if fallback.SafeReplicaCount != nil {
+		safeReplicas = *fallback.SafeReplicaCount
+	}
+	decayDuration := fallback.MaxDuration.Duration
+	if fallback.DecayDuration != nil {
+		decayDuration = fallback.DecayDuration.Duration
+	}
+
+	elapsed := now.Sub(startTime)
+	remaining := fallback.MaxDuration.Duration + decayDuration - elapsed
+	decayElapsed := elapsed - fallback.MaxDuration.Duration
+	switch {
+	case decayElapsed <= 0:
+		return fallback.Replicas, &remaining
+	case remaining <= 0:
+		remaining = 0
+		return safeReplicas, &remaining
+	}
+
+	decayed := float64(fallback.Replicas-safeReplicas) * float64(decayElapsed) / float64(decayDuration)
+	return fallback.Replicas - int32(decayed), &remaining
 }
 
 func fallbackExistsInScaledObject(scaledObject *kedav1alpha1.ScaledObject, metricSpec v2.MetricSpec) bool {
@@ -93,13 +167,37 @@ func fallbackExistsInScaledObject(scaledObject *kedav1alpha1.ScaledObject, metri
 	return false
 }
 
+// CheckFallbackValid returns false if the ScaledObject has a fallback which can't be applied,
+// either because of its parameters or because a trigger doesn't use AverageValue metric type
+func CheckFallbackValid(scaledObject *kedav1alpha1.ScaledObject) bool {
+	valid := true
+	if scaledObject.Spec.Fallback != nil {
+		valid = validateFallback(scaledObject)
+		for _, trigger := range scaledObject.Spec.Triggers {
+			// cpu and memory triggers are resource metrics, fallback doesn't apply to them
+			if trigger.Type == "cpu" || trigger.Type == "memory" {
+				continue
+			}
+			if trigger.MetricType != "" && trigger.MetricType != v2.AverageValueMetricType {
+				valid = false
+			}
+		}
+	}
+
+	return valid
+}
+
 func validateFallback(scaledObject *kedav1alpha1.ScaledObject) bool {
-	return scaledObject.Spec.Fallback.FailureThreshold >= 0 &&
-		scaledObject.Spec.Fallback.Replicas >= 0
+	fallback := scaledObject.Spec.Fallback
+	return fallback.FailureThreshold >= 0 &&
+		fallback.Replicas >= 0 &&
+		(fallback.MaxDuration == nil || fallback.MaxDuration.Duration >= 0) &&
+		(fallback.DecayDuration == nil || fallback.DecayDuration.Duration >= 0) &&
+		(fallback.SafeReplicaCount == nil || (*fallback.SafeReplicaCount >= 0 && *fallback.SafeReplicaCount <= fallback.Replicas))
 }
 
-func doFallback(scaledObject *kedav1alpha1.ScaledObject, metricSpec v2.MetricSpec, metricName string, suppressedError error) []external_metrics.ExternalMetricValue {
-	replicas := int64(scaledObject.Spec.Fallback.Replicas)
+func doFallback(scaledObject *kedav1alpha1.ScaledObject, metricSpec v2.MetricSpec, metricName string, fallbackReplicas int32, suppressedError error) []external_metrics.ExternalMetricValue {
+	replicas := int64(fallbackReplicas)
 	normalisationValue := metricSpec.External.Target.AverageValue.AsApproximateFloat64()
 	metric := external_metrics.ExternalMetricValue{
 		MetricName: metricName,
@@ -108,7 +206,7 @@ func doFallback(scaledObject *kedav1alpha1.ScaledObject, metricSpec v2.MetricSpe
 	}
 	fallbackMetrics := []external_metrics.ExternalMetricValue{metric}
 
-	log.Info("Suppressing error, falling back to fallback.replicas", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "suppressedError", suppressedError, "fallback.replicas", replicas)
+	log.Info("Suppressing error, falling back to fallback.replicas", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "suppressedError", suppressedError, "fallback.replicas", scaledObject.Spec.Fallback.Replicas, "replicas", replicas)
 	return fallbackMetrics
 }
 
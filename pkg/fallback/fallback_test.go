@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -34,6 +35,7 @@ import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
 	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
 )
 
 const metricName = "some_metric_name"
@@ -153,6 +155,49 @@ var _ = Describe("fallback", func() {
 		Expect(so.Status.Health[metricName]).To(haveFailureAndStatus(1, kedav1alpha1.HealthStatusFailing))
 	})
 
+	It("should record the dominant error type of the failures and clear it on success", func() {
+		startingNumberOfFailures := int32(0)
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(3),
+				Replicas:         int32(10),
+			},
+			&kedav1alpha1.ScaledObjectStatus{
+				Health: map[string]kedav1alpha1.HealthStatus{
+					metricName: {
+						NumberOfFailures: &startingNumberOfFailures,
+						Status:           kedav1alpha1.HealthStatusHappy,
+					},
+				},
+			},
+		)
+		metricSpec := createMetricSpec(10)
+
+		expectStatusPatch(ctrl, client)
+		authErr := scalers.WrapScalerError(scalers.ErrAuth, errors.New("api returned 401"))
+		_, err := GetMetricsWithFallback(context.Background(), client, nil, authErr, metricName, so, metricSpec)
+		Expect(err).ShouldNot(BeNil())
+		Expect(so.Status.Health[metricName].ErrorType).To(Equal("auth"))
+
+		// the dominant type is kept on a tie
+		expectStatusPatch(ctrl, client)
+		_, err = GetMetricsWithFallback(context.Background(), client, nil, errors.New("Some error"), metricName, so, metricSpec)
+		Expect(err).ShouldNot(BeNil())
+		Expect(so.Status.Health[metricName].ErrorType).To(Equal("auth"))
+
+		expectStatusPatch(ctrl, client)
+		_, err = GetMetricsWithFallback(context.Background(), client, nil, errors.New("Some error"), metricName, so, metricSpec)
+		Expect(err).ShouldNot(BeNil())
+		Expect(so.Status.Health[metricName].ErrorType).To(Equal("unknown"))
+		Expect(so.Status.Health[metricName].ErrorTypes).To(Equal(map[string]int32{"auth": 1, "unknown": 2}))
+
+		expectStatusPatch(ctrl, client)
+		_, err = GetMetricsWithFallback(context.Background(), client, nil, nil, metricName, so, metricSpec)
+		Expect(err).Should(BeNil())
+		Expect(so.Status.Health[metricName].ErrorType).To(BeEmpty())
+		Expect(so.Status.Health[metricName].ErrorTypes).To(BeNil())
+	})
+
 	It("should return a normalised metric when number of failures are beyond threshold", func() {
 		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Eq(metricName)).Return(nil, false, errors.New("Some error"))
 		startingNumberOfFailures := int32(3)
@@ -336,6 +381,190 @@ var _ = Describe("fallback", func() {
 		condition := so.Status.Conditions.GetFallbackCondition()
 		Expect(condition.IsTrue()).Should(BeFalse())
 	})
+
+	It("should report the fallback as invalid when it has invalid parameter", func() {
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(-3),
+				Replicas:         int32(10),
+			},
+			nil,
+		)
+
+		Expect(CheckFallbackValid(so)).Should(BeFalse())
+	})
+
+	It("should report the fallback as invalid when a trigger metric type is not average value", func() {
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(3),
+				Replicas:         int32(10),
+			},
+			nil,
+		)
+		so.Spec.Triggers[0].MetricType = v2.ValueMetricType
+
+		Expect(CheckFallbackValid(so)).Should(BeFalse())
+	})
+
+	It("should report the fallback as valid when the config is correct or missing", func() {
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(3),
+				Replicas:         int32(10),
+			},
+			nil,
+		)
+		so.Spec.Triggers[0].MetricType = v2.AverageValueMetricType
+
+		Expect(CheckFallbackValid(so)).Should(BeTrue())
+
+		so = buildScaledObject(nil, nil)
+		so.Spec.Triggers[0].MetricType = v2.ValueMetricType
+
+		Expect(CheckFallbackValid(so)).Should(BeTrue())
+	})
+
+	It("should decay the fallback replicas toward safeReplicaCount after maxDuration", func() {
+		safeReplicas := int32(2)
+		fallback := &kedav1alpha1.Fallback{
+			FailureThreshold: int32(3),
+			Replicas:         int32(10),
+			MaxDuration:      &metav1.Duration{Duration: time.Hour},
+			DecayDuration:    &metav1.Duration{Duration: 40 * time.Minute},
+			SafeReplicaCount: &safeReplicas,
+		}
+		startTime := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+
+		for _, step := range []struct {
+			elapsed   time.Duration
+			replicas  int32
+			remaining time.Duration
+		}{
+			{0, 10, 100 * time.Minute},
+			{time.Hour, 10, 40 * time.Minute},
+			{time.Hour + 5*time.Minute, 9, 35 * time.Minute},
+			{time.Hour + 20*time.Minute, 6, 20 * time.Minute},
+			{time.Hour + 39*time.Minute, 3, time.Minute},
+			{time.Hour + 40*time.Minute, 2, 0},
+			{5 * time.Hour, 2, 0},
+		} {
+			replicas, remaining := getFallbackReplicas(fallback, startTime, startTime.Add(step.elapsed))
+			Expect(replicas).To(Equal(step.replicas), "replicas after %s", step.elapsed)
+			Expect(*remaining).To(Equal(step.remaining), "remaining after %s", step.elapsed)
+		}
+
+		// the replicas decay to 0 over maxDuration by default
+		fallback.DecayDuration, fallback.SafeReplicaCount = nil, nil
+		replicas, _ := getFallbackReplicas(fallback, startTime, startTime.Add(90*time.Minute))
+		Expect(replicas).To(Equal(int32(5)))
+
+		// the replicas are held without maxDuration
+		fallback.MaxDuration = nil
+		replicas, remaining := getFallbackReplicas(fallback, startTime, startTime.Add(5*time.Hour))
+		Expect(replicas).To(Equal(int32(10)))
+		Expect(remaining).To(BeNil())
+	})
+
+	It("should fall back to the decayed replicas and show them in the status", func() {
+		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Eq(metricName)).Return(nil, false, errors.New("Some error"))
+		numberOfFailures := int32(10)
+		safeReplicas := int32(2)
+		startTime := metav1.NewTime(time.Now().Add(-90 * time.Minute))
+
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(3),
+				Replicas:         int32(10),
+				MaxDuration:      &metav1.Duration{Duration: time.Hour},
+				DecayDuration:    &metav1.Duration{Duration: time.Hour},
+				SafeReplicaCount: &safeReplicas,
+			},
+			&kedav1alpha1.ScaledObjectStatus{
+				Health: map[string]kedav1alpha1.HealthStatus{
+					metricName: {
+						NumberOfFailures:  &numberOfFailures,
+						Status:            kedav1alpha1.HealthStatusFailing,
+						FallbackStartTime: &startTime,
+					},
+				},
+			},
+		)
+		metricSpec := createMetricSpec(3)
+		expectStatusPatch(ctrl, client)
+
+		metrics, _, err := scaler.GetMetricsAndActivity(context.Background(), metricName)
+		metrics, err = GetMetricsWithFallback(context.Background(), client, metrics, err, metricName, so, metricSpec)
+
+		Expect(err).ToNot(HaveOccurred())
+		// half of the decay from 10 to 2 replicas is done
+		Expect(metrics[0].Value.AsApproximateFloat64()).Should(Equal(float64(3 * 6)))
+		health := so.Status.Health[metricName]
+		Expect(health.FallbackStartTime.Time).To(Equal(startTime.Time))
+		Expect(*health.FallbackReplicas).To(Equal(int32(6)))
+		Expect(health.FallbackDecayRemaining.Duration).To(BeNumerically("~", 30*time.Minute, time.Minute))
+	})
+
+	It("should start the fallback time when the trigger starts falling back and reset it on the first healthy sample", func() {
+		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Eq(metricName)).Return(nil, false, errors.New("Some error"))
+		numberOfFailures := int32(3)
+
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(3),
+				Replicas:         int32(10),
+				MaxDuration:      &metav1.Duration{Duration: time.Hour},
+			},
+			&kedav1alpha1.ScaledObjectStatus{
+				Health: map[string]kedav1alpha1.HealthStatus{
+					metricName: {
+						NumberOfFailures: &numberOfFailures,
+						Status:           kedav1alpha1.HealthStatusFailing,
+					},
+				},
+			},
+		)
+		metricSpec := createMetricSpec(3)
+		expectStatusPatch(ctrl, client)
+
+		metrics, _, err := scaler.GetMetricsAndActivity(context.Background(), metricName)
+		metrics, err = GetMetricsWithFallback(context.Background(), client, metrics, err, metricName, so, metricSpec)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics[0].Value.AsApproximateFloat64()).Should(Equal(float64(3 * 10)))
+		health := so.Status.Health[metricName]
+		Expect(health.FallbackStartTime).ToNot(BeNil())
+		Expect(*health.FallbackReplicas).To(Equal(int32(10)))
+		Expect(health.FallbackDecayRemaining.Duration).To(BeNumerically("~", 2*time.Hour, time.Minute))
+
+		primeGetMetrics(scaler, 4)
+		expectStatusPatch(ctrl, client)
+		metrics, _, err = scaler.GetMetricsAndActivity(context.Background(), metricName)
+		metrics, err = GetMetricsWithFallback(context.Background(), client, metrics, err, metricName, so, metricSpec)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics[0].Value.AsApproximateFloat64()).Should(Equal(float64(4)))
+		health = so.Status.Health[metricName]
+		Expect(health).To(haveFailureAndStatus(0, kedav1alpha1.HealthStatusHappy))
+		Expect(health.FallbackStartTime).To(BeNil())
+		Expect(health.FallbackReplicas).To(BeNil())
+		Expect(health.FallbackDecayRemaining).To(BeNil())
+	})
+
+	It("should report the fallback as invalid when safeReplicaCount is above replicas", func() {
+		safeReplicas := int32(11)
+		so := buildScaledObject(
+			&kedav1alpha1.Fallback{
+				FailureThreshold: int32(3),
+				Replicas:         int32(10),
+				MaxDuration:      &metav1.Duration{Duration: time.Hour},
+				SafeReplicaCount: &safeReplicas,
+			},
+			nil,
+		)
+
+		Expect(CheckFallbackValid(so)).Should(BeFalse())
+	})
 })
 
 func haveFailureAndStatus(numberOfFailures int, status kedav1alpha1.HealthStatusType) types.GomegaMatcher {
@@ -0,0 +1,50 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"k8s.io/client-go/discovery"
+)
+
+// autoscalingAPIVersions are the HPA API versions KEDA knows about, in the order of preference
+var autoscalingAPIVersions = []string{"autoscaling/v2", "autoscaling/v2beta2"}
+
+// DetectAutoscalingAPIVersion returns the most recent HPA API version served by the cluster,
+// or an empty string if none of the known versions is served
+func DetectAutoscalingAPIVersion(client discovery.ServerGroupsInterface) (string, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+
+	served := map[string]bool{}
+	for _, group := range groups.Groups {
+		if group.Name != "autoscaling" {
+			continue
+		}
+		for _, version := range group.Versions {
+			served[version.GroupVersion] = true
+		}
+	}
+
+	for _, version := range autoscalingAPIVersions {
+		if served[version] {
+			return version, nil
+		}
+	}
+	return "", nil
+}
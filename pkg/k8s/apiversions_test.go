@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDetectAutoscalingAPIVersion(t *testing.T) {
+	tests := []struct {
+		groupVersions []string
+		expected      string
+	}{
+		{groupVersions: []string{"apps/v1", "autoscaling/v1", "autoscaling/v2", "autoscaling/v2beta2"}, expected: "autoscaling/v2"},
+		{groupVersions: []string{"apps/v1", "autoscaling/v1", "autoscaling/v2beta2"}, expected: "autoscaling/v2beta2"},
+		{groupVersions: []string{"apps/v1", "autoscaling/v1"}, expected: ""},
+	}
+
+	for _, test := range tests {
+		var resources []*metav1.APIResourceList
+		for _, gv := range test.groupVersions {
+			resources = append(resources, &metav1.APIResourceList{GroupVersion: gv})
+		}
+		client := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+
+		version, err := DetectAutoscalingAPIVersion(client)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, version, "group versions %v", test.groupVersions)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+// unlimitedEventReasons are the reasons of the one-shot events on state transitions, they are always emitted
+var unlimitedEventReasons = map[string]bool{
+	eventreason.ScaledObjectReady:          true,
+	eventreason.ScaledJobReady:             true,
+	eventreason.ScaledObjectDeleted:        true,
+	eventreason.ScaledJobDeleted:           true,
+	eventreason.KEDAScaleTargetActivated:   true,
+	eventreason.KEDAScaleTargetDeactivated: true,
+}
+
+// eventKey identifies the events of an object with the same reason
+type eventKey struct {
+	object string
+	reason string
+}
+
+// eventBucket is the token bucket of the events of an object with the same reason, with the events suppressed
+// since the last summary
+type eventBucket struct {
+	tokens     float64
+	lastRefill time.Time
+
+	lastType    string
+	lastMessage string
+	lastEmitted time.Time
+
+	object         runtime.Object
+	suppressed     int
+	suppressedType string
+}
+
+// RateLimitedEventRecorder limits the events of every object and reason to a rate with a burst, and drops the
+// events repeating the last one emitted within the summary interval. The suppressed events are counted and
+// reported in a summary event every summary interval.
+type RateLimitedEventRecorder struct {
+	next            record.EventRecorder
+	perMinute       float64
+	burst           int
+	summaryInterval time.Duration
+	logger          logr.Logger
+	now             func() time.Time
+
+	lock    sync.Mutex
+	buckets map[eventKey]*eventBucket
+}
+
+// NewRateLimitedEventRecorder wraps the recorder with a limit of perMinute events of an object with the same
+// reason and a burst of burst events
+func NewRateLimitedEventRecorder(next record.EventRecorder, perMinute float64, burst int, summaryInterval time.Duration, logger logr.Logger) (*RateLimitedEventRecorder, error) {
+	if perMinute <= 0 {
+		return nil, fmt.Errorf("the events per object per minute must be positive, %g given", perMinute)
+	}
+	if burst < 1 {
+		return nil, fmt.Errorf("the events burst must be at least 1, %d given", burst)
+	}
+	if summaryInterval <= 0 {
+		return nil, fmt.Errorf("the interval of the suppressed events summaries must be positive, %s given", summaryInterval)
+	}
+	return &RateLimitedEventRecorder{
+		next:            next,
+		perMinute:       perMinute,
+		burst:           burst,
+		summaryInterval: summaryInterval,
+		logger:          logger,
+		now:             time.Now,
+		buckets:         map[eventKey]*eventBucket{},
+	}, nil
+}
+
+// Event emits the event unless the events of the object with the same reason are over the limit
+func (r *RateLimitedEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if r.allow(object, eventtype, reason, message) {
+		r.next.Event(object, eventtype, reason, message)
+	}
+}
+
+// Eventf is like Event, but with Sprintf for the message
+func (r *RateLimitedEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf is like Eventf, but with annotations attached
+func (r *RateLimitedEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if r.allow(object, eventtype, reason, message) {
+		r.next.AnnotatedEventf(object, annotations, eventtype, reason, "%s", message)
+	}
+}
+
+// allow returns whether the event is emitted, it counts the event as suppressed otherwise
+func (r *RateLimitedEventRecorder) allow(object runtime.Object, eventtype, reason, message string) bool {
+	if unlimitedEventReasons[reason] {
+		return true
+	}
+	key, ok := eventObjectKey(object)
+	if !ok {
+		return true
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	k := eventKey{object: key, reason: reason}
+	bucket, found := r.buckets[k]
+	if !found {
+		bucket = &eventBucket{tokens: float64(r.burst), lastRefill: now}
+		r.buckets[k] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.lastRefill).Minutes() * r.perMinute
+	if bucket.tokens > float64(r.burst) {
+		bucket.tokens = float64(r.burst)
+	}
+	bucket.lastRefill = now
+
+	duplicate := bucket.lastType == eventtype && bucket.lastMessage == message && now.Sub(bucket.lastEmitted) < r.summaryInterval
+	if duplicate || bucket.tokens < 1 {
+		bucket.object = object
+		bucket.suppressed++
+		bucket.suppressedType = eventtype
+		return false
+	}
+	bucket.tokens--
+	bucket.lastType = eventtype
+	bucket.lastMessage = message
+	bucket.lastEmitted = now
+	return true
+}
+
+// Start emits the summaries of the suppressed events every summary interval until the context is done, this
+// implements the Runnable interface of controller-runtime Manager, so we can use mgr.Add() to start this component.
+func (r *RateLimitedEventRecorder) Start(ctx context.Context) error {
+	r.logger.Info("Starting rate limiting of the events", "perObjectPerMinute", r.perMinute, "burst", r.burst, "summaryInterval", r.summaryInterval)
+	ticker := time.NewTicker(r.summaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.EmitSummaries()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection returns false as every replica emits its own events
+func (r *RateLimitedEventRecorder) NeedLeaderElection() bool {
+	return false
+}
+
+// EmitSummaries emits an event with the number of the suppressed events of every object and reason since the
+// previous summary, and forgets the objects without recent events
+func (r *RateLimitedEventRecorder) EmitSummaries() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	for key, bucket := range r.buckets {
+		if bucket.suppressed > 0 {
+			r.next.Eventf(bucket.object, bucket.suppressedType, key.reason, "suppressed %d similar events", bucket.suppressed)
+			bucket.object = nil
+			bucket.suppressed = 0
+			continue
+		}
+		// the bucket is full again, a new one behaves the same
+		if now.Sub(bucket.lastRefill).Minutes()*r.perMinute+bucket.tokens >= float64(r.burst) && now.Sub(bucket.lastEmitted) >= r.summaryInterval {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// eventObjectKey returns the key of the involved object of an event, its UID or its kind, namespace and name
+func eventObjectKey(object runtime.Object) (string, bool) {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return "", false
+	}
+	if uid := accessor.GetUID(); uid != "" {
+		return string(uid), true
+	}
+	return fmt.Sprintf("%T/%s/%s", object, accessor.GetNamespace(), accessor.GetName()), true
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+func newTestEventRecorder(t *testing.T, now *time.Time) (*RateLimitedEventRecorder, *record.FakeRecorder) {
+	t.Helper()
+	fake := record.NewFakeRecorder(100)
+	recorder, err := NewRateLimitedEventRecorder(fake, 2, 3, time.Minute, logr.Discard())
+	require.NoError(t, err)
+	recorder.now = func() time.Time { return *now }
+	return recorder, fake
+}
+
+func testEventObject(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)}}
+}
+
+func recordedEvents(fake *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-fake.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestRateLimitedEventRecorderSuppression(t *testing.T) {
+	now := time.Unix(1000, 0)
+	recorder, fake := newTestEventRecorder(t, &now)
+	object := testEventObject("a")
+
+	// the burst of 3 events is emitted
+	for i := 0; i < 10; i++ {
+		recorder.Eventf(object, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, "error %d", i)
+	}
+	assert.Equal(t, []string{
+		"Warning KEDAScalerFailed error 0",
+		"Warning KEDAScalerFailed error 1",
+		"Warning KEDAScalerFailed error 2",
+	}, recordedEvents(fake))
+
+	// the limit is per object and reason
+	recorder.Event(testEventObject("b"), corev1.EventTypeWarning, eventreason.KEDAScalerFailed, "error")
+	recorder.Event(object, corev1.EventTypeWarning, eventreason.KEDAScalersStarted, "error")
+	assert.Len(t, recordedEvents(fake), 2)
+
+	// 2 events per minute are refilled
+	now = now.Add(30 * time.Second)
+	recorder.Event(object, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, "error 10")
+	recorder.Event(object, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, "error 11")
+	assert.Equal(t, []string{"Warning KEDAScalerFailed error 10"}, recordedEvents(fake))
+}
+
+func TestRateLimitedEventRecorderDuplicates(t *testing.T) {
+	now := time.Unix(1000, 0)
+	recorder, fake := newTestEventRecorder(t, &now)
+	object := testEventObject("a")
+
+	recorder.Event(object, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, "error")
+	recorder.Event(object, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, "error")
+	recorder.Event(object, corev1.EventTypeNormal, eventreason.KEDAScalerFailed, "error")
+	assert.Equal(t, []string{
+		"Warning KEDAScalerFailed error",
+		"Normal KEDAScalerFailed error",
+	}, recordedEvents(fake))
+
+	// the same event is emitted again after the summary interval
+	now = now.Add(30 * time.Second)
+	recorder.Event(object, corev1.EventTypeNormal, eventreason.KEDAScalerFailed, "error")
+	assert.Empty(t, recordedEvents(fake))
+	now = now.Add(30 * time.Second)
+	recorder.Event(object, corev1.EventTypeNormal, eventreason.KEDAScalerFailed, "error")
+	assert.Equal(t, []string{"Normal KEDAScalerFailed error"}, recordedEvents(fake))
+}
+
+func TestRateLimitedEventRecorderSummaries(t *testing.T) {
+	now := time.Unix(1000, 0)
+	recorder, fake := newTestEventRecorder(t, &now)
+	object := testEventObject("a")
+
+	for i := 0; i < 315; i++ {
+		recorder.Eventf(object, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, "error %d", i)
+	}
+	recorder.Event(testEventObject("b"), corev1.EventTypeNormal, eventreason.KEDAScalersStarted, "started")
+	recordedEvents(fake)
+
+	now = now.Add(time.Minute)
+	recorder.EmitSummaries()
+	assert.Equal(t, []string{"Warning KEDAScalerFailed suppressed 312 similar events"}, recordedEvents(fake))
+
+	// the counts are reset by the summaries
+	recorder.EmitSummaries()
+	assert.Empty(t, recordedEvents(fake))
+
+	// the idle objects are forgotten once their buckets are full again
+	now = now.Add(2 * time.Minute)
+	recorder.EmitSummaries()
+	assert.Empty(t, recorder.buckets)
+}
+
+func TestRateLimitedEventRecorderBypass(t *testing.T) {
+	now := time.Unix(1000, 0)
+	recorder, fake := newTestEventRecorder(t, &now)
+	object := testEventObject("a")
+
+	for reason := range unlimitedEventReasons {
+		for i := 0; i < 5; i++ {
+			recorder.Event(object, corev1.EventTypeNormal, reason, "transition")
+		}
+	}
+	assert.Len(t, recordedEvents(fake), 5*len(unlimitedEventReasons))
+
+	recorder.EmitSummaries()
+	assert.Empty(t, recordedEvents(fake))
+	assert.True(t, unlimitedEventReasons[eventreason.ScaledObjectReady])
+	assert.True(t, unlimitedEventReasons[eventreason.ScaledJobReady])
+}
+
+func TestNewRateLimitedEventRecorderErrors(t *testing.T) {
+	fake := record.NewFakeRecorder(1)
+	_, err := NewRateLimitedEventRecorder(fake, 0, 1, time.Minute, logr.Discard())
+	assert.Error(t, err)
+	_, err = NewRateLimitedEventRecorder(fake, 1, 0, time.Minute, logr.Discard())
+	assert.Error(t, err)
+	_, err = NewRateLimitedEventRecorder(fake, 1, 1, 0, logr.Discard())
+	assert.Error(t, err)
+}
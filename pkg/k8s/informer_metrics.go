@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+// eventHandlerRegistrar is implemented by both client-go and controller-runtime informers
+type eventHandlerRegistrar interface {
+	AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error)
+}
+
+// RecordInformerSyncs records the full syncs of the informer cache of the kind: the initial list
+// and the periodic resyncs, which are delivered as updates without a change of the resource version
+func RecordInformerSyncs(ctx context.Context, informer eventHandlerRegistrar, kind string) error {
+	registration, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if isResync(oldObj, newObj) {
+				prommetrics.RecordInformerCacheSync(kind, time.Now())
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if cache.WaitForCacheSync(ctx.Done(), registration.HasSynced) {
+			prommetrics.RecordInformerCacheSync(kind, time.Now())
+		}
+	}()
+	return nil
+}
+
+func isResync(oldObj, newObj interface{}) bool {
+	oldMeta, err := meta.Accessor(oldObj)
+	if err != nil {
+		return false
+	}
+	newMeta, err := meta.Accessor(newObj)
+	if err != nil {
+		return false
+	}
+	return oldMeta.GetResourceVersion() == newMeta.GetResourceVersion()
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func TestRecordInformerSyncs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &corev1.SecretList{
+				ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+				Items:    []corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "keda", ResourceVersion: "1"}}},
+			}, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	}
+	informer := cache.NewSharedIndexInformer(listWatch, &corev1.Secret{}, 200*time.Millisecond, cache.Indexers{})
+	assert.NoError(t, RecordInformerSyncs(ctx, informer, "TestSecret"))
+
+	start := time.Now()
+	go informer.Run(ctx.Done())
+
+	// the initial list is recorded
+	assert.Eventually(t, func() bool {
+		return getInformerCacheSync(t, "TestSecret") >= float64(start.UnixNano())/1e9
+	}, 5*time.Second, 10*time.Millisecond)
+	initialSync := getInformerCacheSync(t, "TestSecret")
+
+	// and the gauge advances on the next resync
+	assert.Eventually(t, func() bool {
+		return getInformerCacheSync(t, "TestSecret") > initialSync
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func getInformerCacheSync(t *testing.T, kind string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_informer_cache_sync_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "kind" && label.GetValue() == kind {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
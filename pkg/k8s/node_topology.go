@@ -0,0 +1,34 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetNodeRegion returns the topology.kubernetes.io/region label of the node, empty if the node has no region
+func GetNodeRegion(ctx context.Context, reader client.Reader, nodeName string) (string, error) {
+	node := &corev1.Node{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		return "", fmt.Errorf("error getting the node %s: %w", nodeName, err)
+	}
+	return node.Labels[corev1.LabelTopologyRegion], nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetNodeRegion(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "regional", Labels: map[string]string{corev1.LabelTopologyRegion: "eu-west-1"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}},
+	).Build()
+
+	region, err := GetNodeRegion(context.Background(), kubeClient, "regional")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", region)
+
+	region, err = GetNodeRegion(context.Background(), kubeClient, "unlabeled")
+	assert.NoError(t, err)
+	assert.Equal(t, "", region)
+
+	_, err = GetNodeRegion(context.Background(), kubeClient, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RestartCounterConfigMapName is the name of the ConfigMap persisting the restart count of the operator
+	RestartCounterConfigMapName = "keda-operator-restarts"
+	restartCounterKey           = "restarts"
+)
+
+// IncrementRestartCount counts a start of an operator replica in the keda-operator-restarts ConfigMap of the
+// namespace and returns the restart count. The first start creates the ConfigMap with a count of 0, every later
+// start, whether the container crashed or the replica was recreated, increments it
+func IncrementRestartCount(ctx context.Context, reader client.Reader, writer client.Writer, namespace string) (int64, error) {
+	var restarts int64
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: RestartCounterConfigMapName}, configMap)
+		if apierrors.IsNotFound(err) {
+			restarts = 0
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: RestartCounterConfigMapName},
+				Data:       map[string]string{restartCounterKey: "0"},
+			}
+			err = writer.Create(ctx, configMap)
+			if apierrors.IsAlreadyExists(err) {
+				// another replica created it first, it's read again as a conflict
+				return apierrors.NewConflict(corev1.Resource("configmaps"), RestartCounterConfigMapName, err)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		restarts = 0
+		if value, ok := configMap.Data[restartCounterKey]; ok {
+			// a corrupted count starts over
+			if count, err := strconv.ParseInt(value, 10, 64); err == nil && count >= 0 {
+				restarts = count
+			}
+		}
+		restarts++
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[restartCounterKey] = strconv.FormatInt(restarts, 10)
+		return writer.Update(ctx, configMap)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error counting the restart in the ConfigMap %s/%s: %w", namespace, RestartCounterConfigMapName, err)
+	}
+	return restarts, nil
+}
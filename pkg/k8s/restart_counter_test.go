@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIncrementRestartCount(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().Build()
+
+	// every start after the first one is a restart
+	for _, expected := range []int64{0, 1, 2, 3} {
+		restarts, err := IncrementRestartCount(context.Background(), kubeClient, kubeClient, "keda")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, restarts)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, kubeClient.Get(context.Background(), client.ObjectKey{Namespace: "keda", Name: RestartCounterConfigMapName}, configMap))
+	assert.Equal(t, "3", configMap.Data["restarts"])
+
+	// the count is per namespace
+	restarts, err := IncrementRestartCount(context.Background(), kubeClient, kubeClient, "other")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), restarts)
+}
+
+func TestIncrementRestartCountCorrupted(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "keda", Name: RestartCounterConfigMapName}, Data: map[string]string{"restarts": "not-a-number"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "empty", Name: RestartCounterConfigMapName}},
+	).Build()
+
+	restarts, err := IncrementRestartCount(context.Background(), kubeClient, kubeClient, "keda")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), restarts)
+
+	restarts, err = IncrementRestartCount(context.Background(), kubeClient, kubeClient, "empty")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), restarts)
+}
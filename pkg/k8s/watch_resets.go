@@ -0,0 +1,168 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+// WatchResetsRecorder counts the watches of the operator ended by the API server or the network before their
+// timeout. Each watch is tracked on its own connection, the informers watching the same resources don't mix up
+type WatchResetsRecorder struct {
+	mapper meta.RESTMapper
+	lock   sync.Mutex
+	now    func() time.Time
+}
+
+// NewWatchResetsRecorder creates a recorder, its Wrap must be set as transport wrapper of the rest config
+// of the clients
+func NewWatchResetsRecorder() *WatchResetsRecorder {
+	return &WatchResetsRecorder{
+		now: time.Now,
+	}
+}
+
+// SetRESTMapper sets the mapper turning the watched resources into kinds, the resources are recorded until it's set
+func (r *WatchResetsRecorder) SetRESTMapper(mapper meta.RESTMapper) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.mapper = mapper
+}
+
+// Wrap returns a round tripper recording the watches opened through rt
+func (r *WatchResetsRecorder) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return &watchResetsRoundTripper{recorder: r, next: rt}
+}
+
+type watchResetsRoundTripper struct {
+	recorder *WatchResetsRecorder
+	next     http.RoundTripper
+}
+
+func (rt *watchResetsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query()
+	if req.Method != http.MethodGet || (query.Get("watch") != "true" && query.Get("watch") != "1") {
+		return rt.next.RoundTrip(req)
+	}
+	gvr, ok := parseResourcePath(req.URL.Path)
+	if !ok {
+		return rt.next.RoundTrip(req)
+	}
+
+	start := rt.recorder.now()
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body := &watchBody{ReadCloser: resp.Body, recorder: rt.recorder, ctx: req.Context(), resource: gvr, start: start}
+	if timeoutSeconds, err := strconv.ParseInt(query.Get("timeoutSeconds"), 10, 64); err == nil {
+		body.timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// watchBody is the body of a watch response, the end of the watch is known when its reading fails
+type watchBody struct {
+	io.ReadCloser
+	recorder *WatchResetsRecorder
+	ctx      context.Context
+	resource schema.GroupVersionResource
+	start    time.Time
+	timeout  time.Duration
+	ended    sync.Once
+}
+
+func (b *watchBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.ended.Do(func() {
+			if b.isReset(err) {
+				b.recorder.recordReset(b.resource)
+			}
+		})
+	}
+	return n, err
+}
+
+// Close ends the watch closed by the client, e.g. by a stopped informer, which isn't a reset
+func (b *watchBody) Close() error {
+	b.ended.Do(func() {})
+	return b.ReadCloser.Close()
+}
+
+// isReset returns true if the watch ended with an error, or was closed by the API server before its timeout. A
+// watch without timeout is ended by the API server at any time
+func (b *watchBody) isReset(err error) bool {
+	if b.ctx.Err() != nil {
+		return false
+	}
+	if !errors.Is(err, io.EOF) {
+		return true
+	}
+	return b.timeout > 0 && b.recorder.now().Sub(b.start) < b.timeout
+}
+
+func (r *WatchResetsRecorder) recordReset(gvr schema.GroupVersionResource) {
+	r.lock.Lock()
+	mapper := r.mapper
+	r.lock.Unlock()
+
+	kind := gvr.Resource
+	if mapper != nil {
+		if gvk, err := mapper.KindFor(gvr); err == nil {
+			kind = gvk.Kind
+		}
+	}
+	prommetrics.RecordOperatorWatchReset(kind)
+}
+
+// parseResourcePath returns the resource of an API path, /api/{version}/[namespaces/{namespace}/]{resource}
+// or /apis/{group}/{version}/[namespaces/{namespace}/]{resource}
+func parseResourcePath(urlPath string) (schema.GroupVersionResource, bool) {
+	gvr := schema.GroupVersionResource{}
+	parts := strings.Split(strings.Trim(urlPath, "/"), "/")
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		gvr.Version, parts = parts[1], parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		gvr.Group, gvr.Version, parts = parts[1], parts[2], parts[3:]
+	default:
+		return gvr, false
+	}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	if len(parts) != 1 {
+		return gvr, false
+	}
+	gvr.Resource = parts[0]
+	return gvr, true
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+type bodyRoundTripper struct {
+	body io.ReadCloser
+}
+
+func (rt bodyRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: rt.body}, nil
+}
+
+func TestWatchResetsRecorder(t *testing.T) {
+	now := time.Now()
+	recorder := NewWatchResetsRecorder()
+	recorder.now = func() time.Time { return now }
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: "TestWatchedObject"}, meta.RESTScopeNamespace)
+	recorder.SetRESTMapper(mapper)
+
+	ended := func() io.ReadCloser { return io.NopCloser(strings.NewReader("")) }
+	broken := func() io.ReadCloser { return io.NopCloser(iotest.ErrReader(io.ErrUnexpectedEOF)) }
+	watch := func(ctx context.Context, url string, body io.ReadCloser) io.ReadCloser {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://kubernetes"+url, nil)
+		require.NoError(t, err)
+		resp, err := recorder.Wrap(bodyRoundTripper{body: body}).RoundTrip(req)
+		require.NoError(t, err)
+		return resp.Body
+	}
+	read := func(body io.ReadCloser) {
+		_, _ = io.ReadAll(body)
+		_ = body.Close()
+	}
+	watchURL := "/apis/keda.sh/v1alpha1/namespaces/keda/testwatchedobjects?watch=true&timeoutSeconds=300"
+	// the counter is process global, so only the resets of this test are asserted
+	watchedBefore := getWatchResets(t, "TestWatchedObject")
+	unmappedBefore := getWatchResets(t, "testunmappedobjects")
+
+	// the lists aren't watches
+	read(watch(context.Background(), "/apis/keda.sh/v1alpha1/namespaces/keda/testwatchedobjects?limit=500", broken()))
+	assert.Equal(t, watchedBefore, getWatchResets(t, "TestWatchedObject"))
+
+	// the overlapping watches of the same resource ended at their timeout aren't resets
+	first := watch(context.Background(), watchURL, ended())
+	now = now.Add(10 * time.Second)
+	second := watch(context.Background(), watchURL, ended())
+	now = now.Add(300 * time.Second)
+	read(first)
+	now = now.Add(10 * time.Second)
+	read(second)
+	assert.Equal(t, watchedBefore, getWatchResets(t, "TestWatchedObject"))
+
+	// a watch ended by the API server before its timeout is a reset
+	early := watch(context.Background(), watchURL, ended())
+	now = now.Add(10 * time.Second)
+	read(early)
+	assert.Equal(t, watchedBefore+1, getWatchResets(t, "TestWatchedObject"))
+
+	// as is a watch ended with an error
+	read(watch(context.Background(), watchURL, broken()))
+	assert.Equal(t, watchedBefore+2, getWatchResets(t, "TestWatchedObject"))
+
+	// the watches closed by the client or canceled aren't
+	closed := watch(context.Background(), watchURL, broken())
+	_ = closed.Close()
+	_, _ = io.ReadAll(closed)
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := watch(ctx, watchURL, broken())
+	cancel()
+	read(canceled)
+	assert.Equal(t, watchedBefore+2, getWatchResets(t, "TestWatchedObject"))
+
+	// a watch without timeout is only reset by an error
+	read(watch(context.Background(), "/api/v1/testunmappedobjects?watch=1", ended()))
+	assert.Equal(t, unmappedBefore, getWatchResets(t, "testunmappedobjects"))
+	read(watch(context.Background(), "/api/v1/testunmappedobjects?watch=1", broken()))
+	assert.Equal(t, unmappedBefore+1, getWatchResets(t, "testunmappedobjects"))
+}
+
+func TestWatchResetsRecorderWithInformer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the API server closes every watch right away, as a broken connection would
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") == "true" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fmt.Fprint(w, `{"kind":"ConfigMapList","apiVersion":"v1","metadata":{"resourceVersion":"1"},"items":[]}`)
+	}))
+	defer server.Close()
+
+	recorder := NewWatchResetsRecorder()
+	config := &rest.Config{Host: server.URL, WrapTransport: recorder.Wrap}
+	clientset, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	recorder.SetRESTMapper(mapper)
+
+	before := getWatchResets(t, "ConfigMap")
+	factory := kubeinformers.NewSharedInformerFactoryWithOptions(clientset, time.Hour, kubeinformers.WithNamespace("keda"))
+	factory.Core().V1().ConfigMaps().Informer()
+	factory.Start(ctx.Done())
+
+	assert.Eventually(t, func() bool {
+		return getWatchResets(t, "ConfigMap") >= before+1
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestWatchResetsRecorderWithOverlappingInformers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the API server keeps the watches open, two informers of two clients watch the same secrets
+	var watches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") == "true" {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			watches.Add(1)
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, `{"kind":"SecretList","apiVersion":"v1","metadata":{"resourceVersion":"1"},"items":[]}`)
+	}))
+	defer server.Close()
+
+	recorder := NewWatchResetsRecorder()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+	recorder.SetRESTMapper(mapper)
+	before := getWatchResets(t, "Secret")
+	for i := 0; i < 2; i++ {
+		clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL, WrapTransport: recorder.Wrap})
+		require.NoError(t, err)
+		factory := kubeinformers.NewSharedInformerFactory(clientset, time.Hour)
+		factory.Core().V1().Secrets().Informer()
+		factory.Start(ctx.Done())
+	}
+
+	assert.Eventually(t, func() bool {
+		return watches.Load() >= 2
+	}, 10*time.Second, 50*time.Millisecond)
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, before, getWatchResets(t, "Secret"))
+}
+
+func TestParseResourcePath(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		expected schema.GroupVersionResource
+		ok       bool
+	}{
+		"core":                 {path: "/api/v1/secrets", expected: schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, ok: true},
+		"core namespaced":      {path: "/api/v1/namespaces/keda/secrets", expected: schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, ok: true},
+		"namespaces":           {path: "/api/v1/namespaces", expected: schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, ok: true},
+		"group namespaced":     {path: "/apis/keda.sh/v1alpha1/namespaces/keda/scaledobjects", expected: schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"}, ok: true},
+		"group cluster scoped": {path: "/apis/keda.sh/v1alpha1/clustertriggerauthentications", expected: schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "clustertriggerauthentications"}, ok: true},
+		"object":               {path: "/apis/keda.sh/v1alpha1/namespaces/keda/scaledobjects/name", ok: false},
+		"discovery":            {path: "/apis", ok: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gvr, ok := parseResourcePath(test.path)
+			assert.Equal(t, test.ok, ok)
+			if test.ok {
+				assert.Equal(t, test.expected, gvr)
+			}
+		})
+	}
+}
+
+func getWatchResets(t *testing.T, kind string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_operator_watch_resets_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "kind" && label.GetValue() == kind {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
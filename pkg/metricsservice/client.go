@@ -24,11 +24,13 @@ import (
 	"github.com/go-logr/logr"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 	"k8s.io/metrics/pkg/apis/external_metrics/v1beta1"
 
 	"github.com/kedacore/keda/v2/pkg/metricsservice/api"
 	"github.com/kedacore/keda/v2/pkg/metricsservice/utils"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 type GrpcClient struct {
@@ -56,6 +58,7 @@ func NewGrpcClient(url, certDir string) (*GrpcClient, error) {
 	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(creds),
 		grpc.WithDefaultServiceConfig(retryPolicy),
+		grpc.WithChainUnaryInterceptor(correlationIDClientInterceptor),
 	}
 	conn, err := grpc.Dial(url, opts...)
 	if err != nil {
@@ -80,6 +83,15 @@ func (c *GrpcClient) GetMetrics(ctx context.Context, scaledObjectName, scaledObj
 	return extMetrics, nil
 }
 
+// correlationIDClientInterceptor sends the correlation ID of the request context as gRPC metadata, so the operator
+// logs the scaler calls of a metrics request with the ID logged by the metrics server
+func correlationIDClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if correlationID := kedautil.CorrelationIDFromContext(ctx); correlationID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, kedautil.CorrelationIDMetadataKey, correlationID)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
 // WaitForConnectionReady waits for gRPC connection to be ready
 // returns true if the connection was successful, false if we hit a timeut from context
 func (c *GrpcClient) WaitForConnectionReady(ctx context.Context, logger logr.Logger) bool {
@@ -103,6 +115,16 @@ func (c *GrpcClient) WaitForConnectionReady(ctx context.Context, logger logr.Log
 	return true
 }
 
+// IsConnectionReady returns true if the gRPC connection to KEDA Metrics Server is ready,
+// an idle connection is asked to reconnect so the next check reflects the current state
+func (c *GrpcClient) IsConnectionReady() bool {
+	currentState := c.connection.GetState()
+	if currentState == connectivity.Idle {
+		c.connection.Connect()
+	}
+	return currentState == connectivity.Ready
+}
+
 // GetServerURL returns url of the gRPC server this client is connected to
 func (c *GrpcClient) GetServerURL() string {
 	return c.connection.Target()
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// roundTrip passes the context through the client and server interceptors and returns the context the
+// handler of the metrics service gets
+func roundTrip(ctx context.Context, t *testing.T) context.Context {
+	var handlerCtx context.Context
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		_, err := correlationIDServerInterceptor(metadata.NewIncomingContext(context.Background(), md), nil, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				handlerCtx = ctx
+				return nil, nil
+			})
+		return err
+	}
+	assert.NoError(t, correlationIDClientInterceptor(ctx, "/api.MetricsService/GetMetrics", nil, nil, nil, invoker))
+	return handlerCtx
+}
+
+func TestCorrelationIDInterceptors(t *testing.T) {
+	ctx := roundTrip(kedautil.ContextWithCorrelationID(context.Background(), "correlation"), t)
+	assert.Equal(t, "correlation", kedautil.CorrelationIDFromContext(ctx))
+
+	ctx = roundTrip(context.Background(), t)
+	assert.Equal(t, "", kedautil.CorrelationIDFromContext(ctx))
+}
@@ -22,12 +22,14 @@ import (
 	"net"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"k8s.io/metrics/pkg/apis/external_metrics/v1beta1"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/kedacore/keda/v2/pkg/metricsservice/api"
 	"github.com/kedacore/keda/v2/pkg/metricsservice/utils"
 	"github.com/kedacore/keda/v2/pkg/scaling"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 var log = logf.Log.WithName("grpc_server")
@@ -54,11 +56,21 @@ func (s *GrpcServer) GetMetrics(ctx context.Context, in *api.ScaledObjectRef) (*
 		return v1beta1ExtMetrics, fmt.Errorf("error when converting metric values %w", err)
 	}
 
-	log.V(1).WithValues("scaledObjectName", in.Name, "scaledObjectNamespace", in.Namespace, "metrics", v1beta1ExtMetrics).Info("Providing metrics")
+	log.V(1).WithValues("scaledObjectName", in.Name, "scaledObjectNamespace", in.Namespace, "correlationID", kedautil.CorrelationIDFromContext(ctx), "metrics", v1beta1ExtMetrics).Info("Providing metrics")
 
 	return v1beta1ExtMetrics, nil
 }
 
+// correlationIDServerInterceptor puts the correlation ID sent by the metrics server in the request context
+func correlationIDServerInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(kedautil.CorrelationIDMetadataKey); len(values) > 0 && values[0] != "" {
+			ctx = kedautil.ContextWithCorrelationID(ctx, values[0])
+		}
+	}
+	return handler(ctx, req)
+}
+
 // NewGrpcServer creates a new instance of GrpcServer
 func NewGrpcServer(scaleHandler *scaling.ScaleHandler, address, certDir string, certsReady chan struct{}) GrpcServer {
 	return GrpcServer{
@@ -91,7 +103,7 @@ func (s *GrpcServer) Start(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		s.server = grpc.NewServer(grpc.Creds(creds))
+		s.server = grpc.NewServer(grpc.Creds(creds), grpc.UnaryInterceptor(correlationIDServerInterceptor))
 		api.RegisterMetricsServiceServer(s.server, s)
 	}
 
@@ -79,6 +79,21 @@ func (mr *MockScaleHandlerMockRecorder) GetScaledObjectMetrics(ctx, scaledObject
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScaledObjectMetrics", reflect.TypeOf((*MockScaleHandler)(nil).GetScaledObjectMetrics), ctx, scaledObjectName, scaledObjectNamespace, metricName)
 }
 
+// GetBuiltScalersCache mocks base method.
+func (m *MockScaleHandler) GetBuiltScalersCache(scalableObject interface{}) (*cache.ScalersCache, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBuiltScalersCache", scalableObject)
+	ret0, _ := ret[0].(*cache.ScalersCache)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetBuiltScalersCache indicates an expected call of GetBuiltScalersCache.
+func (mr *MockScaleHandlerMockRecorder) GetBuiltScalersCache(scalableObject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBuiltScalersCache", reflect.TypeOf((*MockScaleHandler)(nil).GetBuiltScalersCache), scalableObject)
+}
+
 // GetScalersCache mocks base method.
 func (m *MockScaleHandler) GetScalersCache(ctx context.Context, scalableObject interface{}) (*cache.ScalersCache, error) {
 	m.ctrl.T.Helper()
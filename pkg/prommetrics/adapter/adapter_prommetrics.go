@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	DefaultPromMetricsNamespace = "keda"
+)
+
+var (
+	operatorReachable = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "metricsadapter",
+			Name:      "operator_reachable",
+			Help:      "Whether the KEDA Operator gRPC Metrics Service is reachable from the Metrics Adapter (1) or not (0)",
+		},
+	)
+	registeredMetrics = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "metricsadapter",
+			Name:      "registered_metrics",
+			Help:      "Number of external metric names of the ScaledObjects served by the Metrics Adapter",
+		},
+	)
+	batchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "metricsadapter",
+			Name:      "batch_size",
+			Help:      "Number of metric values in the responses of the Metrics Adapter to the external metric requests of the HPAs",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(operatorReachable)
+	metrics.Registry.MustRegister(registeredMetrics)
+	metrics.Registry.MustRegister(batchSize)
+}
+
+// RecordOperatorReachable sets the reachability of the KEDA Operator gRPC Metrics Service
+func RecordOperatorReachable(reachable bool) {
+	value := 0
+	if reachable {
+		value = 1
+	}
+	operatorReachable.Set(float64(value))
+}
+
+// RecordRegisteredMetrics sets the number of external metric names served by the Metrics Adapter
+func RecordRegisteredMetrics(count int) {
+	registeredMetrics.Set(float64(count))
+}
+
+// RecordBatchSize observes the number of metric values of a response to an external metric request
+func RecordBatchSize(size int) {
+	batchSize.Observe(float64(size))
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prommetrics
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+)
+
+// throttledLatency is the time a request waits for the client side rate limiter from which it counts as
+// throttled, client-go logs the throttling from the same wait
+const throttledLatency = 50 * time.Millisecond
+
+// rateLimiterLatencyAdapter counts the requests to the API server throttled by the client side rate limiter of
+// client-go, and passes the latencies on to the metric it replaces
+type rateLimiterLatencyAdapter struct {
+	next clientmetrics.LatencyMetric
+}
+
+func (a rateLimiterLatencyAdapter) Observe(ctx context.Context, verb string, u url.URL, latency time.Duration) {
+	if latency >= throttledLatency {
+		operatorAPIServerThrottled.With(prometheus.Labels{"verb": verb}).Inc()
+	}
+	a.next.Observe(ctx, verb, u, latency)
+}
+
+// RegisterAPIServerThrottling counts the requests of the process to the API server throttled by the client
+// side rate limiter in keda_operator_apiserver_throttled_total, the client-go metrics can only be registered
+// once and controller-runtime already does it so the rate limiter metric is replaced directly
+func RegisterAPIServerThrottling() {
+	if _, ok := clientmetrics.RateLimiterLatency.(rateLimiterLatencyAdapter); ok {
+		return
+	}
+	clientmetrics.RateLimiterLatency = rateLimiterLatencyAdapter{next: clientmetrics.RateLimiterLatency}
+}
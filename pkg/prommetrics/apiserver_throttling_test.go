@@ -0,0 +1,72 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prommetrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestRateLimiterLatencyAdapter(t *testing.T) {
+	operatorAPIServerThrottled.Reset()
+	adapter := rateLimiterLatencyAdapter{next: noopLatency{}}
+
+	adapter.Observe(context.Background(), "GET", url.URL{}, time.Millisecond)
+	adapter.Observe(context.Background(), "GET", url.URL{}, 200*time.Millisecond)
+	adapter.Observe(context.Background(), "PATCH", url.URL{}, time.Second)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(operatorAPIServerThrottled.With(prometheus.Labels{"verb": "GET"})))
+	assert.Equal(t, float64(1), testutil.ToFloat64(operatorAPIServerThrottled.With(prometheus.Labels{"verb": "PATCH"})))
+}
+
+func TestRegisterAPIServerThrottling(t *testing.T) {
+	operatorAPIServerThrottled.Reset()
+	RegisterAPIServerThrottling()
+	// registering again doesn't count the requests twice
+	RegisterAPIServerThrottling()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"keda"}}`))
+	}))
+	defer server.Close()
+
+	// a burst of 1 request at 5 QPS delays the following requests by 200ms
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL, QPS: 5, Burst: 1})
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := client.CoreV1().Namespaces().Get(context.Background(), "keda", metav1.GetOptions{})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(operatorAPIServerThrottled.With(prometheus.Labels{"verb": "GET"})))
+}
+
+type noopLatency struct{}
+
+func (noopLatency) Observe(context.Context, string, url.URL, time.Duration) {}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prommetrics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// FileExporter periodically writes the gathered metrics to a file in the Prometheus text format,
+// for the clusters where the metrics endpoint can't be scraped
+type FileExporter struct {
+	gatherer prometheus.Gatherer
+	path     string
+	interval time.Duration
+	logger   logr.Logger
+}
+
+// NewFileExporter creates an exporter writing the metrics of the gatherer to path every interval
+func NewFileExporter(gatherer prometheus.Gatherer, path string, interval time.Duration, logger logr.Logger) (*FileExporter, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the path of the metrics file can't be empty")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("the interval of the metrics file export must be positive, %s given", interval)
+	}
+	return &FileExporter{
+		gatherer: gatherer,
+		path:     path,
+		interval: interval,
+		logger:   logger,
+	}, nil
+}
+
+// Start writes the metrics every interval until the context is done, this implements the Runnable interface
+// of controller-runtime Manager, so we can use mgr.Add() to start this component.
+func (e *FileExporter) Start(ctx context.Context) error {
+	e.logger.Info("Starting metrics file export", "path", e.path, "interval", e.interval)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		if err := e.WriteMetrics(); err != nil {
+			e.logger.Error(err, "error writing the metrics file", "path", e.path)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection returns false as every replica exports its own metrics
+func (e *FileExporter) NeedLeaderElection() bool {
+	return false
+}
+
+// WriteMetrics gathers the metrics and replaces the file with them, the metrics are written to a temporary file
+// renamed over the previous one, so readers never see a partially written file
+func (e *FileExporter) WriteMetrics() error {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("error gathering the metrics: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(e.path), filepath.Base(e.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating the temporary metrics file: %w", err)
+	}
+	// the temporary file is already renamed when everything went fine
+	defer os.Remove(file.Name())
+
+	encoder := expfmt.NewEncoder(file, expfmt.FmtText)
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			file.Close()
+			return fmt.Errorf("error encoding the metric family %s: %w", family.GetName(), err)
+		}
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("error syncing the temporary metrics file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing the temporary metrics file: %w", err)
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return fmt.Errorf("error setting the permissions of the temporary metrics file: %w", err)
+	}
+	if err := os.Rename(file.Name(), e.path); err != nil {
+		return fmt.Errorf("error replacing the metrics file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prommetrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileExporterWritesMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_dumps_total", Help: "Test counter"}, []string{"scaler"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_queue_length", Help: "Test gauge"})
+	registry.MustRegister(counter, gauge)
+	counter.WithLabelValues("kafka").Add(3)
+	gauge.Set(42)
+
+	path := filepath.Join(t.TempDir(), "metrics.prom")
+	exporter, err := NewFileExporter(registry, path, time.Minute, logr.Discard())
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.WriteMetrics())
+
+	expected := `
+# HELP test_dumps_total Test counter
+# TYPE test_dumps_total counter
+test_dumps_total{scaler="kafka"} 3
+# HELP test_queue_length Test gauge
+# TYPE test_queue_length gauge
+test_queue_length 42
+`
+	assertMetricsFile(t, path, expected)
+
+	// the next dump replaces the previous one and doesn't leave temporary files behind
+	gauge.Set(7)
+	assert.NoError(t, exporter.WriteMetrics())
+	assertMetricsFile(t, path, strings.Replace(expected, "test_queue_length 42", "test_queue_length 7", 1))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileExporterStart(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_queue_length", Help: "Test gauge"})
+	registry.MustRegister(gauge)
+
+	path := filepath.Join(t.TempDir(), "metrics.prom")
+	exporter, err := NewFileExporter(registry, path, 10*time.Millisecond, logr.Discard())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- exporter.Start(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		content, err := os.ReadFile(path)
+		return err == nil && strings.Contains(string(content), "test_queue_length 0")
+	}, time.Second, 10*time.Millisecond)
+
+	gauge.Set(5)
+	assert.Eventually(t, func() bool {
+		content, err := os.ReadFile(path)
+		return err == nil && strings.Contains(string(content), "test_queue_length 5")
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestNewFileExporterValidation(t *testing.T) {
+	_, err := NewFileExporter(prometheus.NewRegistry(), "", time.Minute, logr.Discard())
+	assert.Error(t, err)
+	_, err = NewFileExporter(prometheus.NewRegistry(), "metrics.prom", 0, logr.Discard())
+	assert.Error(t, err)
+}
+
+func assertMetricsFile(t *testing.T, path, expected string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimPrefix(expected, "\n"), string(content))
+
+	// the file can be parsed back as a scrape would do
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(strings.NewReader(string(content)))
+	assert.NoError(t, err)
+	assert.Contains(t, families, "test_queue_length")
+}
@@ -17,9 +17,19 @@ limitations under the License.
 package prommetrics
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/metrics/pkg/apis/external_metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -33,6 +43,35 @@ const (
 	ScaledJobResource                    = "scaled_job"
 
 	DefaultPromMetricsNamespace = "keda"
+
+	ScalerErrorTypeAuth    = "auth"
+	ScalerErrorTypeTimeout = "timeout"
+	ScalerErrorTypeBackend = "backend"
+	ScalerErrorTypeConfig  = "config"
+	ScalerErrorTypeUnknown = "unknown"
+)
+
+// the scaler metrics are registered with their first measurement, the registry doesn't allow
+// to change their labels afterwards and they depend on whether the uid label is enabled
+var (
+	scalerMetricsValue              *prometheus.GaugeVec
+	scalerMetricsValueAge           *prometheus.GaugeVec
+	scalerMetricsLatency            *prometheus.GaugeVec
+	scalerActive                    *prometheus.GaugeVec
+	scalerErrors                    *prometheus.CounterVec
+	scaledObjectNegativeValues      *prometheus.CounterVec
+	scaledObjectTriggerContribution *prometheus.GaugeVec
+
+	uidLabelEnabled         bool
+	labelValueMaxLength     int
+	scalerMetricsRegistered atomic.Bool
+	scalerMetricsLock       sync.Mutex
+	scalerMetricsRegisterer prometheus.Registerer = metrics.Registry
+
+	// truncatedLabelValues are the truncated label values of the scaler metrics of each scaled object, so each one is
+	// only counted once
+	truncatedLabelValues     = map[types.NamespacedName]map[string]bool{}
+	truncatedLabelValuesLock sync.Mutex
 )
 
 var (
@@ -46,41 +85,167 @@ var (
 		},
 		[]string{},
 	)
-	scalerMetricsValue = prometheus.NewGaugeVec(
+	scalerExposedMetrics = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: DefaultPromMetricsNamespace,
 			Subsystem: "scaler",
-			Name:      "metrics_value",
-			Help:      "Metric Value used for HPA",
+			Name:      "exposed_metrics",
+			Help:      "Number of distinct external metric names exposed by a scaler",
 		},
-		metricLabels,
+		[]string{"namespace", "scaledObject", "scaler"},
 	)
-	scalerMetricsLatency = prometheus.NewGaugeVec(
+	scalerPartitions = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: DefaultPromMetricsNamespace,
 			Subsystem: "scaler",
-			Name:      "metrics_latency",
-			Help:      "Scaler Metrics Latency",
+			Name:      "partitions",
+			Help:      "Number of partitions or shards observed by a streaming scaler",
 		},
-		metricLabels,
+		[]string{"namespace", "scaledObject", "scaler"},
 	)
-	scalerActive = prometheus.NewGaugeVec(
+	scalerUnhealthyByBackend = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: DefaultPromMetricsNamespace,
 			Subsystem: "scaler",
-			Name:      "active",
-			Help:      "Activity of a Scaler Metric",
+			Name:      "unhealthy_by_backend",
+			Help:      "Number of scalers failing their last health check by the host of their backend",
 		},
-		metricLabels,
+		[]string{"backend"},
 	)
-	scalerErrors = prometheus.NewCounterVec(
+	scalerRebuilds = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: DefaultPromMetricsNamespace,
 			Subsystem: "scaler",
-			Name:      "errors",
-			Help:      "Number of scaler errors",
+			Name:      "rebuilds_total",
+			Help:      "Total number of times a scaler was rebuilt due to a change of the scaled object spec",
+		},
+		[]string{"namespace", "scaledObject", "scaler"},
+	)
+	scalerHTTPResponses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "http_responses_total",
+			Help:      "Total number of HTTP responses received by the scalers, by trigger type and status class",
+		},
+		[]string{"scaler", "status_class"},
+	)
+	scalerValueCoercions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "value_coercions_total",
+			Help:      "Total number of metric values the scalers parsed from a string instead of reading a number from the response",
+		},
+		[]string{"scaler"},
+	)
+	scalerResponseBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "response_bytes",
+			Help:      "Size in bytes of the HTTP response payloads read by the scalers, by trigger type",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		},
+		[]string{"scaler"},
+	)
+	scalerConnectSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "connect_seconds",
+			Help:      "Time spent by the scalers setting up new HTTP connections, including DNS resolution, dialing and TLS handshake",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"scaler"},
+	)
+	scalerQuerySeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "query_seconds",
+			Help:      "Time from sending an HTTP request of a scaler on an established connection to the first byte of its response",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"scaler"},
+	)
+	externalScalerRPCs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "external_scaler",
+			Name:      "rpc_total",
+			Help:      "Total number of gRPC calls to the external scalers by address, method and gRPC status code",
+		},
+		[]string{"address", "method", "status"},
+	)
+	scalerQueryConcurrencyLimit = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "query_concurrency_limit",
+			Help:      "Maximum number of scaler queries running at the same time, 0 if there is no limit",
+		},
+	)
+	scalerQueryConcurrencyActive = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "query_concurrency_active",
+			Help:      "Number of scaler queries currently running",
+		},
+	)
+	scaleLoopsBackingOff = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "internal_scale_loops",
+			Name:      "backing_off",
+			Help:      "Number of scale loops backing off until a scaler query slot is free",
+		},
+	)
+	scalerQueryCoalesced = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "query_coalesced_total",
+			Help:      "Total number of scaler queries served by the result of an identical query in flight instead of querying the backend",
+		},
+		[]string{"metric"},
+	)
+	metricsLabelsTruncated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "metrics",
+			Name:      "labels_truncated_total",
+			Help:      "Total number of distinct label values of the scaler metrics truncated to the maximum label value length",
+		},
+		[]string{"label"},
+	)
+	scalerCAExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "ca_expiry_seconds",
+			Help:      "Unix time in seconds at which the soonest expiring certificate of the custom CA bundle of a scaler expires",
+		},
+		[]string{"namespace", "scaledObject", "scaler"},
+	)
+	scalerPrometheusResultSeries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "prometheus_result_series",
+			Help:      "Number of series returned by the last query of the prometheus scalers of the scaled object",
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scalerPrometheusMultiResults = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "prometheus_multiresult_total",
+			Help:      "Total number of queries of the prometheus scalers of the scaled object returning more than one series",
 		},
-		metricLabels,
+		[]string{"namespace", "scaledObject"},
 	)
 	scaledObjectErrors = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -91,6 +256,265 @@ var (
 		},
 		[]string{"namespace", "scaledObject"},
 	)
+	scaledObjectFallbackInvalid = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "fallback_invalid",
+			Help:      "Whether the fallback configuration of the scaled object is present but invalid (1) or not (0)",
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scaledObjectTargetKind = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "target_kind",
+			Help:      "Kind of the resolved scale target of the scaled object",
+		},
+		[]string{"namespace", "scaledObject", "kind"},
+	)
+	scaledObjectIdleReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "idle_replicas",
+			Help:      "Configured idleReplicaCount of the scaled object, absent when it isn't set",
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scaledObjectDesiredReplicas = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "desired_replicas_distribution",
+			Help:      "Distribution of the replica counts needed by the metric values of the scaled object, observed on each poll",
+			Buckets:   []float64{0, 1, 2, 3, 5, 10, 20, 50, 100, 200, 500, 1000},
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scaledObjectFirstPodReadySeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "first_pod_ready_seconds",
+			Help:      "Time from the activation of the scale target of the scaled object to its first ready pod",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scaledObjectFirstPodReadyTimeouts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "first_pod_ready_timeouts_total",
+			Help:      "Total number of activations of the scale target of the scaled object without a ready pod within the timeout",
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scaleTargetScaledObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaletarget",
+			Name:      "scaledobject_count",
+			Help:      "Number of ScaledObjects referencing the scale target, more than one means their HPAs fight over the replicas",
+		},
+		[]string{"namespace", "kind", "name"},
+	)
+	scaleTargetConflicts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaletarget",
+			Name:      "conflicts_total",
+			Help:      "Total number of times a scale target started being referenced by more than one ScaledObject",
+		},
+	)
+	scaledObjectsByCondition = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Name:      "scaledobjects_by_condition",
+			Help:      "Number of scaled objects currently in each Ready and Active condition reason",
+		},
+		[]string{"condition", "reason"},
+	)
+	scaledObjectsPausedAtReplicas = &pausedAtReplicasCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(DefaultPromMetricsNamespace, "", "scaledobjects_paused_at_replicas"),
+			"Distribution of the replica counts the scaled objects paused with the paused-replicas annotation are currently held at",
+			nil, nil,
+		),
+	}
+	scaledObjectReconcileBudgetExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "reconcile_budget_exceeded_total",
+			Help:      "Total number of times querying the scalers of the scaled object took longer than its polling interval",
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scaledObjectHPAPolicyOverrides = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "hpa_policy_overrides_total",
+			Help:      "Total number of polls where the behavior of the HPA kept the scale target away from the replica count needed by the scaled object metrics",
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scaledObjectDegradedMetrics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "degraded_metrics_total",
+			Help:      "Total number of metric queries of the HPA served with degraded values, reason is fallback when a failing trigger was replaced by its fallback value and partial when the values of the failing triggers were left out",
+		},
+		[]string{"namespace", "scaledObject", "metric", "reason"},
+	)
+	scaledObjectHPAImmutableErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "hpa_immutable_errors_total",
+			Help:      "Total number of HPA updates of the scaled object rejected because they changed an immutable field",
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+	scaledObjectHPAUpdates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "hpa_updates_total",
+			Help:      "Total number of reconciles of the scaled object by whether the HPA was updated or the update was skipped because the HPA already matched the scaled object",
+		},
+		[]string{"namespace", "scaledObject", "result"},
+	)
+	scaledObjectDrivingTrigger = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "driving_trigger_info",
+			Help:      "The trigger of the scaled object with the highest ratio of its value to its target among the metrics served to the HPA, 1 for the trigger driving the scaling",
+		},
+		[]string{"namespace", "scaledObject", "type", "metric"},
+	)
+	scaledObjectReconcileDeferred = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "reconcile_deferred_total",
+			Help:      "Total number of scaled object reconciles deferred because a resource they depend on was not found",
+		},
+		[]string{"namespace", "reason"},
+	)
+	scaledJobAccurateBacklog = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledjob",
+			Name:      "accurate_backlog",
+			Help:      "Number of jobs the accurate scaling strategy of the scaled job computed from the queue length, the running and the pending jobs",
+		},
+		[]string{"namespace", "scaledJob"},
+	)
+	triggerAuthMissingRefs = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "trigger_auth",
+			Name:      "missing_refs",
+			Help:      "Number of TriggerAuthentications and ClusterTriggerAuthentications referencing secrets which don't exist",
+		},
+	)
+	operatorConfigReloads = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "operator",
+			Name:      "config_reloads_total",
+			Help:      "Total number of operator config reload attempts, e.g. of the root CAs of the CA cert dirs",
+		},
+	)
+	operatorConfigReloadErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "operator",
+			Name:      "config_reload_errors_total",
+			Help:      "Total number of failed operator config reload attempts",
+		},
+	)
+	operatorSelfThrottling = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "operator",
+			Name:      "self_throttling",
+			Help:      "Whether the memory or CPU usage of the operator is close to its cgroup limits, 1 under resource pressure and 0 otherwise",
+		},
+	)
+	informerCacheSync = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "informer",
+			Name:      "cache_sync_seconds",
+			Help:      "Time of the last full sync of the informer cache of the operator since unix epoch in seconds",
+		},
+		[]string{"kind"},
+	)
+	operatorWatchResets = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "operator",
+			Name:      "watch_resets_total",
+			Help:      "Total number of watches of the operator to the API server re-established before their timeout, after being closed by the API server or the network",
+		},
+		[]string{"kind"},
+	)
+	runtimeInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Name:      "runtime_info",
+			Help:      "A metric with a constant '1' value labeled by the build, the enabled components and the detected Kubernetes API capabilities of the operator",
+		},
+		[]string{"version", "git_commit", "go_version", "cert_rotation", "scaledobject_generation", "kubernetes_version", "autoscaling_api"},
+	)
+	operatorStartTime = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "operator",
+			Name:      "start_time_seconds",
+			Help:      "Start time of the operator process since unix epoch in seconds, a change means the operator has been restarted",
+		},
+	)
+	operatorAPIServerThrottled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "operator",
+			Name:      "apiserver_throttled_total",
+			Help:      "Total number of requests to the API server delayed by the client side rate limiter, by verb",
+		},
+		[]string{"verb"},
+	)
+	operatorLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "operator",
+			Name:      "leader",
+			Help:      "Whether the operator replica is the leader exporting the scaling metrics, 1 on the leader and 0 otherwise",
+		},
+	)
+	operatorRestarts = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "operator",
+			Name:      "restarts_total",
+			Help:      "Number of starts of the operator replicas after the first one, persisted in the keda-operator-restarts ConfigMap so it survives the restarts",
+		},
+	)
+	metricsDroppedNonLeader = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "metrics",
+			Name:      "dropped_nonleader_total",
+			Help:      "Total number of scaling metric series withheld from the scrapes because the operator replica isn't the leader",
+		},
+	)
 
 	triggerTotalsGaugeVec = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -100,6 +524,15 @@ var (
 		},
 		[]string{"type"},
 	)
+	triggerDeprecatedFieldUsage = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "trigger",
+			Name:      "deprecated_field_usage_total",
+			Help:      "Total number of reconciliations of scaled objects and scaled jobs with a deprecated field set in a trigger, by trigger type and field",
+		},
+		[]string{"type", "field"},
+	)
 
 	crdTotalsGaugeVec = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -112,49 +545,662 @@ var (
 )
 
 func init() {
-	metrics.Registry.MustRegister(scalerErrorsTotal)
-	metrics.Registry.MustRegister(scalerMetricsValue)
-	metrics.Registry.MustRegister(scalerMetricsLatency)
-	metrics.Registry.MustRegister(scalerActive)
-	metrics.Registry.MustRegister(scalerErrors)
-	metrics.Registry.MustRegister(scaledObjectErrors)
+	metrics.Registry.MustRegister(leaderOnly(scalerErrorsTotal))
+	createScalerMetrics(metricLabels)
+	metrics.Registry.MustRegister(leaderOnly(scalerExposedMetrics))
+	metrics.Registry.MustRegister(leaderOnly(scalerPartitions))
+	metrics.Registry.MustRegister(leaderOnly(scalerUnhealthyByBackend))
+	metrics.Registry.MustRegister(scalerHTTPResponses)
+	metrics.Registry.MustRegister(scalerResponseBytes)
+	metrics.Registry.MustRegister(leaderOnly(scalerValueCoercions))
+	metrics.Registry.MustRegister(scalerConnectSeconds)
+	metrics.Registry.MustRegister(scalerQuerySeconds)
+	metrics.Registry.MustRegister(externalScalerRPCs)
+	metrics.Registry.MustRegister(leaderOnly(scalerRebuilds))
+	metrics.Registry.MustRegister(scalerQueryConcurrencyLimit)
+	metrics.Registry.MustRegister(scalerQueryConcurrencyActive)
+	metrics.Registry.MustRegister(scalerQueryCoalesced)
+	metrics.Registry.MustRegister(scaleLoopsBackingOff)
+	metrics.Registry.MustRegister(scalerCAExpiry)
+	metrics.Registry.MustRegister(leaderOnly(scalerPrometheusResultSeries))
+	metrics.Registry.MustRegister(leaderOnly(scalerPrometheusMultiResults))
+	metrics.Registry.MustRegister(metricsLabelsTruncated)
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectErrors))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectFallbackInvalid))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectTargetKind))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectIdleReplicas))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectFirstPodReadySeconds))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectDesiredReplicas))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectFirstPodReadyTimeouts))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectReconcileBudgetExceeded))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectHPAPolicyOverrides))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectHPAImmutableErrors))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectHPAUpdates))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectDrivingTrigger))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectReconcileDeferred))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectDegradedMetrics))
+	metrics.Registry.MustRegister(leaderOnly(scaledJobAccurateBacklog))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectsByCondition))
+	newReplicaGauges("")
+	metrics.Registry.MustRegister(leaderOnly(replicaGaugesCollector{}))
+	metrics.Registry.MustRegister(leaderOnly(scaleTargetScaledObjects))
+	metrics.Registry.MustRegister(leaderOnly(scaleTargetConflicts))
+	metrics.Registry.MustRegister(leaderOnly(scaledObjectsPausedAtReplicas))
+	metrics.Registry.MustRegister(leaderOnly(triggerAuthMissingRefs))
+	metrics.Registry.MustRegister(operatorConfigReloads)
+	metrics.Registry.MustRegister(operatorConfigReloadErrors)
+	metrics.Registry.MustRegister(operatorSelfThrottling)
+	metrics.Registry.MustRegister(operatorStartTime)
+	metrics.Registry.MustRegister(operatorLeader)
+	metrics.Registry.MustRegister(operatorRestarts)
+	metrics.Registry.MustRegister(operatorAPIServerThrottled)
+	metrics.Registry.MustRegister(metricsDroppedNonLeader)
+	metrics.Registry.MustRegister(runtimeInfo)
+	metrics.Registry.MustRegister(informerCacheSync)
+	metrics.Registry.MustRegister(operatorWatchResets)
+	operatorStartTime.SetToCurrentTime()
 
 	metrics.Registry.MustRegister(triggerTotalsGaugeVec)
+	metrics.Registry.MustRegister(triggerDeprecatedFieldUsage)
 	metrics.Registry.MustRegister(crdTotalsGaugeVec)
 }
 
-// RecordScalerMetric create a measurement of the external metric used by the HPA
-func RecordScalerMetric(namespace string, scaledObject string, scaler string, scalerIndex int, metric string, value float64) {
-	scalerMetricsValue.With(getLabels(namespace, scaledObject, scaler, scalerIndex, metric)).Set(value)
+// createScalerMetrics creates the per scaler metric series with the labels
+func createScalerMetrics(labels []string) {
+	scalerMetricsValue = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "metrics_value",
+			Help:      "Metric Value used for HPA, cached is true when the value was read from the cache of useCachedMetrics or reused until the pollingInterval of the trigger elapsed, unit is set when the scaler knows the unit of the value",
+		},
+		append(append([]string{}, labels...), "cached", "unit"),
+	)
+	scalerMetricsValueAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "metrics_value_age_seconds",
+			Help:      "Time in seconds since the metric value used for HPA last changed",
+		},
+		labels,
+	)
+	scalerMetricsLatency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "metrics_latency",
+			Help:      "Scaler Metrics Latency",
+		},
+		labels,
+	)
+	scalerActive = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "active",
+			Help:      "Activity of a Scaler Metric",
+		},
+		labels,
+	)
+	scalerErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaler",
+			Name:      "errors",
+			Help:      "Number of scaler errors",
+		},
+		append(append([]string{}, labels...), "errorType"),
+	)
+	scaledObjectNegativeValues = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "negative_values_total",
+			Help:      "Total number of negative scaler metric values clamped to 0",
+		},
+		labels,
+	)
+	scaledObjectTriggerContribution = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: DefaultPromMetricsNamespace,
+			Subsystem: "scaledobject",
+			Name:      "trigger_contribution",
+			Help:      "Replica count the metric of a trigger with an AverageValue target would request alone, the scaled object is scaled to the highest one",
+		},
+		labels,
+	)
 }
 
-// RecordScalerLatency create a measurement of the latency to external metric
-func RecordScalerLatency(namespace string, scaledObject string, scaler string, scalerIndex int, metric string, value float64) {
-	scalerMetricsLatency.With(getLabels(namespace, scaledObject, scaler, scalerIndex, metric)).Set(value)
+// registerScalerMetrics registers the per scaler metric series, unless they are already registered. They are
+// registered on the first record instead of in init, so their labels can still be set at startup, e.g. by
+// SetUIDLabelEnabled. Only the first records take the lock
+func registerScalerMetrics() {
+	if scalerMetricsRegistered.Load() {
+		return
+	}
+	scalerMetricsLock.Lock()
+	defer scalerMetricsLock.Unlock()
+	if scalerMetricsRegistered.Load() {
+		return
+	}
+	for _, collector := range []prometheus.Collector{scalerMetricsValue, scalerMetricsValueAge, scalerMetricsLatency, scalerActive, scalerErrors,
+		scaledObjectNegativeValues, scaledObjectTriggerContribution} {
+		scalerMetricsRegisterer.MustRegister(leaderOnly(collector))
+	}
+	scalerMetricsRegistered.Store(true)
+}
+
+// SetUIDLabelEnabled adds the uid label, set to metadata.uid of the scaled object, to the per scaler metric series
+// so a scaled object recreated with the same name can be told apart. It's disabled by default as every recreation
+// starts new series. It has to be set before any scaler metric is recorded
+func SetUIDLabelEnabled(enabled bool) error {
+	scalerMetricsLock.Lock()
+	defer scalerMetricsLock.Unlock()
+	if scalerMetricsRegistered.Load() {
+		return fmt.Errorf("the uid label can't be changed after the scaler metrics have been registered")
+	}
+
+	uidLabelEnabled = enabled
+	labels := metricLabels
+	if enabled {
+		labels = append(append([]string{}, metricLabels...), "uid")
+	}
+	createScalerMetrics(labels)
+	return nil
+}
+
+// labelValueHashLength is the length of the hash suffix of a truncated label value, with its separator
+const labelValueHashLength = 9
+
+// SetLabelValueMaxLength sets the maximum length in bytes of the label values of the scaler metrics, longer values like
+// the metric names of long queries are truncated and suffixed with a hash of the full value to keep the series apart.
+// 0 disables the truncation. It has to be set before any scaler metric is recorded
+func SetLabelValueMaxLength(length int) error {
+	if length != 0 && length <= 2*labelValueHashLength {
+		return fmt.Errorf("the maximum label value length must be 0 or greater than %d, got %d", 2*labelValueHashLength, length)
+	}
+
+	scalerMetricsLock.Lock()
+	defer scalerMetricsLock.Unlock()
+	if scalerMetricsRegistered.Load() {
+		return fmt.Errorf("the maximum label value length can't be changed after the scaler metrics have been registered")
+	}
+	labelValueMaxLength = length
+	return nil
+}
+
+// the replica gauges, created by newReplicaGauges with the region label
+var (
+	namespaceManagedReplicasCurrent   *prometheus.GaugeVec
+	namespaceManagedReplicasMax       *prometheus.GaugeVec
+	scaledObjectDryRunDesiredReplicas *prometheus.GaugeVec
+)
+
+// region is the value of the region label of the replica gauges, set with SetRegion
+var region string
+
+// SetRegion sets the region label of the replica gauges, to tell apart the operators of several regions. The gauges
+// don't have the label when no region is configured, so the existing series keep their label set. It has to be set
+// before any replica gauge is recorded, the gauges are created again with the new label
+func SetRegion(value string) {
+	if value == region {
+		return
+	}
+	newReplicaGauges(value)
+}
+
+// newReplicaGauges creates the replica gauges, with a constant region label if the region is set
+func newReplicaGauges(value string) {
+	region = value
+	var constLabels prometheus.Labels
+	if value != "" {
+		constLabels = prometheus.Labels{"region": value}
+	}
+	namespaceManagedReplicasCurrent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   DefaultPromMetricsNamespace,
+			Subsystem:   "namespace",
+			Name:        "managed_replicas_current",
+			Help:        "Sum of the current replicas of the scale targets of the ScaledObjects in the namespace",
+			ConstLabels: constLabels,
+		},
+		[]string{"namespace"},
+	)
+	namespaceManagedReplicasMax = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   DefaultPromMetricsNamespace,
+			Subsystem:   "namespace",
+			Name:        "managed_replicas_max",
+			Help:        "Sum of the maximum replica counts of the ScaledObjects in the namespace",
+			ConstLabels: constLabels,
+		},
+		[]string{"namespace"},
+	)
+	scaledObjectDryRunDesiredReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   DefaultPromMetricsNamespace,
+			Subsystem:   "scaledobject",
+			Name:        "dry_run_desired_replicas",
+			Help:        "Replica count KEDA would scale the scale target of the scaled object in dry-run mode to",
+			ConstLabels: constLabels,
+		},
+		[]string{"namespace", "scaledObject"},
+	)
+}
+
+// replicaGaugesCollector collects the current replica gauges. It is unchecked, it doesn't describe the gauges, because
+// a registry doesn't accept a metric registered again with other label names
+type replicaGaugesCollector struct{}
+
+func (replicaGaugesCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (replicaGaugesCollector) Collect(ch chan<- prometheus.Metric) {
+	namespaceManagedReplicasCurrent.Collect(ch)
+	namespaceManagedReplicasMax.Collect(ch)
+	scaledObjectDryRunDesiredReplicas.Collect(ch)
+}
+
+// leader is whether the operator replica is the leader, the scaling metrics are only exported by the leader so the
+// replicas waiting for the leader election don't export stale series
+var leader atomic.Bool
+
+// RecordOperatorRestarts sets the persisted restart count of the operator
+func RecordOperatorRestarts(restarts int64) {
+	operatorRestarts.Set(float64(restarts))
+}
+
+// RecordOperatorLeader sets whether the operator replica is the leader, the scaling metrics are only exported while
+// it is. It's only set by LeaderRecorder, so the metrics adapter never exports them
+func RecordOperatorLeader(isLeader bool) {
+	leader.Store(isLeader)
+	if isLeader {
+		operatorLeader.Set(1)
+	} else {
+		operatorLeader.Set(0)
+	}
+}
+
+// leaderOnlyCollector collects the scaling metrics of the collector only on the leader, on the other replicas the
+// series are withheld from the scrape and counted in keda_metrics_dropped_nonleader_total
+type leaderOnlyCollector struct {
+	prometheus.Collector
+}
+
+// leaderOnly wraps the collector of scaling metrics, the metrics local to the replica, e.g. the config reloads or the
+// watch resets, are registered without it
+func leaderOnly(collector prometheus.Collector) prometheus.Collector {
+	return leaderOnlyCollector{Collector: collector}
+}
+
+func (c leaderOnlyCollector) Collect(ch chan<- prometheus.Metric) {
+	if leader.Load() {
+		c.Collector.Collect(ch)
+		return
+	}
+
+	withheld := make(chan prometheus.Metric)
+	go func() {
+		c.Collector.Collect(withheld)
+		close(withheld)
+	}()
+	for range withheld {
+		metricsDroppedNonLeader.Inc()
+	}
+}
+
+// LeaderRecorder marks the operator replica as the leader while it holds the leader election lease. It needs the
+// leader election, so the manager only starts it once the replica is elected, or right away without leader election
+type LeaderRecorder struct{}
+
+// Start records the replica as the leader until the context is done, this implements the Runnable interface of
+// controller-runtime Manager, so we can use mgr.Add() to start this component.
+func (LeaderRecorder) Start(ctx context.Context) error {
+	RecordOperatorLeader(true)
+	<-ctx.Done()
+	RecordOperatorLeader(false)
+	return nil
+}
+
+// NeedLeaderElection returns true so the recorder is only started on the leader
+func (LeaderRecorder) NeedLeaderElection() bool {
+	return true
+}
+
+// truncateLabelValue returns the value unchanged if it fits the maximum label value length, else its prefix followed
+// by the first characters of its sha256
+func truncateLabelValue(value string) (string, bool) {
+	if labelValueMaxLength == 0 || len(value) <= labelValueMaxLength {
+		return value, false
+	}
+
+	end := labelValueMaxLength - labelValueHashLength
+	// don't cut a multi-byte character, the label values have to be valid UTF-8
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+	hash := sha256.Sum256([]byte(value))
+	return value[:end] + "-" + hex.EncodeToString(hash[:])[:labelValueHashLength-1], true
+}
+
+// RuntimeInfo describes the build, the enabled optional components and the Kubernetes API capabilities
+// detected at startup, it's exported as keda_runtime_info and served on the /version endpoint
+type RuntimeInfo struct {
+	Version                string `json:"version"`
+	GitCommit              string `json:"gitCommit"`
+	GoVersion              string `json:"goVersion"`
+	CertRotation           bool   `json:"certRotation"`
+	ScaledObjectGeneration bool   `json:"scaledObjectGeneration"`
+	KubernetesVersion      string `json:"kubernetesVersion"`
+	AutoscalingAPI         string `json:"autoscalingAPI"`
+}
+
+// RecordRuntimeInfo publishes the runtime info, replacing the previously recorded one
+func RecordRuntimeInfo(info RuntimeInfo) {
+	runtimeInfo.Reset()
+	runtimeInfo.With(prometheus.Labels{
+		"version":                 info.Version,
+		"git_commit":              info.GitCommit,
+		"go_version":              info.GoVersion,
+		"cert_rotation":           strconv.FormatBool(info.CertRotation),
+		"scaledobject_generation": strconv.FormatBool(info.ScaledObjectGeneration),
+		"kubernetes_version":      info.KubernetesVersion,
+		"autoscaling_api":         info.AutoscalingAPI,
+	}).Set(1)
+}
+
+// RecordInformerCacheSync records the time of a full sync of the informer cache of the kind
+func RecordInformerCacheSync(kind string, syncTime time.Time) {
+	informerCacheSync.With(prometheus.Labels{"kind": kind}).Set(float64(syncTime.UnixNano()) / 1e9)
+}
+
+// RecordOperatorWatchReset counts a watch of the kind re-established before its timeout
+func RecordOperatorWatchReset(kind string) {
+	operatorWatchResets.With(prometheus.Labels{"kind": kind}).Inc()
+}
+
+// RecordScalerMetric create a measurement of the external metric used by the HPA,
+// the metric value is kept as a quantity and converted to float only when it is exported.
+// cached tells whether the value was served from a cache instead of being queried, a metric only keeps the series
+// of its last record. unit is the unit of the value (e.g. seconds, bytes), empty when the scaler doesn't know it
+func RecordScalerMetric(namespace string, scaledObject string, scaledObjectUID string, scaler string, scalerIndex int, metric external_metrics.ExternalMetricValue, cached bool, unit string) {
+	registerScalerMetrics()
+	labels := getLabels(namespace, scaledObject, scaledObjectUID, scaler, scalerIndex, metric.MetricName)
+	scalerMetricsValue.With(withValueLabels(labels, cached, unit)).Set(metric.Value.AsApproximateFloat64())
+	scalerMetricsValue.Delete(withValueLabels(labels, !cached, unit))
+}
+
+// RecordScaledObjectNegativeValue counts a negative metric value of a count scaler which has been clamped to 0
+func RecordScaledObjectNegativeValue(namespace string, scaledObject string, scaledObjectUID string, scaler string, scalerIndex int, metric string) {
+	registerScalerMetrics()
+	scaledObjectNegativeValues.With(getLabels(namespace, scaledObject, scaledObjectUID, scaler, scalerIndex, metric)).Inc()
+}
+
+// withValueLabels returns a copy of the labels with the cached and unit labels of the metric value
+func withValueLabels(labels prometheus.Labels, cached bool, unit string) prometheus.Labels {
+	valueLabels := prometheus.Labels{"cached": strconv.FormatBool(cached), "unit": unit}
+	for label, value := range labels {
+		valueLabels[label] = value
+	}
+	return valueLabels
+}
+
+// RecordScalerMetricAge create a measurement of the time since the value of the external metric last changed
+func RecordScalerMetricAge(namespace string, scaledObject string, scaledObjectUID string, scaler string, scalerIndex int, metric string, age time.Duration) {
+	registerScalerMetrics()
+	scalerMetricsValueAge.With(getLabels(namespace, scaledObject, scaledObjectUID, scaler, scalerIndex, metric)).Set(age.Seconds())
+}
+
+// RecordScaledObjectTriggerContribution create a measurement of the replica count the metric of the trigger requests alone
+func RecordScaledObjectTriggerContribution(namespace string, scaledObject string, scaledObjectUID string, scaler string, scalerIndex int, metric string, replicas int32) {
+	registerScalerMetrics()
+	scaledObjectTriggerContribution.With(getLabels(namespace, scaledObject, scaledObjectUID, scaler, scalerIndex, metric)).Set(float64(replicas))
+}
+
+// DeleteScalerMetrics removes the scaler metric series of a deleted scaled object
+func DeleteScalerMetrics(namespace string, scaledObject string) {
+	registerScalerMetrics()
+	labels := prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}
+	// the per scaler metric series are labelled with the truncated values
+	truncatedLabels := prometheus.Labels{}
+	for label, value := range labels {
+		truncatedLabels[label], _ = truncateLabelValue(value)
+	}
+	scalerMetricsValue.DeletePartialMatch(truncatedLabels)
+	scalerMetricsValueAge.DeletePartialMatch(truncatedLabels)
+	scalerMetricsLatency.DeletePartialMatch(truncatedLabels)
+	scalerActive.DeletePartialMatch(truncatedLabels)
+	scalerErrors.DeletePartialMatch(truncatedLabels)
+	scaledObjectNegativeValues.DeletePartialMatch(truncatedLabels)
+	scaledObjectTriggerContribution.DeletePartialMatch(truncatedLabels)
+	truncatedLabelValuesLock.Lock()
+	delete(truncatedLabelValues, types.NamespacedName{Namespace: namespace, Name: scaledObject})
+	truncatedLabelValuesLock.Unlock()
+	scalerExposedMetrics.DeletePartialMatch(labels)
+	scalerRebuilds.DeletePartialMatch(labels)
+	scalerCAExpiry.DeletePartialMatch(labels)
+	scalerPartitions.DeletePartialMatch(labels)
+	deleteScalerBackendHealth(namespace, scaledObject)
+	scaledObjectDesiredReplicas.Delete(labels)
+	scaledObjectDrivingTrigger.DeletePartialMatch(labels)
+	scalerPrometheusResultSeries.Delete(labels)
+	scalerPrometheusMultiResults.Delete(labels)
+}
+
+// RecordScalerLatency create a measurement of the latency to external metric
+func RecordScalerLatency(namespace string, scaledObject string, scaledObjectUID string, scaler string, scalerIndex int, metric string, value float64) {
+	registerScalerMetrics()
+	scalerMetricsLatency.With(getLabels(namespace, scaledObject, scaledObjectUID, scaler, scalerIndex, metric)).Set(value)
 }
 
 // RecordScalerActive create a measurement of the activity of the scaler
-func RecordScalerActive(namespace string, scaledObject string, scaler string, scalerIndex int, metric string, active bool) {
+func RecordScalerActive(namespace string, scaledObject string, scaledObjectUID string, scaler string, scalerIndex int, metric string, active bool) {
+	registerScalerMetrics()
 	activeVal := 0
 	if active {
 		activeVal = 1
 	}
 
-	scalerActive.With(getLabels(namespace, scaledObject, scaler, scalerIndex, metric)).Set(float64(activeVal))
+	scalerActive.With(getLabels(namespace, scaledObject, scaledObjectUID, scaler, scalerIndex, metric)).Set(float64(activeVal))
 }
 
-// RecordScalerError counts the number of errors occurred in trying get an external metric used by the HPA
-func RecordScalerError(namespace string, scaledObject string, scaler string, scalerIndex int, metric string, err error) {
-	if err != nil {
-		scalerErrors.With(getLabels(namespace, scaledObject, scaler, scalerIndex, metric)).Inc()
-		RecordScaledObjectError(namespace, scaledObject, err)
-		scalerErrorsTotal.With(prometheus.Labels{}).Inc()
+// RecordScalerExposedMetrics create a measurement of the number of distinct external metric names the scaler provides
+func RecordScalerExposedMetrics(namespace string, scaledObject string, scaler string, count int) {
+	scalerExposedMetrics.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "scaler": scaler}).Set(float64(count))
+}
+
+// RecordScalerPartitions create a measurement of the number of partitions or shards the streaming scaler observed
+func RecordScalerPartitions(namespace string, scaledObject string, scaler string, count int) {
+	scalerPartitions.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "scaler": scaler}).Set(float64(count))
+}
+
+// scalerHealthKey identifies a scaler of a scaled object
+type scalerHealthKey struct {
+	namespace    string
+	scaledObject string
+	scalerIndex  int
+}
+
+// scalerHealth is the result of the last health check of a scaler
+type scalerHealth struct {
+	backend string
+	healthy bool
+}
+
+var (
+	scalerHealthsLock sync.Mutex
+	scalerHealths     = map[scalerHealthKey]scalerHealth{}
+)
+
+// RecordScalerBackendHealth records the result of the health check of a scaler and recomputes the number of unhealthy
+// scalers of its backend, and of its previous backend when it changed
+func RecordScalerBackendHealth(namespace string, scaledObject string, scalerIndex int, backend string, healthy bool) {
+	scalerHealthsLock.Lock()
+	defer scalerHealthsLock.Unlock()
+
+	key := scalerHealthKey{namespace: namespace, scaledObject: scaledObject, scalerIndex: scalerIndex}
+	previous, found := scalerHealths[key]
+	scalerHealths[key] = scalerHealth{backend: backend, healthy: healthy}
+	if found && previous.backend != backend {
+		updateScalerUnhealthyByBackend(previous.backend)
+	}
+	updateScalerUnhealthyByBackend(backend)
+}
+
+// deleteScalerBackendHealth forgets the scalers of the scaled object and recomputes the number of unhealthy scalers
+// of their backends
+func deleteScalerBackendHealth(namespace string, scaledObject string) {
+	scalerHealthsLock.Lock()
+	defer scalerHealthsLock.Unlock()
+
+	backends := map[string]bool{}
+	for key, health := range scalerHealths {
+		if key.namespace == namespace && key.scaledObject == scaledObject {
+			delete(scalerHealths, key)
+			backends[health.backend] = true
+		}
+	}
+	for backend := range backends {
+		updateScalerUnhealthyByBackend(backend)
+	}
+}
+
+// updateScalerUnhealthyByBackend sets the number of unhealthy scalers of the backend, the series is deleted once no
+// scaler queries the backend anymore. scalerHealthsLock must be held
+func updateScalerUnhealthyByBackend(backend string) {
+	scalers, unhealthy := 0, 0
+	for _, health := range scalerHealths {
+		if health.backend != backend {
+			continue
+		}
+		scalers++
+		if !health.healthy {
+			unhealthy++
+		}
+	}
+	if scalers == 0 {
+		scalerUnhealthyByBackend.DeleteLabelValues(backend)
 		return
 	}
-	// initialize metric with 0 if not already set
-	_, errscaler := scalerErrors.GetMetricWith(getLabels(namespace, scaledObject, scaler, scalerIndex, metric))
-	if errscaler != nil {
-		log.Error(errscaler, "Unable to write to metrics to Prometheus Server: %v")
+	scalerUnhealthyByBackend.WithLabelValues(backend).Set(float64(unhealthy))
+}
+
+// RecordScalerError counts the number of errors occurred in trying get an external metric used by the HPA,
+// errorType is the type of err. The series of an error type is created by its first error
+func RecordScalerError(namespace string, scaledObject string, scaledObjectUID string, scaler string, scalerIndex int, metric string, err error, errorType string) {
+	if err == nil {
+		return
+	}
+	registerScalerMetrics()
+	labels := getLabels(namespace, scaledObject, scaledObjectUID, scaler, scalerIndex, metric)
+	labels["errorType"] = errorType
+	scalerErrors.With(labels).Inc()
+	RecordScaledObjectError(namespace, scaledObject, err)
+	scalerErrorsTotal.With(prometheus.Labels{}).Inc()
+}
+
+// RecordScalerRebuild counts a scaler rebuilt because the spec of the scaled object changed
+func RecordScalerRebuild(namespace string, scaledObject string, scaler string) {
+	scalerRebuilds.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "scaler": scaler}).Inc()
+}
+
+// RecordScalerHTTPResponse counts the HTTP responses received by a scaler by their status class (2xx, 4xx, ...)
+func RecordScalerHTTPResponse(scaler string, statusCode int) {
+	scalerHTTPResponses.With(prometheus.Labels{"scaler": scaler, "status_class": fmt.Sprintf("%dxx", statusCode/100)}).Inc()
+}
+
+// RecordScalerValueCoercion counts a metric value a scaler parsed from a string of the response
+func RecordScalerValueCoercion(scaler string) {
+	scalerValueCoercions.With(prometheus.Labels{"scaler": scaler}).Inc()
+}
+
+// RecordScalerResponseBytes observes the size of an HTTP response payload read by a scaler
+func RecordScalerResponseBytes(scaler string, size int64) {
+	scalerResponseBytes.With(prometheus.Labels{"scaler": scaler}).Observe(float64(size))
+}
+
+// RecordScalerConnectDuration observes the time a scaler spent setting up a new HTTP connection
+func RecordScalerConnectDuration(scaler string, duration time.Duration) {
+	scalerConnectSeconds.With(prometheus.Labels{"scaler": scaler}).Observe(duration.Seconds())
+}
+
+// RecordScalerQueryDuration observes the time a scaler waited for the first byte of an HTTP response
+func RecordScalerQueryDuration(scaler string, duration time.Duration) {
+	scalerQuerySeconds.With(prometheus.Labels{"scaler": scaler}).Observe(duration.Seconds())
+}
+
+// ScalerHTTPMetricsRecorder records the metrics of the HTTP requests of the scalers, it's the recorder of the HTTP
+// clients created by the util package
+type ScalerHTTPMetricsRecorder struct{}
+
+func (ScalerHTTPMetricsRecorder) RecordHTTPResponse(triggerType string, statusCode int) {
+	RecordScalerHTTPResponse(triggerType, statusCode)
+}
+
+func (ScalerHTTPMetricsRecorder) RecordResponseBytes(triggerType string, size int64) {
+	RecordScalerResponseBytes(triggerType, size)
+}
+
+func (ScalerHTTPMetricsRecorder) RecordConnectDuration(triggerType string, duration time.Duration) {
+	RecordScalerConnectDuration(triggerType, duration)
+}
+
+func (ScalerHTTPMetricsRecorder) RecordQueryDuration(triggerType string, duration time.Duration) {
+	RecordScalerQueryDuration(triggerType, duration)
+}
+
+// RecordExternalScalerRPC counts a gRPC call to the external scaler at address
+func RecordExternalScalerRPC(address string, method string, status string) {
+	externalScalerRPCs.With(prometheus.Labels{"address": address, "method": method, "status": status}).Inc()
+}
+
+// RecordScalerQueryConcurrencyLimit sets the maximum number of scaler queries running at the same time
+func RecordScalerQueryConcurrencyLimit(limit int) {
+	scalerQueryConcurrencyLimit.Set(float64(limit))
+}
+
+// RecordScalerQueryStart counts a scaler query as running until RecordScalerQueryEnd is called
+func RecordScalerQueryStart() {
+	scalerQueryConcurrencyActive.Inc()
+}
+
+// RecordScalerQueryEnd counts the end of a scaler query recorded by RecordScalerQueryStart
+func RecordScalerQueryEnd() {
+	scalerQueryConcurrencyActive.Dec()
+}
+
+// RecordScaleLoopBackoffStart counts a scale loop as backing off until RecordScaleLoopBackoffEnd is called
+func RecordScaleLoopBackoffStart() {
+	scaleLoopsBackingOff.Inc()
+}
+
+// RecordScaleLoopBackoffEnd counts the end of a backoff recorded by RecordScaleLoopBackoffStart
+func RecordScaleLoopBackoffEnd() {
+	scaleLoopsBackingOff.Dec()
+}
+
+// RecordScalerQueryCoalesced counts a scaler query served by the result of an identical query in flight
+func RecordScalerQueryCoalesced(metric string) {
+	scalerQueryCoalesced.With(prometheus.Labels{"metric": metric}).Inc()
+}
+
+// RecordScalerCAExpiry sets the expiry of the custom CA bundle of a scaler
+func RecordScalerCAExpiry(namespace string, scaledObject string, scaler string, notAfter time.Time) {
+	scalerCAExpiry.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "scaler": scaler}).Set(float64(notAfter.Unix()))
+}
+
+// DeleteScalerCAExpiry removes the expiry of the custom CA bundle of a scaler which no longer has one
+func DeleteScalerCAExpiry(namespace string, scaledObject string, scaler string) {
+	scalerCAExpiry.Delete(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "scaler": scaler})
+}
+
+// RecordPrometheusScalerResultSeries sets the number of series returned by a query of a prometheus scaler, and
+// counts the queries returning more than one
+func RecordPrometheusScalerResultSeries(namespace string, scaledObject string, series int) {
+	labels := prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}
+	scalerPrometheusResultSeries.With(labels).Set(float64(series))
+	if series > 1 {
+		scalerPrometheusMultiResults.With(labels).Inc()
 	}
 }
 
@@ -173,8 +1219,346 @@ func RecordScaledObjectError(namespace string, scaledObject string, err error) {
 	}
 }
 
-func getLabels(namespace string, scaledObject string, scaler string, scalerIndex int, metric string) prometheus.Labels {
-	return prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "scaler": scaler, "scalerIndex": strconv.Itoa(scalerIndex), "metric": metric}
+// RecordScaledObjectFallbackInvalid sets whether the fallback configuration of the scaled object is invalid
+func RecordScaledObjectFallbackInvalid(namespace string, scaledObject string, invalid bool) {
+	invalidVal := 0
+	if invalid {
+		invalidVal = 1
+	}
+
+	scaledObjectFallbackInvalid.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Set(float64(invalidVal))
+}
+
+// DeleteScaledObjectFallbackInvalid removes the fallback validity measurement of a deleted scaled object
+func DeleteScaledObjectFallbackInvalid(namespace string, scaledObject string) {
+	scaledObjectFallbackInvalid.Delete(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject})
+}
+
+// RecordScaledObjectTargetKind sets the kind of the resolved scale target, replacing the previous kind if it changed
+func RecordScaledObjectTargetKind(namespace string, scaledObject string, kind string) {
+	scaledObjectTargetKind.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject})
+	scaledObjectTargetKind.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "kind": kind}).Set(1)
+}
+
+// DeleteScaledObjectTargetKind removes the scale target kind of a deleted scaled object
+func DeleteScaledObjectTargetKind(namespace string, scaledObject string) {
+	scaledObjectTargetKind.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject})
+}
+
+// RecordScaledObjectIdleReplicas sets the configured idle replica count of the scaled object, the measurement is
+// removed when idleReplicas is nil
+func RecordScaledObjectIdleReplicas(namespace string, scaledObject string, idleReplicas *int32) {
+	labels := prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}
+	if idleReplicas == nil {
+		scaledObjectIdleReplicas.Delete(labels)
+		return
+	}
+	scaledObjectIdleReplicas.With(labels).Set(float64(*idleReplicas))
+}
+
+// DeleteScaledObjectIdleReplicas removes the idle replica count of a deleted scaled object
+func DeleteScaledObjectIdleReplicas(namespace string, scaledObject string) {
+	scaledObjectIdleReplicas.Delete(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject})
+}
+
+// RecordScaledObjectReconcileBudgetExceeded counts a query of the scalers of the scaled object which took longer than its polling interval
+func RecordScaledObjectReconcileBudgetExceeded(namespace string, scaledObject string) {
+	scaledObjectReconcileBudgetExceeded.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Inc()
+}
+
+// RecordScaledObjectHPAPolicyOverride counts a poll where the behavior of the HPA overrode the replica count needed by the scaled object metrics
+func RecordScaledObjectHPAPolicyOverride(namespace string, scaledObject string) {
+	scaledObjectHPAPolicyOverrides.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Inc()
+}
+
+const (
+	// DegradedMetricsReasonFallback is the reason of a metric query served with the fallback value of a failing trigger
+	DegradedMetricsReasonFallback = "fallback"
+	// DegradedMetricsReasonPartial is the reason of a metric query served without the values of the failing triggers
+	DegradedMetricsReasonPartial = "partial"
+)
+
+// RecordScaledObjectDegradedMetrics counts a metric query of the HPA served with fallback or partial values
+func RecordScaledObjectDegradedMetrics(namespace string, scaledObject string, metric string, reason string) {
+	scaledObjectDegradedMetrics.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "metric": metric, "reason": reason}).Inc()
+}
+
+// DeleteScaledObjectDegradedMetrics removes the degraded metric query counts of a deleted scaled object
+func DeleteScaledObjectDegradedMetrics(namespace string, scaledObject string) {
+	scaledObjectDegradedMetrics.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject})
+}
+
+// RecordScaledObjectHPAImmutableError counts an update of the HPA of the scaled object rejected for changing an immutable field
+func RecordScaledObjectHPAImmutableError(namespace string, scaledObject string) {
+	scaledObjectHPAImmutableErrors.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Inc()
+}
+
+const (
+	// HPAUpdateResultPerformed is the result of a reconcile which updated the HPA differing from the scaled object
+	HPAUpdateResultPerformed = "performed"
+	// HPAUpdateResultSkipped is the result of a reconcile which didn't update the HPA already matching the scaled object
+	HPAUpdateResultSkipped = "skipped"
+)
+
+// RecordScaledObjectHPAUpdate counts a reconcile of the HPA of the scaled object by whether the HPA was updated
+func RecordScaledObjectHPAUpdate(namespace string, scaledObject string, result string) {
+	scaledObjectHPAUpdates.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "result": result}).Inc()
+}
+
+// RecordScaledObjectDrivingTrigger sets the trigger driving the scaling of the scaled object, the series of the
+// previous driving trigger is removed
+func RecordScaledObjectDrivingTrigger(namespace string, scaledObject string, triggerType string, metric string) {
+	labels := prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}
+	scaledObjectDrivingTrigger.DeletePartialMatch(labels)
+	labels["type"] = triggerType
+	labels["metric"] = metric
+	scaledObjectDrivingTrigger.With(labels).Set(1)
+}
+
+const (
+	// ReconcileDeferredReasonScaleTargetNotFound is the reason of a reconcile deferred because the scale target doesn't exist yet
+	ReconcileDeferredReasonScaleTargetNotFound = "ScaleTargetNotFound"
+	// ReconcileDeferredReasonScaleTargetKindNotFound is the reason of a reconcile deferred because the kind of the scale target isn't served yet, e.g. its CRD isn't installed
+	ReconcileDeferredReasonScaleTargetKindNotFound = "ScaleTargetKindNotFound"
+)
+
+// RecordScaledObjectReconcileDeferred counts a reconcile of a scaled object deferred because a resource it depends on, e.g. its scale target, was not found
+func RecordScaledObjectReconcileDeferred(namespace string, reason string) {
+	scaledObjectReconcileDeferred.With(prometheus.Labels{"namespace": namespace, "reason": reason}).Inc()
+}
+
+// RecordScaledObjectDesiredReplicas observes the replica count needed by the metric values of the scaled object
+func RecordScaledObjectDesiredReplicas(namespace string, scaledObject string, replicas int32) {
+	scaledObjectDesiredReplicas.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Observe(float64(replicas))
+}
+
+// RecordScaledObjectFirstPodReady records the time from the activation of the scale target of the scaled object to
+// its first ready pod
+func RecordScaledObjectFirstPodReady(namespace string, scaledObject string, duration time.Duration) {
+	scaledObjectFirstPodReadySeconds.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Observe(duration.Seconds())
+}
+
+// RecordScaledObjectFirstPodReadyTimeout counts an activation of the scale target of the scaled object without a
+// ready pod within the timeout
+func RecordScaledObjectFirstPodReadyTimeout(namespace string, scaledObject string) {
+	scaledObjectFirstPodReadyTimeouts.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Inc()
+}
+
+// RecordScaledObjectDryRunDesiredReplicas sets the replica count the scale target of the scaled object in dry-run mode would be scaled to
+func RecordScaledObjectDryRunDesiredReplicas(namespace string, scaledObject string, replicas int32) {
+	scaledObjectDryRunDesiredReplicas.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Set(float64(replicas))
+}
+
+// DeleteScaledObjectDryRunDesiredReplicas removes the dry-run desired replica count of a scaled object which isn't in dry-run mode anymore
+func DeleteScaledObjectDryRunDesiredReplicas(namespace string, scaledObject string) {
+	scaledObjectDryRunDesiredReplicas.Delete(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject})
+}
+
+// RecordScaledJobAccurateBacklog sets the number of jobs computed by the accurate scaling strategy of the scaled job
+func RecordScaledJobAccurateBacklog(namespace string, scaledJob string, backlog int64) {
+	scaledJobAccurateBacklog.With(prometheus.Labels{"namespace": namespace, "scaledJob": scaledJob}).Set(float64(backlog))
+}
+
+// DeleteScaledJobAccurateBacklog removes the accurate backlog of a deleted scaled job or one using another scaling strategy
+func DeleteScaledJobAccurateBacklog(namespace string, scaledJob string) {
+	scaledJobAccurateBacklog.Delete(prometheus.Labels{"namespace": namespace, "scaledJob": scaledJob})
+}
+
+// ConditionReason identifies a condition type of the scaled objects and its reason
+type ConditionReason struct {
+	Condition string
+	Reason    string
+}
+
+// RecordNamespaceManagedReplicas sets the sums of the current and maximum replicas of the ScaledObjects in a namespace
+func RecordNamespaceManagedReplicas(namespace string, currentReplicas int64, maxReplicas int64) {
+	labels := prometheus.Labels{"namespace": namespace}
+	namespaceManagedReplicasCurrent.With(labels).Set(float64(currentReplicas))
+	namespaceManagedReplicasMax.With(labels).Set(float64(maxReplicas))
+}
+
+// DeleteNamespaceManagedReplicas deletes the replicas metrics of a namespace without ScaledObjects
+func DeleteNamespaceManagedReplicas(namespace string) {
+	labels := prometheus.Labels{"namespace": namespace}
+	namespaceManagedReplicasCurrent.Delete(labels)
+	namespaceManagedReplicasMax.Delete(labels)
+}
+
+// RecordScaleTargetScaledObjects sets the number of ScaledObjects referencing a scale target
+func RecordScaleTargetScaledObjects(namespace string, kind string, name string, count int) {
+	scaleTargetScaledObjects.With(prometheus.Labels{"namespace": namespace, "kind": kind, "name": name}).Set(float64(count))
+}
+
+// DeleteScaleTargetScaledObjects deletes the metric of a scale target no longer referenced by any ScaledObject
+func DeleteScaleTargetScaledObjects(namespace string, kind string, name string) {
+	scaleTargetScaledObjects.Delete(prometheus.Labels{"namespace": namespace, "kind": kind, "name": name})
+}
+
+// RecordScaleTargetConflict counts a scale target newly referenced by more than one ScaledObject
+func RecordScaleTargetConflict() {
+	scaleTargetConflicts.Inc()
+}
+
+// scaledObjectConditions holds the Ready and Active condition reasons of the scaled objects by namespace and name,
+// keda_scaledobjects_by_condition is recomputed from them on every change
+var scaledObjectConditions = struct {
+	lock    sync.Mutex
+	reasons map[string][]ConditionReason
+}{reasons: map[string][]ConditionReason{}}
+
+// RecordScaledObjectConditions sets the Ready and Active condition reasons of the scaled object
+func RecordScaledObjectConditions(namespace string, scaledObject string, readyReason string, activeReason string) {
+	scaledObjectConditions.lock.Lock()
+	defer scaledObjectConditions.lock.Unlock()
+
+	scaledObjectConditions.reasons[namespace+"/"+scaledObject] = []ConditionReason{
+		{Condition: "Ready", Reason: readyReason},
+		{Condition: "Active", Reason: activeReason},
+	}
+	recordScaledObjectsByCondition()
+}
+
+// RecordScaledObjectCondition sets the reason of the Ready or Active condition the scale loop gave the scaled object,
+// it's ignored until the conditions of the scaled object are recorded by its reconciliation
+func RecordScaledObjectCondition(namespace string, scaledObject string, condition string, reason string) {
+	scaledObjectConditions.lock.Lock()
+	defer scaledObjectConditions.lock.Unlock()
+
+	reasons, ok := scaledObjectConditions.reasons[namespace+"/"+scaledObject]
+	if !ok {
+		return
+	}
+	updated := make([]ConditionReason, 0, len(reasons))
+	for _, conditionReason := range reasons {
+		if conditionReason.Condition == condition {
+			conditionReason.Reason = reason
+		}
+		updated = append(updated, conditionReason)
+	}
+	scaledObjectConditions.reasons[namespace+"/"+scaledObject] = updated
+	recordScaledObjectsByCondition()
+}
+
+// DeleteScaledObjectConditions removes the condition reasons of a deleted scaled object
+func DeleteScaledObjectConditions(namespace string, scaledObject string) {
+	scaledObjectConditions.lock.Lock()
+	defer scaledObjectConditions.lock.Unlock()
+
+	delete(scaledObjectConditions.reasons, namespace+"/"+scaledObject)
+	recordScaledObjectsByCondition()
+}
+
+// recordScaledObjectsByCondition replaces the numbers of scaled objects in each condition reason, the condition
+// reasons no scaled object is in anymore are removed
+func recordScaledObjectsByCondition() {
+	counts := map[ConditionReason]int{}
+	for _, reasons := range scaledObjectConditions.reasons {
+		for _, conditionReason := range reasons {
+			counts[conditionReason]++
+		}
+	}
+	scaledObjectsByCondition.Reset()
+	for conditionReason, count := range counts {
+		scaledObjectsByCondition.With(prometheus.Labels{"condition": conditionReason.Condition, "reason": conditionReason.Reason}).Set(float64(count))
+	}
+}
+
+// pausedAtReplicasBuckets are the upper bounds of the buckets of keda_scaledobjects_paused_at_replicas
+var pausedAtReplicasBuckets = []float64{0, 1, 2, 5, 10, 20, 50, 100}
+
+// pausedAtReplicasCollector exposes the paused replica counts as a histogram of the current scaled objects,
+// it is recomputed from them instead of accumulating observations
+type pausedAtReplicasCollector struct {
+	desc *prometheus.Desc
+
+	lock     sync.Mutex
+	replicas []int32
+}
+
+func (c *pausedAtReplicasCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *pausedAtReplicasCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var sum float64
+	buckets := make(map[float64]uint64, len(pausedAtReplicasBuckets))
+	for _, bound := range pausedAtReplicasBuckets {
+		buckets[bound] = 0
+	}
+	for _, replicas := range c.replicas {
+		sum += float64(replicas)
+		for _, bound := range pausedAtReplicasBuckets {
+			if float64(replicas) <= bound {
+				buckets[bound]++
+			}
+		}
+	}
+	ch <- prometheus.MustNewConstHistogram(c.desc, uint64(len(c.replicas)), sum, buckets)
+}
+
+// RecordScaledObjectsPausedAtReplicas replaces the paused replica counts of the scaled objects
+func RecordScaledObjectsPausedAtReplicas(replicas []int32) {
+	scaledObjectsPausedAtReplicas.lock.Lock()
+	defer scaledObjectsPausedAtReplicas.lock.Unlock()
+	scaledObjectsPausedAtReplicas.replicas = replicas
+}
+
+// RecordTriggerAuthMissingRefs sets the number of trigger authentications referencing secrets which don't exist
+func RecordTriggerAuthMissingRefs(count int) {
+	triggerAuthMissingRefs.Set(float64(count))
+}
+
+// RecordOperatorConfigReload counts an operator config reload attempt, and its failure if err is set. Every replica
+// reloads its own config, e.g. the root CAs of the CA cert dirs, so the reloads of the followers are counted too
+func RecordOperatorConfigReload(err error) {
+	operatorConfigReloads.Inc()
+	if err != nil {
+		operatorConfigReloadErrors.Inc()
+	}
+}
+
+// RecordOperatorSelfThrottling sets whether the operator is under resource pressure
+func RecordOperatorSelfThrottling(pressure bool) {
+	if pressure {
+		operatorSelfThrottling.Set(1)
+	} else {
+		operatorSelfThrottling.Set(0)
+	}
+}
+
+func getLabels(namespace string, scaledObject string, scaledObjectUID string, scaler string, scalerIndex int, metric string) prometheus.Labels {
+	labels := prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "scaler": scaler, "scalerIndex": strconv.Itoa(scalerIndex), "metric": metric}
+	if uidLabelEnabled {
+		labels["uid"] = scaledObjectUID
+	}
+	for label, value := range labels {
+		if truncated, ok := truncateLabelValue(value); ok {
+			labels[label] = truncated
+			countTruncatedLabelValue(namespace, scaledObject, label, value)
+		}
+	}
+	return labels
+}
+
+// countTruncatedLabelValue counts the truncated label value the first time it's seen for the scaled object
+func countTruncatedLabelValue(namespace string, scaledObject string, label string, value string) {
+	truncatedLabelValuesLock.Lock()
+	defer truncatedLabelValuesLock.Unlock()
+
+	key := types.NamespacedName{Namespace: namespace, Name: scaledObject}
+	seen, found := truncatedLabelValues[key]
+	if !found {
+		seen = map[string]bool{}
+		truncatedLabelValues[key] = seen
+	}
+	if seen[label+"="+value] {
+		return
+	}
+	seen[label+"="+value] = true
+	metricsLabelsTruncated.With(prometheus.Labels{"label": label}).Inc()
 }
 
 func IncrementTriggerTotal(triggerType string) {
@@ -189,6 +1573,11 @@ func DecrementTriggerTotal(triggerType string) {
 	}
 }
 
+// RecordTriggerDeprecatedFieldUsage counts a reconciliation of a trigger with the deprecated field set
+func RecordTriggerDeprecatedFieldUsage(triggerType string, field string) {
+	triggerDeprecatedFieldUsage.With(prometheus.Labels{"type": triggerType, "field": field}).Inc()
+}
+
 func IncrementCRDTotal(crdType, namespace string) {
 	if namespace == "" {
 		namespace = "default"
@@ -0,0 +1,877 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prommetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func TestMain(m *testing.M) {
+	// the scaling metrics are only gathered on the leader
+	RecordOperatorLeader(true)
+	os.Exit(m.Run())
+}
+
+type recordScalerMetricTestData struct {
+	quantity string
+	expected string
+}
+
+var recordScalerMetricTestDataset = []recordScalerMetricTestData{
+	{quantity: "1500m", expected: "1.5"},
+	{quantity: "999m", expected: "0.999"},
+	{quantity: "1m", expected: "0.001"},
+	{quantity: "42", expected: "42"},
+	{quantity: "2k", expected: "2000"},
+}
+
+func TestRecordScalerMetricKeepsQuantityPrecision(t *testing.T) {
+	for _, testData := range recordScalerMetricTestDataset {
+		scalerMetricsValue.Reset()
+		RecordScalerMetric("test-namespace", "test-so", "", "testScaler", 0, external_metrics.ExternalMetricValue{
+			MetricName: "s0-test-metric",
+			Value:      resource.MustParse(testData.quantity),
+		}, false, "")
+
+		expected := fmt.Sprintf(`
+# HELP keda_scaler_metrics_value Metric Value used for HPA, cached is true when the value was read from the cache of useCachedMetrics or reused until the pollingInterval of the trigger elapsed, unit is set when the scaler knows the unit of the value
+# TYPE keda_scaler_metrics_value gauge
+keda_scaler_metrics_value{cached="false",metric="s0-test-metric",namespace="test-namespace",scaledObject="test-so",scaler="testScaler",scalerIndex="0",unit=""} %s
+`, testData.expected)
+		if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_metrics_value"); err != nil {
+			t.Errorf("quantity %s: %s", testData.quantity, err)
+		}
+	}
+}
+
+func TestRecordScaledObjectNegativeValue(t *testing.T) {
+	scaledObjectNegativeValues.Reset()
+
+	RecordScaledObjectNegativeValue("test-namespace", "test-so", "", "queueScaler", 0, "s0-queue")
+	RecordScaledObjectNegativeValue("test-namespace", "test-so", "", "queueScaler", 0, "s0-queue")
+
+	expected := `
+# HELP keda_scaledobject_negative_values_total Total number of negative scaler metric values clamped to 0
+# TYPE keda_scaledobject_negative_values_total counter
+keda_scaledobject_negative_values_total{metric="s0-queue",namespace="test-namespace",scaledObject="test-so",scaler="queueScaler",scalerIndex="0"} 2
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_negative_values_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordScalerMetricsWithUIDLabel(t *testing.T) {
+	// the scaler metrics of the other tests are already registered with the default labels,
+	// the metrics with the uid label are registered in their own registry
+	value, valueAge, latency, active := scalerMetricsValue, scalerMetricsValueAge, scalerMetricsLatency, scalerActive
+	scalerErrs, negativeValues, contribution := scalerErrors, scaledObjectNegativeValues, scaledObjectTriggerContribution
+	registerer, registered := scalerMetricsRegisterer, scalerMetricsRegistered.Load()
+	defer func() {
+		scalerMetricsValue, scalerMetricsValueAge, scalerMetricsLatency, scalerActive = value, valueAge, latency, active
+		scalerErrors, scaledObjectNegativeValues, scaledObjectTriggerContribution = scalerErrs, negativeValues, contribution
+		scalerMetricsRegisterer, uidLabelEnabled = registerer, false
+		scalerMetricsRegistered.Store(registered)
+	}()
+	registry := prometheus.NewRegistry()
+	scalerMetricsRegisterer = registry
+	scalerMetricsRegistered.Store(false)
+
+	if err := SetUIDLabelEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// a scaled object recreated with the same name gets a new uid
+	for _, uid := range []string{"uid-1", "uid-2"} {
+		RecordScalerMetric("test-namespace", "test-so", uid, "queueScaler", 0, external_metrics.ExternalMetricValue{
+			MetricName: "s0-queue",
+			Value:      resource.MustParse("5"),
+		}, false, "")
+		RecordScalerActive("test-namespace", "test-so", uid, "queueScaler", 0, "s0-queue", true)
+	}
+	RecordScalerError("test-namespace", "test-so", "uid-2", "queueScaler", 0, "s0-queue", errors.New("failure"), ScalerErrorTypeUnknown)
+
+	expected := `
+# HELP keda_scaler_active Activity of a Scaler Metric
+# TYPE keda_scaler_active gauge
+keda_scaler_active{metric="s0-queue",namespace="test-namespace",scaledObject="test-so",scaler="queueScaler",scalerIndex="0",uid="uid-1"} 1
+keda_scaler_active{metric="s0-queue",namespace="test-namespace",scaledObject="test-so",scaler="queueScaler",scalerIndex="0",uid="uid-2"} 1
+# HELP keda_scaler_errors Number of scaler errors
+# TYPE keda_scaler_errors counter
+keda_scaler_errors{errorType="unknown",metric="s0-queue",namespace="test-namespace",scaledObject="test-so",scaler="queueScaler",scalerIndex="0",uid="uid-2"} 1
+# HELP keda_scaler_metrics_value Metric Value used for HPA, cached is true when the value was read from the cache of useCachedMetrics or reused until the pollingInterval of the trigger elapsed, unit is set when the scaler knows the unit of the value
+# TYPE keda_scaler_metrics_value gauge
+keda_scaler_metrics_value{cached="false",metric="s0-queue",namespace="test-namespace",scaledObject="test-so",scaler="queueScaler",scalerIndex="0",uid="uid-1",unit=""} 5
+keda_scaler_metrics_value{cached="false",metric="s0-queue",namespace="test-namespace",scaledObject="test-so",scaler="queueScaler",scalerIndex="0",uid="uid-2",unit=""} 5
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "keda_scaler_active", "keda_scaler_errors", "keda_scaler_metrics_value"); err != nil {
+		t.Error(err)
+	}
+
+	if err := SetUIDLabelEnabled(false); err == nil {
+		t.Error("expected an error changing the uid label of registered metrics")
+	}
+}
+
+func TestRecordScalerMetricUnitLabel(t *testing.T) {
+	scalerMetricsValue.Reset()
+	RecordScalerMetric("test-namespace", "unit-so", "", "awsCloudwatchScaler", 0, external_metrics.ExternalMetricValue{
+		MetricName: "s0-aws-cloudwatch",
+		Value:      resource.MustParse("1500m"),
+	}, false, "bytes_per_second")
+
+	expected := `
+# HELP keda_scaler_metrics_value Metric Value used for HPA, cached is true when the value was read from the cache of useCachedMetrics or reused until the pollingInterval of the trigger elapsed, unit is set when the scaler knows the unit of the value
+# TYPE keda_scaler_metrics_value gauge
+keda_scaler_metrics_value{cached="false",metric="s0-aws-cloudwatch",namespace="test-namespace",scaledObject="unit-so",scaler="awsCloudwatchScaler",scalerIndex="0",unit="bytes_per_second"} 1.5
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_metrics_value"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordScalerMetricCachedLabel(t *testing.T) {
+	scalerMetricsValue.Reset()
+	metric := external_metrics.ExternalMetricValue{MetricName: "s0-queue", Value: *resource.NewQuantity(3, resource.DecimalSI)}
+	RecordScalerMetric("test-namespace", "cached-so", "", "queueScaler", 0, metric, false, "")
+
+	expected := `
+# HELP keda_scaler_metrics_value Metric Value used for HPA, cached is true when the value was read from the cache of useCachedMetrics or reused until the pollingInterval of the trigger elapsed, unit is set when the scaler knows the unit of the value
+# TYPE keda_scaler_metrics_value gauge
+keda_scaler_metrics_value{cached="false",metric="s0-queue",namespace="test-namespace",scaledObject="cached-so",scaler="queueScaler",scalerIndex="0",unit=""} 3
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_metrics_value"); err != nil {
+		t.Error(err)
+	}
+
+	// the cached read replaces the series of the live one
+	metric.Value = *resource.NewQuantity(4, resource.DecimalSI)
+	RecordScalerMetric("test-namespace", "cached-so", "", "queueScaler", 0, metric, true, "")
+	expected = `
+# HELP keda_scaler_metrics_value Metric Value used for HPA, cached is true when the value was read from the cache of useCachedMetrics or reused until the pollingInterval of the trigger elapsed, unit is set when the scaler knows the unit of the value
+# TYPE keda_scaler_metrics_value gauge
+keda_scaler_metrics_value{cached="true",metric="s0-queue",namespace="test-namespace",scaledObject="cached-so",scaler="queueScaler",scalerIndex="0",unit=""} 4
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_metrics_value"); err != nil {
+		t.Error(err)
+	}
+
+	DeleteScalerMetrics("test-namespace", "cached-so")
+	if count := testutil.CollectAndCount(scalerMetricsValue); count != 0 {
+		t.Errorf("Expected no series after the delete but got %d", count)
+	}
+}
+
+func TestRecordScaledObjectTargetKind(t *testing.T) {
+	scaledObjectTargetKind.Reset()
+	RecordScaledObjectTargetKind("test-namespace", "deployment-so", "Deployment")
+	RecordScaledObjectTargetKind("test-namespace", "custom-so", "Rollout")
+
+	expected := `
+# HELP keda_scaledobject_target_kind Kind of the resolved scale target of the scaled object
+# TYPE keda_scaledobject_target_kind gauge
+keda_scaledobject_target_kind{kind="Deployment",namespace="test-namespace",scaledObject="deployment-so"} 1
+keda_scaledobject_target_kind{kind="Rollout",namespace="test-namespace",scaledObject="custom-so"} 1
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_target_kind"); err != nil {
+		t.Error(err)
+	}
+
+	// the previous kind is replaced when the scale target changes
+	RecordScaledObjectTargetKind("test-namespace", "deployment-so", "StatefulSet")
+	DeleteScaledObjectTargetKind("test-namespace", "custom-so")
+
+	expected = `
+# HELP keda_scaledobject_target_kind Kind of the resolved scale target of the scaled object
+# TYPE keda_scaledobject_target_kind gauge
+keda_scaledobject_target_kind{kind="StatefulSet",namespace="test-namespace",scaledObject="deployment-so"} 1
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_target_kind"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordScaledObjectIdleReplicas(t *testing.T) {
+	scaledObjectIdleReplicas.Reset()
+	idleReplicas := int32(0)
+	RecordScaledObjectIdleReplicas("test-namespace", "idle-so", &idleReplicas)
+	RecordScaledObjectIdleReplicas("test-namespace", "default-so", nil)
+
+	expected := `
+# HELP keda_scaledobject_idle_replicas Configured idleReplicaCount of the scaled object, absent when it isn't set
+# TYPE keda_scaledobject_idle_replicas gauge
+keda_scaledobject_idle_replicas{namespace="test-namespace",scaledObject="idle-so"} 0
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_idle_replicas"); err != nil {
+		t.Error(err)
+	}
+
+	// the measurement is removed when the idleReplicaCount is unset
+	RecordScaledObjectIdleReplicas("test-namespace", "idle-so", nil)
+	if count := testutil.CollectAndCount(scaledObjectIdleReplicas); count != 0 {
+		t.Errorf("Expected no series once the idleReplicaCount is unset but got %d", count)
+	}
+
+	idleReplicas = 1
+	RecordScaledObjectIdleReplicas("test-namespace", "idle-so", &idleReplicas)
+	if value := testutil.ToFloat64(scaledObjectIdleReplicas.With(prometheus.Labels{"namespace": "test-namespace", "scaledObject": "idle-so"})); value != 1 {
+		t.Errorf("Expected an idle replica count of 1 but got %v", value)
+	}
+	DeleteScaledObjectIdleReplicas("test-namespace", "idle-so")
+	if count := testutil.CollectAndCount(scaledObjectIdleReplicas); count != 0 {
+		t.Errorf("Expected no series after the delete but got %d", count)
+	}
+}
+
+func TestRecordScaledJobAccurateBacklog(t *testing.T) {
+	scaledJobAccurateBacklog.Reset()
+	RecordScaledJobAccurateBacklog("test-namespace", "accurate-sj", 3)
+	RecordScaledJobAccurateBacklog("test-namespace", "accurate-sj", 2)
+
+	expected := `
+# HELP keda_scaledjob_accurate_backlog Number of jobs the accurate scaling strategy of the scaled job computed from the queue length, the running and the pending jobs
+# TYPE keda_scaledjob_accurate_backlog gauge
+keda_scaledjob_accurate_backlog{namespace="test-namespace",scaledJob="accurate-sj"} 2
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledjob_accurate_backlog"); err != nil {
+		t.Error(err)
+	}
+
+	DeleteScaledJobAccurateBacklog("test-namespace", "accurate-sj")
+	if count := testutil.CollectAndCount(scaledJobAccurateBacklog); count != 0 {
+		t.Errorf("Expected no series after the delete but got %d", count)
+	}
+}
+
+func TestRecordScalerValueCoercion(t *testing.T) {
+	scalerValueCoercions.Reset()
+	RecordScalerValueCoercion("metricsAPIScaler")
+	RecordScalerValueCoercion("metricsAPIScaler")
+	RecordScalerValueCoercion("elasticsearchScaler")
+
+	expected := `
+# HELP keda_scaler_value_coercions_total Total number of metric values the scalers parsed from a string instead of reading a number from the response
+# TYPE keda_scaler_value_coercions_total counter
+keda_scaler_value_coercions_total{scaler="elasticsearchScaler"} 1
+keda_scaler_value_coercions_total{scaler="metricsAPIScaler"} 2
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_value_coercions_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordTriggerDeprecatedFieldUsage(t *testing.T) {
+	triggerDeprecatedFieldUsage.Reset()
+	RecordTriggerDeprecatedFieldUsage("prometheus", "metricName")
+	RecordTriggerDeprecatedFieldUsage("prometheus", "metricName")
+	RecordTriggerDeprecatedFieldUsage("cpu", "type")
+
+	expected := `
+# HELP keda_trigger_deprecated_field_usage_total Total number of reconciliations of scaled objects and scaled jobs with a deprecated field set in a trigger, by trigger type and field
+# TYPE keda_trigger_deprecated_field_usage_total counter
+keda_trigger_deprecated_field_usage_total{field="metricName",type="prometheus"} 2
+keda_trigger_deprecated_field_usage_total{field="type",type="cpu"} 1
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_trigger_deprecated_field_usage_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordPrometheusScalerResultSeries(t *testing.T) {
+	scalerPrometheusResultSeries.Reset()
+	scalerPrometheusMultiResults.Reset()
+	RecordPrometheusScalerResultSeries("test-namespace", "single-so", 1)
+	RecordPrometheusScalerResultSeries("test-namespace", "multi-so", 3)
+	RecordPrometheusScalerResultSeries("test-namespace", "multi-so", 2)
+
+	expected := `
+# HELP keda_scaler_prometheus_multiresult_total Total number of queries of the prometheus scalers of the scaled object returning more than one series
+# TYPE keda_scaler_prometheus_multiresult_total counter
+keda_scaler_prometheus_multiresult_total{namespace="test-namespace",scaledObject="multi-so"} 2
+# HELP keda_scaler_prometheus_result_series Number of series returned by the last query of the prometheus scalers of the scaled object
+# TYPE keda_scaler_prometheus_result_series gauge
+keda_scaler_prometheus_result_series{namespace="test-namespace",scaledObject="multi-so"} 2
+keda_scaler_prometheus_result_series{namespace="test-namespace",scaledObject="single-so"} 1
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_prometheus_result_series", "keda_scaler_prometheus_multiresult_total"); err != nil {
+		t.Error(err)
+	}
+
+	DeleteScalerMetrics("test-namespace", "multi-so")
+	if count := testutil.CollectAndCount(scalerPrometheusMultiResults); count != 0 {
+		t.Errorf("Expected no multi result series after the delete but got %d", count)
+	}
+	if count := testutil.CollectAndCount(scalerPrometheusResultSeries); count != 1 {
+		t.Errorf("Expected the result series of single-so only after the delete but got %d", count)
+	}
+}
+
+func TestRecordOperatorConfigReload(t *testing.T) {
+	reloads := testutil.ToFloat64(operatorConfigReloads)
+	reloadErrors := testutil.ToFloat64(operatorConfigReloadErrors)
+
+	RecordOperatorConfigReload(nil)
+	if value := testutil.ToFloat64(operatorConfigReloads); value != reloads+1 {
+		t.Errorf("Expected %v reloads but got %v", reloads+1, value)
+	}
+	if value := testutil.ToFloat64(operatorConfigReloadErrors); value != reloadErrors {
+		t.Errorf("Expected %v reload errors after a successful reload but got %v", reloadErrors, value)
+	}
+
+	RecordOperatorConfigReload(errors.New("invalid config"))
+	if value := testutil.ToFloat64(operatorConfigReloads); value != reloads+2 {
+		t.Errorf("Expected %v reloads but got %v", reloads+2, value)
+	}
+	if value := testutil.ToFloat64(operatorConfigReloadErrors); value != reloadErrors+1 {
+		t.Errorf("Expected %v reload errors after a failed reload but got %v", reloadErrors+1, value)
+	}
+}
+
+func TestOperatorStartTimeIsSetAtInit(t *testing.T) {
+	startTime := testutil.ToFloat64(operatorStartTime)
+	now := float64(time.Now().UnixNano()) / float64(time.Second)
+
+	if startTime <= 0 || startTime > now {
+		t.Errorf("Expected the operator start time to be set before %v but got %v", now, startTime)
+	}
+	if now-startTime > time.Minute.Seconds() {
+		t.Errorf("Expected the operator start time to be near %v but got %v", now, startTime)
+	}
+}
+
+func TestRecordScaledObjectConditions(t *testing.T) {
+	RecordScaledObjectConditions("default", "ready-active", "ScaledObjectReady", "ScalerActive")
+	RecordScaledObjectConditions("default", "ready-idle", "ScaledObjectReady", "ScalerNotActive")
+	RecordScaledObjectConditions("other", "ready-idle", "ScaledObjectReady", "ScalerNotActive")
+	RecordScaledObjectConditions("default", "failed", "ScaledObjectCheckFailed", "UnkownState")
+	// the scale loop changes the conditions of a recorded scaled object only
+	RecordScaledObjectCondition("default", "ready-idle", "Active", "ScalerActive")
+	RecordScaledObjectCondition("default", "not-reconciled", "Active", "ScalerActive")
+	// the condition reasons of the deleted scaled objects aren't counted anymore
+	DeleteScaledObjectConditions("default", "failed")
+	t.Cleanup(func() {
+		for _, scaledObject := range []string{"default/ready-active", "default/ready-idle", "other/ready-idle"} {
+			namespace, name, _ := strings.Cut(scaledObject, "/")
+			DeleteScaledObjectConditions(namespace, name)
+		}
+	})
+
+	expected := `
+# HELP keda_scaledobjects_by_condition Number of scaled objects currently in each Ready and Active condition reason
+# TYPE keda_scaledobjects_by_condition gauge
+keda_scaledobjects_by_condition{condition="Active",reason="ScalerActive"} 2
+keda_scaledobjects_by_condition{condition="Active",reason="ScalerNotActive"} 1
+keda_scaledobjects_by_condition{condition="Ready",reason="ScaledObjectReady"} 3
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobjects_by_condition"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordScaledObjectsPausedAtReplicas(t *testing.T) {
+	RecordScaledObjectsPausedAtReplicas([]int32{3, 7})
+	// the recomputed counts replace the previous ones
+	RecordScaledObjectsPausedAtReplicas([]int32{0, 0, 1, 4, 10, 250})
+
+	expected := `
+# HELP keda_scaledobjects_paused_at_replicas Distribution of the replica counts the scaled objects paused with the paused-replicas annotation are currently held at
+# TYPE keda_scaledobjects_paused_at_replicas histogram
+keda_scaledobjects_paused_at_replicas_bucket{le="0"} 2
+keda_scaledobjects_paused_at_replicas_bucket{le="1"} 3
+keda_scaledobjects_paused_at_replicas_bucket{le="2"} 3
+keda_scaledobjects_paused_at_replicas_bucket{le="5"} 4
+keda_scaledobjects_paused_at_replicas_bucket{le="10"} 5
+keda_scaledobjects_paused_at_replicas_bucket{le="20"} 5
+keda_scaledobjects_paused_at_replicas_bucket{le="50"} 5
+keda_scaledobjects_paused_at_replicas_bucket{le="100"} 5
+keda_scaledobjects_paused_at_replicas_bucket{le="+Inf"} 6
+keda_scaledobjects_paused_at_replicas_sum 265
+keda_scaledobjects_paused_at_replicas_count 6
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobjects_paused_at_replicas"); err != nil {
+		t.Error(err)
+	}
+
+	RecordScaledObjectsPausedAtReplicas(nil)
+	expected = `
+# HELP keda_scaledobjects_paused_at_replicas Distribution of the replica counts the scaled objects paused with the paused-replicas annotation are currently held at
+# TYPE keda_scaledobjects_paused_at_replicas histogram
+keda_scaledobjects_paused_at_replicas_bucket{le="0"} 0
+keda_scaledobjects_paused_at_replicas_bucket{le="1"} 0
+keda_scaledobjects_paused_at_replicas_bucket{le="2"} 0
+keda_scaledobjects_paused_at_replicas_bucket{le="5"} 0
+keda_scaledobjects_paused_at_replicas_bucket{le="10"} 0
+keda_scaledobjects_paused_at_replicas_bucket{le="20"} 0
+keda_scaledobjects_paused_at_replicas_bucket{le="50"} 0
+keda_scaledobjects_paused_at_replicas_bucket{le="100"} 0
+keda_scaledobjects_paused_at_replicas_bucket{le="+Inf"} 0
+keda_scaledobjects_paused_at_replicas_sum 0
+keda_scaledobjects_paused_at_replicas_count 0
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobjects_paused_at_replicas"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordRuntimeInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     RuntimeInfo
+		expected string
+	}{
+		{
+			name: "defaults",
+			info: RuntimeInfo{Version: "main", GoVersion: "go1.20", KubernetesVersion: "1.26", AutoscalingAPI: "autoscaling/v2"},
+			expected: `keda_runtime_info{autoscaling_api="autoscaling/v2",cert_rotation="false",git_commit="",go_version="go1.20",kubernetes_version="1.26",scaledobject_generation="false",version="main"} 1
+`,
+		},
+		{
+			name: "all components enabled",
+			info: RuntimeInfo{Version: "2.10.0", GitCommit: "abc123", GoVersion: "go1.20", CertRotation: true, ScaledObjectGeneration: true, KubernetesVersion: "1.26", AutoscalingAPI: "autoscaling/v2"},
+			expected: `keda_runtime_info{autoscaling_api="autoscaling/v2",cert_rotation="true",git_commit="abc123",go_version="go1.20",kubernetes_version="1.26",scaledobject_generation="true",version="2.10.0"} 1
+`,
+		},
+		{
+			name: "legacy autoscaling api",
+			info: RuntimeInfo{Version: "2.10.0", GitCommit: "abc123", GoVersion: "go1.20", CertRotation: true, KubernetesVersion: "1.22", AutoscalingAPI: "autoscaling/v2beta2"},
+			expected: `keda_runtime_info{autoscaling_api="autoscaling/v2beta2",cert_rotation="true",git_commit="abc123",go_version="go1.20",kubernetes_version="1.22",scaledobject_generation="false",version="2.10.0"} 1
+`,
+		},
+	}
+
+	for _, test := range tests {
+		RecordRuntimeInfo(test.info)
+		expected := `
+# HELP keda_runtime_info A metric with a constant '1' value labeled by the build, the enabled components and the detected Kubernetes API capabilities of the operator
+# TYPE keda_runtime_info gauge
+` + test.expected
+		if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_runtime_info"); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestRecordScalerErrorByType(t *testing.T) {
+	scalerErrors.Reset()
+	RecordScalerError("test-error-types", "so", "", "scaler", 0, "s0-metric", nil, "")
+	RecordScalerError("test-error-types", "so", "", "scaler", 0, "s0-metric", errors.New("unauthorized"), ScalerErrorTypeAuth)
+	RecordScalerError("test-error-types", "so", "", "scaler", 0, "s0-metric", errors.New("unauthorized"), ScalerErrorTypeAuth)
+	RecordScalerError("test-error-types", "so", "", "scaler", 0, "s0-metric", errors.New("unknown"), ScalerErrorTypeUnknown)
+
+	// only the series of the error types which occurred are created
+	expected := `
+# HELP keda_scaler_errors Number of scaler errors
+# TYPE keda_scaler_errors counter
+keda_scaler_errors{errorType="auth",metric="s0-metric",namespace="test-error-types",scaledObject="so",scaler="scaler",scalerIndex="0"} 2
+keda_scaler_errors{errorType="unknown",metric="s0-metric",namespace="test-error-types",scaledObject="so",scaler="scaler",scalerIndex="0"} 1
+`
+	if err := testutil.CollectAndCompare(scalerErrors, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordScalerMetricTruncatesLongLabelValues(t *testing.T) {
+	labelValueMaxLength = 32
+	defer func() { labelValueMaxLength = 0 }()
+	metricTruncations := testutil.ToFloat64(metricsLabelsTruncated.With(prometheus.Labels{"label": "metric"}))
+	scaledObjectTruncations := testutil.ToFloat64(metricsLabelsTruncated.With(prometheus.Labels{"label": "scaledObject"}))
+
+	scaledObject := "truncated-" + strings.Repeat("scaledobject", 3)
+	RecordScalerMetric("test-namespace", scaledObject, "", "prometheusScaler", 0, external_metrics.ExternalMetricValue{
+		MetricName: "s0-prometheus-" + strings.Repeat("sum(rate(http_requests_total[2m]))", 4),
+		Value:      resource.MustParse("3"),
+	}, false, "")
+	// the multi-byte character crossing the limit is dropped whole
+	RecordScalerMetric("test-namespace", scaledObject, "", "prometheusScaler", 1, external_metrics.ExternalMetricValue{
+		MetricName: "s1-prometheus-" + strings.Repeat("é", 20),
+		Value:      resource.MustParse("4"),
+	}, false, "")
+
+	expected := map[string]float64{"s0-prometheus-sum(rate(-c033f11c": 3, "s1-prometheus-éééé-c3d72f6d": 4}
+	if values := getScalerMetricValues(t, "truncated-scaledobjects-490e3b59"); fmt.Sprint(values) != fmt.Sprint(expected) {
+		t.Errorf("expected the metric values %v, got %v", expected, values)
+	}
+	// each distinct truncated value is only counted once
+	RecordScalerLatency("test-namespace", scaledObject, "", "prometheusScaler", 0, "s0-prometheus-"+strings.Repeat("sum(rate(http_requests_total[2m]))", 4), 12)
+	if value := testutil.ToFloat64(metricsLabelsTruncated.With(prometheus.Labels{"label": "metric"})); value != metricTruncations+2 {
+		t.Errorf("expected %v truncated metric labels, got %v", metricTruncations+2, value)
+	}
+	if value := testutil.ToFloat64(metricsLabelsTruncated.With(prometheus.Labels{"label": "scaledObject"})); value != scaledObjectTruncations+1 {
+		t.Errorf("expected %v truncated scaledObject labels, got %v", scaledObjectTruncations+1, value)
+	}
+
+	// all the series of the truncated scaled object name are deleted with it
+	DeleteScalerMetrics("test-namespace", scaledObject)
+	if values := getScalerMetricValues(t, "truncated-scaledobjects-490e3b59"); len(values) != 0 {
+		t.Errorf("expected the metric values to be deleted, got %v", values)
+	}
+	if count := scalerMetricsLatency.DeletePartialMatch(prometheus.Labels{"scaledObject": "truncated-scaledobjects-490e3b59"}); count != 0 {
+		t.Errorf("expected the latency series to be deleted, got %d", count)
+	}
+}
+
+func getScalerMetricValues(t *testing.T, scaledObject string) map[string]float64 {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "keda_scaler_metrics_value" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["scaledObject"] == scaledObject {
+				values[labels["metric"]] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+func TestSetLabelValueMaxLength(t *testing.T) {
+	if err := SetLabelValueMaxLength(10); err == nil {
+		t.Error("expected an error for a length shorter than the hash suffix")
+	}
+	registerScalerMetrics()
+	if err := SetLabelValueMaxLength(64); err == nil {
+		t.Error("expected an error changing the maximum length of registered metrics")
+	}
+	if labelValueMaxLength != 0 {
+		t.Errorf("expected the maximum length to be unchanged, got %d", labelValueMaxLength)
+	}
+}
+
+func TestRecordReplicaGaugesWithoutRegion(t *testing.T) {
+	namespaceManagedReplicasCurrent.Reset()
+	t.Cleanup(func() { DeleteNamespaceManagedReplicas("test-namespace") })
+
+	RecordNamespaceManagedReplicas("test-namespace", 3, 10)
+
+	expected := `
+# HELP keda_namespace_managed_replicas_current Sum of the current replicas of the scale targets of the ScaledObjects in the namespace
+# TYPE keda_namespace_managed_replicas_current gauge
+keda_namespace_managed_replicas_current{namespace="test-namespace"} 3
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_namespace_managed_replicas_current"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordReplicaGaugesWithRegion(t *testing.T) {
+	SetRegion("eu-west-1")
+	t.Cleanup(func() { SetRegion("") })
+
+	RecordNamespaceManagedReplicas("test-namespace", 3, 10)
+	RecordScaledObjectDryRunDesiredReplicas("test-namespace", "dry-run-so", 4)
+
+	expected := `
+# HELP keda_namespace_managed_replicas_current Sum of the current replicas of the scale targets of the ScaledObjects in the namespace
+# TYPE keda_namespace_managed_replicas_current gauge
+keda_namespace_managed_replicas_current{namespace="test-namespace",region="eu-west-1"} 3
+# HELP keda_namespace_managed_replicas_max Sum of the maximum replica counts of the ScaledObjects in the namespace
+# TYPE keda_namespace_managed_replicas_max gauge
+keda_namespace_managed_replicas_max{namespace="test-namespace",region="eu-west-1"} 10
+# HELP keda_scaledobject_dry_run_desired_replicas Replica count KEDA would scale the scale target of the scaled object in dry-run mode to
+# TYPE keda_scaledobject_dry_run_desired_replicas gauge
+keda_scaledobject_dry_run_desired_replicas{namespace="test-namespace",region="eu-west-1",scaledObject="dry-run-so"} 4
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected),
+		"keda_namespace_managed_replicas_current", "keda_namespace_managed_replicas_max", "keda_scaledobject_dry_run_desired_replicas"); err != nil {
+		t.Error(err)
+	}
+
+	// the series are deleted with the region they were recorded with
+	DeleteNamespaceManagedReplicas("test-namespace")
+	DeleteScaledObjectDryRunDesiredReplicas("test-namespace", "dry-run-so")
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(""),
+		"keda_namespace_managed_replicas_current", "keda_namespace_managed_replicas_max", "keda_scaledobject_dry_run_desired_replicas"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordOperatorLeader(t *testing.T) {
+	t.Cleanup(func() { RecordOperatorLeader(true) })
+	scalerMetricsValue.Reset()
+	scaledObjectTargetKind.Reset()
+	RecordScalerMetric("test-namespace", "leader-so", "", "queueScaler", 0, external_metrics.ExternalMetricValue{
+		MetricName: "s0-queue",
+		Value:      resource.MustParse("4"),
+	}, false, "")
+	RecordScaledObjectTargetKind("test-namespace", "leader-so", "Deployment")
+	dropped := testutil.ToFloat64(metricsDroppedNonLeader)
+
+	// the scaling metrics of a follower are withheld and counted, the replica-local ones are still gathered
+	RecordOperatorLeader(false)
+	if value := testutil.ToFloat64(operatorLeader); value != 0 {
+		t.Errorf("Expected the leader gauge to be 0 on a follower but got %v", value)
+	}
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gathered := map[string]bool{}
+	for _, family := range families {
+		gathered[family.GetName()] = true
+	}
+	for _, name := range []string{"keda_scaler_metrics_value", "keda_scaledobject_target_kind"} {
+		if gathered[name] {
+			t.Errorf("Expected %s not to be gathered on a follower", name)
+		}
+	}
+	if !gathered["keda_operator_config_reloads_total"] {
+		t.Error("Expected the replica-local keda_operator_config_reloads_total to be gathered on a follower")
+	}
+	withheld := testutil.ToFloat64(metricsDroppedNonLeader)
+	if withheld < dropped+2 {
+		t.Errorf("Expected at least 2 withheld series but got %v", withheld-dropped)
+	}
+
+	// the leader exports the series recorded before it was elected
+	RecordOperatorLeader(true)
+	if value := testutil.ToFloat64(operatorLeader); value != 1 {
+		t.Errorf("Expected the leader gauge to be 1 on the leader but got %v", value)
+	}
+	expected := `
+# HELP keda_scaler_metrics_value Metric Value used for HPA, cached is true when the value was read from the cache of useCachedMetrics or reused until the pollingInterval of the trigger elapsed, unit is set when the scaler knows the unit of the value
+# TYPE keda_scaler_metrics_value gauge
+keda_scaler_metrics_value{cached="false",metric="s0-queue",namespace="test-namespace",scaledObject="leader-so",scaler="queueScaler",scalerIndex="0",unit=""} 4
+# HELP keda_scaledobject_target_kind Kind of the resolved scale target of the scaled object
+# TYPE keda_scaledobject_target_kind gauge
+keda_scaledobject_target_kind{kind="Deployment",namespace="test-namespace",scaledObject="leader-so"} 1
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_metrics_value", "keda_scaledobject_target_kind"); err != nil {
+		t.Error(err)
+	}
+	if value := testutil.ToFloat64(metricsDroppedNonLeader); value != withheld {
+		t.Errorf("Expected no more withheld series on the leader but got %v", value-withheld)
+	}
+}
+
+func TestLeaderRecorder(t *testing.T) {
+	t.Cleanup(func() { RecordOperatorLeader(true) })
+	RecordOperatorLeader(false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- LeaderRecorder{}.Start(ctx) }()
+	assert.Eventually(t, func() bool { return testutil.ToFloat64(operatorLeader) == 1 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if value := testutil.ToFloat64(operatorLeader); value != 0 {
+		t.Errorf("Expected the leader gauge to be 0 after losing the lease but got %v", value)
+	}
+}
+
+func TestRecordScalerPartitions(t *testing.T) {
+	scalerPartitions.Reset()
+	RecordScalerPartitions("test-namespace", "stream-so", "kafkaScaler", 6)
+	RecordScalerPartitions("test-namespace", "other-so", "awsKinesisStreamScaler", 2)
+
+	expected := `
+# HELP keda_scaler_partitions Number of partitions or shards observed by a streaming scaler
+# TYPE keda_scaler_partitions gauge
+keda_scaler_partitions{namespace="test-namespace",scaledObject="other-so",scaler="awsKinesisStreamScaler"} 2
+keda_scaler_partitions{namespace="test-namespace",scaledObject="stream-so",scaler="kafkaScaler"} 6
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_partitions"); err != nil {
+		t.Error(err)
+	}
+
+	DeleteScalerMetrics("test-namespace", "stream-so")
+	if count := testutil.CollectAndCount(scalerPartitions); count != 1 {
+		t.Errorf("Expected only the series of the other scaled object after the delete but got %d", count)
+	}
+}
+
+func TestScalerHTTPMetricsRecorder(t *testing.T) {
+	scalerHTTPResponses.Reset()
+	scalerResponseBytes.Reset()
+	recorder := ScalerHTTPMetricsRecorder{}
+	for _, statusCode := range []int{200, 204, 404, 503} {
+		recorder.RecordHTTPResponse("prometheus", statusCode)
+	}
+	recorder.RecordResponseBytes("prometheus", 2000)
+
+	expected := `
+# HELP keda_scaler_http_responses_total Total number of HTTP responses received by the scalers, by trigger type and status class
+# TYPE keda_scaler_http_responses_total counter
+keda_scaler_http_responses_total{scaler="prometheus",status_class="2xx"} 2
+keda_scaler_http_responses_total{scaler="prometheus",status_class="4xx"} 1
+keda_scaler_http_responses_total{scaler="prometheus",status_class="5xx"} 1
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_http_responses_total"); err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(scalerResponseBytes); count != 1 {
+		t.Errorf("Expected one response bytes series but got %d", count)
+	}
+}
+
+func TestRecordScalerExposedMetrics(t *testing.T) {
+	scalerExposedMetrics.Reset()
+	RecordScalerExposedMetrics("test-namespace", "exposed-so", "prometheusScaler", 3)
+	RecordScalerExposedMetrics("test-namespace", "other-so", "cpuScaler", 1)
+
+	expected := `
+# HELP keda_scaler_exposed_metrics Number of distinct external metric names exposed by a scaler
+# TYPE keda_scaler_exposed_metrics gauge
+keda_scaler_exposed_metrics{namespace="test-namespace",scaledObject="exposed-so",scaler="prometheusScaler"} 3
+keda_scaler_exposed_metrics{namespace="test-namespace",scaledObject="other-so",scaler="cpuScaler"} 1
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_exposed_metrics"); err != nil {
+		t.Error(err)
+	}
+
+	DeleteScalerMetrics("test-namespace", "exposed-so")
+	if count := testutil.CollectAndCount(scalerExposedMetrics); count != 1 {
+		t.Errorf("Expected only the series of the other scaled object after the delete but got %d", count)
+	}
+	DeleteScalerMetrics("test-namespace", "other-so")
+}
+
+func TestRecordScaledObjectTriggerContribution(t *testing.T) {
+	RecordScaledObjectTriggerContribution("test-namespace", "combined-so", "", "queue", 0, "s0-queue", 4)
+	RecordScaledObjectTriggerContribution("test-namespace", "combined-so", "", "stream", 1, "s1-stream", 6)
+	RecordScaledObjectTriggerContribution("test-namespace", "other-so", "", "queue", 0, "s0-queue", 1)
+
+	expected := `
+# HELP keda_scaledobject_trigger_contribution Replica count the metric of a trigger with an AverageValue target would request alone, the scaled object is scaled to the highest one
+# TYPE keda_scaledobject_trigger_contribution gauge
+keda_scaledobject_trigger_contribution{metric="s0-queue",namespace="test-namespace",scaledObject="combined-so",scaler="queue",scalerIndex="0"} 4
+keda_scaledobject_trigger_contribution{metric="s0-queue",namespace="test-namespace",scaledObject="other-so",scaler="queue",scalerIndex="0"} 1
+keda_scaledobject_trigger_contribution{metric="s1-stream",namespace="test-namespace",scaledObject="combined-so",scaler="stream",scalerIndex="1"} 6
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_trigger_contribution"); err != nil {
+		t.Error(err)
+	}
+
+	DeleteScalerMetrics("test-namespace", "combined-so")
+	if count := testutil.CollectAndCount(scaledObjectTriggerContribution); count != 1 {
+		t.Errorf("Expected only the series of the other scaled object after the delete but got %d", count)
+	}
+	DeleteScalerMetrics("test-namespace", "other-so")
+}
+
+func TestRecordScalerBackendHealth(t *testing.T) {
+	scalerUnhealthyByBackend.Reset()
+	scalerHealths = map[scalerHealthKey]scalerHealth{}
+	RecordScalerBackendHealth("test-namespace", "orders-so", 0, "rabbitmq:5672", false)
+	RecordScalerBackendHealth("test-namespace", "orders-so", 1, "postgres:5432", true)
+	RecordScalerBackendHealth("test-namespace", "invoices-so", 0, "rabbitmq:5672", false)
+	// the scaler now queries another backend
+	RecordScalerBackendHealth("test-namespace", "invoices-so", 1, "redis:6379", false)
+	RecordScalerBackendHealth("test-namespace", "invoices-so", 1, "postgres:5432", true)
+
+	expected := `
+# HELP keda_scaler_unhealthy_by_backend Number of scalers failing their last health check by the host of their backend
+# TYPE keda_scaler_unhealthy_by_backend gauge
+keda_scaler_unhealthy_by_backend{backend="postgres:5432"} 0
+keda_scaler_unhealthy_by_backend{backend="rabbitmq:5672"} 2
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_unhealthy_by_backend"); err != nil {
+		t.Error(err)
+	}
+
+	RecordScalerBackendHealth("test-namespace", "orders-so", 0, "rabbitmq:5672", true)
+	DeleteScalerMetrics("test-namespace", "invoices-so")
+	expected = `
+# HELP keda_scaler_unhealthy_by_backend Number of scalers failing their last health check by the host of their backend
+# TYPE keda_scaler_unhealthy_by_backend gauge
+keda_scaler_unhealthy_by_backend{backend="postgres:5432"} 0
+keda_scaler_unhealthy_by_backend{backend="rabbitmq:5672"} 0
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaler_unhealthy_by_backend"); err != nil {
+		t.Error(err)
+	}
+
+	DeleteScalerMetrics("test-namespace", "orders-so")
+	if count := testutil.CollectAndCount(scalerUnhealthyByBackend); count != 0 {
+		t.Errorf("Expected no series once no scaler queries the backends but got %d", count)
+	}
+}
+
+func TestRecordScaledObjectReconcileDeferred(t *testing.T) {
+	scaledObjectReconcileDeferred.Reset()
+	RecordScaledObjectReconcileDeferred("test-namespace", ReconcileDeferredReasonScaleTargetNotFound)
+	RecordScaledObjectReconcileDeferred("test-namespace", ReconcileDeferredReasonScaleTargetNotFound)
+	RecordScaledObjectReconcileDeferred("test-namespace", ReconcileDeferredReasonScaleTargetKindNotFound)
+
+	expected := `
+# HELP keda_scaledobject_reconcile_deferred_total Total number of scaled object reconciles deferred because a resource they depend on was not found
+# TYPE keda_scaledobject_reconcile_deferred_total counter
+keda_scaledobject_reconcile_deferred_total{namespace="test-namespace",reason="ScaleTargetKindNotFound"} 1
+keda_scaledobject_reconcile_deferred_total{namespace="test-namespace",reason="ScaleTargetNotFound"} 2
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_reconcile_deferred_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordScaledObjectDesiredReplicas(t *testing.T) {
+	scaledObjectDesiredReplicas.Reset()
+	for _, replicas := range []int32{0, 1, 4, 4, 12, 2000} {
+		RecordScaledObjectDesiredReplicas("test-namespace", "planned-so", replicas)
+	}
+
+	expected := `
+# HELP keda_scaledobject_desired_replicas_distribution Distribution of the replica counts needed by the metric values of the scaled object, observed on each poll
+# TYPE keda_scaledobject_desired_replicas_distribution histogram
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="0"} 1
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="1"} 2
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="2"} 2
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="3"} 2
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="5"} 4
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="10"} 4
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="20"} 5
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="50"} 5
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="100"} 5
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="200"} 5
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="500"} 5
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="1000"} 5
+keda_scaledobject_desired_replicas_distribution_bucket{namespace="test-namespace",scaledObject="planned-so",le="+Inf"} 6
+keda_scaledobject_desired_replicas_distribution_sum{namespace="test-namespace",scaledObject="planned-so"} 2021
+keda_scaledobject_desired_replicas_distribution_count{namespace="test-namespace",scaledObject="planned-so"} 6
+`
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledobject_desired_replicas_distribution"); err != nil {
+		t.Error(err)
+	}
+
+	DeleteScalerMetrics("test-namespace", "planned-so")
+	if count := testutil.CollectAndCount(scaledObjectDesiredReplicas); count != 0 {
+		t.Errorf("Expected no series after the delete but got %d", count)
+	}
+}
@@ -19,6 +19,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/labels"
@@ -29,7 +30,16 @@ import (
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/metricsservice"
+	adapterprommetrics "github.com/kedacore/keda/v2/pkg/prommetrics/adapter"
 	"github.com/kedacore/keda/v2/pkg/scaling"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	// operatorHealthCheckInterval is the interval in which the reachability of KEDA Operator is checked
+	operatorHealthCheckInterval = 10 * time.Second
+	// registeredMetricsInterval is the interval in which the external metric names of the ScaledObjects are counted
+	registeredMetricsInterval = 30 * time.Second
 )
 
 // KedaProvider implements External Metrics Provider
@@ -45,6 +55,11 @@ type KedaProvider struct {
 	useMetricsServiceGrpc bool
 }
 
+// operatorConnectionChecker checks whether the connection to KEDA Operator gRPC Metrics Service is ready
+type operatorConnectionChecker interface {
+	IsConnectionReady() bool
+}
+
 var (
 	logger logr.Logger
 
@@ -74,9 +89,74 @@ func NewProvider(ctx context.Context, adapterLogger logr.Logger, scaleHandler sc
 		}
 	}()
 
+	go startOperatorHealthCheck(ctx, &provider.grpcClient, operatorHealthCheckInterval)
+	go startRegisteredMetricsCount(ctx, client, watchedNamespace, registeredMetricsInterval)
+
 	return provider
 }
 
+// startOperatorHealthCheck periodically checks whether KEDA Operator gRPC Metrics Service is reachable
+// and exposes the result as a Prometheus metric, it blocks until the context is done
+func startOperatorHealthCheck(ctx context.Context, checker operatorConnectionChecker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		checkOperatorReachable(checker)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkOperatorReachable records whether KEDA Operator gRPC Metrics Service is reachable
+func checkOperatorReachable(checker operatorConnectionChecker) bool {
+	reachable := checker.IsConnectionReady()
+	adapterprommetrics.RecordOperatorReachable(reachable)
+	return reachable
+}
+
+// startRegisteredMetricsCount periodically counts the external metric names the HPAs can request
+// and exposes the result as a Prometheus metric, it blocks until the context is done
+func startRegisteredMetricsCount(ctx context.Context, reader client.Reader, namespace string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := countRegisteredMetrics(ctx, reader, namespace); err != nil {
+			logger.Error(err, "error counting the registered external metrics")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// countRegisteredMetrics records the number of distinct external metric names in the status of the ScaledObjects,
+// the previous value is kept if the ScaledObjects can't be listed
+func countRegisteredMetrics(ctx context.Context, reader client.Reader, namespace string) (int, error) {
+	scaledObjects := &kedav1alpha1.ScaledObjectList{}
+	if err := reader.List(ctx, scaledObjects, client.InNamespace(namespace)); err != nil {
+		return 0, err
+	}
+
+	// metric names are requested by the HPAs in their own namespace
+	names := map[string]struct{}{}
+	for _, scaledObject := range scaledObjects.Items {
+		for _, name := range scaledObject.Status.ExternalMetricNames {
+			names[scaledObject.Namespace+"/"+name] = struct{}{}
+		}
+	}
+	adapterprommetrics.RecordRegisteredMetrics(len(names))
+	return len(names), nil
+}
+
 // GetExternalMetric retrieves metrics from the scalers
 // Metric is normally identified by a name and a set of labels/tags. It is up to a specific
 // implementation how to translate metricSelector to a filter for metric values.
@@ -112,8 +192,13 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 		return &external_metrics.ExternalMetricValueList{}, err
 	}
 
-	metrics, err := p.grpcClient.GetMetrics(ctx, scaledObjectName, namespace, info.Metric)
-	logger.V(1).WithValues("scaledObjectName", scaledObjectName, "scaledObjectNamespace", namespace, "metrics", metrics).Info("Receiving metrics")
+	// the operator logs the scaler calls serving this request with the same correlation ID
+	correlationID := kedautil.NewCorrelationID()
+	metrics, err := p.grpcClient.GetMetrics(kedautil.ContextWithCorrelationID(ctx, correlationID), scaledObjectName, namespace, info.Metric)
+	logger.V(1).WithValues("scaledObjectName", scaledObjectName, "scaledObjectNamespace", namespace, "correlationID", correlationID, "metrics", metrics).Info("Receiving metrics")
+	if err == nil {
+		adapterprommetrics.RecordBatchSize(len(metrics.Items))
+	}
 
 	return metrics, err
 }
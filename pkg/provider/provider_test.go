@@ -0,0 +1,338 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	externalprovider "sigs.k8s.io/custom-metrics-apiserver/pkg/provider"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/metricsservice"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_scaling"
+	"github.com/kedacore/keda/v2/pkg/scaling"
+)
+
+type fakeConnectionChecker struct {
+	ready  atomic.Bool
+	checks atomic.Int32
+}
+
+func (f *fakeConnectionChecker) IsConnectionReady() bool {
+	f.checks.Add(1)
+	return f.ready.Load()
+}
+
+func expectedOperatorReachable(value int) string {
+	return fmt.Sprintf(`
+# HELP keda_metricsadapter_operator_reachable Whether the KEDA Operator gRPC Metrics Service is reachable from the Metrics Adapter (1) or not (0)
+# TYPE keda_metricsadapter_operator_reachable gauge
+keda_metricsadapter_operator_reachable %d
+`, value)
+}
+
+func TestCheckOperatorReachable(t *testing.T) {
+	checker := &fakeConnectionChecker{}
+
+	checker.ready.Store(true)
+	if !checkOperatorReachable(checker) {
+		t.Error("Expected operator to be reachable")
+	}
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expectedOperatorReachable(1)), "keda_metricsadapter_operator_reachable"); err != nil {
+		t.Error(err)
+	}
+
+	checker.ready.Store(false)
+	if checkOperatorReachable(checker) {
+		t.Error("Expected operator to be unreachable")
+	}
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expectedOperatorReachable(0)), "keda_metricsadapter_operator_reachable"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStartOperatorHealthCheck(t *testing.T) {
+	checker := &fakeConnectionChecker{}
+	checker.ready.Store(false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		startOperatorHealthCheck(ctx, checker, 10*time.Millisecond)
+		close(done)
+	}()
+
+	waitForChecks(t, checker, 1)
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expectedOperatorReachable(0)), "keda_metricsadapter_operator_reachable"); err != nil {
+		t.Error(err)
+	}
+
+	// operator becomes reachable, the periodic check should pick it up
+	checker.ready.Store(true)
+	waitForChecks(t, checker, checker.checks.Load()+2)
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expectedOperatorReachable(1)), "keda_metricsadapter_operator_reachable"); err != nil {
+		t.Error(err)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Health check didn't stop after the context was canceled")
+	}
+}
+
+func waitForChecks(t *testing.T, checker *fakeConnectionChecker, count int32) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for checker.checks.Load() < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected at least %d health checks, got %d", count, checker.checks.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func expectedRegisteredMetrics(value int) string {
+	return fmt.Sprintf(`
+# HELP keda_metricsadapter_registered_metrics Number of external metric names of the ScaledObjects served by the Metrics Adapter
+# TYPE keda_metricsadapter_registered_metrics gauge
+keda_metricsadapter_registered_metrics %d
+`, value)
+}
+
+func newScaledObjectWithMetrics(namespace, name string, metricNames ...string) *kedav1alpha1.ScaledObject {
+	return &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Status:     kedav1alpha1.ScaledObjectStatus{ExternalMetricNames: metricNames},
+	}
+}
+
+func TestCountRegisteredMetrics(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := kedav1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	first := newScaledObjectWithMetrics("default", "first", "s0-rabbitmq-queue", "s1-cron")
+	second := newScaledObjectWithMetrics("default", "second", "s0-rabbitmq-queue")
+	other := newScaledObjectWithMetrics("other", "first", "s0-rabbitmq-queue")
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(first, second, other).Build()
+	ctx := context.Background()
+
+	// the same metric name of the same namespace is served once
+	count, err := countRegisteredMetrics(ctx, reader, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 registered metrics, got %d", count)
+	}
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expectedRegisteredMetrics(3)), "keda_metricsadapter_registered_metrics"); err != nil {
+		t.Error(err)
+	}
+
+	// only the watched namespace is counted
+	if count, _ := countRegisteredMetrics(ctx, reader, "default"); count != 2 {
+		t.Errorf("Expected 2 registered metrics in the watched namespace, got %d", count)
+	}
+
+	// the metrics of a deleted ScaledObject are unregistered
+	if err := reader.Delete(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := countRegisteredMetrics(ctx, reader, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expectedRegisteredMetrics(2)), "keda_metricsadapter_registered_metrics"); err != nil {
+		t.Error(err)
+	}
+
+	// a new ScaledObject registers its metrics
+	if err := reader.Create(ctx, newScaledObjectWithMetrics("default", "third", "s0-kafka-orders")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := countRegisteredMetrics(ctx, reader, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expectedRegisteredMetrics(3)), "keda_metricsadapter_registered_metrics"); err != nil {
+		t.Error(err)
+	}
+}
+
+// writeTestGrpcCertificates writes a CA and a certificate of 127.0.0.1 signed by it, used by both the gRPC server
+// and the client, in the layout read by utils.LoadGrpcTLSCredentials
+func writeTestGrpcCertificates(t *testing.T, certDir string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "keda-test"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	files := map[string][]byte{
+		"ca.crt":  certPEM,
+		"tls.crt": certPEM,
+		"tls.key": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(certDir, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// getBatchSize returns the cumulative bucket counts, the count and the sum of the batch size histogram
+func getBatchSize(t *testing.T) ([8]uint64, uint64, float64) {
+	var buckets [8]uint64
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "keda_metricsadapter_batch_size" || len(family.GetMetric()) == 0 {
+			continue
+		}
+		histogram := family.GetMetric()[0].GetHistogram()
+		for i, bucket := range histogram.GetBucket() {
+			if i < len(buckets) {
+				buckets[i] = bucket.GetCumulativeCount()
+			}
+		}
+		return buckets, histogram.GetSampleCount(), histogram.GetSampleSum()
+	}
+	return buckets, 0, 0
+}
+
+func TestGetExternalMetricBatchSize(t *testing.T) {
+	certDir := t.TempDir()
+	writeTestGrpcCertificates(t, certDir)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	// the operator serves a value per trigger of the metric
+	ctrl := gomock.NewController(t)
+	scaleHandler := mock_scaling.NewMockScaleHandler(ctrl)
+	scaleHandler.EXPECT().GetScaledObjectMetrics(gomock.Any(), "so", "default", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _, metricName string) (*external_metrics.ExternalMetricValueList, error) {
+			if metricName == "s0-failing" {
+				return nil, fmt.Errorf("scaler error")
+			}
+			list := &external_metrics.ExternalMetricValueList{}
+			for i := 0; i < len(metricName); i++ {
+				list.Items = append(list.Items, external_metrics.ExternalMetricValue{MetricName: metricName, Value: *resource.NewQuantity(1, resource.DecimalSI)})
+			}
+			return list, nil
+		}).AnyTimes()
+	var handler scaling.ScaleHandler = scaleHandler
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	certsReady := make(chan struct{})
+	close(certsReady)
+	server := metricsservice.NewGrpcServer(&handler, address, certDir, certsReady)
+	go func() { _ = server.Start(ctx) }()
+
+	grpcClient, err := metricsservice.NewGrpcClient(address, certDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger = logr.Discard()
+	provider := &KedaProvider{grpcClient: *grpcClient}
+	selector := labels.SelectorFromSet(labels.Set{kedav1alpha1.ScaledObjectOwnerAnnotation: "so"})
+
+	// the histogram is process global, so only the observations of this test are asserted
+	bucketsBefore, countBefore, sumBefore := getBatchSize(t)
+
+	// the length of the metric name is the number of values of the response
+	for _, metricName := range []string{"a", "abc", "abcdefghij", "s0-failing"} {
+		requestCtx, requestCancel := context.WithTimeout(ctx, 10*time.Second)
+		values, err := provider.GetExternalMetric(requestCtx, "default", selector, externalprovider.ExternalMetricInfo{Metric: metricName})
+		requestCancel()
+		if metricName == "s0-failing" {
+			if err == nil {
+				t.Error("Expected an error for the failing metric")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values.Items) != len(metricName) {
+			t.Errorf("Expected %d values, got %d", len(metricName), len(values.Items))
+		}
+	}
+
+	// the failed request isn't observed
+	buckets, count, sum := getBatchSize(t)
+	for i, expected := range [8]uint64{1, 1, 2, 2, 3, 3, 3, 3} {
+		if buckets[i]-bucketsBefore[i] != expected {
+			t.Errorf("Expected %d observations in the bucket of %d, got %d", expected, 1<<i, buckets[i]-bucketsBefore[i])
+		}
+	}
+	if count-countBefore != 3 {
+		t.Errorf("Expected 3 observations, got %d", count-countBefore)
+	}
+	if sum-sumBefore != 14 {
+		t.Errorf("Expected a sum of 14, got %v", sum-sumBefore)
+	}
+}
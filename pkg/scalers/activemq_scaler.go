@@ -63,7 +63,7 @@ func NewActiveMQScaler(config *ScalerConfig) (Scaler, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error parsing ActiveMQ metadata: %w", err)
 	}
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy)
 
 	return &activeMQScaler{
 		metricType: metricType,
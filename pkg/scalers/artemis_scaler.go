@@ -60,7 +60,7 @@ func NewArtemisQueueScaler(config *ScalerConfig) (Scaler, error) {
 	// do we need to guarantee this timeout for a specific
 	// reason? if not, we can have buildScaler pass in
 	// the global client
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy)
 
 	metricType, err := GetMetricTargetType(config)
 	if err != nil {
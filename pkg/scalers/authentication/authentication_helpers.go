@@ -89,6 +89,13 @@ func GetAuthConfigs(triggerMetadata, authParams map[string]string) (out *AuthMet
 		out.CA = authParams["ca"]
 	}
 
+	if len(authParams["tlsServerPublicKeyPin"]) > 0 {
+		if err := kedautil.ValidateServerPublicKeyPin(authParams["tlsServerPublicKeyPin"]); err != nil {
+			return nil, err
+		}
+		out.ServerPublicKeyPin = authParams["tlsServerPublicKeyPin"]
+	}
+
 	return out, err
 }
 
@@ -97,18 +104,25 @@ func GetBearerToken(auth *AuthMeta) string {
 }
 
 func NewTLSConfig(auth *AuthMeta, unsafeSsl bool) (*tls.Config, error) {
-	return kedautil.NewTLSConfig(
+	tlsConfig, err := kedautil.NewTLSConfig(
 		auth.Cert,
 		auth.Key,
 		auth.CA,
 		unsafeSsl,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if err := kedautil.PinServerPublicKey(tlsConfig, auth.ServerPublicKeyPin); err != nil {
+		return nil, err
+	}
+	return tlsConfig, nil
 }
 
 func CreateHTTPRoundTripper(roundTripperType TransportType, auth *AuthMeta, conf ...*HTTPTransport) (rt http.RoundTripper, err error) {
 	unsafeSsl := false
 	tlsConfig := kedautil.CreateTLSClientConfig(unsafeSsl)
-	if auth != nil && (auth.CA != "" || auth.EnableTLS) {
+	if auth != nil && (auth.CA != "" || auth.EnableTLS || auth.ServerPublicKeyPin != "") {
 		tlsConfig, err = NewTLSConfig(auth, unsafeSsl)
 		if err != nil || tlsConfig == nil {
 			return nil, fmt.Errorf("error creating the TLS config: %w", err)
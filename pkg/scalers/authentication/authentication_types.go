@@ -41,6 +41,8 @@ type AuthMeta struct {
 	Cert      string
 	Key       string
 	CA        string
+	// ServerPublicKeyPin replaces the verification of the certificate chain of the server when it's set
+	ServerPublicKeyPin string
 
 	// custom auth header
 	EnableCustomAuth bool
@@ -221,7 +221,7 @@ func parseAwsCloudwatchMetadata(config *ScalerConfig) (*awsCloudwatchMetadata, e
 		meta.awsEndpoint = val
 	}
 
-	awsAuthorization, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+	awsAuthorization, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv, config.PodIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -302,6 +302,14 @@ func (s *awsCloudwatchScaler) GetMetricSpecForScaling(context.Context) []v2.Metr
 	return []v2.MetricSpec{metricSpec}
 }
 
+// GetMetricUnit returns the metricUnit of the trigger in the Prometheus naming, e.g. Bytes/Second is bytes_per_second
+func (s *awsCloudwatchScaler) GetMetricUnit() string {
+	if s.metadata.metricUnit == cloudwatch.StandardUnitNone {
+		return ""
+	}
+	return strings.ToLower(strings.ReplaceAll(s.metadata.metricUnit, "/", "_per_"))
+}
+
 func (s *awsCloudwatchScaler) Close(context.Context) error {
 	return nil
 }
@@ -555,3 +555,16 @@ func TestComputeQueryWindow(t *testing.T) {
 		assert.Equal(t, testData.expectedEndTime, endTime.UTC().Format(time.RFC3339Nano), "unexpected endTime", "name", testData.name)
 	}
 }
+
+func TestAWSCloudwatchGetMetricUnit(t *testing.T) {
+	units := map[string]string{
+		"":             "",
+		"None":         "",
+		"Seconds":      "seconds",
+		"Bytes/Second": "bytes_per_second",
+	}
+	for metricUnit, expected := range units {
+		scaler := awsCloudwatchScaler{metadata: &awsCloudwatchMetadata{metricUnit: metricUnit}}
+		assert.Equal(t, expected, GetMetricUnit(&scaler), "metricUnit %q", metricUnit)
+	}
+}
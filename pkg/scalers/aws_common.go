@@ -3,11 +3,28 @@ package scalers
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/endpointcreds"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	awsendpoints "github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+const (
+	// the environment variables set by the EKS Pod Identity webhook on the containers of the pods
+	awsContainerCredentialsFullURIEnv     = "AWS_CONTAINER_CREDENTIALS_FULL_URI"
+	awsContainerAuthorizationTokenFileEnv = "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"
+	awsContainerAuthorizationTokenEnv     = "AWS_CONTAINER_AUTHORIZATION_TOKEN"
+
+	awsContainerCredentialsExpiryWindow = 5 * time.Minute
 )
 
 // ErrAwsNoAccessKey is returned when awsAccessKeyID is missing.
@@ -20,6 +37,15 @@ type awsAuthorizationMetadata struct {
 	awsSecretAccessKey string
 	awsSessionToken    string
 
+	// awsEndpointURL replaces the STS endpoint used to assume awsRoleArn, e.g. with a PrivateLink endpoint
+	awsEndpointURL string
+	// stsRegionalEndpoints selects the regional STS endpoint of the partition of the region instead of the global one
+	stsRegionalEndpoints awsendpoints.STSRegionalEndpoint
+
+	// the container credentials endpoint of EKS Pod Identity, empty if it isn't used
+	containerCredentialsURI         string
+	containerAuthorizationTokenFile string
+
 	podIdentityOwner bool
 }
 
@@ -36,8 +62,9 @@ func getAwsConfig(awsRegion string, awsEndpoint string, awsAuthorization awsAuth
 		awsAuthorization: awsAuthorization}
 
 	sess := session.Must(session.NewSession(&aws.Config{
-		Region:   aws.String(metadata.awsRegion),
-		Endpoint: aws.String(metadata.awsEndpoint),
+		Region:              aws.String(metadata.awsRegion),
+		Endpoint:            aws.String(metadata.awsEndpoint),
+		STSRegionalEndpoint: metadata.awsAuthorization.stsRegionalEndpoints,
 	}))
 
 	if !metadata.awsAuthorization.podIdentityOwner {
@@ -48,9 +75,20 @@ func getAwsConfig(awsRegion string, awsEndpoint string, awsAuthorization awsAuth
 	}
 
 	creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
+	// the role is assumed with the credentials of the session, the ones of KEDA unless EKS Pod Identity gives others
+	roleSess := sess
+
+	if metadata.awsAuthorization.containerCredentialsURI != "" {
+		creds = credentials.NewCredentials(&awsContainerCredentialsProvider{
+			sess:      sess,
+			uri:       metadata.awsAuthorization.containerCredentialsURI,
+			tokenFile: metadata.awsAuthorization.containerAuthorizationTokenFile,
+		})
+		roleSess = sess.Copy(&aws.Config{Credentials: creds})
+	}
 
 	if metadata.awsAuthorization.awsRoleArn != "" {
-		creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
+		creds = stscreds.NewCredentialsWithClient(getAwsStsClient(roleSess, metadata.awsAuthorization), metadata.awsAuthorization.awsRoleArn)
 	}
 
 	return sess, &aws.Config{
@@ -60,9 +98,40 @@ func getAwsConfig(awsRegion string, awsEndpoint string, awsAuthorization awsAuth
 	}
 }
 
-func getAwsAuthorization(authParams, metadata, resolvedEnv map[string]string) (awsAuthorizationMetadata, error) {
+// getAwsStsClient returns the STS client used to assume roles, on awsEndpointURL if it is given
+// or else on the STS endpoint resolved for the partition of the region
+func getAwsStsClient(sess *session.Session, awsAuthorization awsAuthorizationMetadata) *sts.STS {
+	config := &aws.Config{STSRegionalEndpoint: awsAuthorization.stsRegionalEndpoints}
+	if awsAuthorization.awsEndpointURL != "" {
+		config.Endpoint = aws.String(awsAuthorization.awsEndpointURL)
+	}
+	return sts.New(sess, config)
+}
+
+func getAwsAuthorization(authParams, metadata, resolvedEnv map[string]string, podIdentity kedav1alpha1.AuthPodIdentity) (awsAuthorizationMetadata, error) {
 	meta := awsAuthorizationMetadata{}
 
+	meta.awsEndpointURL = getAwsAuthorizationParameter(authParams, metadata, "awsEndpointURL")
+	if val := getAwsAuthorizationParameter(authParams, metadata, "stsRegionalEndpoints"); val != "" {
+		stsRegionalEndpoints, err := awsendpoints.GetSTSRegionalEndpoint(val)
+		if err != nil {
+			return meta, fmt.Errorf("stsRegionalEndpoints must be either legacy or regional, %s given", val)
+		}
+		meta.stsRegionalEndpoints = stsRegionalEndpoints
+	}
+
+	if podIdentity.Provider == kedav1alpha1.PodIdentityProviderAwsEKSPodIdentity {
+		meta.podIdentityOwner = true
+		meta.containerCredentialsURI = os.Getenv(awsContainerCredentialsFullURIEnv)
+		if meta.containerCredentialsURI == "" {
+			return meta, fmt.Errorf("%s isn't set, EKS Pod Identity isn't available to KEDA", awsContainerCredentialsFullURIEnv)
+		}
+		meta.containerAuthorizationTokenFile = os.Getenv(awsContainerAuthorizationTokenFileEnv)
+		// the role of the pod identity is optional, the credentials of EKS Pod Identity are used directly without it
+		meta.awsRoleArn = podIdentity.IdentityID
+		return meta, nil
+	}
+
 	if metadata["identityOwner"] == "operator" {
 		meta.podIdentityOwner = false
 	} else if metadata["identityOwner"] == "" || metadata["identityOwner"] == "pod" {
@@ -100,3 +169,52 @@ func getAwsAuthorization(authParams, metadata, resolvedEnv map[string]string) (a
 
 	return meta, nil
 }
+
+// getAwsAuthorizationParameter returns the parameter from the authentication parameters, or else from the trigger metadata
+func getAwsAuthorizationParameter(authParams, metadata map[string]string, name string) string {
+	if val := authParams[name]; val != "" {
+		return val
+	}
+	return metadata[name]
+}
+
+// awsContainerCredentialsProvider gets the credentials from the container credentials endpoint of EKS Pod Identity,
+// the authorization token file is read on each retrieval as the token is rotated
+type awsContainerCredentialsProvider struct {
+	sess      *session.Session
+	uri       string
+	tokenFile string
+
+	// provider is the one of the last retrieval, it knows when its credentials expire
+	provider *endpointcreds.Provider
+}
+
+func (p *awsContainerCredentialsProvider) Retrieve() (credentials.Value, error) {
+	return p.RetrieveWithContext(aws.BackgroundContext())
+}
+
+func (p *awsContainerCredentialsProvider) RetrieveWithContext(ctx credentials.Context) (credentials.Value, error) {
+	token := os.Getenv(awsContainerAuthorizationTokenEnv)
+	if p.tokenFile != "" {
+		content, err := os.ReadFile(p.tokenFile)
+		if err != nil {
+			return credentials.Value{}, fmt.Errorf("error reading the container authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+
+	provider := endpointcreds.NewProviderClient(*p.sess.Config, p.sess.Handlers, p.uri, func(provider *endpointcreds.Provider) {
+		provider.AuthorizationToken = token
+		provider.ExpiryWindow = awsContainerCredentialsExpiryWindow
+	}).(*endpointcreds.Provider)
+	value, err := provider.RetrieveWithContext(ctx)
+	if err != nil {
+		return credentials.Value{}, err
+	}
+	p.provider = provider
+	return value, nil
+}
+
+func (p *awsContainerCredentialsProvider) IsExpired() bool {
+	return p.provider == nil || p.provider.IsExpired()
+}
@@ -0,0 +1,164 @@
+package scalers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	awsendpoints "github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/stretchr/testify/assert"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type awsStsEndpointTestData struct {
+	name             string
+	region           string
+	authParams       map[string]string
+	metadata         map[string]string
+	expectedEndpoint string
+	isError          bool
+}
+
+var testAwsStsEndpoints = []awsStsEndpointTestData{
+	{
+		name:             "global endpoint by default",
+		region:           "eu-west-1",
+		expectedEndpoint: "https://sts.amazonaws.com",
+	},
+	{
+		name:             "regional endpoint",
+		region:           "eu-west-1",
+		metadata:         map[string]string{"stsRegionalEndpoints": "regional"},
+		expectedEndpoint: "https://sts.eu-west-1.amazonaws.com",
+	},
+	{
+		name:             "legacy endpoint",
+		region:           "eu-west-1",
+		authParams:       map[string]string{"stsRegionalEndpoints": "legacy"},
+		expectedEndpoint: "https://sts.amazonaws.com",
+	},
+	{
+		name:             "china partition",
+		region:           "cn-north-1",
+		metadata:         map[string]string{"stsRegionalEndpoints": "regional"},
+		expectedEndpoint: "https://sts.cn-north-1.amazonaws.com.cn",
+	},
+	{
+		name:             "govcloud partition",
+		region:           "us-gov-west-1",
+		metadata:         map[string]string{"stsRegionalEndpoints": "regional"},
+		expectedEndpoint: "https://sts.us-gov-west-1.amazonaws.com",
+	},
+	{
+		name:             "privatelink endpoint from authParams wins over metadata",
+		region:           "eu-west-1",
+		authParams:       map[string]string{"awsEndpointURL": "https://vpce-0123-abcd.sts.eu-west-1.vpce.amazonaws.com"},
+		metadata:         map[string]string{"awsEndpointURL": "https://sts.example.com", "stsRegionalEndpoints": "regional"},
+		expectedEndpoint: "https://vpce-0123-abcd.sts.eu-west-1.vpce.amazonaws.com",
+	},
+	{
+		name:     "invalid stsRegionalEndpoints",
+		region:   "eu-west-1",
+		metadata: map[string]string{"stsRegionalEndpoints": "local"},
+		isError:  true,
+	},
+}
+
+func TestAwsStsEndpointResolution(t *testing.T) {
+	// a CA bundle makes the sessions set their own transport on http.DefaultClient, shared with other tests
+	t.Setenv("AWS_CA_BUNDLE", "")
+	for _, testData := range testAwsStsEndpoints {
+		t.Run(testData.name, func(t *testing.T) {
+			authParams := map[string]string{"awsRoleArn": "arn:aws:iam::123456789012:role/keda"}
+			for key, value := range testData.authParams {
+				authParams[key] = value
+			}
+			auth, err := getAwsAuthorization(authParams, testData.metadata, nil, kedav1alpha1.AuthPodIdentity{})
+			if testData.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			sess, _ := getAwsConfig(testData.region, "", auth)
+			assert.Equal(t, testData.expectedEndpoint, getAwsStsClient(sess, auth).Endpoint)
+		})
+	}
+}
+
+func TestAwsEKSPodIdentityContainerCredentials(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "eks-pod-identity-token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("first-token\n"), 0o600))
+
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		authorizations = append(authorizations, authorization)
+		if authorization == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"AccessKeyId":     "ASIA" + authorization,
+			"SecretAccessKey": "secret",
+			"Token":           "session-token",
+			"Expiration":      time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv(awsContainerCredentialsFullURIEnv, server.URL+"/v1/credentials")
+	t.Setenv(awsContainerAuthorizationTokenFileEnv, tokenFile)
+
+	auth, err := getAwsAuthorization(map[string]string{}, map[string]string{}, nil, kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAwsEKSPodIdentity})
+	assert.NoError(t, err)
+	assert.Equal(t, "", auth.awsRoleArn)
+
+	_, config := getAwsConfig("eu-west-1", "https://sqs.eu-west-1.amazonaws.com", auth)
+	value, err := config.Credentials.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "ASIAfirst-token", value.AccessKeyID)
+	assert.Equal(t, "secret", value.SecretAccessKey)
+	assert.Equal(t, "session-token", value.SessionToken)
+
+	// the credentials are cached until they expire
+	_, err = config.Credentials.Get()
+	assert.NoError(t, err)
+	assert.Len(t, authorizations, 1)
+
+	// the rotated token is used by the next retrieval
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("second-token"), 0o600))
+	config.Credentials.Expire()
+	value, err = config.Credentials.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "ASIAsecond-token", value.AccessKeyID)
+	assert.Equal(t, []string{"first-token", "second-token"}, authorizations)
+}
+
+func TestAwsEKSPodIdentityRole(t *testing.T) {
+	t.Setenv(awsContainerCredentialsFullURIEnv, "http://169.254.170.23/v1/credentials")
+	t.Setenv(awsContainerAuthorizationTokenFileEnv, "")
+
+	auth, err := getAwsAuthorization(map[string]string{"stsRegionalEndpoints": "regional"}, map[string]string{}, nil, kedav1alpha1.AuthPodIdentity{
+		Provider:   kedav1alpha1.PodIdentityProviderAwsEKSPodIdentity,
+		IdentityID: "arn:aws-cn:iam::123456789012:role/keda",
+	})
+	assert.NoError(t, err)
+	assert.True(t, auth.podIdentityOwner)
+	assert.Equal(t, "arn:aws-cn:iam::123456789012:role/keda", auth.awsRoleArn)
+	assert.Equal(t, "http://169.254.170.23/v1/credentials", auth.containerCredentialsURI)
+	assert.Equal(t, awsendpoints.RegionalSTSEndpoint, auth.stsRegionalEndpoints)
+}
+
+func TestAwsEKSPodIdentityUnavailable(t *testing.T) {
+	t.Setenv(awsContainerCredentialsFullURIEnv, "")
+
+	_, err := getAwsAuthorization(map[string]string{}, map[string]string{}, nil, kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAwsEKSPodIdentity})
+	assert.Error(t, err)
+}
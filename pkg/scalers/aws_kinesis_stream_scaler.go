@@ -24,6 +24,8 @@ type awsKinesisStreamScaler struct {
 	metadata      *awsKinesisStreamMetadata
 	kinesisClient kinesisiface.KinesisAPI
 	logger        logr.Logger
+
+	observedPartitions
 }
 
 type awsKinesisStreamMetadata struct {
@@ -98,7 +100,7 @@ func parseAwsKinesisStreamMetadata(config *ScalerConfig, logger logr.Logger) (*a
 		meta.awsEndpoint = val
 	}
 
-	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv, config.PodIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -141,6 +143,7 @@ func (s *awsKinesisStreamScaler) GetMetricsAndActivity(_ context.Context, metric
 		s.logger.Error(err, "Error getting shard count")
 		return []external_metrics.ExternalMetricValue{}, false, err
 	}
+	s.setPartitionCount(int(shardCount))
 
 	metric := GenerateMetricInMili(metricName, float64(shardCount))
 
@@ -338,7 +338,7 @@ func TestAWSKinesisGetMetricSpecForScaling(t *testing.T) {
 		if err != nil {
 			t.Fatal("Could not parse metadata:", err)
 		}
-		mockAWSKinesisStreamScaler := awsKinesisStreamScaler{"", meta, &mockKinesis{}, logr.Discard()}
+		mockAWSKinesisStreamScaler := awsKinesisStreamScaler{metadata: meta, kinesisClient: &mockKinesis{}, logger: logr.Discard()}
 
 		metricSpec := mockAWSKinesisStreamScaler.GetMetricSpecForScaling(ctx)
 		metricName := metricSpec[0].External.Metric.Name
@@ -350,13 +350,16 @@ func TestAWSKinesisGetMetricSpecForScaling(t *testing.T) {
 
 func TestAWSKinesisStreamScalerGetMetrics(t *testing.T) {
 	for _, meta := range awsKinesisGetMetricTestData {
-		scaler := awsKinesisStreamScaler{"", meta, &mockKinesis{}, logr.Discard()}
+		scaler := awsKinesisStreamScaler{metadata: meta, kinesisClient: &mockKinesis{}, logger: logr.Discard()}
 		value, _, err := scaler.GetMetricsAndActivity(context.Background(), "MetricName")
 		switch meta.streamName {
 		case testAWSKinesisErrorStream:
 			assert.Error(t, err, "expect error because of kinesis api error")
 		default:
 			assert.EqualValues(t, int64(100.0), value[0].Value.Value())
+			count, observed := scaler.GetPartitionCount()
+			assert.True(t, observed)
+			assert.Equal(t, 100, count)
 		}
 	}
 }
@@ -151,7 +151,7 @@ func parseAwsSqsQueueMetadata(config *ScalerConfig, logger logr.Logger) (*awsSqs
 		meta.awsEndpoint = val
 	}
 
-	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv, config.PodIdentity)
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +175,11 @@ func (s *awsSqsQueueScaler) Close(context.Context) error {
 	return nil
 }
 
+// ClampNegativeMetrics returns true, the number of messages in the queue is never negative
+func (s *awsSqsQueueScaler) ClampNegativeMetrics() bool {
+	return true
+}
+
 func (s *awsSqsQueueScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
 	externalMetric := &v2.ExternalMetricSource{
 		Metric: v2.MetricIdentifier{
@@ -36,13 +36,14 @@ type BlobMetadata struct {
 	AccountName               string
 	MetricName                string
 	EndpointSuffix            string
+	Endpoint                  string
 	ScalerIndex               int
 	GlobPattern               *glob.Glob
 }
 
 // GetAzureBlobListLength returns the count of the blobs in blob container in int
 func GetAzureBlobListLength(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.AuthPodIdentity, meta *BlobMetadata) (int64, error) {
-	credential, endpoint, err := ParseAzureStorageBlobConnection(ctx, httpClient, podIdentity, meta.Connection, meta.AccountName, meta.EndpointSuffix)
+	credential, endpoint, err := ParseAzureStorageBlobConnection(ctx, httpClient, podIdentity, meta.Connection, meta.AccountName, meta.EndpointSuffix, meta.Endpoint)
 	if err != nil {
 		return -1, err
 	}
@@ -0,0 +1,295 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+const (
+	cosmosDBAPIVersion = "2018-12-31"
+
+	// cosmosDBMaxThrottlingRetries is the number of times a throttled request is retried, the same default as the Cosmos DB SDKs
+	cosmosDBMaxThrottlingRetries = 9
+	// cosmosDBDefaultRetryAfter is used when a throttled response doesn't tell when to retry
+	cosmosDBDefaultRetryAfter = time.Second
+)
+
+// CosmosDBLease is a lease document of a change feed processor
+type CosmosDBLease struct {
+	ID                string `json:"id"`
+	LeaseToken        string `json:"LeaseToken"`
+	ContinuationToken string `json:"ContinuationToken"`
+	Owner             string `json:"Owner"`
+}
+
+// CosmosDBChangeFeedState describes the changes of a partition key range after a continuation token
+type CosmosDBChangeFeedState struct {
+	// HasChanges is false if there is no change after the continuation token
+	HasChanges bool
+	// FirstLSN is the logical sequence number of the first change after the continuation token
+	FirstLSN int64
+	// CurrentLSN is the latest logical sequence number of the partition key range
+	CurrentLSN int64
+}
+
+// CosmosDBClient queries the Cosmos DB REST API, authenticated with the account key or an Azure AD identity
+type CosmosDBClient struct {
+	httpClient *http.Client
+	endpoint   string
+	accountKey []byte
+	credential azcore.TokenCredential
+	scope      string
+}
+
+// NewCosmosDBClient creates a client of the Cosmos DB account, the account key is used when there is no pod identity
+func NewCosmosDBClient(httpClient *http.Client, endpoint, accountKey string, podIdentity kedav1alpha1.AuthPodIdentity) (*CosmosDBClient, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cosmos db endpoint %s: %w", endpoint, err)
+	}
+	client := &CosmosDBClient{
+		httpClient: httpClient,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+	}
+
+	switch podIdentity.Provider {
+	case "", kedav1alpha1.PodIdentityProviderNone:
+		key, err := base64.StdEncoding.DecodeString(accountKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cosmos db account key: %w", err)
+		}
+		client.accountKey = key
+	case kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload:
+		credential, err := NewChainedCredential(podIdentity.IdentityID, podIdentity.Provider)
+		if err != nil {
+			return nil, err
+		}
+		client.credential = credential
+		client.scope = fmt.Sprintf("%s://%s/.default", endpointURL.Scheme, endpointURL.Hostname())
+	default:
+		return nil, fmt.Errorf("pod identity %s not supported for azure cosmos db", podIdentity.Provider)
+	}
+	return client, nil
+}
+
+// GetLeases returns the lease documents of the change feed processor from the lease container
+func (c *CosmosDBClient) GetLeases(ctx context.Context, databaseID, containerID, processorName string) ([]CosmosDBLease, error) {
+	query, err := json.Marshal(map[string]interface{}{
+		"query": "SELECT * FROM c WHERE STARTSWITH(c.id, @prefix)",
+		"parameters": []map[string]string{
+			{"name": "@prefix", "value": processorName},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var leases []CosmosDBLease
+	continuation := ""
+	for {
+		headers := map[string]string{
+			"Content-Type":                               "application/query+json",
+			"x-ms-documentdb-isquery":                    "True",
+			"x-ms-documentdb-query-enablecrosspartition": "True",
+		}
+		if continuation != "" {
+			headers["x-ms-continuation"] = continuation
+		}
+		resp, body, err := c.do(ctx, http.MethodPost, databaseID, containerID, headers, query)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error querying the leases of %s/%s: %s", databaseID, containerID, getCosmosDBErrorMessage(resp, body))
+		}
+
+		var page struct {
+			Documents []CosmosDBLease `json:"Documents"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("error parsing the leases of %s/%s: %w", databaseID, containerID, err)
+		}
+		// the processor stores the .info and .lock documents with the same prefix, they aren't leases
+		for _, lease := range page.Documents {
+			if lease.LeaseToken != "" {
+				leases = append(leases, lease)
+			}
+		}
+
+		continuation = resp.Header.Get("x-ms-continuation")
+		if continuation == "" {
+			return leases, nil
+		}
+	}
+}
+
+// ReadChangeFeed reads the first change of the partition key range after the continuation token
+func (c *CosmosDBClient) ReadChangeFeed(ctx context.Context, databaseID, containerID, partitionKeyRangeID, continuationToken string) (CosmosDBChangeFeedState, error) {
+	headers := map[string]string{
+		"A-IM":                                "Incremental feed",
+		"x-ms-documentdb-partitionkeyrangeid": partitionKeyRangeID,
+		"x-ms-max-item-count":                 "1",
+	}
+	if continuationToken != "" {
+		headers["If-None-Match"] = continuationToken
+	}
+	resp, body, err := c.do(ctx, http.MethodGet, databaseID, containerID, headers, nil)
+	if err != nil {
+		return CosmosDBChangeFeedState{}, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return CosmosDBChangeFeedState{}, nil
+	case http.StatusOK:
+	default:
+		return CosmosDBChangeFeedState{}, fmt.Errorf("error reading the change feed of partition key range %s: %s", partitionKeyRangeID, getCosmosDBErrorMessage(resp, body))
+	}
+
+	var page struct {
+		Documents []struct {
+			LSN int64 `json:"_lsn"`
+		} `json:"Documents"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return CosmosDBChangeFeedState{}, fmt.Errorf("error parsing the change feed of partition key range %s: %w", partitionKeyRangeID, err)
+	}
+	if len(page.Documents) == 0 {
+		return CosmosDBChangeFeedState{}, nil
+	}
+
+	currentLSN, err := parseCosmosDBSessionToken(resp.Header.Get("x-ms-session-token"))
+	if err != nil {
+		return CosmosDBChangeFeedState{}, err
+	}
+	return CosmosDBChangeFeedState{
+		HasChanges: true,
+		FirstLSN:   page.Documents[0].LSN,
+		CurrentLSN: currentLSN,
+	}, nil
+}
+
+// do sends a request on the documents of the container, throttled requests are retried after the delay given by Cosmos DB
+func (c *CosmosDBClient) do(ctx context.Context, method, databaseID, containerID string, headers map[string]string, body []byte) (*http.Response, []byte, error) {
+	resourceLink := fmt.Sprintf("dbs/%s/colls/%s", databaseID, containerID)
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s/docs", c.endpoint, resourceLink), bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, err
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		date := time.Now().UTC().Format(http.TimeFormat)
+		req.Header.Set("x-ms-date", date)
+		req.Header.Set("x-ms-version", cosmosDBAPIVersion)
+		authorization, err := c.getAuthorization(ctx, method, resourceLink, date)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Authorization", authorization)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= cosmosDBMaxThrottlingRetries {
+			return resp, respBody, nil
+		}
+
+		retryAfter := cosmosDBDefaultRetryAfter
+		if milliseconds, err := strconv.Atoi(resp.Header.Get("x-ms-retry-after-ms")); err == nil {
+			retryAfter = time.Duration(milliseconds) * time.Millisecond
+		}
+		select {
+		case <-time.After(retryAfter):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// getAuthorization returns the authorization header of the request, signed with the account key or with an Azure AD token
+func (c *CosmosDBClient) getAuthorization(ctx context.Context, method, resourceLink, date string) (string, error) {
+	if c.credential != nil {
+		token, err := c.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{c.scope}})
+		if err != nil {
+			return "", err
+		}
+		return url.QueryEscape("type=aad&ver=1.0&sig=" + token.Token), nil
+	}
+
+	payload := fmt.Sprintf("%s\ndocs\n%s\n%s\n\n", strings.ToLower(method), resourceLink, strings.ToLower(date))
+	mac := hmac.New(sha256.New, c.accountKey)
+	mac.Write([]byte(payload))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return url.QueryEscape("type=master&ver=1.0&sig=" + signature), nil
+}
+
+// parseCosmosDBSessionToken returns the logical sequence number of a session token,
+// which is either <partitionKeyRangeId>:<lsn> or <partitionKeyRangeId>:<version>#<lsn>#<regional lsns>
+func parseCosmosDBSessionToken(sessionToken string) (int64, error) {
+	_, token, found := strings.Cut(sessionToken, ":")
+	if !found {
+		return 0, fmt.Errorf("invalid session token %q", sessionToken)
+	}
+	segments := strings.Split(token, "#")
+	lsn := segments[0]
+	if len(segments) > 1 {
+		lsn = segments[1]
+	}
+	value, err := strconv.ParseInt(lsn, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid session token %q: %w", sessionToken, err)
+	}
+	return value, nil
+}
+
+func getCosmosDBErrorMessage(resp *http.Response, body []byte) string {
+	var cosmosErr struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &cosmosErr); err == nil && cosmosErr.Message != "" {
+		return fmt.Sprintf("%d %s: %s", resp.StatusCode, cosmosErr.Code, cosmosErr.Message)
+	}
+	return fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+}
@@ -0,0 +1,152 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type parseCosmosDBSessionTokenTestData struct {
+	sessionToken string
+	lsn          int64
+	isError      bool
+}
+
+var parseCosmosDBSessionTokenTestDataset = []parseCosmosDBSessionTokenTestData{
+	{sessionToken: "0:1234", lsn: 1234},
+	{sessionToken: "3:-1#567#1=560#2=567", lsn: 567},
+	{sessionToken: "1234", isError: true},
+	{sessionToken: "0:abc", isError: true},
+	{sessionToken: "", isError: true},
+}
+
+func TestParseCosmosDBSessionToken(t *testing.T) {
+	for _, testData := range parseCosmosDBSessionTokenTestDataset {
+		lsn, err := parseCosmosDBSessionToken(testData.sessionToken)
+		if testData.isError {
+			if err == nil {
+				t.Errorf("%q: expected error but got success", testData.sessionToken)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: expected success but got error %s", testData.sessionToken, err)
+		} else if lsn != testData.lsn {
+			t.Errorf("%q: expected %d but got %d", testData.sessionToken, testData.lsn, lsn)
+		}
+	}
+}
+
+func newTestCosmosDBClient(t *testing.T, handler http.HandlerFunc) *CosmosDBClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewCosmosDBClient(server.Client(), server.URL, "a2V5", kedav1alpha1.AuthPodIdentity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCosmosDBGetLeases(t *testing.T) {
+	pages := 0
+	client := newTestCosmosDBClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dbs/orders/colls/leases/docs" || r.Header.Get("x-ms-documentdb-isquery") != "True" || r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		pages++
+		if r.Header.Get("x-ms-continuation") == "" {
+			w.Header().Set("x-ms-continuation", "page-2")
+			_, _ = w.Write([]byte(`{"Documents": [{"id": "processor.info"}, {"id": "processor..0", "LeaseToken": "0", "ContinuationToken": "\"12\""}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Documents": [{"id": "processor..1", "LeaseToken": "1", "ContinuationToken": "\"30\"", "Owner": "host"}]}`))
+	})
+
+	leases, err := client.GetLeases(context.Background(), "orders", "leases", "processor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 2 {
+		t.Errorf("expected 2 pages but got %d", pages)
+	}
+	if len(leases) != 2 || leases[0].LeaseToken != "0" || leases[1].ContinuationToken != `"30"` {
+		t.Errorf("unexpected leases %v", leases)
+	}
+}
+
+func TestCosmosDBReadChangeFeed(t *testing.T) {
+	client := newTestCosmosDBClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("A-IM") != "Incremental feed" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.Header.Get("x-ms-documentdb-partitionkeyrangeid") {
+		case "0":
+			w.Header().Set("x-ms-session-token", "0:-1#42")
+			_, _ = w.Write([]byte(`{"Documents": [{"id": "order", "_lsn": 13}]}`))
+		case "1":
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code": "NotFound", "message": "partition key range not found"}`))
+		}
+	})
+
+	state, err := client.ReadChangeFeed(context.Background(), "orders", "events", "0", `"12"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != (CosmosDBChangeFeedState{HasChanges: true, FirstLSN: 13, CurrentLSN: 42}) {
+		t.Errorf("unexpected change feed state %v", state)
+	}
+
+	state, err = client.ReadChangeFeed(context.Background(), "orders", "events", "1", `"30"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.HasChanges {
+		t.Error("expected no changes for a not modified change feed")
+	}
+
+	if _, err := client.ReadChangeFeed(context.Background(), "orders", "events", "2", ""); err == nil {
+		t.Error("expected error for a missing partition key range")
+	}
+}
+
+func TestCosmosDBThrottlingRetry(t *testing.T) {
+	attempts := 0
+	client := newTestCosmosDBClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("x-ms-retry-after-ms", "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNotModified)
+	})
+
+	if _, err := client.ReadChangeFeed(context.Background(), "orders", "events", "0", `"12"`); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts but got %d", attempts)
+	}
+}
+
+func TestCosmosDBThrottlingRetryCanceled(t *testing.T) {
+	client := newTestCosmosDBClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ms-retry-after-ms", "60000")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.ReadChangeFeed(ctx, "orders", "events", "0", `"12"`); err == nil {
+		t.Error("expected error when the context is done while throttled")
+	}
+}
@@ -257,7 +257,9 @@ func (checkpointer *defaultCheckpointer) extractCheckpoint(get *azblob.DownloadR
 	return checkpoint, err
 }
 
-func getCheckpoint(ctx context.Context, httpClient util.HTTPDoer, info EventHubInfo, checkpointer checkpointer) (Checkpoint, error) {
+// getCheckpointStorageIdentity returns the pod identity the checkpoints are read with, which can differ from the
+// one of the event hub client when the checkpoint storage has its own connection string
+func getCheckpointStorageIdentity(info EventHubInfo) (kedav1alpha1.AuthPodIdentity, error) {
 	var podIdentity = info.PodIdentity
 
 	// For back-compat, prefer a connection string over pod identity when present
@@ -265,14 +267,33 @@ func getCheckpoint(ctx context.Context, httpClient util.HTTPDoer, info EventHubI
 		podIdentity.Provider = kedav1alpha1.PodIdentityProviderNone
 	}
 
-	if podIdentity.Provider == kedav1alpha1.PodIdentityProviderAzure || podIdentity.Provider == kedav1alpha1.PodIdentityProviderAzureWorkload {
+	switch podIdentity.Provider {
+	case kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload:
 		if len(info.StorageAccountName) == 0 {
-			return Checkpoint{}, fmt.Errorf("storageAccountName not supplied when PodIdentity authentication is enabled")
+			return podIdentity, fmt.Errorf("storageAccountName not supplied when PodIdentity authentication is enabled")
+		}
+		if len(info.BlobStorageEndpoint) == 0 {
+			return podIdentity, fmt.Errorf("no blob storage endpoint for storage account %s", info.StorageAccountName)
+		}
+	case "", kedav1alpha1.PodIdentityProviderNone:
+		if len(info.StorageConnection) == 0 {
+			return podIdentity, fmt.Errorf("no storage connection string given")
 		}
+	default:
+		return podIdentity, fmt.Errorf("checkpoint storage does not support pod identity %v", podIdentity.Provider)
+	}
+
+	return podIdentity, nil
+}
+
+func getCheckpoint(ctx context.Context, httpClient util.HTTPDoer, info EventHubInfo, checkpointer checkpointer) (Checkpoint, error) {
+	podIdentity, err := getCheckpointStorageIdentity(info)
+	if err != nil {
+		return Checkpoint{}, err
 	}
 
 	blobCreds, storageEndpoint, err := ParseAzureStorageBlobConnection(ctx, httpClient,
-		podIdentity, info.StorageConnection, info.StorageAccountName, info.BlobStorageEndpoint)
+		podIdentity, info.StorageConnection, info.StorageAccountName, info.BlobStorageEndpoint, "")
 
 	if err != nil {
 		return Checkpoint{}, err
@@ -445,7 +445,7 @@ func createNewCheckpointInStorage(urlPath string, containerName string, partitio
 	ctx := context.Background()
 
 	credential, endpoint, _ := ParseAzureStorageBlobConnection(ctx, http.DefaultClient,
-		kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderNone}, StorageConnectionString, "", "")
+		kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderNone}, StorageConnectionString, "", "", "")
 
 	// Create container
 	path, _ := url.Parse(containerName)
@@ -477,3 +477,110 @@ func createNewCheckpointInStorage(urlPath string, containerName string, partitio
 	}
 	return ctx, nil
 }
+
+func TestCheckpointStorageIdentity(t *testing.T) {
+	workloadIdentity := kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAzureWorkload, IdentityID: "client-id"}
+	tests := []struct {
+		name             string
+		info             EventHubInfo
+		expectedProvider kedav1alpha1.PodIdentityProvider
+		isError          bool
+	}{
+		{
+			name:             "connection strings",
+			info:             EventHubInfo{EventHubConnection: "connection", StorageConnection: "connection"},
+			expectedProvider: kedav1alpha1.PodIdentityProviderNone,
+		},
+		{
+			name:    "connection string without storage",
+			info:    EventHubInfo{EventHubConnection: "connection"},
+			isError: true,
+		},
+		{
+			name:             "workload identity for the hub and the storage",
+			info:             EventHubInfo{PodIdentity: workloadIdentity, StorageAccountName: "account", BlobStorageEndpoint: "blob.core.chinacloudapi.cn"},
+			expectedProvider: kedav1alpha1.PodIdentityProviderAzureWorkload,
+		},
+		{
+			name:             "pod identity for the hub and the storage",
+			info:             EventHubInfo{PodIdentity: kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAzure}, StorageAccountName: "account", BlobStorageEndpoint: "blob.core.windows.net"},
+			expectedProvider: kedav1alpha1.PodIdentityProviderAzure,
+		},
+		{
+			name:             "workload identity for the hub and a storage connection string",
+			info:             EventHubInfo{PodIdentity: workloadIdentity, StorageConnection: "connection"},
+			expectedProvider: kedav1alpha1.PodIdentityProviderNone,
+		},
+		{
+			name:             "storage connection string preferred over the storage account",
+			info:             EventHubInfo{PodIdentity: workloadIdentity, StorageConnection: "connection", StorageAccountName: "account", BlobStorageEndpoint: "blob.core.windows.net"},
+			expectedProvider: kedav1alpha1.PodIdentityProviderNone,
+		},
+		{
+			name:    "workload identity without storage account",
+			info:    EventHubInfo{PodIdentity: workloadIdentity},
+			isError: true,
+		},
+		{
+			name:    "workload identity without blob endpoint",
+			info:    EventHubInfo{PodIdentity: workloadIdentity, StorageAccountName: "account"},
+			isError: true,
+		},
+		{
+			name:    "unsupported pod identity",
+			info:    EventHubInfo{PodIdentity: kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAwsKiam}, StorageAccountName: "account"},
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			podIdentity, err := getCheckpointStorageIdentity(test.info)
+			if test.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedProvider, podIdentity.Provider)
+			assert.Equal(t, test.info.PodIdentity.IdentityID, podIdentity.IdentityID)
+		})
+	}
+}
+
+func TestGetEventHubClient(t *testing.T) {
+	identityInfo := EventHubInfo{
+		Namespace:                "eventhubnamespace",
+		EventHubName:             "hub-test",
+		ServiceBusEndpointSuffix: "servicebus.chinacloudapi.cn",
+		ActiveDirectoryEndpoint:  "https://login.chinacloudapi.cn/",
+		EventHubResourceURL:      DefaultEventhubResourceURL,
+	}
+	tests := []struct {
+		name        string
+		podIdentity kedav1alpha1.AuthPodIdentity
+		connection  string
+		isError     bool
+	}{
+		{name: "connection string", connection: "Endpoint=sb://eventhubnamespace.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=secretKey123;EntityPath=hub-test"},
+		{name: "invalid connection string", connection: "Endpoint=sb://eventhubnamespace.servicebus.windows.net/", isError: true},
+		// the aad-pod-identity provider fetches its token when it's created, so it can't be built without the identity endpoint
+		{name: "workload identity", podIdentity: kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAzureWorkload, IdentityID: "client-id"}},
+		{name: "unsupported pod identity", podIdentity: kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAwsKiam}, isError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := identityInfo
+			info.PodIdentity = test.podIdentity
+			info.EventHubConnection = test.connection
+
+			hub, err := GetEventHubClient(context.Background(), info)
+			if test.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, hub)
+		})
+	}
+}
@@ -53,6 +53,12 @@ const (
 const (
 	// Azure storage resource is "https://storage.azure.com/" in all cloud environments
 	storageResource = "https://storage.azure.com/"
+
+	// developmentStorageAccountName and developmentStorageAccountKey are the well-known credentials of the storage
+	// emulators, Azurite and the legacy Azure Storage Emulator, used with UseDevelopmentStorage=true
+	developmentStorageAccountName = "devstoreaccount1"
+	developmentStorageAccountKey  = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+	developmentStorageHost        = "http://127.0.0.1"
 )
 
 var (
@@ -73,13 +79,24 @@ func (e StorageEndpointType) Name() string {
 	return [...]string{"blob", "queue", "table", "file"}[e]
 }
 
+// developmentStoragePort returns the port of the StorageEndpointType on the storage emulators, 0 if they don't
+// provide it
+func (e StorageEndpointType) developmentStoragePort() int {
+	return [...]int{10000, 10001, 10002, 0}[e]
+}
+
 // GetEndpointSuffix returns the endpoint suffix for a StorageEndpointType based on the specified environment
 func (e StorageEndpointType) GetEndpointSuffix(environment az.Environment) string {
 	return fmt.Sprintf("%s.%s", e.Name(), environment.StorageEndpointSuffix)
 }
 
-// ParseAzureStorageEndpointSuffix parses cloud and endpointSuffix metadata and returns endpoint suffix
+// ParseAzureStorageEndpointSuffix parses cloud and endpointSuffix metadata and returns endpoint suffix, the
+// endpointSuffix is used as it is without cloud, like with the Private cloud
 func ParseAzureStorageEndpointSuffix(metadata map[string]string, endpointType StorageEndpointType) (string, error) {
+	if metadata["cloud"] == "" && metadata[DefaultEndpointSuffixKey] != "" {
+		return metadata[DefaultEndpointSuffixKey], nil
+	}
+
 	envSuffixProvider := func(env az.Environment) (string, error) {
 		return endpointType.GetEndpointSuffix(env), nil
 	}
@@ -87,11 +104,37 @@ func ParseAzureStorageEndpointSuffix(metadata map[string]string, endpointType St
 	return ParseEnvironmentProperty(metadata, DefaultEndpointSuffixKey, envSuffixProvider)
 }
 
+// ParseAzureStorageEndpoint parses the endpoint metadata, the full URL of the storage service like
+// http://azurite:10001/devstoreaccount1 overriding the one built from the account name and the endpoint suffix
+func ParseAzureStorageEndpoint(metadata map[string]string) (string, error) {
+	val, ok := metadata["endpoint"]
+	if !ok || val == "" {
+		return "", nil
+	}
+	endpoint, err := url.Parse(val)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %s: %w", val, err)
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return "", fmt.Errorf("endpoint must be an http or https URL, %s given", val)
+	}
+	return val, nil
+}
+
+// ValidateStorageEndpointForPodIdentity returns an error if the endpoint can't be used with a pod identity, the
+// access tokens are only sent over https, so the storage emulators served over http can't be used
+func ValidateStorageEndpointForPodIdentity(endpoint string) error {
+	if endpoint != "" && !strings.HasPrefix(endpoint, "https://") {
+		return fmt.Errorf("pod identity is not supported with the http endpoint %s, use a connection string instead", endpoint)
+	}
+	return nil
+}
+
 // ParseAzureStorageQueueConnection parses queue connection string and returns credential and resource url
-func ParseAzureStorageQueueConnection(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.AuthPodIdentity, connectionString, accountName, endpointSuffix string) (azqueue.Credential, *url.URL, error) {
+func ParseAzureStorageQueueConnection(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.AuthPodIdentity, connectionString, accountName, endpointSuffix, endpointOverride string) (azqueue.Credential, *url.URL, error) {
 	switch podIdentity.Provider {
 	case kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload:
-		token, endpoint, err := parseAccessTokenAndEndpoint(ctx, httpClient, accountName, endpointSuffix, podIdentity)
+		token, endpoint, err := parseAccessTokenAndEndpoint(ctx, httpClient, accountName, endpointSuffix, endpointOverride, podIdentity)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -99,10 +142,15 @@ func ParseAzureStorageQueueConnection(ctx context.Context, httpClient util.HTTPD
 		credential := azqueue.NewTokenCredential(token, nil)
 		return credential, endpoint, nil
 	case "", kedav1alpha1.PodIdentityProviderNone:
-		endpoint, accountName, accountKey, err := parseAzureStorageConnectionString(connectionString, QueueEndpoint)
+		endpoint, accountName, accountKey, err := parseAzureStorageConnectionString(connectionString, QueueEndpoint, endpointSuffix)
 		if err != nil {
 			return nil, nil, err
 		}
+		if endpointOverride != "" {
+			if endpoint, err = url.Parse(endpointOverride); err != nil {
+				return nil, nil, err
+			}
+		}
 
 		credential, err := azqueue.NewSharedKeyCredential(accountName, accountKey)
 		if err != nil {
@@ -116,10 +164,10 @@ func ParseAzureStorageQueueConnection(ctx context.Context, httpClient util.HTTPD
 }
 
 // ParseAzureStorageBlobConnection parses blob connection string and returns credential and resource url
-func ParseAzureStorageBlobConnection(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.AuthPodIdentity, connectionString, accountName, endpointSuffix string) (azblob.Credential, *url.URL, error) {
+func ParseAzureStorageBlobConnection(ctx context.Context, httpClient util.HTTPDoer, podIdentity kedav1alpha1.AuthPodIdentity, connectionString, accountName, endpointSuffix, endpointOverride string) (azblob.Credential, *url.URL, error) {
 	switch podIdentity.Provider {
 	case kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload:
-		token, endpoint, err := parseAccessTokenAndEndpoint(ctx, httpClient, accountName, endpointSuffix, podIdentity)
+		token, endpoint, err := parseAccessTokenAndEndpoint(ctx, httpClient, accountName, endpointSuffix, endpointOverride, podIdentity)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -127,10 +175,15 @@ func ParseAzureStorageBlobConnection(ctx context.Context, httpClient util.HTTPDo
 		credential := azblob.NewTokenCredential(token, nil)
 		return credential, endpoint, nil
 	case "", kedav1alpha1.PodIdentityProviderNone:
-		endpoint, accountName, accountKey, err := parseAzureStorageConnectionString(connectionString, BlobEndpoint)
+		endpoint, accountName, accountKey, err := parseAzureStorageConnectionString(connectionString, BlobEndpoint, endpointSuffix)
 		if err != nil {
 			return nil, nil, err
 		}
+		if endpointOverride != "" {
+			if endpoint, err = url.Parse(endpointOverride); err != nil {
+				return nil, nil, err
+			}
+		}
 
 		credential, err := azblob.NewSharedKeyCredential(accountName, accountKey)
 		if err != nil {
@@ -143,7 +196,11 @@ func ParseAzureStorageBlobConnection(ctx context.Context, httpClient util.HTTPDo
 	}
 }
 
-func parseAzureStorageConnectionString(connectionString string, endpointType StorageEndpointType) (*url.URL, string, string, error) {
+// parseAzureStorageConnectionString returns the endpoint, the account name and the account key of the connection
+// string. The endpoint is the one of the endpoint type if given, else the one of the storage emulators with
+// UseDevelopmentStorage=true, else it's built from the endpoint suffix of the connection string or from the
+// defaultEndpointSuffix, like queue.core.windows.net, without it
+func parseAzureStorageConnectionString(connectionString string, endpointType StorageEndpointType, defaultEndpointSuffix string) (*url.URL, string, string, error) {
 	parts := strings.Split(connectionString, ";")
 
 	getValue := func(pair string) string {
@@ -154,9 +211,14 @@ func parseAzureStorageConnectionString(connectionString string, endpointType Sto
 		return ""
 	}
 
-	var endpointProtocol, name, key, endpointSuffix, endpoint string
+	var endpointProtocol, name, key, endpointSuffix, endpoint, developmentStorageProxy string
+	developmentStorage := false
 	for _, v := range parts {
 		switch {
+		case strings.HasPrefix(v, "UseDevelopmentStorage"):
+			developmentStorage = strings.EqualFold(getValue(v), "true")
+		case strings.HasPrefix(v, "DevelopmentStorageProxyUri"):
+			developmentStorageProxy = getValue(v)
 		case strings.HasPrefix(v, "DefaultEndpointsProtocol"):
 			endpointProtocol = getValue(v)
 		case strings.HasPrefix(v, "AccountName"):
@@ -176,6 +238,21 @@ func parseAzureStorageConnectionString(connectionString string, endpointType Sto
 		}
 	}
 
+	if developmentStorage && endpoint == "" {
+		port := endpointType.developmentStoragePort()
+		if port == 0 {
+			return nil, "", "", fmt.Errorf("the storage emulators don't provide the %s service", endpointType.Name())
+		}
+		host := developmentStorageHost
+		if developmentStorageProxy != "" {
+			host = strings.TrimSuffix(developmentStorageProxy, "/")
+		}
+		endpoint = fmt.Sprintf("%s:%d/%s", host, port, developmentStorageAccountName)
+	}
+	if developmentStorage && name == "" && key == "" {
+		name, key = developmentStorageAccountName, developmentStorageAccountKey
+	}
+
 	if name == "" || key == "" {
 		return nil, "", "", ErrAzureConnectionStringKeyName
 	}
@@ -188,11 +265,19 @@ func parseAzureStorageConnectionString(connectionString string, endpointType Sto
 		return u, name, key, nil
 	}
 
+	if endpointSuffix != "" {
+		endpointSuffix = fmt.Sprintf("%s.%s", endpointType.Name(), endpointSuffix)
+	} else if defaultEndpointSuffix != "" {
+		endpointSuffix = defaultEndpointSuffix
+		if endpointProtocol == "" {
+			endpointProtocol = "https"
+		}
+	}
 	if endpointProtocol == "" || endpointSuffix == "" {
 		return nil, "", "", ErrAzureConnectionStringEndpoint
 	}
 
-	u, err := url.Parse(fmt.Sprintf("%s://%s.%s.%s", endpointProtocol, name, endpointType.Name(), endpointSuffix))
+	u, err := url.Parse(fmt.Sprintf("%s://%s.%s", endpointProtocol, name, endpointSuffix))
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -200,11 +285,15 @@ func parseAzureStorageConnectionString(connectionString string, endpointType Sto
 	return u, name, key, nil
 }
 
-func parseAccessTokenAndEndpoint(ctx context.Context, httpClient util.HTTPDoer, accountName string, endpointSuffix string,
+func parseAccessTokenAndEndpoint(ctx context.Context, httpClient util.HTTPDoer, accountName string, endpointSuffix string, endpointOverride string,
 	podIdentity kedav1alpha1.AuthPodIdentity) (string, *url.URL, error) {
 	var token AADToken
 	var err error
 
+	if err := ValidateStorageEndpointForPodIdentity(endpointOverride); err != nil {
+		return "", nil, err
+	}
+
 	switch podIdentity.Provider {
 	case kedav1alpha1.PodIdentityProviderAzure:
 		token, err = GetAzureADPodIdentityToken(ctx, httpClient, podIdentity.IdentityID, storageResource)
@@ -216,6 +305,14 @@ func parseAccessTokenAndEndpoint(ctx context.Context, httpClient util.HTTPDoer,
 		return "", nil, err
 	}
 
+	if endpointOverride != "" {
+		endpoint, err := url.Parse(endpointOverride)
+		if err != nil {
+			return "", nil, err
+		}
+		return token.AccessToken, endpoint, nil
+	}
+
 	if accountName == "" {
 		return "", nil, fmt.Errorf("accountName is required for podIdentity azure")
 	}
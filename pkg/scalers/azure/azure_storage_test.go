@@ -22,11 +22,17 @@ var parseConnectionStringTestDataset = []parseConnectionStringTestData{
 	{"DefaultEndpointsProtocol=https;AccountName=testing;AccountKey=key==;EndpointSuffix=core.windows.net;BlobEndpoint=https://blob.net", "testing", "key==", "https://blob.net", BlobEndpoint, false},
 	{"DefaultEndpointsProtocol=https;AccountName=testing;AccountKey=key==;EndpointSuffix=core.windows.net;TableEndpoint=https://table.net", "testing", "key==", "https://table.net", TableEndpoint, false},
 	{"DefaultEndpointsProtocol=https;AccountName=testing;AccountKey=key==;EndpointSuffix=core.windows.net;FileEndpoint=https://file.net", "testing", "key==", "https://file.net", FileEndpoint, false},
+	{"UseDevelopmentStorage=true", "devstoreaccount1", developmentStorageAccountKey, "http://127.0.0.1:10000/devstoreaccount1", BlobEndpoint, false},
+	{"UseDevelopmentStorage=true", "devstoreaccount1", developmentStorageAccountKey, "http://127.0.0.1:10001/devstoreaccount1", QueueEndpoint, false},
+	{"UseDevelopmentStorage=true;DevelopmentStorageProxyUri=http://azurite", "devstoreaccount1", developmentStorageAccountKey, "http://azurite:10001/devstoreaccount1", QueueEndpoint, false},
+	{"UseDevelopmentStorage=true", "", "", "", FileEndpoint, true},
+	{"DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=key==;QueueEndpoint=http://azurite:10001/devstoreaccount1", "devstoreaccount1", "key==", "http://azurite:10001/devstoreaccount1", QueueEndpoint, false},
+	{"DefaultEndpointsProtocol=https;AccountName=testing;AccountKey=key==;EndpointSuffix=core.chinacloudapi.cn", "testing", "key==", "https://testing.queue.core.chinacloudapi.cn", QueueEndpoint, false},
 }
 
 func TestParseStorageConnectionString(t *testing.T) {
 	for _, testData := range parseConnectionStringTestDataset {
-		endpoint, accountName, accountKey, err := parseAzureStorageConnectionString(testData.connectionString, testData.endpointType)
+		endpoint, accountName, accountKey, err := parseAzureStorageConnectionString(testData.connectionString, testData.endpointType, "")
 
 		if !testData.isError && err != nil {
 			t.Error("Expected success but got err", err)
@@ -61,6 +67,26 @@ func TestParseStorageConnectionString(t *testing.T) {
 	}
 }
 
+func TestParseStorageConnectionStringDefaultEndpointSuffix(t *testing.T) {
+	connectionString := "AccountName=testing;AccountKey=key=="
+	endpoint, _, _, err := parseAzureStorageConnectionString(connectionString, QueueEndpoint, "queue.core.chinacloudapi.cn")
+	if err != nil {
+		t.Fatal("Expected success but got err", err)
+	}
+	if endpoint.String() != "https://testing.queue.core.chinacloudapi.cn" {
+		t.Error("Expected endpoint https://testing.queue.core.chinacloudapi.cn but got", endpoint)
+	}
+
+	// the endpoint suffix of the connection string takes precedence
+	endpoint, _, _, err = parseAzureStorageConnectionString("DefaultEndpointsProtocol=https;"+connectionString+";EndpointSuffix=core.windows.net", QueueEndpoint, "queue.core.chinacloudapi.cn")
+	if err != nil {
+		t.Fatal("Expected success but got err", err)
+	}
+	if endpoint.String() != "https://testing.queue.core.windows.net" {
+		t.Error("Expected endpoint https://testing.queue.core.windows.net but got", endpoint)
+	}
+}
+
 type parseAzureStorageEndpointSuffixTestData struct {
 	metadata       map[string]string
 	endpointSuffix string
@@ -74,7 +100,7 @@ var parseAzureStorageEndpointSuffixTestDataset = []parseAzureStorageEndpointSuff
 	{map[string]string{"cloud": "AzureUSGovernmentCloud"}, "queue.core.usgovcloudapi.net", QueueEndpoint, false},
 	{map[string]string{"cloud": "Private"}, "", BlobEndpoint, true},
 	{map[string]string{"cloud": "Private", "endpointSuffix": "blob.core.private.cloud"}, "blob.core.private.cloud", BlobEndpoint, false},
-	{map[string]string{"endpointSuffix": "ignored"}, "blob.core.windows.net", BlobEndpoint, false},
+	{map[string]string{"endpointSuffix": "blob.core.custom.cloud"}, "blob.core.custom.cloud", BlobEndpoint, false},
 }
 
 func TestParseAzureStorageEndpointSuffix(t *testing.T) {
@@ -96,3 +122,47 @@ func TestParseAzureStorageEndpointSuffix(t *testing.T) {
 		}
 	}
 }
+
+type parseAzureStorageEndpointTestData struct {
+	metadata map[string]string
+	endpoint string
+	isError  bool
+}
+
+var parseAzureStorageEndpointTestDataset = []parseAzureStorageEndpointTestData{
+	{map[string]string{}, "", false},
+	{map[string]string{"endpoint": "http://azurite:10001/devstoreaccount1"}, "http://azurite:10001/devstoreaccount1", false},
+	{map[string]string{"endpoint": "https://testing.queue.core.custom.cloud"}, "https://testing.queue.core.custom.cloud", false},
+	{map[string]string{"endpoint": "azurite:10001"}, "", true},
+	{map[string]string{"endpoint": "ftp://azurite"}, "", true},
+}
+
+func TestParseAzureStorageEndpoint(t *testing.T) {
+	for _, testData := range parseAzureStorageEndpointTestDataset {
+		endpoint, err := ParseAzureStorageEndpoint(testData.metadata)
+		if !testData.isError && err != nil {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+		if endpoint != testData.endpoint {
+			t.Error(
+				"For", testData.metadata,
+				"expected endpoint=", testData.endpoint,
+				"but got", endpoint)
+		}
+	}
+}
+
+func TestValidateStorageEndpointForPodIdentity(t *testing.T) {
+	if err := ValidateStorageEndpointForPodIdentity(""); err != nil {
+		t.Error("Expected success without endpoint but got error", err)
+	}
+	if err := ValidateStorageEndpointForPodIdentity("https://testing.queue.core.custom.cloud"); err != nil {
+		t.Error("Expected success with https endpoint but got error", err)
+	}
+	if err := ValidateStorageEndpointForPodIdentity("http://azurite:10001/devstoreaccount1"); err == nil {
+		t.Error("Expected error with http endpoint but got success")
+	}
+}
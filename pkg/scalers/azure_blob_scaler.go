@@ -66,7 +66,7 @@ func NewAzureBlobScaler(config *ScalerConfig) (Scaler, error) {
 		metricType:  metricType,
 		metadata:    meta,
 		podIdentity: podIdentity,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy),
 		logger:      logger,
 	}, nil
 }
@@ -138,6 +138,13 @@ func parseAzureBlobMetadata(config *ScalerConfig, logger logr.Logger) (*azure.Bl
 
 	meta.EndpointSuffix = endpointSuffix
 
+	endpoint, err := azure.ParseAzureStorageEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, kedav1alpha1.AuthPodIdentity{}, err
+	}
+
+	meta.Endpoint = endpoint
+
 	// before triggerAuthentication CRD, pod identity was configured using this property
 	if val, ok := config.TriggerMetadata["useAAdPodIdentity"]; ok && config.PodIdentity.Provider == "" && val == stringTrue {
 		config.PodIdentity = kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAzure}
@@ -166,12 +173,16 @@ func parseAzureBlobMetadata(config *ScalerConfig, logger logr.Logger) (*azure.Bl
 			return nil, kedav1alpha1.AuthPodIdentity{}, fmt.Errorf("no connection setting given")
 		}
 	case kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload:
-		// If the Use AAD Pod Identity / Workload Identity is present then check account name
+		// If the Use AAD Pod Identity / Workload Identity is present then check account name,
+		// which isn't needed with a full endpoint
 		if val, ok := config.TriggerMetadata["accountName"]; ok && val != "" {
 			meta.AccountName = val
-		} else {
+		} else if meta.Endpoint == "" {
 			return nil, kedav1alpha1.AuthPodIdentity{}, fmt.Errorf("no accountName given")
 		}
+		if err := azure.ValidateStorageEndpointForPodIdentity(meta.Endpoint); err != nil {
+			return nil, kedav1alpha1.AuthPodIdentity{}, err
+		}
 	default:
 		return nil, kedav1alpha1.AuthPodIdentity{}, fmt.Errorf("pod identity %s not supported for azure storage blobs", config.PodIdentity)
 	}
@@ -72,7 +72,7 @@ var testAzBlobMetadata = []parseAzBlobMetadataTestData{
 	// podIdentity = azure with private cloud and no endpoint suffix
 	{map[string]string{"accountName": "sample_acc", "blobContainerName": "sample_container", "cloud": "Private", "endpointSuffix": ""}, true, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 	// podIdentity = azure with endpoint suffix and no cloud
-	{map[string]string{"accountName": "sample_acc", "blobContainerName": "sample_container", "cloud": "", "endpointSuffix": "ignored"}, false, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	{map[string]string{"accountName": "sample_acc", "blobContainerName": "sample_container", "cloud": "", "endpointSuffix": "blob.core.custom.cloud"}, false, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 	// podIdentity = azure-workload with account name
 	{map[string]string{"accountName": "sample_acc", "blobContainerName": "sample_container"}, false, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
 	// podIdentity = azure-workload without account name
@@ -88,9 +88,17 @@ var testAzBlobMetadata = []parseAzBlobMetadataTestData{
 	// podIdentity = azure-workload with private cloud and no endpoint suffix
 	{map[string]string{"accountName": "sample_acc", "blobContainerName": "sample_container", "cloud": "Private", "endpointSuffix": ""}, true, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
 	// podIdentity = azure-workload with endpoint suffix and no cloud
-	{map[string]string{"accountName": "sample_acc", "blobContainerName": "sample_container", "cloud": "", "endpointSuffix": "ignored"}, false, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
+	{map[string]string{"accountName": "sample_acc", "blobContainerName": "sample_container", "cloud": "", "endpointSuffix": "blob.core.custom.cloud"}, false, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
 	// connection from authParams
 	{map[string]string{"blobContainerName": "sample_container", "blobCount": "5"}, false, testAzBlobResolvedEnv, map[string]string{"connection": "value"}, kedav1alpha1.PodIdentityProviderNone},
+	// connection with endpoint
+	{map[string]string{"connectionFromEnv": "CONNECTION", "blobContainerName": "sample", "endpoint": "http://azurite:10000/devstoreaccount1"}, false, testAzBlobResolvedEnv, map[string]string{}, ""},
+	// invalid endpoint
+	{map[string]string{"connectionFromEnv": "CONNECTION", "blobContainerName": "sample", "endpoint": "azurite:10000"}, true, testAzBlobResolvedEnv, map[string]string{}, ""},
+	// podIdentity = azure-workload with endpoint and no account name
+	{map[string]string{"blobContainerName": "sample_container", "endpoint": "https://sample_acc.blob.core.custom.cloud"}, false, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
+	// podIdentity = azure-workload with http endpoint
+	{map[string]string{"blobContainerName": "sample_container", "endpoint": "http://azurite:10000/devstoreaccount1"}, true, testAzBlobResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
 	// with globPattern
 	{map[string]string{"connectionFromEnv": "CONNECTION", "blobContainerName": "sample", "blobCount": "5", "globPattern": "foo**"}, false, testAzBlobResolvedEnv, map[string]string{}, ""},
 	// with recursive true
@@ -0,0 +1,193 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers/azure"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultAzureCosmosDBLagThreshold           = 100
+	defaultAzureCosmosDBActivationLagThreshold = 0
+)
+
+type azureCosmosDBMetadata struct {
+	endpoint               string
+	accountKey             string
+	databaseID             string
+	containerID            string
+	leaseDatabaseID        string
+	leaseContainerID       string
+	processorName          string
+	lagThreshold           int64
+	activationLagThreshold int64
+	scalerIndex            int
+}
+
+// azureCosmosDBClient reads the leases of a change feed processor and the change feed of the monitored container
+type azureCosmosDBClient interface {
+	GetLeases(ctx context.Context, databaseID, containerID, processorName string) ([]azure.CosmosDBLease, error)
+	ReadChangeFeed(ctx context.Context, databaseID, containerID, partitionKeyRangeID, continuationToken string) (azure.CosmosDBChangeFeedState, error)
+}
+
+type azureCosmosDBScaler struct {
+	metricType v2.MetricTargetType
+	metadata   *azureCosmosDBMetadata
+	client     azureCosmosDBClient
+	logger     logr.Logger
+}
+
+// NewAzureCosmosDBScaler creates a new scaler for the change feed lag of an Azure Cosmos DB container
+func NewAzureCosmosDBScaler(config *ScalerConfig) (Scaler, error) {
+	metricType, err := GetMetricTargetType(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scaler metric type: %w", err)
+	}
+
+	logger := InitializeLogger(config, "azure_cosmosdb_scaler")
+
+	meta, err := parseAzureCosmosDBMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing azure cosmos db metadata: %w", err)
+	}
+
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy)
+	client, err := azure.NewCosmosDBClient(httpClient, meta.endpoint, meta.accountKey, config.PodIdentity)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure cosmos db client: %w", err)
+	}
+
+	return &azureCosmosDBScaler{
+		metricType: metricType,
+		metadata:   meta,
+		client:     client,
+		logger:     logger,
+	}, nil
+}
+
+func parseAzureCosmosDBMetadata(config *ScalerConfig) (*azureCosmosDBMetadata, error) {
+	meta := azureCosmosDBMetadata{}
+
+	var err error
+	meta.endpoint, err = getParameterFromConfig(config, "endpoint", true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, parameter := range []struct {
+		name  string
+		value *string
+	}{
+		{name: "databaseId", value: &meta.databaseID},
+		{name: "containerId", value: &meta.containerID},
+		{name: "leaseDatabaseId", value: &meta.leaseDatabaseID},
+		{name: "leaseContainerId", value: &meta.leaseContainerID},
+		{name: "processorName", value: &meta.processorName},
+	} {
+		val, ok := config.TriggerMetadata[parameter.name]
+		if !ok || val == "" {
+			return nil, fmt.Errorf("no %s given", parameter.name)
+		}
+		*parameter.value = val
+	}
+
+	meta.lagThreshold = defaultAzureCosmosDBLagThreshold
+	if val, ok := config.TriggerMetadata["lagThreshold"]; ok {
+		lagThreshold, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing lagThreshold: %w", err)
+		}
+		if lagThreshold <= 0 {
+			return nil, fmt.Errorf("lagThreshold must be positive, %d given", lagThreshold)
+		}
+		meta.lagThreshold = lagThreshold
+	}
+
+	meta.activationLagThreshold = defaultAzureCosmosDBActivationLagThreshold
+	if val, ok := config.TriggerMetadata["activationLagThreshold"]; ok {
+		activationLagThreshold, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationLagThreshold: %w", err)
+		}
+		if activationLagThreshold < 0 {
+			return nil, fmt.Errorf("activationLagThreshold must be non-negative, %d given", activationLagThreshold)
+		}
+		meta.activationLagThreshold = activationLagThreshold
+	}
+
+	switch config.PodIdentity.Provider {
+	case "", kedav1alpha1.PodIdentityProviderNone:
+		meta.accountKey, err = getParameterFromConfig(config, "accountKey", true)
+		if err != nil {
+			return nil, err
+		}
+	case kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload:
+	default:
+		return nil, fmt.Errorf("pod identity %s not supported for azure cosmos db", config.PodIdentity.Provider)
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func (s *azureCosmosDBScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *azureCosmosDBScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	externalMetric := &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("azure-cosmosdb-%s", s.metadata.processorName))),
+		},
+		Target: GetMetricTarget(s.metricType, s.metadata.lagThreshold),
+	}
+	metricSpec := v2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2.MetricSpec{metricSpec}
+}
+
+// GetMetricsAndActivity returns the estimated number of changes the change feed processor hasn't processed yet
+func (s *azureCosmosDBScaler) GetMetricsAndActivity(ctx context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	lag, err := s.getChangeFeedLag(ctx)
+	if err != nil {
+		s.logger.Error(err, "error getting azure cosmos db change feed lag")
+		return []external_metrics.ExternalMetricValue{}, false, err
+	}
+
+	metric := GenerateMetricInMili(metricName, float64(lag))
+
+	return []external_metrics.ExternalMetricValue{metric}, lag > s.metadata.activationLagThreshold, nil
+}
+
+// getChangeFeedLag estimates the lag of each lease as the number of changes between its continuation token
+// and the latest change of its partition key range, and returns the sum
+func (s *azureCosmosDBScaler) getChangeFeedLag(ctx context.Context) (int64, error) {
+	leases, err := s.client.GetLeases(ctx, s.metadata.leaseDatabaseID, s.metadata.leaseContainerID, s.metadata.processorName)
+	if err != nil {
+		return 0, err
+	}
+
+	var lag int64
+	for _, lease := range leases {
+		state, err := s.client.ReadChangeFeed(ctx, s.metadata.databaseID, s.metadata.containerID, lease.LeaseToken, lease.ContinuationToken)
+		if err != nil {
+			return 0, fmt.Errorf("error estimating the lag of lease %s: %w", lease.ID, err)
+		}
+		if !state.HasChanges {
+			continue
+		}
+		if leaseLag := state.CurrentLSN - state.FirstLSN + 1; leaseLag > 0 {
+			s.logger.V(1).Info("Estimated lease lag", "lease", lease.ID, "leaseToken", lease.LeaseToken, "lag", leaseLag)
+			lag += leaseLag
+		}
+	}
+	return lag, nil
+}
@@ -0,0 +1,228 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers/azure"
+)
+
+type parseAzureCosmosDBMetadataTestData struct {
+	metadata    map[string]string
+	authParams  map[string]string
+	podIdentity kedav1alpha1.PodIdentityProvider
+	isError     bool
+}
+
+type azureCosmosDBMetricIdentifier struct {
+	metadataTestData *parseAzureCosmosDBMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testAzureCosmosDBBaseMetadata = map[string]string{
+	"endpoint":         "https://orders.documents.azure.com:443/",
+	"databaseId":       "orders",
+	"containerId":      "events",
+	"leaseDatabaseId":  "orders",
+	"leaseContainerId": "leases",
+	"processorName":    "order-processor",
+}
+
+func withAzureCosmosDBMetadata(values map[string]string) map[string]string {
+	metadata := map[string]string{}
+	for key, value := range testAzureCosmosDBBaseMetadata {
+		metadata[key] = value
+	}
+	for key, value := range values {
+		if value == "" {
+			delete(metadata, key)
+		} else {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+var testAzureCosmosDBAuthParams = map[string]string{"accountKey": "a2V5"}
+
+var testAzureCosmosDBMetadata = []parseAzureCosmosDBMetadataTestData{
+	// properly formed metadata with the account key
+	{metadata: testAzureCosmosDBBaseMetadata, authParams: testAzureCosmosDBAuthParams},
+	// thresholds
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"lagThreshold": "50", "activationLagThreshold": "10"}), authParams: testAzureCosmosDBAuthParams},
+	// endpoint from the authentication parameters
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"endpoint": ""}), authParams: map[string]string{"endpoint": "https://orders.documents.azure.com:443/", "accountKey": "a2V5"}},
+	// workload identity without account key
+	{metadata: testAzureCosmosDBBaseMetadata, podIdentity: kedav1alpha1.PodIdentityProviderAzureWorkload},
+	// missing account key
+	{metadata: testAzureCosmosDBBaseMetadata, isError: true},
+	// unsupported pod identity
+	{metadata: testAzureCosmosDBBaseMetadata, podIdentity: kedav1alpha1.PodIdentityProviderAwsEKS, isError: true},
+	// missing endpoint
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"endpoint": ""}), authParams: testAzureCosmosDBAuthParams, isError: true},
+	// missing databaseId
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"databaseId": ""}), authParams: testAzureCosmosDBAuthParams, isError: true},
+	// missing containerId
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"containerId": ""}), authParams: testAzureCosmosDBAuthParams, isError: true},
+	// missing leaseDatabaseId
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"leaseDatabaseId": ""}), authParams: testAzureCosmosDBAuthParams, isError: true},
+	// missing leaseContainerId
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"leaseContainerId": ""}), authParams: testAzureCosmosDBAuthParams, isError: true},
+	// missing processorName
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"processorName": ""}), authParams: testAzureCosmosDBAuthParams, isError: true},
+	// invalid lagThreshold
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"lagThreshold": "a"}), authParams: testAzureCosmosDBAuthParams, isError: true},
+	// non positive lagThreshold
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"lagThreshold": "0"}), authParams: testAzureCosmosDBAuthParams, isError: true},
+	// negative activationLagThreshold
+	{metadata: withAzureCosmosDBMetadata(map[string]string{"activationLagThreshold": "-1"}), authParams: testAzureCosmosDBAuthParams, isError: true},
+}
+
+var azureCosmosDBMetricIdentifiers = []azureCosmosDBMetricIdentifier{
+	{&testAzureCosmosDBMetadata[0], 0, "s0-azure-cosmosdb-order-processor"},
+	{&testAzureCosmosDBMetadata[0], 1, "s1-azure-cosmosdb-order-processor"},
+}
+
+func TestParseAzureCosmosDBMetadata(t *testing.T) {
+	for i, testData := range testAzureCosmosDBMetadata {
+		_, err := parseAzureCosmosDBMetadata(&ScalerConfig{
+			TriggerMetadata: testData.metadata,
+			AuthParams:      testData.authParams,
+			PodIdentity:     kedav1alpha1.AuthPodIdentity{Provider: testData.podIdentity},
+		})
+		if err != nil && !testData.isError {
+			t.Errorf("test %d: expected success but got error: %s", i, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("test %d: expected error but got success", i)
+		}
+	}
+}
+
+func TestAzureCosmosDBGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range azureCosmosDBMetricIdentifiers {
+		meta, err := parseAzureCosmosDBMetadata(&ScalerConfig{
+			TriggerMetadata: testData.metadataTestData.metadata,
+			AuthParams:      testData.metadataTestData.authParams,
+			ScalerIndex:     testData.scalerIndex,
+		})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		scaler := azureCosmosDBScaler{metadata: meta}
+
+		metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected %s", metricName, testData.name)
+		}
+	}
+}
+
+// leases stored by a change feed processor, with the .info and .lock documents already filtered out by the client
+const azureCosmosDBLeasesFixture = `[
+	{"id": "order-processororders.documents.azure.com_AAAA_BBBB..0", "LeaseToken": "0", "ContinuationToken": "\"120\"", "Owner": "host-1"},
+	{"id": "order-processororders.documents.azure.com_AAAA_BBBB..1", "LeaseToken": "1", "ContinuationToken": "\"340\"", "Owner": "host-1"},
+	{"id": "order-processororders.documents.azure.com_AAAA_BBBB..2", "LeaseToken": "2", "ContinuationToken": "\"15\"", "Owner": "host-2"},
+	{"id": "order-processororders.documents.azure.com_AAAA_BBBB..3", "LeaseToken": "3", "ContinuationToken": null, "Owner": null}
+]`
+
+type azureCosmosDBMockClient struct {
+	leases []azure.CosmosDBLease
+	// changes of each partition key range by continuation token
+	changes map[string]azure.CosmosDBChangeFeedState
+	err     error
+}
+
+func (c *azureCosmosDBMockClient) GetLeases(_ context.Context, databaseID, containerID, processorName string) ([]azure.CosmosDBLease, error) {
+	if databaseID != "orders" || containerID != "leases" || processorName != "order-processor" {
+		return nil, fmt.Errorf("unexpected lease container %s/%s for %s", databaseID, containerID, processorName)
+	}
+	return c.leases, nil
+}
+
+func (c *azureCosmosDBMockClient) ReadChangeFeed(_ context.Context, databaseID, containerID, partitionKeyRangeID, continuationToken string) (azure.CosmosDBChangeFeedState, error) {
+	if databaseID != "orders" || containerID != "events" {
+		return azure.CosmosDBChangeFeedState{}, fmt.Errorf("unexpected monitored container %s/%s", databaseID, containerID)
+	}
+	if c.err != nil {
+		return azure.CosmosDBChangeFeedState{}, c.err
+	}
+	return c.changes[partitionKeyRangeID+"/"+continuationToken], nil
+}
+
+func newAzureCosmosDBMockClient(t *testing.T) *azureCosmosDBMockClient {
+	var leases []azure.CosmosDBLease
+	if err := json.Unmarshal([]byte(azureCosmosDBLeasesFixture), &leases); err != nil {
+		t.Fatal(err)
+	}
+	return &azureCosmosDBMockClient{
+		leases: leases,
+		changes: map[string]azure.CosmosDBChangeFeedState{
+			// 30 changes after the continuation token
+			`0/"120"`: {HasChanges: true, FirstLSN: 121, CurrentLSN: 150},
+			// no change after the continuation token
+			`1/"340"`: {},
+			// a single change
+			`2/"15"`: {HasChanges: true, FirstLSN: 16, CurrentLSN: 16},
+			// never processed, 5 changes from the beginning
+			`3/`: {HasChanges: true, FirstLSN: 1, CurrentLSN: 5},
+		},
+	}
+}
+
+func TestAzureCosmosDBGetMetricsAndActivity(t *testing.T) {
+	meta, err := parseAzureCosmosDBMetadata(&ScalerConfig{TriggerMetadata: testAzureCosmosDBBaseMetadata, AuthParams: testAzureCosmosDBAuthParams})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scaler := azureCosmosDBScaler{
+		metadata: meta,
+		client:   newAzureCosmosDBMockClient(t),
+		logger:   logr.Discard(),
+	}
+
+	metrics, isActive, err := scaler.GetMetricsAndActivity(context.Background(), "s0-azure-cosmosdb-order-processor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := metrics[0].Value.Value(); value != 36 {
+		t.Errorf("expected lag 36 but got %d", value)
+	}
+	if !isActive {
+		t.Error("expected active scaler")
+	}
+
+	scaler.metadata.activationLagThreshold = 36
+	_, isActive, err = scaler.GetMetricsAndActivity(context.Background(), "s0-azure-cosmosdb-order-processor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isActive {
+		t.Error("expected inactive scaler when the lag doesn't exceed activationLagThreshold")
+	}
+}
+
+func TestAzureCosmosDBGetMetricsAndActivityError(t *testing.T) {
+	meta, err := parseAzureCosmosDBMetadata(&ScalerConfig{TriggerMetadata: testAzureCosmosDBBaseMetadata, AuthParams: testAzureCosmosDBAuthParams})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newAzureCosmosDBMockClient(t)
+	client.err = fmt.Errorf("404 NotFound")
+	scaler := azureCosmosDBScaler{
+		metadata: meta,
+		client:   client,
+		logger:   logr.Discard(),
+	}
+
+	if _, _, err := scaler.GetMetricsAndActivity(context.Background(), "s0-azure-cosmosdb-order-processor"); err == nil {
+		t.Error("expected error when the change feed can't be read")
+	}
+}
@@ -55,7 +55,7 @@ func NewAzureDataExplorerScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("failed to parse azure data explorer metadata: %w", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy)
 	client, err := azure.CreateAzureDataExplorerClient(metadata, httpClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create azure data explorer client: %w", err)
@@ -53,6 +53,8 @@ type azureEventHubScaler struct {
 	client     *eventhub.Hub
 	httpClient *http.Client
 	logger     logr.Logger
+
+	observedPartitions
 }
 
 type eventHubMetadata struct {
@@ -85,7 +87,7 @@ func NewAzureEventHubScaler(ctx context.Context, config *ScalerConfig) (Scaler,
 		metricType: metricType,
 		metadata:   parsedMetadata,
 		client:     hub,
-		httpClient: kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient: kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy),
 		logger:     logger,
 	}, nil
 }
@@ -189,6 +191,9 @@ func parseAzureEventHubAuthenticationMetadata(logger logr.Logger, config *Scaler
 	switch config.PodIdentity.Provider {
 	case "", v1alpha1.PodIdentityProviderNone:
 		if len(meta.eventHubInfo.StorageConnection) == 0 {
+			if config.TriggerMetadata["storageAccountName"] != "" {
+				return fmt.Errorf("storageAccountName requires the %s or %s pod identity, give a storage connection string instead", v1alpha1.PodIdentityProviderAzure, v1alpha1.PodIdentityProviderAzureWorkload)
+			}
 			return fmt.Errorf("no storage connection string given")
 		}
 
@@ -220,6 +225,11 @@ func parseAzureEventHubAuthenticationMetadata(logger logr.Logger, config *Scaler
 
 		meta.eventHubInfo.EventHubConnection = connection
 	case v1alpha1.PodIdentityProviderAzure, v1alpha1.PodIdentityProviderAzureWorkload:
+		// the event hub client is always authenticated with the pod identity, the connection string would be ignored
+		if config.AuthParams["connection"] != "" || config.TriggerMetadata["connectionFromEnv"] != "" {
+			return fmt.Errorf("an event hub connection string can't be used with the %s pod identity, give eventHubNamespace and eventHubName instead", config.PodIdentity.Provider)
+		}
+
 		meta.eventHubInfo.StorageAccountName = ""
 		if val, ok := config.TriggerMetadata["storageAccountName"]; ok {
 			meta.eventHubInfo.StorageAccountName = val
@@ -227,6 +237,13 @@ func parseAzureEventHubAuthenticationMetadata(logger logr.Logger, config *Scaler
 			logger.Info("no 'storageAccountName' provided to enable identity based authentication to Blob Storage. Attempting to use connection string instead")
 		}
 
+		if strings.ContainsAny(meta.eventHubInfo.StorageAccountName, ".=/") {
+			return fmt.Errorf("storageAccountName must be the name of the storage account, not its endpoint or connection string, set cloud or storageEndpointSuffix for other clouds")
+		}
+		if len(meta.eventHubInfo.StorageConnection) != 0 && len(meta.eventHubInfo.StorageAccountName) != 0 {
+			logger.Info("both a storage connection string and 'storageAccountName' are given, the checkpoints are read with the connection string")
+		}
+
 		if len(meta.eventHubInfo.StorageAccountName) != 0 {
 			storageEndpointSuffixProvider := func(env az.Environment) (string, error) {
 				return env.StorageEndpointSuffix, nil
@@ -251,6 +268,9 @@ func parseAzureEventHubAuthenticationMetadata(logger logr.Logger, config *Scaler
 		if len(meta.eventHubInfo.Namespace) == 0 {
 			return fmt.Errorf("no event hub namespace string given")
 		}
+		if strings.Contains(meta.eventHubInfo.Namespace, ".") {
+			return fmt.Errorf("eventHubNamespace must be the name of the namespace, not its host name, set cloud or endpointSuffix for other clouds")
+		}
 
 		if config.TriggerMetadata["eventHubName"] != "" {
 			meta.eventHubInfo.EventHubName = config.TriggerMetadata["eventHubName"]
@@ -261,6 +281,8 @@ func parseAzureEventHubAuthenticationMetadata(logger logr.Logger, config *Scaler
 		if len(meta.eventHubInfo.EventHubName) == 0 {
 			return fmt.Errorf("no event hub name string given")
 		}
+	default:
+		return fmt.Errorf("pod identity %s not supported for azure event hub", config.PodIdentity.Provider)
 	}
 
 	return nil
@@ -369,6 +391,7 @@ func (s *azureEventHubScaler) GetMetricsAndActivity(ctx context.Context, metricN
 	}
 
 	partitionIDs := runtimeInfo.PartitionIDs
+	s.setPartitionCount(len(partitionIDs))
 
 	for i := 0; i < len(partitionIDs); i++ {
 		partitionID := partitionIDs[i]
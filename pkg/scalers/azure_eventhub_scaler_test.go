@@ -103,6 +103,12 @@ var parseEventHubMetadataDataset = []parseEventHubMetadataTestData{
 		resolvedEnv: map[string]string{eventHubConnectionSetting: "Endpoint=sb://testEventHubNamespace.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=testKey;", storageConnectionSetting: "none"},
 		isError:     false,
 	},
+	// storage account name without pod identity
+	{
+		metadata:    map[string]string{"storageAccountName": "blobstorage", "consumerGroup": eventHubConsumerGroup, "connectionFromEnv": eventHubConnectionSetting},
+		resolvedEnv: sampleEventHubResolvedEnv,
+		isError:     true,
+	},
 }
 
 var parseEventHubMetadataDatasetWithPodIdentity = []parseEventHubMetadataTestData{
@@ -200,6 +206,36 @@ var parseEventHubMetadataDatasetWithPodIdentity = []parseEventHubMetadataTestDat
 		resolvedEnv: sampleEventHubResolvedEnv,
 		isError:     false,
 	},
+	// properly formed event hub metadata with Pod Identity and no storage connection string in a sovereign cloud
+	{
+		metadata:    map[string]string{"cloud": "AzureChinaCloud", "storageAccountName": "blobstorage", "consumerGroup": eventHubConsumerGroup, "eventHubName": testEventHubName, "eventHubNamespace": testEventHubNamespace},
+		resolvedEnv: sampleEventHubResolvedEnv,
+		isError:     false,
+	},
+	// storage connection string and storage account name - the connection string is used for the storage
+	{
+		metadata:    map[string]string{"storageConnectionFromEnv": storageConnectionSetting, "storageAccountName": "blobstorage", "consumerGroup": eventHubConsumerGroup, "eventHubName": testEventHubName, "eventHubNamespace": testEventHubNamespace},
+		resolvedEnv: sampleEventHubResolvedEnv,
+		isError:     false,
+	},
+	// event hub connection string with Pod Identity, even with the namespace - should fail
+	{
+		metadata:    map[string]string{"connectionFromEnv": eventHubConnectionSetting, "storageAccountName": "blobstorage", "consumerGroup": eventHubConsumerGroup, "eventHubName": testEventHubName, "eventHubNamespace": testEventHubNamespace},
+		resolvedEnv: sampleEventHubResolvedEnv,
+		isError:     true,
+	},
+	// eventHubNamespace given as a host name - should fail
+	{
+		metadata:    map[string]string{"storageAccountName": "blobstorage", "consumerGroup": eventHubConsumerGroup, "eventHubName": testEventHubName, "eventHubNamespace": testEventHubNamespace + ".servicebus.windows.net"},
+		resolvedEnv: sampleEventHubResolvedEnv,
+		isError:     true,
+	},
+	// storageAccountName given as an endpoint - should fail
+	{
+		metadata:    map[string]string{"storageAccountName": "blobstorage.blob.core.windows.net", "consumerGroup": eventHubConsumerGroup, "eventHubName": testEventHubName, "eventHubNamespace": testEventHubNamespace},
+		resolvedEnv: sampleEventHubResolvedEnv,
+		isError:     true,
+	},
 }
 
 var eventHubMetricIdentifiers = []eventHubMetricIdentifier{
@@ -254,6 +290,35 @@ func TestParseEventHubMetadata(t *testing.T) {
 	}
 }
 
+func TestParseEventHubMetadataEndpoints(t *testing.T) {
+	for _, provider := range []kedav1alpha1.PodIdentityProvider{kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload} {
+		meta, err := parseAzureEventHubMetadata(logr.Discard(), &ScalerConfig{
+			TriggerMetadata: map[string]string{"cloud": "AzureChinaCloud", "storageAccountName": "blobstorage", "eventHubName": testEventHubName, "eventHubNamespace": testEventHubNamespace},
+			AuthParams:      map[string]string{},
+			PodIdentity:     kedav1alpha1.AuthPodIdentity{Provider: provider, IdentityID: "client-id"},
+		})
+		if err != nil {
+			t.Fatalf("Expected success but got error: %s", err)
+		}
+		info := meta.eventHubInfo
+		if info.BlobStorageEndpoint != "blob.core.chinacloudapi.cn" || info.ServiceBusEndpointSuffix != "servicebus.chinacloudapi.cn" || info.ActiveDirectoryEndpoint != "https://login.chinacloudapi.cn/" {
+			t.Errorf("Expected the endpoints of the sovereign cloud but got %s, %s and %s", info.BlobStorageEndpoint, info.ServiceBusEndpointSuffix, info.ActiveDirectoryEndpoint)
+		}
+		if info.PodIdentity.Provider != provider || info.PodIdentity.IdentityID != "client-id" {
+			t.Errorf("Expected the pod identity %s for the hub and the storage but got %v", provider, info.PodIdentity)
+		}
+	}
+
+	_, err := parseAzureEventHubMetadata(logr.Discard(), &ScalerConfig{
+		TriggerMetadata: map[string]string{"storageAccountName": "blobstorage", "eventHubName": testEventHubName, "eventHubNamespace": testEventHubNamespace},
+		AuthParams:      map[string]string{},
+		PodIdentity:     kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAwsEKS},
+	})
+	if err == nil {
+		t.Error("Expected error for an unsupported pod identity and got success")
+	}
+}
+
 func TestGetUnprocessedEventCountInPartition(t *testing.T) {
 	ctx := context.Background()
 	t.Log("This test will use the environment variable EVENTHUB_CONNECTION_STRING and STORAGE_CONNECTION_STRING if it is set.")
@@ -266,7 +331,7 @@ func TestGetUnprocessedEventCountInPartition(t *testing.T) {
 	if eventHubKey != "" && storageConnectionString != "" {
 		eventHubConnectionString := fmt.Sprintf("Endpoint=sb://%s.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=%s;EntityPath=%s", testEventHubNamespace, eventHubKey, testEventHubName)
 		storageCredentials, endpoint, err := azure.ParseAzureStorageBlobConnection(ctx, http.DefaultClient,
-			kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderNone}, storageConnectionString, "", "")
+			kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderNone}, storageConnectionString, "", "", "")
 		if err != nil {
 			t.Error(err)
 			t.FailNow()
@@ -129,7 +129,7 @@ func NewAzureLogAnalyticsScaler(config *ScalerConfig) (Scaler, error) {
 		metadata:   azureLogAnalyticsMetadata,
 		name:       config.ScalableObjectName,
 		namespace:  config.ScalableObjectNamespace,
-		httpClient: kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, useSsl),
+		httpClient: kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, useSsl, config.HTTPProxy),
 		logger:     InitializeLogger(config, "azure_log_analytics_scaler"),
 	}, nil
 }
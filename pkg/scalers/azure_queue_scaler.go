@@ -53,6 +53,7 @@ type azureQueueMetadata struct {
 	connection                  string
 	accountName                 string
 	endpointSuffix              string
+	endpoint                    string
 	scalerIndex                 int
 }
 
@@ -74,7 +75,7 @@ func NewAzureQueueScaler(config *ScalerConfig) (Scaler, error) {
 		metricType:  metricType,
 		metadata:    meta,
 		podIdentity: podIdentity,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy),
 		logger:      logger,
 	}, nil
 }
@@ -113,6 +114,13 @@ func parseAzureQueueMetadata(config *ScalerConfig, logger logr.Logger) (*azureQu
 
 	meta.endpointSuffix = endpointSuffix
 
+	endpoint, err := azure.ParseAzureStorageEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, kedav1alpha1.AuthPodIdentity{}, err
+	}
+
+	meta.endpoint = endpoint
+
 	if val, ok := config.TriggerMetadata["queueName"]; ok && val != "" {
 		meta.queueName = val
 	} else {
@@ -143,12 +151,16 @@ func parseAzureQueueMetadata(config *ScalerConfig, logger logr.Logger) (*azureQu
 			return nil, kedav1alpha1.AuthPodIdentity{}, fmt.Errorf("no connection setting given")
 		}
 	case kedav1alpha1.PodIdentityProviderAzure, kedav1alpha1.PodIdentityProviderAzureWorkload:
-		// If the Use AAD Pod Identity is present then check account name
+		// If the Use AAD Pod Identity is present then check account name,
+		// which isn't needed with a full endpoint
 		if val, ok := config.TriggerMetadata["accountName"]; ok && val != "" {
 			meta.accountName = val
-		} else {
+		} else if meta.endpoint == "" {
 			return nil, kedav1alpha1.AuthPodIdentity{}, fmt.Errorf("no accountName given")
 		}
+		if err := azure.ValidateStorageEndpointForPodIdentity(meta.endpoint); err != nil {
+			return nil, kedav1alpha1.AuthPodIdentity{}, err
+		}
 	default:
 		return nil, kedav1alpha1.AuthPodIdentity{}, fmt.Errorf("pod identity %s not supported for azure storage queues", config.PodIdentity)
 	}
@@ -162,6 +174,11 @@ func (s *azureQueueScaler) Close(context.Context) error {
 	return nil
 }
 
+// ClampNegativeMetrics returns true, the queue length is never negative
+func (s *azureQueueScaler) ClampNegativeMetrics() bool {
+	return true
+}
+
 func (s *azureQueueScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
 	externalMetric := &v2.ExternalMetricSource{
 		Metric: v2.MetricIdentifier{
@@ -183,6 +200,7 @@ func (s *azureQueueScaler) GetMetricsAndActivity(ctx context.Context, metricName
 		s.metadata.queueName,
 		s.metadata.accountName,
 		s.metadata.endpointSuffix,
+		s.metadata.endpoint,
 	)
 
 	if err != nil {
@@ -76,7 +76,7 @@ var testAzQueueMetadata = []parseAzQueueMetadataTestData{
 	// podIdentity = azure with private cloud and no endpoint suffix
 	{map[string]string{"accountName": "sample_acc", "queueName": "sample_queue", "cloud": "Private", "endpointSuffix": ""}, true, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 	// podIdentity = azure with endpoint suffix and no cloud
-	{map[string]string{"accountName": "sample_acc", "queueName": "sample_queue", "cloud": "", "endpointSuffix": "ignored"}, false, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	{map[string]string{"accountName": "sample_acc", "queueName": "sample_queue", "cloud": "", "endpointSuffix": "queue.core.custom.cloud"}, false, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 	// podIdentity = azure-workload with account name
 	{map[string]string{"accountName": "sample_acc", "queueName": "sample_queue"}, false, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
 	// podIdentity = azure-workload without account name
@@ -92,9 +92,17 @@ var testAzQueueMetadata = []parseAzQueueMetadataTestData{
 	// podIdentity = azure-workload with private cloud and no endpoint suffix
 	{map[string]string{"accountName": "sample_acc", "queueName": "sample_queue", "cloud": "Private", "endpointSuffix": ""}, true, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
 	// podIdentity = azure-workload with endpoint suffix and no cloud
-	{map[string]string{"accountName": "sample_acc", "queueName": "sample_queue", "cloud": "", "endpointSuffix": "ignored"}, false, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
+	{map[string]string{"accountName": "sample_acc", "queueName": "sample_queue", "cloud": "", "endpointSuffix": "queue.core.custom.cloud"}, false, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
 	// connection from authParams
 	{map[string]string{"queueName": "sample", "queueLength": "5"}, false, testAzQueueResolvedEnv, map[string]string{"connection": "value"}, kedav1alpha1.PodIdentityProviderNone},
+	// connection with endpoint
+	{map[string]string{"connectionFromEnv": "CONNECTION", "queueName": "sample", "endpoint": "http://azurite:10001/devstoreaccount1"}, false, testAzQueueResolvedEnv, map[string]string{}, ""},
+	// invalid endpoint
+	{map[string]string{"connectionFromEnv": "CONNECTION", "queueName": "sample", "endpoint": "azurite:10001"}, true, testAzQueueResolvedEnv, map[string]string{}, ""},
+	// podIdentity = azure-workload with endpoint and no account name
+	{map[string]string{"queueName": "sample_queue", "endpoint": "https://sample_acc.queue.core.custom.cloud"}, false, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
+	// podIdentity = azure-workload with http endpoint
+	{map[string]string{"queueName": "sample_queue", "endpoint": "http://azurite:10001/devstoreaccount1"}, true, testAzQueueResolvedEnv, map[string]string{}, kedav1alpha1.PodIdentityProviderAzureWorkload},
 }
 
 var azQueueMetricIdentifiers = []azQueueMetricIdentifier{
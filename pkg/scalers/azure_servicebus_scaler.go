@@ -227,6 +227,11 @@ func (s *azureServiceBusScaler) Close(context.Context) error {
 	return nil
 }
 
+// ClampNegativeMetrics returns true, the message count is never negative
+func (s *azureServiceBusScaler) ClampNegativeMetrics() bool {
+	return true
+}
+
 // Returns the metric spec to be used by the HPA
 func (s *azureServiceBusScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
 	metricName := ""
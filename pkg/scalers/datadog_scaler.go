@@ -246,7 +246,7 @@ func newDatadogConnection(ctx context.Context, meta *datadogMetadata, config *Sc
 		})
 
 	configuration := datadog.NewConfiguration()
-	configuration.HTTPClient = kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	configuration.HTTPClient = kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy)
 	apiClient := datadog.NewAPIClient(configuration)
 
 	_, _, err := apiClient.AuthenticationApi.Validate(ctx) //nolint:bodyclose
@@ -0,0 +1,30 @@
+package scalers
+
+// deprecatedTriggerFields are the deprecated metadata fields of the triggers by trigger type, they are still read
+// by the scalers until their removal
+var deprecatedTriggerFields = map[string][]string{
+	"azure-data-explorer": {"clientSecret"},
+	"azure-log-analytics": {"metricName"},
+	"cassandra":           {"metricName"},
+	"cpu":                 {"type"},
+	"datadog":             {"type"},
+	"external":            {"tlsCertFile"},
+	"external-push":       {"tlsCertFile"},
+	"graphite":            {"metricName"},
+	"huawei-cloudeye":     {"minMetricValue"},
+	"influxdb":            {"metricName"},
+	"memory":              {"type"},
+	"mssql":               {"metricName"},
+	"prometheus":          {"metricName"},
+}
+
+// GetDeprecatedTriggerFields returns the deprecated fields set in the metadata of a trigger of the type
+func GetDeprecatedTriggerFields(triggerType string, metadata map[string]string) []string {
+	var fields []string
+	for _, field := range deprecatedTriggerFields[triggerType] {
+		if metadata[field] != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
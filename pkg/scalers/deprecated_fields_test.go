@@ -0,0 +1,29 @@
+package scalers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetDeprecatedTriggerFields(t *testing.T) {
+	testData := []struct {
+		name        string
+		triggerType string
+		metadata    map[string]string
+		expected    []string
+	}{
+		{"deprecated_field", "prometheus", map[string]string{"metricName": "http_requests", "query": "sum(rate(http_requests_total[2m]))"}, []string{"metricName"}},
+		{"empty_deprecated_field", "prometheus", map[string]string{"metricName": "", "query": "sum(rate(http_requests_total[2m]))"}, nil},
+		{"no_deprecated_field", "prometheus", map[string]string{"query": "sum(rate(http_requests_total[2m]))"}, nil},
+		{"field_deprecated_for_another_type", "kafka", map[string]string{"metricName": "lag", "topic": "my-topic"}, nil},
+		{"deprecated_metric_type", "cpu", map[string]string{"type": "Utilization", "value": "50"}, []string{"type"}},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			if fields := GetDeprecatedTriggerFields(tt.triggerType, tt.metadata); !reflect.DeepEqual(tt.expected, fields) {
+				t.Errorf("Expected %v but got %v", tt.expected, fields)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	v2 "k8s.io/api/autoscaling/v2"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/util"
 )
 
@@ -316,6 +317,7 @@ func getValueFromSearch(body []byte, valueLocation string) (float64, error) {
 		if err != nil {
 			return 0, fmt.Errorf(errorMsg, r.String())
 		}
+		prommetrics.RecordScalerValueCoercion("elasticsearchScaler")
 		return q, nil
 	}
 	if r.Type != gjson.Number {
@@ -471,3 +471,18 @@ func TestElasticsearchGetMetricSpecForScaling(t *testing.T) {
 		assert.Equal(t, metricSpec[0].External.Metric.Name, testData.name)
 	}
 }
+
+func TestGetValueFromSearchCoercions(t *testing.T) {
+	body := []byte(`{"hits":{"total":{"value":3}},"aggregations":{"count":{"value":"5"}}}`)
+	coercions := gatheredMetricValue(t, "keda_scaler_value_coercions_total", "scaler", "elasticsearchScaler")
+
+	v, err := getValueFromSearch(body, "hits.total.value")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), v)
+	assert.Equal(t, coercions, gatheredMetricValue(t, "keda_scaler_value_coercions_total", "scaler", "elasticsearchScaler"))
+
+	v, err = getValueFromSearch(body, "aggregations.count.value")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(5), v)
+	assert.Equal(t, coercions+1, gatheredMetricValue(t, "keda_scaler_value_coercions_total", "scaler", "elasticsearchScaler"))
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+var (
+	// ErrAuth is the kind of the errors caused by missing or rejected credentials
+	ErrAuth = errors.New("authentication error")
+
+	// ErrTimeout is the kind of the errors caused by a backend not answering in time
+	ErrTimeout = errors.New("timeout error")
+
+	// ErrBackend is the kind of the errors caused by a failing or unreachable backend
+	ErrBackend = errors.New("backend error")
+
+	// ErrConfig is the kind of the errors caused by an invalid trigger configuration
+	ErrConfig = errors.New("configuration error")
+)
+
+// scalerError wraps an error with its kind, the kind is matched by errors.Is
+// and exposed as the errorType label of the scaler error metrics by GetErrorType
+type scalerError struct {
+	kind error
+	err  error
+}
+
+func (e *scalerError) Error() string {
+	if e == nil || e.err == nil {
+		return "<nil>"
+	}
+	return e.err.Error()
+}
+
+func (e *scalerError) Unwrap() []error {
+	if e == nil {
+		return nil
+	}
+	return []error{e.kind, e.err}
+}
+
+// GetErrorType classifies the error as auth, timeout, backend or config error by its kind, used in the metrics and
+// the health status. The errors without kind are timeouts when they wrap a deadline, the others are unknown
+func GetErrorType(err error) string {
+	switch {
+	case err == nil:
+		return prommetrics.ScalerErrorTypeUnknown
+	case errors.Is(err, ErrAuth):
+		return prommetrics.ScalerErrorTypeAuth
+	case errors.Is(err, ErrTimeout):
+		return prommetrics.ScalerErrorTypeTimeout
+	case errors.Is(err, ErrBackend):
+		return prommetrics.ScalerErrorTypeBackend
+	case errors.Is(err, ErrConfig):
+		return prommetrics.ScalerErrorTypeConfig
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return prommetrics.ScalerErrorTypeTimeout
+	default:
+		return prommetrics.ScalerErrorTypeUnknown
+	}
+}
+
+// WrapScalerError wraps err with its kind, one of ErrAuth, ErrTimeout, ErrBackend or ErrConfig,
+// a nil error stays nil
+func WrapScalerError(kind error, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &scalerError{kind: kind, err: err}
+}
+
+// wrapHTTPRequestError wraps the error of an HTTP request which didn't get any response
+func wrapHTTPRequestError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return WrapScalerError(ErrTimeout, err)
+	}
+	return WrapScalerError(ErrBackend, err)
+}
+
+// wrapHTTPStatusError wraps the error returned for an unexpected HTTP status code with the kind matching the code
+func wrapHTTPStatusError(statusCode int, err error) error {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusProxyAuthRequired:
+		return WrapScalerError(ErrAuth, err)
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return WrapScalerError(ErrTimeout, err)
+	default:
+		return WrapScalerError(ErrBackend, err)
+	}
+}
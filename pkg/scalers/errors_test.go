@@ -0,0 +1,131 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v2 "k8s.io/api/autoscaling/v2"
+
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+type scalerErrorTypeTestData struct {
+	name           string
+	responseStatus int
+	responseDelay  time.Duration
+	errorType      string
+	kind           error
+}
+
+var scalerHTTPErrorTypeTestDataset = []scalerErrorTypeTestData{
+	{name: "unauthorized", responseStatus: http.StatusUnauthorized, errorType: prommetrics.ScalerErrorTypeAuth, kind: ErrAuth},
+	{name: "forbidden", responseStatus: http.StatusForbidden, errorType: prommetrics.ScalerErrorTypeAuth, kind: ErrAuth},
+	{name: "gateway timeout", responseStatus: http.StatusGatewayTimeout, errorType: prommetrics.ScalerErrorTypeTimeout, kind: ErrTimeout},
+	{name: "internal server error", responseStatus: http.StatusInternalServerError, errorType: prommetrics.ScalerErrorTypeBackend, kind: ErrBackend},
+	{name: "not found", responseStatus: http.StatusNotFound, errorType: prommetrics.ScalerErrorTypeBackend, kind: ErrBackend},
+	{name: "client timeout", responseStatus: http.StatusOK, responseDelay: 200 * time.Millisecond, errorType: prommetrics.ScalerErrorTypeTimeout, kind: ErrTimeout},
+}
+
+func newErrorTypeTestServer(t *testing.T, testData scalerErrorTypeTestData) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(testData.responseDelay)
+		w.WriteHeader(testData.responseStatus)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMetricsAPIScalerErrorTypes(t *testing.T) {
+	for _, testData := range scalerHTTPErrorTypeTestDataset {
+		server := newErrorTypeTestServer(t, testData)
+		s, err := NewMetricsAPIScaler(&ScalerConfig{
+			TriggerMetadata:   map[string]string{"url": server.URL, "valueLocation": "value", "targetValue": "1"},
+			AuthParams:        map[string]string{},
+			GlobalHTTPTimeout: 50 * time.Millisecond,
+		})
+		assert.NoError(t, err)
+
+		_, _, err = s.GetMetricsAndActivity(context.TODO(), "test-metric")
+		assert.ErrorIs(t, err, testData.kind, testData.name)
+		assert.Equal(t, testData.errorType, GetErrorType(err), testData.name)
+	}
+}
+
+func TestPrometheusScalerErrorTypes(t *testing.T) {
+	for _, testData := range scalerHTTPErrorTypeTestDataset {
+		server := newErrorTypeTestServer(t, testData)
+		s := prometheusScaler{
+			metadata:   &prometheusMetadata{serverAddress: server.URL},
+			httpClient: &http.Client{Timeout: 50 * time.Millisecond},
+			logger:     logr.Discard(),
+		}
+
+		_, err := s.ExecutePromQuery(context.TODO())
+		assert.ErrorIs(t, err, testData.kind, testData.name)
+		assert.Equal(t, testData.errorType, GetErrorType(err), testData.name)
+	}
+}
+
+func TestScalerConfigErrorTypes(t *testing.T) {
+	_, err := NewMetricsAPIScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "value", "targetValue": "1"},
+		MetricType:      v2.UtilizationMetricType,
+	})
+	assert.ErrorIs(t, err, ErrConfig)
+	assert.ErrorIs(t, err, ErrScalerUnsupportedUtilizationMetricType)
+	assert.Equal(t, prommetrics.ScalerErrorTypeConfig, GetErrorType(err))
+
+	_, err = GetFromAuthOrMeta(&ScalerConfig{TriggerMetadata: map[string]string{}, AuthParams: map[string]string{}}, "host")
+	assert.ErrorIs(t, err, ErrScalerConfigMissingField)
+	assert.Equal(t, prommetrics.ScalerErrorTypeConfig, GetErrorType(err))
+
+	_, err = GetHTTPProxy(&ScalerConfig{TriggerMetadata: map[string]string{"proxy": "ftp://proxy:21"}})
+	assert.Equal(t, prommetrics.ScalerErrorTypeConfig, GetErrorType(err))
+}
+
+func TestWrapScalerError(t *testing.T) {
+	assert.Nil(t, WrapScalerError(ErrAuth, nil))
+
+	err := WrapScalerError(ErrBackend, errors.New("queue not found"))
+	assert.Equal(t, "queue not found", err.Error())
+	assert.ErrorIs(t, err, ErrBackend)
+	assert.NotErrorIs(t, err, ErrAuth)
+
+	var typedNil *scalerError
+	assert.Equal(t, prommetrics.ScalerErrorTypeUnknown, GetErrorType(typedNil))
+}
+
+type timeoutTestError struct{}
+
+func (timeoutTestError) Error() string   { return "i/o timeout" }
+func (timeoutTestError) Timeout() bool   { return true }
+func (timeoutTestError) Temporary() bool { return true }
+func (timeoutTestError) Unwrap() error   { return os.ErrDeadlineExceeded }
+
+func TestGetErrorType(t *testing.T) {
+	tests := []struct {
+		err       error
+		errorType string
+	}{
+		{err: nil, errorType: prommetrics.ScalerErrorTypeUnknown},
+		{err: errors.New("something failed"), errorType: prommetrics.ScalerErrorTypeUnknown},
+		{err: WrapScalerError(ErrAuth, errors.New("unauthorized")), errorType: prommetrics.ScalerErrorTypeAuth},
+		{err: fmt.Errorf("error requesting metrics endpoint: %w", WrapScalerError(ErrBackend, errors.New("bad gateway"))), errorType: prommetrics.ScalerErrorTypeBackend},
+		{err: fmt.Errorf("query failed: %w", context.DeadlineExceeded), errorType: prommetrics.ScalerErrorTypeTimeout},
+		{err: fmt.Errorf("get: %w", timeoutTestError{}), errorType: prommetrics.ScalerErrorTypeTimeout},
+		{err: errors.Join(errors.New("first"), WrapScalerError(ErrConfig, errors.New("missing host"))), errorType: prommetrics.ScalerErrorTypeConfig},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.errorType, GetErrorType(test.err), "%v", test.err)
+	}
+}
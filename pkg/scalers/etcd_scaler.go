@@ -49,6 +49,8 @@ type etcdMetadata struct {
 	key         string
 	keyPassword string
 	ca          string
+	// serverPublicKeyPin replaces the verification of the certificate chain of the etcd servers when it's set
+	serverPublicKeyPin string
 }
 
 // NewEtcdScaler creates a new etcdScaler
@@ -96,6 +98,11 @@ func parseEtcdAuthParams(config *ScalerConfig, meta *etcdMetadata) error {
 			} else {
 				meta.keyPassword = ""
 			}
+			pin, err := getTLSServerPublicKeyPin(config)
+			if err != nil {
+				return err
+			}
+			meta.serverPublicKeyPin = pin
 			meta.enableTLS = true
 		} else if val != etcdTLSDisable {
 			return fmt.Errorf("err incorrect value for TLS given: %s", val)
@@ -158,6 +165,9 @@ func getEtcdClients(metadata *etcdMetadata) (*clientv3.Client, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := kedautil.PinServerPublicKey(tlsConfig, metadata.serverPublicKeyPin); err != nil {
+			return nil, err
+		}
 	}
 
 	cli, err := clientv3.New(clientv3.Config{
@@ -3,6 +3,8 @@ package scalers
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,9 +16,11 @@ import (
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	v2 "k8s.io/api/autoscaling/v2"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	pb "github.com/kedacore/keda/v2/pkg/scalers/externalscaler"
 	"github.com/kedacore/keda/v2/pkg/util"
 )
@@ -41,6 +45,9 @@ type externalScalerMetadata struct {
 	tlsClientCert    string
 	tlsClientKey     string
 	unsafeSsl        bool
+	// serverPublicKeyPin replaces the verification of the certificate chain of the external scaler when it's set
+	serverPublicKeyPin string
+	proxy              *url.URL
 }
 
 type connectionGroup struct {
@@ -104,6 +111,7 @@ func NewExternalPushScaler(config *ScalerConfig) (PushScaler, error) {
 func parseExternalScalerMetadata(config *ScalerConfig) (externalScalerMetadata, error) {
 	meta := externalScalerMetadata{
 		originalMetadata: config.TriggerMetadata,
+		proxy:            config.HTTPProxy,
 	}
 
 	// Check if scalerAddress is present
@@ -138,6 +146,13 @@ func parseExternalScalerMetadata(config *ScalerConfig) (externalScalerMetadata,
 		}
 		meta.unsafeSsl = boolVal
 	}
+
+	pin, err := getTLSServerPublicKeyPin(config)
+	if err != nil {
+		return meta, err
+	}
+	meta.serverPublicKeyPin = pin
+
 	// Add elements to metadata
 	for key, value := range config.TriggerMetadata {
 		// Check if key is in resolved environment and resolve
@@ -303,6 +318,14 @@ func getClientForConnectionPool(metadata externalScalerMetadata, logger logr.Log
 	defer connectionPoolMutex.Unlock()
 
 	buildGRPCConnection := func(metadata externalScalerMetadata) (*grpc.ClientConn, error) {
+		opts := []grpc.DialOption{
+			grpc.WithChainUnaryInterceptor(externalScalerRPCUnaryInterceptor(metadata.scalerAddress)),
+			grpc.WithChainStreamInterceptor(externalScalerRPCStreamInterceptor(metadata.scalerAddress)),
+		}
+		if dialer := util.ProxyDialer(metadata.proxy); dialer != nil {
+			opts = append(opts, grpc.WithContextDialer(dialer))
+		}
+
 		// FIXME: DEPRECATED to be removed in v2.13 https://github.com/kedacore/keda/issues/4549
 		if metadata.tlsCertFile != "" {
 			logger.V(1).Info("tlsCertFile in ScaleObject metadata will be deprecated in v2.12. Please use" +
@@ -311,7 +334,7 @@ func getClientForConnectionPool(metadata externalScalerMetadata, logger logr.Log
 			if err != nil {
 				return nil, err
 			}
-			return grpc.Dial(metadata.scalerAddress, grpc.WithTransportCredentials(creds))
+			return grpc.Dial(metadata.scalerAddress, append(opts, grpc.WithTransportCredentials(creds))...)
 		}
 
 		tlsConfig, err := util.NewTLSConfig(metadata.tlsClientCert, metadata.tlsClientKey, metadata.caCert, metadata.unsafeSsl)
@@ -319,17 +342,25 @@ func getClientForConnectionPool(metadata externalScalerMetadata, logger logr.Log
 			return nil, err
 		}
 
-		if len(tlsConfig.Certificates) > 0 || metadata.caCert != "" {
+		if err := util.PinServerPublicKey(tlsConfig, metadata.serverPublicKeyPin); err != nil {
+			return nil, err
+		}
+
+		if len(tlsConfig.Certificates) > 0 || metadata.caCert != "" || metadata.serverPublicKeyPin != "" {
 			// nosemgrep: go.grpc.ssrf.grpc-tainted-url-host.grpc-tainted-url-host
-			return grpc.Dial(metadata.scalerAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			return grpc.Dial(metadata.scalerAddress, append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))...)
 		}
 
-		return grpc.Dial(metadata.scalerAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		return grpc.Dial(metadata.scalerAddress, append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
 	}
 
 	// create a unique key per-metadata. If scaledObjects share the same connection properties
 	// in the metadata, they will share the same grpc.ClientConn
-	key, err := hashstructure.Hash(metadata.scalerAddress, nil)
+	proxy := ""
+	if metadata.proxy != nil {
+		proxy = metadata.proxy.String()
+	}
+	key, err := hashstructure.Hash([]string{metadata.scalerAddress, proxy, metadata.serverPublicKeyPin}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -365,6 +396,25 @@ func getClientForConnectionPool(metadata externalScalerMetadata, logger logr.Log
 	return pb.NewExternalScalerClient(connGroup.grpcConnection), nil
 }
 
+// externalScalerRPCUnaryInterceptor counts the calls to the external scaler at address by method and status
+func externalScalerRPCUnaryInterceptor(address string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		prommetrics.RecordExternalScalerRPC(address, path.Base(method), status.Code(err).String())
+		return err
+	}
+}
+
+// externalScalerRPCStreamInterceptor counts the streams opened to the external scaler at address, the status is the
+// one of the opening of the stream
+func externalScalerRPCStreamInterceptor(address string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		prommetrics.RecordExternalScalerRPC(address, path.Base(method), status.Code(err).String())
+		return stream, err
+	}
+}
+
 func waitForState(ctx context.Context, conn *grpc.ClientConn, states ...connectivity.State) (done chan struct{}) {
 	done = make(chan struct{})
 
@@ -2,7 +2,13 @@ package scalers
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"math/big"
 	"net"
 	"sync/atomic"
 	"testing"
@@ -11,10 +17,13 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	pb "github.com/kedacore/keda/v2/pkg/scalers/externalscaler"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 var serverRootCA = `-----BEGIN CERTIFICATE-----
@@ -293,3 +302,232 @@ func TestWaitForState(t *testing.T) {
 		t.Error("waitForState should be get connectivity.Shutdown.")
 	}
 }
+
+func TestExternalScalerThroughProxy(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	activeCh := make(chan bool)
+	pb.RegisterExternalScalerServer(grpcServer, &testExternalScaler{t: t, active: activeCh})
+	go func() { _ = grpcServer.Serve(lis) }()
+	defer grpcServer.Stop()
+	proxy := newTestProxy(t, lis.Addr().String())
+
+	config := &ScalerConfig{
+		ScalableObjectName:      "app",
+		ScalableObjectNamespace: "namespace",
+		TriggerMetadata:         map[string]string{"scalerAddress": "external-scaler.example.com:6000", "proxy": proxy.server.URL},
+		ResolvedEnv:             map[string]string{},
+	}
+	httpProxy, err := GetHTTPProxy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.HTTPProxy = httpProxy
+
+	pushScaler, err := NewExternalPushScaler(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	active := make(chan bool)
+	go pushScaler.Run(ctx, active)
+
+	go func() { activeCh <- true }()
+	select {
+	case result := <-active:
+		if !result {
+			t.Error("Expected active result from the external scaler")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the external scaler through the proxy")
+	}
+
+	hosts := proxy.recordedHosts()
+	if len(hosts) == 0 || hosts[0] != "external-scaler.example.com:6000" {
+		t.Errorf("Expected CONNECT to external-scaler.example.com:6000 but got %v", hosts)
+	}
+}
+
+type countingExternalScaler struct {
+	testExternalScaler
+
+	getMetricsCalls atomic.Int32
+}
+
+func (e *countingExternalScaler) IsActive(context.Context, *pb.ScaledObjectRef) (*pb.IsActiveResponse, error) {
+	return &pb.IsActiveResponse{Result: true}, nil
+}
+
+func (e *countingExternalScaler) GetMetricSpec(context.Context, *pb.ScaledObjectRef) (*pb.GetMetricSpecResponse, error) {
+	return &pb.GetMetricSpecResponse{MetricSpecs: []*pb.MetricSpec{{MetricName: "queue", TargetSize: 10}}}, nil
+}
+
+func (e *countingExternalScaler) GetMetrics(context.Context, *pb.GetMetricsRequest) (*pb.GetMetricsResponse, error) {
+	// the first call fails
+	if e.getMetricsCalls.Add(1) == 1 {
+		return nil, status.Errorf(codes.Unavailable, "backend not ready")
+	}
+	return &pb.GetMetricsResponse{MetricValues: []*pb.MetricValue{{MetricName: "queue", MetricValue: 5}}}, nil
+}
+
+func TestExternalScalerRPCMetrics(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	activeCh := make(chan bool)
+	pb.RegisterExternalScalerServer(grpcServer, &countingExternalScaler{testExternalScaler: testExternalScaler{t: t, active: activeCh}})
+	go func() { _ = grpcServer.Serve(lis) }()
+	defer grpcServer.Stop()
+	address := lis.Addr().String()
+
+	config := &ScalerConfig{
+		ScalableObjectName:      "app",
+		ScalableObjectNamespace: "namespace",
+		TriggerMetadata:         map[string]string{"scalerAddress": address},
+		ResolvedEnv:             map[string]string{},
+	}
+	scaler, err := NewExternalScaler(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metricSpecs := scaler.GetMetricSpecForScaling(context.Background())
+	if len(metricSpecs) != 1 {
+		t.Fatalf("Expected 1 metric spec but got %d", len(metricSpecs))
+	}
+	metricName := metricSpecs[0].External.Metric.Name
+	if _, _, err := scaler.GetMetricsAndActivity(context.Background(), metricName); err == nil {
+		t.Error("Expected an error from the first GetMetrics call")
+	}
+	if _, active, err := scaler.GetMetricsAndActivity(context.Background(), metricName); err != nil || !active {
+		t.Errorf("Expected an active result but got %t and %v", active, err)
+	}
+
+	pushScaler, err := NewExternalPushScaler(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	active := make(chan bool)
+	go pushScaler.Run(ctx, active)
+	go func() { activeCh <- true }()
+	select {
+	case <-active:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for the external push scaler")
+	}
+
+	expected := []struct {
+		method string
+		status string
+		count  float64
+	}{
+		{"GetMetricSpec", "OK", 1},
+		{"GetMetrics", "Unavailable", 1},
+		{"GetMetrics", "OK", 1},
+		{"IsActive", "OK", 1},
+		{"IsActive", "Unavailable", 0},
+		{"StreamIsActive", "OK", 1},
+	}
+	for _, e := range expected {
+		if count := getExternalScalerRPCCount(t, address, e.method, e.status); count != e.count {
+			t.Errorf("Expected %v %s calls with status %s but got %v", e.count, e.method, e.status, count)
+		}
+	}
+}
+
+func getExternalScalerRPCCount(t *testing.T, address, method, status string) float64 {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "keda_external_scaler_rpc_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["address"] == address && labels["method"] == method && labels["status"] == status {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+// newTestTLSExternalScalerServer serves an external scaler with a self-signed certificate and returns its address and
+// the pin of its key
+func newTestTLSExternalScalerServer(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewServerTLSFromCert(&tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key})))
+	pb.RegisterExternalScalerServer(grpcServer, &countingExternalScaler{testExternalScaler: testExternalScaler{t: t}})
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+	return lis.Addr().String(), kedautil.GetServerPublicKeyPin(cert)
+}
+
+func TestExternalScalerServerPublicKeyPin(t *testing.T) {
+	address, pin := newTestTLSExternalScalerServer(t)
+	_, otherPin := newTestTLSExternalScalerServer(t)
+
+	for _, test := range []struct {
+		pin             string
+		expectedMetrics int
+	}{
+		{pin, 1},
+		{otherPin + "," + pin, 1},
+		{otherPin, 0},
+	} {
+		scaler, err := NewExternalScaler(&ScalerConfig{
+			ScalableObjectName:      "app",
+			ScalableObjectNamespace: "namespace",
+			TriggerMetadata:         map[string]string{"scalerAddress": address},
+			AuthParams:              map[string]string{"tlsServerPublicKeyPin": test.pin},
+			ResolvedEnv:             map[string]string{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		metricSpecs := scaler.GetMetricSpecForScaling(ctx)
+		cancel()
+		if len(metricSpecs) != test.expectedMetrics {
+			t.Errorf("Expected %d metric specs with the pin %s but got %d", test.expectedMetrics, test.pin, len(metricSpecs))
+		}
+	}
+
+	_, err := NewExternalScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"scalerAddress": address, "tlsServerPublicKeyPin": "not a pin"},
+		ResolvedEnv:     map[string]string{},
+	})
+	if err == nil {
+		t.Error("Expected an error for an invalid tlsServerPublicKeyPin")
+	}
+}
@@ -0,0 +1,199 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/mitchellh/hashstructure"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+const (
+	bigQueryEndpoint = "https://bigquery.googleapis.com/bigquery/v2"
+	bigQueryScope    = "https://www.googleapis.com/auth/bigquery.readonly"
+)
+
+// bigQueryClient runs the queries of the bigquery scalers of a GCP project, it's the jobs.query method of the
+// BigQuery REST API
+type bigQueryClient interface {
+	Query(ctx context.Context, request *bigQueryQueryRequest) (*bigQueryQueryResponse, error)
+}
+
+// bigQueryQueryRequest is the body of jobs.query, only standard SQL queries are run
+type bigQueryQueryRequest struct {
+	Query              string `json:"query"`
+	UseLegacySQL       bool   `json:"useLegacySql"`
+	Location           string `json:"location,omitempty"`
+	MaximumBytesBilled string `json:"maximumBytesBilled,omitempty"`
+	TimeoutMs          int64  `json:"timeoutMs,omitempty"`
+}
+
+// bigQueryQueryResponse is the response of jobs.query, the job isn't complete when it didn't end within timeoutMs
+type bigQueryQueryResponse struct {
+	JobComplete bool                 `json:"jobComplete"`
+	Schema      bigQueryTableSchema  `json:"schema"`
+	Rows        []bigQueryTableRow   `json:"rows"`
+	Errors      []bigQueryErrorProto `json:"errors"`
+}
+
+type bigQueryTableSchema struct {
+	Fields []bigQueryTableField `json:"fields"`
+}
+
+type bigQueryTableField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type bigQueryTableRow struct {
+	F []bigQueryTableCell `json:"f"`
+}
+
+// bigQueryTableCell is a cell of the result, all the values are returned as strings and NULL as nil
+type bigQueryTableCell struct {
+	V *string `json:"v"`
+}
+
+type bigQueryErrorProto struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// bigQueryError is an error returned by BigQuery, the reason tells why the query failed,
+// e.g. bytesBilledLimitExceeded when the query would bill more than maximumBytesBilled
+type bigQueryError struct {
+	Reason  string
+	Message string
+}
+
+func (e *bigQueryError) Error() string {
+	return fmt.Sprintf("bigquery error %s: %s", e.Reason, e.Message)
+}
+
+// bigQueryRESTClient calls the BigQuery REST API with the http client authenticated for the project
+type bigQueryRESTClient struct {
+	endpoint   string
+	projectID  string
+	httpClient *http.Client
+}
+
+func (c *bigQueryRESTClient) Query(ctx context.Context, request *bigQueryQueryRequest) (*bigQueryQueryResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	queryURL := fmt.Sprintf("%s/projects/%s/queries", c.endpoint, url.PathEscape(c.projectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, queryURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, wrapHTTPRequestError(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Error struct {
+				Message string               `json:"message"`
+				Errors  []bigQueryErrorProto `json:"errors"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(data, &errorResponse); err != nil || errorResponse.Error.Message == "" {
+			return nil, wrapHTTPStatusError(resp.StatusCode, fmt.Errorf("bigquery returned %s: %s", resp.Status, string(data)))
+		}
+		reason := strconv.Itoa(resp.StatusCode)
+		if len(errorResponse.Error.Errors) > 0 && errorResponse.Error.Errors[0].Reason != "" {
+			reason = errorResponse.Error.Errors[0].Reason
+		}
+		return nil, wrapHTTPStatusError(resp.StatusCode, &bigQueryError{Reason: reason, Message: errorResponse.Error.Message})
+	}
+
+	response := &bigQueryQueryResponse{}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("error parsing the bigquery response: %w", err)
+	}
+	return response, nil
+}
+
+// newBigQueryClient creates the client of the project, authenticated with the service account JSON or file of the
+// trigger or with the workload identity of KEDA
+var newBigQueryClient = func(ctx context.Context, projectID string, gcpAuthorization *gcpAuthorizationMetadata) (bigQueryClient, error) {
+	opts := []option.ClientOption{option.WithScopes(bigQueryScope)}
+	switch {
+	case gcpAuthorization.podIdentityProviderEnabled:
+	case gcpAuthorization.GoogleApplicationCredentialsFile != "":
+		opts = append(opts, option.WithCredentialsFile(gcpAuthorization.GoogleApplicationCredentialsFile))
+	default:
+		opts = append(opts, option.WithCredentialsJSON([]byte(gcpAuthorization.GoogleApplicationCredentials)))
+	}
+	httpClient, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &bigQueryRESTClient{endpoint: bigQueryEndpoint, projectID: projectID, httpClient: httpClient}, nil
+}
+
+// pooledBigQueryClient is a client shared by the triggers querying the same project with the same credentials
+type pooledBigQueryClient struct {
+	key    uint64
+	refs   int
+	client bigQueryClient
+}
+
+var (
+	bigQueryClientPool     = map[uint64]*pooledBigQueryClient{}
+	bigQueryClientPoolLock sync.Mutex
+)
+
+// acquireBigQueryClient returns the pooled client of the project, it's created if no other trigger uses it.
+// The client must be given back with releaseBigQueryClient
+func acquireBigQueryClient(ctx context.Context, projectID string, gcpAuthorization *gcpAuthorizationMetadata) (*pooledBigQueryClient, error) {
+	key, err := hashstructure.Hash([]string{projectID, gcpAuthorization.GoogleApplicationCredentials, gcpAuthorization.GoogleApplicationCredentialsFile,
+		strconv.FormatBool(gcpAuthorization.podIdentityProviderEnabled)}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	bigQueryClientPoolLock.Lock()
+	defer bigQueryClientPoolLock.Unlock()
+
+	if pooled, found := bigQueryClientPool[key]; found {
+		pooled.refs++
+		return pooled, nil
+	}
+
+	client, err := newBigQueryClient(ctx, projectID, gcpAuthorization)
+	if err != nil {
+		return nil, err
+	}
+	pooled := &pooledBigQueryClient{key: key, refs: 1, client: client}
+	bigQueryClientPool[key] = pooled
+	return pooled, nil
+}
+
+// releaseBigQueryClient gives back a client returned by acquireBigQueryClient, it's dropped once no trigger uses it
+func releaseBigQueryClient(pooled *pooledBigQueryClient) {
+	bigQueryClientPoolLock.Lock()
+	defer bigQueryClientPoolLock.Unlock()
+
+	pooled.refs--
+	if pooled.refs <= 0 {
+		delete(bigQueryClientPool, pooled.key)
+	}
+}
@@ -0,0 +1,219 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+type bigQueryScaler struct {
+	client     *pooledBigQueryClient
+	metricType v2.MetricTargetType
+	metadata   *bigQueryMetadata
+	logger     logr.Logger
+}
+
+type bigQueryMetadata struct {
+	projectID          string
+	query              string
+	location           string
+	maximumBytesBilled int64
+	targetValue        float64
+	activationValue    float64
+	timeout            time.Duration
+	metricName         string
+
+	gcpAuthorization *gcpAuthorizationMetadata
+}
+
+// bigQueryNumericTypes are the types of the column the query can return
+var bigQueryNumericTypes = map[string]bool{
+	"INTEGER":    true,
+	"INT64":      true,
+	"FLOAT":      true,
+	"FLOAT64":    true,
+	"NUMERIC":    true,
+	"BIGNUMERIC": true,
+}
+
+// NewBigQueryScaler creates a new scaler for the result of a BigQuery query, the client is shared by the triggers
+// querying the same project with the same credentials
+func NewBigQueryScaler(ctx context.Context, config *ScalerConfig) (Scaler, error) {
+	metricType, err := GetMetricTargetType(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scaler metric type: %w", err)
+	}
+
+	meta, err := parseBigQueryMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing BigQuery metadata: %w", err)
+	}
+
+	client, err := acquireBigQueryClient(ctx, meta.projectID, meta.gcpAuthorization)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the BigQuery client: %w", err)
+	}
+
+	return &bigQueryScaler{
+		client:     client,
+		metricType: metricType,
+		metadata:   meta,
+		logger:     InitializeLogger(config, "gcp_bigquery_scaler"),
+	}, nil
+}
+
+func parseBigQueryMetadata(config *ScalerConfig) (*bigQueryMetadata, error) {
+	meta := bigQueryMetadata{}
+
+	if val, ok := config.TriggerMetadata["projectID"]; ok && val != "" {
+		meta.projectID = val
+	} else {
+		return nil, fmt.Errorf("no projectID given")
+	}
+
+	if val, ok := config.TriggerMetadata["query"]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no query given")
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error: %w", err)
+		}
+		if targetValue <= 0 {
+			return nil, fmt.Errorf("targetValue must be greater than 0, got %s", val)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	meta.activationValue = 0
+	if val, ok := config.TriggerMetadata["activationValue"]; ok && val != "" {
+		activationValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationValue parsing error: %w", err)
+		}
+		meta.activationValue = activationValue
+	}
+
+	meta.location = config.TriggerMetadata["location"]
+
+	if val, ok := config.TriggerMetadata["maximumBytesBilled"]; ok && val != "" {
+		maximumBytesBilled, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || maximumBytesBilled <= 0 {
+			return nil, fmt.Errorf("maximumBytesBilled must be a number of bytes greater than 0, got %s", val)
+		}
+		meta.maximumBytesBilled = maximumBytesBilled
+	}
+
+	meta.timeout = config.GlobalHTTPTimeout
+	if val, ok := config.TriggerMetadata["timeout"]; ok && val != "" {
+		timeoutMS, err := strconv.Atoi(val)
+		if err != nil || timeoutMS <= 0 {
+			return nil, fmt.Errorf("timeout must be a number of milliseconds greater than 0, got %s", val)
+		}
+		meta.timeout = time.Duration(timeoutMS) * time.Millisecond
+	}
+
+	auth, err := getGcpAuthorization(config, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+	meta.gcpAuthorization = auth
+
+	meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("gcp-bigquery-%s", meta.projectID)))
+	return &meta, nil
+}
+
+// Close gives the shared client back
+func (s *bigQueryScaler) Close(context.Context) error {
+	if s.client != nil {
+		releaseBigQueryClient(s.client)
+		s.client = nil
+	}
+	return nil
+}
+
+// GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
+func (s *bigQueryScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	externalMetric := &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{
+			Name: s.metadata.metricName,
+		},
+		Target: GetMetricTargetMili(s.metricType, s.metadata.targetValue),
+	}
+	metricSpec := v2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2.MetricSpec{metricSpec}
+}
+
+// GetMetricsAndActivity runs the query and returns its result and whether it's above the activation value
+func (s *bigQueryScaler) GetMetricsAndActivity(ctx context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, false, fmt.Errorf("error running the BigQuery query of project %s: %w", s.metadata.projectID, err)
+	}
+
+	metric := GenerateMetricInMili(metricName, value)
+
+	return []external_metrics.ExternalMetricValue{metric}, value > s.metadata.activationValue, nil
+}
+
+// getQueryResult runs the query within the timeout, its result must be a single numeric cell
+func (s *bigQueryScaler) getQueryResult(ctx context.Context) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.metadata.timeout)
+	defer cancel()
+
+	request := &bigQueryQueryRequest{
+		Query:     s.metadata.query,
+		Location:  s.metadata.location,
+		TimeoutMs: s.metadata.timeout.Milliseconds(),
+	}
+	if s.metadata.maximumBytesBilled > 0 {
+		request.MaximumBytesBilled = strconv.FormatInt(s.metadata.maximumBytesBilled, 10)
+	}
+
+	response, err := s.client.client.Query(ctx, request)
+	if err != nil {
+		return 0, err
+	}
+	if len(response.Errors) > 0 {
+		return 0, WrapScalerError(ErrBackend, &bigQueryError{Reason: response.Errors[0].Reason, Message: response.Errors[0].Message})
+	}
+	if !response.JobComplete {
+		return 0, WrapScalerError(ErrTimeout, fmt.Errorf("the query didn't complete within %s", s.metadata.timeout))
+	}
+
+	if len(response.Schema.Fields) != 1 {
+		return 0, WrapScalerError(ErrConfig, fmt.Errorf("the query must return a single column, got %d", len(response.Schema.Fields)))
+	}
+	field := response.Schema.Fields[0]
+	if !bigQueryNumericTypes[field.Type] {
+		return 0, WrapScalerError(ErrConfig, fmt.Errorf("the column %s of the query result must be numeric, got %s", field.Name, field.Type))
+	}
+	if len(response.Rows) != 1 || len(response.Rows[0].F) != 1 {
+		return 0, WrapScalerError(ErrConfig, fmt.Errorf("the query must return a single row, got %d", len(response.Rows)))
+	}
+	cell := response.Rows[0].F[0].V
+	if cell == nil {
+		return 0, WrapScalerError(ErrConfig, fmt.Errorf("the column %s of the query result is NULL", field.Name))
+	}
+
+	value, err := strconv.ParseFloat(*cell, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing the query result %s: %w", *cell, err)
+	}
+	s.logger.V(1).Info("BigQuery query result", "projectID", s.metadata.projectID, "value", value)
+	return value, nil
+}
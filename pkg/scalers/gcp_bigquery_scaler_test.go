@@ -0,0 +1,257 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v2 "k8s.io/api/autoscaling/v2"
+)
+
+var testBigQueryResolvedEnv = map[string]string{
+	"SAMPLE_CREDS": "{}",
+}
+
+const testBigQueryQuery = "SELECT COUNT(*) FROM billing.pending_invoices"
+
+type parseBigQueryMetadataTestData struct {
+	name       string
+	authParams map[string]string
+	metadata   map[string]string
+	isError    bool
+}
+
+var testBigQueryMetadata = []parseBigQueryMetadataTestData{
+	{"nothing passed", nil, map[string]string{}, true},
+	{"all properly formed", nil, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "targetValue": "100", "activationValue": "10", "location": "EU", "maximumBytesBilled": "1000000", "timeout": "5000", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	{"all required properly formed", nil, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	{"credentials from AuthParams", map[string]string{"GoogleApplicationCredentials": "{}"}, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "targetValue": "0.5"}, false},
+	{"missing projectID", nil, map[string]string{"query": testBigQueryQuery, "targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	{"missing query", nil, map[string]string{"projectID": "billing", "targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	{"missing targetValue", nil, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	{"zero targetValue", nil, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "targetValue": "0", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	{"malformed activationValue", nil, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "targetValue": "100", "activationValue": "a", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	{"negative maximumBytesBilled", nil, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "targetValue": "100", "maximumBytesBilled": "-1", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	{"malformed timeout", nil, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "targetValue": "100", "timeout": "1s", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	{"missing credentials", nil, map[string]string{"projectID": "billing", "query": testBigQueryQuery, "targetValue": "100"}, true},
+}
+
+func TestBigQueryParseMetadata(t *testing.T) {
+	for _, testData := range testBigQueryMetadata {
+		t.Run(testData.name, func(t *testing.T) {
+			_, err := parseBigQueryMetadata(&ScalerConfig{AuthParams: testData.authParams, TriggerMetadata: testData.metadata, ResolvedEnv: testBigQueryResolvedEnv, GlobalHTTPTimeout: time.Second})
+			if err != nil && !testData.isError {
+				t.Error("Expected success but got error", err)
+			}
+			if testData.isError && err == nil {
+				t.Error("Expected error but got success")
+			}
+		})
+	}
+}
+
+func TestBigQueryGetMetricSpecForScaling(t *testing.T) {
+	meta, err := parseBigQueryMetadata(&ScalerConfig{TriggerMetadata: testBigQueryMetadata[1].metadata, ResolvedEnv: testBigQueryResolvedEnv, ScalerIndex: 1})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := bigQueryScaler{metadata: meta, metricType: v2.AverageValueMetricType}
+
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+	assert.Equal(t, "s1-gcp-bigquery-billing", metricSpec[0].External.Metric.Name)
+	assert.Equal(t, int64(100000), metricSpec[0].External.Target.AverageValue.MilliValue())
+}
+
+// mockBigQueryClient returns the response or the error of the test and records the last request
+type mockBigQueryClient struct {
+	response *bigQueryQueryResponse
+	err      error
+	request  *bigQueryQueryRequest
+	deadline bool
+}
+
+func (c *mockBigQueryClient) Query(ctx context.Context, request *bigQueryQueryRequest) (*bigQueryQueryResponse, error) {
+	c.request = request
+	_, c.deadline = ctx.Deadline()
+	return c.response, c.err
+}
+
+// newBigQueryResponse returns the response of a complete query with the cells of a single column
+func newBigQueryResponse(columnType string, cells ...*string) *bigQueryQueryResponse {
+	response := &bigQueryQueryResponse{
+		JobComplete: true,
+		Schema:      bigQueryTableSchema{Fields: []bigQueryTableField{{Name: "f0_", Type: columnType}}},
+	}
+	for _, cell := range cells {
+		response.Rows = append(response.Rows, bigQueryTableRow{F: []bigQueryTableCell{{V: cell}}})
+	}
+	return response
+}
+
+func bigQueryCell(value string) *string {
+	return &value
+}
+
+func TestBigQueryGetMetricsAndActivity(t *testing.T) {
+	twoColumns := newBigQueryResponse("INTEGER", bigQueryCell("1"))
+	twoColumns.Schema.Fields = append(twoColumns.Schema.Fields, twoColumns.Schema.Fields[0])
+	incomplete := newBigQueryResponse("INTEGER")
+	incomplete.JobComplete = false
+	withErrors := newBigQueryResponse("INTEGER")
+	withErrors.Errors = []bigQueryErrorProto{{Reason: "invalidQuery", Message: "Unrecognized name: pending"}}
+
+	tests := []struct {
+		name          string
+		response      *bigQueryQueryResponse
+		err           error
+		expectedValue int64
+		isActive      bool
+		expectedError string
+		errorKind     error
+	}{
+		{name: "integer", response: newBigQueryResponse("INTEGER", bigQueryCell("250")), expectedValue: 250000, isActive: true},
+		{name: "float", response: newBigQueryResponse("FLOAT64", bigQueryCell("2.5")), expectedValue: 2500},
+		{name: "numeric", response: newBigQueryResponse("NUMERIC", bigQueryCell("12")), expectedValue: 12000, isActive: true},
+		{name: "no rows", response: newBigQueryResponse("INTEGER"), expectedError: "single row", errorKind: ErrConfig},
+		{name: "several rows", response: newBigQueryResponse("INTEGER", bigQueryCell("1"), bigQueryCell("2")), expectedError: "single row", errorKind: ErrConfig},
+		{name: "several columns", response: twoColumns, expectedError: "single column", errorKind: ErrConfig},
+		{name: "string column", response: newBigQueryResponse("STRING", bigQueryCell("12")), expectedError: "must be numeric, got STRING", errorKind: ErrConfig},
+		{name: "null", response: newBigQueryResponse("INTEGER", nil), expectedError: "NULL", errorKind: ErrConfig},
+		{name: "incomplete", response: incomplete, expectedError: "didn't complete", errorKind: ErrTimeout},
+		{name: "errors in response", response: withErrors, expectedError: "invalidQuery", errorKind: ErrBackend},
+		{name: "bytes billed limit exceeded", err: &bigQueryError{Reason: "bytesBilledLimitExceeded", Message: "Query exceeded limit for bytes billed: 1000000."}, expectedError: "bytesBilledLimitExceeded"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &mockBigQueryClient{response: test.response, err: test.err}
+			scaler := bigQueryScaler{
+				client: &pooledBigQueryClient{client: client},
+				metadata: &bigQueryMetadata{
+					projectID:          "billing",
+					query:              testBigQueryQuery,
+					location:           "EU",
+					maximumBytesBilled: 1000000,
+					activationValue:    10,
+					timeout:            time.Second,
+				},
+				logger: logr.Discard(),
+			}
+
+			metrics, active, err := scaler.GetMetricsAndActivity(context.Background(), "s0-gcp-bigquery-billing")
+			assert.Equal(t, &bigQueryQueryRequest{Query: testBigQueryQuery, Location: "EU", MaximumBytesBilled: "1000000", TimeoutMs: 1000}, client.request)
+			assert.True(t, client.deadline, "the query must be run with the per-poll timeout")
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				if test.errorKind != nil {
+					assert.ErrorIs(t, err, test.errorKind)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, metrics[0].Value.MilliValue())
+			assert.Equal(t, test.isActive, active)
+		})
+	}
+}
+
+func TestBigQueryRESTClientQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		expectedValue string
+		expectedError string
+		errorKind     error
+	}{
+		{
+			name:          "result",
+			status:        http.StatusOK,
+			body:          `{"jobComplete":true,"schema":{"fields":[{"name":"f0_","type":"INTEGER"}]},"rows":[{"f":[{"v":"42"}]}]}`,
+			expectedValue: "42",
+		},
+		{
+			name:          "bytes billed limit exceeded",
+			status:        http.StatusBadRequest,
+			body:          `{"error":{"code":400,"message":"Query exceeded limit for bytes billed: 1000000.","errors":[{"reason":"bytesBilledLimitExceeded","message":"Query exceeded limit for bytes billed: 1000000."}]}}`,
+			expectedError: "bigquery error bytesBilledLimitExceeded",
+			errorKind:     ErrBackend,
+		},
+		{
+			name:          "access denied",
+			status:        http.StatusForbidden,
+			body:          `{"error":{"code":403,"message":"Access Denied: Project billing","errors":[{"reason":"accessDenied","message":"Access Denied: Project billing"}]}}`,
+			expectedError: "bigquery error accessDenied",
+			errorKind:     ErrAuth,
+		},
+		{
+			name:          "not a bigquery error",
+			status:        http.StatusBadGateway,
+			body:          `bad gateway`,
+			expectedError: "bad gateway",
+			errorKind:     ErrBackend,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var request bigQueryQueryRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/projects/billing/queries", r.URL.Path)
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			client := &bigQueryRESTClient{endpoint: server.URL, projectID: "billing", httpClient: server.Client()}
+			response, err := client.Query(context.Background(), &bigQueryQueryRequest{Query: testBigQueryQuery, MaximumBytesBilled: "1000000"})
+			assert.Equal(t, testBigQueryQuery, request.Query)
+			assert.Equal(t, "1000000", request.MaximumBytesBilled)
+			assert.False(t, request.UseLegacySQL)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				assert.ErrorIs(t, err, test.errorKind)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedValue, *response.Rows[0].F[0].V)
+		})
+	}
+}
+
+func TestBigQueryClientSharedByProject(t *testing.T) {
+	created := 0
+	oldNewBigQueryClient := newBigQueryClient
+	newBigQueryClient = func(context.Context, string, *gcpAuthorizationMetadata) (bigQueryClient, error) {
+		created++
+		return &mockBigQueryClient{}, nil
+	}
+	defer func() { newBigQueryClient = oldNewBigQueryClient }()
+
+	auth := &gcpAuthorizationMetadata{GoogleApplicationCredentials: "{}"}
+	first, err := acquireBigQueryClient(context.Background(), "billing", auth)
+	assert.NoError(t, err)
+	second, err := acquireBigQueryClient(context.Background(), "billing", auth)
+	assert.NoError(t, err)
+	other, err := acquireBigQueryClient(context.Background(), "analytics", auth)
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, 2, created)
+
+	releaseBigQueryClient(first)
+	releaseBigQueryClient(other)
+	third, err := acquireBigQueryClient(context.Background(), "billing", auth)
+	assert.NoError(t, err)
+	assert.Same(t, second, third, "the client is kept while a trigger still uses it")
+
+	releaseBigQueryClient(second)
+	releaseBigQueryClient(third)
+	assert.Empty(t, bigQueryClientPool)
+}
@@ -70,7 +70,7 @@ func NewGraphiteScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing graphite metadata: %w", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy)
 
 	return &graphiteScaler{
 		metricType: metricType,
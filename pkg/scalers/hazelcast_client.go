@@ -0,0 +1,389 @@
+package scalers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The subset of the Hazelcast open binary client protocol used by the hazelcast scaler: the authentication, the
+// size of a queue and the size of a map. A message is a list of frames, every frame starts with its length and
+// flags. The first frame holds the message type, the correlation ID, the partition ID and the fixed size parameters,
+// each variable size parameter follows in its own frame. The e2e test in tests/scalers/hazelcast checks it against
+// a real member.
+//
+// The scaler doesn't use github.com/hazelcast/hazelcast-go-client: a client of it joins the cluster, keeps a
+// connection to every member, syncs the partition table and runs the heartbeats and the listeners of the cluster
+// in its own goroutines, for every ScaledObject, while the scaler only needs two size requests over one connection.
+const (
+	hazelcastProtocolHeader       = "CP2"
+	hazelcastClientType           = "GOO"
+	hazelcastClientVersion        = "5.0"
+	hazelcastSerializationVersion = 1
+
+	hazelcastErrorResponseType  = 0x000000
+	hazelcastAuthenticationType = 0x000100
+	hazelcastMapSizeType        = 0x012A00
+	hazelcastQueueSizeType      = 0x030300
+
+	hazelcastFrameHeaderSize = 6
+	hazelcastUUIDSize        = 17
+
+	hazelcastRequestHeaderSize  = 16
+	hazelcastResponseHeaderSize = 13
+
+	hazelcastBeginFragmentFlag      uint16 = 1 << 15
+	hazelcastEndFragmentFlag        uint16 = 1 << 14
+	hazelcastFinalFlag              uint16 = 1 << 13
+	hazelcastBeginDataStructureFlag uint16 = 1 << 12
+	hazelcastEndDataStructureFlag   uint16 = 1 << 11
+	hazelcastNullFlag               uint16 = 1 << 10
+	hazelcastEventFlag              uint16 = 1 << 9
+
+	hazelcastAuthenticated     = 0
+	hazelcastCredentialsFailed = 1
+	hazelcastPartitionHashSeed = 0x01000193
+	hazelcastMaxFrameLength    = 64 << 20
+	hazelcastAnyPartition      = -1
+)
+
+// errHazelcastClusterUnreachable is the error of a request whose connection to the cluster failed, the client
+// can't be used anymore and has to be created again
+var errHazelcastClusterUnreachable = errors.New("hazelcast cluster unreachable")
+
+type hazelcastFrame struct {
+	flags   uint16
+	content []byte
+}
+
+type hazelcastClientConfig struct {
+	addresses   []string
+	clusterName string
+	username    string
+	password    string
+	tlsConfig   *tls.Config
+	timeout     time.Duration
+}
+
+// hazelcastMemberClient is a connection to a member of the cluster, the member forwards the requests to the owners
+// of the partitions. The requests are sent one at a time
+type hazelcastMemberClient struct {
+	lock           sync.Mutex
+	conn           net.Conn
+	timeout        time.Duration
+	partitionCount int32
+	correlationID  int64
+	broken         bool
+}
+
+// newHazelcastMemberClient connects and authenticates to the first reachable address of the cluster
+func newHazelcastMemberClient(ctx context.Context, config hazelcastClientConfig) (*hazelcastMemberClient, error) {
+	var errs []error
+	for _, address := range config.addresses {
+		client, err := connectHazelcastMember(ctx, config, address)
+		if err == nil {
+			return client, nil
+		}
+		if !errors.Is(err, errHazelcastClusterUnreachable) {
+			return nil, err
+		}
+		errs = append(errs, err)
+	}
+	return nil, WrapScalerError(ErrBackend, errors.Join(errs...))
+}
+
+func connectHazelcastMember(ctx context.Context, config hazelcastClientConfig, address string) (*hazelcastMemberClient, error) {
+	dialer := net.Dialer{Timeout: config.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error connecting to %s: %v", errHazelcastClusterUnreachable, address, err)
+	}
+	if config.tlsConfig != nil {
+		tlsConfig := config.tlsConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(address)
+		}
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client := &hazelcastMemberClient{conn: conn, timeout: config.timeout}
+	if err := client.authenticate(ctx, config); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func (c *hazelcastMemberClient) authenticate(ctx context.Context, config hazelcastClientConfig) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	clientUUID := make([]byte, 16)
+	if _, err := rand.Read(clientUUID); err != nil {
+		return err
+	}
+	fixed := make([]byte, hazelcastUUIDSize+1)
+	copy(fixed[1:], clientUUID)
+	fixed[hazelcastUUIDSize] = hazelcastSerializationVersion
+
+	frames := []hazelcastFrame{
+		hazelcastStringFrame(config.clusterName),
+		hazelcastNullableStringFrame(config.username),
+		hazelcastNullableStringFrame(config.password),
+		hazelcastStringFrame(hazelcastClientType),
+		hazelcastStringFrame(hazelcastClientVersion),
+		hazelcastStringFrame("keda"),
+		// no labels
+		{flags: hazelcastBeginDataStructureFlag},
+		{flags: hazelcastEndDataStructureFlag},
+	}
+
+	if err := c.setDeadline(ctx); err != nil {
+		return fmt.Errorf("%w: %v", errHazelcastClusterUnreachable, err)
+	}
+	if _, err := c.conn.Write([]byte(hazelcastProtocolHeader)); err != nil {
+		return fmt.Errorf("%w: %v", errHazelcastClusterUnreachable, err)
+	}
+	response, err := c.invoke(hazelcastAuthenticationType, hazelcastAnyPartition, fixed, frames)
+	if err != nil {
+		return err
+	}
+
+	initial := response[0].content
+	if len(initial) < hazelcastResponseHeaderSize+1+hazelcastUUIDSize+1+4 {
+		return fmt.Errorf("invalid authentication response of %d bytes", len(initial))
+	}
+	switch status := initial[hazelcastResponseHeaderSize]; status {
+	case hazelcastAuthenticated:
+	case hazelcastCredentialsFailed:
+		return WrapScalerError(ErrAuth, fmt.Errorf("authentication to the hazelcast cluster %s failed", config.clusterName))
+	default:
+		return fmt.Errorf("authentication to the hazelcast cluster %s failed with status %d", config.clusterName, status)
+	}
+	partitionCountOffset := hazelcastResponseHeaderSize + 1 + hazelcastUUIDSize + 1
+	c.partitionCount = int32(binary.LittleEndian.Uint32(initial[partitionCountOffset:]))
+	if c.partitionCount <= 0 {
+		return fmt.Errorf("invalid partition count %d", c.partitionCount)
+	}
+	return nil
+}
+
+// QueueSize returns the number of items of the queue, the request is sent to the partition of the queue
+func (c *hazelcastMemberClient) QueueSize(ctx context.Context, name string) (int64, error) {
+	return c.size(ctx, hazelcastQueueSizeType, hazelcastPartitionID(name, c.partitionCount), name)
+}
+
+// MapSize returns the number of entries of the map over all its partitions
+func (c *hazelcastMemberClient) MapSize(ctx context.Context, name string) (int64, error) {
+	return c.size(ctx, hazelcastMapSizeType, hazelcastAnyPartition, name)
+}
+
+// Shutdown closes the connection to the cluster
+func (c *hazelcastMemberClient) Shutdown(context.Context) error {
+	return c.conn.Close()
+}
+
+func (c *hazelcastMemberClient) size(ctx context.Context, messageType int32, partitionID int32, name string) (int64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.broken {
+		return 0, fmt.Errorf("%w: the connection was closed after an error", errHazelcastClusterUnreachable)
+	}
+	if err := c.setDeadline(ctx); err != nil {
+		c.broken = true
+		return 0, fmt.Errorf("%w: %v", errHazelcastClusterUnreachable, err)
+	}
+	response, err := c.invoke(messageType, partitionID, nil, []hazelcastFrame{hazelcastStringFrame(name)})
+	if err != nil {
+		return 0, err
+	}
+	initial := response[0].content
+	if len(initial) < hazelcastResponseHeaderSize+4 {
+		return 0, fmt.Errorf("invalid size response of %d bytes", len(initial))
+	}
+	return int64(int32(binary.LittleEndian.Uint32(initial[hazelcastResponseHeaderSize:]))), nil
+}
+
+// setDeadline bounds the next request by the timeout and the deadline of the context
+func (c *hazelcastMemberClient) setDeadline(ctx context.Context) error {
+	var deadline time.Time
+	if c.timeout > 0 {
+		deadline = time.Now().Add(c.timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	return c.conn.SetDeadline(deadline)
+}
+
+// invoke sends a request and returns the frames of its response, the events and the responses of other
+// correlation IDs are skipped. The connection is marked as broken after an I/O error
+func (c *hazelcastMemberClient) invoke(messageType int32, partitionID int32, fixed []byte, frames []hazelcastFrame) ([]hazelcastFrame, error) {
+	c.correlationID++
+	correlationID := c.correlationID
+
+	initial := make([]byte, hazelcastRequestHeaderSize+len(fixed))
+	binary.LittleEndian.PutUint32(initial, uint32(messageType))
+	binary.LittleEndian.PutUint64(initial[4:], uint64(correlationID))
+	binary.LittleEndian.PutUint32(initial[12:], uint32(partitionID))
+	copy(initial[hazelcastRequestHeaderSize:], fixed)
+
+	message := append([]hazelcastFrame{{flags: hazelcastBeginFragmentFlag | hazelcastEndFragmentFlag, content: initial}}, frames...)
+	if err := writeHazelcastMessage(c.conn, message); err != nil {
+		c.broken = true
+		return nil, fmt.Errorf("%w: %v", errHazelcastClusterUnreachable, err)
+	}
+
+	for {
+		response, err := readHazelcastMessage(c.conn)
+		if err != nil {
+			c.broken = true
+			return nil, fmt.Errorf("%w: %v", errHazelcastClusterUnreachable, err)
+		}
+		initial := response[0].content
+		if len(initial) < hazelcastResponseHeaderSize || response[0].flags&hazelcastEventFlag != 0 ||
+			int64(binary.LittleEndian.Uint64(initial[4:])) != correlationID {
+			continue
+		}
+		if int32(binary.LittleEndian.Uint32(initial)) == hazelcastErrorResponseType {
+			return nil, WrapScalerError(ErrBackend, decodeHazelcastError(response))
+		}
+		return response, nil
+	}
+}
+
+func writeHazelcastMessage(w io.Writer, frames []hazelcastFrame) error {
+	var buffer []byte
+	for i, frame := range frames {
+		flags := frame.flags
+		if i == len(frames)-1 {
+			flags |= hazelcastFinalFlag
+		}
+		header := make([]byte, hazelcastFrameHeaderSize)
+		binary.LittleEndian.PutUint32(header, uint32(hazelcastFrameHeaderSize+len(frame.content)))
+		binary.LittleEndian.PutUint16(header[4:], flags)
+		buffer = append(append(buffer, header...), frame.content...)
+	}
+	_, err := w.Write(buffer)
+	return err
+}
+
+func readHazelcastMessage(r io.Reader) ([]hazelcastFrame, error) {
+	var frames []hazelcastFrame
+	header := make([]byte, hazelcastFrameHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := binary.LittleEndian.Uint32(header)
+		if length < hazelcastFrameHeaderSize || length > hazelcastMaxFrameLength {
+			return nil, fmt.Errorf("invalid frame length %d", length)
+		}
+		frame := hazelcastFrame{flags: binary.LittleEndian.Uint16(header[4:]), content: make([]byte, length-hazelcastFrameHeaderSize)}
+		if _, err := io.ReadFull(r, frame.content); err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+		if frame.flags&hazelcastFinalFlag != 0 {
+			return frames, nil
+		}
+	}
+}
+
+// decodeHazelcastError returns the class name and the message of the first error of an error response, made of
+// the initial frame and a list of error holders starting with the error code, the class name and the message
+func decodeHazelcastError(frames []hazelcastFrame) error {
+	if len(frames) < 6 {
+		return fmt.Errorf("hazelcast returned an error")
+	}
+	className := string(frames[4].content)
+	message := ""
+	if frames[5].flags&hazelcastNullFlag == 0 {
+		message = string(frames[5].content)
+	}
+	return fmt.Errorf("hazelcast returned %s: %s", className, message)
+}
+
+func hazelcastStringFrame(value string) hazelcastFrame {
+	return hazelcastFrame{content: []byte(value)}
+}
+
+func hazelcastNullableStringFrame(value string) hazelcastFrame {
+	if value == "" {
+		return hazelcastFrame{flags: hazelcastNullFlag}
+	}
+	return hazelcastStringFrame(value)
+}
+
+// hazelcastPartitionID returns the partition of a data structure, hashing its name serialized as a string like
+// the members do. The part of the name after @ is the partition key when it's given
+func hazelcastPartitionID(name string, partitionCount int32) int32 {
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name = name[i+1:]
+	}
+	// the hash covers the payload of the serialized string, its big endian length and its UTF-8 bytes
+	payload := make([]byte, 4+len(name))
+	binary.BigEndian.PutUint32(payload, uint32(len(name)))
+	copy(payload[4:], name)
+
+	hash := int32(murmur3x86_32(payload, hazelcastPartitionHashSeed))
+	if hash == -1<<31 {
+		return 0
+	}
+	if hash < 0 {
+		hash = -hash
+	}
+	return hash % partitionCount
+}
+
+// murmur3x86_32 is the 32 bit MurmurHash3 of the data
+func murmur3x86_32(data []byte, seed uint32) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+
+	h := seed
+	blocks := len(data) / 4
+	for i := 0; i < blocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[blocks*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
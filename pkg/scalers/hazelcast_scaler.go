@@ -0,0 +1,291 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultHazelcastPort          = "5701"
+	defaultHazelcastClusterName   = "dev"
+	hazelcastStructureTypeQueue   = "queue"
+	hazelcastStructureTypeMap     = "map"
+	defaultHazelcastStructureType = hazelcastStructureTypeQueue
+)
+
+// hazelcastClient reads the size of the data structures of a Hazelcast cluster
+type hazelcastClient interface {
+	QueueSize(ctx context.Context, name string) (int64, error)
+	MapSize(ctx context.Context, name string) (int64, error)
+	Shutdown(ctx context.Context) error
+}
+
+type hazelcastScaler struct {
+	metricType v2.MetricTargetType
+	metadata   *hazelcastMetadata
+	logger     logr.Logger
+
+	// the client is created on the first poll, shared by the next ones and created again when the cluster is unreachable
+	clientLock sync.Mutex
+	client     hazelcastClient
+	newClient  func(ctx context.Context) (hazelcastClient, error)
+}
+
+type hazelcastMetadata struct {
+	addresses             []string
+	clusterName           string
+	structureType         string
+	structureName         string
+	targetValue           int64
+	activationTargetValue int64
+	timeout               time.Duration
+	metricName            string
+
+	// authentication
+	username  string
+	password  string
+	enableTLS bool
+	ca        string
+	cert      string
+	key       string
+	unsafeSsl bool
+
+	// serverPublicKeyPin replaces the verification of the certificate chain of the members when it's set
+	serverPublicKeyPin string
+}
+
+// NewHazelcastScaler creates a new scaler for the size of a queue or a map of a Hazelcast cluster, the connection
+// to the cluster is kept between the polls
+func NewHazelcastScaler(config *ScalerConfig) (Scaler, error) {
+	metricType, err := GetMetricTargetType(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scaler metric type: %w", err)
+	}
+
+	meta, err := parseHazelcastMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing hazelcast metadata: %w", err)
+	}
+
+	clientConfig := hazelcastClientConfig{
+		addresses:   meta.addresses,
+		clusterName: meta.clusterName,
+		username:    meta.username,
+		password:    meta.password,
+		timeout:     meta.timeout,
+	}
+	if meta.enableTLS {
+		clientConfig.tlsConfig, err = kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca, meta.unsafeSsl)
+		if err != nil {
+			return nil, err
+		}
+		if err := kedautil.PinServerPublicKey(clientConfig.tlsConfig, meta.serverPublicKeyPin); err != nil {
+			return nil, err
+		}
+	}
+
+	return &hazelcastScaler{
+		metricType: metricType,
+		metadata:   meta,
+		logger:     InitializeLogger(config, "hazelcast_scaler"),
+		newClient: func(ctx context.Context) (hazelcastClient, error) {
+			return newHazelcastMemberClient(ctx, clientConfig)
+		},
+	}, nil
+}
+
+func parseHazelcastMetadata(config *ScalerConfig) (*hazelcastMetadata, error) {
+	meta := hazelcastMetadata{}
+
+	addresses, err := GetFromAuthOrMeta(config, "addresses")
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range strings.Split(addresses, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, defaultHazelcastPort)
+		}
+		meta.addresses = append(meta.addresses, address)
+	}
+	if len(meta.addresses) == 0 {
+		return nil, fmt.Errorf("no addresses given")
+	}
+
+	meta.clusterName = defaultHazelcastClusterName
+	if val, err := GetFromAuthOrMeta(config, "clusterName"); err == nil {
+		meta.clusterName = val
+	}
+
+	meta.structureType = defaultHazelcastStructureType
+	if val, ok := config.TriggerMetadata["structureType"]; ok && val != "" {
+		switch val {
+		case hazelcastStructureTypeQueue, hazelcastStructureTypeMap:
+			meta.structureType = val
+		default:
+			return nil, fmt.Errorf("structureType must be %s or %s, got %s", hazelcastStructureTypeQueue, hazelcastStructureTypeMap, val)
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["structureName"]; ok && val != "" {
+		meta.structureName = val
+	} else {
+		return nil, fmt.Errorf("no structureName given")
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error: %w", err)
+		}
+		if targetValue <= 0 {
+			return nil, fmt.Errorf("targetValue must be greater than 0, got %s", val)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	meta.activationTargetValue = 0
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationTargetValue parsing error: %w", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.timeout = config.GlobalHTTPTimeout
+	if val, ok := config.TriggerMetadata["timeout"]; ok && val != "" {
+		timeoutMS, err := strconv.Atoi(val)
+		if err != nil || timeoutMS <= 0 {
+			return nil, fmt.Errorf("timeout must be a number of milliseconds greater than 0, got %s", val)
+		}
+		meta.timeout = time.Duration(timeoutMS) * time.Millisecond
+	}
+
+	if val, ok := config.TriggerMetadata["unsafeSsl"]; ok && val != "" {
+		unsafeSsl, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("unsafeSsl parsing error: %w", err)
+		}
+		meta.unsafeSsl = unsafeSsl
+	}
+
+	if val, ok := config.AuthParams["tls"]; ok {
+		switch strings.TrimSpace(val) {
+		case "enable":
+			meta.enableTLS = true
+			meta.ca = config.AuthParams["ca"]
+			meta.cert = config.AuthParams["cert"]
+			meta.key = config.AuthParams["key"]
+		case "disable":
+		default:
+			return nil, fmt.Errorf("incorrect value for tls given: %s", val)
+		}
+	}
+	if (meta.cert == "") != (meta.key == "") {
+		return nil, fmt.Errorf("both cert and key must be given for the client certificate")
+	}
+
+	meta.serverPublicKeyPin, err = getTLSServerPublicKeyPin(config)
+	if err != nil {
+		return nil, err
+	}
+
+	meta.username = config.AuthParams["username"]
+	meta.password = config.AuthParams["password"]
+	if meta.password != "" && meta.username == "" {
+		return nil, fmt.Errorf("no username given for the password")
+	}
+
+	meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("hazelcast-%s-%s", meta.structureType, meta.structureName)))
+	return &meta, nil
+}
+
+// Close shuts the client down
+func (s *hazelcastScaler) Close(ctx context.Context) error {
+	s.clientLock.Lock()
+	defer s.clientLock.Unlock()
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Shutdown(ctx)
+	s.client = nil
+	return err
+}
+
+// getSize returns the size of the data structure. The client is created again and the size read once more when the
+// cluster is unreachable, e.g. after the member it was connected to left the cluster
+func (s *hazelcastScaler) getSize(ctx context.Context) (int64, error) {
+	s.clientLock.Lock()
+	defer s.clientLock.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.client == nil {
+			s.client, err = s.newClient(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("error connecting to the hazelcast cluster %s: %w", s.metadata.clusterName, err)
+			}
+		}
+
+		var size int64
+		if s.metadata.structureType == hazelcastStructureTypeMap {
+			size, err = s.client.MapSize(ctx, s.metadata.structureName)
+		} else {
+			size, err = s.client.QueueSize(ctx, s.metadata.structureName)
+		}
+		if !errors.Is(err, errHazelcastClusterUnreachable) {
+			return size, err
+		}
+
+		s.logger.V(1).Info("Hazelcast cluster unreachable, creating the client again", "error", err)
+		if shutdownErr := s.client.Shutdown(ctx); shutdownErr != nil {
+			s.logger.V(1).Info("Error shutting down the hazelcast client", "error", shutdownErr)
+		}
+		s.client = nil
+	}
+	return 0, WrapScalerError(ErrBackend, err)
+}
+
+// GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
+func (s *hazelcastScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	externalMetric := &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{
+			Name: s.metadata.metricName,
+		},
+		Target: GetMetricTarget(s.metricType, s.metadata.targetValue),
+	}
+	metricSpec := v2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2.MetricSpec{metricSpec}
+}
+
+// GetMetricsAndActivity returns the size of the queue or the map and whether it's above the activation target value
+func (s *hazelcastScaler) GetMetricsAndActivity(ctx context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	size, err := s.getSize(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, false, fmt.Errorf("error reading the size of the hazelcast %s %s: %w", s.metadata.structureType, s.metadata.structureName, err)
+	}
+
+	metric := GenerateMetricInMili(metricName, float64(size))
+
+	return []external_metrics.ExternalMetricValue{metric}, size > s.metadata.activationTargetValue, nil
+}
@@ -0,0 +1,460 @@
+package scalers
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type parseHazelcastMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type hazelcastMetricIdentifier struct {
+	metadataTestData *parseHazelcastMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testHazelcastMetadata = []parseHazelcastMetadataTestData{
+	// queue with the defaults
+	{map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "10"}, map[string]string{}, false},
+	// map with all the settings
+	{map[string]string{"addresses": "hz-0:5701, hz-1:5702", "clusterName": "prod", "structureType": "map", "structureName": "orders", "targetValue": "5", "activationTargetValue": "1", "timeout": "1000", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret", "tls": "enable", "ca": "ca"}, false},
+	// addresses and cluster name from the TriggerAuthentication
+	{map[string]string{"structureName": "jobs", "targetValue": "10"}, map[string]string{"addresses": "hazelcast", "clusterName": "prod"}, false},
+	// no metadata
+	{map[string]string{}, map[string]string{}, true},
+	// missing addresses
+	{map[string]string{"structureName": "jobs", "targetValue": "10"}, map[string]string{}, true},
+	// empty addresses
+	{map[string]string{"addresses": " , ", "structureName": "jobs", "targetValue": "10"}, map[string]string{}, true},
+	// invalid structureType
+	{map[string]string{"addresses": "hazelcast", "structureType": "topic", "structureName": "jobs", "targetValue": "10"}, map[string]string{}, true},
+	// missing structureName
+	{map[string]string{"addresses": "hazelcast", "targetValue": "10"}, map[string]string{}, true},
+	// missing targetValue
+	{map[string]string{"addresses": "hazelcast", "structureName": "jobs"}, map[string]string{}, true},
+	// targetValue not greater than 0
+	{map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "0"}, map[string]string{}, true},
+	// invalid activationTargetValue
+	{map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "10", "activationTargetValue": "a"}, map[string]string{}, true},
+	// invalid timeout
+	{map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "10", "timeout": "0"}, map[string]string{}, true},
+	// invalid tls
+	{map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "10"}, map[string]string{"tls": "yes"}, true},
+	// cert without key
+	{map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "10"}, map[string]string{"tls": "enable", "cert": "cert"}, true},
+	// password without username
+	{map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "10"}, map[string]string{"password": "secret"}, true},
+}
+
+var hazelcastMetricIdentifiers = []hazelcastMetricIdentifier{
+	{&testHazelcastMetadata[0], 0, "s0-hazelcast-queue-jobs"},
+	{&testHazelcastMetadata[1], 1, "s1-hazelcast-map-orders"},
+}
+
+func TestParseHazelcastMetadata(t *testing.T) {
+	for i, testData := range testHazelcastMetadata {
+		_, err := parseHazelcastMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("test case %d: expected success but got error %v", i, err)
+		}
+		if err == nil && testData.isError {
+			t.Errorf("test case %d: expected error but got success", i)
+		}
+	}
+
+	meta, err := parseHazelcastMetadata(&ScalerConfig{TriggerMetadata: testHazelcastMetadata[1].metadata, AuthParams: testHazelcastMetadata[1].authParams})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hz-0:5701", "hz-1:5702"}, meta.addresses)
+	assert.Equal(t, "prod", meta.clusterName)
+
+	meta, err = parseHazelcastMetadata(&ScalerConfig{TriggerMetadata: testHazelcastMetadata[0].metadata, AuthParams: testHazelcastMetadata[0].authParams})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hazelcast:5701"}, meta.addresses)
+	assert.Equal(t, "dev", meta.clusterName)
+	assert.Equal(t, "queue", meta.structureType)
+}
+
+func TestHazelcastGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range hazelcastMetricIdentifiers {
+		meta, err := parseHazelcastMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockHazelcastScaler := hazelcastScaler{metadata: meta}
+
+		metricSpec := mockHazelcastScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+// fakeHazelcastClient returns the sizes of its queues and maps, or the error of the next call when it's set
+type fakeHazelcastClient struct {
+	queues   map[string]int64
+	maps     map[string]int64
+	errs     []error
+	shutdown bool
+}
+
+func (c *fakeHazelcastClient) nextError() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	err := c.errs[0]
+	c.errs = c.errs[1:]
+	return err
+}
+
+func (c *fakeHazelcastClient) QueueSize(_ context.Context, name string) (int64, error) {
+	if err := c.nextError(); err != nil {
+		return 0, err
+	}
+	return c.queues[name], nil
+}
+
+func (c *fakeHazelcastClient) MapSize(_ context.Context, name string) (int64, error) {
+	if err := c.nextError(); err != nil {
+		return 0, err
+	}
+	return c.maps[name], nil
+}
+
+func (c *fakeHazelcastClient) Shutdown(context.Context) error {
+	c.shutdown = true
+	return nil
+}
+
+// newTestHazelcastScaler returns a scaler for the metadata creating the given clients in turn
+func newTestHazelcastScaler(t *testing.T, metadata map[string]string, clients ...*fakeHazelcastClient) *hazelcastScaler {
+	meta, err := parseHazelcastMetadata(&ScalerConfig{TriggerMetadata: metadata})
+	require.NoError(t, err)
+	return &hazelcastScaler{
+		metadata: meta,
+		logger:   logr.Discard(),
+		newClient: func(context.Context) (hazelcastClient, error) {
+			if len(clients) == 0 {
+				return nil, WrapScalerError(ErrBackend, errors.New("no more clients"))
+			}
+			client := clients[0]
+			clients = clients[1:]
+			return client, nil
+		},
+	}
+}
+
+func TestHazelcastGetMetricsAndActivity(t *testing.T) {
+	client := &fakeHazelcastClient{queues: map[string]int64{"jobs": 7}, maps: map[string]int64{"orders": 3}}
+
+	queueScaler := newTestHazelcastScaler(t, map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "5", "activationTargetValue": "7"}, client)
+	metrics, active, err := queueScaler.GetMetricsAndActivity(context.Background(), "s0-hazelcast-queue-jobs")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), metrics[0].Value.Value())
+	assert.False(t, active)
+
+	mapScaler := newTestHazelcastScaler(t, map[string]string{"addresses": "hazelcast", "structureType": "map", "structureName": "orders", "targetValue": "5"}, client)
+	metrics, active, err = mapScaler.GetMetricsAndActivity(context.Background(), "s0-hazelcast-map-orders")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), metrics[0].Value.Value())
+	assert.True(t, active)
+}
+
+func TestHazelcastClientSharedAndRecreated(t *testing.T) {
+	metadata := map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "5"}
+	first := &fakeHazelcastClient{queues: map[string]int64{"jobs": 1}}
+	second := &fakeHazelcastClient{queues: map[string]int64{"jobs": 2}}
+	scaler := newTestHazelcastScaler(t, metadata, first, second)
+
+	// the client is shared across the polls
+	for i := 0; i < 3; i++ {
+		metrics, _, err := scaler.GetMetricsAndActivity(context.Background(), "s0-hazelcast-queue-jobs")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), metrics[0].Value.Value())
+	}
+	assert.Same(t, first, scaler.client)
+
+	// an error of the cluster keeps the client
+	first.errs = []error{errors.New("hazelcast returned com.hazelcast.core.HazelcastException: failure")}
+	_, _, err := scaler.GetMetricsAndActivity(context.Background(), "s0-hazelcast-queue-jobs")
+	assert.Error(t, err)
+	assert.Same(t, first, scaler.client)
+	assert.False(t, first.shutdown)
+
+	// the client is created again when the cluster is unreachable and the size read once more
+	first.errs = []error{errHazelcastClusterUnreachable}
+	metrics, _, err := scaler.GetMetricsAndActivity(context.Background(), "s0-hazelcast-queue-jobs")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), metrics[0].Value.Value())
+	assert.True(t, first.shutdown)
+	assert.Same(t, second, scaler.client)
+
+	// the client is shut down on Close
+	require.NoError(t, scaler.Close(context.Background()))
+	assert.True(t, second.shutdown)
+	assert.Nil(t, scaler.client)
+}
+
+func TestHazelcastClientUnreachableTwice(t *testing.T) {
+	metadata := map[string]string{"addresses": "hazelcast", "structureName": "jobs", "targetValue": "5"}
+	first := &fakeHazelcastClient{errs: []error{errHazelcastClusterUnreachable}}
+	second := &fakeHazelcastClient{errs: []error{errHazelcastClusterUnreachable}}
+	scaler := newTestHazelcastScaler(t, metadata, first, second)
+
+	_, _, err := scaler.GetMetricsAndActivity(context.Background(), "s0-hazelcast-queue-jobs")
+	assert.ErrorIs(t, err, ErrBackend)
+	assert.True(t, first.shutdown)
+	assert.True(t, second.shutdown)
+	assert.Nil(t, scaler.client)
+}
+
+func TestMurmur3x86_32(t *testing.T) {
+	assert.Equal(t, uint32(0), murmur3x86_32([]byte(""), 0))
+	assert.Equal(t, uint32(0x248bfa47), murmur3x86_32([]byte("hello"), 0))
+	assert.Equal(t, uint32(0x2e4ff723), murmur3x86_32([]byte("The quick brown fox jumps over the lazy dog"), 0))
+}
+
+func TestHazelcastPartitionID(t *testing.T) {
+	partitionID := hazelcastPartitionID("jobs", 271)
+	assert.True(t, partitionID >= 0 && partitionID < 271)
+	// the partition key after @ decides the partition
+	assert.Equal(t, hazelcastPartitionID("key", 271), hazelcastPartitionID("jobs@key", 271))
+}
+
+// serveTestHazelcastMember answers the authentication and the size requests of a client like a member of a
+// cluster of 271 partitions, the sizes are the ones of the names of the data structures
+func serveTestHazelcastMember(t *testing.T, conn net.Conn, sizes map[string]int32) {
+	defer conn.Close()
+	header := make([]byte, len(hazelcastProtocolHeader))
+	if _, err := io.ReadFull(conn, header); err != nil || string(header) != hazelcastProtocolHeader {
+		return
+	}
+	for {
+		request, err := readHazelcastMessage(conn)
+		if err != nil {
+			return
+		}
+		initial := request[0].content
+		messageType := int32(binary.LittleEndian.Uint32(initial))
+
+		var fixed []byte
+		switch messageType {
+		case hazelcastAuthenticationType:
+			// status, member UUID, serialization version, partition count
+			fixed = make([]byte, 1+hazelcastUUIDSize+1+4)
+			if string(request[1].content) != "dev" {
+				fixed[0] = hazelcastCredentialsFailed
+			}
+			binary.LittleEndian.PutUint32(fixed[1+hazelcastUUIDSize+1:], 271)
+		case hazelcastQueueSizeType, hazelcastMapSizeType:
+			name := string(request[1].content)
+			if messageType == hazelcastQueueSizeType {
+				assert.Equal(t, hazelcastPartitionID(name, 271), int32(binary.LittleEndian.Uint32(initial[12:])))
+			}
+			fixed = make([]byte, 4)
+			binary.LittleEndian.PutUint32(fixed, uint32(sizes[name]))
+		default:
+			t.Errorf("unexpected message type %x", messageType)
+			return
+		}
+
+		response := make([]byte, hazelcastResponseHeaderSize+len(fixed))
+		binary.LittleEndian.PutUint32(response, uint32(messageType+1))
+		copy(response[4:12], initial[4:12])
+		copy(response[hazelcastResponseHeaderSize:], fixed)
+		if err := writeHazelcastMessage(conn, []hazelcastFrame{{flags: hazelcastBeginFragmentFlag | hazelcastEndFragmentFlag, content: response}}); err != nil {
+			return
+		}
+	}
+}
+
+func TestHazelcastMemberClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestHazelcastMember(t, conn, map[string]int32{"jobs": 12, "orders": 34})
+		}
+	}()
+
+	// the unreachable address is skipped
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	client, err := newHazelcastMemberClient(context.Background(), hazelcastClientConfig{addresses: []string{closedAddress, listener.Addr().String()}, clusterName: "dev"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(271), client.partitionCount)
+
+	size, err := client.QueueSize(context.Background(), "jobs")
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), size)
+	size, err = client.MapSize(context.Background(), "orders")
+	require.NoError(t, err)
+	assert.Equal(t, int64(34), size)
+
+	// the client is unusable once the connection is closed
+	require.NoError(t, client.Shutdown(context.Background()))
+	_, err = client.QueueSize(context.Background(), "jobs")
+	assert.ErrorIs(t, err, errHazelcastClusterUnreachable)
+
+	_, err = newHazelcastMemberClient(context.Background(), hazelcastClientConfig{addresses: []string{listener.Addr().String()}, clusterName: "prod"})
+	assert.ErrorIs(t, err, ErrAuth)
+
+	_, err = newHazelcastMemberClient(context.Background(), hazelcastClientConfig{addresses: []string{closedAddress}, clusterName: "dev"})
+	assert.ErrorIs(t, err, errHazelcastClusterUnreachable)
+}
+
+// the verification vectors of the reference MurmurHash3 implementation of SMHasher
+func TestMurmur3x86_32ReferenceVectors(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		seed     uint32
+		expected uint32
+	}{
+		{[]byte{}, 1, 0x514e28b7},
+		{[]byte{}, 0xffffffff, 0x81f16f39},
+		{[]byte{0xff, 0xff, 0xff, 0xff}, 0, 0x76293b50},
+		{[]byte{0x21, 0x43, 0x65, 0x87}, 0, 0xf55b516b},
+		{[]byte{0x21, 0x43, 0x65, 0x87}, 0x5082edee, 0x2362f9de},
+		{[]byte{0x21, 0x43, 0x65}, 0, 0x7e4a8634},
+		{[]byte{0x21, 0x43}, 0, 0xa0f7b07a},
+		{[]byte{0x21}, 0, 0x72661cf4},
+		{[]byte{0, 0, 0, 0}, 0, 0x2362f9de},
+		{[]byte("aaaa"), 0x9747b28c, 0x5a97808a},
+		{[]byte("abc"), 0x9747b28c, 0xc84a62dd},
+		{[]byte("Hello, world!"), 0x9747b28c, 0x24884cba},
+		{[]byte("The quick brown fox jumps over the lazy dog"), 0x9747b28c, 0x2fa826cd},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, murmur3x86_32(test.data, test.seed), "%x with the seed %x", test.data, test.seed)
+	}
+}
+
+func testHazelcastBytes(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	require.NoError(t, err)
+	return data
+}
+
+// exchangeTestHazelcastMessage reads a request of the length of the expected one from the client, and answers
+// with the response once it matches. The bytes of the request in the ignored range aren't compared
+func exchangeTestHazelcastMessage(t *testing.T, conn net.Conn, expected []byte, ignored [2]int, response []byte) {
+	request := make([]byte, len(expected))
+	if _, err := io.ReadFull(conn, request); err != nil {
+		t.Error(err)
+		return
+	}
+	copy(request[ignored[0]:ignored[1]], expected[ignored[0]:ignored[1]])
+	assert.Equal(t, expected, request)
+	if _, err := conn.Write(response); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestHazelcastClientProtocol checks the bytes exchanged with a member against the message layouts of the
+// Hazelcast open binary client protocol 2.x specification
+func TestHazelcastClientProtocol(t *testing.T) {
+	conn, member := net.Pipe()
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		member.Close()
+		<-done
+	})
+	go func() {
+		defer close(done)
+		// protocol header, then the Client.Authentication request 0x000100: the initial frame holds the client
+		// UUID and the serialization version, the cluster name, the null username and password, the client type,
+		// version and name and the empty labels list follow in their own frames
+		authentication := testHazelcastBytes(t, "435032"+
+			"28000000 00c0 00010000 0100000000000000 ffffffff 00 00000000000000000000000000000000 01"+
+			"09000000 0000 646576"+
+			"06000000 0004"+
+			"06000000 0004"+
+			"09000000 0000 474f4f"+
+			"09000000 0000 352e30"+
+			"0a000000 0000 6b656461"+
+			"06000000 0010"+
+			"06000000 0028")
+		// the response 0x000101 with the status, the member UUID, the serialization version, the partition
+		// count of 271, the cluster ID and the failover support, followed by the null member address
+		authenticated := testHazelcastBytes(t,
+			"3c000000 00c0 01010000 0100000000000000 00 00 00 0102030405060708090a0b0c0d0e0f10 01 0f010000 00 00000000000000000000000000000000 00"+
+				"06000000 0024")
+		exchangeTestHazelcastMessage(t, member, authentication, [2]int{3 + 23, 3 + 39}, authenticated)
+
+		// the Map.Size request 0x012A00 is sent to any partition, its response 0x012A01 follows an event frame of
+		// another correlation ID, which is skipped
+		mapSize := testHazelcastBytes(t,
+			"16000000 00c0 002a0100 0200000000000000 ffffffff"+
+				"0c000000 0020 6f7264657273")
+		event := testHazelcastBytes(t, "16000000 00e2 01020300 6300000000000000 ffffffff")
+		mapSizeResponse := testHazelcastBytes(t, "17000000 00e0 012a0100 0200000000000000 00 22000000")
+		exchangeTestHazelcastMessage(t, member, mapSize, [2]int{}, append(event, mapSizeResponse...))
+
+		// the Queue.Size request 0x030300 is sent to the partition of the queue, and answered with an error
+		// response 0x000000 holding a list of error holders with the code, the class name, the message and the
+		// stack trace
+		queueSize := testHazelcastBytes(t,
+			"16000000 00c0 00030300 0300000000000000 "+fmt.Sprintf("%08x", bits.ReverseBytes32(uint32(hazelcastPartitionID("jobs", 271))))+
+				"0a000000 0020 6a6f6273")
+		errorResponse := testHazelcastBytes(t,
+			"13000000 00c0 00000000 0300000000000000 00"+
+				"06000000 0010"+
+				"06000000 0010"+
+				"0a000000 0000 1f000000"+
+				"2b000000 0000 636f6d2e68617a656c636173742e636f72652e48617a656c63617374457863657074696f6e"+
+				"0a000000 0000 646f776e"+
+				"06000000 0010"+
+				"06000000 0008"+
+				"06000000 0008"+
+				"06000000 0028")
+		exchangeTestHazelcastMessage(t, member, queueSize, [2]int{}, errorResponse)
+	}()
+
+	client := &hazelcastMemberClient{conn: conn, timeout: 5 * time.Second}
+	require.NoError(t, client.authenticate(context.Background(), hazelcastClientConfig{clusterName: "dev"}))
+	assert.Equal(t, int32(271), client.partitionCount)
+
+	size, err := client.MapSize(context.Background(), "orders")
+	require.NoError(t, err)
+	assert.Equal(t, int64(34), size)
+
+	_, err = client.QueueSize(context.Background(), "jobs")
+	assert.ErrorIs(t, err, ErrBackend)
+	assert.ErrorContains(t, err, "com.hazelcast.core.HazelcastException: down")
+	require.NoError(t, client.Shutdown(context.Background()))
+}
+
+// the partition of a name is the one of its serialized form: the hash covers the payload of the heap data, the big
+// endian length of the string and its UTF-8 bytes
+func TestHazelcastPartitionIDOfSerializedName(t *testing.T) {
+	hash := int32(murmur3x86_32(testHazelcastBytes(t, "00000004 6a6f6273"), hazelcastPartitionHashSeed))
+	if hash < 0 {
+		hash = -hash
+	}
+	assert.Equal(t, hash%271, hazelcastPartitionID("jobs", 271))
+}
@@ -7,8 +7,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/go-logr/logr"
 	v2 "k8s.io/api/autoscaling/v2"
 	"k8s.io/metrics/pkg/apis/external_metrics"
@@ -23,6 +25,8 @@ type kafkaScaler struct {
 	admin           sarama.ClusterAdmin
 	logger          logr.Logger
 	previousOffsets map[string]map[int32]int64
+
+	observedPartitions
 }
 
 const (
@@ -31,16 +35,20 @@ const (
 )
 
 type kafkaMetadata struct {
-	bootstrapServers       []string
-	group                  string
-	topic                  string
-	partitionLimitation    []int32
-	lagThreshold           int64
-	activationLagThreshold int64
-	offsetResetPolicy      offsetResetPolicy
-	allowIdleConsumers     bool
-	excludePersistentLag   bool
-	version                sarama.KafkaVersion
+	bootstrapServers []string
+	// bootstrap servers lists used when none of the brokers of the previous lists are reachable
+	fallbackBootstrapServers []string
+	group                    string
+	topic                    string
+	partitionLimitation      []int32
+	lagThreshold             int64
+	activationLagThreshold   int64
+	offsetResetPolicy        offsetResetPolicy
+	allowIdleConsumers       bool
+	excludePersistentLag     bool
+	version                  sarama.KafkaVersion
+	// how often the client refreshes the metadata of the topics in the background, 0 disables it
+	metadataRefreshInterval time.Duration
 
 	// If an invalid offset is found, whether to scale to 1 (false - the default) so consumption can
 	// occur or scale to 0 (true). See discussion in https://github.com/kedacore/keda/issues/2612
@@ -56,12 +64,18 @@ type kafkaMetadata struct {
 	oauthTokenEndpointURI string
 	oauthExtensions       map[string]string
 
+	// AWS_MSK_IAM
+	awsRegion        string
+	awsAuthorization awsAuthorizationMetadata
+
 	// TLS
 	enableTLS   bool
 	cert        string
 	key         string
 	keyPassword string
 	ca          string
+	// serverPublicKeyPin replaces the verification of the certificate chain of the brokers when it's set
+	serverPublicKeyPin string
 
 	scalerIndex int
 }
@@ -82,6 +96,7 @@ const (
 	KafkaSASLTypeSCRAMSHA256 kafkaSaslType = "scram_sha256"
 	KafkaSASLTypeSCRAMSHA512 kafkaSaslType = "scram_sha512"
 	KafkaSASLTypeOAuthbearer kafkaSaslType = "oauthbearer"
+	KafkaSASLTypeMskIam      kafkaSaslType = "aws_msk_iam"
 )
 
 const (
@@ -91,6 +106,7 @@ const (
 	defaultKafkaLagThreshold           = 10
 	defaultKafkaActivationLagThreshold = 0
 	defaultOffsetResetPolicy           = latest
+	defaultKafkaMetadataRefresh        = 10 * time.Minute
 	invalidOffset                      = -1
 )
 
@@ -177,6 +193,18 @@ func parseKafkaAuthParams(config *ScalerConfig, meta *kafkaMetadata) error {
 					}
 				}
 			}
+		} else if mode == KafkaSASLTypeMskIam {
+			if config.TriggerMetadata["awsRegion"] == "" {
+				return errors.New("no awsRegion given, it's required to sign the aws_msk_iam authentication")
+			}
+			meta.awsRegion = config.TriggerMetadata["awsRegion"]
+
+			awsAuthorization, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv, config.PodIdentity)
+			if err != nil {
+				return err
+			}
+			meta.awsAuthorization = awsAuthorization
+			meta.saslType = mode
 		} else {
 			return fmt.Errorf("err SASL mode %s given", mode)
 		}
@@ -227,22 +255,45 @@ func parseKafkaAuthParams(config *ScalerConfig, meta *kafkaMetadata) error {
 		} else {
 			meta.keyPassword = ""
 		}
+		pin, err := getTLSServerPublicKeyPin(config)
+		if err != nil {
+			return err
+		}
+		meta.serverPublicKeyPin = pin
 		meta.enableTLS = true
 	}
 
+	if meta.saslType == KafkaSASLTypeMskIam && !meta.enableTLS {
+		return errors.New("tls must be enabled when using aws_msk_iam")
+	}
+
 	return nil
 }
 
 func parseKafkaMetadata(config *ScalerConfig, logger logr.Logger) (kafkaMetadata, error) {
 	meta := kafkaMetadata{}
+	var bootstrapServers string
 	switch {
 	case config.TriggerMetadata["bootstrapServersFromEnv"] != "":
-		meta.bootstrapServers = strings.Split(config.ResolvedEnv[config.TriggerMetadata["bootstrapServersFromEnv"]], ",")
+		bootstrapServers = config.ResolvedEnv[config.TriggerMetadata["bootstrapServersFromEnv"]]
 	case config.TriggerMetadata["bootstrapServers"] != "":
-		meta.bootstrapServers = strings.Split(config.TriggerMetadata["bootstrapServers"], ",")
+		bootstrapServers = config.TriggerMetadata["bootstrapServers"]
 	default:
 		return meta, errors.New("no bootstrapServers given")
 	}
+	meta.bootstrapServers = strings.Split(bootstrapServers, ",")
+
+	// the fallback bootstrap servers are tried when none of the bootstrap servers is reachable
+	var fallbackBootstrapServers string
+	switch {
+	case config.TriggerMetadata["fallbackBootstrapServersFromEnv"] != "":
+		fallbackBootstrapServers = config.ResolvedEnv[config.TriggerMetadata["fallbackBootstrapServersFromEnv"]]
+	case config.TriggerMetadata["fallbackBootstrapServers"] != "":
+		fallbackBootstrapServers = config.TriggerMetadata["fallbackBootstrapServers"]
+	}
+	if fallbackBootstrapServers != "" {
+		meta.fallbackBootstrapServers = strings.Split(fallbackBootstrapServers, ",")
+	}
 
 	switch {
 	case config.TriggerMetadata["consumerGroupFromEnv"] != "":
@@ -356,13 +407,88 @@ func parseKafkaMetadata(config *ScalerConfig, logger logr.Logger) (kafkaMetadata
 		}
 		meta.version = version
 	}
+
+	meta.metadataRefreshInterval = defaultKafkaMetadataRefresh
+	if val, ok := config.TriggerMetadata["metadataRefreshInterval"]; ok && val != "" {
+		interval, err := time.ParseDuration(val)
+		if err != nil {
+			return meta, fmt.Errorf("error parsing metadataRefreshInterval: %w", err)
+		}
+		if interval < 0 {
+			return meta, fmt.Errorf("metadataRefreshInterval must not be negative, got %s", val)
+		}
+		meta.metadataRefreshInterval = interval
+	}
 	meta.scalerIndex = config.ScalerIndex
 	return meta, nil
 }
 
 func getKafkaClients(metadata kafkaMetadata) (sarama.Client, sarama.ClusterAdmin, error) {
+	config, err := getKafkaClientConfig(metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var client sarama.Client
+	var clientErrs []error
+	bootstrapServersLists := [][]string{metadata.bootstrapServers}
+	if len(metadata.fallbackBootstrapServers) > 0 {
+		bootstrapServersLists = append(bootstrapServersLists, metadata.fallbackBootstrapServers)
+	}
+	for _, bootstrapServers := range bootstrapServersLists {
+		client, err = sarama.NewClient(bootstrapServers, config)
+		if err == nil && !config.Metadata.Full {
+			// the client doesn't fetch any metadata when it's created without the full metadata
+			if err = refreshKafkaTopicMetadata(client, metadata.topic); err != nil {
+				client.Close()
+				client = nil
+			}
+		}
+		if err == nil {
+			break
+		}
+		clientErrs = append(clientErrs, fmt.Errorf("%s: %w", strings.Join(bootstrapServers, ","), err))
+	}
+	if client == nil {
+		return nil, nil, fmt.Errorf("error creating kafka client: %w", errors.Join(clientErrs...))
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		if !client.Closed() {
+			client.Close()
+		}
+		return nil, nil, fmt.Errorf("error creating kafka admin: %w", err)
+	}
+
+	return client, admin, nil
+}
+
+// refreshKafkaTopicMetadata fetches the metadata of the topic, which checks that the brokers are reachable and
+// gets the controller of the cluster. The errors sarama tolerates on its initial fetch of the full metadata are
+// ignored, as well as an unknown topic which is reported by the polls
+func refreshKafkaTopicMetadata(client sarama.Client, topic string) error {
+	err := client.RefreshMetadata(topic)
+	switch {
+	case err == nil,
+		errors.Is(err, sarama.ErrUnknownTopicOrPartition),
+		errors.Is(err, sarama.ErrLeaderNotAvailable),
+		errors.Is(err, sarama.ErrReplicaNotAvailable),
+		errors.Is(err, sarama.ErrTopicAuthorizationFailed),
+		errors.Is(err, sarama.ErrClusterAuthorizationFailed):
+		return nil
+	default:
+		return err
+	}
+}
+
+func getKafkaClientConfig(metadata kafkaMetadata) (*sarama.Config, error) {
 	config := sarama.NewConfig()
 	config.Version = metadata.version
+	// the metadata of every topic of the cluster takes a lot of memory with many partitions, only the metadata of
+	// the topic is kept when it's known. The topics of the consumer group are found by each poll otherwise
+	config.Metadata.Full = metadata.topic == ""
+	config.Metadata.RefreshFrequency = metadata.metadataRefreshInterval
 
 	if metadata.saslType != KafkaSASLTypeNone {
 		config.Net.SASL.Enable = true
@@ -374,7 +500,10 @@ func getKafkaClients(metadata kafkaMetadata) (sarama.Client, sarama.ClusterAdmin
 		config.Net.TLS.Enable = true
 		tlsConfig, err := kedautil.NewTLSConfigWithPassword(metadata.cert, metadata.key, metadata.keyPassword, metadata.ca, false)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
+		}
+		if err := kedautil.PinServerPublicKey(tlsConfig, metadata.serverPublicKeyPin); err != nil {
+			return nil, err
 		}
 		config.Net.TLS.Config = tlsConfig
 	}
@@ -398,20 +527,42 @@ func getKafkaClients(metadata kafkaMetadata) (sarama.Client, sarama.ClusterAdmin
 		config.Net.SASL.TokenProvider = OAuthBearerTokenProvider(metadata.username, metadata.password, metadata.oauthTokenEndpointURI, metadata.scopes, metadata.oauthExtensions)
 	}
 
-	client, err := sarama.NewClient(metadata.bootstrapServers, config)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error creating kafka client: %w", err)
+	if metadata.saslType == KafkaSASLTypeMskIam {
+		config.Net.SASL.Mechanism = sarama.SASLTypeOAuth
+		config.Net.SASL.TokenProvider = newMSKIAMTokenProvider(metadata.awsRegion, getMSKIAMCredentials(metadata))
 	}
 
-	admin, err := sarama.NewClusterAdminFromClient(client)
+	return config, nil
+}
+
+// getMSKIAMCredentials returns the AWS credentials used to sign the aws_msk_iam authentication
+func getMSKIAMCredentials(metadata kafkaMetadata) *credentials.Credentials {
+	awsAuthorization := metadata.awsAuthorization
+	if awsAuthorization.podIdentityOwner && awsAuthorization.awsRoleArn == "" && awsAuthorization.awsAccessKeyID != "" {
+		return credentials.NewStaticCredentials(awsAuthorization.awsAccessKeyID, awsAuthorization.awsSecretAccessKey, awsAuthorization.awsSessionToken)
+	}
+	sess, awsConfig := getAwsConfig(metadata.awsRegion, "", metadata.awsAuthorization)
+	if awsConfig.Credentials != nil {
+		return awsConfig.Credentials
+	}
+	return sess.Config.Credentials
+}
+
+// refreshClients recreates the kafka clients, so the bootstrap servers are resolved again
+// and the fallback bootstrap servers are tried. The current clients are kept if it fails
+func (s *kafkaScaler) refreshClients() error {
+	client, admin, err := getKafkaClients(s.metadata)
 	if err != nil {
-		if !client.Closed() {
-			client.Close()
+		return err
+	}
+	if s.admin != nil {
+		if err := s.admin.Close(); err != nil {
+			s.logger.V(1).Info("Error closing previous kafka admin", "error", err.Error())
 		}
-		return nil, nil, fmt.Errorf("error creating kafka admin: %w", err)
 	}
-
-	return client, admin, nil
+	s.client = client
+	s.admin = admin
+	return nil
 }
 
 func (s *kafkaScaler) getTopicPartitions() (map[string][]int32, error) {
@@ -618,6 +769,13 @@ func (s *kafkaScaler) getConsumerAndProducerOffsets(topicPartitions map[string][
 // GetMetricsAndActivity returns value for a supported metric and an error if there is a problem getting the metric
 func (s *kafkaScaler) GetMetricsAndActivity(_ context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
 	totalLag, totalLagWithPersistent, err := s.getTotalLag()
+	if errors.Is(err, sarama.ErrOutOfBrokers) {
+		// the brokers may have been replaced (e.g. DNS rotation), so retry once with new clients
+		s.logger.V(1).Info("Unable to reach any kafka broker, recreating the kafka clients", "error", err.Error())
+		if err = s.refreshClients(); err == nil {
+			totalLag, totalLagWithPersistent, err = s.getTotalLag()
+		}
+	}
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, false, err
 	}
@@ -634,6 +792,11 @@ func (s *kafkaScaler) getTotalLag() (int64, int64, error) {
 	if err != nil {
 		return 0, 0, err
 	}
+	partitionCount := 0
+	for _, partitions := range topicPartitions {
+		partitionCount += len(partitions)
+	}
+	s.setPartitionCount(partitionCount)
 
 	consumerOffsets, producerOffsets, err := s.getConsumerAndProducerOffsets(topicPartitions)
 	if err != nil {
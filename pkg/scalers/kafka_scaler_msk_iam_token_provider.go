@@ -0,0 +1,57 @@
+package scalers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	mskIAMSigningService = "kafka-cluster"
+	mskIAMConnectAction  = "kafka-cluster:Connect"
+	mskIAMTokenExpiry    = 15 * time.Minute
+	mskIAMUserAgent      = "keda-kafka-scaler"
+)
+
+// MSKIAMTokenProvider provides the OAUTHBEARER tokens used to authenticate against AWS MSK with IAM,
+// a token is the base64 encoded kafka-cluster:Connect url presigned with the AWS credentials
+type MSKIAMTokenProvider struct {
+	region      string
+	credentials *credentials.Credentials
+	now         func() time.Time
+}
+
+func newMSKIAMTokenProvider(region string, creds *credentials.Credentials) *MSKIAMTokenProvider {
+	return &MSKIAMTokenProvider{
+		region:      region,
+		credentials: creds,
+		now:         time.Now,
+	}
+}
+
+func (p *MSKIAMTokenProvider) Token() (*sarama.AccessToken, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://kafka.%s.amazonaws.com/", p.region), nil)
+	if err != nil {
+		return nil, err
+	}
+	query := req.URL.Query()
+	query.Set("Action", mskIAMConnectAction)
+	req.URL.RawQuery = query.Encode()
+
+	signer := v4.NewSigner(p.credentials)
+	if _, err := signer.Presign(req, nil, mskIAMSigningService, p.region, mskIAMTokenExpiry, p.now()); err != nil {
+		return nil, fmt.Errorf("error signing aws msk iam token: %w", err)
+	}
+
+	// the user agent isn't part of the signature, it's only used by MSK for auditing
+	query = req.URL.Query()
+	query.Set("User-Agent", mskIAMUserAgent)
+	req.URL.RawQuery = query.Encode()
+
+	return &sarama.AccessToken{Token: base64.RawURLEncoding.EncodeToString([]byte(req.URL.String()))}, nil
+}
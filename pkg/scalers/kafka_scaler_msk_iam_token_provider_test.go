@@ -0,0 +1,63 @@
+package scalers
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+func TestMSKIAMTokenProvider(t *testing.T) {
+	provider := newMSKIAMTokenProvider("eu-west-1", credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", "session-token"))
+	provider.now = func() time.Time { return time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	token, err := provider.Token()
+	if err != nil {
+		t.Fatal("Expected success but got error", err)
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token.Token)
+	if err != nil {
+		t.Fatal("Expected token to be base64 url encoded but got error", err)
+	}
+	signedURL, err := url.Parse(string(decoded))
+	if err != nil {
+		t.Fatal("Expected token to be an url but got error", err)
+	}
+
+	if signedURL.Host != "kafka.eu-west-1.amazonaws.com" {
+		t.Errorf("Expected host kafka.eu-west-1.amazonaws.com but got %s", signedURL.Host)
+	}
+	query := signedURL.Query()
+	expectedParams := map[string]string{
+		"Action":               "kafka-cluster:Connect",
+		"X-Amz-Algorithm":      "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":     "AKIDEXAMPLE/20230601/eu-west-1/kafka-cluster/aws4_request",
+		"X-Amz-Date":           "20230601T120000Z",
+		"X-Amz-Expires":        "900",
+		"X-Amz-Security-Token": "session-token",
+		"User-Agent":           mskIAMUserAgent,
+	}
+	for param, expected := range expectedParams {
+		if value := query.Get(param); value != expected {
+			t.Errorf("Expected %s to be %s but got %s", param, expected, value)
+		}
+	}
+	if query.Get("X-Amz-Signature") == "" {
+		t.Error("Expected the url to be signed")
+	}
+	if strings.Contains(query.Get("X-Amz-SignedHeaders"), "user-agent") {
+		t.Error("Expected the user agent not to be signed")
+	}
+}
+
+func TestMSKIAMTokenProviderCredentialsError(t *testing.T) {
+	provider := newMSKIAMTokenProvider("eu-west-1", credentials.NewStaticCredentials("", "", ""))
+
+	if _, err := provider.Token(); err == nil {
+		t.Error("Expected error for missing credentials but got success")
+	}
+}
@@ -2,9 +2,12 @@ package scalers
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/go-logr/logr"
@@ -112,6 +115,8 @@ var parseKafkaMetadataTestDataset = []parseKafkaMetadataTestData{
 	{map[string]string{"bootstrapServers": "foobar:9092", "consumerGroup": "my-group", "topic": "my-topic", "excludePersistentLag": "true"}, false, 1, []string{"foobar:9092"}, "my-group", "my-topic", nil, offsetResetPolicy("latest"), false, true},
 	// success, version supported
 	{map[string]string{"bootstrapServers": "foobar:9092", "consumerGroup": "my-group", "topic": "my-topic", "allowIdleConsumers": "true", "version": "1.0.0"}, false, 1, []string{"foobar:9092"}, "my-group", "my-topic", nil, offsetResetPolicy("latest"), true, false},
+	// success, fallback bootstrap servers
+	{map[string]string{"bootstrapServers": "foo:9092,bar:9092", "fallbackBootstrapServers": "backup:9092,backup2:9092", "consumerGroup": "my-group", "topic": "my-topic"}, false, 2, []string{"foo:9092", "bar:9092"}, "my-group", "my-topic", nil, offsetResetPolicy("latest"), false, false},
 }
 
 var parseKafkaAuthParamsTestDataset = []parseKafkaAuthParamsTestData{
@@ -243,6 +248,21 @@ var parseKafkaOAuthbrearerAuthParamsTestDataset = []parseKafkaAuthParamsTestData
 	{map[string]string{"sasl": "oauthbearer", "username": "admin", "password": "admin", "scopes": "scope", "oauthTokenEndpointUri": "https://website.com", "tls": "disable", "oauthExtensions": "extension_foo=bar,extension_bazbaz"}, true, false},
 }
 
+var parseKafkaMskIamAuthParamsTestDataset = []parseAuthParamsTestDataSecondAuthMethod{
+	// success, role assumption
+	{map[string]string{"awsRegion": "eu-west-1", "tls": "enable"}, map[string]string{"sasl": "aws_msk_iam", "awsRoleArn": "arn:aws:iam::123456789012:role/msk"}, false, true},
+	// success, static keys
+	{map[string]string{"awsRegion": "eu-west-1"}, map[string]string{"sasl": "aws_msk_iam", "tls": "enable", "awsAccessKeyID": "AKIDEXAMPLE", "awsSecretAccessKey": "secret"}, false, true},
+	// success, operator identity
+	{map[string]string{"awsRegion": "eu-west-1", "identityOwner": "operator", "tls": "enable"}, map[string]string{"sasl": "aws_msk_iam"}, false, true},
+	// failure, no awsRegion
+	{map[string]string{"tls": "enable"}, map[string]string{"sasl": "aws_msk_iam", "awsRoleArn": "arn:aws:iam::123456789012:role/msk"}, true, true},
+	// failure, no credentials
+	{map[string]string{"awsRegion": "eu-west-1", "tls": "enable"}, map[string]string{"sasl": "aws_msk_iam"}, true, true},
+	// failure, TLS disabled
+	{map[string]string{"awsRegion": "eu-west-1", "tls": "disable"}, map[string]string{"sasl": "aws_msk_iam", "awsRoleArn": "arn:aws:iam::123456789012:role/msk"}, true, false},
+}
+
 var kafkaMetricIdentifiers = []kafkaMetricIdentifier{
 	{&parseKafkaMetadataTestDataset[10], 0, "s0-kafka-my-topic"},
 	{&parseKafkaMetadataTestDataset[10], 1, "s1-kafka-my-topic"},
@@ -398,13 +418,286 @@ func TestKafkaOAuthbrearerAuthParams(t *testing.T) {
 	}
 }
 
+func TestKafkaMskIamAuthParams(t *testing.T) {
+	for id, testData := range parseKafkaMskIamAuthParamsTestDataset {
+		metadata := map[string]string{"bootstrapServers": "broker1:9098", "consumerGroup": "my-group", "topic": "my-topic"}
+		for k, v := range testData.metadata {
+			metadata[k] = v
+		}
+		meta, err := parseKafkaMetadata(&ScalerConfig{TriggerMetadata: metadata, AuthParams: testData.authParams}, logr.Discard())
+
+		if err != nil && !testData.isError {
+			t.Errorf("Test case: %v. Expected success but got error %v", id, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test case: %v. Expected error but got success", id)
+		}
+		if err != nil {
+			continue
+		}
+		if meta.saslType != KafkaSASLTypeMskIam {
+			t.Errorf("Test case: %v. Expected sasl type %s but got %s", id, KafkaSASLTypeMskIam, meta.saslType)
+		}
+		if meta.awsRegion != testData.metadata["awsRegion"] {
+			t.Errorf("Test case: %v. Expected awsRegion %s but got %s", id, testData.metadata["awsRegion"], meta.awsRegion)
+		}
+		if meta.awsAuthorization.awsRoleArn != testData.authParams["awsRoleArn"] {
+			t.Errorf("Test case: %v. Expected awsRoleArn %s but got %s", id, testData.authParams["awsRoleArn"], meta.awsAuthorization.awsRoleArn)
+		}
+		if meta.awsAuthorization.awsAccessKeyID != testData.authParams["awsAccessKeyID"] {
+			t.Errorf("Test case: %v. Expected awsAccessKeyID %s but got %s", id, testData.authParams["awsAccessKeyID"], meta.awsAuthorization.awsAccessKeyID)
+		}
+		if meta.enableTLS != testData.enableTLS {
+			t.Errorf("Test case: %v. Expected enableTLS %v but got %v", id, testData.enableTLS, meta.enableTLS)
+		}
+	}
+}
+
+func TestKafkaMskIamClientConfig(t *testing.T) {
+	meta, err := parseKafkaMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"bootstrapServers": "broker1:9098", "consumerGroup": "my-group", "awsRegion": "eu-west-1", "tls": "enable"},
+		AuthParams:      map[string]string{"sasl": "aws_msk_iam", "awsAccessKeyID": "AKIDEXAMPLE", "awsSecretAccessKey": "secret", "awsSessionToken": "session-token"},
+	}, logr.Discard())
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+
+	config, err := getKafkaClientConfig(meta)
+	if err != nil {
+		t.Fatal("Could not create client config:", err)
+	}
+	if !config.Net.SASL.Enable || config.Net.SASL.Mechanism != sarama.SASLTypeOAuth {
+		t.Errorf("Expected SASL %s to be enabled but got enabled=%v mechanism=%s", sarama.SASLTypeOAuth, config.Net.SASL.Enable, config.Net.SASL.Mechanism)
+	}
+	if !config.Net.TLS.Enable {
+		t.Error("Expected TLS to be enabled")
+	}
+
+	tokenProvider, ok := config.Net.SASL.TokenProvider.(*MSKIAMTokenProvider)
+	if !ok {
+		t.Fatalf("Expected token provider to be *MSKIAMTokenProvider but got %T", config.Net.SASL.TokenProvider)
+	}
+	if tokenProvider.region != "eu-west-1" {
+		t.Errorf("Expected token provider region eu-west-1 but got %s", tokenProvider.region)
+	}
+	creds, err := tokenProvider.credentials.Get()
+	if err != nil {
+		t.Fatal("Could not get credentials:", err)
+	}
+	if creds.AccessKeyID != "AKIDEXAMPLE" || creds.SecretAccessKey != "secret" || creds.SessionToken != "session-token" {
+		t.Errorf("Expected the static credentials to be used but got %s", creds.AccessKeyID)
+	}
+}
+
+func TestKafkaClientMetadataConfig(t *testing.T) {
+	testData := []struct {
+		name             string
+		metadata         map[string]string
+		isError          bool
+		fullMetadata     bool
+		refreshFrequency time.Duration
+	}{
+		{"topic", map[string]string{"topic": "my-topic"}, false, false, 10 * time.Minute},
+		{"consumer_group_topics", map[string]string{}, false, true, 10 * time.Minute},
+		{"refresh_interval", map[string]string{"topic": "my-topic", "metadataRefreshInterval": "90s"}, false, false, 90 * time.Second},
+		{"refresh_disabled", map[string]string{"topic": "my-topic", "metadataRefreshInterval": "0s"}, false, false, 0},
+		{"negative_refresh_interval", map[string]string{"topic": "my-topic", "metadataRefreshInterval": "-1m"}, true, false, 0},
+		{"malformed_refresh_interval", map[string]string{"topic": "my-topic", "metadataRefreshInterval": "10"}, true, false, 0},
+	}
+
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := map[string]string{"bootstrapServers": "foobar:9092", "consumerGroup": "my-group"}
+			for key, value := range tt.metadata {
+				metadata[key] = value
+			}
+			meta, err := parseKafkaMetadata(&ScalerConfig{TriggerMetadata: metadata, AuthParams: validWithoutAuthParams}, logr.Discard())
+			if tt.isError {
+				if err == nil {
+					t.Error("Expected error but got success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+
+			config, err := getKafkaClientConfig(meta)
+			if err != nil {
+				t.Fatal("Could not create client config:", err)
+			}
+			if config.Metadata.Full != tt.fullMetadata {
+				t.Errorf("Expected full metadata %t but got %t", tt.fullMetadata, config.Metadata.Full)
+			}
+			if config.Metadata.RefreshFrequency != tt.refreshFrequency {
+				t.Errorf("Expected metadata refresh frequency %s but got %s", tt.refreshFrequency, config.Metadata.RefreshFrequency)
+			}
+		})
+	}
+}
+
+func TestKafkaClientsFallbackBootstrapServers(t *testing.T) {
+	broker := newKafkaMockBroker(t)
+	defer broker.Close()
+
+	meta, err := parseKafkaMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"bootstrapServers": "127.0.0.1:1", "fallbackBootstrapServers": broker.Addr(), "consumerGroup": "my-group", "topic": "my-topic"},
+		AuthParams:      map[string]string{},
+	}, logr.Discard())
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+
+	client, admin, err := getKafkaClients(meta)
+	if err != nil {
+		t.Fatal("Expected the fallback bootstrap servers to be used but got error", err)
+	}
+	defer admin.Close()
+	if brokers := client.Brokers(); len(brokers) != 1 || brokers[0].Addr() != broker.Addr() {
+		t.Errorf("Expected client to be connected to %s", broker.Addr())
+	}
+}
+
+func TestKafkaRefreshClientsWhenBrokersUnreachable(t *testing.T) {
+	broker := newKafkaMockBroker(t)
+	defer broker.Close()
+
+	meta, err := parseKafkaMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"bootstrapServers": broker.Addr(), "consumerGroup": "my-group", "topic": "my-topic", "lagThreshold": "1"},
+		AuthParams:      map[string]string{},
+	}, logr.Discard())
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+
+	unreachableAdmin := &unreachableClusterAdmin{}
+	scaler := kafkaScaler{metadata: meta, admin: unreachableAdmin, logger: logr.Discard(), previousOffsets: make(map[string]map[int32]int64)}
+	defer scaler.Close(context.Background())
+
+	metrics, _, err := scaler.GetMetricsAndActivity(context.Background(), "s0-kafka-my-topic")
+	if err != nil {
+		t.Fatal("Expected the kafka clients to be recreated but got error", err)
+	}
+	if !unreachableAdmin.closed {
+		t.Error("Expected the previous kafka admin to be closed")
+	}
+	if value := metrics[0].Value.AsApproximateFloat64(); value != 1 {
+		t.Errorf("Expected lag 1 but got %v", value)
+	}
+}
+
+// newKafkaMockBroker returns a broker serving topic my-topic with a single partition,
+// whose latest offset is 10 and whose my-group consumer offset is 9
+func newKafkaMockBroker(t *testing.T) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetController(broker.BrokerID()).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("my-topic", 0, broker.BrokerID()),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "my-group", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("my-group", "my-topic", 0, 9, "", sarama.ErrNoError),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("my-topic", 0, sarama.OffsetNewest, 10),
+	})
+	return broker
+}
+
+// BenchmarkKafkaScalerMemory reports the heap retained by each scaler after a poll of a topic with 3000 partitions,
+// in a cluster of 10 such topics. Without a topic the scalers read the topics of the consumer group and keep the
+// metadata of the whole cluster
+func BenchmarkKafkaScalerMemory(b *testing.B) {
+	b.Run("topic", func(b *testing.B) {
+		benchmarkKafkaScalerMemory(b, map[string]string{"topic": "topic-0"})
+	})
+	b.Run("consumer_group_topics", func(b *testing.B) {
+		benchmarkKafkaScalerMemory(b, map[string]string{})
+	})
+}
+
+func benchmarkKafkaScalerMemory(b *testing.B, metadata map[string]string) {
+	const topics, partitions = 10, 3000
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	broker := sarama.NewMockBroker(b, 1)
+	metadataResponse := sarama.NewMockMetadataResponse(b).
+		SetController(broker.BrokerID()).
+		SetBroker(broker.Addr(), broker.BrokerID())
+	offsetFetchResponse := sarama.NewMockOffsetFetchResponse(b)
+	offsetResponse := sarama.NewMockOffsetResponse(b)
+	for partition := int32(0); partition < partitions; partition++ {
+		for topic := 0; topic < topics; topic++ {
+			metadataResponse.SetLeader(fmt.Sprintf("topic-%d", topic), partition, broker.BrokerID())
+		}
+		offsetFetchResponse.SetOffset("my-group", "topic-0", partition, 9, "", sarama.ErrNoError)
+		offsetResponse.SetOffset("topic-0", partition, sarama.OffsetNewest, 10)
+	}
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": metadataResponse,
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(b).
+			SetCoordinator(sarama.CoordinatorGroup, "my-group", broker),
+		"OffsetFetchRequest": offsetFetchResponse,
+		"OffsetRequest":      offsetResponse,
+	})
+
+	triggerMetadata := map[string]string{"bootstrapServers": broker.Addr(), "consumerGroup": "my-group"}
+	for key, value := range metadata {
+		triggerMetadata[key] = value
+	}
+	scalers := make([]Scaler, 0, b.N)
+	defer func() {
+		for _, scaler := range scalers {
+			_ = scaler.Close(context.Background())
+		}
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scaler, err := NewKafkaScaler(&ScalerConfig{TriggerMetadata: triggerMetadata, AuthParams: map[string]string{}})
+		if err != nil {
+			b.Fatal("Could not create the scaler:", err)
+		}
+		scalers = append(scalers, scaler)
+		if _, _, err := scaler.GetMetricsAndActivity(context.Background(), "s0-kafka"); err != nil {
+			b.Fatal("Could not poll the scaler:", err)
+		}
+	}
+	b.StopTimer()
+
+	// the broker keeps the history of the requests and the responses
+	broker.Close()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/float64(b.N), "retained-B/scaler")
+}
+
+// unreachableClusterAdmin behaves as an admin which can't reach any broker anymore
+type unreachableClusterAdmin struct {
+	MockClusterAdmin
+	closed bool
+}
+
+func (m *unreachableClusterAdmin) DescribeTopics(_ []string) ([]*sarama.TopicMetadata, error) {
+	return nil, sarama.ErrOutOfBrokers
+}
+
+func (m *unreachableClusterAdmin) Close() error {
+	m.closed = true
+	return nil
+}
+
 func TestKafkaGetMetricSpecForScaling(t *testing.T) {
 	for _, testData := range kafkaMetricIdentifiers {
 		meta, err := parseKafkaMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: validWithAuthParams, ScalerIndex: testData.scalerIndex}, logr.Discard())
 		if err != nil {
 			t.Fatal("Could not parse metadata:", err)
 		}
-		mockKafkaScaler := kafkaScaler{"", meta, nil, nil, logr.Discard(), make(map[string]map[int32]int64)}
+		mockKafkaScaler := kafkaScaler{metadata: meta, logger: logr.Discard(), previousOffsets: make(map[string]map[int32]int64)}
 
 		metricSpec := mockKafkaScaler.GetMetricSpecForScaling(context.Background())
 		metricName := metricSpec[0].External.Metric.Name
@@ -432,7 +725,7 @@ func TestGetTopicPartitions(t *testing.T) {
 			if err != nil {
 				t.Fatal("Could not parse metadata:", err)
 			}
-			mockKafkaScaler := kafkaScaler{"", meta, nil, &MockClusterAdmin{partitionIds: tt.partitionIds}, logr.Discard(), make(map[string]map[int32]int64)}
+			mockKafkaScaler := kafkaScaler{metadata: meta, admin: &MockClusterAdmin{partitionIds: tt.partitionIds}, logger: logr.Discard(), previousOffsets: make(map[string]map[int32]int64)}
 
 			partitions, err := mockKafkaScaler.getTopicPartitions()
 
@@ -0,0 +1,280 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// KubernetesResourceInformers gives the kubernetes-resource scalers the shared informers of the resources they count
+type KubernetesResourceInformers interface {
+	// Acquire returns the store of the informer of the kind in the namespace, starting the informer if needed.
+	// The informer is stopped once the stores of all its users are released.
+	Acquire(gvk schema.GroupVersionKind, namespace string) (store KubernetesResourceStore, release func(), err error)
+}
+
+// KubernetesResourceStore is the store of a shared informer
+type KubernetesResourceStore interface {
+	HasSynced() bool
+	List() []interface{}
+}
+
+type kubernetesResourceScaler struct {
+	metricType v2.MetricTargetType
+	metadata   *kubernetesResourceMetadata
+	store      KubernetesResourceStore
+	release    func()
+	closeOnce  sync.Once
+	logger     logr.Logger
+}
+
+type kubernetesResourceMetadata struct {
+	gvk             schema.GroupVersionKind
+	namespace       string
+	labelSelector   labels.Selector
+	fieldSelector   fields.Selector
+	jsonPath        []kubernetesResourcePathSegment
+	jsonPathValue   string
+	value           float64
+	activationValue float64
+	scalerIndex     int
+}
+
+// kubernetesResourcePathSegment is a field name, or an index if the field name is empty
+type kubernetesResourcePathSegment struct {
+	field string
+	index int
+}
+
+// NewKubernetesResourceScaler creates a new kubernetesResourceScaler counting the objects in the store of a shared informer
+func NewKubernetesResourceScaler(informers KubernetesResourceInformers, config *ScalerConfig) (Scaler, error) {
+	metricType, err := GetMetricTargetType(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scaler metric type: %w", err)
+	}
+
+	meta, err := parseKubernetesResourceMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing kubernetes resource metadata: %w", err)
+	}
+
+	store, release, err := informers.Acquire(meta.gvk, meta.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the informer of %s: %w", meta.gvk, err)
+	}
+
+	return &kubernetesResourceScaler{
+		metricType: metricType,
+		metadata:   meta,
+		store:      store,
+		release:    release,
+		logger:     InitializeLogger(config, "kubernetes_resource_scaler"),
+	}, nil
+}
+
+func parseKubernetesResourceMetadata(config *ScalerConfig) (*kubernetesResourceMetadata, error) {
+	meta := &kubernetesResourceMetadata{}
+	meta.namespace = config.ScalableObjectNamespace
+
+	gv, err := schema.ParseGroupVersion(config.TriggerMetadata["apiVersion"])
+	if err != nil || gv.Version == "" {
+		return nil, fmt.Errorf("apiVersion must be a group/version or a version of the core group")
+	}
+	kind := config.TriggerMetadata["kind"]
+	if kind == "" {
+		return nil, fmt.Errorf("no kind given")
+	}
+	meta.gvk = gv.WithKind(kind)
+
+	meta.labelSelector, err = labels.Parse(config.TriggerMetadata["labelSelector"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid labelSelector: %w", err)
+	}
+	meta.fieldSelector, err = fields.ParseSelector(config.TriggerMetadata["fieldSelector"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid fieldSelector: %w", err)
+	}
+	if val, ok := config.TriggerMetadata["jsonPath"]; ok && val != "" {
+		meta.jsonPath, err = parseKubernetesResourcePath(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonPath: %w", err)
+		}
+		meta.jsonPathValue = config.TriggerMetadata["jsonPathValue"]
+	} else if config.TriggerMetadata["jsonPathValue"] != "" {
+		return nil, fmt.Errorf("jsonPathValue given without jsonPath")
+	}
+
+	value, err := strconv.ParseFloat(config.TriggerMetadata[valueKey], 64)
+	if err != nil || value <= 0 {
+		return nil, fmt.Errorf("value must be a float greater than 0")
+	}
+	meta.value = value
+
+	if val, ok := config.TriggerMetadata[activationValueKey]; ok {
+		meta.activationValue, err = strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationValue must be a float")
+		}
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// parseKubernetesResourcePath parses a path like {.status.conditions[0].type}, the filters of JSONPath aren't supported
+func parseKubernetesResourcePath(path string) ([]kubernetesResourcePathSegment, error) {
+	path = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(path), "{"), "}")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var segments []kubernetesResourcePathSegment
+	for _, part := range strings.Split(path, ".") {
+		field, indexes, _ := strings.Cut(part, "[")
+		if field == "" && indexes == "" {
+			return nil, fmt.Errorf("empty field in %q", path)
+		}
+		if field != "" {
+			segments = append(segments, kubernetesResourcePathSegment{field: field})
+		}
+		if indexes == "" {
+			continue
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("unsupported index [%s] in %q", index, path)
+			}
+			segments = append(segments, kubernetesResourcePathSegment{index: i})
+		}
+	}
+	return segments, nil
+}
+
+// lookupKubernetesResourcePath returns the value at the path of the object as a string
+func lookupKubernetesResourcePath(object map[string]interface{}, path []kubernetesResourcePathSegment) (string, bool) {
+	var current interface{} = object
+	for _, segment := range path {
+		if segment.field != "" {
+			fields, ok := current.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+			if current, ok = fields[segment.field]; !ok {
+				return "", false
+			}
+			continue
+		}
+		items, ok := current.([]interface{})
+		if !ok || segment.index >= len(items) {
+			return "", false
+		}
+		current = items[segment.index]
+	}
+
+	switch value := current.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	case map[string]interface{}, []interface{}:
+		return "", true
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// kubernetesResourceFields matches the field selectors against any field of the object, not only the ones
+// supported by the API server
+type kubernetesResourceFields map[string]interface{}
+
+func (f kubernetesResourceFields) Has(field string) bool {
+	_, found := f.lookup(field)
+	return found
+}
+
+func (f kubernetesResourceFields) Get(field string) string {
+	value, _ := f.lookup(field)
+	return value
+}
+
+func (f kubernetesResourceFields) lookup(field string) (string, bool) {
+	path, err := parseKubernetesResourcePath(field)
+	if err != nil {
+		return "", false
+	}
+	return lookupKubernetesResourcePath(f, path)
+}
+
+// Close releases the informer of the scaler
+func (s *kubernetesResourceScaler) Close(context.Context) error {
+	s.closeOnce.Do(s.release)
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *kubernetesResourceScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	externalMetric := &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("resource-%s", strings.ToLower(s.metadata.gvk.Kind)))),
+		},
+		Target: GetMetricTargetMili(s.metricType, s.metadata.value),
+	}
+	metricSpec := v2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2.MetricSpec{metricSpec}
+}
+
+// GetMetricsAndActivity returns the number of matching objects
+func (s *kubernetesResourceScaler) GetMetricsAndActivity(_ context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	if !s.store.HasSynced() {
+		return []external_metrics.ExternalMetricValue{}, false, fmt.Errorf("the informer of %s in namespace %s isn't synced yet", s.metadata.gvk, s.metadata.namespace)
+	}
+
+	count := s.countObjects()
+	metric := GenerateMetricInMili(metricName, float64(count))
+
+	return []external_metrics.ExternalMetricValue{metric}, float64(count) > s.metadata.activationValue, nil
+}
+
+func (s *kubernetesResourceScaler) countObjects() int64 {
+	var count int64
+	for _, item := range s.store.List() {
+		object, ok := item.(*unstructured.Unstructured)
+		if !ok || !s.matches(object) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+func (s *kubernetesResourceScaler) matches(object *unstructured.Unstructured) bool {
+	if !s.metadata.labelSelector.Matches(labels.Set(object.GetLabels())) {
+		return false
+	}
+	if !s.metadata.fieldSelector.Empty() && !s.metadata.fieldSelector.Matches(kubernetesResourceFields(object.Object)) {
+		return false
+	}
+	if s.metadata.jsonPath == nil {
+		return true
+	}
+	value, found := lookupKubernetesResourcePath(object.Object, s.metadata.jsonPath)
+	if s.metadata.jsonPathValue == "" {
+		// the objects match if the field is set and isn't false
+		return found && value != "false"
+	}
+	return found && value == s.metadata.jsonPathValue
+}
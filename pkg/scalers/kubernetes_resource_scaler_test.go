@@ -0,0 +1,151 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type kubernetesResourceMetadataTestData struct {
+	name     string
+	metadata map[string]string
+	isError  bool
+}
+
+var parseKubernetesResourceMetadataTestDataset = []kubernetesResourceMetadataTestData{
+	{"custom resource", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "value": "10"}, false},
+	{"core group", map[string]string{"apiVersion": "v1", "kind": "ConfigMap", "value": "1"}, false},
+	{"selectors", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "labelSelector": "queue in (a, b)", "fieldSelector": "status.phase=Pending", "value": "1"}, false},
+	{"jsonPath", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "jsonPath": "{.status.conditions[0].type}", "jsonPathValue": "Ready", "value": "1", "activationValue": "5"}, false},
+	{"no apiVersion", map[string]string{"kind": "WorkItem", "value": "1"}, true},
+	{"no version", map[string]string{"apiVersion": "example.com/", "kind": "WorkItem", "value": "1"}, true},
+	{"no kind", map[string]string{"apiVersion": "example.com/v1", "value": "1"}, true},
+	{"invalid labelSelector", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "labelSelector": "queue in (", "value": "1"}, true},
+	{"invalid fieldSelector", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "fieldSelector": "status.phase", "value": "1"}, true},
+	{"JSONPath filter", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "jsonPath": "{.status.conditions[?(@.type==\"Ready\")]}", "value": "1"}, true},
+	{"jsonPathValue without jsonPath", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "jsonPathValue": "Ready", "value": "1"}, true},
+	{"no value", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem"}, true},
+	{"zero value", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "value": "0"}, true},
+	{"invalid activationValue", map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "value": "1", "activationValue": "a"}, true},
+}
+
+func TestParseKubernetesResourceMetadata(t *testing.T) {
+	for _, testData := range parseKubernetesResourceMetadataTestDataset {
+		t.Run(testData.name, func(t *testing.T) {
+			_, err := parseKubernetesResourceMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ScalableObjectNamespace: "test"})
+			if testData.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+type fakeKubernetesResourceInformers struct {
+	store    *fakeKubernetesResourceStore
+	acquired []schema.GroupVersionKind
+	released int
+}
+
+func (f *fakeKubernetesResourceInformers) Acquire(gvk schema.GroupVersionKind, namespace string) (KubernetesResourceStore, func(), error) {
+	f.acquired = append(f.acquired, gvk.GroupVersion().WithKind(namespace+"/"+gvk.Kind))
+	return f.store, func() { f.released++ }, nil
+}
+
+type fakeKubernetesResourceStore struct {
+	synced  bool
+	objects []interface{}
+}
+
+func (f *fakeKubernetesResourceStore) HasSynced() bool {
+	return f.synced
+}
+
+func (f *fakeKubernetesResourceStore) List() []interface{} {
+	return f.objects
+}
+
+func newKubernetesResourceTestObject(labels map[string]string, object map[string]interface{}) *unstructured.Unstructured {
+	item := &unstructured.Unstructured{Object: object}
+	item.SetLabels(labels)
+	return item
+}
+
+type kubernetesResourceCountTestData struct {
+	name          string
+	metadata      map[string]string
+	expectedCount int64
+	active        bool
+}
+
+var kubernetesResourceCountTestDataset = []kubernetesResourceCountTestData{
+	{"all objects", map[string]string{}, 5, true},
+	{"labelSelector", map[string]string{"labelSelector": "queue=a"}, 3, true},
+	{"fieldSelector", map[string]string{"fieldSelector": "status.phase=Pending"}, 3, true},
+	{"fieldSelector on a number", map[string]string{"fieldSelector": "spec.priority!=1"}, 4, true},
+	{"both selectors", map[string]string{"labelSelector": "queue=a", "fieldSelector": "status.phase=Pending"}, 2, true},
+	{"jsonPath value", map[string]string{"jsonPath": "{.status.conditions[0].type}", "jsonPathValue": "Ready"}, 1, true},
+	{"jsonPath set", map[string]string{"jsonPath": ".status.conditions"}, 2, true},
+	{"jsonPath not false", map[string]string{"jsonPath": "spec.suspend"}, 1, true},
+	{"activationValue", map[string]string{"fieldSelector": "status.phase=Pending", "activationValue": "3"}, 3, false},
+}
+
+func TestKubernetesResourceCount(t *testing.T) {
+	store := &fakeKubernetesResourceStore{synced: true, objects: []interface{}{
+		newKubernetesResourceTestObject(map[string]string{"queue": "a"}, map[string]interface{}{
+			"spec":   map[string]interface{}{"priority": int64(1), "suspend": true},
+			"status": map[string]interface{}{"phase": "Pending"},
+		}),
+		newKubernetesResourceTestObject(map[string]string{"queue": "a"}, map[string]interface{}{
+			"spec":   map[string]interface{}{"suspend": false},
+			"status": map[string]interface{}{"phase": "Pending", "conditions": []interface{}{map[string]interface{}{"type": "Ready"}}},
+		}),
+		newKubernetesResourceTestObject(map[string]string{"queue": "a"}, map[string]interface{}{
+			"status": map[string]interface{}{"phase": "Running", "conditions": []interface{}{map[string]interface{}{"type": "Progressing"}}},
+		}),
+		newKubernetesResourceTestObject(map[string]string{"queue": "b"}, map[string]interface{}{
+			"status": map[string]interface{}{"phase": "Pending"},
+		}),
+		newKubernetesResourceTestObject(nil, map[string]interface{}{}),
+	}}
+
+	for _, testData := range kubernetesResourceCountTestDataset {
+		t.Run(testData.name, func(t *testing.T) {
+			metadata := map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "value": "1"}
+			for key, value := range testData.metadata {
+				metadata[key] = value
+			}
+			informers := &fakeKubernetesResourceInformers{store: store}
+			scaler, err := NewKubernetesResourceScaler(informers, &ScalerConfig{TriggerMetadata: metadata, ScalableObjectNamespace: "test"})
+			assert.NoError(t, err)
+			assert.Equal(t, []schema.GroupVersionKind{{Group: "example.com", Version: "v1", Kind: "test/WorkItem"}}, informers.acquired)
+
+			metrics, active, err := scaler.GetMetricsAndActivity(context.Background(), "s0-resource-workitem")
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedCount, metrics[0].Value.Value())
+			assert.Equal(t, testData.active, active)
+
+			// the informer is released once even if the scaler is closed again after a refresh
+			assert.NoError(t, scaler.Close(context.Background()))
+			assert.NoError(t, scaler.Close(context.Background()))
+			assert.Equal(t, 1, informers.released)
+		})
+	}
+}
+
+func TestKubernetesResourceNotSynced(t *testing.T) {
+	informers := &fakeKubernetesResourceInformers{store: &fakeKubernetesResourceStore{}}
+	scaler, err := NewKubernetesResourceScaler(informers, &ScalerConfig{
+		TriggerMetadata:         map[string]string{"apiVersion": "example.com/v1", "kind": "WorkItem", "value": "1"},
+		ScalableObjectNamespace: "test",
+	})
+	assert.NoError(t, err)
+
+	_, _, err = scaler.GetMetricsAndActivity(context.Background(), "s0-resource-workitem")
+	assert.Error(t, err)
+	assert.Equal(t, "s0-resource-workitem", scaler.GetMetricSpecForScaling(context.Background())[0].External.Metric.Name)
+}
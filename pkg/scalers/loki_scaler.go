@@ -77,7 +77,7 @@ func NewLokiScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing loki metadata: %w", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, meta.unsafeSsl)
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, meta.unsafeSsl, config.HTTPProxy)
 
 	return &lokiScaler{
 		metricType: metricType,
@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
@@ -32,7 +33,10 @@ type metricsAPIScalerMetadata struct {
 	activationTargetValue float64
 	url                   string
 	valueLocation         string
+	aggregation           string
+	ignoreEmpty           bool
 	unsafeSsl             bool
+	serverPublicKeyPin    string
 
 	// apiKeyAuth
 	enableAPIKeyAuth bool
@@ -62,6 +66,11 @@ type metricsAPIScalerMetadata struct {
 
 const (
 	methodValueQuery = "query"
+
+	aggregationSum   = "sum"
+	aggregationMax   = "max"
+	aggregationAvg   = "avg"
+	aggregationCount = "count"
 )
 
 // NewMetricsAPIScaler creates a new HTTP scaler
@@ -76,14 +85,17 @@ func NewMetricsAPIScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing metric API metadata: %w", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, meta.unsafeSsl)
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, meta.unsafeSsl, config.HTTPProxy)
 
-	if meta.enableTLS || len(meta.ca) > 0 {
-		config, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca, meta.unsafeSsl)
+	if meta.enableTLS || len(meta.ca) > 0 || meta.serverPublicKeyPin != "" {
+		tlsConfig, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca, meta.unsafeSsl)
 		if err != nil {
 			return nil, err
 		}
-		httpClient.Transport = kedautil.CreateHTTPTransportWithTLSConfig(config)
+		if err := kedautil.PinServerPublicKey(tlsConfig, meta.serverPublicKeyPin); err != nil {
+			return nil, err
+		}
+		httpClient.Transport = kedautil.CreateHTTPTransportWithProxy(tlsConfig, config.HTTPProxy)
 	}
 
 	return &metricsAPIScaler{
@@ -107,6 +119,12 @@ func parseMetricsAPIMetadata(config *ScalerConfig) (*metricsAPIScalerMetadata, e
 		meta.unsafeSsl = unsafeSsl
 	}
 
+	pin, err := getTLSServerPublicKeyPin(config)
+	if err != nil {
+		return nil, err
+	}
+	meta.serverPublicKeyPin = pin
+
 	if val, ok := config.TriggerMetadata["targetValue"]; ok {
 		targetValue, err := strconv.ParseFloat(val, 64)
 		if err != nil {
@@ -138,6 +156,24 @@ func parseMetricsAPIMetadata(config *ScalerConfig) (*metricsAPIScalerMetadata, e
 		return nil, fmt.Errorf("no valueLocation given in metadata")
 	}
 
+	if val, ok := config.TriggerMetadata["aggregation"]; ok && val != "" {
+		switch val {
+		case aggregationSum, aggregationMax, aggregationAvg, aggregationCount:
+			meta.aggregation = val
+		default:
+			return nil, fmt.Errorf("aggregation must be one of %s, %s, %s or %s, got %s", aggregationSum, aggregationMax, aggregationAvg, aggregationCount, val)
+		}
+	}
+
+	meta.ignoreEmpty = false
+	if val, ok := config.TriggerMetadata["ignoreEmpty"]; ok {
+		ignoreEmpty, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing ignoreEmpty: %w", err)
+		}
+		meta.ignoreEmpty = ignoreEmpty
+	}
+
 	authMode, ok := config.TriggerMetadata["authMode"]
 	// no authMode specified
 	if !ok {
@@ -215,6 +251,7 @@ func GetValueFromResponse(body []byte, valueLocation string) (float64, error) {
 		if err != nil {
 			return 0, fmt.Errorf(errorMsg, r.String())
 		}
+		prommetrics.RecordScalerValueCoercion("metricsAPIScaler")
 		return v.AsApproximateFloat64(), nil
 	}
 	if r.Type != gjson.Number {
@@ -223,6 +260,85 @@ func GetValueFromResponse(body []byte, valueLocation string) (float64, error) {
 	return r.Num, nil
 }
 
+// GetAggregatedValueFromResponse uses provided valueLocation to select the values in provided body,
+// which can be spread over (nested) arrays, and aggregates them. The selected values must all be of the same type,
+// numbers or strings representing a Quantity unless they are only counted. An empty selection is an error, or 0 if ignoreEmpty is set
+func GetAggregatedValueFromResponse(body []byte, valueLocation string, aggregation string, ignoreEmpty bool) (float64, error) {
+	values := flattenJSONResults(gjson.GetBytes(body, valueLocation), nil)
+	if len(values) == 0 {
+		if ignoreEmpty {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("valueLocation '%s' didn't select any value", valueLocation)
+	}
+
+	for _, v := range values[1:] {
+		if jsonTypeName(v) != jsonTypeName(values[0]) {
+			return 0, fmt.Errorf("valueLocation '%s' selects values of mixed types: %s and %s", valueLocation, jsonTypeName(values[0]), jsonTypeName(v))
+		}
+	}
+
+	if aggregation == aggregationCount {
+		return float64(len(values)), nil
+	}
+
+	var sum, maxValue float64
+	for i, v := range values {
+		var num float64
+		switch v.Type {
+		case gjson.Number:
+			num = v.Num
+		case gjson.String:
+			q, err := resource.ParseQuantity(v.String())
+			if err != nil {
+				return 0, fmt.Errorf("valueLocation must select values of type number or strings representing a Quantity got: '%s'", v.String())
+			}
+			prommetrics.RecordScalerValueCoercion("metricsAPIScaler")
+			num = q.AsApproximateFloat64()
+		default:
+			return 0, fmt.Errorf("valueLocation must select values of type number or strings representing a Quantity got: '%s'", jsonTypeName(v))
+		}
+		sum += num
+		if i == 0 || num > maxValue {
+			maxValue = num
+		}
+	}
+
+	switch aggregation {
+	case aggregationMax:
+		return maxValue, nil
+	case aggregationAvg:
+		return sum / float64(len(values)), nil
+	default:
+		return sum, nil
+	}
+}
+
+// flattenJSONResults appends the values of the result to the list, descending into nested arrays
+func flattenJSONResults(r gjson.Result, values []gjson.Result) []gjson.Result {
+	if !r.Exists() {
+		return values
+	}
+	if !r.IsArray() {
+		return append(values, r)
+	}
+	for _, v := range r.Array() {
+		values = flattenJSONResults(v, values)
+	}
+	return values
+}
+
+func jsonTypeName(r gjson.Result) string {
+	switch {
+	case r.Type == gjson.True || r.Type == gjson.False:
+		return "bool"
+	case r.IsObject():
+		return "object"
+	default:
+		return r.Type.String()
+	}
+}
+
 func (s *metricsAPIScaler) getMetricValue(ctx context.Context) (float64, error) {
 	request, err := getMetricAPIServerRequest(ctx, s.metadata)
 	if err != nil {
@@ -231,19 +347,25 @@ func (s *metricsAPIScaler) getMetricValue(ctx context.Context) (float64, error)
 
 	r, err := s.client.Do(request)
 	if err != nil {
-		return 0, err
+		return 0, wrapHTTPRequestError(err)
 	}
 	defer r.Body.Close()
 
 	if r.StatusCode != http.StatusOK {
 		msg := fmt.Sprintf("%s: api returned %d", r.Request.URL.Path, r.StatusCode)
-		return 0, errors.New(msg)
+		return 0, wrapHTTPStatusError(r.StatusCode, errors.New(msg))
 	}
 
 	b, err := io.ReadAll(r.Body)
 	if err != nil {
 		return 0, err
 	}
+	if s.metadata.aggregation != "" {
+		return GetAggregatedValueFromResponse(b, s.metadata.valueLocation, s.metadata.aggregation, s.metadata.ignoreEmpty)
+	}
+	if s.metadata.ignoreEmpty && !gjson.GetBytes(b, s.metadata.valueLocation).Exists() {
+		return 0, nil
+	}
 	v, err := GetValueFromResponse(b, s.metadata.valueLocation)
 	if err != nil {
 		return 0, err
@@ -32,6 +32,12 @@ var testMetricsAPIMetadata = []metricsAPIMetadataTestData{
 	{metadata: map[string]string{"valueLocation": "metric", "targetValue": "aa"}, raisesError: true},
 	// Missing targetValue
 	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric"}, raisesError: true},
+	// OK aggregation
+	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "items.#.pending", "targetValue": "42", "aggregation": "sum", "ignoreEmpty": "true"}, raisesError: false},
+	// Unknown aggregation
+	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "items.#.pending", "targetValue": "42", "aggregation": "median"}, raisesError: true},
+	// ignoreEmpty not a bool
+	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "items.#.pending", "targetValue": "42", "aggregation": "sum", "ignoreEmpty": "yes"}, raisesError: true},
 }
 
 type metricAPIAuthMetadataTestData struct {
@@ -160,6 +166,109 @@ func TestGetValueFromResponse(t *testing.T) {
 	}
 }
 
+func TestGetValueFromResponseCoercions(t *testing.T) {
+	d := []byte(`{"number": 32, "str": "64", "values": ["1", "2"]}`)
+	coercions := gatheredMetricValue(t, "keda_scaler_value_coercions_total", "scaler", "metricsAPIScaler")
+
+	_, err := GetValueFromResponse(d, "number")
+	assert.NoError(t, err)
+	assert.Equal(t, coercions, gatheredMetricValue(t, "keda_scaler_value_coercions_total", "scaler", "metricsAPIScaler"))
+
+	_, err = GetValueFromResponse(d, "str")
+	assert.NoError(t, err)
+	assert.Equal(t, coercions+1, gatheredMetricValue(t, "keda_scaler_value_coercions_total", "scaler", "metricsAPIScaler"))
+
+	// every value parsed from a string is counted
+	_, err = GetAggregatedValueFromResponse(d, "values", aggregationSum, false)
+	assert.NoError(t, err)
+	assert.Equal(t, coercions+3, gatheredMetricValue(t, "keda_scaler_value_coercions_total", "scaler", "metricsAPIScaler"))
+}
+
+type metricsAPIAggregationTestData struct {
+	name          string
+	body          string
+	valueLocation string
+	aggregation   string
+	ignoreEmpty   bool
+	value         float64
+	isError       bool
+}
+
+var testMetricsAPIAggregations = []metricsAPIAggregationTestData{
+	{name: "sum", body: `{"items":[{"pending":3},{"pending":4},{"pending":5}]}`, valueLocation: "items.#.pending", aggregation: "sum", value: 12},
+	{name: "max", body: `{"items":[{"pending":3},{"pending":7},{"pending":5}]}`, valueLocation: "items.#.pending", aggregation: "max", value: 7},
+	{name: "max of negative values", body: `{"items":[{"pending":-3},{"pending":-1}]}`, valueLocation: "items.#.pending", aggregation: "max", value: -1},
+	{name: "avg", body: `{"items":[{"pending":3},{"pending":4},{"pending":8}]}`, valueLocation: "items.#.pending", aggregation: "avg", value: 5},
+	{name: "count", body: `{"items":[{"pending":3},{"pending":4},{"pending":8}]}`, valueLocation: "items.#.pending", aggregation: "count", value: 3},
+	{name: "count of objects", body: `{"items":[{"pending":3},{"pending":4}]}`, valueLocation: "items", aggregation: "count", value: 2},
+	{name: "quantities", body: `{"items":[{"pending":"1k"},{"pending":"500"}]}`, valueLocation: "items.#.pending", aggregation: "sum", value: 1500},
+	{name: "single value", body: `{"pending":3}`, valueLocation: "pending", aggregation: "sum", value: 3},
+	{name: "items without the field are skipped", body: `{"items":[{"pending":3},{"done":4},{"pending":5}]}`, valueLocation: "items.#.pending", aggregation: "avg", value: 4},
+	{name: "nested arrays", body: `{"queues":[{"partitions":[{"pending":1},{"pending":2}]},{"partitions":[{"pending":3}]}]}`, valueLocation: "queues.#.partitions.#.pending", aggregation: "sum", value: 6},
+	{name: "nested arrays max", body: `{"queues":[{"partitions":[{"pending":1},{"pending":9}]},{"partitions":[{"pending":3}]}]}`, valueLocation: "queues.#.partitions.#.pending", aggregation: "max", value: 9},
+	{name: "nested arrays count", body: `{"queues":[{"partitions":[{"pending":1},{"pending":9}]},{"partitions":[]},{"partitions":[{"pending":3}]}]}`, valueLocation: "queues.#.partitions.#.pending", aggregation: "count", value: 3},
+	{name: "array of arrays", body: `{"matrix":[[1,2],[3,[4]]]}`, valueLocation: "matrix", aggregation: "sum", value: 10},
+	{name: "empty array", body: `{"items":[]}`, valueLocation: "items.#.pending", aggregation: "sum", isError: true},
+	{name: "empty array ignored", body: `{"items":[]}`, valueLocation: "items.#.pending", aggregation: "sum", ignoreEmpty: true, value: 0},
+	{name: "empty nested arrays ignored", body: `{"queues":[{"partitions":[]}]}`, valueLocation: "queues.#.partitions.#.pending", aggregation: "max", ignoreEmpty: true, value: 0},
+	{name: "missing location", body: `{"other":1}`, valueLocation: "items.#.pending", aggregation: "count", isError: true},
+	{name: "missing location ignored", body: `{"other":1}`, valueLocation: "items.#.pending", aggregation: "count", ignoreEmpty: true, value: 0},
+	{name: "mixed numbers and strings", body: `{"items":[{"pending":3},{"pending":"4"}]}`, valueLocation: "items.#.pending", aggregation: "sum", isError: true},
+	{name: "mixed numbers and objects counted", body: `{"items":[{"pending":3},{"pending":{"count":4}}]}`, valueLocation: "items.#.pending", aggregation: "count", isError: true},
+	{name: "non-numeric strings", body: `{"items":[{"pending":"many"},{"pending":"few"}]}`, valueLocation: "items.#.pending", aggregation: "sum", isError: true},
+	{name: "booleans", body: `{"items":[{"pending":true},{"pending":false}]}`, valueLocation: "items.#.pending", aggregation: "max", isError: true},
+	{name: "booleans counted", body: `{"items":[{"pending":true},{"pending":false}]}`, valueLocation: "items.#.pending", aggregation: "count", value: 2},
+	{name: "objects", body: `{"items":[{"pending":3},{"pending":4}]}`, valueLocation: "items", aggregation: "sum", isError: true},
+	{name: "nulls", body: `{"items":[{"pending":null}]}`, valueLocation: "items.#.pending", aggregation: "avg", isError: true},
+}
+
+func TestGetAggregatedValueFromResponse(t *testing.T) {
+	for _, testData := range testMetricsAPIAggregations {
+		v, err := GetAggregatedValueFromResponse([]byte(testData.body), testData.valueLocation, testData.aggregation, testData.ignoreEmpty)
+		if testData.isError {
+			assert.Error(t, err, testData.name)
+			continue
+		}
+		assert.NoError(t, err, testData.name)
+		assert.Equal(t, testData.value, v, testData.name)
+	}
+}
+
+func TestMetricsAPIScalerAggregationActivity(t *testing.T) {
+	body := `{"items":[]}`
+	var apiStub = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer apiStub.Close()
+
+	s, err := NewMetricsAPIScaler(
+		&ScalerConfig{
+			TriggerMetadata: map[string]string{
+				"url":           apiStub.URL,
+				"valueLocation": "items.#.pending",
+				"targetValue":   "10",
+				"aggregation":   "sum",
+				"ignoreEmpty":   "true",
+			},
+			AuthParams:        map[string]string{},
+			GlobalHTTPTimeout: 3000 * time.Millisecond,
+		},
+	)
+	assert.NoError(t, err)
+
+	metrics, isActive, err := s.GetMetricsAndActivity(context.TODO(), "test-metric")
+	assert.NoError(t, err)
+	assert.False(t, isActive)
+	assert.Equal(t, int64(0), metrics[0].Value.MilliValue())
+
+	body = `{"items":[{"pending":2},{"pending":5}]}`
+	metrics, isActive, err = s.GetMetricsAndActivity(context.TODO(), "test-metric")
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+	assert.Equal(t, int64(7000), metrics[0].Value.MilliValue())
+}
+
 func TestMetricAPIScalerAuthParams(t *testing.T) {
 	for _, testData := range testMetricsAPIAuthMetadata {
 		meta, err := parseMetricsAPIMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
@@ -127,7 +127,7 @@ func NewNATSJetStreamScaler(config *ScalerConfig) (Scaler, error) {
 		metricType: metricType,
 		stream:     &streamDetail{},
 		metadata:   jsMetadata,
-		httpClient: kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient: kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy),
 		logger:     InitializeLogger(config, "nats_jetstream_scaler"),
 	}, nil
 }
@@ -0,0 +1,316 @@
+package scalers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var errPromExpressionDivisionByZero = errors.New("division by zero")
+
+// promExpression is an arithmetic expression over the named results of the prometheus queries,
+// it supports numbers, query names, + - * /, parentheses and the max, min and abs functions
+type promExpression interface {
+	evaluate(values map[string]float64) (float64, error)
+}
+
+type promNumber float64
+
+func (n promNumber) evaluate(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+type promQueryRef string
+
+func (r promQueryRef) evaluate(values map[string]float64) (float64, error) {
+	value, ok := values[string(r)]
+	if !ok {
+		return 0, fmt.Errorf("no result for query %s", string(r))
+	}
+	return value, nil
+}
+
+type promNegation struct {
+	operand promExpression
+}
+
+func (n promNegation) evaluate(values map[string]float64) (float64, error) {
+	value, err := n.operand.evaluate(values)
+	return -value, err
+}
+
+type promBinaryOperation struct {
+	operator    byte
+	left, right promExpression
+}
+
+func (o promBinaryOperation) evaluate(values map[string]float64) (float64, error) {
+	left, err := o.left.evaluate(values)
+	if err != nil {
+		return 0, err
+	}
+	right, err := o.right.evaluate(values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch o.operator {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		if right == 0 {
+			return 0, errPromExpressionDivisionByZero
+		}
+		return left / right, nil
+	}
+}
+
+type promFunctionCall struct {
+	function  string
+	arguments []promExpression
+}
+
+func (c promFunctionCall) evaluate(values map[string]float64) (float64, error) {
+	results := make([]float64, len(c.arguments))
+	for i, argument := range c.arguments {
+		value, err := argument.evaluate(values)
+		if err != nil {
+			return 0, err
+		}
+		results[i] = value
+	}
+
+	switch c.function {
+	case "abs":
+		return math.Abs(results[0]), nil
+	case "max":
+		result := results[0]
+		for _, value := range results[1:] {
+			result = math.Max(result, value)
+		}
+		return result, nil
+	default:
+		result := results[0]
+		for _, value := range results[1:] {
+			result = math.Min(result, value)
+		}
+		return result, nil
+	}
+}
+
+// promExpressionParser is a recursive descent parser of the expressions, the names are the defined query names
+type promExpressionParser struct {
+	input string
+	pos   int
+	names map[string]bool
+}
+
+// parsePromExpression parses the expression, it fails if the expression refers to a query which isn't defined
+func parsePromExpression(input string, names map[string]bool) (promExpression, error) {
+	p := &promExpressionParser{input: input, names: names}
+	expression, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	return expression, nil
+}
+
+func (p *promExpressionParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next non space character, 0 at the end of the input
+func (p *promExpressionParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *promExpressionParser) parseSum() (promExpression, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator := p.peek()
+		if operator != '+' && operator != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = promBinaryOperation{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *promExpressionParser) parseProduct() (promExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator := p.peek()
+		if operator != '*' && operator != '/' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = promBinaryOperation{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *promExpressionParser) parseUnary() (promExpression, error) {
+	if p.peek() == '-' {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return promNegation{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *promExpressionParser) parsePrimary() (promExpression, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		expression, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ) at position %d", p.pos)
+		}
+		p.pos++
+		return expression, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] == '.' || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9')) {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", p.input[start:p.pos])
+		}
+		return promNumber(value), nil
+	case isPromNameStart(c):
+		start := p.pos
+		for p.pos < len(p.input) && isPromNamePart(p.input[p.pos]) {
+			p.pos++
+		}
+		name := p.input[start:p.pos]
+		if p.peek() == '(' {
+			return p.parseFunctionCall(name)
+		}
+		if !p.names[name] {
+			return nil, fmt.Errorf("unknown query %s", name)
+		}
+		return promQueryRef(name), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+}
+
+func (p *promExpressionParser) parseFunctionCall(function string) (promExpression, error) {
+	if function != "abs" && function != "max" && function != "min" {
+		return nil, fmt.Errorf("unknown function %s", function)
+	}
+	// skip the opening parenthesis
+	p.pos++
+
+	var arguments []promExpression
+	for {
+		argument, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+
+		c := p.peek()
+		p.pos++
+		if c == ')' {
+			break
+		}
+		if c != ',' {
+			return nil, fmt.Errorf("missing ) of %s at position %d", function, p.pos-1)
+		}
+	}
+
+	if function == "abs" && len(arguments) != 1 {
+		return nil, fmt.Errorf("abs takes 1 argument, %d given", len(arguments))
+	}
+	return promFunctionCall{function: function, arguments: arguments}, nil
+}
+
+func isPromNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isPromNamePart(c byte) bool {
+	return isPromNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// parsePromQueries parses the named queries, one name=query per line
+func parsePromQueries(input string) ([]promNamedQuery, error) {
+	var queries []promNamedQuery
+	names := map[string]bool{}
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, query, found := strings.Cut(line, "=")
+		name, query = strings.TrimSpace(name), strings.TrimSpace(query)
+		if !found || name == "" || query == "" {
+			return nil, fmt.Errorf("invalid query %q, expected name=query", line)
+		}
+		if !isPromName(name) {
+			return nil, fmt.Errorf("invalid query name %s", name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate query name %s", name)
+		}
+		names[name] = true
+		queries = append(queries, promNamedQuery{name: name, query: query})
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no query given")
+	}
+	return queries, nil
+}
+
+func isPromName(name string) bool {
+	if !isPromNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isPromNamePart(name[i]) {
+			return false
+		}
+	}
+	return true
+}
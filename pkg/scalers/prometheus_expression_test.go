@@ -0,0 +1,143 @@
+package scalers
+
+import (
+	"errors"
+	"testing"
+)
+
+type promExpressionTestData struct {
+	expression string
+	expected   float64
+	isError    bool
+}
+
+var promExpressionNames = map[string]bool{"a": true, "b": true, "produced_rate": true}
+
+var promExpressionValues = map[string]float64{"a": 10, "b": 4, "produced_rate": -3}
+
+var testPromExpressions = []promExpressionTestData{
+	{expression: "a - b", expected: 6},
+	{expression: "a+b*2", expected: 18},
+	{expression: "(a + b) * 2", expected: 28},
+	{expression: "a / b", expected: 2.5},
+	{expression: "a - b - 1", expected: 5},
+	{expression: "a / b / 5", expected: 0.5},
+	{expression: "-a + b", expected: -6},
+	{expression: "--a", expected: 10},
+	{expression: "0.5 * a", expected: 5},
+	{expression: ".5 * a", expected: 5},
+	{expression: "abs(produced_rate)", expected: 3},
+	{expression: "max(a, b)", expected: 10},
+	{expression: "max(a - b, 0)", expected: 6},
+	{expression: "min(a, b, produced_rate)", expected: -3},
+	{expression: "max(a)", expected: 10},
+	{expression: "max(abs(produced_rate), min(a, b)) * 2", expected: 8},
+	// invalid expressions
+	{expression: "", isError: true},
+	{expression: "a -", isError: true},
+	{expression: "a b", isError: true},
+	{expression: "(a - b", isError: true},
+	{expression: "a - b)", isError: true},
+	{expression: "c - b", isError: true},
+	{expression: "sqrt(a)", isError: true},
+	{expression: "abs(a, b)", isError: true},
+	{expression: "max()", isError: true},
+	{expression: "max(a b)", isError: true},
+	{expression: "max(a,", isError: true},
+	{expression: "1.2.3", isError: true},
+	{expression: "a % b", isError: true},
+}
+
+func TestPromExpression(t *testing.T) {
+	for _, testData := range testPromExpressions {
+		expression, err := parsePromExpression(testData.expression, promExpressionNames)
+		if testData.isError {
+			if err == nil {
+				t.Errorf("%q: expected parse error but got success", testData.expression)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: expected success but got error %s", testData.expression, err)
+			continue
+		}
+
+		value, err := expression.evaluate(promExpressionValues)
+		if err != nil {
+			t.Errorf("%q: expected success but got error %s", testData.expression, err)
+		} else if value != testData.expected {
+			t.Errorf("%q: expected %v but got %v", testData.expression, testData.expected, value)
+		}
+	}
+}
+
+func TestPromExpressionDivisionByZero(t *testing.T) {
+	for _, input := range []string{"a / (b - 4)", "a / 0", "max(a / (b - b), 1)"} {
+		expression, err := parsePromExpression(input, promExpressionNames)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %s", input, err)
+		}
+		if _, err := expression.evaluate(promExpressionValues); !errors.Is(err, errPromExpressionDivisionByZero) {
+			t.Errorf("%q: expected division by zero but got %v", input, err)
+		}
+	}
+}
+
+func TestPromExpressionMissingResult(t *testing.T) {
+	expression, err := parsePromExpression("a - b", promExpressionNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expression.evaluate(map[string]float64{"a": 1}); err == nil {
+		t.Error("expected an error for the missing result of b")
+	}
+}
+
+type promQueriesTestData struct {
+	queries  string
+	expected []promNamedQuery
+	isError  bool
+}
+
+var testPromQueries = []promQueriesTestData{
+	{queries: "a=sum(rate(produced[1m]))", expected: []promNamedQuery{{name: "a", query: "sum(rate(produced[1m]))"}}},
+	{
+		queries: "\n produced = sum(rate(messages_total{direction=\"in\"}[1m]))\n\nconsumed=sum by (queue, vhost) (rate(acks[1m]))\n",
+		expected: []promNamedQuery{
+			{name: "produced", query: "sum(rate(messages_total{direction=\"in\"}[1m]))"},
+			{name: "consumed", query: "sum by (queue, vhost) (rate(acks[1m]))"},
+		},
+	},
+	{queries: "", isError: true},
+	{queries: "sum(rate(produced[1m]))", isError: true},
+	{queries: "a=", isError: true},
+	{queries: "=up", isError: true},
+	{queries: "1a=up", isError: true},
+	{queries: "a-b=up", isError: true},
+	{queries: "a=up\na=down", isError: true},
+}
+
+func TestParsePromQueries(t *testing.T) {
+	for _, testData := range testPromQueries {
+		queries, err := parsePromQueries(testData.queries)
+		if testData.isError {
+			if err == nil {
+				t.Errorf("%q: expected error but got success", testData.queries)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: expected success but got error %s", testData.queries, err)
+			continue
+		}
+		if len(queries) != len(testData.expected) {
+			t.Errorf("%q: expected %v but got %v", testData.queries, testData.expected, queries)
+			continue
+		}
+		for i := range queries {
+			if queries[i] != testData.expected[i] {
+				t.Errorf("%q: expected %v but got %v", testData.queries, testData.expected[i], queries[i])
+			}
+		}
+	}
+}
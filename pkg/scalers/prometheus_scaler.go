@@ -3,6 +3,7 @@ package scalers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -15,6 +16,7 @@ import (
 	v2 "k8s.io/api/autoscaling/v2"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
 	"github.com/kedacore/keda/v2/pkg/scalers/azure"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
@@ -27,6 +29,8 @@ const (
 	promMetricName = "metricName"
 
 	promQuery               = "query"
+	promQueries             = "queries"
+	promExpressionKey       = "expression"
 	promThreshold           = "threshold"
 	promActivationThreshold = "activationThreshold"
 	promNamespace           = "namespace"
@@ -45,6 +49,9 @@ type prometheusScaler struct {
 	metadata   *prometheusMetadata
 	httpClient *http.Client
 	logger     logr.Logger
+
+	scaledObjectNamespace string
+	scaledObjectName      string
 }
 
 type prometheusMetadata struct {
@@ -63,6 +70,14 @@ type prometheusMetadata struct {
 	// https://github.com/kedacore/keda/issues/3065
 	ignoreNullValues bool
 	unsafeSsl        bool
+	// queries and expression replace query, the metric value is the expression evaluated over the query results
+	queries    []promNamedQuery
+	expression promExpression
+}
+
+type promNamedQuery struct {
+	name  string
+	query string
 }
 
 type promQueryResult struct {
@@ -91,10 +106,10 @@ func NewPrometheusScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing prometheus metadata: %w", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, meta.unsafeSsl)
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, meta.unsafeSsl, config.HTTPProxy)
 
 	if meta.prometheusAuth != nil {
-		if meta.prometheusAuth.CA != "" || meta.prometheusAuth.EnableTLS {
+		if meta.prometheusAuth.CA != "" || meta.prometheusAuth.EnableTLS || meta.prometheusAuth.ServerPublicKeyPin != "" {
 			// create http.RoundTripper with auth settings from ScalerConfig
 			transport, err := authentication.CreateHTTPRoundTripper(
 				authentication.NetHTTP,
@@ -127,6 +142,9 @@ func NewPrometheusScaler(config *ScalerConfig) (Scaler, error) {
 		metadata:   meta,
 		httpClient: httpClient,
 		logger:     logger,
+
+		scaledObjectNamespace: config.ScalableObjectNamespace,
+		scaledObjectName:      config.ScalableObjectName,
 	}, nil
 }
 
@@ -139,10 +157,8 @@ func parsePrometheusMetadata(config *ScalerConfig) (meta *prometheusMetadata, er
 		return nil, fmt.Errorf("no %s given", promServerAddress)
 	}
 
-	if val, ok := config.TriggerMetadata[promQuery]; ok && val != "" {
-		meta.query = val
-	} else {
-		return nil, fmt.Errorf("no %s given", promQuery)
+	if err := parsePrometheusQueries(config, meta); err != nil {
+		return nil, err
 	}
 
 	// FIXME: DEPRECATED to be removed in v2.12
@@ -220,6 +236,45 @@ func parsePrometheusMetadata(config *ScalerConfig) (meta *prometheusMetadata, er
 	return meta, nil
 }
 
+// parsePrometheusQueries parses either a single query or the named queries and the expression over their results
+func parsePrometheusQueries(config *ScalerConfig, meta *prometheusMetadata) error {
+	query := config.TriggerMetadata[promQuery]
+	queries := config.TriggerMetadata[promQueries]
+	expression := config.TriggerMetadata[promExpressionKey]
+
+	switch {
+	case query != "" && queries != "":
+		return fmt.Errorf("%s and %s can't be given together", promQuery, promQueries)
+	case query != "":
+		if expression != "" {
+			return fmt.Errorf("%s is only supported with %s", promExpressionKey, promQueries)
+		}
+		meta.query = query
+		return nil
+	case queries == "":
+		return fmt.Errorf("no %s given", promQuery)
+	case expression == "":
+		return fmt.Errorf("no %s given for %s", promExpressionKey, promQueries)
+	}
+
+	namedQueries, err := parsePromQueries(queries)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", promQueries, err)
+	}
+	names := make(map[string]bool, len(namedQueries))
+	for _, namedQuery := range namedQueries {
+		names[namedQuery.name] = true
+	}
+	parsedExpression, err := parsePromExpression(expression, names)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", promExpressionKey, err)
+	}
+
+	meta.queries = namedQueries
+	meta.expression = parsedExpression
+	return nil
+}
+
 func parseAuthConfig(config *ScalerConfig, meta *prometheusMetadata) error {
 	// parse auth configs from ScalerConfig
 	auth, err := authentication.GetAuthConfigs(config.TriggerMetadata, config.AuthParams)
@@ -253,9 +308,34 @@ func (s *prometheusScaler) GetMetricSpecForScaling(context.Context) []v2.MetricS
 	return []v2.MetricSpec{metricSpec}
 }
 
+// ExecutePromQuery returns the result of the query, or the result of the expression over the results of the queries
 func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error) {
+	if len(s.metadata.queries) == 0 {
+		return s.executeQuery(ctx, s.metadata.query)
+	}
+
+	results := make(map[string]float64, len(s.metadata.queries))
+	for _, namedQuery := range s.metadata.queries {
+		value, err := s.executeQuery(ctx, namedQuery.query)
+		if err != nil {
+			return -1, fmt.Errorf("error executing prometheus query %s: %w", namedQuery.name, err)
+		}
+		results[namedQuery.name] = value
+	}
+
+	v, err := s.metadata.expression.evaluate(results)
+	if errors.Is(err, errPromExpressionDivisionByZero) && s.metadata.ignoreNullValues {
+		return 0, nil
+	}
+	if err != nil {
+		return -1, fmt.Errorf("error evaluating prometheus expression: %w", err)
+	}
+	return v, nil
+}
+
+func (s *prometheusScaler) executeQuery(ctx context.Context, query string) (float64, error) {
 	t := time.Now().UTC().Format(time.RFC3339)
-	queryEscaped := url_pkg.QueryEscape(s.metadata.query)
+	queryEscaped := url_pkg.QueryEscape(query)
 	url := fmt.Sprintf("%s/api/v1/query?query=%s&time=%s", s.metadata.serverAddress, queryEscaped, t)
 
 	// set 'namespace' parameter for namespaced Prometheus requests (eg. for Thanos Querier)
@@ -285,7 +365,7 @@ func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error
 
 	r, err := s.httpClient.Do(req)
 	if err != nil {
-		return -1, err
+		return -1, wrapHTTPRequestError(err)
 	}
 
 	b, err := io.ReadAll(r.Body)
@@ -297,7 +377,7 @@ func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error
 	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
 		err := fmt.Errorf("prometheus query api returned error. status: %d response: %s", r.StatusCode, string(b))
 		s.logger.Error(err, "prometheus query api returned error")
-		return -1, err
+		return -1, wrapHTTPStatusError(r.StatusCode, err)
 	}
 
 	var result promQueryResult
@@ -306,6 +386,8 @@ func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error
 		return -1, err
 	}
 
+	prommetrics.RecordPrometheusScalerResultSeries(s.scaledObjectNamespace, s.scaledObjectName, len(result.Data.Result))
+
 	var v float64 = -1
 
 	// allow for zero element or single element result sets
@@ -315,7 +397,7 @@ func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error
 		}
 		return -1, fmt.Errorf("prometheus metrics %s target may be lost, the result is empty", s.metadata.metricName)
 	} else if len(result.Data.Result) > 1 {
-		return -1, fmt.Errorf("prometheus query %s returned multiple elements", s.metadata.query)
+		return -1, fmt.Errorf("prometheus query %s returned multiple elements", query)
 	}
 
 	valueLen := len(result.Data.Result[0].Value)
@@ -325,7 +407,7 @@ func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error
 		}
 		return -1, fmt.Errorf("prometheus metrics %s target may be lost, the value list is empty", s.metadata.metricName)
 	} else if valueLen < 2 {
-		return -1, fmt.Errorf("prometheus query %s didn't return enough values", s.metadata.query)
+		return -1, fmt.Errorf("prometheus query %s didn't return enough values", query)
 	}
 
 	val := result.Data.Result[0].Value[1]
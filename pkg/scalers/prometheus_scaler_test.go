@@ -4,11 +4,13 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 )
@@ -54,6 +56,18 @@ var testPromMetadata = []parsePrometheusMetadataTestData{
 	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "customHeaders": "key1=value1,key2"}, true},
 	// deprecated cortexOrgID
 	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up", "cortexOrgID": "my-org"}, true},
+	// queries with expression
+	{map[string]string{"serverAddress": "http://localhost:9090", "threshold": "100", "queries": "a=sum(rate(produced[1m]))\nb=sum(rate(consumed[1m]))", "expression": "max(a - b, 0)"}, false},
+	// queries without expression
+	{map[string]string{"serverAddress": "http://localhost:9090", "threshold": "100", "queries": "a=sum(rate(produced[1m]))"}, true},
+	// expression without queries
+	{map[string]string{"serverAddress": "http://localhost:9090", "threshold": "100", "query": "up", "expression": "a - b"}, true},
+	// query and queries together
+	{map[string]string{"serverAddress": "http://localhost:9090", "threshold": "100", "query": "up", "queries": "a=up", "expression": "a"}, true},
+	// malformed queries
+	{map[string]string{"serverAddress": "http://localhost:9090", "threshold": "100", "queries": "sum(rate(produced[1m]))", "expression": "a"}, true},
+	// expression referencing an unknown query
+	{map[string]string{"serverAddress": "http://localhost:9090", "threshold": "100", "queries": "a=up", "expression": "a - b"}, true},
 }
 
 var prometheusMetricIdentifiers = []prometheusMetricIdentifier{
@@ -324,6 +338,82 @@ func TestPrometheusScalerExecutePromQuery(t *testing.T) {
 	}
 }
 
+func TestPrometheusScalerResultSeriesMetrics(t *testing.T) {
+	testData := []struct {
+		name          string
+		scaledObject  string
+		bodyStr       string
+		series        float64
+		multiResults  float64
+		isError       bool
+		expectedValue float64
+	}{
+		{"single series", "single-series-so", `{"data":{"result":[{"value": ["1", "2"]}]}}`, 1, 0, false, 2},
+		{"multiple series", "multi-series-so", `{"data":{"result":[{"value": ["1", "2"]},{"value": ["1", "3"]}]}}`, 2, 1, true, -1},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+				if _, err := writer.Write([]byte(data.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			scaler := prometheusScaler{
+				metadata: &prometheusMetadata{
+					serverAddress: server.URL,
+				},
+				httpClient:            http.DefaultClient,
+				logger:                logr.Discard(),
+				scaledObjectNamespace: "test-namespace",
+				scaledObjectName:      data.scaledObject,
+			}
+
+			multiResultsBefore := gatheredMetricValue(t, "keda_scaler_prometheus_multiresult_total", "scaledObject", data.scaledObject)
+
+			value, err := scaler.ExecutePromQuery(context.TODO())
+			assert.Equal(t, data.expectedValue, value)
+			if data.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, data.series, gatheredMetricValue(t, "keda_scaler_prometheus_result_series", "scaledObject", data.scaledObject))
+			assert.Equal(t, multiResultsBefore+data.multiResults, gatheredMetricValue(t, "keda_scaler_prometheus_multiresult_total", "scaledObject", data.scaledObject))
+		})
+	}
+}
+
+// gatheredMetricValue returns the value of the gauge or counter series with the label value from the metrics
+// registry, 0 when there is none
+func gatheredMetricValue(t *testing.T, name string, labelName string, labelValue string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == labelName && label.GetValue() == labelValue {
+					if metric.GetCounter() != nil {
+						return metric.GetCounter().GetValue()
+					}
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
 func TestPrometheusScalerCustomHeaders(t *testing.T) {
 	testData := prometheusQromQueryResultTestData{
 		name:             "no values",
@@ -363,3 +453,104 @@ func TestPrometheusScalerCustomHeaders(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+type prometheusMultiQueryTestData struct {
+	name             string
+	expression       string
+	responses        map[string]string
+	ignoreNullValues bool
+	expectedValue    float64
+	isError          bool
+	errorContains    string
+}
+
+var testPromMultiQueryResult = []prometheusMultiQueryTestData{
+	{
+		name:          "difference",
+		expression:    "produced - consumed",
+		responses:     map[string]string{"produced": `{"data":{"result":[{"value": ["1", "12"]}]}}`, "consumed": `{"data":{"result":[{"value": ["1", "5"]}]}}`},
+		expectedValue: 7,
+	},
+	{
+		name:          "negative difference clamped",
+		expression:    "max(produced - consumed, 0)",
+		responses:     map[string]string{"produced": `{"data":{"result":[{"value": ["1", "5"]}]}}`, "consumed": `{"data":{"result":[{"value": ["1", "12"]}]}}`},
+		expectedValue: 0,
+	},
+	{
+		name:             "missing result ignored",
+		expression:       "produced - consumed",
+		responses:        map[string]string{"produced": `{"data":{"result":[{"value": ["1", "12"]}]}}`, "consumed": `{"data":{"result":[]}}`},
+		ignoreNullValues: true,
+		expectedValue:    12,
+	},
+	{
+		name:          "missing result not ignored",
+		expression:    "produced - consumed",
+		responses:     map[string]string{"produced": `{"data":{"result":[{"value": ["1", "12"]}]}}`, "consumed": `{"data":{"result":[]}}`},
+		expectedValue: -1,
+		isError:       true,
+		errorContains: "query consumed",
+	},
+	{
+		name:          "failing query",
+		expression:    "produced - consumed",
+		responses:     map[string]string{"produced": `{"data":{"result":[{},{}]}}`, "consumed": `{"data":{"result":[{"value": ["1", "5"]}]}}`},
+		expectedValue: -1,
+		isError:       true,
+		errorContains: "query produced",
+	},
+	{
+		name:             "division by zero ignored",
+		expression:       "produced / consumed",
+		responses:        map[string]string{"produced": `{"data":{"result":[{"value": ["1", "12"]}]}}`, "consumed": `{"data":{"result":[{"value": ["1", "0"]}]}}`},
+		ignoreNullValues: true,
+		expectedValue:    0,
+	},
+	{
+		name:          "division by zero not ignored",
+		expression:    "produced / consumed",
+		responses:     map[string]string{"produced": `{"data":{"result":[{"value": ["1", "12"]}]}}`, "consumed": `{"data":{"result":[{"value": ["1", "0"]}]}}`},
+		expectedValue: -1,
+		isError:       true,
+		errorContains: "division by zero",
+	},
+}
+
+func TestPrometheusScalerExecutePromMultiQuery(t *testing.T) {
+	for _, testData := range testPromMultiQueryResult {
+		t.Run(testData.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+				if _, err := writer.Write([]byte(testData.responses[request.URL.Query().Get("query")])); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			meta, err := parsePrometheusMetadata(&ScalerConfig{TriggerMetadata: map[string]string{
+				"serverAddress":    server.URL,
+				"threshold":        "10",
+				"queries":          "produced=produced\nconsumed=consumed",
+				"expression":       testData.expression,
+				"ignoreNullValues": strconv.FormatBool(testData.ignoreNullValues),
+			}})
+			assert.NoError(t, err)
+
+			scaler := prometheusScaler{
+				metadata:   meta,
+				httpClient: http.DefaultClient,
+				logger:     logr.Discard(),
+			}
+
+			value, err := scaler.ExecutePromQuery(context.TODO())
+
+			assert.Equal(t, testData.expectedValue, value)
+			if testData.isError {
+				assert.ErrorContains(t, err, testData.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package scalers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	proxyStatsFormatEnvoyPrometheus = "envoy-prometheus"
+	proxyStatsFormatNginxStub       = "nginx-stub"
+
+	nginxStubActiveConnections = "active"
+	nginxStubReading           = "reading"
+	nginxStubWriting           = "writing"
+	nginxStubWaiting           = "waiting"
+)
+
+var (
+	proxyStatsLabelMatcherRegex = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*=\s*"((?:[^"\\]|\\.)*)"\s*(,|$)`)
+	proxyStatsFamilyNameRegex   = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+)
+
+type proxyStatsScaler struct {
+	metricType v2.MetricTargetType
+	metadata   *proxyStatsMetadata
+	httpClient *http.Client
+	logger     logr.Logger
+}
+
+type proxyStatsMetadata struct {
+	statsURL        string
+	format          string
+	statName        string
+	targetValue     float64
+	activationValue float64
+	unsafeSsl       bool
+	auth            *authentication.AuthMeta
+	scalerIndex     int
+
+	// parsed from statName for the envoy-prometheus format
+	family        string
+	labelMatchers map[string]string
+}
+
+// NewProxyStatsScaler creates a new scaler reading the active connections
+// from the stats endpoint of Envoy or nginx
+func NewProxyStatsScaler(config *ScalerConfig) (Scaler, error) {
+	metricType, err := GetMetricTargetType(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scaler metric type: %w", err)
+	}
+
+	meta, err := parseProxyStatsMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing proxy stats metadata: %w", err)
+	}
+
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, meta.unsafeSsl, config.HTTPProxy)
+	if meta.auth != nil && (meta.auth.CA != "" || meta.auth.EnableTLS || meta.auth.ServerPublicKeyPin != "") {
+		tlsConfig, err := authentication.NewTLSConfig(meta.auth, meta.unsafeSsl)
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = kedautil.CreateHTTPTransportWithProxy(tlsConfig, config.HTTPProxy)
+	}
+
+	return &proxyStatsScaler{
+		metricType: metricType,
+		metadata:   meta,
+		httpClient: httpClient,
+		logger:     InitializeLogger(config, "proxy_stats_scaler"),
+	}, nil
+}
+
+func parseProxyStatsMetadata(config *ScalerConfig) (*proxyStatsMetadata, error) {
+	meta := proxyStatsMetadata{}
+
+	if val, ok := config.TriggerMetadata["statsURL"]; ok && val != "" {
+		meta.statsURL = val
+	} else {
+		return nil, fmt.Errorf("no statsURL given")
+	}
+
+	meta.statName = strings.TrimSpace(config.TriggerMetadata["statName"])
+	switch format := config.TriggerMetadata["format"]; format {
+	case proxyStatsFormatEnvoyPrometheus:
+		if meta.statName == "" {
+			return nil, fmt.Errorf("no statName given, it's required for the %s format", proxyStatsFormatEnvoyPrometheus)
+		}
+		family, labelMatchers, err := parseProxyStatsStatName(meta.statName)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing statName: %w", err)
+		}
+		meta.family = family
+		meta.labelMatchers = labelMatchers
+	case proxyStatsFormatNginxStub:
+		switch meta.statName {
+		case "":
+			meta.statName = nginxStubActiveConnections
+		case nginxStubActiveConnections, nginxStubReading, nginxStubWriting, nginxStubWaiting:
+		default:
+			return nil, fmt.Errorf("statName must be one of %s, %s, %s or %s for the %s format, got %s",
+				nginxStubActiveConnections, nginxStubReading, nginxStubWriting, nginxStubWaiting, proxyStatsFormatNginxStub, meta.statName)
+		}
+	case "":
+		return nil, fmt.Errorf("no format given")
+	default:
+		return nil, fmt.Errorf("format must be either %s or %s, got %s", proxyStatsFormatEnvoyPrometheus, proxyStatsFormatNginxStub, format)
+	}
+	meta.format = config.TriggerMetadata["format"]
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error: %w", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	meta.activationValue = 0
+	if val, ok := config.TriggerMetadata["activationValue"]; ok && val != "" {
+		activationValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationValue parsing error: %w", err)
+		}
+		meta.activationValue = activationValue
+	}
+
+	meta.unsafeSsl = false
+	if val, ok := config.TriggerMetadata[unsafeSsl]; ok && val != "" {
+		unsafeSslValue, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", unsafeSsl, err)
+		}
+		meta.unsafeSsl = unsafeSslValue
+	}
+
+	auth, err := authentication.GetAuthConfigs(config.TriggerMetadata, config.AuthParams)
+	if err != nil {
+		return nil, err
+	}
+	meta.auth = auth
+
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// parseProxyStatsStatName parses a statName like
+// envoy_cluster_upstream_cx_active{envoy_cluster_name="backend"} into the family name and the label matchers
+func parseProxyStatsStatName(statName string) (string, map[string]string, error) {
+	family, matchers, hasMatchers := strings.Cut(statName, "{")
+	family = strings.TrimSpace(family)
+	if !proxyStatsFamilyNameRegex.MatchString(family) {
+		return "", nil, fmt.Errorf("invalid metric family name %q", family)
+	}
+
+	labelMatchers := map[string]string{}
+	if !hasMatchers {
+		return family, labelMatchers, nil
+	}
+
+	matchers = strings.TrimSpace(matchers)
+	if !strings.HasSuffix(matchers, "}") {
+		return "", nil, fmt.Errorf("label matchers of %q must end with '}'", statName)
+	}
+	matchers = strings.TrimSuffix(matchers, "}")
+
+	for strings.TrimSpace(matchers) != "" {
+		match := proxyStatsLabelMatcherRegex.FindStringSubmatch(matchers)
+		if match == nil {
+			return "", nil, fmt.Errorf("invalid label matchers %q, expected label=\"value\" pairs separated by commas", matchers)
+		}
+		value, err := strconv.Unquote(`"` + match[2] + `"`)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid value of label %s: %w", match[1], err)
+		}
+		if _, ok := labelMatchers[match[1]]; ok {
+			return "", nil, fmt.Errorf("label %s is matched more than once", match[1])
+		}
+		labelMatchers[match[1]] = value
+		matchers = matchers[len(match[0]):]
+	}
+	return family, labelMatchers, nil
+}
+
+// parseEnvoyPrometheusStats sums the values of the series of the family which match all the label matchers
+func parseEnvoyPrometheusStats(body []byte, family string, labelMatchers map[string]string) (float64, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing prometheus stats: %w", err)
+	}
+
+	metricFamily, ok := families[family]
+	if !ok {
+		return 0, fmt.Errorf("metric family %s not found in the stats", family)
+	}
+
+	var value float64
+	found := false
+	for _, metric := range metricFamily.GetMetric() {
+		if !proxyStatsLabelsMatch(metric.GetLabel(), labelMatchers) {
+			continue
+		}
+		switch metricFamily.GetType() {
+		case dto.MetricType_GAUGE:
+			value += metric.GetGauge().GetValue()
+		case dto.MetricType_COUNTER:
+			value += metric.GetCounter().GetValue()
+		case dto.MetricType_UNTYPED:
+			value += metric.GetUntyped().GetValue()
+		default:
+			return 0, fmt.Errorf("metric family %s has unsupported type %s", family, metricFamily.GetType())
+		}
+		found = true
+	}
+	if !found {
+		return 0, fmt.Errorf("no series of metric family %s matches the labels %v", family, labelMatchers)
+	}
+	return value, nil
+}
+
+func proxyStatsLabelsMatch(labels []*dto.LabelPair, labelMatchers map[string]string) bool {
+	matched := 0
+	for _, label := range labels {
+		if value, ok := labelMatchers[label.GetName()]; ok {
+			if value != label.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labelMatchers)
+}
+
+// parseNginxStubStatus returns the given stat of a nginx stub_status response, which looks like:
+//
+//	Active connections: 291
+//	server accepts handled requests
+//	 16630948 16630948 31070465
+//	Reading: 6 Writing: 179 Waiting: 106
+func parseNginxStubStatus(body []byte, statName string) (float64, error) {
+	stats := map[string]float64{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "Active connections:"):
+			value, err := parseNginxStubValue(strings.TrimPrefix(line, "Active connections:"))
+			if err != nil {
+				return 0, fmt.Errorf("invalid active connections: %w", err)
+			}
+			stats[nginxStubActiveConnections] = value
+		case strings.HasPrefix(line, "Reading:"):
+			fields := strings.Fields(line)
+			if len(fields) != 6 || fields[0] != "Reading:" || fields[2] != "Writing:" || fields[4] != "Waiting:" {
+				return 0, fmt.Errorf("invalid stub_status line %q", line)
+			}
+			for i, name := range []string{nginxStubReading, nginxStubWriting, nginxStubWaiting} {
+				value, err := parseNginxStubValue(fields[2*i+1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid %s connections: %w", name, err)
+				}
+				stats[name] = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	value, ok := stats[statName]
+	if !ok {
+		return 0, fmt.Errorf("%s connections not found in the stub_status response", statName)
+	}
+	return value, nil
+}
+
+func parseNginxStubValue(value string) (float64, error) {
+	parsed, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return float64(parsed), nil
+}
+
+func (s *proxyStatsScaler) getStatValue(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.metadata.statsURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case s.metadata.auth == nil:
+	case s.metadata.auth.EnableBearerAuth:
+		req.Header.Set("Authorization", authentication.GetBearerToken(s.metadata.auth))
+	case s.metadata.auth.EnableBasicAuth:
+		req.SetBasicAuth(s.metadata.auth.Username, s.metadata.auth.Password)
+	case s.metadata.auth.EnableCustomAuth:
+		req.Header.Set(s.metadata.auth.CustomAuthHeader, s.metadata.auth.CustomAuthValue)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, wrapHTTPRequestError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, wrapHTTPStatusError(resp.StatusCode, fmt.Errorf("stats endpoint returned %d", resp.StatusCode))
+	}
+
+	if s.metadata.format == proxyStatsFormatNginxStub {
+		return parseNginxStubStatus(body, s.metadata.statName)
+	}
+	return parseEnvoyPrometheusStats(body, s.metadata.family, s.metadata.labelMatchers)
+}
+
+// Close does nothing in case of proxyStatsScaler
+func (s *proxyStatsScaler) Close(context.Context) error {
+	return nil
+}
+
+// GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
+func (s *proxyStatsScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	externalMetric := &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("proxy-stats-%s", s.metadata.format))),
+		},
+		Target: GetMetricTargetMili(s.metricType, s.metadata.targetValue),
+	}
+	metricSpec := v2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2.MetricSpec{metricSpec}
+}
+
+// GetMetricsAndActivity returns the value of the stat and whether it's above the activation value
+func (s *proxyStatsScaler) GetMetricsAndActivity(ctx context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	val, err := s.getStatValue(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, false, fmt.Errorf("error requesting proxy stats: %w", err)
+	}
+
+	metric := GenerateMetricInMili(metricName, val)
+
+	return []external_metrics.ExternalMetricValue{metric}, val > s.metadata.activationValue, nil
+}
@@ -0,0 +1,272 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseProxyStatsMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type proxyStatsMetricIdentifier struct {
+	metadataTestData *parseProxyStatsMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testProxyStatsMetadata = []parseProxyStatsMetadataTestData{
+	// envoy-prometheus
+	{map[string]string{"statsURL": "http://envoy:9901/stats/prometheus", "format": "envoy-prometheus", "statName": `envoy_cluster_upstream_cx_active{envoy_cluster_name="backend"}`, "targetValue": "100"}, map[string]string{}, false},
+	// nginx-stub with default statName
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "nginx-stub", "targetValue": "100", "activationValue": "10"}, map[string]string{}, false},
+	// nginx-stub with statName
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "nginx-stub", "statName": "writing", "targetValue": "100"}, map[string]string{}, false},
+	// no metadata
+	{map[string]string{}, map[string]string{}, true},
+	// missing statsURL
+	{map[string]string{"format": "nginx-stub", "targetValue": "100"}, map[string]string{}, true},
+	// missing format
+	{map[string]string{"statsURL": "http://nginx/stub_status", "targetValue": "100"}, map[string]string{}, true},
+	// unknown format
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "haproxy", "targetValue": "100"}, map[string]string{}, true},
+	// envoy-prometheus without statName
+	{map[string]string{"statsURL": "http://envoy:9901/stats/prometheus", "format": "envoy-prometheus", "targetValue": "100"}, map[string]string{}, true},
+	// envoy-prometheus with malformed statName
+	{map[string]string{"statsURL": "http://envoy:9901/stats/prometheus", "format": "envoy-prometheus", "statName": `envoy_cluster_upstream_cx_active{envoy_cluster_name=backend}`, "targetValue": "100"}, map[string]string{}, true},
+	// nginx-stub with unknown statName
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "nginx-stub", "statName": "accepts", "targetValue": "100"}, map[string]string{}, true},
+	// missing targetValue
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "nginx-stub"}, map[string]string{}, true},
+	// invalid targetValue
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "nginx-stub", "targetValue": "a"}, map[string]string{}, true},
+	// invalid activationValue
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "nginx-stub", "targetValue": "100", "activationValue": "a"}, map[string]string{}, true},
+	// invalid unsafeSsl
+	{map[string]string{"statsURL": "https://nginx/stub_status", "format": "nginx-stub", "targetValue": "100", "unsafeSsl": "yes"}, map[string]string{}, true},
+	// basic auth
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "nginx-stub", "targetValue": "100", "authModes": "basic"}, map[string]string{"username": "user", "password": "pass"}, false},
+	// basic auth without username
+	{map[string]string{"statsURL": "http://nginx/stub_status", "format": "nginx-stub", "targetValue": "100", "authModes": "basic"}, map[string]string{}, true},
+	// tls auth without key
+	{map[string]string{"statsURL": "https://nginx/stub_status", "format": "nginx-stub", "targetValue": "100", "authModes": "tls"}, map[string]string{"cert": "cert"}, true},
+}
+
+var proxyStatsMetricIdentifiers = []proxyStatsMetricIdentifier{
+	{&testProxyStatsMetadata[0], 0, "s0-proxy-stats-envoy-prometheus"},
+	{&testProxyStatsMetadata[1], 1, "s1-proxy-stats-nginx-stub"},
+}
+
+func TestParseProxyStatsMetadata(t *testing.T) {
+	for i, testData := range testProxyStatsMetadata {
+		_, err := parseProxyStatsMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("test case %d: expected success but got error %v", i, err)
+		}
+		if err == nil && testData.isError {
+			t.Errorf("test case %d: expected error but got success", i)
+		}
+	}
+}
+
+func TestProxyStatsGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range proxyStatsMetricIdentifiers {
+		meta, err := parseProxyStatsMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockProxyStatsScaler := proxyStatsScaler{metadata: meta}
+
+		metricSpec := mockProxyStatsScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestParseProxyStatsStatName(t *testing.T) {
+	tests := []struct {
+		statName      string
+		family        string
+		labelMatchers map[string]string
+		isError       bool
+	}{
+		{statName: "envoy_server_total_connections", family: "envoy_server_total_connections", labelMatchers: map[string]string{}},
+		{statName: "envoy_cluster_upstream_cx_active{}", family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{}},
+		{statName: `envoy_cluster_upstream_cx_active{envoy_cluster_name="backend"}`, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{"envoy_cluster_name": "backend"}},
+		{statName: `envoy_cluster_upstream_cx_active { envoy_cluster_name = "backend" , zone="a,b" }`, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{"envoy_cluster_name": "backend", "zone": "a,b"}},
+		{statName: `envoy_http_downstream_cx_active{envoy_http_conn_manager_prefix="in\"gress"}`, family: "envoy_http_downstream_cx_active", labelMatchers: map[string]string{"envoy_http_conn_manager_prefix": `in"gress`}},
+		{statName: "", isError: true},
+		{statName: "1envoy", isError: true},
+		{statName: "envoy-cluster", isError: true},
+		{statName: `envoy_cluster_upstream_cx_active{envoy_cluster_name="backend"`, isError: true},
+		{statName: `envoy_cluster_upstream_cx_active{envoy_cluster_name=backend}`, isError: true},
+		{statName: `envoy_cluster_upstream_cx_active{envoy_cluster_name="backend" zone="a"}`, isError: true},
+		{statName: `envoy_cluster_upstream_cx_active{envoy_cluster_name="a",envoy_cluster_name="b"}`, isError: true},
+		{statName: `envoy_cluster_upstream_cx_active{envoy_cluster_name="bad\escape"}`, isError: true},
+	}
+
+	for _, test := range tests {
+		family, labelMatchers, err := parseProxyStatsStatName(test.statName)
+		if test.isError {
+			assert.Error(t, err, test.statName)
+			continue
+		}
+		assert.NoError(t, err, test.statName)
+		assert.Equal(t, test.family, family, test.statName)
+		assert.Equal(t, test.labelMatchers, labelMatchers, test.statName)
+	}
+}
+
+const envoyPrometheusStats = `# TYPE envoy_cluster_upstream_cx_active gauge
+envoy_cluster_upstream_cx_active{envoy_cluster_name="backend",zone="a"} 12
+envoy_cluster_upstream_cx_active{envoy_cluster_name="backend",zone="b"} 30
+envoy_cluster_upstream_cx_active{envoy_cluster_name="admin",zone="a"} 5
+# TYPE envoy_cluster_upstream_cx_total counter
+envoy_cluster_upstream_cx_total{envoy_cluster_name="backend"} 1042
+# TYPE envoy_server_uptime untyped
+envoy_server_uptime 3600
+# TYPE envoy_cluster_upstream_cx_length_ms histogram
+envoy_cluster_upstream_cx_length_ms_bucket{envoy_cluster_name="backend",le="10"} 3
+envoy_cluster_upstream_cx_length_ms_bucket{envoy_cluster_name="backend",le="+Inf"} 4
+envoy_cluster_upstream_cx_length_ms_sum{envoy_cluster_name="backend"} 52
+envoy_cluster_upstream_cx_length_ms_count{envoy_cluster_name="backend"} 4
+`
+
+func TestParseEnvoyPrometheusStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		family        string
+		labelMatchers map[string]string
+		value         float64
+		isError       bool
+	}{
+		{name: "single series", body: envoyPrometheusStats, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{"envoy_cluster_name": "admin"}, value: 5},
+		{name: "sum of matching series", body: envoyPrometheusStats, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{"envoy_cluster_name": "backend"}, value: 42},
+		{name: "all series without matchers", body: envoyPrometheusStats, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{}, value: 47},
+		{name: "several matchers", body: envoyPrometheusStats, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{"envoy_cluster_name": "backend", "zone": "b"}, value: 30},
+		{name: "counter", body: envoyPrometheusStats, family: "envoy_cluster_upstream_cx_total", labelMatchers: map[string]string{}, value: 1042},
+		{name: "untyped", body: envoyPrometheusStats, family: "envoy_server_uptime", labelMatchers: map[string]string{}, value: 3600},
+		{name: "no type line", body: "envoy_server_live 1\n", family: "envoy_server_live", labelMatchers: map[string]string{}, value: 1},
+		{name: "histogram", body: envoyPrometheusStats, family: "envoy_cluster_upstream_cx_length_ms", labelMatchers: map[string]string{}, isError: true},
+		{name: "missing family", body: envoyPrometheusStats, family: "envoy_cluster_upstream_rq_active", labelMatchers: map[string]string{}, isError: true},
+		{name: "no matching series", body: envoyPrometheusStats, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{"envoy_cluster_name": "frontend"}, isError: true},
+		{name: "matcher on a missing label", body: envoyPrometheusStats, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{"region": "eu"}, isError: true},
+		{name: "empty body", body: "", family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{}, isError: true},
+		{name: "invalid value", body: "envoy_cluster_upstream_cx_active{envoy_cluster_name=\"backend\"} many\n", family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{}, isError: true},
+		{name: "unterminated labels", body: "envoy_cluster_upstream_cx_active{envoy_cluster_name=\"backend\" 12\n", family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{}, isError: true},
+		{name: "duplicate type line", body: "# TYPE envoy_server_live gauge\n# TYPE envoy_server_live gauge\nenvoy_server_live 1\n", family: "envoy_server_live", labelMatchers: map[string]string{}, isError: true},
+		{name: "json body", body: `{"stats":[{"name":"cluster.backend.upstream_cx_active","value":12}]}`, family: "envoy_cluster_upstream_cx_active", labelMatchers: map[string]string{}, isError: true},
+	}
+
+	for _, test := range tests {
+		value, err := parseEnvoyPrometheusStats([]byte(test.body), test.family, test.labelMatchers)
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.value, value, test.name)
+	}
+}
+
+const nginxStubStatus = `Active connections: 291
+server accepts handled requests
+ 16630948 16630948 31070465
+Reading: 6 Writing: 179 Waiting: 106
+`
+
+func TestParseNginxStubStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		statName string
+		value    float64
+		isError  bool
+	}{
+		{name: "active connections", body: nginxStubStatus, statName: "active", value: 291},
+		{name: "reading", body: nginxStubStatus, statName: "reading", value: 6},
+		{name: "writing", body: nginxStubStatus, statName: "writing", value: 179},
+		{name: "waiting", body: nginxStubStatus, statName: "waiting", value: 106},
+		{name: "crlf line endings", body: "Active connections: 3\r\nserver accepts handled requests\r\n 1 1 1\r\nReading: 0 Writing: 1 Waiting: 2\r\n", statName: "waiting", value: 2},
+		{name: "only active connections", body: "Active connections: 7\n", statName: "active", value: 7},
+		{name: "missing reading line", body: "Active connections: 7\n", statName: "writing", isError: true},
+		{name: "empty body", body: "", statName: "active", isError: true},
+		{name: "html body", body: "<html><body>404 Not Found</body></html>", statName: "active", isError: true},
+		{name: "invalid active connections", body: "Active connections: many\n", statName: "active", isError: true},
+		{name: "negative active connections", body: "Active connections: -1\n", statName: "active", isError: true},
+		{name: "missing active connections value", body: "Active connections:\n", statName: "active", isError: true},
+		{name: "truncated reading line", body: "Active connections: 3\nReading: 0 Writing: 1\n", statName: "reading", isError: true},
+		{name: "invalid writing value", body: "Active connections: 3\nReading: 0 Writing: x Waiting: 2\n", statName: "reading", isError: true},
+		{name: "unexpected field names", body: "Active connections: 3\nReading: 0 Sending: 1 Waiting: 2\n", statName: "reading", isError: true},
+	}
+
+	for _, test := range tests {
+		value, err := parseNginxStubStatus([]byte(test.body), test.statName)
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.value, value, test.name)
+	}
+}
+
+func TestProxyStatsGetMetricsAndActivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/stub_status":
+			_, _ = w.Write([]byte(nginxStubStatus))
+		case "/stats/prometheus":
+			_, _ = w.Write([]byte(envoyPrometheusStats))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		value    float64
+		isActive bool
+		isError  bool
+	}{
+		{name: "nginx", metadata: map[string]string{"statsURL": server.URL + "/stub_status", "format": "nginx-stub", "targetValue": "100", "activationValue": "300"}, value: 291, isActive: false},
+		{name: "envoy", metadata: map[string]string{"statsURL": server.URL + "/stats/prometheus", "format": "envoy-prometheus", "statName": `envoy_cluster_upstream_cx_active{envoy_cluster_name="backend"}`, "targetValue": "10"}, value: 42, isActive: true},
+		{name: "not found", metadata: map[string]string{"statsURL": server.URL + "/stats", "format": "nginx-stub", "targetValue": "10"}, isError: true},
+	}
+
+	for _, test := range tests {
+		test.metadata["authModes"] = "basic"
+		s, err := NewProxyStatsScaler(&ScalerConfig{
+			TriggerMetadata:   test.metadata,
+			AuthParams:        map[string]string{"username": "user", "password": "pass"},
+			GlobalHTTPTimeout: 3000 * time.Millisecond,
+		})
+		assert.NoError(t, err, test.name)
+
+		metrics, isActive, err := s.GetMetricsAndActivity(context.Background(), "s0-proxy-stats")
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.value, metrics[0].Value.AsApproximateFloat64(), test.name)
+		assert.Equal(t, test.isActive, isActive, test.name)
+	}
+}
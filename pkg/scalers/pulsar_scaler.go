@@ -100,15 +100,15 @@ func NewPulsarScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing pulsar metadata: %w", err)
 	}
 
-	client := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	client := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy)
 
 	if pulsarMetadata.pulsarAuth != nil {
-		if pulsarMetadata.pulsarAuth.CA != "" || pulsarMetadata.pulsarAuth.EnableTLS {
-			config, err := authentication.NewTLSConfig(pulsarMetadata.pulsarAuth, false)
+		if pulsarMetadata.pulsarAuth.CA != "" || pulsarMetadata.pulsarAuth.EnableTLS || pulsarMetadata.pulsarAuth.ServerPublicKeyPin != "" {
+			tlsConfig, err := authentication.NewTLSConfig(pulsarMetadata.pulsarAuth, false)
 			if err != nil {
 				return nil, err
 			}
-			client.Transport = kedautil.CreateHTTPTransportWithTLSConfig(config)
+			client.Transport = kedautil.CreateHTTPTransportWithProxy(tlsConfig, config.HTTPProxy)
 		}
 
 		if pulsarMetadata.pulsarAuth.EnableBearerAuth || pulsarMetadata.pulsarAuth.EnableBasicAuth {
@@ -0,0 +1,281 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+type pvcUsageScaler struct {
+	metricType    v2.MetricTargetType
+	metadata      *pvcUsageMetadata
+	kubeClient    client.Client
+	statsProvider pvcStatsProvider
+	logger        logr.Logger
+}
+
+type pvcUsageMetadata struct {
+	namespace                             string
+	pvcSelector                           labels.Selector
+	targetUtilizationPercentage           float64
+	activationTargetUtilizationPercentage float64
+	scalerIndex                           int
+}
+
+// kubeletStatsSummary is the subset of the kubelet stats summary API needed by the scaler
+type kubeletStatsSummary struct {
+	Pods []kubeletPodStats `json:"pods"`
+}
+
+type kubeletPodStats struct {
+	VolumeStats []kubeletVolumeStats `json:"volume,omitempty"`
+}
+
+type kubeletVolumeStats struct {
+	Name          string         `json:"name"`
+	PVCRef        *kubeletPVCRef `json:"pvcRef,omitempty"`
+	CapacityBytes *uint64        `json:"capacityBytes,omitempty"`
+	UsedBytes     *uint64        `json:"usedBytes,omitempty"`
+}
+
+type kubeletPVCRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// podMountsPVC returns whether one of the volumes of the pod is the pvc
+func podMountsPVC(pod *corev1.Pod, pvcName string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvcName {
+			return true
+		}
+	}
+	return false
+}
+
+// pvcStatsProvider returns the kubelet stats summary of a node
+type pvcStatsProvider interface {
+	GetNodeSummary(ctx context.Context, nodeName string) (*kubeletStatsSummary, error)
+}
+
+// apiServerProxyStatsProvider reads the kubelet stats summary through the apiserver node proxy
+type apiServerProxyStatsProvider struct {
+	clientset kubernetes.Interface
+}
+
+func (p *apiServerProxyStatsProvider) GetNodeSummary(ctx context.Context, nodeName string) (*kubeletStatsSummary, error) {
+	body, err := p.clientset.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", nodeName, "proxy", "stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &kubeletStatsSummary{}
+	if err := json.Unmarshal(body, summary); err != nil {
+		return nil, fmt.Errorf("error parsing stats summary of node %s: %w", nodeName, err)
+	}
+	return summary, nil
+}
+
+// NewPVCUsageScaler creates a new pvcUsageScaler
+func NewPVCUsageScaler(kubeClient client.Client, config *ScalerConfig) (Scaler, error) {
+	statsProvider, err := newAPIServerProxyStatsProvider()
+	if err != nil {
+		return nil, fmt.Errorf("error creating kubelet stats provider: %w", err)
+	}
+	return newPVCUsageScaler(kubeClient, statsProvider, config)
+}
+
+func newAPIServerProxyStatsProvider() (pvcStatsProvider, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &apiServerProxyStatsProvider{clientset: clientset}, nil
+}
+
+func newPVCUsageScaler(kubeClient client.Client, statsProvider pvcStatsProvider, config *ScalerConfig) (Scaler, error) {
+	metricType, err := GetMetricTargetType(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scaler metric type: %w", err)
+	}
+
+	meta, err := parsePVCUsageMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing pvc usage metadata: %w", err)
+	}
+
+	return &pvcUsageScaler{
+		metricType:    metricType,
+		metadata:      meta,
+		kubeClient:    kubeClient,
+		statsProvider: statsProvider,
+		logger:        InitializeLogger(config, "pvc_usage_scaler"),
+	}, nil
+}
+
+func parsePVCUsageMetadata(config *ScalerConfig) (*pvcUsageMetadata, error) {
+	meta := &pvcUsageMetadata{}
+
+	// the pvcs are only read in the namespace of the scaled object
+	meta.namespace = config.ScalableObjectNamespace
+
+	pvcSelector, err := labels.Parse(config.TriggerMetadata["pvcSelector"])
+	if err != nil || pvcSelector.String() == "" {
+		return nil, fmt.Errorf("invalid pvc selector")
+	}
+	meta.pvcSelector = pvcSelector
+
+	val, ok := config.TriggerMetadata["targetUtilizationPercentage"]
+	if !ok {
+		return nil, fmt.Errorf("no targetUtilizationPercentage given")
+	}
+	targetUtilizationPercentage, err := strconv.ParseFloat(val, 64)
+	if err != nil || targetUtilizationPercentage <= 0 || targetUtilizationPercentage > 100 {
+		return nil, fmt.Errorf("targetUtilizationPercentage must be a float greater than 0 and lower or equal to 100")
+	}
+	meta.targetUtilizationPercentage = targetUtilizationPercentage
+
+	meta.activationTargetUtilizationPercentage = 0
+	if val, ok := config.TriggerMetadata["activationTargetUtilizationPercentage"]; ok {
+		activationTargetUtilizationPercentage, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationTargetUtilizationPercentage parsing error %w", err)
+		}
+		meta.activationTargetUtilizationPercentage = activationTargetUtilizationPercentage
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+	return meta, nil
+}
+
+// Close no need for pvc usage scaler
+func (s *pvcUsageScaler) Close(context.Context) error {
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *pvcUsageScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	externalMetric := &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("pvc-usage-%s", s.metadata.namespace))),
+		},
+		Target: GetMetricTargetMili(s.metricType, s.metadata.targetUtilizationPercentage),
+	}
+	metricSpec := v2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2.MetricSpec{metricSpec}
+}
+
+// GetMetricsAndActivity returns the max utilization percentage across the matching PVCs
+func (s *pvcUsageScaler) GetMetricsAndActivity(ctx context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	utilization, err := s.getMaxUtilization(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, false, fmt.Errorf("error inspecting pvc usage: %w", err)
+	}
+
+	metric := GenerateMetricInMili(metricName, utilization)
+
+	return []external_metrics.ExternalMetricValue{metric}, utilization > s.metadata.activationTargetUtilizationPercentage, nil
+}
+
+func (s *pvcUsageScaler) getMaxUtilization(ctx context.Context) (float64, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	err := s.kubeClient.List(ctx, pvcList, &client.ListOptions{
+		Namespace:     s.metadata.namespace,
+		LabelSelector: s.metadata.pvcSelector,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(pvcList.Items) == 0 {
+		return 0, fmt.Errorf("no pvc matches the selector %s in namespace %s", s.metadata.pvcSelector, s.metadata.namespace)
+	}
+
+	summaries := map[string]*kubeletStatsSummary{}
+	found := false
+	maxUtilization := float64(0)
+	for _, pvc := range pvcList.Items {
+		nodeName, err := s.getPVCNode(ctx, pvc.Name)
+		if err != nil {
+			return 0, err
+		}
+		if nodeName == "" {
+			s.logger.V(1).Info("Skipping pvc which isn't mounted by any scheduled pod", "pvc", pvc.Name)
+			continue
+		}
+
+		summary, ok := summaries[nodeName]
+		if !ok {
+			summary, err = s.statsProvider.GetNodeSummary(ctx, nodeName)
+			if err != nil {
+				s.logger.V(1).Info("Skipping pvc, unable to get stats summary of its node", "pvc", pvc.Name, "node", nodeName, "error", err.Error())
+				continue
+			}
+			summaries[nodeName] = summary
+		}
+
+		utilization, ok := getPVCUtilization(summary, pvc.Namespace, pvc.Name)
+		if !ok {
+			s.logger.V(1).Info("Skipping pvc without volume stats", "pvc", pvc.Name, "node", nodeName)
+			continue
+		}
+		found = true
+		if utilization > maxUtilization {
+			maxUtilization = utilization
+		}
+	}
+
+	if !found {
+		return 0, fmt.Errorf("no volume stats found for the pvcs matching the selector %s in namespace %s", s.metadata.pvcSelector, s.metadata.namespace)
+	}
+	return maxUtilization, nil
+}
+
+// getPVCNode returns the node of a scheduled pod mounting the pvc, where the kubelet reports its volume stats. It is
+// empty if no scheduled pod mounts the pvc. The API server can't select the pods by their volumes, so the pods of the
+// namespace are listed when a pvc-usage trigger is polled, like the kubernetes-workload scaler does
+func (s *pvcUsageScaler) getPVCNode(ctx context.Context, pvcName string) (string, error) {
+	podList := &corev1.PodList{}
+	err := s.kubeClient.List(ctx, podList, client.InNamespace(s.metadata.namespace))
+	if err != nil {
+		return "", err
+	}
+	for i := range podList.Items {
+		if podList.Items[i].Spec.NodeName != "" && podMountsPVC(&podList.Items[i], pvcName) {
+			return podList.Items[i].Spec.NodeName, nil
+		}
+	}
+	return "", nil
+}
+
+func getPVCUtilization(summary *kubeletStatsSummary, namespace, name string) (float64, bool) {
+	for _, pod := range summary.Pods {
+		for _, volume := range pod.VolumeStats {
+			if volume.PVCRef == nil || volume.PVCRef.Namespace != namespace || volume.PVCRef.Name != name {
+				continue
+			}
+			if volume.CapacityBytes == nil || volume.UsedBytes == nil || *volume.CapacityBytes == 0 {
+				return 0, false
+			}
+			return float64(*volume.UsedBytes) / float64(*volume.CapacityBytes) * 100, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,214 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type parsePVCUsageMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+type pvcUsageMetricIdentifier struct {
+	metadataTestData *parsePVCUsageMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testPVCUsageMetadata = []parsePVCUsageMetadataTestData{
+	// properly formed
+	{map[string]string{"pvcSelector": "app=demo", "targetUtilizationPercentage": "80"}, false},
+	// namespace is ignored and activation
+	{map[string]string{"pvcSelector": "app=demo", "namespace": "other", "targetUtilizationPercentage": "80", "activationTargetUtilizationPercentage": "50"}, false},
+	// missing selector
+	{map[string]string{"targetUtilizationPercentage": "80"}, true},
+	// invalid selector
+	{map[string]string{"pvcSelector": "app in (", "targetUtilizationPercentage": "80"}, true},
+	// missing target
+	{map[string]string{"pvcSelector": "app=demo"}, true},
+	// invalid target
+	{map[string]string{"pvcSelector": "app=demo", "targetUtilizationPercentage": "a"}, true},
+	// out of range target
+	{map[string]string{"pvcSelector": "app=demo", "targetUtilizationPercentage": "150"}, true},
+	{map[string]string{"pvcSelector": "app=demo", "targetUtilizationPercentage": "0"}, true},
+	// invalid activation
+	{map[string]string{"pvcSelector": "app=demo", "targetUtilizationPercentage": "80", "activationTargetUtilizationPercentage": "a"}, true},
+}
+
+var pvcUsageMetricIdentifiers = []pvcUsageMetricIdentifier{
+	{&testPVCUsageMetadata[0], 0, "s0-pvc-usage-test"},
+	{&testPVCUsageMetadata[1], 1, "s1-pvc-usage-test"},
+}
+
+func TestParsePVCUsageMetadata(t *testing.T) {
+	for _, testData := range testPVCUsageMetadata {
+		_, err := parsePVCUsageMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ScalableObjectNamespace: "test"})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestPVCUsageGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range pvcUsageMetricIdentifiers {
+		s, err := newPVCUsageScaler(fake.NewClientBuilder().Build(), &fakePVCStatsProvider{}, &ScalerConfig{
+			TriggerMetadata:         testData.metadataTestData.metadata,
+			ScalableObjectNamespace: "test",
+			ScalerIndex:             testData.scalerIndex,
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		metricSpec := s.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+type fakePVCStatsProvider struct {
+	summaries map[string]*kubeletStatsSummary
+}
+
+func (p *fakePVCStatsProvider) GetNodeSummary(_ context.Context, nodeName string) (*kubeletStatsSummary, error) {
+	summary, ok := p.summaries[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("node %s not found", nodeName)
+	}
+	return summary, nil
+}
+
+type pvcUsageMetricsTestData struct {
+	name          string
+	pvcs          []string
+	mounts        map[string]string
+	summaries     map[string]*kubeletStatsSummary
+	expectedValue float64
+	isActive      bool
+	isError       bool
+}
+
+var pvcUsageMetricsTestDataset = []pvcUsageMetricsTestData{
+	{
+		name:   "max utilization across nodes",
+		pvcs:   []string{"data-0", "data-1"},
+		mounts: map[string]string{"data-0": "node-a", "data-1": "node-b"},
+		summaries: map[string]*kubeletStatsSummary{
+			"node-a": createStatsSummary("data-0", 100, 30),
+			"node-b": createStatsSummary("data-1", 100, 90),
+		},
+		expectedValue: 90,
+		isActive:      true,
+	},
+	{
+		name:   "missing stats are skipped",
+		pvcs:   []string{"data-0", "data-1", "data-2"},
+		mounts: map[string]string{"data-0": "node-a", "data-1": "node-b"},
+		summaries: map[string]*kubeletStatsSummary{
+			"node-a": createStatsSummary("data-0", 200, 50),
+		},
+		expectedValue: 25,
+		isActive:      false,
+	},
+	{
+		name:      "no stats at all",
+		pvcs:      []string{"data-0"},
+		mounts:    map[string]string{"data-0": "node-a"},
+		summaries: map[string]*kubeletStatsSummary{"node-a": {}},
+		isError:   true,
+	},
+	{
+		name:    "no matching pvc",
+		isError: true,
+	},
+}
+
+func TestPVCUsageGetMetricsAndActivity(t *testing.T) {
+	for _, testData := range pvcUsageMetricsTestDataset {
+		t.Run(testData.name, func(t *testing.T) {
+			objects := []runtime.Object{}
+			for _, pvc := range testData.pvcs {
+				objects = append(objects, &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: pvc, Namespace: "test", Labels: map[string]string{"app": "demo"}},
+				})
+			}
+			for pvc, node := range testData.mounts {
+				objects = append(objects, createPodMountingPVC(pvc, node))
+			}
+
+			s, err := newPVCUsageScaler(
+				fake.NewClientBuilder().WithRuntimeObjects(objects...).Build(),
+				&fakePVCStatsProvider{summaries: testData.summaries},
+				&ScalerConfig{
+					TriggerMetadata:         map[string]string{"pvcSelector": "app=demo", "targetUtilizationPercentage": "80", "activationTargetUtilizationPercentage": "30"},
+					ScalableObjectNamespace: "test",
+				},
+			)
+			if err != nil {
+				t.Fatal("Could not create scaler:", err)
+			}
+
+			metrics, isActive, err := s.GetMetricsAndActivity(context.Background(), "s0-pvc-usage-test")
+			if testData.isError {
+				if err == nil {
+					t.Error("Expected error but got success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Expected success but got error", err)
+			}
+			if value := metrics[0].Value.AsApproximateFloat64(); value != testData.expectedValue {
+				t.Errorf("Expected value %v but got %v", testData.expectedValue, value)
+			}
+			if isActive != testData.isActive {
+				t.Errorf("Expected active %v but got %v", testData.isActive, isActive)
+			}
+		})
+	}
+}
+
+func createStatsSummary(pvc string, capacityBytes, usedBytes uint64) *kubeletStatsSummary {
+	return &kubeletStatsSummary{
+		Pods: []kubeletPodStats{
+			{
+				VolumeStats: []kubeletVolumeStats{
+					{
+						Name:          "data",
+						PVCRef:        &kubeletPVCRef{Name: pvc, Namespace: "test"},
+						CapacityBytes: &capacityBytes,
+						UsedBytes:     &usedBytes,
+					},
+				},
+			},
+		},
+	}
+}
+
+func createPodMountingPVC(pvc, node string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-" + pvc, Namespace: "test"},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc},
+					},
+				},
+			},
+		},
+	}
+}
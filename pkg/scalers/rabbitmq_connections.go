@@ -0,0 +1,196 @@
+package scalers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/hashstructure"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultRabbitMQHeartbeat = 10 * time.Second
+	defaultRabbitMQLocale    = "en_US"
+)
+
+// rabbitMQConnection is the part of amqp.Connection used by the scaler
+type rabbitMQConnection interface {
+	Channel() (rabbitMQChannel, error)
+	IsClosed() bool
+	Close() error
+}
+
+// rabbitMQChannel is the part of amqp.Channel used by the scaler
+type rabbitMQChannel interface {
+	QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	IsClosed() bool
+	Close() error
+}
+
+type amqpConnection struct {
+	*amqp.Connection
+}
+
+func (c amqpConnection) Channel() (rabbitMQChannel, error) {
+	return c.Connection.Channel()
+}
+
+// rabbitMQDial opens the AMQP connections of the pool
+var rabbitMQDial = func(host string, config amqp.Config) (rabbitMQConnection, error) {
+	conn, err := amqp.DialConfig(host, config)
+	if err != nil {
+		return nil, err
+	}
+	return amqpConnection{conn}, nil
+}
+
+// pooledRabbitMQConnection is an AMQP connection shared by the triggers with the same host, vhost, credentials and
+// dial settings, each of them has its own channel
+type pooledRabbitMQConnection struct {
+	key    uint64
+	host   string
+	config amqp.Config
+	refs   int
+
+	lock       sync.Mutex
+	connection rabbitMQConnection
+}
+
+var (
+	rabbitMQConnectionPool     = map[uint64]*pooledRabbitMQConnection{}
+	rabbitMQConnectionPoolLock sync.Mutex
+
+	rabbitMQHTTPClients sync.Map
+)
+
+// acquireRabbitMQConnection returns the pooled connection to the host, it's dialed if no other trigger uses it.
+// The connection must be given back with releaseRabbitMQConnection
+func acquireRabbitMQConnection(host string, meta *rabbitMQMetadata) (*pooledRabbitMQConnection, error) {
+	key, err := hashstructure.Hash([]string{host, meta.ca, meta.cert, meta.key, meta.keyPassword, meta.serverPublicKeyPin,
+		fmt.Sprint(meta.enableTLS), fmt.Sprint(meta.unsafeSsl), meta.timeout.String(), meta.heartbeat.String()}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rabbitMQConnectionPoolLock.Lock()
+	defer rabbitMQConnectionPoolLock.Unlock()
+
+	if pooled, found := rabbitMQConnectionPool[key]; found {
+		pooled.refs++
+		return pooled, nil
+	}
+
+	config, err := getRabbitMQDialConfig(meta)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := rabbitMQDial(host, config)
+	if err != nil {
+		return nil, err
+	}
+	pooled := &pooledRabbitMQConnection{key: key, host: host, config: config, refs: 1, connection: connection}
+	rabbitMQConnectionPool[key] = pooled
+	return pooled, nil
+}
+
+// releaseRabbitMQConnection gives back a connection returned by acquireRabbitMQConnection, the connection is closed
+// once no trigger uses it
+func releaseRabbitMQConnection(pooled *pooledRabbitMQConnection) error {
+	rabbitMQConnectionPoolLock.Lock()
+	defer rabbitMQConnectionPoolLock.Unlock()
+
+	pooled.refs--
+	if pooled.refs > 0 {
+		return nil
+	}
+	delete(rabbitMQConnectionPool, pooled.key)
+
+	pooled.lock.Lock()
+	defer pooled.lock.Unlock()
+	if pooled.connection.IsClosed() {
+		return nil
+	}
+	return pooled.connection.Close()
+}
+
+// channel opens a channel on the connection, a closed connection is dialed again first
+func (p *pooledRabbitMQConnection) channel() (rabbitMQChannel, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.connection.IsClosed() {
+		connection, err := rabbitMQDial(p.host, p.config)
+		if err != nil {
+			return nil, err
+		}
+		p.connection = connection
+	}
+	return p.connection.Channel()
+}
+
+func getRabbitMQDialConfig(meta *rabbitMQMetadata) (amqp.Config, error) {
+	config := amqp.Config{
+		Heartbeat: defaultRabbitMQHeartbeat,
+		Locale:    defaultRabbitMQLocale,
+	}
+	if meta.heartbeat > 0 {
+		config.Heartbeat = meta.heartbeat
+	}
+	if meta.timeout > 0 {
+		config.Dial = amqp.DefaultDial(meta.timeout)
+	}
+	if meta.enableTLS {
+		tlsConfig, err := kedautil.NewTLSConfigWithPassword(meta.cert, meta.key, meta.keyPassword, meta.ca, meta.unsafeSsl)
+		if err != nil {
+			return config, err
+		}
+		if err := kedautil.PinServerPublicKey(tlsConfig, meta.serverPublicKeyPin); err != nil {
+			return config, err
+		}
+		config.TLSClientConfig = tlsConfig
+	}
+	return config, nil
+}
+
+// getRabbitMQHTTPClient returns the HTTP client shared by the triggers using the http protocol with the same settings
+func getRabbitMQHTTPClient(timeout time.Duration, unsafeSsl bool, proxy *url.URL) *http.Client {
+	key := fmt.Sprintf("%s/%t/%s", timeout, unsafeSsl, proxy)
+	if client, found := rabbitMQHTTPClients.Load(key); found {
+		return client.(*http.Client)
+	}
+	client, _ := rabbitMQHTTPClients.LoadOrStore(key, kedautil.CreateHTTPClientWithProxy(timeout, unsafeSsl, proxy))
+	return client.(*http.Client)
+}
+
+// getQueueMessages returns the number of messages in the queue, the channel is opened again if it's closed and a
+// broken connection is dialed again once
+func (s *rabbitMQScaler) getQueueMessages() (int, error) {
+	s.channelLock.Lock()
+	defer s.channelLock.Unlock()
+
+	for retry := true; ; retry = false {
+		if s.channel == nil || s.channel.IsClosed() {
+			channel, err := s.connection.channel()
+			if err != nil {
+				return -1, err
+			}
+			s.channel = channel
+		}
+
+		// QueueDeclarePassive assumes that the queue exists and fails if it doesn't
+		queue, err := s.channel.QueueDeclarePassive(s.metadata.queueName, false, false, false, false, amqp.Table{})
+		if err == nil {
+			return queue.Messages, nil
+		}
+		if !retry || !errors.Is(err, amqp.ErrClosed) {
+			return -1, err
+		}
+		s.channel = nil
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -56,10 +57,12 @@ const (
 type rabbitMQScaler struct {
 	metricType v2.MetricTargetType
 	metadata   *rabbitMQMetadata
-	connection *amqp.Connection
-	channel    *amqp.Channel
+	connection *pooledRabbitMQConnection
 	httpClient *http.Client
 	logger     logr.Logger
+
+	channelLock sync.Mutex
+	channel     rabbitMQChannel
 }
 
 type rabbitMQMetadata struct {
@@ -75,7 +78,8 @@ type rabbitMQMetadata struct {
 	pageSize              int64         // specify the page size if useRegex is enabled
 	operation             string        // specify the operation to apply in case of multiples queues
 	metricName            string        // custom metric name for trigger
-	timeout               time.Duration // custom http timeout for a specific trigger
+	timeout               time.Duration // custom http or amqp dial timeout for a specific trigger
+	heartbeat             time.Duration // amqp heartbeat interval
 	scalerIndex           int           // scaler index
 
 	// TLS
@@ -85,6 +89,8 @@ type rabbitMQMetadata struct {
 	keyPassword string
 	enableTLS   bool
 	unsafeSsl   bool
+	// serverPublicKeyPin replaces the verification of the certificate chain of the broker when it's set
+	serverPublicKeyPin string
 }
 
 type queueInfo struct {
@@ -125,9 +131,10 @@ func NewRabbitMQScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing rabbitmq metadata: %w", err)
 	}
 	s.metadata = meta
-	s.httpClient = kedautil.CreateHTTPClient(meta.timeout, meta.unsafeSsl)
 
-	if meta.protocol == amqpProtocol {
+	if meta.protocol == httpProtocol {
+		s.httpClient = getRabbitMQHTTPClient(meta.timeout, meta.unsafeSsl, config.HTTPProxy)
+	} else {
 		// Override vhost if requested.
 		host := meta.host
 		if meta.vhostName != "" {
@@ -139,12 +146,16 @@ func NewRabbitMQScaler(config *ScalerConfig) (Scaler, error) {
 			host = hostURI.String()
 		}
 
-		conn, ch, err := getConnectionAndChannel(host, meta)
+		conn, err := acquireRabbitMQConnection(host, meta)
 		if err != nil {
 			return nil, fmt.Errorf("error establishing rabbitmq connection: %w", err)
 		}
 		s.connection = conn
-		s.channel = ch
+		s.channel, err = conn.channel()
+		if err != nil {
+			_ = releaseRabbitMQConnection(conn)
+			return nil, fmt.Errorf("error establishing rabbitmq connection: %w", err)
+		}
 	}
 
 	return s, nil
@@ -184,19 +195,33 @@ func resolveTimeout(config *ScalerConfig, meta *rabbitMQMetadata) error {
 		if err != nil {
 			return fmt.Errorf("unable to parse timeout: %w", err)
 		}
-		if meta.protocol == amqpProtocol {
-			return fmt.Errorf("amqp protocol doesn't support custom timeouts: %w", err)
-		}
 		if timeoutMS <= 0 {
 			return fmt.Errorf("timeout must be greater than 0: %w", err)
 		}
 		meta.timeout = time.Duration(timeoutMS) * time.Millisecond
-	} else {
+	} else if meta.protocol == httpProtocol {
 		meta.timeout = config.GlobalHTTPTimeout
 	}
 	return nil
 }
 
+func resolveHeartbeat(config *ScalerConfig, meta *rabbitMQMetadata) error {
+	if val, ok := config.TriggerMetadata["heartbeat"]; ok {
+		if meta.protocol != amqpProtocol {
+			return fmt.Errorf("heartbeat is only supported by the amqp protocol")
+		}
+		heartbeatMS, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("unable to parse heartbeat: %w", err)
+		}
+		if heartbeatMS <= 0 {
+			return fmt.Errorf("heartbeat must be greater than 0")
+		}
+		meta.heartbeat = time.Duration(heartbeatMS) * time.Millisecond
+	}
+	return nil
+}
+
 func resolveTLSAuthParams(config *ScalerConfig, meta *rabbitMQMetadata) error {
 	meta.enableTLS = false
 	if val, ok := config.AuthParams["tls"]; ok {
@@ -205,6 +230,11 @@ func resolveTLSAuthParams(config *ScalerConfig, meta *rabbitMQMetadata) error {
 			meta.ca = config.AuthParams["ca"]
 			meta.cert = config.AuthParams["cert"]
 			meta.key = config.AuthParams["key"]
+			pin, err := getTLSServerPublicKeyPin(config)
+			if err != nil {
+				return err
+			}
+			meta.serverPublicKeyPin = pin
 			meta.enableTLS = true
 		} else if val != "disable" {
 			return fmt.Errorf("err incorrect value for TLS given: %s", val)
@@ -307,6 +337,11 @@ func parseRabbitMQMetadata(config *ScalerConfig) (*rabbitMQMetadata, error) {
 	if err := resolveTimeout(config, &meta); err != nil {
 		return nil, err
 	}
+
+	// Resolve heartbeat
+	if err := resolveHeartbeat(config, &meta); err != nil {
+		return nil, err
+	}
 	meta.scalerIndex = config.ScalerIndex
 
 	return &meta, nil
@@ -424,44 +459,30 @@ func parseTrigger(meta *rabbitMQMetadata, config *ScalerConfig) (*rabbitMQMetada
 	return meta, nil
 }
 
-// getConnectionAndChannel returns an amqp connection. If enableTLS is true tls connection is made using
-//
-//	the given ceClient cert, ceClient key,and CA certificate. If clientKeyPassword is not empty the provided password will be used to
-//
-// decrypt the given key. If enableTLS is disabled then amqp connection will be created without tls.
-func getConnectionAndChannel(host string, meta *rabbitMQMetadata) (*amqp.Connection, *amqp.Channel, error) {
-	var conn *amqp.Connection
-	var err error
-	if meta.enableTLS {
-		tlsConfig, configErr := kedautil.NewTLSConfigWithPassword(meta.cert, meta.key, meta.keyPassword, meta.ca, meta.unsafeSsl)
-		if configErr == nil {
-			conn, err = amqp.DialTLS(host, tlsConfig)
-		}
-	} else {
-		conn, err = amqp.Dial(host)
+// Close disposes of RabbitMQ connections
+func (s *rabbitMQScaler) Close(context.Context) error {
+	if s.connection == nil {
+		return nil
 	}
-	if err != nil {
-		return nil, nil, err
+
+	s.channelLock.Lock()
+	if s.channel != nil && !s.channel.IsClosed() {
+		_ = s.channel.Close()
 	}
+	s.channelLock.Unlock()
 
-	channel, err := conn.Channel()
+	err := releaseRabbitMQConnection(s.connection)
+	s.connection = nil
 	if err != nil {
-		return nil, nil, err
+		s.logger.Error(err, "Error closing rabbitmq connection")
+		return err
 	}
-
-	return conn, channel, nil
+	return nil
 }
 
-// Close disposes of RabbitMQ connections
-func (s *rabbitMQScaler) Close(context.Context) error {
-	if s.connection != nil {
-		err := s.connection.Close()
-		if err != nil {
-			s.logger.Error(err, "Error closing rabbitmq connection")
-			return err
-		}
-	}
-	return nil
+// ClampNegativeMetrics returns true in the QueueLength mode, the queue length is never negative
+func (s *rabbitMQScaler) ClampNegativeMetrics() bool {
+	return s.metadata.mode == rabbitModeQueueLength
 }
 
 func (s *rabbitMQScaler) getQueueStatus() (int64, float64, error) {
@@ -479,13 +500,12 @@ func (s *rabbitMQScaler) getQueueStatus() (int64, float64, error) {
 		return int64(info.Messages), info.MessageStat.PublishDetail.Rate, nil
 	}
 
-	// QueueDeclarePassive assumes that the queue exists and fails if it doesn't
-	items, err := s.channel.QueueDeclarePassive(s.metadata.queueName, false, false, false, false, amqp.Table{})
+	messages, err := s.getQueueMessages()
 	if err != nil {
 		return -1, -1, err
 	}
 
-	return int64(items.Messages), 0, nil
+	return int64(messages), 0, nil
 }
 
 func getJSON(s *rabbitMQScaler, url string) (queueInfo, error) {
@@ -255,6 +255,11 @@ func (s *redisScaler) Close(context.Context) error {
 	return s.closeFn()
 }
 
+// ClampNegativeMetrics returns true, the list length is never negative
+func (s *redisScaler) ClampNegativeMetrics() bool {
+	return true
+}
+
 // GetMetricSpecForScaling returns the metric spec for the HPA
 func (s *redisScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
 	metricName := util.NormalizeString(fmt.Sprintf("redis-%s", s.metadata.listName))
@@ -20,7 +20,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -32,6 +35,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 func init() {
@@ -61,6 +65,64 @@ type PushScaler interface {
 	Run(ctx context.Context, active chan<- bool)
 }
 
+// CountScaler interface is implemented by the scalers reporting counts (e.g. queue length),
+// these can never be negative so negative values are clamped to 0 before they are used
+type CountScaler interface {
+	Scaler
+
+	// ClampNegativeMetrics opts the scaler in for clamping its negative metric values
+	ClampNegativeMetrics() bool
+}
+
+// ClampsNegativeMetrics returns whether the negative metric values of the scaler should be clamped to 0
+func ClampsNegativeMetrics(scaler Scaler) bool {
+	countScaler, ok := scaler.(CountScaler)
+	return ok && countScaler.ClampNegativeMetrics()
+}
+
+// UnitScaler interface is implemented by the scalers knowing the unit of their metric values (e.g. seconds, bytes),
+// the unit is exported along the metric value so dashboards don't have to guess it
+type UnitScaler interface {
+	Scaler
+
+	// GetMetricUnit returns the unit of the metric values in the Prometheus base unit naming, empty when it's unknown
+	GetMetricUnit() string
+}
+
+// GetMetricUnit returns the unit of the metric values of the scaler, empty when it's unknown
+func GetMetricUnit(scaler Scaler) string {
+	if unitScaler, ok := scaler.(UnitScaler); ok {
+		return unitScaler.GetMetricUnit()
+	}
+	return ""
+}
+
+// PartitionScaler interface is implemented by the streaming scalers reading partitions or shards (e.g. Kafka
+// partitions, Kinesis shards), the count observed by the last poll is exposed for capacity planning
+type PartitionScaler interface {
+	Scaler
+
+	// GetPartitionCount returns the number of partitions or shards observed by the last poll, false when none was observed yet
+	GetPartitionCount() (int, bool)
+}
+
+// observedPartitions keeps the partition count observed by the last poll of a PartitionScaler, it's safe for
+// concurrent use as the metrics can be read while the scaler is polled
+type observedPartitions struct {
+	// count is shifted by one so the zero value means that no count was observed yet
+	count atomic.Int64
+}
+
+func (o *observedPartitions) setPartitionCount(count int) {
+	o.count.Store(int64(count) + 1)
+}
+
+// GetPartitionCount returns the partition count observed by the last poll, false when none was observed yet
+func (o *observedPartitions) GetPartitionCount() (int, bool) {
+	count := o.count.Load()
+	return int(count - 1), count > 0
+}
+
 // ScalerConfig contains config fields common for all scalers
 type ScalerConfig struct {
 	// ScalableObjectName specifies name of the ScaledObject/ScaledJob that owns this scaler
@@ -78,10 +140,21 @@ type ScalerConfig struct {
 	// Name of the trigger
 	TriggerName string
 
+	// Type of the trigger, e.g. prometheus
+	TriggerType string
+
 	// Marks whether we should query metrics only during the polling interval
 	// Any requests for metrics in between are read from the cache
 	TriggerUseCachedMetrics bool
 
+	// TriggerMaxStaleness is the time after which a metric value which hasn't changed
+	// is considered stale and reported as a scaler error, zero disables the check
+	TriggerMaxStaleness time.Duration
+
+	// TriggerPollingInterval is the interval between the queries of the trigger by the scale loop, the last values
+	// are reused by the polls in between. Zero queries the trigger on every poll of the ScaledObject
+	TriggerPollingInterval time.Duration
+
 	// TriggerMetadata
 	TriggerMetadata map[string]string
 
@@ -99,6 +172,9 @@ type ScalerConfig struct {
 
 	// MetricType
 	MetricType v2.MetricTargetType
+
+	// HTTPProxy is the proxy set for the trigger, nil means the global proxy is used
+	HTTPProxy *url.URL
 }
 
 var (
@@ -120,11 +196,24 @@ func GetFromAuthOrMeta(config *ScalerConfig, field string) (string, error) {
 		result = config.TriggerMetadata[field]
 	}
 	if result == "" {
-		err = fmt.Errorf("%w: no %s given", ErrScalerConfigMissingField, field)
+		err = WrapScalerError(ErrConfig, fmt.Errorf("%w: no %s given", ErrScalerConfigMissingField, field))
 	}
 	return result, err
 }
 
+// getTLSServerPublicKeyPin returns the tlsServerPublicKeyPin of the TriggerAuthentication or the metadata, empty when
+// it's not given, see kedautil.PinServerPublicKey
+func getTLSServerPublicKeyPin(config *ScalerConfig) (string, error) {
+	pin, err := GetFromAuthOrMeta(config, "tlsServerPublicKeyPin")
+	if err != nil {
+		return "", nil
+	}
+	if err := kedautil.ValidateServerPublicKeyPin(pin); err != nil {
+		return "", WrapScalerError(ErrConfig, err)
+	}
+	return pin, nil
+}
+
 // GenerateMetricNameWithIndex helps adding the index prefix to the metric name
 func GenerateMetricNameWithIndex(scalerIndex int, metricName string) string {
 	return fmt.Sprintf("s%d-%s", scalerIndex, metricName)
@@ -149,11 +238,71 @@ func InitializeLogger(config *ScalerConfig, scalerName string) logr.Logger {
 	return logf.Log.WithName(scalerName).WithValues("type", config.ScalableObjectType, "namespace", config.ScalableObjectNamespace, "name", config.ScalableObjectName)
 }
 
+// GetHTTPProxy returns the proxy set in the trigger metadata, the credentials
+// can be provided via proxyUsername and proxyPassword in TriggerAuthentication
+func GetHTTPProxy(config *ScalerConfig) (*url.URL, error) {
+	proxy, ok := config.TriggerMetadata["proxy"]
+	if !ok || proxy == "" {
+		return nil, nil
+	}
+	proxyURL, err := kedautil.ParseProxyURL(proxy)
+	if err != nil {
+		return nil, WrapScalerError(ErrConfig, err)
+	}
+
+	if username, ok := config.AuthParams["proxyUsername"]; ok && username != "" {
+		proxyURL.User = url.UserPassword(username, config.AuthParams["proxyPassword"])
+	}
+	return proxyURL, nil
+}
+
+// UnknownBackendHost is the backend host of the scalers without any address of their backend in their trigger
+const UnknownBackendHost = "unknown"
+
+// backendHostFields are the fields of the trigger giving the address of the backend, the first one set is used
+var backendHostFields = []string{"host", "serverAddress", "address", "addresses", "url", "serverURL", "bootstrapServers",
+	"brokerList", "brokerAddress", "managementEndpoint", "adminURL", "natsServerMonitoringEndpoint", "scalerAddress"}
+
+// GetBackendHost returns the host, with its port when it's given, of the backend queried by the scaler, read from
+// the auth parameters, the metadata or the env of the trigger. The first address of a list is used
+func GetBackendHost(config *ScalerConfig) string {
+	for _, field := range backendHostFields {
+		value, _ := GetFromAuthOrMeta(config, field)
+		if value == "" && config.TriggerMetadata[field+"FromEnv"] != "" {
+			value = config.ResolvedEnv[config.TriggerMetadata[field+"FromEnv"]]
+		}
+		if host := parseBackendHost(value, config.TriggerMetadata["port"]); host != "" {
+			return host
+		}
+	}
+	return UnknownBackendHost
+}
+
+// parseBackendHost returns the host of an address given as a URL or as host[:port], the credentials of a URL are
+// dropped and the port is added when the address has none
+func parseBackendHost(address string, port string) string {
+	address = strings.TrimSpace(strings.Split(address, ",")[0])
+	if address == "" {
+		return ""
+	}
+	if !strings.Contains(address, "://") {
+		address = "//" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Port() == "" && port != "" {
+		return net.JoinHostPort(u.Hostname(), port)
+	}
+	return u.Host
+}
+
 // GetMetricTargetType helps getting the metric target type of the scaler
 func GetMetricTargetType(config *ScalerConfig) (v2.MetricTargetType, error) {
 	switch config.MetricType {
 	case v2.UtilizationMetricType:
-		return "", ErrScalerUnsupportedUtilizationMetricType
+		return "", WrapScalerError(ErrConfig, ErrScalerUnsupportedUtilizationMetricType)
 	case "":
 		// Use AverageValue if no metric type was provided
 		return v2.AverageValueMetricType, nil
@@ -88,7 +88,7 @@ func NewSeleniumGridScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing selenium grid metadata: %w", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, meta.unsafeSsl)
+	httpClient := kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, meta.unsafeSsl, config.HTTPProxy)
 
 	return &seleniumGridScaler{
 		metricType: metricType,
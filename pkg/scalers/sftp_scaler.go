@@ -0,0 +1,310 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultSFTPPort        = 22
+	defaultSFTPFilePattern = "*"
+	defaultSFTPMaxDepth    = 5
+)
+
+type sftpScaler struct {
+	metricType v2.MetricTargetType
+	metadata   *sftpMetadata
+	logger     logr.Logger
+}
+
+type sftpMetadata struct {
+	host                string
+	port                int
+	directory           string
+	filePattern         string
+	targetFileCount     int64
+	activationFileCount int64
+	recursive           bool
+	maxDepth            int
+	timeout             time.Duration
+	metricName          string
+
+	// authentication
+	username   string
+	password   string
+	privateKey string
+	passphrase string
+	hostKey    string
+	unsafeSsl  bool
+}
+
+// NewSFTPScaler creates a new scaler counting the files matching a pattern in a directory of an SFTP server, it
+// opens a new connection on every poll
+func NewSFTPScaler(config *ScalerConfig) (Scaler, error) {
+	metricType, err := GetMetricTargetType(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scaler metric type: %w", err)
+	}
+
+	meta, err := parseSFTPMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sftp metadata: %w", err)
+	}
+
+	return &sftpScaler{
+		metricType: metricType,
+		metadata:   meta,
+		logger:     InitializeLogger(config, "sftp_scaler"),
+	}, nil
+}
+
+func parseSFTPMetadata(config *ScalerConfig) (*sftpMetadata, error) {
+	meta := sftpMetadata{}
+
+	if val, ok := config.TriggerMetadata["host"]; ok && val != "" {
+		meta.host = val
+	} else {
+		return nil, fmt.Errorf("no host given")
+	}
+
+	meta.port = defaultSFTPPort
+	if val, ok := config.TriggerMetadata["port"]; ok && val != "" {
+		port, err := strconv.Atoi(val)
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("port must be a number between 1 and 65535, got %s", val)
+		}
+		meta.port = port
+	}
+
+	if val, ok := config.TriggerMetadata["directory"]; ok && val != "" {
+		meta.directory = val
+	} else {
+		return nil, fmt.Errorf("no directory given")
+	}
+
+	meta.filePattern = defaultSFTPFilePattern
+	if val, ok := config.TriggerMetadata["filePattern"]; ok && val != "" {
+		if _, err := path.Match(val, ""); err != nil {
+			return nil, fmt.Errorf("filePattern parsing error: %w", err)
+		}
+		meta.filePattern = val
+	}
+
+	if val, ok := config.TriggerMetadata["targetFileCount"]; ok && val != "" {
+		targetFileCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetFileCount parsing error: %w", err)
+		}
+		if targetFileCount <= 0 {
+			return nil, fmt.Errorf("targetFileCount must be greater than 0, got %s", val)
+		}
+		meta.targetFileCount = targetFileCount
+	} else {
+		return nil, fmt.Errorf("no targetFileCount given")
+	}
+
+	meta.activationFileCount = 0
+	if val, ok := config.TriggerMetadata["activationFileCount"]; ok && val != "" {
+		activationFileCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationFileCount parsing error: %w", err)
+		}
+		meta.activationFileCount = activationFileCount
+	}
+
+	if val, ok := config.TriggerMetadata["recursive"]; ok && val != "" {
+		recursive, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("recursive parsing error: %w", err)
+		}
+		meta.recursive = recursive
+	}
+
+	meta.maxDepth = defaultSFTPMaxDepth
+	if val, ok := config.TriggerMetadata["maxDepth"]; ok && val != "" {
+		maxDepth, err := strconv.Atoi(val)
+		if err != nil || maxDepth < 1 {
+			return nil, fmt.Errorf("maxDepth must be a number greater than 0, got %s", val)
+		}
+		meta.maxDepth = maxDepth
+	}
+
+	meta.timeout = config.GlobalHTTPTimeout
+	if val, ok := config.TriggerMetadata["timeout"]; ok && val != "" {
+		timeoutMS, err := strconv.Atoi(val)
+		if err != nil || timeoutMS <= 0 {
+			return nil, fmt.Errorf("timeout must be a number of milliseconds greater than 0, got %s", val)
+		}
+		meta.timeout = time.Duration(timeoutMS) * time.Millisecond
+	}
+
+	if val, ok := config.TriggerMetadata["unsafeSsl"]; ok && val != "" {
+		unsafeSsl, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("unsafeSsl parsing error: %w", err)
+		}
+		meta.unsafeSsl = unsafeSsl
+	}
+
+	meta.username = config.AuthParams["username"]
+	if meta.username == "" {
+		return nil, fmt.Errorf("no username given")
+	}
+	meta.password = config.AuthParams["password"]
+	meta.privateKey = config.AuthParams["privateKey"]
+	meta.passphrase = config.AuthParams["passphrase"]
+	if meta.password == "" && meta.privateKey == "" {
+		return nil, fmt.Errorf("either password or privateKey must be given")
+	}
+	meta.hostKey = config.AuthParams["hostKey"]
+	if meta.hostKey == "" && !meta.unsafeSsl {
+		return nil, fmt.Errorf("no hostKey given, set unsafeSsl to skip the verification of the host key")
+	}
+
+	meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("sftp-%s", meta.directory)))
+	return &meta, nil
+}
+
+// clientConfig returns the SSH configuration authenticating with the password and the private key given
+func (m *sftpMetadata) clientConfig() (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if m.privateKey != "" {
+		var signer ssh.Signer
+		var err error
+		if m.passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(m.privateKey), []byte(m.passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(m.privateKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if m.password != "" {
+		auth = append(auth, ssh.Password(m.password))
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if m.hostKey != "" {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(m.hostKey))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the host key: %w", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	}
+
+	return &ssh.ClientConfig{
+		User:            m.username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         m.timeout,
+	}, nil
+}
+
+// countFiles connects to the server and counts the regular files matching the pattern in the directory, and in
+// its subdirectories up to maxDepth when recursive. The timeout applies to the whole session
+func (s *sftpScaler) countFiles(ctx context.Context) (int64, error) {
+	clientConfig, err := s.metadata.clientConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	address := net.JoinHostPort(s.metadata.host, strconv.Itoa(s.metadata.port))
+	dialer := net.Dialer{Timeout: s.metadata.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return 0, fmt.Errorf("error connecting to %s: %w", address, err)
+	}
+	defer conn.Close()
+	if s.metadata.timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(s.metadata.timeout)); err != nil {
+			return 0, err
+		}
+	}
+
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, address, clientConfig)
+	if err != nil {
+		return 0, fmt.Errorf("error opening the SSH connection to %s: %w", address, err)
+	}
+	sshClient := ssh.NewClient(sshConn, channels, requests)
+	defer sshClient.Close()
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		return 0, fmt.Errorf("error starting the SFTP session: %w", err)
+	}
+	defer client.Close()
+
+	return s.countDirectory(client, s.metadata.directory, 0)
+}
+
+func (s *sftpScaler) countDirectory(client *sftp.Client, directory string, depth int) (int64, error) {
+	entries, err := client.ReadDir(directory)
+	if err != nil {
+		return 0, fmt.Errorf("error listing %s: %w", directory, err)
+	}
+
+	var count int64
+	for _, entry := range entries {
+		switch {
+		case entry.IsDir():
+			if s.metadata.recursive && depth < s.metadata.maxDepth {
+				subCount, err := s.countDirectory(client, path.Join(directory, entry.Name()), depth+1)
+				if err != nil {
+					return 0, err
+				}
+				count += subCount
+			}
+		case entry.Mode().IsRegular():
+			// the pattern is validated when parsing the metadata
+			if matched, _ := path.Match(s.metadata.filePattern, entry.Name()); matched {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// Close does nothing, there is no connection between the polls
+func (s *sftpScaler) Close(context.Context) error {
+	return nil
+}
+
+// GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
+func (s *sftpScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	externalMetric := &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{
+			Name: s.metadata.metricName,
+		},
+		Target: GetMetricTarget(s.metricType, s.metadata.targetFileCount),
+	}
+	metricSpec := v2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2.MetricSpec{metricSpec}
+}
+
+// GetMetricsAndActivity returns the number of matching files and whether it's above the activation file count
+func (s *sftpScaler) GetMetricsAndActivity(ctx context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	count, err := s.countFiles(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, false, fmt.Errorf("error counting the files on the SFTP server: %w", err)
+	}
+
+	metric := GenerateMetricInMili(metricName, float64(count))
+
+	return []external_metrics.ExternalMetricValue{metric}, count > s.metadata.activationFileCount, nil
+}
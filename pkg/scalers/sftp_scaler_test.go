@@ -0,0 +1,296 @@
+package scalers
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+type parseSFTPMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type sftpMetricIdentifier struct {
+	metadataTestData *parseSFTPMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testSFTPMetadata = []parseSFTPMetadataTestData{
+	// password authentication with the defaults
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "10", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, false},
+	// all the settings
+	{map[string]string{"host": "sftp.example.com", "port": "2222", "directory": "/upload/orders", "filePattern": "*.csv", "targetFileCount": "5", "activationFileCount": "1", "recursive": "true", "maxDepth": "2", "timeout": "1000"}, map[string]string{"username": "keda", "privateKey": "key", "hostKey": "ssh-ed25519 AAAA"}, false},
+	// no metadata
+	{map[string]string{}, map[string]string{}, true},
+	// missing host
+	{map[string]string{"directory": "/upload", "targetFileCount": "10", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// invalid port
+	{map[string]string{"host": "sftp.example.com", "port": "70000", "directory": "/upload", "targetFileCount": "10", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// missing directory
+	{map[string]string{"host": "sftp.example.com", "targetFileCount": "10", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// invalid filePattern
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "filePattern": "[", "targetFileCount": "10", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// missing targetFileCount
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// targetFileCount not greater than 0
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "0", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// invalid activationFileCount
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "10", "activationFileCount": "a", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// invalid recursive
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "10", "recursive": "yes please", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// invalid maxDepth
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "10", "maxDepth": "0", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// invalid timeout
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "10", "timeout": "-1", "unsafeSsl": "true"}, map[string]string{"username": "keda", "password": "secret"}, true},
+	// missing username
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "10", "unsafeSsl": "true"}, map[string]string{"password": "secret"}, true},
+	// neither password nor privateKey
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "10", "unsafeSsl": "true"}, map[string]string{"username": "keda"}, true},
+	// no hostKey without unsafeSsl
+	{map[string]string{"host": "sftp.example.com", "directory": "/upload", "targetFileCount": "10"}, map[string]string{"username": "keda", "password": "secret"}, true},
+}
+
+var sftpMetricIdentifiers = []sftpMetricIdentifier{
+	{&testSFTPMetadata[0], 0, "s0-sftp--upload"},
+	{&testSFTPMetadata[1], 1, "s1-sftp--upload-orders"},
+}
+
+func TestParseSFTPMetadata(t *testing.T) {
+	for i, testData := range testSFTPMetadata {
+		_, err := parseSFTPMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("test case %d: expected success but got error %v", i, err)
+		}
+		if err == nil && testData.isError {
+			t.Errorf("test case %d: expected error but got success", i)
+		}
+	}
+}
+
+func TestSFTPGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range sftpMetricIdentifiers {
+		meta, err := parseSFTPMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockSFTPScaler := sftpScaler{metadata: meta}
+
+		metricSpec := mockSFTPScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+// newTestSFTPServer serves the local filesystem over SFTP to the keda user authenticated with the password
+// "secret" or with the returned private key, it returns the address, the host key and the private key
+func newTestSFTPServer(t *testing.T) (string, string, string) {
+	_, hostPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivateKey)
+	require.NoError(t, err)
+	clientPublicKey, clientPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authorizedKey, err := ssh.NewPublicKey(clientPublicKey)
+	require.NoError(t, err)
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(clientPrivateKey)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "keda" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("invalid password")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "keda" && string(key.Marshal()) == string(authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown public key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSFTPConn(conn, config)
+		}
+	}()
+
+	return listener.Addr().String(), string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey())), string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER}))
+}
+
+func serveTestSFTPConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for request := range channelRequests {
+				_ = request.Reply(request.Type == "subsystem" && string(request.Payload[4:]) == "sftp", nil)
+			}
+		}()
+		server, err := sftp.NewServer(channel, sftp.ReadOnly())
+		if err != nil {
+			return
+		}
+		_ = server.Serve()
+		server.Close()
+	}
+}
+
+func TestSFTPGetMetricsAndActivity(t *testing.T) {
+	address, hostKey, privateKey := newTestSFTPServer(t)
+	_, otherHostKey, _ := newTestSFTPServer(t)
+	host, port, err := net.SplitHostPort(address)
+	require.NoError(t, err)
+
+	directory := t.TempDir()
+	for _, name := range []string{"a.csv", "b.csv", "c.txt", "sub/d.csv", "sub/deeper/e.csv"} {
+		path := filepath.Join(directory, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+	}
+
+	testCases := []struct {
+		name           string
+		metadata       map[string]string
+		authParams     map[string]string
+		expectedValue  int64
+		expectedActive bool
+		isError        bool
+	}{
+		{
+			name:           "all files with a password",
+			metadata:       map[string]string{"targetFileCount": "10"},
+			authParams:     map[string]string{"username": "keda", "password": "secret", "hostKey": hostKey},
+			expectedValue:  3,
+			expectedActive: true,
+		},
+		{
+			name:           "matching files with a private key",
+			metadata:       map[string]string{"filePattern": "*.csv", "targetFileCount": "10", "activationFileCount": "2"},
+			authParams:     map[string]string{"username": "keda", "privateKey": privateKey, "hostKey": hostKey},
+			expectedValue:  2,
+			expectedActive: false,
+		},
+		{
+			name:           "recursive up to the depth limit",
+			metadata:       map[string]string{"filePattern": "*.csv", "targetFileCount": "10", "recursive": "true", "maxDepth": "1", "unsafeSsl": "true"},
+			authParams:     map[string]string{"username": "keda", "password": "secret"},
+			expectedValue:  3,
+			expectedActive: true,
+		},
+		{
+			name:           "recursive",
+			metadata:       map[string]string{"filePattern": "*.csv", "targetFileCount": "10", "recursive": "true", "unsafeSsl": "true"},
+			authParams:     map[string]string{"username": "keda", "password": "secret"},
+			expectedValue:  4,
+			expectedActive: true,
+		},
+		{
+			name:       "wrong password",
+			metadata:   map[string]string{"targetFileCount": "10", "unsafeSsl": "true"},
+			authParams: map[string]string{"username": "keda", "password": "wrong"},
+			isError:    true,
+		},
+		{
+			name:       "wrong host key",
+			metadata:   map[string]string{"targetFileCount": "10"},
+			authParams: map[string]string{"username": "keda", "password": "secret", "hostKey": otherHostKey},
+			isError:    true,
+		},
+		{
+			name:       "missing directory",
+			metadata:   map[string]string{"directory": filepath.Join(directory, "missing"), "targetFileCount": "10", "unsafeSsl": "true"},
+			authParams: map[string]string{"username": "keda", "password": "secret"},
+			isError:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.metadata["host"] = host
+			testCase.metadata["port"] = port
+			if _, ok := testCase.metadata["directory"]; !ok {
+				testCase.metadata["directory"] = directory
+			}
+			scaler, err := NewSFTPScaler(&ScalerConfig{TriggerMetadata: testCase.metadata, AuthParams: testCase.authParams, GlobalHTTPTimeout: 5 * time.Second})
+			require.NoError(t, err)
+
+			metrics, active, err := scaler.GetMetricsAndActivity(context.Background(), "s0-sftp")
+			if testCase.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedValue*1000, metrics[0].Value.MilliValue())
+			assert.Equal(t, testCase.expectedActive, active)
+		})
+	}
+}
+
+func TestSFTPDialTimeout(t *testing.T) {
+	// the server accepts the connection but never answers the SSH handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	scaler, err := NewSFTPScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"host": host, "port": port, "directory": "/", "targetFileCount": "10", "timeout": "100", "unsafeSsl": "true"},
+		AuthParams:      map[string]string{"username": "keda", "password": "secret"},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, _, err = scaler.GetMetricsAndActivity(context.Background(), "s0-sftp")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -77,7 +77,7 @@ func NewStanScaler(config *ScalerConfig) (Scaler, error) {
 		channelInfo: &monitorChannelInfo{},
 		metricType:  metricType,
 		metadata:    stanMetadata,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  kedautil.CreateHTTPClientWithProxy(config.GlobalHTTPTimeout, false, config.HTTPProxy),
 		logger:      InitializeLogger(config, "stan_scaler"),
 	}, nil
 }
@@ -0,0 +1,226 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultVaultKVMount   = "secret"
+	defaultVaultKVVersion = 2
+)
+
+type vaultScaler struct {
+	metricType v2.MetricTargetType
+	metadata   *vaultMetadata
+	handler    *resolver.HashicorpVaultHandler
+	logger     logr.Logger
+}
+
+type vaultMetadata struct {
+	vault                 kedav1alpha1.HashiCorpVault
+	serviceAccountToken   string
+	kvMount               string
+	kvVersion             int
+	path                  string
+	jsonPath              []kubernetesResourcePathSegment
+	targetValue           float64
+	activationTargetValue float64
+	metricName            string
+}
+
+// NewVaultScaler creates a new scaler reading a numeric field of a Vault KV secret, it authenticates like the
+// HashiCorp Vault TriggerAuthentication provider and shares its token renewal
+func NewVaultScaler(config *ScalerConfig) (Scaler, error) {
+	metricType, err := GetMetricTargetType(config)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scaler metric type: %w", err)
+	}
+
+	meta, err := parseVaultMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing vault metadata: %w", err)
+	}
+
+	logger := InitializeLogger(config, "vault_scaler")
+	handler := resolver.NewHashicorpVaultHandler(&meta.vault)
+	if meta.serviceAccountToken != "" {
+		handler = resolver.NewHashicorpVaultHandlerWithServiceAccountToken(&meta.vault, meta.serviceAccountToken)
+	}
+	if err := handler.Initialize(logger); err != nil {
+		return nil, fmt.Errorf("error authenticating to Vault: %w", err)
+	}
+
+	return &vaultScaler{
+		metricType: metricType,
+		metadata:   meta,
+		handler:    handler,
+		logger:     logger,
+	}, nil
+}
+
+func parseVaultMetadata(config *ScalerConfig) (*vaultMetadata, error) {
+	meta := vaultMetadata{}
+
+	if val, ok := config.TriggerMetadata["address"]; ok && val != "" {
+		meta.vault.Address = val
+	} else {
+		return nil, fmt.Errorf("no address given")
+	}
+	meta.vault.Namespace = config.TriggerMetadata["namespace"]
+
+	switch authentication := kedav1alpha1.VaultAuthentication(config.TriggerMetadata["authentication"]); authentication {
+	case "", kedav1alpha1.VaultAuthenticationToken:
+		meta.vault.Authentication = kedav1alpha1.VaultAuthenticationToken
+		// the token can also be given with the VAULT_TOKEN environment variable of KEDA
+		meta.vault.Credential = &kedav1alpha1.Credential{Token: config.AuthParams["token"]}
+	case kedav1alpha1.VaultAuthenticationKubernetes:
+		// the credentials only come from the TriggerAuthentication, the token is sent to the address of the trigger
+		meta.vault.Authentication = authentication
+		meta.vault.Role = config.AuthParams["role"]
+		if meta.vault.Role == "" {
+			return nil, fmt.Errorf("no role given, it's required for the %s authentication", authentication)
+		}
+		meta.vault.Mount = config.AuthParams["mount"]
+		if meta.vault.Mount == "" {
+			return nil, fmt.Errorf("no mount given, it's required for the %s authentication", authentication)
+		}
+		meta.serviceAccountToken = config.AuthParams["serviceAccountToken"]
+		if meta.serviceAccountToken == "" {
+			return nil, fmt.Errorf("no serviceAccountToken given, it's required for the %s authentication", authentication)
+		}
+	default:
+		return nil, fmt.Errorf("authentication must be either %s or %s, got %s",
+			kedav1alpha1.VaultAuthenticationToken, kedav1alpha1.VaultAuthenticationKubernetes, authentication)
+	}
+
+	meta.kvMount = defaultVaultKVMount
+	if val, ok := config.TriggerMetadata["kvMount"]; ok && val != "" {
+		meta.kvMount = strings.Trim(val, "/")
+	}
+
+	meta.kvVersion = defaultVaultKVVersion
+	if val, ok := config.TriggerMetadata["kvVersion"]; ok && val != "" {
+		kvVersion, err := strconv.Atoi(val)
+		if err != nil || (kvVersion != 1 && kvVersion != 2) {
+			return nil, fmt.Errorf("kvVersion must be either 1 or 2, got %s", val)
+		}
+		meta.kvVersion = kvVersion
+	}
+
+	meta.path = strings.Trim(config.TriggerMetadata["path"], "/")
+	if meta.path == "" {
+		return nil, fmt.Errorf("no path given")
+	}
+
+	jsonPath, err := parseKubernetesResourcePath(config.TriggerMetadata["jsonPath"])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jsonPath: %w", err)
+	}
+	meta.jsonPath = jsonPath
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error: %w", err)
+		}
+		if targetValue <= 0 {
+			return nil, fmt.Errorf("targetValue must be greater than 0, got %s", val)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	meta.activationTargetValue = 0
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationTargetValue parsing error: %w", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("vault-%s", meta.path)))
+	return &meta, nil
+}
+
+// secretPath returns the path of the secret in the API of the KV secrets engine, the version 2 serves the
+// latest version of the secrets under data/
+func (m *vaultMetadata) secretPath() string {
+	if m.kvVersion == 1 {
+		return fmt.Sprintf("%s/%s", m.kvMount, m.path)
+	}
+	return fmt.Sprintf("%s/data/%s", m.kvMount, m.path)
+}
+
+func (s *vaultScaler) getSecretValue() (float64, error) {
+	secret, err := s.handler.Read(s.metadata.secretPath())
+	if err != nil {
+		return 0, err
+	}
+	if secret == nil {
+		return 0, fmt.Errorf("no secret found at %s", s.metadata.secretPath())
+	}
+
+	data := secret.Data
+	if s.metadata.kvVersion == 2 {
+		// the version 2 wraps the secret along with its metadata
+		var ok bool
+		if data, ok = secret.Data["data"].(map[string]interface{}); !ok {
+			return 0, fmt.Errorf("no data found in the secret at %s, is it deleted?", s.metadata.secretPath())
+		}
+	}
+
+	value, found := lookupKubernetesResourcePath(data, s.metadata.jsonPath)
+	if !found {
+		return 0, fmt.Errorf("no value found at jsonPath in the secret at %s", s.metadata.secretPath())
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("the value at jsonPath in the secret at %s isn't a number: %w", s.metadata.secretPath(), err)
+	}
+	return number, nil
+}
+
+// Close stops the renewal of the Vault token
+func (s *vaultScaler) Close(context.Context) error {
+	s.handler.Stop()
+	return nil
+}
+
+// GetMetricSpecForScaling returns the MetricSpec for the Horizontal Pod Autoscaler
+func (s *vaultScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	externalMetric := &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{
+			Name: s.metadata.metricName,
+		},
+		Target: GetMetricTargetMili(s.metricType, s.metadata.targetValue),
+	}
+	metricSpec := v2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2.MetricSpec{metricSpec}
+}
+
+// GetMetricsAndActivity returns the value of the secret field and whether it's above the activation target value
+func (s *vaultScaler) GetMetricsAndActivity(_ context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	val, err := s.getSecretValue()
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, false, fmt.Errorf("error reading the Vault secret: %w", err)
+	}
+
+	metric := GenerateMetricInMili(metricName, val)
+
+	return []external_metrics.ExternalMetricValue{metric}, val > s.metadata.activationTargetValue, nil
+}
@@ -0,0 +1,222 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseVaultMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type vaultMetricIdentifier struct {
+	metadataTestData *parseVaultMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testVaultMetadata = []parseVaultMetadataTestData{
+	// token authentication with the defaults
+	{map[string]string{"address": "http://vault:8200", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"}, map[string]string{"token": "token"}, false},
+	// kubernetes authentication with kv v1
+	{map[string]string{"address": "http://vault:8200", "authentication": "kubernetes", "kvMount": "kv", "kvVersion": "1", "path": "pki/queue", "jsonPath": "{.stats.pending}", "targetValue": "5", "activationTargetValue": "1"}, map[string]string{"role": "keda", "mount": "kubernetes", "serviceAccountToken": "token"}, false},
+	// no metadata
+	{map[string]string{}, map[string]string{}, true},
+	// missing address
+	{map[string]string{"path": "queues/orders", "jsonPath": "depth", "targetValue": "10"}, map[string]string{}, true},
+	// unknown authentication
+	{map[string]string{"address": "http://vault:8200", "authentication": "aws", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"}, map[string]string{}, true},
+	// kubernetes authentication without role
+	{map[string]string{"address": "http://vault:8200", "authentication": "kubernetes", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"}, map[string]string{"mount": "kubernetes", "serviceAccountToken": "token"}, true},
+	// kubernetes authentication without mount
+	{map[string]string{"address": "http://vault:8200", "authentication": "kubernetes", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"}, map[string]string{"role": "keda", "serviceAccountToken": "token"}, true},
+	// kubernetes authentication without service account token, the token of the operator isn't used
+	{map[string]string{"address": "http://vault:8200", "authentication": "kubernetes", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"}, map[string]string{"role": "keda", "mount": "kubernetes"}, true},
+	// kubernetes authentication with the credentials in the trigger metadata
+	{map[string]string{"address": "http://vault:8200", "authentication": "kubernetes", "role": "keda", "mount": "kubernetes", "serviceAccount": "/var/run/secrets/kubernetes.io/serviceaccount/token", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"}, map[string]string{}, true},
+	// invalid kvVersion
+	{map[string]string{"address": "http://vault:8200", "kvVersion": "3", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"}, map[string]string{}, true},
+	// missing path
+	{map[string]string{"address": "http://vault:8200", "jsonPath": "depth", "targetValue": "10"}, map[string]string{}, true},
+	// missing jsonPath
+	{map[string]string{"address": "http://vault:8200", "path": "queues/orders", "targetValue": "10"}, map[string]string{}, true},
+	// unsupported index in jsonPath
+	{map[string]string{"address": "http://vault:8200", "path": "queues/orders", "jsonPath": "depths[last]", "targetValue": "10"}, map[string]string{}, true},
+	// missing targetValue
+	{map[string]string{"address": "http://vault:8200", "path": "queues/orders", "jsonPath": "depth"}, map[string]string{}, true},
+	// targetValue not greater than 0
+	{map[string]string{"address": "http://vault:8200", "path": "queues/orders", "jsonPath": "depth", "targetValue": "0"}, map[string]string{}, true},
+	// invalid activationTargetValue
+	{map[string]string{"address": "http://vault:8200", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10", "activationTargetValue": "a"}, map[string]string{}, true},
+}
+
+var vaultMetricIdentifiers = []vaultMetricIdentifier{
+	{&testVaultMetadata[0], 0, "s0-vault-queues-orders"},
+	{&testVaultMetadata[1], 1, "s1-vault-pki-queue"},
+}
+
+func TestParseVaultMetadata(t *testing.T) {
+	for i, testData := range testVaultMetadata {
+		_, err := parseVaultMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("test case %d: expected success but got error %v", i, err)
+		}
+		if err == nil && testData.isError {
+			t.Errorf("test case %d: expected error but got success", i)
+		}
+	}
+}
+
+func TestVaultGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range vaultMetricIdentifiers {
+		meta, err := parseVaultMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockVaultScaler := vaultScaler{metadata: meta}
+
+		metricSpec := mockVaultScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+// newTestVaultServer serves the token lookup, the kubernetes login and the secrets of a KV v2 engine mounted on
+// secret/ and of a KV v1 engine mounted on kv/
+func newTestVaultServer(t *testing.T) *httptest.Server {
+	respond := func(w http.ResponseWriter, body map[string]interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(body))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/token/lookup-self", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		respond(w, map[string]interface{}{"data": map[string]interface{}{"renewable": false}})
+	})
+	mux.HandleFunc("/v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var login map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&login))
+		assert.Equal(t, map[string]string{"jwt": "sa-token", "role": "keda"}, login)
+		respond(w, map[string]interface{}{"auth": map[string]interface{}{"client_token": "test-token"}})
+	})
+	mux.HandleFunc("/v1/secret/data/queues/orders", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, map[string]interface{}{"data": map[string]interface{}{
+			"data":     map[string]interface{}{"depth": 42, "stats": map[string]interface{}{"pending": "7.5"}},
+			"metadata": map[string]interface{}{"version": 3},
+		}})
+	})
+	mux.HandleFunc("/v1/secret/data/queues/deleted", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, map[string]interface{}{"data": map[string]interface{}{
+			"data":     nil,
+			"metadata": map[string]interface{}{"version": 2, "deletion_time": "2023-01-01T00:00:00Z"},
+		}})
+	})
+	mux.HandleFunc("/v1/kv/queues/orders", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, map[string]interface{}{"data": map[string]interface{}{"depth": 3, "name": "orders"}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultGetMetricsAndActivity(t *testing.T) {
+	// the token of the environment would take precedence over the one of the trigger
+	t.Setenv("VAULT_TOKEN", "")
+	server := newTestVaultServer(t)
+
+	testCases := []struct {
+		name           string
+		metadata       map[string]string
+		authParams     map[string]string
+		expectedValue  int64
+		expectedActive bool
+		isError        bool
+	}{
+		{
+			name:           "kv v2",
+			metadata:       map[string]string{"path": "queues/orders", "jsonPath": "depth", "targetValue": "10", "activationTargetValue": "50"},
+			authParams:     map[string]string{"token": "test-token"},
+			expectedValue:  42000,
+			expectedActive: false,
+		},
+		{
+			name:           "kv v2 with a nested string value",
+			metadata:       map[string]string{"path": "queues/orders", "jsonPath": "stats.pending", "targetValue": "10"},
+			authParams:     map[string]string{"token": "test-token"},
+			expectedValue:  7500,
+			expectedActive: true,
+		},
+		{
+			name:           "kv v1 with kubernetes authentication",
+			metadata:       map[string]string{"authentication": "kubernetes", "kvMount": "kv", "kvVersion": "1", "path": "/queues/orders", "jsonPath": "depth", "targetValue": "10"},
+			authParams:     map[string]string{"role": "keda", "mount": "kubernetes", "serviceAccountToken": "sa-token"},
+			expectedValue:  3000,
+			expectedActive: true,
+		},
+		{
+			name:       "kv v1 path read as kv v2",
+			metadata:   map[string]string{"kvMount": "kv", "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"},
+			authParams: map[string]string{"token": "test-token"},
+			isError:    true,
+		},
+		{
+			name:       "kv v2 deleted secret",
+			metadata:   map[string]string{"path": "queues/deleted", "jsonPath": "depth", "targetValue": "10"},
+			authParams: map[string]string{"token": "test-token"},
+			isError:    true,
+		},
+		{
+			name:       "missing field",
+			metadata:   map[string]string{"path": "queues/orders", "jsonPath": "size", "targetValue": "10"},
+			authParams: map[string]string{"token": "test-token"},
+			isError:    true,
+		},
+		{
+			name:       "non numeric field",
+			metadata:   map[string]string{"kvMount": "kv", "kvVersion": "1", "path": "queues/orders", "jsonPath": "name", "targetValue": "10"},
+			authParams: map[string]string{"token": "test-token"},
+			isError:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.metadata["address"] = server.URL
+			scaler, err := NewVaultScaler(&ScalerConfig{TriggerMetadata: testCase.metadata, AuthParams: testCase.authParams})
+			assert.NoError(t, err)
+			defer scaler.Close(context.Background())
+
+			metrics, active, err := scaler.GetMetricsAndActivity(context.Background(), "s0-vault-queues-orders")
+			if testCase.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedValue, metrics[0].Value.MilliValue())
+			assert.Equal(t, testCase.expectedActive, active)
+		})
+	}
+}
+
+func TestNewVaultScalerInvalidToken(t *testing.T) {
+	t.Setenv("VAULT_TOKEN", "")
+	server := newTestVaultServer(t)
+
+	_, err := NewVaultScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"address": server.URL, "path": "queues/orders", "jsonPath": "depth", "targetValue": "10"},
+		AuthParams:      map[string]string{"token": "invalid"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+// metricTrend is a ring buffer of the last values of a metric
+type metricTrend struct {
+	values []float64
+	next   int
+	count  int
+}
+
+func newMetricTrend(window int) *metricTrend {
+	return &metricTrend{values: make([]float64, window)}
+}
+
+func (t *metricTrend) add(value float64) {
+	t.values[t.next] = value
+	t.next = (t.next + 1) % len(t.values)
+	if t.count < len(t.values) {
+		t.count++
+	}
+}
+
+// slope returns the least squares slope of the values per poll,
+// the second return value is false until the buffer is full
+func (t *metricTrend) slope() (float64, bool) {
+	if t.count < len(t.values) || t.count < 2 {
+		return 0, false
+	}
+
+	n := float64(t.count)
+	var sumX, sumY, sumXY, sumXX float64
+	for i := 0; i < t.count; i++ {
+		// the oldest value is at t.next once the buffer is full
+		y := t.values[(t.next+i)%len(t.values)]
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX), true
+}
+
+// RecordMetricTrend stores the composite value of the ScaledObject for the scale down trend guard,
+// the buffer is recreated when the window changes
+func (c *ScalersCache) RecordMetricTrend(value float64, window int) {
+	if window < 2 {
+		return
+	}
+
+	c.trendLock.Lock()
+	defer c.trendLock.Unlock()
+
+	if c.trend == nil || len(c.trend.values) != window {
+		c.trend = newMetricTrend(window)
+	}
+	c.trend.add(value)
+}
+
+// IsMetricTrendRising returns true if the slope of the last recorded composite values of the ScaledObject
+// is greater than minSlope, it is false until the whole window has been recorded
+func (c *ScalersCache) IsMetricTrendRising(minSlope float64) bool {
+	c.trendLock.Lock()
+	defer c.trendLock.Unlock()
+
+	if c.trend == nil {
+		return false
+	}
+	slope, ok := c.trend.slope()
+	return ok && slope > minSlope
+}
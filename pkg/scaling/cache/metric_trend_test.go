@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMetricTrendRising(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   []float64
+		minSlope float64
+		rising   bool
+	}{
+		{name: "rising", values: []float64{10, 20, 30}, rising: true},
+		{name: "falling", values: []float64{30, 20, 10}, rising: false},
+		{name: "flat", values: []float64{20, 20, 20}, rising: false},
+		{name: "rising below min slope", values: []float64{10, 11, 12}, minSlope: 5, rising: false},
+		{name: "window not filled", values: []float64{10, 20}, rising: false},
+		// only the last 3 values are kept
+		{name: "falling after rising", values: []float64{10, 20, 30, 25, 20, 15}, rising: false},
+		{name: "rising after falling", values: []float64{30, 20, 10, 12, 14, 16}, rising: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cache := &ScalersCache{}
+			for _, value := range c.values {
+				cache.RecordMetricTrend(value, 3)
+			}
+			assert.Equal(t, c.rising, cache.IsMetricTrendRising(c.minSlope))
+		})
+	}
+}
+
+func TestRecordMetricTrendResetsOnWindowChange(t *testing.T) {
+	cache := &ScalersCache{}
+	cache.RecordMetricTrend(10, 2)
+	cache.RecordMetricTrend(20, 2)
+	assert.True(t, cache.IsMetricTrendRising(0))
+
+	cache.RecordMetricTrend(30, 3)
+	assert.False(t, cache.IsMetricTrendRising(0))
+}
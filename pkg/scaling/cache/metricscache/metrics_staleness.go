@@ -0,0 +1,46 @@
+package metricscache
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type metricValueChange struct {
+	value     resource.Quantity
+	changedAt time.Time
+}
+
+// MetricsStaleness tracks when the value of each metric series of the scaled objects last changed,
+// it's kept across the scalers cache invalidations so a wedged scaler isn't reset by its own errors
+type MetricsStaleness struct {
+	series map[string]map[string]metricValueChange
+	lock   sync.Mutex
+}
+
+// Record stores the value of the series and returns for how long the value hasn't changed
+func (ms *MetricsStaleness) Record(scaledObjectIdentifier, series string, value resource.Quantity, now time.Time) time.Duration {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	if ms.series == nil {
+		ms.series = map[string]map[string]metricValueChange{}
+	}
+	if ms.series[scaledObjectIdentifier] == nil {
+		ms.series[scaledObjectIdentifier] = map[string]metricValueChange{}
+	}
+
+	last, ok := ms.series[scaledObjectIdentifier][series]
+	if !ok || last.value.Cmp(value) != 0 {
+		ms.series[scaledObjectIdentifier][series] = metricValueChange{value: value.DeepCopy(), changedAt: now}
+		return 0
+	}
+	return now.Sub(last.changedAt)
+}
+
+func (ms *MetricsStaleness) Delete(scaledObjectIdentifier string) {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	delete(ms.series, scaledObjectIdentifier)
+}
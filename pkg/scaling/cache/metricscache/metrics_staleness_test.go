@@ -0,0 +1,31 @@
+package metricscache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestMetricsStalenessRecord(t *testing.T) {
+	ms := MetricsStaleness{}
+	start := time.Now()
+
+	assert.Equal(t, time.Duration(0), ms.Record("so-a", "0/metric", resource.MustParse("5"), start))
+	assert.Equal(t, 10*time.Minute, ms.Record("so-a", "0/metric", resource.MustParse("5"), start.Add(10*time.Minute)))
+	// the same value in another format isn't a change
+	assert.Equal(t, 20*time.Minute, ms.Record("so-a", "0/metric", resource.MustParse("5000m"), start.Add(20*time.Minute)))
+
+	// the series are tracked independently
+	assert.Equal(t, time.Duration(0), ms.Record("so-a", "1/metric", resource.MustParse("5"), start.Add(20*time.Minute)))
+	assert.Equal(t, time.Duration(0), ms.Record("so-b", "0/metric", resource.MustParse("5"), start.Add(20*time.Minute)))
+
+	// a changed value resets the age
+	assert.Equal(t, time.Duration(0), ms.Record("so-a", "0/metric", resource.MustParse("6"), start.Add(30*time.Minute)))
+	assert.Equal(t, 5*time.Minute, ms.Record("so-a", "0/metric", resource.MustParse("6"), start.Add(35*time.Minute)))
+
+	ms.Delete("so-a")
+	assert.Equal(t, time.Duration(0), ms.Record("so-a", "0/metric", resource.MustParse("6"), start.Add(40*time.Minute)))
+	assert.Equal(t, 20*time.Minute, ms.Record("so-b", "0/metric", resource.MustParse("5"), start.Add(40*time.Minute)))
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+// queryLimiter bounds the number of scaler queries running at the same time across all the scalable objects
+type queryLimiter struct {
+	// slots is nil if there is no limit
+	slots chan struct{}
+}
+
+var (
+	globalQueryLimiter     = &queryLimiter{}
+	globalQueryLimiterLock sync.RWMutex
+)
+
+// SetMaxConcurrentQueries sets the maximum number of scaler queries running at the same time, 0 removes the limit
+func SetMaxConcurrentQueries(limit int) error {
+	if limit < 0 {
+		return fmt.Errorf("the maximum number of concurrent scaler queries can't be negative, %d given", limit)
+	}
+
+	limiter := &queryLimiter{}
+	if limit > 0 {
+		limiter.slots = make(chan struct{}, limit)
+	}
+
+	globalQueryLimiterLock.Lock()
+	defer globalQueryLimiterLock.Unlock()
+	globalQueryLimiter = limiter
+	prommetrics.RecordScalerQueryConcurrencyLimit(limit)
+	return nil
+}
+
+type scaleLoopContextKey struct{}
+
+// ContextWithScaleLoop returns a copy of the context marking the queries made by a scale loop, the loops waiting for
+// a query slot are counted as backing off
+func ContextWithScaleLoop(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scaleLoopContextKey{}, true)
+}
+
+func isScaleLoop(ctx context.Context) bool {
+	scaleLoop, _ := ctx.Value(scaleLoopContextKey{}).(bool)
+	return scaleLoop
+}
+
+// acquireQuerySlot waits until a scaler query can run, the returned function has to be called when the query is done
+func acquireQuerySlot(ctx context.Context) (func(), error) {
+	globalQueryLimiterLock.RLock()
+	limiter := globalQueryLimiter
+	globalQueryLimiterLock.RUnlock()
+	return limiter.acquire(ctx)
+}
+
+func (l *queryLimiter) acquire(ctx context.Context) (func(), error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			if err := l.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	prommetrics.RecordScalerQueryStart()
+	return func() {
+		prommetrics.RecordScalerQueryEnd()
+		if l.slots != nil {
+			<-l.slots
+		}
+	}, nil
+}
+
+// wait blocks until a slot is free
+func (l *queryLimiter) wait(ctx context.Context) error {
+	if isScaleLoop(ctx) {
+		prommetrics.RecordScaleLoopBackoffStart()
+		defer prommetrics.RecordScaleLoopBackoffEnd()
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting for a scaler query slot: %w", ctx.Err())
+	}
+}
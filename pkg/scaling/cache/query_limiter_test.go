@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestQueryConcurrencyLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	assert.NoError(t, SetMaxConcurrentQueries(2))
+	defer func() {
+		assert.NoError(t, SetMaxConcurrentQueries(0))
+	}()
+
+	started := make(chan struct{}, 4)
+	unblock := make(chan struct{})
+	cache := ScalersCache{}
+	for i := 0; i < 4; i++ {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), "metric").DoAndReturn(
+			func(context.Context, string) ([]external_metrics.ExternalMetricValue, bool, error) {
+				started <- struct{}{}
+				<-unblock
+				return []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili("metric", 1)}, true, nil
+			})
+		cache.Scalers = append(cache.Scalers, ScalerBuilder{Scaler: scaler})
+	}
+
+	var wg sync.WaitGroup
+	for i := range cache.Scalers {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			_, _, _, err := cache.GetMetricsAndActivityForScaler(context.Background(), index, "metric")
+			assert.NoError(t, err)
+		}(i)
+	}
+
+	// only 2 of the 4 queries run until one of them is done
+	<-started
+	<-started
+	select {
+	case <-started:
+		t.Fatal("more queries than the limit are running")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, float64(2), getGaugeValue(t, "keda_scaler_query_concurrency_limit"))
+	assert.Equal(t, float64(2), getGaugeValue(t, "keda_scaler_query_concurrency_active"))
+
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, float64(0), getGaugeValue(t, "keda_scaler_query_concurrency_active"))
+}
+
+func TestQueryConcurrencyLimitCanceled(t *testing.T) {
+	assert.NoError(t, SetMaxConcurrentQueries(1))
+	defer func() {
+		assert.NoError(t, SetMaxConcurrentQueries(0))
+	}()
+
+	release, err := acquireQuerySlot(context.Background())
+	assert.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = acquireQuerySlot(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, float64(1), getGaugeValue(t, "keda_scaler_query_concurrency_active"))
+}
+
+func TestScaleLoopsBackingOff(t *testing.T) {
+	assert.NoError(t, SetMaxConcurrentQueries(1))
+	defer func() {
+		assert.NoError(t, SetMaxConcurrentQueries(0))
+	}()
+
+	release, err := acquireQuerySlot(ContextWithScaleLoop(context.Background()))
+	assert.NoError(t, err)
+	// the loop getting a free slot doesn't back off
+	assert.Equal(t, float64(0), getGaugeValue(t, "keda_internal_scale_loops_backing_off"))
+
+	acquired := make(chan func(), 2)
+	for _, ctx := range []context.Context{ContextWithScaleLoop(context.Background()), context.Background()} {
+		go func(ctx context.Context) {
+			release, err := acquireQuerySlot(ctx)
+			assert.NoError(t, err)
+			acquired <- release
+		}(ctx)
+	}
+	canceledCtx, cancel := context.WithCancel(ContextWithScaleLoop(context.Background()))
+	canceled := make(chan error)
+	go func() {
+		_, err := acquireQuerySlot(canceledCtx)
+		canceled <- err
+	}()
+
+	// the queries which aren't made by a scale loop aren't counted
+	assert.Eventually(t, func() bool {
+		return getGaugeValue(t, "keda_internal_scale_loops_backing_off") == 2
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+	assert.Equal(t, float64(1), getGaugeValue(t, "keda_internal_scale_loops_backing_off"))
+
+	release()
+	(<-acquired)()
+	(<-acquired)()
+	assert.Equal(t, float64(0), getGaugeValue(t, "keda_internal_scale_loops_backing_off"))
+}
+
+func TestSetMaxConcurrentQueriesNegative(t *testing.T) {
+	assert.Error(t, SetMaxConcurrentQueries(-1))
+}
+
+func getGaugeValue(t *testing.T, name string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
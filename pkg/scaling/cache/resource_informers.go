@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	k8scache "k8s.io/client-go/tools/cache"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// ResourceInformers are the informers shared by the kubernetes-resource triggers counting the objects of the same
+// kind in the same namespace. An informer is started by its first trigger and stopped when its last one is closed.
+type ResourceInformers struct {
+	mapper       meta.RESTMapper
+	newListWatch func(gvr schema.GroupVersionResource, namespace string) k8scache.ListerWatcher
+
+	lock      sync.Mutex
+	informers map[resourceInformerKey]*resourceInformer
+}
+
+type resourceInformerKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+}
+
+type resourceInformer struct {
+	informer k8scache.SharedIndexInformer
+	stop     chan struct{}
+	refs     int
+}
+
+var (
+	globalResourceInformers     = &ResourceInformers{}
+	globalResourceInformersLock sync.RWMutex
+)
+
+// SetResourceInformersClient sets the clients the informers of the kubernetes-resource triggers are created with
+func SetResourceInformersClient(client dynamic.Interface, mapper meta.RESTMapper) {
+	globalResourceInformersLock.Lock()
+	defer globalResourceInformersLock.Unlock()
+	globalResourceInformers = newResourceInformers(mapper, func(gvr schema.GroupVersionResource, namespace string) k8scache.ListerWatcher {
+		resource := client.Resource(gvr).Namespace(namespace)
+		return &k8scache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return resource.List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return resource.Watch(context.Background(), options)
+			},
+		}
+	})
+}
+
+// GetResourceInformers returns the informers shared by the kubernetes-resource triggers
+func GetResourceInformers() *ResourceInformers {
+	globalResourceInformersLock.RLock()
+	defer globalResourceInformersLock.RUnlock()
+	return globalResourceInformers
+}
+
+func newResourceInformers(mapper meta.RESTMapper, newListWatch func(gvr schema.GroupVersionResource, namespace string) k8scache.ListerWatcher) *ResourceInformers {
+	return &ResourceInformers{
+		mapper:       mapper,
+		newListWatch: newListWatch,
+		informers:    map[resourceInformerKey]*resourceInformer{},
+	}
+}
+
+// Acquire returns the store of the informer of the kind in the namespace, the informer is started if it isn't running yet
+func (r *ResourceInformers) Acquire(gvk schema.GroupVersionKind, namespace string) (scalers.KubernetesResourceStore, func(), error) {
+	if r.newListWatch == nil {
+		return nil, nil, fmt.Errorf("the kubernetes-resource triggers aren't supported by this component")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := resourceInformerKey{gvk: gvk, namespace: namespace}
+	entry, found := r.informers[key]
+	if !found {
+		mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, nil, err
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return nil, nil, fmt.Errorf("%s isn't namespaced", gvk)
+		}
+
+		entry = &resourceInformer{
+			informer: k8scache.NewSharedIndexInformer(r.newListWatch(mapping.Resource, namespace), &unstructured.Unstructured{}, 0, k8scache.Indexers{}),
+			stop:     make(chan struct{}),
+		}
+		go entry.informer.Run(entry.stop)
+		r.informers[key] = entry
+	}
+	entry.refs++
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			r.release(key, entry)
+		})
+	}
+	return entry, release, nil
+}
+
+func (r *ResourceInformers) release(key resourceInformerKey, entry *resourceInformer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	entry.refs--
+	if entry.refs > 0 {
+		return
+	}
+	close(entry.stop)
+	if r.informers[key] == entry {
+		delete(r.informers, key)
+	}
+}
+
+// HasSynced returns whether the initial list of the informer is in its store
+func (e *resourceInformer) HasSynced() bool {
+	return e.informer.HasSynced()
+}
+
+// List returns the objects of the store of the informer
+func (e *resourceInformer) List() []interface{} {
+	return e.informer.GetStore().List()
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	k8scache "k8s.io/client-go/tools/cache"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+var testWorkItemGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "WorkItem"}
+
+// fakeResourceListWatches serves the informers of the tests, the watchers of each namespace are kept to send them events
+type fakeResourceListWatches struct {
+	lock     sync.Mutex
+	objects  map[string][]unstructured.Unstructured
+	lists    map[string]int
+	watchers map[string]*watch.FakeWatcher
+}
+
+func newFakeResourceListWatches(objects ...unstructured.Unstructured) *fakeResourceListWatches {
+	lws := &fakeResourceListWatches{
+		objects:  map[string][]unstructured.Unstructured{},
+		lists:    map[string]int{},
+		watchers: map[string]*watch.FakeWatcher{},
+	}
+	for _, object := range objects {
+		lws.objects[object.GetNamespace()] = append(lws.objects[object.GetNamespace()], object)
+	}
+	return lws
+}
+
+func (f *fakeResourceListWatches) newListWatch(_ schema.GroupVersionResource, namespace string) k8scache.ListerWatcher {
+	return &k8scache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			f.lock.Lock()
+			defer f.lock.Unlock()
+			f.lists[namespace]++
+			list := &unstructured.UnstructuredList{Items: f.objects[namespace]}
+			list.SetResourceVersion("1")
+			return list, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			f.lock.Lock()
+			defer f.lock.Unlock()
+			watcher := watch.NewFake()
+			f.watchers[namespace] = watcher
+			return watcher, nil
+		},
+	}
+}
+
+func (f *fakeResourceListWatches) getLists(namespace string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.lists[namespace]
+}
+
+func (f *fakeResourceListWatches) getWatcher(t *testing.T, namespace string) *watch.FakeWatcher {
+	var watcher *watch.FakeWatcher
+	assert.Eventually(t, func() bool {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		watcher = f.watchers[namespace]
+		return watcher != nil
+	}, time.Second, 10*time.Millisecond)
+	return watcher
+}
+
+func newTestResourceInformers(lws *fakeResourceListWatches) *ResourceInformers {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{testWorkItemGVK.GroupVersion()})
+	mapper.Add(testWorkItemGVK, meta.RESTScopeNamespace)
+	mapper.Add(testWorkItemGVK.GroupVersion().WithKind("Tenant"), meta.RESTScopeRoot)
+	return newResourceInformers(mapper, lws.newListWatch)
+}
+
+func newTestWorkItem(namespace, name, phase string) *unstructured.Unstructured {
+	item := &unstructured.Unstructured{}
+	item.SetGroupVersionKind(testWorkItemGVK)
+	item.SetNamespace(namespace)
+	item.SetName(name)
+	item.SetResourceVersion("1")
+	_ = unstructured.SetNestedField(item.Object, phase, "status", "phase")
+	return item
+}
+
+func TestResourceInformersLifecycle(t *testing.T) {
+	lws := newFakeResourceListWatches()
+	informers := newTestResourceInformers(lws)
+
+	first, releaseFirst, err := informers.Acquire(testWorkItemGVK, "default")
+	assert.NoError(t, err)
+	second, releaseSecond, err := informers.Acquire(testWorkItemGVK, "default")
+	assert.NoError(t, err)
+	_, releaseOther, err := informers.Acquire(testWorkItemGVK, "other")
+	assert.NoError(t, err)
+
+	// the triggers of the same kind in the same namespace share the informer
+	assert.Same(t, first, second)
+	assert.Len(t, informers.informers, 2)
+	assert.Eventually(t, first.HasSynced, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, lws.getLists("default"))
+
+	entry := informers.informers[resourceInformerKey{gvk: testWorkItemGVK, namespace: "default"}]
+	releaseFirst()
+	// releasing twice doesn't release the informer of the other trigger
+	releaseFirst()
+	assert.Equal(t, 1, entry.refs)
+	assert.Len(t, informers.informers, 2)
+
+	releaseSecond()
+	assert.Len(t, informers.informers, 1)
+	select {
+	case <-entry.stop:
+	default:
+		t.Error("the informer without triggers isn't stopped")
+	}
+
+	// the next trigger starts a new informer
+	third, releaseThird, err := informers.Acquire(testWorkItemGVK, "default")
+	assert.NoError(t, err)
+	assert.NotSame(t, first, third)
+	assert.Eventually(t, third.HasSynced, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, lws.getLists("default"))
+
+	releaseThird()
+	releaseOther()
+	assert.Empty(t, informers.informers)
+}
+
+func TestResourceInformersErrors(t *testing.T) {
+	informers := newTestResourceInformers(newFakeResourceListWatches())
+
+	_, _, err := informers.Acquire(testWorkItemGVK.GroupVersion().WithKind("Unknown"), "default")
+	assert.Error(t, err)
+	_, _, err = informers.Acquire(testWorkItemGVK.GroupVersion().WithKind("Tenant"), "default")
+	assert.Error(t, err)
+	assert.Empty(t, informers.informers)
+
+	// the components without a dynamic client don't support the trigger
+	_, _, err = (&ResourceInformers{}).Acquire(testWorkItemGVK, "default")
+	assert.Error(t, err)
+}
+
+func TestResourceInformersCountDuringChurn(t *testing.T) {
+	lws := newFakeResourceListWatches(
+		*newTestWorkItem("default", "item-0", "Pending"),
+		*newTestWorkItem("default", "item-1", "Running"),
+		*newTestWorkItem("other", "item-2", "Pending"),
+	)
+	informers := newTestResourceInformers(lws)
+
+	scaler, err := scalers.NewKubernetesResourceScaler(informers, &scalers.ScalerConfig{
+		ScalableObjectNamespace: "default",
+		TriggerMetadata: map[string]string{
+			"apiVersion":    "example.com/v1",
+			"kind":          "WorkItem",
+			"fieldSelector": "status.phase=Pending",
+			"value":         "1",
+		},
+	})
+	assert.NoError(t, err)
+	defer scaler.Close(context.Background())
+
+	assertCount := func(expected int64) {
+		t.Helper()
+		assert.Eventually(t, func() bool {
+			metrics, _, err := scaler.GetMetricsAndActivity(context.Background(), "s0-resource-workitem")
+			return err == nil && metrics[0].Value.Value() == expected
+		}, time.Second, 10*time.Millisecond)
+	}
+	assertCount(1)
+
+	watcher := lws.getWatcher(t, "default")
+	for i := 3; i < 13; i++ {
+		watcher.Add(newTestWorkItem("default", fmt.Sprintf("item-%d", i), "Pending"))
+	}
+	assertCount(11)
+
+	// the objects leaving the phase, deleted or added back are counted as they change
+	for i := 3; i < 8; i++ {
+		item := newTestWorkItem("default", fmt.Sprintf("item-%d", i), "Running")
+		item.SetResourceVersion("2")
+		watcher.Modify(item)
+	}
+	watcher.Delete(newTestWorkItem("default", "item-0", "Pending"))
+	watcher.Modify(newTestWorkItem("default", "item-1", "Pending"))
+	assertCount(6)
+	assert.Equal(t, 1, lws.getLists("default"))
+}
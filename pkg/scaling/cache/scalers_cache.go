@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	v2 "k8s.io/api/autoscaling/v2"
@@ -31,6 +33,7 @@ import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/scalers"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 var log = logf.Log.WithName("scalers_cache")
@@ -40,6 +43,23 @@ type ScalersCache struct {
 	Scalers                  []ScalerBuilder
 	ScalableObjectGeneration int64
 	Recorder                 record.EventRecorder
+	// PollingInterval of the scalable object, the results of its triggers are only shared if it is longer than their TTL
+	PollingInterval time.Duration
+	// FailedTriggers are the triggers left out of the cache because their scaler couldn't be built, only set when
+	// partial HPAs are allowed
+	FailedTriggers []FailedTrigger
+	// RootCAsGeneration is the generation of the root CAs the scalers were built with, they're rebuilt once the
+	// CA cert dirs are reloaded
+	RootCAsGeneration uint64
+
+	// trend keeps the last composite values of the ScaledObject for the scale down trend guard
+	trend     *metricTrend
+	trendLock sync.Mutex
+
+	// polls keeps the last results of the triggers with their own pollingInterval, the cache is recreated on
+	// every scaler error so all the triggers are queried again then
+	polls     map[string]triggerPoll
+	pollsLock sync.Mutex
 }
 
 type ScalerBuilder struct {
@@ -48,6 +68,14 @@ type ScalerBuilder struct {
 	Factory      func() (scalers.Scaler, *scalers.ScalerConfig, error)
 }
 
+// FailedTrigger is a trigger of the scalable object whose scaler couldn't be built
+type FailedTrigger struct {
+	Index int
+	Name  string
+	Type  string
+	Err   error
+}
+
 // GetScalers returns array of scalers and scaler config stored in the cache
 func (c *ScalersCache) GetScalers() ([]scalers.Scaler, []scalers.ScalerConfig) {
 	scalersList := make([]scalers.Scaler, 0, len(c.Scalers))
@@ -126,8 +154,15 @@ func (c *ScalersCache) GetMetricsAndActivityForScaler(ctx context.Context, index
 	if index < 0 || index >= len(c.Scalers) {
 		return nil, false, -1, fmt.Errorf("scaler with id %d not found. Len = %d", index, len(c.Scalers))
 	}
+	ctx = kedautil.ContextWithTriggerType(ctx, c.Scalers[index].ScalerConfig.TriggerType)
+	release, err := acquireQuerySlot(ctx)
+	if err != nil {
+		return nil, false, -1, err
+	}
+	defer release()
+
 	startTime := time.Now()
-	metric, activity, err := c.Scalers[index].Scaler.GetMetricsAndActivity(ctx, metricName)
+	metric, activity, err := c.getSharedMetricsAndActivity(ctx, index, metricName)
 	if err == nil {
 		return metric, activity, time.Since(startTime).Milliseconds(), nil
 	}
@@ -141,12 +176,34 @@ func (c *ScalersCache) GetMetricsAndActivityForScaler(ctx context.Context, index
 	return metric, activity, time.Since(startTime).Milliseconds(), err
 }
 
+// getSharedMetricsAndActivity queries the scaler, the result is shared with the identical triggers of the other
+// ScaledObjects if the sharing is enabled and the TTL is shorter than the polling interval
+func (c *ScalersCache) getSharedMetricsAndActivity(ctx context.Context, index int, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	scaler := c.Scalers[index].Scaler
+	shared := getSharedMetricsCache()
+	if shared.ttl == 0 || c.ScaledObject == nil || shared.ttl >= c.PollingInterval {
+		return scaler.GetMetricsAndActivity(ctx, metricName)
+	}
+
+	key := getSharedMetricsKey(c.Scalers[index].ScalerConfig)
+	metric, activity, err := shared.get(ctx, key, metricName, func() ([]external_metrics.ExternalMetricValue, bool, error) {
+		return scaler.GetMetricsAndActivity(ctx, metricName)
+	})
+	if err != nil {
+		// the scaler is rebuilt after an error, e.g. with changed authentication parameters
+		shared.delete(key)
+	}
+	return metric, activity, err
+}
+
 // TODO needs refactor - move ScaledJob related methods to scale_handler, the similar way ScaledObject methods are
 // refactor logic
-func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) (bool, int64, int64) {
+func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) (bool, int64, int64, int) {
 	var queueLength float64
 	var maxValue float64
 	isActive := false
+	// triggerIndex is the index of the trigger which caused the scale-up, -1 if there isn't any
+	triggerIndex := -1
 
 	logger := logf.Log.WithName("scalemetrics")
 	scalersMetrics := c.getScaledJobMetrics(ctx, scaledJob)
@@ -157,6 +214,7 @@ func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1a
 				queueLength = metrics.queueLength
 				maxValue = metrics.maxValue
 				isActive = metrics.isActive
+				triggerIndex = metrics.triggerIndex
 			}
 		}
 	case "avg":
@@ -175,6 +233,7 @@ func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1a
 			queueLength = queueLengthSum / float64(length)
 			maxValue = maxValueSum / float64(length)
 		}
+		triggerIndex = getLargestActiveTriggerIndex(scalersMetrics)
 	case "sum":
 		for _, metrics := range scalersMetrics {
 			if metrics.isActive {
@@ -183,12 +242,14 @@ func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1a
 				isActive = metrics.isActive
 			}
 		}
+		triggerIndex = getLargestActiveTriggerIndex(scalersMetrics)
 	default: // max
 		for _, metrics := range scalersMetrics {
 			if metrics.queueLength > queueLength && metrics.isActive {
 				queueLength = metrics.queueLength
 				maxValue = metrics.maxValue
 				isActive = metrics.isActive
+				triggerIndex = metrics.triggerIndex
 			}
 		}
 	}
@@ -198,9 +259,23 @@ func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1a
 	}
 
 	maxValue = min(float64(scaledJob.MaxReplicaCount()), maxValue)
-	logger.V(1).WithValues("ScaledJob", scaledJob.Name).Info("Checking if ScaleJob Scalers are active", "isActive", isActive, "maxValue", maxValue, "MultipleScalersCalculation", scaledJob.Spec.ScalingStrategy.MultipleScalersCalculation)
+	logger.V(1).WithValues("ScaledJob", scaledJob.Name).Info("Checking if ScaleJob Scalers are active", "isActive", isActive, "maxValue", maxValue, "MultipleScalersCalculation", scaledJob.Spec.ScalingStrategy.MultipleScalersCalculation, "triggerIndex", triggerIndex)
+
+	return isActive, ceilToInt64(queueLength), ceilToInt64(maxValue), triggerIndex
+}
 
-	return isActive, ceilToInt64(queueLength), ceilToInt64(maxValue)
+// getLargestActiveTriggerIndex returns the index of the active trigger with the largest queue length,
+// it is the trigger which contributes the most when the metrics of the triggers are combined
+func getLargestActiveTriggerIndex(scalersMetrics []scalerMetrics) int {
+	triggerIndex := -1
+	var queueLength float64
+	for _, metrics := range scalersMetrics {
+		if metrics.isActive && (triggerIndex == -1 || metrics.queueLength > queueLength) {
+			queueLength = metrics.queueLength
+			triggerIndex = metrics.triggerIndex
+		}
+	}
+	return triggerIndex
 }
 
 func (c *ScalersCache) refreshScaler(ctx context.Context, id int) (scalers.Scaler, error) {
@@ -228,9 +303,10 @@ func (c *ScalersCache) refreshScaler(ctx context.Context, id int) (scalers.Scale
 }
 
 type scalerMetrics struct {
-	queueLength float64
-	maxValue    float64
-	isActive    bool
+	queueLength  float64
+	maxValue     float64
+	isActive     bool
+	triggerIndex int
 }
 
 func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) []scalerMetrics {
@@ -255,14 +331,8 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 
 		// TODO here we should probably loop through all metrics in a Scaler
 		// as it is done for ScaledObject
-		metrics, isTriggerActive, err := s.Scaler.GetMetricsAndActivity(ctx, metricSpecs[0].External.Metric.Name)
-		if err != nil {
-			var ns scalers.Scaler
-			ns, err = c.refreshScaler(ctx, i)
-			if err == nil {
-				metrics, isTriggerActive, err = ns.GetMetricsAndActivity(ctx, metricSpecs[0].External.Metric.Name)
-			}
-		}
+		scalerCtx := kedautil.ContextWithTriggerType(ctx, s.ScalerConfig.TriggerType)
+		metrics, isTriggerActive, err := c.getScaledJobScalerMetrics(scalerCtx, i, metricSpecs[0].External.Metric.Name)
 
 		if err != nil {
 			scalerLogger.V(1).Info("Error getting scaler metrics and activity, but continue", "error", err)
@@ -281,6 +351,15 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 		}
 		scalerLogger.V(1).Info("Scaler Metric value", "isTriggerActive", isTriggerActive, metricSpecs[0].External.Metric.Name, queueLength, "targetAverageValue", targetAverageValue)
 
+		weight, err := GetTriggerWeight(s.ScalerConfig.TriggerMetadata)
+		if err != nil {
+			scalerLogger.Error(err, "Error getting trigger weight, skipping the trigger")
+			c.Recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+			continue
+		}
+		queueLength *= weight
+		scalerLogger.V(1).Info("Scaler weighted demand", "weight", weight, "weightedQueueLength", queueLength)
+
 		if isTriggerActive {
 			isActive = true
 		}
@@ -290,14 +369,51 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 			maxValue = min(float64(scaledJob.MaxReplicaCount()), averageLength)
 		}
 		scalersMetrics = append(scalersMetrics, scalerMetrics{
-			queueLength: queueLength,
-			maxValue:    maxValue,
-			isActive:    isActive,
+			queueLength:  queueLength,
+			maxValue:     maxValue,
+			isActive:     isActive,
+			triggerIndex: s.ScalerConfig.ScalerIndex,
 		})
 	}
 	return scalersMetrics
 }
 
+// getScaledJobScalerMetrics queries the scaler, it's refreshed and queried again if the query fails
+func (c *ScalersCache) getScaledJobScalerMetrics(ctx context.Context, index int, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	release, err := acquireQuerySlot(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer release()
+
+	metrics, isTriggerActive, err := c.Scalers[index].Scaler.GetMetricsAndActivity(ctx, metricName)
+	if err != nil {
+		var ns scalers.Scaler
+		ns, err = c.refreshScaler(ctx, index)
+		if err == nil {
+			metrics, isTriggerActive, err = ns.GetMetricsAndActivity(ctx, metricName)
+		}
+	}
+	return metrics, isTriggerActive, err
+}
+
+// GetTriggerWeight parses the optional weight of a ScaledJob trigger, which is applied to its queue length
+// before the multipleScalersCalculation, the weight defaults to 1
+func GetTriggerWeight(metadata map[string]string) (float64, error) {
+	val, ok := metadata["weight"]
+	if !ok || val == "" {
+		return 1, nil
+	}
+	weight, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing weight: %w", err)
+	}
+	if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return 0, fmt.Errorf("weight must be a non-negative number, got %s", val)
+	}
+	return weight, nil
+}
+
 func getTargetAverageValue(metricSpecs []v2.MetricSpec) float64 {
 	var targetAverageValue float64
 	var metricValue float64
@@ -82,7 +82,7 @@ func TestIsScaledJobActive(t *testing.T) {
 		Recorder: recorder,
 	}
 
-	isActive, queueLength, maxValue := cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
+	isActive, queueLength, maxValue, _ := cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
 	assert.Equal(t, true, isActive)
 	assert.Equal(t, int64(20), queueLength)
 	assert.Equal(t, int64(10), maxValue)
@@ -101,7 +101,7 @@ func TestIsScaledJobActive(t *testing.T) {
 		Recorder: recorder,
 	}
 
-	isActive, queueLength, maxValue = cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
+	isActive, queueLength, maxValue, _ = cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
 	assert.Equal(t, false, isActive)
 	assert.Equal(t, int64(0), queueLength)
 	assert.Equal(t, int64(0), maxValue)
@@ -145,7 +145,7 @@ func TestIsScaledJobActive(t *testing.T) {
 			Recorder: recorder,
 		}
 		fmt.Printf("index: %d", index)
-		isActive, queueLength, maxValue = cache.IsScaledJobActive(context.TODO(), scaledJob)
+		isActive, queueLength, maxValue, _ = cache.IsScaledJobActive(context.TODO(), scaledJob)
 		//	assert.Equal(t, 5, index)
 		assert.Equal(t, scalerTestData.ResultIsActive, isActive)
 		assert.Equal(t, scalerTestData.ResultQueueLength, queueLength)
@@ -173,13 +173,141 @@ func TestIsScaledJobActiveIfQueueEmptyButMinReplicaCountGreaterZero(t *testing.T
 		Recorder: recorder,
 	}
 
-	isActive, queueLength, maxValue := cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
+	isActive, queueLength, maxValue, _ := cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
 	assert.Equal(t, true, isActive)
 	assert.Equal(t, int64(0), queueLength)
 	assert.Equal(t, int64(0), maxValue)
 	cache.Close(context.Background())
 }
 
+func TestIsScaledJobActiveWithTriggerWeights(t *testing.T) {
+	metricName := "s0-queueLength"
+	ctrl := gomock.NewController(t)
+	recorder := record.NewFakeRecorder(10)
+
+	type weightedTrigger struct {
+		queueLength  int64
+		averageValue int64
+		weight       string
+	}
+	testData := []struct {
+		name                       string
+		multipleScalersCalculation string
+		triggers                   []weightedTrigger
+		resultQueueLength          int64
+		resultMaxValue             int64
+		resultTriggerIndex         int
+	}{
+		{
+			name:                       "sum with default weights",
+			multipleScalersCalculation: "sum",
+			triggers:                   []weightedTrigger{{20, 2, ""}, {10, 1, ""}},
+			resultQueueLength:          30,
+			resultMaxValue:             20,
+			resultTriggerIndex:         0,
+		},
+		{
+			name:                       "sum with weights",
+			multipleScalersCalculation: "sum",
+			triggers:                   []weightedTrigger{{20, 2, "0.5"}, {10, 1, "2"}},
+			resultQueueLength:          30,
+			resultMaxValue:             25,
+			resultTriggerIndex:         1,
+		},
+		{
+			name:                       "sum with zero weight",
+			multipleScalersCalculation: "sum",
+			triggers:                   []weightedTrigger{{20, 2, "0"}, {10, 1, "1"}},
+			resultQueueLength:          10,
+			resultMaxValue:             10,
+			resultTriggerIndex:         1,
+		},
+		{
+			name:                       "max with default weights",
+			multipleScalersCalculation: "max",
+			triggers:                   []weightedTrigger{{20, 2, ""}, {10, 1, ""}},
+			resultQueueLength:          20,
+			resultMaxValue:             10,
+			resultTriggerIndex:         0,
+		},
+		{
+			name:                       "max with weights changing the winner",
+			multipleScalersCalculation: "max",
+			triggers:                   []weightedTrigger{{20, 2, "0.25"}, {10, 1, "3"}},
+			resultQueueLength:          30,
+			resultMaxValue:             30,
+			resultTriggerIndex:         1,
+		},
+		{
+			name:                       "max with weight capped by maxReplicaCount",
+			multipleScalersCalculation: "max",
+			triggers:                   []weightedTrigger{{20, 2, "100"}, {10, 1, "1"}},
+			resultQueueLength:          2000,
+			resultMaxValue:             100,
+			resultTriggerIndex:         0,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			scaledJob := createScaledJob(0, 100, data.multipleScalersCalculation)
+			var scalersToTest []ScalerBuilder
+			for index, trigger := range data.triggers {
+				trigger := trigger
+				config := scalers.ScalerConfig{TriggerMetadata: map[string]string{}, ScalerIndex: index}
+				if trigger.weight != "" {
+					config.TriggerMetadata["weight"] = trigger.weight
+				}
+				scalersToTest = append(scalersToTest, ScalerBuilder{
+					Scaler:       createScaler(ctrl, trigger.queueLength, trigger.averageValue, true, metricName),
+					ScalerConfig: config,
+					Factory: func() (scalers.Scaler, *scalers.ScalerConfig, error) {
+						return createScaler(ctrl, trigger.queueLength, trigger.averageValue, true, metricName), &config, nil
+					},
+				})
+			}
+
+			cache := ScalersCache{
+				Scalers:  scalersToTest,
+				Recorder: recorder,
+			}
+
+			isActive, queueLength, maxValue, triggerIndex := cache.IsScaledJobActive(context.TODO(), scaledJob)
+			assert.Equal(t, true, isActive)
+			assert.Equal(t, data.resultQueueLength, queueLength)
+			assert.Equal(t, data.resultMaxValue, maxValue)
+			assert.Equal(t, data.resultTriggerIndex, triggerIndex)
+			cache.Close(context.Background())
+		})
+	}
+}
+
+func TestGetTriggerWeight(t *testing.T) {
+	testData := []struct {
+		metadata map[string]string
+		weight   float64
+		isError  bool
+	}{
+		{metadata: map[string]string{}, weight: 1},
+		{metadata: map[string]string{"weight": ""}, weight: 1},
+		{metadata: map[string]string{"weight": "0"}, weight: 0},
+		{metadata: map[string]string{"weight": "2.5"}, weight: 2.5},
+		{metadata: map[string]string{"weight": "-1"}, isError: true},
+		{metadata: map[string]string{"weight": "NaN"}, isError: true},
+		{metadata: map[string]string{"weight": "heavy"}, isError: true},
+	}
+
+	for _, data := range testData {
+		weight, err := GetTriggerWeight(data.metadata)
+		if data.isError {
+			assert.Error(t, err, "metadata %v", data.metadata)
+			continue
+		}
+		assert.NoError(t, err, "metadata %v", data.metadata)
+		assert.Equal(t, data.weight, weight)
+	}
+}
+
 func newScalerTestData(
 	metricName string,
 	maxReplicaCount int,
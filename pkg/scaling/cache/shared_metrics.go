@@ -0,0 +1,210 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// objectScopedTriggerTypes are the triggers whose metric values depend on the scalable object they belong to,
+// their identical triggers are only shared within the same scalable object
+var objectScopedTriggerTypes = map[string]bool{
+	"kubernetes-workload": true,
+	"pvc-usage":           true,
+	"external":            true,
+	"external-push":       true,
+	"kubernetes-resource": true,
+}
+
+// sharedMetricsCache shares the results of identical triggers across the scalable objects for a short TTL,
+// so the identical triggers polled within the TTL make a single query to the backend
+type sharedMetricsCache struct {
+	// ttl is 0 if the results aren't shared
+	ttl time.Duration
+
+	lock    sync.Mutex
+	entries map[string]*sharedMetricsEntry
+}
+
+type sharedMetricsEntry struct {
+	// done is closed when the query is finished, the identical queries started meanwhile wait for it
+	done chan struct{}
+	// metricName is the metric name the query was made with, the values are renamed for the other triggers
+	metricName string
+
+	metrics   []external_metrics.ExternalMetricValue
+	activity  bool
+	err       error
+	expiresAt time.Time
+}
+
+var (
+	globalSharedMetrics     = &sharedMetricsCache{}
+	globalSharedMetricsLock sync.RWMutex
+)
+
+// SetSharedMetricsTTL sets how long the result of a trigger is shared with the identical triggers of other
+// scalable objects, 0 disables the sharing. The results are only shared by scalable objects polled less often than the TTL.
+func SetSharedMetricsTTL(ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("the TTL of the shared scaler metrics can't be negative, %s given", ttl)
+	}
+
+	globalSharedMetricsLock.Lock()
+	defer globalSharedMetricsLock.Unlock()
+	globalSharedMetrics = &sharedMetricsCache{
+		ttl:     ttl,
+		entries: map[string]*sharedMetricsEntry{},
+	}
+	return nil
+}
+
+func getSharedMetricsCache() *sharedMetricsCache {
+	globalSharedMetricsLock.RLock()
+	defer globalSharedMetricsLock.RUnlock()
+	return globalSharedMetrics
+}
+
+// getSharedMetricsKey returns the normalized hash of the trigger definition, the metric name is left out
+// as it only differs by the index of the trigger in the scalable object
+func getSharedMetricsKey(config scalers.ScalerConfig) string {
+	hash := sha256.New()
+	writeSection := func(name string, values map[string]string) {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(hash, "%s:%d\n", name, len(keys))
+		for _, key := range keys {
+			fmt.Fprintf(hash, "%q=%q\n", key, values[key])
+		}
+	}
+
+	fmt.Fprintf(hash, "type:%q\n", config.TriggerType)
+	writeSection("metadata", config.TriggerMetadata)
+	writeSection("authParams", config.AuthParams)
+	fmt.Fprintf(hash, "podIdentity:%q/%q\n", config.PodIdentity.Provider, config.PodIdentity.IdentityID)
+	// the values taken from the environment of the scale target are part of the trigger definition
+	env := map[string]string{}
+	for key, value := range config.TriggerMetadata {
+		if strings.HasSuffix(key, "FromEnv") {
+			env[value] = config.ResolvedEnv[value]
+		}
+	}
+	writeSection("env", env)
+	if config.HTTPProxy != nil {
+		fmt.Fprintf(hash, "proxy:%q\n", config.HTTPProxy.String())
+	}
+	if objectScopedTriggerTypes[config.TriggerType] {
+		fmt.Fprintf(hash, "object:%q/%q/%q\n", config.ScalableObjectType, config.ScalableObjectNamespace, config.ScalableObjectName)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// get returns the shared result of the trigger if it is still valid, or else makes the query and shares its result.
+// Failed queries aren't shared with the triggers polled after them.
+func (c *sharedMetricsCache) get(ctx context.Context, key, metricName string, query func() ([]external_metrics.ExternalMetricValue, bool, error)) ([]external_metrics.ExternalMetricValue, bool, error) {
+	now := time.Now()
+	c.lock.Lock()
+	entry, found := c.entries[key]
+	if found && entry.isExpired(now) {
+		found = false
+	}
+	if !found {
+		c.deleteExpired(now)
+		entry = &sharedMetricsEntry{done: make(chan struct{}), metricName: metricName}
+		c.entries[key] = entry
+	}
+	c.lock.Unlock()
+
+	if found {
+		select {
+		case <-entry.done:
+		default:
+			// the query is still running, its result is awaited instead of querying the backend again
+			prommetrics.RecordScalerQueryCoalesced(metricName)
+		}
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+		return copyMetrics(entry.metrics, entry.metricName, metricName), entry.activity, entry.err
+	}
+
+	metrics, activity, err := query()
+	c.lock.Lock()
+	// the caller may change its values, the shared ones are a copy
+	entry.metrics, entry.activity, entry.err = copyMetrics(metrics, metricName, metricName), activity, err
+	entry.expiresAt = time.Now().Add(c.ttl)
+	if err != nil && c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+	c.lock.Unlock()
+	close(entry.done)
+	return metrics, activity, err
+}
+
+// delete forgets the result of the trigger, e.g. when its scaler has been rebuilt
+func (c *sharedMetricsCache) delete(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, key)
+}
+
+// deleteExpired removes the expired entries, the lock has to be held
+func (c *sharedMetricsCache) deleteExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if entry.isExpired(now) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// isExpired returns whether the query is finished for longer than the TTL, the lock has to be held
+func (e *sharedMetricsEntry) isExpired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// copyMetrics deep copies the metric values, renaming the ones of the metric name the query was made with
+func copyMetrics(metrics []external_metrics.ExternalMetricValue, queryMetricName, metricName string) []external_metrics.ExternalMetricValue {
+	if metrics == nil {
+		return nil
+	}
+	result := make([]external_metrics.ExternalMetricValue, 0, len(metrics))
+	for _, metric := range metrics {
+		metric := *metric.DeepCopy()
+		if metric.MetricName == queryMetricName {
+			metric.MetricName = metricName
+		}
+		result = append(result, metric)
+	}
+	return result
+}
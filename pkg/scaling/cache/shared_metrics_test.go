@@ -0,0 +1,302 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestSharedMetricsConcurrentIdenticalTriggers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	assert.NoError(t, SetSharedMetricsTTL(time.Minute))
+	defer func() {
+		assert.NoError(t, SetSharedMetricsTTL(0))
+	}()
+
+	var queries int32
+	caches := make([]*ScalersCache, 8)
+	for i := range caches {
+		caches[i] = createSharedMetricsCache(ctrl, &queries, map[string]string{"token": "secret"}, 2*time.Minute, nil)
+	}
+
+	var wg sync.WaitGroup
+	for i := range caches {
+		for j := 0; j < 10; j++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				metricName := fmt.Sprintf("s0-metric-%d", i)
+				metrics, activity, _, err := caches[i].GetMetricsAndActivityForScaler(context.Background(), 0, metricName)
+				assert.NoError(t, err)
+				assert.True(t, activity)
+				assert.Len(t, metrics, 1)
+				assert.Equal(t, metricName, metrics[0].MetricName)
+				assert.Equal(t, int64(10), metrics[0].Value.Value())
+			}(i)
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queries))
+}
+
+func TestSharedMetricsCoalescedQueriesCounted(t *testing.T) {
+	shared := &sharedMetricsCache{ttl: time.Minute, entries: map[string]*sharedMetricsEntry{}}
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	var queries int32
+	query := func() ([]external_metrics.ExternalMetricValue, bool, error) {
+		if atomic.AddInt32(&queries, 1) == 1 {
+			close(started)
+		}
+		<-unblock
+		return []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili("s0-coalesced", 10)}, true, nil
+	}
+
+	coalesced := getCounterValue(t, "keda_scaler_query_coalesced_total", "s0-coalesced")
+	var wg sync.WaitGroup
+	get := func() {
+		defer wg.Done()
+		metrics, _, err := shared.get(context.Background(), "key", "s0-coalesced", query)
+		assert.NoError(t, err)
+		assert.Len(t, metrics, 1)
+	}
+	wg.Add(1)
+	go get()
+	<-started
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go get()
+	}
+
+	// the identical queries started while the first one is in flight wait for its result
+	assert.Eventually(t, func() bool {
+		return getCounterValue(t, "keda_scaler_query_coalesced_total", "s0-coalesced") == coalesced+5
+	}, time.Second, 10*time.Millisecond)
+	close(unblock)
+	wg.Wait()
+
+	// the queries served by a finished query aren't coalesced
+	_, _, err := shared.get(context.Background(), "key", "s0-coalesced", query)
+	assert.NoError(t, err)
+	assert.Equal(t, coalesced+5, getCounterValue(t, "keda_scaler_query_coalesced_total", "s0-coalesced"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queries))
+}
+
+func TestSharedMetricsDifferentAuthParams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	assert.NoError(t, SetSharedMetricsTTL(time.Minute))
+	defer func() {
+		assert.NoError(t, SetSharedMetricsTTL(0))
+	}()
+
+	var queries int32
+	first := createSharedMetricsCache(ctrl, &queries, map[string]string{"token": "first"}, 2*time.Minute, nil)
+	second := createSharedMetricsCache(ctrl, &queries, map[string]string{"token": "second"}, 2*time.Minute, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, cache := range []*ScalersCache{first, second} {
+			wg.Add(1)
+			go func(cache *ScalersCache) {
+				defer wg.Done()
+				_, _, _, err := cache.GetMetricsAndActivityForScaler(context.Background(), 0, "s0-metric")
+				assert.NoError(t, err)
+			}(cache)
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&queries))
+}
+
+func TestSharedMetricsNotUsedWithShorterPollingInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	assert.NoError(t, SetSharedMetricsTTL(time.Minute))
+	defer func() {
+		assert.NoError(t, SetSharedMetricsTTL(0))
+	}()
+
+	var queries int32
+	for i := 0; i < 2; i++ {
+		cache := createSharedMetricsCache(ctrl, &queries, nil, 30*time.Second, nil)
+		_, _, _, err := cache.GetMetricsAndActivityForScaler(context.Background(), 0, "s0-metric")
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&queries))
+}
+
+func TestSharedMetricsExpire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	assert.NoError(t, SetSharedMetricsTTL(10*time.Millisecond))
+	defer func() {
+		assert.NoError(t, SetSharedMetricsTTL(0))
+	}()
+
+	var queries int32
+	cache := createSharedMetricsCache(ctrl, &queries, nil, time.Minute, nil)
+	_, _, _, err := cache.GetMetricsAndActivityForScaler(context.Background(), 0, "s0-metric")
+	assert.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, _, _, err = cache.GetMetricsAndActivityForScaler(context.Background(), 0, "s0-metric")
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&queries))
+	assert.Len(t, getSharedMetricsCache().entries, 1)
+}
+
+func TestSharedMetricsErrorsNotShared(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	assert.NoError(t, SetSharedMetricsTTL(time.Minute))
+	defer func() {
+		assert.NoError(t, SetSharedMetricsTTL(0))
+	}()
+
+	var queries int32
+	failing := createSharedMetricsCache(ctrl, &queries, nil, 2*time.Minute, errors.New("backend unavailable"))
+	// the scaler rebuilt after the error fails again
+	failing.Scalers[0].Factory = func() (scalers.Scaler, *scalers.ScalerConfig, error) {
+		rebuilt := createSharedMetricsCache(ctrl, &queries, nil, 2*time.Minute, errors.New("backend unavailable"))
+		return rebuilt.Scalers[0].Scaler, &rebuilt.Scalers[0].ScalerConfig, nil
+	}
+	_, _, _, err := failing.GetMetricsAndActivityForScaler(context.Background(), 0, "s0-metric")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&queries))
+	assert.Empty(t, getSharedMetricsCache().entries)
+
+	cache := createSharedMetricsCache(ctrl, &queries, nil, 2*time.Minute, nil)
+	_, _, _, err = cache.GetMetricsAndActivityForScaler(context.Background(), 0, "s0-metric")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&queries))
+}
+
+func TestSharedMetricsCopies(t *testing.T) {
+	shared := &sharedMetricsCache{ttl: time.Minute, entries: map[string]*sharedMetricsEntry{}}
+	query := func() ([]external_metrics.ExternalMetricValue, bool, error) {
+		return []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili("s0-first", 10)}, true, nil
+	}
+
+	first, _, err := shared.get(context.Background(), "key", "s0-first", query)
+	assert.NoError(t, err)
+	first[0].MetricName = "changed"
+
+	second, activity, err := shared.get(context.Background(), "key", "s1-second", query)
+	assert.NoError(t, err)
+	assert.True(t, activity)
+	assert.Equal(t, "s1-second", second[0].MetricName)
+}
+
+func TestGetSharedMetricsKey(t *testing.T) {
+	config := scalers.ScalerConfig{
+		ScalableObjectName:      "first",
+		ScalableObjectNamespace: "default",
+		ScalableObjectType:      "ScaledObject",
+		TriggerType:             "prometheus",
+		TriggerMetadata:         map[string]string{"query": "sum(up)", "threshold": "10", "tokenFromEnv": "TOKEN"},
+		ResolvedEnv:             map[string]string{"TOKEN": "first"},
+		AuthParams:              map[string]string{"bearerToken": "secret"},
+	}
+	key := getSharedMetricsKey(config)
+
+	other := config
+	other.ScalableObjectName = "second"
+	other.TriggerMetadata = map[string]string{"threshold": "10", "query": "sum(up)", "tokenFromEnv": "TOKEN"}
+	assert.Equal(t, key, getSharedMetricsKey(other))
+
+	workload, otherWorkload := config, other
+	workload.TriggerType, otherWorkload.TriggerType = "kubernetes-workload", "kubernetes-workload"
+	assert.NotEqual(t, getSharedMetricsKey(workload), getSharedMetricsKey(otherWorkload))
+
+	other.TriggerType = "datadog"
+	assert.NotEqual(t, key, getSharedMetricsKey(other))
+
+	other.TriggerType = config.TriggerType
+	other.AuthParams = map[string]string{"bearerToken": "rotated"}
+	assert.NotEqual(t, key, getSharedMetricsKey(other))
+
+	other.AuthParams = config.AuthParams
+	other.ResolvedEnv = map[string]string{"TOKEN": "second"}
+	assert.NotEqual(t, key, getSharedMetricsKey(other))
+
+	other.ResolvedEnv = config.ResolvedEnv
+	other.PodIdentity = kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAzureWorkload}
+	assert.NotEqual(t, key, getSharedMetricsKey(other))
+}
+
+func TestSetSharedMetricsTTLNegative(t *testing.T) {
+	assert.Error(t, SetSharedMetricsTTL(-time.Second))
+}
+
+func createSharedMetricsCache(ctrl *gomock.Controller, queries *int32, authParams map[string]string, pollingInterval time.Duration, err error) *ScalersCache {
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+			atomic.AddInt32(queries, 1)
+			if err != nil {
+				return nil, false, err
+			}
+			time.Sleep(10 * time.Millisecond)
+			return []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(metricName, 10)}, true, nil
+		}).AnyTimes()
+	scaler.EXPECT().Close(gomock.Any()).AnyTimes()
+
+	return &ScalersCache{
+		ScaledObject:    &kedav1alpha1.ScaledObject{},
+		PollingInterval: pollingInterval,
+		Scalers: []ScalerBuilder{{
+			Scaler: scaler,
+			ScalerConfig: scalers.ScalerConfig{
+				TriggerMetadata: map[string]string{"query": "sum(up)"},
+				AuthParams:      authParams,
+			},
+		}},
+	}
+}
+
+func getCounterValue(t *testing.T, name, metric string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "metric" && label.GetValue() == metric {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+// triggerPoll is the last result of a metric of a trigger with its own pollingInterval
+type triggerPoll struct {
+	metrics  []external_metrics.ExternalMetricValue
+	isActive bool
+	nextPoll time.Time
+}
+
+// RecordTriggerPoll stores the metrics and activity of the metric of the scaler queried at now, they are reused by
+// the scale loop until the pollingInterval of the trigger has elapsed
+func (c *ScalersCache) RecordTriggerPoll(scalerIndex int, metricName string, metrics []external_metrics.ExternalMetricValue, isActive bool, pollingInterval time.Duration, now time.Time) {
+	if pollingInterval <= 0 {
+		return
+	}
+
+	c.pollsLock.Lock()
+	defer c.pollsLock.Unlock()
+
+	if c.polls == nil {
+		c.polls = map[string]triggerPoll{}
+	}
+	c.polls[fmt.Sprintf("%d/%s", scalerIndex, metricName)] = triggerPoll{
+		metrics:  metrics,
+		isActive: isActive,
+		nextPoll: now.Add(pollingInterval),
+	}
+}
+
+// GetTriggerPoll returns the last recorded metrics and activity of the metric of the scaler, the third return value
+// is false if there is none or if the trigger is due to be queried again at now
+func (c *ScalersCache) GetTriggerPoll(scalerIndex int, metricName string, now time.Time) ([]external_metrics.ExternalMetricValue, bool, bool) {
+	c.pollsLock.Lock()
+	defer c.pollsLock.Unlock()
+
+	poll, ok := c.polls[fmt.Sprintf("%d/%s", scalerIndex, metricName)]
+	if !ok || !now.Before(poll.nextPoll) {
+		return nil, false, false
+	}
+	return poll.metrics, poll.isActive, true
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+func TestTriggerPoll(t *testing.T) {
+	cache := &ScalersCache{}
+	now := time.Unix(1000, 0)
+	metrics := []external_metrics.ExternalMetricValue{{MetricName: "s1-metric", Value: *resource.NewQuantity(5, resource.DecimalSI)}}
+
+	_, _, ok := cache.GetTriggerPoll(1, "s1-metric", now)
+	assert.False(t, ok)
+
+	// without pollingInterval the trigger is queried on every poll
+	cache.RecordTriggerPoll(0, "s0-metric", metrics, true, 0, now)
+	_, _, ok = cache.GetTriggerPoll(0, "s0-metric", now.Add(time.Second))
+	assert.False(t, ok)
+
+	cache.RecordTriggerPoll(1, "s1-metric", metrics, true, 5*time.Minute, now)
+	reused, isActive, ok := cache.GetTriggerPoll(1, "s1-metric", now.Add(15*time.Second))
+	assert.True(t, ok)
+	assert.True(t, isActive)
+	assert.Equal(t, metrics, reused)
+	_, _, ok = cache.GetTriggerPoll(1, "s1-other-metric", now.Add(15*time.Second))
+	assert.False(t, ok)
+
+	// the trigger is due once its pollingInterval has elapsed
+	_, _, ok = cache.GetTriggerPoll(1, "s1-metric", now.Add(5*time.Minute))
+	assert.False(t, ok)
+}
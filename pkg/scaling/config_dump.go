@@ -0,0 +1,300 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+const redactedConfigValue = "<redacted>"
+
+// nonSecretConfigKeys are the lower-cased metadata and auth parameters whose values are shown in the config dumps,
+// the values of any other key are redacted. Addresses, connection strings and queries aren't listed as they can embed credentials
+var nonSecretConfigKeys = map[string]bool{
+	"activationlagthreshold":              true,
+	"activationqueuelength":               true,
+	"activationtargetvalue":               true,
+	"activationthreshold":                 true,
+	"activationunprocessedeventthreshold": true,
+	"activationvalue":                     true,
+	"aggregation":                         true,
+	"allowidleconsumers":                  true,
+	"apiversion":                          true,
+	"authentication":                      true,
+	"authmode":                            true,
+	"authmodes":                           true,
+	"awsregion":                           true,
+	"blobcontainer":                       true,
+	"checkpointstrategy":                  true,
+	"cloud":                               true,
+	"consumergroup":                       true,
+	"desiredreplicas":                     true,
+	"end":                                 true,
+	"endpointsuffix":                      true,
+	"eventhubname":                        true,
+	"eventhubnamespace":                   true,
+	"expression":                          true,
+	"fieldselector":                       true,
+	"identityowner":                       true,
+	"ignorenullvalues":                    true,
+	"jsonpath":                            true,
+	"jsonpathvalue":                       true,
+	"kind":                                true,
+	"kvmount":                             true,
+	"kvversion":                           true,
+	"labelselector":                       true,
+	"lagthreshold":                        true,
+	"listlength":                          true,
+	"listname":                            true,
+	"maxstaleness":                        true,
+	"metricname":                          true,
+	"mode":                                true,
+	"namespace":                           true,
+	"offsetresetpolicy":                   true,
+	"path":                                true,
+	"protocol":                            true,
+	"queuelength":                         true,
+	"queuename":                           true,
+	"role":                                true,
+	"sasl":                                true,
+	"start":                               true,
+	"storageaccountname":                  true,
+	"storageendpointsuffix":               true,
+	"subscriptionname":                    true,
+	"targetvalue":                         true,
+	"threshold":                           true,
+	"timezone":                            true,
+	"tls":                                 true,
+	"topic":                               true,
+	"unprocessedeventthreshold":           true,
+	"unsafessl":                           true,
+	"value":                               true,
+	"valuelocation":                       true,
+	"vhostname":                           true,
+}
+
+// scaledObjectConfigDump is the effective configuration of the triggers of a ScaledObject
+type scaledObjectConfigDump struct {
+	Namespace string              `json:"namespace"`
+	Name      string              `json:"name"`
+	Triggers  []triggerConfigDump `json:"triggers"`
+}
+
+// triggerConfigDump is the resolved ScalerConfig of a trigger, with the values which aren't known to be non-secret redacted
+type triggerConfigDump struct {
+	Index               int                 `json:"index"`
+	Type                string              `json:"type"`
+	Name                string              `json:"name,omitempty"`
+	Metadata            map[string]string   `json:"metadata"`
+	AuthParams          map[string]string   `json:"authParams,omitempty"`
+	PodIdentityProvider string              `json:"podIdentityProvider,omitempty"`
+	Metrics             []triggerMetricDump `json:"metrics"`
+}
+
+type triggerMetricDump struct {
+	Name       string `json:"name"`
+	TargetType string `json:"targetType"`
+	Target     string `json:"target"`
+}
+
+// ScalerConfigDumpServer serves the scaler configuration dumps on a loopback address, the dumps are reached
+// with a port-forward to the operator pod by the users allowed to do it
+type ScalerConfigDumpServer struct {
+	server   *http.Server
+	listener net.Listener
+	logger   logr.Logger
+}
+
+// NewScalerConfigDumpServer returns a server of the scaler configuration dumps listening on address,
+// which must be a loopback address as the dumps aren't authenticated
+func NewScalerConfigDumpServer(address string, kubeClient client.Client, scaleHandler ScaleHandler, logger logr.Logger) (*ScalerConfigDumpServer, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scaler config dump address %q: %w", address, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("the scaler config dump address %q must be a loopback address", address)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("error listening on %s: %w", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/scaler-config", NewScalerConfigDumpHandler(kubeClient, scaleHandler))
+	return &ScalerConfigDumpServer{
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		listener: listener,
+		logger:   logger,
+	}, nil
+}
+
+// Start serves the dumps until the context is done, this implements the Runnable interface of the controller-runtime Manager
+func (s *ScalerConfigDumpServer) Start(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		if err := s.server.Shutdown(context.Background()); err != nil {
+			s.logger.Error(err, "error shutting down the scaler config dump server")
+		}
+	}()
+	s.logger.Info("Starting scaler config dump server", "address", s.listener.Addr().String())
+	if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection returns false, the followers answer that the scalers aren't built as they don't run the scale loops
+func (s *ScalerConfigDumpServer) NeedLeaderElection() bool {
+	return false
+}
+
+// NewScalerConfigDumpHandler returns a handler serving the effective scaler configuration of the ScaledObject given by
+// the namespace and name query parameters, the secrets are redacted. Only the scalers already built by the scale loop
+// are dumped, a request never builds them
+func NewScalerConfigDumpHandler(kubeClient client.Client, scaleHandler ScaleHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		namespace, name := r.URL.Query().Get("namespace"), r.URL.Query().Get("name")
+		if namespace == "" || name == "" {
+			http.Error(w, "the namespace and name query parameters are required", http.StatusBadRequest)
+			return
+		}
+
+		scaledObject := &kedav1alpha1.ScaledObject{}
+		if err := kubeClient.Get(r.Context(), types.NamespacedName{Namespace: namespace, Name: name}, scaledObject); err != nil {
+			status := http.StatusInternalServerError
+			if apierrors.IsNotFound(err) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		scalersCache, found := scaleHandler.GetBuiltScalersCache(scaledObject)
+		if !found {
+			http.Error(w, "the scalers of the ScaledObject aren't built for its current generation on this replica", http.StatusConflict)
+			return
+		}
+
+		dump := scaledObjectConfigDump{Namespace: namespace, Name: name, Triggers: []triggerConfigDump{}}
+		for i, builder := range scalersCache.Scalers {
+			triggerType := ""
+			if i < len(scaledObject.Spec.Triggers) {
+				triggerType = scaledObject.Spec.Triggers[i].Type
+			}
+			dump.Triggers = append(dump.Triggers, dumpScalerConfig(triggerType, &builder.ScalerConfig, builder.Scaler.GetMetricSpecForScaling(r.Context())))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(dump)
+	})
+}
+
+// dumpScalerConfig returns the configuration of a trigger, the metadata read from the environment with the *FromEnv
+// keys is added under the key without the suffix
+func dumpScalerConfig(triggerType string, config *scalers.ScalerConfig, metricSpecs []v2.MetricSpec) triggerConfigDump {
+	dump := triggerConfigDump{
+		Index:    config.ScalerIndex,
+		Type:     triggerType,
+		Name:     config.TriggerName,
+		Metadata: map[string]string{},
+		Metrics:  []triggerMetricDump{},
+	}
+
+	for key, value := range config.TriggerMetadata {
+		if strings.HasSuffix(key, "FromEnv") {
+			// the name of the variable isn't secret, unlike its value
+			dump.Metadata[key] = value
+			continue
+		}
+		dump.Metadata[key] = redactConfigValue(key, value)
+	}
+	for key, value := range config.TriggerMetadata {
+		resolvedKey := strings.TrimSuffix(key, "FromEnv")
+		if resolvedKey == key {
+			continue
+		}
+		if _, found := config.TriggerMetadata[resolvedKey]; found {
+			continue
+		}
+		if resolved, found := config.ResolvedEnv[value]; found {
+			dump.Metadata[resolvedKey] = redactConfigValue(resolvedKey, resolved)
+		}
+	}
+
+	if len(config.AuthParams) > 0 {
+		dump.AuthParams = map[string]string{}
+		for key, value := range config.AuthParams {
+			dump.AuthParams[key] = redactConfigValue(key, value)
+		}
+	}
+	if config.PodIdentity.Provider != "" {
+		dump.PodIdentityProvider = string(config.PodIdentity.Provider)
+	}
+
+	for _, spec := range metricSpecs {
+		switch {
+		case spec.External != nil:
+			dump.Metrics = append(dump.Metrics, newTriggerMetricDump(spec.External.Metric.Name, spec.External.Target))
+		case spec.Resource != nil:
+			dump.Metrics = append(dump.Metrics, newTriggerMetricDump(string(spec.Resource.Name), spec.Resource.Target))
+		}
+	}
+	sort.Slice(dump.Metrics, func(i, j int) bool { return dump.Metrics[i].Name < dump.Metrics[j].Name })
+	return dump
+}
+
+func newTriggerMetricDump(name string, target v2.MetricTarget) triggerMetricDump {
+	metric := triggerMetricDump{Name: name, TargetType: string(target.Type)}
+	switch {
+	case target.AverageValue != nil:
+		metric.Target = target.AverageValue.String()
+	case target.Value != nil:
+		metric.Target = target.Value.String()
+	case target.AverageUtilization != nil:
+		metric.Target = fmt.Sprintf("%d", *target.AverageUtilization)
+	}
+	return metric
+}
+
+func redactConfigValue(key, value string) string {
+	if value == "" || nonSecretConfigKeys[strings.ToLower(key)] {
+		return value
+	}
+	return redactedConfigValue
+}
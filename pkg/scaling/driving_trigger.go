@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// triggerRatio is the ratio of the value a trigger served to the HPA to its target
+type triggerRatio struct {
+	triggerIndex int
+	triggerType  string
+	triggerName  string
+	metricName   string
+	ratio        float64
+}
+
+// drivingTriggers keeps the last ratio of each metric served for the ScaledObjects, the HPA asks for their metrics
+// one by one and scales on the highest of them
+type drivingTriggers struct {
+	scaledObjects map[string]*scaledObjectRatios
+	lock          sync.Mutex
+}
+
+type scaledObjectRatios struct {
+	generation int64
+	ratios     map[string]triggerRatio
+	driver     *triggerRatio
+	// statusMetricName is the metric of the driving trigger in the status of the ScaledObject
+	statusMetricName string
+}
+
+// update records the ratio of a metric of the ScaledObject and returns its driving trigger, changed is true when
+// another trigger drives the scaling and statusOutdated when the status doesn't show the driving trigger yet.
+// The ratios of the previous generations of the ScaledObject are dropped
+func (d *drivingTriggers) update(scaledObject *kedav1alpha1.ScaledObject, ratio triggerRatio) (driver triggerRatio, changed, statusOutdated bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.scaledObjects == nil {
+		d.scaledObjects = map[string]*scaledObjectRatios{}
+	}
+	key := scaledObject.GenerateIdentifier()
+	ratios, found := d.scaledObjects[key]
+	if !found {
+		ratios = &scaledObjectRatios{generation: scaledObject.Generation, ratios: map[string]triggerRatio{}}
+		if scaledObject.Status.DrivingTrigger != nil {
+			ratios.statusMetricName = scaledObject.Status.DrivingTrigger.MetricName
+		}
+		d.scaledObjects[key] = ratios
+	}
+	if ratios.generation != scaledObject.Generation {
+		ratios.generation = scaledObject.Generation
+		ratios.ratios = map[string]triggerRatio{}
+	}
+
+	ratios.ratios[ratio.metricName] = ratio
+	driver = selectDrivingTrigger(ratios.ratios, ratios.driver)
+	changed = ratios.driver == nil || ratios.driver.metricName != driver.metricName
+	statusOutdated = ratios.statusMetricName != driver.metricName
+	ratios.driver = &driver
+	ratios.statusMetricName = driver.metricName
+	return driver, changed, statusOutdated
+}
+
+// statusFailed forgets the driving trigger of the status, it's updated again with the next metric served
+func (d *drivingTriggers) statusFailed(scaledObjectIdentifier string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if ratios, found := d.scaledObjects[scaledObjectIdentifier]; found {
+		ratios.statusMetricName = ""
+	}
+}
+
+func (d *drivingTriggers) delete(scaledObjectIdentifier string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.scaledObjects, scaledObjectIdentifier)
+}
+
+// selectDrivingTrigger returns the trigger with the highest ratio. On a tie the current driving trigger is kept,
+// so it doesn't flap between triggers with the same ratio, otherwise the first trigger of the spec wins
+func selectDrivingTrigger(ratios map[string]triggerRatio, current *triggerRatio) triggerRatio {
+	var driver triggerRatio
+	found := false
+	for _, ratio := range ratios {
+		if !found || ratio.ratio > driver.ratio || (ratio.ratio == driver.ratio && isBefore(ratio, driver)) {
+			driver = ratio
+			found = true
+		}
+	}
+	if current != nil {
+		if ratio, ok := ratios[current.metricName]; ok && ratio.ratio == driver.ratio {
+			return ratio
+		}
+	}
+	return driver
+}
+
+func isBefore(ratio, other triggerRatio) bool {
+	if ratio.triggerIndex != other.triggerIndex {
+		return ratio.triggerIndex < other.triggerIndex
+	}
+	return ratio.metricName < other.metricName
+}
+
+// getMetricTargetRatio returns the ratio of the sum of the values of the metric to its target, false when the target
+// isn't a value or an average value
+func getMetricTargetRatio(spec v2.MetricSpec, metrics []external_metrics.ExternalMetricValue) (float64, bool) {
+	if spec.External == nil {
+		return 0, false
+	}
+	target := spec.External.Target.AverageValue
+	if spec.External.Target.Type == v2.ValueMetricType {
+		target = spec.External.Target.Value
+	}
+	if target == nil || target.IsZero() {
+		return 0, false
+	}
+
+	var value float64
+	for _, metric := range metrics {
+		value += metric.Value.AsApproximateFloat64()
+	}
+	return value / target.AsApproximateFloat64(), true
+}
+
+// updateDrivingTrigger records the ratio of the metric of a trigger served to the HPA, the driving trigger of the
+// ScaledObject is only reported when it changes
+func (h *scaleHandler) updateDrivingTrigger(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scalerConfig scalers.ScalerConfig, metricName string, ratio float64) {
+	trigger := triggerRatio{triggerIndex: scalerConfig.ScalerIndex, triggerName: scalerConfig.TriggerName, metricName: metricName, ratio: ratio}
+	if scalerConfig.ScalerIndex < len(scaledObject.Spec.Triggers) {
+		trigger.triggerType = scaledObject.Spec.Triggers[scalerConfig.ScalerIndex].Type
+	}
+
+	driver, changed, statusOutdated := h.drivingTriggers.update(scaledObject, trigger)
+	if changed {
+		logger.V(1).Info("Driving trigger changed", "trigger", driver.triggerName, "type", driver.triggerType, "metricName", driver.metricName, "ratio", driver.ratio)
+		prommetrics.RecordScaledObjectDrivingTrigger(scaledObject.Namespace, scaledObject.Name, driver.triggerType, driver.metricName)
+	}
+	if !statusOutdated {
+		return
+	}
+
+	status := &kedav1alpha1.DrivingTriggerStatus{
+		Type:               driver.triggerType,
+		Name:               driver.triggerName,
+		MetricName:         driver.metricName,
+		LastTransitionTime: metav1.Now(),
+	}
+	// the ScaledObject of the scalers cache is shared, the status is patched on a copy
+	if err := h.setDrivingTrigger(ctx, logger, scaledObject.DeepCopy(), status); err != nil {
+		logger.Error(err, "error updating the driving trigger of the ScaledObject")
+		h.drivingTriggers.statusFailed(scaledObject.GenerateIdentifier())
+	}
+}
+
+func (h *scaleHandler) setDrivingTrigger(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, drivingTrigger *kedav1alpha1.DrivingTriggerStatus) error {
+	transform := func(runtimeObj runtimeclient.Object, target interface{}) error {
+		drivingTrigger, ok := target.(*kedav1alpha1.DrivingTriggerStatus)
+		if !ok {
+			return fmt.Errorf("transform target is not *kedav1alpha1.DrivingTriggerStatus type %v", target)
+		}
+		if obj, ok := runtimeObj.(*kedav1alpha1.ScaledObject); ok {
+			obj.Status.DrivingTrigger = drivingTrigger
+		}
+		return nil
+	}
+	return kedautil.TransformObject(ctx, h.client, logger, scaledObject, drivingTrigger, transform)
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+)
+
+func TestSelectDrivingTrigger(t *testing.T) {
+	queue := triggerRatio{triggerIndex: 0, metricName: "s0-queue"}
+	stream := triggerRatio{triggerIndex: 1, metricName: "s1-stream"}
+	latency := triggerRatio{triggerIndex: 2, metricName: "s2-latency"}
+	withRatio := func(trigger triggerRatio, ratio float64) triggerRatio {
+		trigger.ratio = ratio
+		return trigger
+	}
+
+	cases := []struct {
+		name     string
+		ratios   []triggerRatio
+		current  *triggerRatio
+		expected string
+	}{
+		{name: "single trigger", ratios: []triggerRatio{withRatio(stream, 0.5)}, expected: "s1-stream"},
+		{name: "highest ratio", ratios: []triggerRatio{withRatio(queue, 2), withRatio(stream, 3.5), withRatio(latency, 1)}, expected: "s1-stream"},
+		{name: "highest ratio over the current trigger", ratios: []triggerRatio{withRatio(queue, 2), withRatio(stream, 3.5)}, current: &queue, expected: "s1-stream"},
+		{name: "tie goes to the first trigger", ratios: []triggerRatio{withRatio(latency, 3), withRatio(stream, 3), withRatio(queue, 1)}, expected: "s1-stream"},
+		{name: "tie keeps the current trigger", ratios: []triggerRatio{withRatio(queue, 3), withRatio(stream, 3), withRatio(latency, 3)}, current: &latency, expected: "s2-latency"},
+		{name: "tie without the current trigger", ratios: []triggerRatio{withRatio(queue, 3), withRatio(stream, 3), withRatio(latency, 1)}, current: &latency, expected: "s0-queue"},
+		{name: "all zero", ratios: []triggerRatio{withRatio(latency, 0), withRatio(stream, 0)}, expected: "s1-stream"},
+	}
+
+	for _, c := range cases {
+		ratios := map[string]triggerRatio{}
+		for _, ratio := range c.ratios {
+			ratios[ratio.metricName] = ratio
+		}
+		assert.Equal(t, c.expected, selectDrivingTrigger(ratios, c.current).metricName, c.name)
+	}
+}
+
+func TestDrivingTriggersUpdate(t *testing.T) {
+	scaledObject := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Name: "so", Namespace: "ns", Generation: 1}}
+	triggers := drivingTriggers{}
+
+	driver, changed, statusOutdated := triggers.update(scaledObject, triggerRatio{triggerIndex: 0, metricName: "s0-queue", ratio: 2})
+	assert.Equal(t, "s0-queue", driver.metricName)
+	assert.True(t, changed)
+	assert.True(t, statusOutdated)
+
+	// a lower ratio of another trigger doesn't change anything
+	driver, changed, statusOutdated = triggers.update(scaledObject, triggerRatio{triggerIndex: 1, metricName: "s1-stream", ratio: 1})
+	assert.Equal(t, "s0-queue", driver.metricName)
+	assert.False(t, changed)
+	assert.False(t, statusOutdated)
+
+	// the other trigger drives once its ratio is higher
+	driver, _, statusOutdated = triggers.update(scaledObject, triggerRatio{triggerIndex: 1, metricName: "s1-stream", ratio: 4})
+	assert.Equal(t, "s1-stream", driver.metricName)
+	assert.True(t, statusOutdated)
+
+	// and keeps driving on a tie
+	driver, changed, _ = triggers.update(scaledObject, triggerRatio{triggerIndex: 0, metricName: "s0-queue", ratio: 4})
+	assert.Equal(t, "s1-stream", driver.metricName)
+	assert.False(t, changed)
+
+	// the ratios of the previous generation are dropped
+	scaledObject.Generation = 2
+	driver, changed, _ = triggers.update(scaledObject, triggerRatio{triggerIndex: 0, metricName: "s0-queue", ratio: 1})
+	assert.Equal(t, "s0-queue", driver.metricName)
+	assert.True(t, changed)
+
+	// a failed status update is retried with the next metric
+	triggers.statusFailed(scaledObject.GenerateIdentifier())
+	_, changed, statusOutdated = triggers.update(scaledObject, triggerRatio{triggerIndex: 0, metricName: "s0-queue", ratio: 1})
+	assert.False(t, changed)
+	assert.True(t, statusOutdated)
+
+	// after a restart the status already shows the driving trigger, only the metric is recorded
+	triggers.delete(scaledObject.GenerateIdentifier())
+	scaledObject.Status.DrivingTrigger = &kedav1alpha1.DrivingTriggerStatus{MetricName: "s0-queue"}
+	_, changed, statusOutdated = triggers.update(scaledObject, triggerRatio{triggerIndex: 0, metricName: "s0-queue", ratio: 1})
+	assert.True(t, changed)
+	assert.False(t, statusOutdated)
+}
+
+func TestGetMetricTargetRatio(t *testing.T) {
+	values := []external_metrics.ExternalMetricValue{
+		scalers.GenerateMetricInMili("metric", 6),
+		scalers.GenerateMetricInMili("metric", 1.5),
+	}
+	valueSpec := createMetricSpec(0, "metric")
+	valueSpec.External.Target = v2.MetricTarget{Type: v2.ValueMetricType, Value: resource.NewQuantity(5, resource.DecimalSI)}
+	utilizationSpec := createMetricSpec(0, "metric")
+	utilizationSpec.External.Target = v2.MetricTarget{Type: v2.UtilizationMetricType}
+
+	cases := []struct {
+		name  string
+		spec  v2.MetricSpec
+		ratio float64
+		ok    bool
+	}{
+		{name: "average value", spec: createMetricSpec(3, "metric"), ratio: 2.5, ok: true},
+		{name: "value", spec: valueSpec, ratio: 1.5, ok: true},
+		{name: "zero target", spec: createMetricSpec(0, "metric")},
+		{name: "utilization", spec: utilizationSpec},
+		{name: "resource metric", spec: v2.MetricSpec{Resource: &v2.ResourceMetricSource{}}},
+	}
+
+	for _, c := range cases {
+		ratio, ok := getMetricTargetRatio(c.spec, values)
+		assert.Equal(t, c.ok, ok, c.name)
+		assert.InDelta(t, c.ratio, ratio, 0.001, c.name)
+	}
+}
+
+func TestGetScaledObjectMetricsDrivingTrigger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_client.NewMockClient(ctrl)
+	mockStatusWriter := mock_client.NewMockStatusWriter(ctrl)
+	mockClient.EXPECT().Status().Return(mockStatusWriter).AnyTimes()
+	var patched []kedav1alpha1.DrivingTriggerStatus
+	mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+			if so, ok := obj.(*kedav1alpha1.ScaledObject); ok && so.Status.DrivingTrigger != nil {
+				patched = append(patched, *so.Status.DrivingTrigger)
+			}
+			return nil
+		}).AnyTimes()
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "driven", Namespace: "test-driving-trigger"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{Type: "rabbitmq", Name: "queue"},
+				{Type: "kafka", Name: "stream"},
+			},
+		},
+	}
+
+	values := map[string]float64{}
+	scalerCache := cache.ScalersCache{ScaledObject: &scaledObject, Recorder: record.NewFakeRecorder(10)}
+	for i, spec := range []v2.MetricSpec{createMetricSpec(2, "s0-queue"), createMetricSpec(5, "s1-stream")} {
+		spec := spec
+		spec.External.Target.Type = v2.AverageValueMetricType
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{spec}).AnyTimes()
+		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, string) ([]external_metrics.ExternalMetricValue, bool, error) {
+				metricName := spec.External.Metric.Name
+				return []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(metricName, values[metricName])}, true, nil
+			}).AnyTimes()
+		scaler.EXPECT().Close(gomock.Any()).AnyTimes()
+		scalerCache.Scalers = append(scalerCache.Scalers, cache.ScalerBuilder{
+			Scaler:       scaler,
+			ScalerConfig: scalers.ScalerConfig{TriggerName: scaledObject.Spec.Triggers[i].Name, ScalerIndex: i},
+		})
+	}
+	sh := newTestScaleHandler(mockClient, nil, map[string]*cache.ScalersCache{scaledObject.GenerateIdentifier(): &scalerCache})
+	defer scalerCache.Close(context.Background())
+
+	serve := func(queue, stream float64) {
+		values["s0-queue"], values["s1-stream"] = queue, stream
+		for _, metricName := range []string{"s0-queue", "s1-stream"} {
+			_, err := sh.GetScaledObjectMetrics(context.TODO(), scaledObject.Name, scaledObject.Namespace, metricName)
+			assert.NoError(t, err)
+		}
+	}
+
+	// 8 / 2 is above 10 / 5
+	serve(8, 10)
+	assert.Equal(t, map[string]float64{"rabbitmq/s0-queue": 1}, getDrivingTriggers(t, "driven"))
+	// the same values don't update the status again
+	serve(8, 10)
+	// 25 / 5 is above 8 / 2
+	serve(8, 25)
+	assert.Equal(t, map[string]float64{"kafka/s1-stream": 1}, getDrivingTriggers(t, "driven"))
+	// 10 / 2 ties with 25 / 5, the stream keeps driving
+	serve(10, 25)
+	assert.Equal(t, map[string]float64{"kafka/s1-stream": 1}, getDrivingTriggers(t, "driven"))
+
+	assert.Len(t, patched, 2)
+	if len(patched) == 2 {
+		assert.Equal(t, "rabbitmq", patched[0].Type)
+		assert.Equal(t, "queue", patched[0].Name)
+		assert.Equal(t, "s0-queue", patched[0].MetricName)
+		assert.Equal(t, "kafka", patched[1].Type)
+		assert.Equal(t, "stream", patched[1].Name)
+		assert.Equal(t, "s1-stream", patched[1].MetricName)
+		assert.False(t, patched[1].LastTransitionTime.IsZero())
+	}
+}
+
+func getDrivingTriggers(t *testing.T, scaledObject string) map[string]float64 {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	drivers := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_driving_trigger_info" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "test-driving-trigger" && labels["scaledObject"] == scaledObject {
+				drivers[labels["type"]+"/"+labels["metric"]] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return drivers
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+// activationAttempt is an activation from zero of the scale target of a ScaledObject which none of the pods is
+// ready for yet
+type activationAttempt struct {
+	selector  labels.Selector
+	activated time.Time
+}
+
+// getActivationFailureGracePeriod returns the advanced.activationFailureGracePeriod of the ScaledObject, 0 if it isn't set
+func getActivationFailureGracePeriod(scaledObject *kedav1alpha1.ScaledObject) time.Duration {
+	if scaledObject.Spec.Advanced == nil || scaledObject.Spec.Advanced.ActivationFailureGracePeriod == nil {
+		return 0
+	}
+	return scaledObject.Spec.Advanced.ActivationFailureGracePeriod.Duration
+}
+
+// startActivationAttempt holds the scale to zero of the scale target activated from zero until one of its pods,
+// matched with the selector of its scale subresource, is ready or the activationFailureGracePeriod expires
+func (e *scaleExecutor) startActivationAttempt(logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, selector string) {
+	if selector == "" {
+		logger.V(1).Info("The ScaleTarget has no selector, its scale to zero isn't held until one of its pods is ready")
+		return
+	}
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		logger.Error(err, "Error parsing the selector of the ScaleTarget")
+		return
+	}
+	e.activationsLock.Lock()
+	defer e.activationsLock.Unlock()
+	if e.activations == nil {
+		e.activations = map[types.NamespacedName]activationAttempt{}
+	}
+	e.activations[types.NamespacedName{Namespace: scaledObject.Namespace, Name: scaledObject.Name}] = activationAttempt{selector: parsed, activated: e.now()}
+}
+
+func (e *scaleExecutor) forgetActivationAttempt(scaledObject *kedav1alpha1.ScaledObject) {
+	e.activationsLock.Lock()
+	defer e.activationsLock.Unlock()
+	delete(e.activations, types.NamespacedName{Namespace: scaledObject.Namespace, Name: scaledObject.Name})
+}
+
+// holdForActivationAttempt returns true while the scale target activated from zero must not be scaled back to zero,
+// none of its pods is ready and the activationFailureGracePeriod hasn't expired. A warning event is emitted once
+// the grace period expires, the scale target is scaled to zero from then on
+func (e *scaleExecutor) holdForActivationAttempt(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) bool {
+	e.activationsLock.Lock()
+	attempt, found := e.activations[types.NamespacedName{Namespace: scaledObject.Namespace, Name: scaledObject.Name}]
+	e.activationsLock.Unlock()
+	if !found {
+		return false
+	}
+
+	gracePeriod := getActivationFailureGracePeriod(scaledObject)
+	if gracePeriod <= 0 {
+		e.forgetActivationAttempt(scaledObject)
+		return false
+	}
+
+	ready, err := e.isAnyPodReady(ctx, scaledObject.Namespace, attempt.selector)
+	if err != nil {
+		// the readiness is unknown, the grace period still applies
+		logger.Error(err, "Error listing the pods of the ScaleTarget")
+	}
+	if ready {
+		e.forgetActivationAttempt(scaledObject)
+		return false
+	}
+
+	if e.now().Sub(attempt.activated) < gracePeriod {
+		logger.V(1).Info("ScaleTarget held until one of its pods is ready after its activation",
+			"ActivatedAt", attempt.activated,
+			"ActivationFailureGracePeriod", gracePeriod)
+		return true
+	}
+
+	e.forgetActivationAttempt(scaledObject)
+	logger.Info("No pod of the ScaleTarget became ready within the activationFailureGracePeriod", "ActivationFailureGracePeriod", gracePeriod)
+	e.recorder.Eventf(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScaleTargetActivationGracePeriodExceeded,
+		"No pod of %s %s/%s became ready within %s after its activation", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, gracePeriod)
+	return false
+}
+
+// isAnyPodReady returns true if one of the pods matched by the selector is ready
+func (e *scaleExecutor) isAnyPodReady(ctx context.Context, namespace string, selector labels.Selector) (bool, error) {
+	pods := &corev1.PodList{}
+	if err := e.client.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, err
+	}
+	for i := range pods.Items {
+		if pods.Items[i].DeletionTimestamp != nil {
+			continue
+		}
+		if _, ready := getPodReadyTime(&pods.Items[i]); ready {
+			return true, nil
+		}
+	}
+	return false, nil
+}
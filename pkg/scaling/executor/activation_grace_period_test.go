@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_scale"
+)
+
+// activationGraceTest is a scale target with a scale subresource and the pods listed by the executor
+type activationGraceTest struct {
+	executor     *scaleExecutor
+	recorder     *record.FakeRecorder
+	scaledObject *v1alpha1.ScaledObject
+	replicas     int32
+	pods         []corev1.Pod
+	now          time.Time
+}
+
+func newActivationGraceTest(t *testing.T) *activationGraceTest {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+
+	minReplicas := int32(0)
+	cooldownPeriod := int32(60)
+	test := &activationGraceTest{
+		recorder: record.NewFakeRecorder(10),
+		scaledObject: &v1alpha1.ScaledObject{
+			ObjectMeta: v1.ObjectMeta{Name: "slow-start", Namespace: "namespace"},
+			Spec: v1alpha1.ScaledObjectSpec{
+				ScaleTargetRef:  &v1alpha1.ScaleTarget{Name: "worker"},
+				MinReplicaCount: &minReplicas,
+				CooldownPeriod:  &cooldownPeriod,
+				Advanced:        &v1alpha1.AdvancedConfig{ActivationFailureGracePeriod: &v1.Duration{Duration: 5 * time.Minute}},
+			},
+			Status: v1alpha1.ScaledObjectStatus{
+				ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{Group: "example.com", Kind: "Worker"},
+				ScaleTargetKind: "example.com/v1.Worker",
+				Conditions:      *v1alpha1.GetInitializedConditions(),
+			},
+		},
+		now: time.Unix(1000, 0),
+	}
+	test.executor = NewScaleExecutor(client, mockScaleClient, nil, test.recorder).(*scaleExecutor)
+	test.executor.now = func() time.Time { return test.now }
+
+	mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).AnyTimes()
+	mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, interface{}, string, v1.GetOptions) (*autoscalingv1.Scale, error) {
+			return &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: test.replicas}, Status: autoscalingv1.ScaleStatus{Selector: "app=worker"}}, nil
+		}).AnyTimes()
+	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ interface{}, scale *autoscalingv1.Scale, _ v1.UpdateOptions) (*autoscalingv1.Scale, error) {
+			test.replicas = scale.Spec.Replicas
+			return scale, nil
+		}).AnyTimes()
+	client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, list *corev1.PodList, opts ...runtimeclient.ListOption) error {
+			listOptions := &runtimeclient.ListOptions{}
+			listOptions.ApplyOptions(opts)
+			for _, pod := range test.pods {
+				if pod.Namespace == listOptions.Namespace && listOptions.LabelSelector.Matches(labels.Set(pod.Labels)) {
+					list.Items = append(list.Items, pod)
+				}
+			}
+			return nil
+		}).AnyTimes()
+	client.EXPECT().Status().Return(statusWriter).AnyTimes()
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	return test
+}
+
+// deactivate requests the scale to zero of the scale target once its cooldown period is over
+func (test *activationGraceTest) deactivate() {
+	test.scaledObject.Status.LastActiveTime = &v1.Time{Time: time.Now().Add(-time.Hour)}
+	test.executor.RequestScale(context.TODO(), test.scaledObject, false, false, &ScaleExecutorOptions{})
+}
+
+func (test *activationGraceTest) addPod(name string, ready bool) {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	test.pods = append(test.pods, corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "namespace", Labels: map[string]string{"app": "worker"}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	})
+}
+
+func TestActivationFailureGracePeriodSlowReadyPod(t *testing.T) {
+	test := newActivationGraceTest(t)
+
+	test.executor.RequestScale(context.TODO(), test.scaledObject, true, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(1), test.replicas)
+	assert.Contains(t, <-test.recorder.Events, "KEDAScaleTargetActivated")
+
+	// the image of the pod is still being pulled after the cooldown period
+	test.addPod("pulling", false)
+	test.now = test.now.Add(2 * time.Minute)
+	test.deactivate()
+	assert.Equal(t, int32(1), test.replicas)
+	assert.Empty(t, test.recorder.Events)
+
+	// the scale target is scaled to zero once its pod is ready
+	test.pods = nil
+	test.addPod("pulling", true)
+	test.now = test.now.Add(2 * time.Minute)
+	test.deactivate()
+	assert.Equal(t, int32(0), test.replicas)
+	assert.Contains(t, <-test.recorder.Events, "KEDAScaleTargetDeactivated")
+	assert.Empty(t, test.executor.activations)
+}
+
+func TestActivationFailureGracePeriodNeverReadyPod(t *testing.T) {
+	test := newActivationGraceTest(t)
+	test.addPod("crashing", false)
+	// a ready pod of another workload
+	test.pods = append(test.pods, corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: "other", Namespace: "namespace", Labels: map[string]string{"app": "other"}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	})
+
+	test.executor.RequestScale(context.TODO(), test.scaledObject, true, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(1), test.replicas)
+	assert.Contains(t, <-test.recorder.Events, "KEDAScaleTargetActivated")
+
+	test.now = test.now.Add(4 * time.Minute)
+	test.deactivate()
+	assert.Equal(t, int32(1), test.replicas)
+
+	// the grace period expired without a ready pod
+	test.now = test.now.Add(2 * time.Minute)
+	test.deactivate()
+	assert.Equal(t, int32(0), test.replicas)
+	assert.Contains(t, <-test.recorder.Events, "KEDAScaleTargetActivationGracePeriodExceeded")
+	assert.Contains(t, <-test.recorder.Events, "KEDAScaleTargetDeactivated")
+	assert.Empty(t, test.executor.activations)
+}
+
+func TestActivationFailureGracePeriodNotSet(t *testing.T) {
+	test := newActivationGraceTest(t)
+	test.scaledObject.Spec.Advanced = nil
+	test.addPod("pulling", false)
+
+	test.executor.RequestScale(context.TODO(), test.scaledObject, true, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(1), test.replicas)
+
+	test.deactivate()
+	assert.Equal(t, int32(0), test.replicas)
+}
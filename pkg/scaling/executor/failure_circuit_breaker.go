@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultFailureCircuitBreakerWindow   = 10 * time.Minute
+	defaultFailureCircuitBreakerCooldown = 5 * time.Minute
+)
+
+// circuitBreakerState is the state of the failure circuit breaker of a ScaledJob
+type circuitBreakerState struct {
+	// opened is when the breaker opened, zero while it's closed
+	opened time.Time
+	// closed is when the breaker last closed, the jobs failed before aren't counted again
+	closed time.Time
+}
+
+// getFailureCircuitBreakerPeriods returns the window and the cooldown of the failure circuit breaker with their defaults
+func getFailureCircuitBreakerPeriods(breaker *kedav1alpha1.FailureCircuitBreaker) (time.Duration, time.Duration) {
+	window := defaultFailureCircuitBreakerWindow
+	if breaker.Window != nil && breaker.Window.Duration > 0 {
+		window = breaker.Window.Duration
+	}
+	cooldown := defaultFailureCircuitBreakerCooldown
+	if breaker.Cooldown != nil && breaker.Cooldown.Duration > 0 {
+		cooldown = breaker.Cooldown.Duration
+	}
+	return window, cooldown
+}
+
+// getJobFinishedTime returns the type and the time of the Complete or Failed condition of the job, the job is still
+// running or retrying its failed pods within its backoffLimit when there is none
+func getJobFinishedTime(job *batchv1.Job) (batchv1.JobConditionType, time.Time) {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return c.Type, c.LastTransitionTime.Time
+		}
+	}
+	return "", time.Time{}
+}
+
+// isFailureCircuitBreakerOpen returns true while no new job of the ScaledJob must be created. The breaker opens when
+// more than failureThreshold jobs failed within the window, it closes once the cooldown elapsed or a job succeeded.
+// The jobs failed before the breaker last closed aren't counted again, so it doesn't open again right away
+func (e *scaleExecutor) isFailureCircuitBreakerOpen(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob) bool {
+	key := types.NamespacedName{Namespace: scaledJob.Namespace, Name: scaledJob.Name}
+	breaker := scaledJob.Spec.ScalingStrategy.FailureCircuitBreaker
+	condition := scaledJob.Status.Conditions.GetCircuitBreakerOpenCondition()
+	if breaker == nil {
+		e.forgetCircuitBreaker(key)
+		if condition.IsTrue() {
+			e.setCircuitBreakerOpenCondition(ctx, logger, scaledJob, metav1.ConditionFalse, kedav1alpha1.ScaledJobConditionCircuitBreakerDisabledReason,
+				"The failure circuit breaker was removed, new jobs are created")
+		}
+		return false
+	}
+	window, cooldown := getFailureCircuitBreakerPeriods(breaker)
+	now := e.now()
+
+	e.circuitBreakersLock.Lock()
+	state, found := e.circuitBreakers[key]
+	e.circuitBreakersLock.Unlock()
+	if !found && condition.IsTrue() {
+		// the breaker was opened before a restart of the operator, its cooldown starts again
+		state.opened = now
+		e.storeCircuitBreaker(key, state)
+	}
+
+	jobs := &batchv1.JobList{}
+	err := e.client.List(ctx, jobs, runtimeclient.InNamespace(scaledJob.GetNamespace()),
+		runtimeclient.MatchingLabels(map[string]string{"scaledjob.keda.sh/name": scaledJob.GetName()}))
+	if err != nil {
+		logger.Error(err, "Error listing the jobs of the ScaledJob for the failure circuit breaker")
+		return !state.opened.IsZero()
+	}
+
+	if !state.opened.IsZero() {
+		reason, message := "", ""
+		switch {
+		case hasJobSucceededSince(jobs, state.opened):
+			reason, message = kedav1alpha1.ScaledJobConditionCircuitBreakerJobSucceededReason, "A job succeeded, new jobs are created again"
+		case now.Sub(state.opened) >= cooldown:
+			reason, message = kedav1alpha1.ScaledJobConditionCircuitBreakerCooldownElapsedReason, fmt.Sprintf("The cooldown of %s elapsed, new jobs are created again", cooldown)
+		default:
+			logger.V(1).Info("The failure circuit breaker is open, no new job is created", "OpenedAt", state.opened, "Cooldown", cooldown)
+			return true
+		}
+		e.storeCircuitBreaker(key, circuitBreakerState{closed: now})
+		logger.Info("Closing the failure circuit breaker", "reason", reason)
+		e.recorder.Event(scaledJob, corev1.EventTypeNormal, eventreason.ScaledJobCircuitBreakerClosed, message)
+		e.setCircuitBreakerOpenCondition(ctx, logger, scaledJob, metav1.ConditionFalse, reason, message)
+		return false
+	}
+
+	since := now.Add(-window)
+	if state.closed.After(since) {
+		since = state.closed
+	}
+	failed := countJobsFailedSince(jobs, since)
+	if failed <= int(breaker.FailureThreshold) {
+		return false
+	}
+
+	e.storeCircuitBreaker(key, circuitBreakerState{opened: now})
+	message := fmt.Sprintf("%d jobs failed within %s, no new job is created for %s or until a job succeeds", failed, window, cooldown)
+	logger.Info("Opening the failure circuit breaker", "FailedJobs", failed, "FailureThreshold", breaker.FailureThreshold)
+	e.recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.ScaledJobCircuitBreakerOpened, message)
+	e.setCircuitBreakerOpenCondition(ctx, logger, scaledJob, metav1.ConditionTrue, kedav1alpha1.ScaledJobConditionCircuitBreakerOpenReason, message)
+	return true
+}
+
+func countJobsFailedSince(jobs *batchv1.JobList, since time.Time) int {
+	failed := 0
+	for i := range jobs.Items {
+		if finishedType, finished := getJobFinishedTime(&jobs.Items[i]); finishedType == batchv1.JobFailed && finished.After(since) {
+			failed++
+		}
+	}
+	return failed
+}
+
+func hasJobSucceededSince(jobs *batchv1.JobList, since time.Time) bool {
+	for i := range jobs.Items {
+		if finishedType, finished := getJobFinishedTime(&jobs.Items[i]); finishedType == batchv1.JobComplete && !finished.Before(since) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *scaleExecutor) storeCircuitBreaker(key types.NamespacedName, state circuitBreakerState) {
+	e.circuitBreakersLock.Lock()
+	defer e.circuitBreakersLock.Unlock()
+	if e.circuitBreakers == nil {
+		e.circuitBreakers = map[types.NamespacedName]circuitBreakerState{}
+	}
+	e.circuitBreakers[key] = state
+}
+
+func (e *scaleExecutor) forgetCircuitBreaker(key types.NamespacedName) {
+	e.circuitBreakersLock.Lock()
+	defer e.circuitBreakersLock.Unlock()
+	delete(e.circuitBreakers, key)
+}
+
+// setCircuitBreakerOpenCondition sets the CircuitBreakerOpen condition of the ScaledJob, it's added on the first transition
+func (e *scaleExecutor) setCircuitBreakerOpenCondition(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob, status metav1.ConditionStatus, reason string, message string) {
+	transform := func(runtimeObj runtimeclient.Object, target interface{}) error {
+		condition, ok := target.(kedav1alpha1.Condition)
+		if !ok {
+			return fmt.Errorf("transform target is not kedav1alpha1.Condition type %v", target)
+		}
+		if obj, ok := runtimeObj.(*kedav1alpha1.ScaledJob); ok {
+			obj.Status.Conditions.SetCircuitBreakerOpenCondition(condition.Status, condition.Reason, condition.Message)
+		}
+		return nil
+	}
+	condition := kedav1alpha1.Condition{Type: kedav1alpha1.ConditionCircuitBreakerOpen, Status: status, Reason: reason, Message: message}
+	if err := kedautil.TransformObject(ctx, e.client, logger, scaledJob, condition, transform); err != nil {
+		logger.Error(err, "Error setting the CircuitBreakerOpen condition")
+	}
+}
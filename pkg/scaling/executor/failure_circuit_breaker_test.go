@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+)
+
+// circuitBreakerTest is a ScaledJob with a failure circuit breaker and the jobs listed by the executor
+type circuitBreakerTest struct {
+	executor  *scaleExecutor
+	recorder  *record.FakeRecorder
+	scaledJob *kedav1alpha1.ScaledJob
+	jobs      []batchv1.Job
+	now       time.Time
+}
+
+func newCircuitBreakerTest(t *testing.T) *circuitBreakerTest {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+	test := &circuitBreakerTest{
+		recorder: record.NewFakeRecorder(10),
+		scaledJob: &kedav1alpha1.ScaledJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "poison", Namespace: "namespace"},
+			Spec: kedav1alpha1.ScaledJobSpec{
+				JobTargetRef: &batchv1.JobSpec{},
+				ScalingStrategy: kedav1alpha1.ScalingStrategy{
+					FailureCircuitBreaker: &kedav1alpha1.FailureCircuitBreaker{
+						FailureThreshold: 2,
+						Window:           &metav1.Duration{Duration: 10 * time.Minute},
+						Cooldown:         &metav1.Duration{Duration: 5 * time.Minute},
+					},
+				},
+			},
+			Status: kedav1alpha1.ScaledJobStatus{Conditions: *kedav1alpha1.GetInitializedConditions()},
+		},
+		now: time.Unix(100000, 0),
+	}
+	test.executor = NewScaleExecutor(client, nil, nil, test.recorder).(*scaleExecutor)
+	test.executor.now = func() time.Time { return test.now }
+
+	client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, list runtimeclient.ObjectList, _ ...runtimeclient.ListOption) error {
+			if jobs, ok := list.(*batchv1.JobList); ok {
+				jobs.Items = append(jobs.Items, test.jobs...)
+			}
+			return nil
+		}).AnyTimes()
+	client.EXPECT().Status().Return(statusWriter).AnyTimes()
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	return test
+}
+
+// addJob adds a job finished with the condition the given time ago, a job without condition is still running
+func (test *circuitBreakerTest) addJob(conditionType batchv1.JobConditionType, ago time.Duration) {
+	job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("poison-%d", len(test.jobs)), Namespace: "namespace"}}
+	if conditionType != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type:               conditionType,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(test.now.Add(-ago)),
+		}}
+	}
+	test.jobs = append(test.jobs, job)
+}
+
+func (test *circuitBreakerTest) isOpen() bool {
+	return test.executor.isFailureCircuitBreakerOpen(context.TODO(), logf.Log.WithName("test"), test.scaledJob)
+}
+
+func (test *circuitBreakerTest) assertCondition(t *testing.T, status metav1.ConditionStatus, reason string) {
+	condition := test.scaledJob.Status.Conditions.GetCircuitBreakerOpenCondition()
+	assert.Equal(t, status, condition.Status)
+	assert.Equal(t, reason, condition.Reason)
+}
+
+func (test *circuitBreakerTest) assertEvent(t *testing.T, reason string) {
+	select {
+	case event := <-test.recorder.Events:
+		assert.Contains(t, event, reason)
+	default:
+		t.Errorf("Expected a %s event", reason)
+	}
+}
+
+func TestFailureCircuitBreakerOpensAboveThreshold(t *testing.T) {
+	test := newCircuitBreakerTest(t)
+	test.addJob(batchv1.JobFailed, time.Minute)
+	test.addJob(batchv1.JobFailed, 2*time.Minute)
+	// failed before the window
+	test.addJob(batchv1.JobFailed, 20*time.Minute)
+	// retrying its failed pods within its backoffLimit
+	test.addJob("", 0)
+	test.addJob(batchv1.JobComplete, 3*time.Minute)
+
+	assert.False(t, test.isOpen(), "the failures within the window don't exceed the threshold")
+	assert.Empty(t, test.scaledJob.Status.Conditions.GetCircuitBreakerOpenCondition().Type)
+
+	test.addJob(batchv1.JobFailed, 0)
+	assert.True(t, test.isOpen())
+	test.assertCondition(t, metav1.ConditionTrue, kedav1alpha1.ScaledJobConditionCircuitBreakerOpenReason)
+	test.assertEvent(t, "ScaledJobCircuitBreakerOpened")
+
+	test.now = test.now.Add(4 * time.Minute)
+	assert.True(t, test.isOpen(), "the breaker is open until the end of the cooldown")
+}
+
+func TestFailureCircuitBreakerClosesAfterCooldown(t *testing.T) {
+	test := newCircuitBreakerTest(t)
+	for i := 0; i < 3; i++ {
+		test.addJob(batchv1.JobFailed, time.Minute)
+	}
+	assert.True(t, test.isOpen())
+	test.assertEvent(t, "ScaledJobCircuitBreakerOpened")
+
+	test.now = test.now.Add(5 * time.Minute)
+	assert.False(t, test.isOpen())
+	test.assertCondition(t, metav1.ConditionFalse, kedav1alpha1.ScaledJobConditionCircuitBreakerCooldownElapsedReason)
+	test.assertEvent(t, "ScaledJobCircuitBreakerClosed")
+
+	// the failures counted before aren't counted again while they're still within the window
+	test.now = test.now.Add(time.Minute)
+	assert.False(t, test.isOpen())
+
+	for i := 0; i < 3; i++ {
+		test.addJob(batchv1.JobFailed, 0)
+	}
+	assert.True(t, test.isOpen(), "the failures after the cooldown open the breaker again")
+	test.assertCondition(t, metav1.ConditionTrue, kedav1alpha1.ScaledJobConditionCircuitBreakerOpenReason)
+}
+
+func TestFailureCircuitBreakerClosesWhenJobSucceeds(t *testing.T) {
+	test := newCircuitBreakerTest(t)
+	for i := 0; i < 3; i++ {
+		test.addJob(batchv1.JobFailed, time.Minute)
+	}
+	assert.True(t, test.isOpen())
+
+	test.now = test.now.Add(time.Minute)
+	test.addJob(batchv1.JobComplete, 0)
+	assert.False(t, test.isOpen())
+	test.assertCondition(t, metav1.ConditionFalse, kedav1alpha1.ScaledJobConditionCircuitBreakerJobSucceededReason)
+}
+
+func TestFailureCircuitBreakerRemoved(t *testing.T) {
+	test := newCircuitBreakerTest(t)
+	for i := 0; i < 3; i++ {
+		test.addJob(batchv1.JobFailed, time.Minute)
+	}
+	assert.True(t, test.isOpen())
+
+	test.scaledJob.Spec.ScalingStrategy.FailureCircuitBreaker = nil
+	assert.False(t, test.isOpen())
+	test.assertCondition(t, metav1.ConditionFalse, kedav1alpha1.ScaledJobConditionCircuitBreakerDisabledReason)
+	assert.Empty(t, test.executor.circuitBreakers)
+}
+
+func TestFailureCircuitBreakerOpenBeforeRestart(t *testing.T) {
+	test := newCircuitBreakerTest(t)
+	test.scaledJob.Status.Conditions.SetCircuitBreakerOpenCondition(metav1.ConditionTrue, kedav1alpha1.ScaledJobConditionCircuitBreakerOpenReason, "")
+
+	assert.True(t, test.isOpen(), "the breaker stays open with the cooldown started again")
+	test.now = test.now.Add(5 * time.Minute)
+	assert.False(t, test.isOpen())
+}
+
+func TestRequestJobScaleWithOpenFailureCircuitBreaker(t *testing.T) {
+	test := newCircuitBreakerTest(t)
+	for i := 0; i < 3; i++ {
+		test.addJob(batchv1.JobFailed, time.Minute)
+	}
+
+	// the mock client fails the test on any Create of a job
+	test.executor.RequestJobScale(context.TODO(), test.scaledJob, true, 5, 5, 0)
+	test.assertCondition(t, metav1.ConditionTrue, kedav1alpha1.ScaledJobConditionCircuitBreakerOpenReason)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+// firstPodReadyCheckInterval is how often the activations without a ready pod are checked for the timeout
+const firstPodReadyCheckInterval = 10 * time.Second
+
+// firstPodReady is the tracker of the activated scale targets, nil while keda_scaledobject_first_pod_ready_seconds
+// is disabled
+var firstPodReady atomic.Pointer[FirstPodReadyTracker]
+
+// SetFirstPodReadyTracker sets the tracker the activations of the scale targets are reported to
+func SetFirstPodReadyTracker(tracker *FirstPodReadyTracker) {
+	firstPodReady.Store(tracker)
+}
+
+type pendingActivation struct {
+	selector  labels.Selector
+	activated time.Time
+}
+
+// FirstPodReadyTracker records the time from the activation of the scale target of a ScaledObject to its first
+// ready pod, the pods are matched with the selector of the scale subresource. Activations without a ready pod
+// within the timeout are counted and forgotten
+type FirstPodReadyTracker struct {
+	timeout time.Duration
+	logger  logr.Logger
+	now     func() time.Time
+
+	lock    sync.Mutex
+	pending map[types.NamespacedName]pendingActivation
+}
+
+// NewFirstPodReadyTracker creates a tracker waiting up to timeout for the first ready pod of an activated scale target
+func NewFirstPodReadyTracker(timeout time.Duration, logger logr.Logger) (*FirstPodReadyTracker, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("the timeout of the first ready pod must be positive, %s given", timeout)
+	}
+	return &FirstPodReadyTracker{
+		timeout: timeout,
+		logger:  logger,
+		now:     time.Now,
+		pending: map[types.NamespacedName]pendingActivation{},
+	}, nil
+}
+
+// PodEventHandler returns the handler to add to the pod informer
+func (t *FirstPodReadyTracker) PodEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: t.podChanged,
+		UpdateFunc: func(_, newObj interface{}) {
+			t.podChanged(newObj)
+		},
+	}
+}
+
+// Start expires the activations without a ready pod until the context is done, this implements the Runnable
+// interface of controller-runtime Manager, so we can use mgr.Add() to start this component.
+func (t *FirstPodReadyTracker) Start(ctx context.Context) error {
+	ticker := time.NewTicker(firstPodReadyCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.expire()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection returns false as the activations are reported by the scale loops of the replica
+func (t *FirstPodReadyTracker) NeedLeaderElection() bool {
+	return false
+}
+
+// activated starts waiting for the first ready pod of the scale target of the ScaledObject
+func (t *FirstPodReadyTracker) activated(scaledObject *kedav1alpha1.ScaledObject, selector string) {
+	if selector == "" {
+		t.logger.V(1).Info("The scale target has no selector, its first ready pod isn't tracked", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
+		return
+	}
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		t.logger.Error(err, "error parsing the selector of the scale target", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.pending[types.NamespacedName{Namespace: scaledObject.Namespace, Name: scaledObject.Name}] = pendingActivation{selector: parsed, activated: t.now()}
+}
+
+// deactivated stops waiting for the first ready pod of the scale target of the ScaledObject
+func (t *FirstPodReadyTracker) deactivated(scaledObject *kedav1alpha1.ScaledObject) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.pending, types.NamespacedName{Namespace: scaledObject.Namespace, Name: scaledObject.Name})
+}
+
+func (t *FirstPodReadyTracker) podChanged(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.DeletionTimestamp != nil {
+		return
+	}
+	readyTime, ready := getPodReadyTime(pod)
+	if !ready {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for key, activation := range t.pending {
+		// the pods are created after the activation, the creation timestamp is truncated to the second
+		if key.Namespace != pod.Namespace || pod.CreationTimestamp.Time.Before(activation.activated.Truncate(time.Second)) ||
+			!activation.selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		observed := readyTime
+		if observed.Before(activation.activated) {
+			observed = t.now()
+		}
+		prommetrics.RecordScaledObjectFirstPodReady(key.Namespace, key.Name, observed.Sub(activation.activated))
+		delete(t.pending, key)
+	}
+}
+
+func (t *FirstPodReadyTracker) expire() {
+	now := t.now()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for key, activation := range t.pending {
+		if now.Sub(activation.activated) >= t.timeout {
+			t.logger.Info("No pod of the activated scale target became ready within the timeout", "scaledObject.Namespace", key.Namespace, "scaledObject.Name", key.Name, "timeout", t.timeout)
+			prommetrics.RecordScaledObjectFirstPodReadyTimeout(key.Namespace, key.Name)
+			delete(t.pending, key)
+		}
+	}
+}
+
+// getPodReadyTime returns the time the pod became ready, if it's ready
+func getPodReadyTime(pod *corev1.Pod) (time.Time, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.LastTransitionTime.Time, condition.Status == corev1.ConditionTrue
+		}
+	}
+	return time.Time{}, false
+}
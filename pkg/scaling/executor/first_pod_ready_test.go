@@ -0,0 +1,178 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	"github.com/kedacore/keda/v2/pkg/mock/mock_scale"
+)
+
+func newFirstPodReadyTestTracker(t *testing.T, now *time.Time) *FirstPodReadyTracker {
+	t.Helper()
+	tracker, err := NewFirstPodReadyTracker(time.Minute, logr.Discard())
+	require.NoError(t, err)
+	tracker.now = func() time.Time { return *now }
+	SetFirstPodReadyTracker(tracker)
+	t.Cleanup(func() { SetFirstPodReadyTracker(nil) })
+	return tracker
+}
+
+func newFirstPodReadyTestPod(name string, labels map[string]string, created time.Time, readySince *time.Time) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "first-pod-ready", Labels: labels, CreationTimestamp: v1.NewTime(created)},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}},
+	}
+	if readySince != nil {
+		pod.Status.Conditions[0] = corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: v1.NewTime(*readySince)}
+	}
+	return pod
+}
+
+func TestFirstPodReadyAfterActivation(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tracker := newFirstPodReadyTestTracker(t, &now)
+
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, record.NewFakeRecorder(10))
+
+	scaledObject := &v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{Name: "activated", Namespace: "first-pod-ready"},
+		Spec:       v1alpha1.ScaledObjectSpec{ScaleTargetRef: &v1alpha1.ScaleTarget{Name: "worker"}},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{Group: "example.com", Kind: "Worker"},
+			Conditions:      *v1alpha1.GetInitializedConditions(),
+		},
+	}
+	mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).AnyTimes()
+	mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		&autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Selector: "app=worker"}}, nil)
+	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	client.EXPECT().Status().Return(statusWriter).AnyTimes()
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	// the histogram is process global, so the observations are compared against the ones before the test
+	countBefore := getFirstPodReadyCount(t, "activated")
+	sumBefore := getFirstPodReadySum(t, "activated")
+
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, true, false, &ScaleExecutorOptions{})
+	handler := tracker.PodEventHandler()
+
+	// a pod of another workload and a pod of the scale target which isn't ready yet
+	readySince := now.Add(5 * time.Second)
+	handler.OnAdd(newFirstPodReadyTestPod("other", map[string]string{"app": "other"}, now, &readySince), false)
+	pod := newFirstPodReadyTestPod("worker", map[string]string{"app": "worker"}, now, nil)
+	handler.OnAdd(pod, false)
+	assert.Equal(t, countBefore, getFirstPodReadyCount(t, "activated"))
+
+	now = now.Add(30 * time.Second)
+	readyPod := newFirstPodReadyTestPod("worker", map[string]string{"app": "worker"}, pod.CreationTimestamp.Time, &readySince)
+	handler.OnUpdate(pod, readyPod)
+	assert.Equal(t, countBefore+1, getFirstPodReadyCount(t, "activated"))
+	assert.Equal(t, sumBefore+5.0, getFirstPodReadySum(t, "activated"))
+
+	// only the first ready pod is observed
+	handler.OnAdd(newFirstPodReadyTestPod("second", map[string]string{"app": "worker"}, now, &now), false)
+	assert.Equal(t, countBefore+1, getFirstPodReadyCount(t, "activated"))
+}
+
+func TestFirstPodReadyTimeout(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tracker := newFirstPodReadyTestTracker(t, &now)
+	scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "never-ready", Namespace: "first-pod-ready"}}
+	deactivated := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "deactivated", Namespace: "first-pod-ready"}}
+	// the collectors are process global, so the values are compared against the ones before the test
+	timeoutsBefore := getFirstPodReadyTimeouts(t, "never-ready")
+	deactivatedBefore := getFirstPodReadyTimeouts(t, "deactivated")
+	countBefore := getFirstPodReadyCount(t, "never-ready")
+
+	tracker.activated(scaledObject, "app=never-ready")
+	tracker.activated(deactivated, "app=deactivated")
+	tracker.deactivated(deactivated)
+
+	now = now.Add(30 * time.Second)
+	tracker.expire()
+	assert.Equal(t, timeoutsBefore, getFirstPodReadyTimeouts(t, "never-ready"))
+
+	now = now.Add(30 * time.Second)
+	tracker.expire()
+	assert.Equal(t, timeoutsBefore+1, getFirstPodReadyTimeouts(t, "never-ready"))
+	assert.Equal(t, deactivatedBefore, getFirstPodReadyTimeouts(t, "deactivated"))
+
+	// the pod ready after the timeout isn't observed
+	handler := tracker.PodEventHandler()
+	handler.OnAdd(newFirstPodReadyTestPod("late", map[string]string{"app": "never-ready"}, now, &now), false)
+	assert.Equal(t, countBefore, getFirstPodReadyCount(t, "never-ready"))
+}
+
+func TestNewFirstPodReadyTrackerTimeout(t *testing.T) {
+	_, err := NewFirstPodReadyTracker(0, logr.Discard())
+	assert.Error(t, err)
+}
+
+func getFirstPodReadyCount(t *testing.T, scaledObject string) uint64 {
+	return getFirstPodReadyMetric(t, "keda_scaledobject_first_pod_ready_seconds", scaledObject).GetHistogram().GetSampleCount()
+}
+
+func getFirstPodReadySum(t *testing.T, scaledObject string) float64 {
+	return getFirstPodReadyMetric(t, "keda_scaledobject_first_pod_ready_seconds", scaledObject).GetHistogram().GetSampleSum()
+}
+
+func getFirstPodReadyTimeouts(t *testing.T, scaledObject string) float64 {
+	return getFirstPodReadyMetric(t, "keda_scaledobject_first_pod_ready_timeouts_total", scaledObject).GetCounter().GetValue()
+}
+
+// getFirstPodReadyMetric returns the series of the ScaledObject in the first-pod-ready namespace, nil if there is none
+func getFirstPodReadyMetric(t *testing.T, name, scaledObject string) *dto.Metric {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "first-pod-ready" && labels["scaledObject"] == scaledObject {
+				return metric
+			}
+		}
+	}
+	return nil
+}
@@ -19,16 +19,20 @@ package executor
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/scale"
 	"k8s.io/client-go/tools/record"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
@@ -39,8 +43,16 @@ const (
 
 // ScaleExecutor contains methods RequestJobScale and RequestScale
 type ScaleExecutor interface {
-	RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64)
-	RequestScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool)
+	RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64, triggerIndex int)
+	RequestScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, options *ScaleExecutorOptions)
+}
+
+// ScaleExecutorOptions holds the optional information about the state of the scaled object used by RequestScale
+type ScaleExecutorOptions struct {
+	// DesiredReplicas is the replica count needed by the current metric values, 0 if it isn't known
+	DesiredReplicas int32
+	// TriggersActivity is the activity of the triggers queried without error by metric name
+	TriggersActivity map[string]bool
 }
 
 type scaleExecutor struct {
@@ -49,6 +61,19 @@ type scaleExecutor struct {
 	reconcilerScheme *runtime.Scheme
 	logger           logr.Logger
 	recorder         record.EventRecorder
+	now              func() time.Time
+
+	// the activations from zero waiting for a ready pod with advanced.activationFailureGracePeriod
+	activationsLock sync.Mutex
+	activations     map[types.NamespacedName]activationAttempt
+
+	// the activity of the triggers at the last poll of the ScaledObjects with the perTrigger cooldownPolicy
+	triggersActivityLock sync.Mutex
+	triggersActivity     map[types.NamespacedName]map[string]bool
+
+	// the failure circuit breakers of the ScaledJobs with scalingStrategy.failureCircuitBreaker
+	circuitBreakersLock sync.Mutex
+	circuitBreakers     map[types.NamespacedName]circuitBreakerState
 }
 
 // NewScaleExecutor creates a ScaleExecutor object
@@ -59,6 +84,10 @@ func NewScaleExecutor(client runtimeclient.Client, scaleClient scale.ScalesGette
 		reconcilerScheme: reconcilerScheme,
 		logger:           logf.Log.WithName("scaleexecutor"),
 		recorder:         recorder,
+		now:              time.Now,
+		activations:      map[types.NamespacedName]activationAttempt{},
+		circuitBreakers:  map[types.NamespacedName]circuitBreakerState{},
+		triggersActivity: map[types.NamespacedName]map[string]bool{},
 	}
 }
 
@@ -81,6 +110,34 @@ func (e *scaleExecutor) updateLastActiveTime(ctx context.Context, logger logr.Lo
 	return kedautil.TransformObject(ctx, e.client, logger, object, now, transform)
 }
 
+func (e *scaleExecutor) setTriggersLastActiveTime(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, lastActiveTimes map[string]metav1.Time) error {
+	transform := func(runtimeObj runtimeclient.Object, target interface{}) error {
+		lastActiveTimes, ok := target.(map[string]metav1.Time)
+		if !ok {
+			return fmt.Errorf("transform target is not map[string]metav1.Time type %v", target)
+		}
+		if obj, ok := runtimeObj.(*kedav1alpha1.ScaledObject); ok {
+			obj.Status.TriggersLastActiveTime = lastActiveTimes
+		}
+		return nil
+	}
+	return kedautil.TransformObject(ctx, e.client, logger, scaledObject, lastActiveTimes, transform)
+}
+
+func (e *scaleExecutor) setRolloutPause(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, rolloutPause *kedav1alpha1.RolloutPauseStatus) error {
+	transform := func(runtimeObj runtimeclient.Object, target interface{}) error {
+		rolloutPause, ok := target.(*kedav1alpha1.RolloutPauseStatus)
+		if !ok {
+			return fmt.Errorf("transform target is not *kedav1alpha1.RolloutPauseStatus type %v", target)
+		}
+		if obj, ok := runtimeObj.(*kedav1alpha1.ScaledObject); ok {
+			obj.Status.RolloutPause = rolloutPause
+		}
+		return nil
+	}
+	return kedautil.TransformObject(ctx, e.client, logger, scaledObject, rolloutPause, transform)
+}
+
 func (e *scaleExecutor) setCondition(ctx context.Context, logger logr.Logger, object interface{}, status metav1.ConditionStatus, reason string, message string, setCondition func(kedav1alpha1.Conditions, metav1.ConditionStatus, string, string)) error {
 	type transformStruct struct {
 		status  metav1.ConditionStatus
@@ -110,14 +167,30 @@ func (e *scaleExecutor) setReadyCondition(ctx context.Context, logger logr.Logge
 	active := func(conditions kedav1alpha1.Conditions, status metav1.ConditionStatus, reason string, message string) {
 		conditions.SetReadyCondition(status, reason, message)
 	}
-	return e.setCondition(ctx, logger, object, status, reason, message, active)
+	if err := e.setCondition(ctx, logger, object, status, reason, message, active); err != nil {
+		return err
+	}
+	recordConditionMetrics(object, kedav1alpha1.ConditionReady, reason)
+	return nil
 }
 
 func (e *scaleExecutor) setActiveCondition(ctx context.Context, logger logr.Logger, object interface{}, status metav1.ConditionStatus, reason string, message string) error {
 	active := func(conditions kedav1alpha1.Conditions, status metav1.ConditionStatus, reason string, message string) {
 		conditions.SetActiveCondition(status, reason, message)
 	}
-	return e.setCondition(ctx, logger, object, status, reason, message, active)
+	if err := e.setCondition(ctx, logger, object, status, reason, message, active); err != nil {
+		return err
+	}
+	recordConditionMetrics(object, kedav1alpha1.ConditionActive, reason)
+	return nil
+}
+
+// recordConditionMetrics records the condition reason the scale loop gave a ScaledObject, the reconciliation doesn't
+// follow the status changes so the condition metrics would be stale until the next one otherwise
+func recordConditionMetrics(object interface{}, condition kedav1alpha1.ConditionType, reason string) {
+	if scaledObject, ok := object.(*kedav1alpha1.ScaledObject); ok {
+		prommetrics.RecordScaledObjectCondition(scaledObject.Namespace, scaledObject.Name, string(condition), reason)
+	}
 }
 
 func (e *scaleExecutor) setFallbackCondition(ctx context.Context, logger logr.Logger, object interface{}, status metav1.ConditionStatus, reason string, message string) error {
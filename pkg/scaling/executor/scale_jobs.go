@@ -30,15 +30,19 @@ import (
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	version "github.com/kedacore/keda/v2/version"
 )
 
 const (
 	defaultSuccessfulJobsHistoryLimit = int32(100)
 	defaultFailedJobsHistoryLimit     = int32(100)
+
+	// triggerIndexAnnotation records on each created Job the index of the ScaledJob trigger that caused the scale-up
+	triggerIndexAnnotation = "scaledjob.keda.sh/trigger-index"
 )
 
-func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64) {
+func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64, triggerIndex int) {
 	logger := e.logger.WithValues("scaledJob.Name", scaledJob.Name, "scaledJob.Namespace", scaledJob.Namespace)
 
 	runningJobCount := e.getRunningJobCount(ctx, scaledJob)
@@ -52,6 +56,8 @@ func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1al
 		effectiveMaxScale = 0
 	}
 
+	circuitBreakerOpen := e.isFailureCircuitBreakerOpen(ctx, logger, scaledJob)
+
 	if isActive {
 		logger.V(1).Info("At least one scaler is active")
 		now := metav1.Now()
@@ -60,7 +66,11 @@ func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1al
 		if err != nil {
 			logger.Error(err, "Failed to update last active time")
 		}
-		e.createJobs(ctx, logger, scaledJob, scaleTo, effectiveMaxScale)
+		if circuitBreakerOpen {
+			logger.Info("The failure circuit breaker is open, no new job is created")
+		} else {
+			e.createJobs(ctx, logger, scaledJob, scaleTo, effectiveMaxScale, triggerIndex)
+		}
 	} else {
 		logger.V(1).Info("No change in activity")
 	}
@@ -95,13 +105,19 @@ func (e *scaleExecutor) getScalingDecision(scaledJob *kedav1alpha1.ScaledJob, ru
 		scaleTo = scaleToMinReplica
 		effectiveMaxScale = scaleToMinReplica
 	} else {
-		effectiveMaxScale = NewScalingStrategy(logger, scaledJob).GetEffectiveMaxScale(maxScale, runningJobCount-minReplicaCount, pendingJobCount, scaledJob.MaxReplicaCount())
+		strategy := NewScalingStrategy(logger, scaledJob)
+		effectiveMaxScale = strategy.GetEffectiveMaxScale(maxScale, runningJobCount-minReplicaCount, pendingJobCount, scaledJob.MaxReplicaCount())
+		if _, ok := strategy.(accurateScalingStrategy); ok {
+			prommetrics.RecordScaledJobAccurateBacklog(scaledJob.Namespace, scaledJob.Name, effectiveMaxScale)
+		} else {
+			prommetrics.DeleteScaledJobAccurateBacklog(scaledJob.Namespace, scaledJob.Name)
+		}
 	}
 	return effectiveMaxScale, scaleTo
 }
 
-func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob, scaleTo int64, maxScale int64) {
-	scaledJob.Spec.JobTargetRef.Template.GenerateName = scaledJob.GetName() + "-"
+func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob, scaleTo int64, maxScale int64, triggerIndex int) {
+	scaledJob.Spec.JobTargetRef.Template.GenerateName = scaledJob.JobGenerateName()
 	if scaledJob.Spec.JobTargetRef.Template.Labels == nil {
 		scaledJob.Spec.JobTargetRef.Template.Labels = map[string]string{}
 	}
@@ -124,13 +140,27 @@ func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scal
 	for key, value := range scaledJob.ObjectMeta.Labels {
 		labels[key] = value
 	}
+	for key, value := range scaledJob.Spec.JobLabels {
+		labels[key] = value
+	}
+	// the running Jobs are listed with this label, it can't be overridden
+	labels["scaledjob.keda.sh/name"] = scaledJob.GetName()
+
+	annotations := map[string]string{}
+	for key, value := range scaledJob.Spec.JobAnnotations {
+		annotations[key] = value
+	}
+	if triggerIndex >= 0 {
+		annotations[triggerIndexAnnotation] = strconv.Itoa(triggerIndex)
+	}
 
 	for i := 0; i < int(scaleTo); i++ {
 		job := &batchv1.Job{
 			ObjectMeta: metav1.ObjectMeta{
-				GenerateName: scaledJob.GetName() + "-",
+				GenerateName: scaledJob.JobGenerateName(),
 				Namespace:    scaledJob.GetNamespace(),
 				Labels:       labels,
+				Annotations:  annotations,
 			},
 			Spec: *scaledJob.Spec.JobTargetRef.DeepCopy(),
 		}
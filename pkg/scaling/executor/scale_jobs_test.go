@@ -19,20 +19,25 @@ package executor
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 )
 
 func TestCleanUpNormalCase(t *testing.T) {
@@ -213,6 +218,45 @@ func TestRunningJobCountGreaterOrEqualMinReplicaCountExecutesScalingStrategy(t *
 	assert.Equal(t, int64(2), scaleTo)
 }
 
+func TestAccurateScalingStrategyRecordsBacklog(t *testing.T) {
+	scaleExecutor := getMockScaleExecutor(nil)
+	scaledJob := getMockScaledJobWithStrategy("accurate-backlog", "accurate", 0, "0")
+	scaledJob.Namespace = "test-namespace"
+	maxReplicaCount := int32(10)
+	scaledJob.Spec.MaxReplicaCount = &maxReplicaCount
+	t.Cleanup(func() { prommetrics.DeleteScaledJobAccurateBacklog("test-namespace", "accurate-backlog") })
+
+	// 5 messages in the queue with 2 pending jobs
+	effectiveMaxScale, _ := scaleExecutor.getScalingDecision(scaledJob, 4, 5, 5, 2, scaleExecutor.logger)
+	assert.Equal(t, int64(3), effectiveMaxScale)
+	assertAccurateBacklog(t, "accurate-backlog", "3")
+
+	// the running jobs reach the max replica count
+	effectiveMaxScale, _ = scaleExecutor.getScalingDecision(scaledJob, 8, 5, 5, 0, scaleExecutor.logger)
+	assert.Equal(t, int64(2), effectiveMaxScale)
+	assertAccurateBacklog(t, "accurate-backlog", "2")
+
+	// the backlog is removed when the scaled job switches to another strategy
+	scaledJob.Spec.ScalingStrategy.Strategy = "default"
+	scaleExecutor.getScalingDecision(scaledJob, 4, 5, 5, 2, scaleExecutor.logger)
+	assertAccurateBacklog(t, "accurate-backlog", "")
+}
+
+func assertAccurateBacklog(t *testing.T, scaledJob string, expectedValue string) {
+	t.Helper()
+	expected := ""
+	if expectedValue != "" {
+		expected = fmt.Sprintf(`
+# HELP keda_scaledjob_accurate_backlog Number of jobs the accurate scaling strategy of the scaled job computed from the queue length, the running and the pending jobs
+# TYPE keda_scaledjob_accurate_backlog gauge
+keda_scaledjob_accurate_backlog{namespace="test-namespace",scaledJob="%s"} %s
+`, scaledJob, expectedValue)
+	}
+	if err := testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "keda_scaledjob_accurate_backlog"); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestCleanUpDefaultValue(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -286,6 +330,79 @@ func TestGetPendingJobCount(t *testing.T) {
 	}
 }
 
+func TestCreateJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+
+	testData := []struct {
+		name                string
+		jobNamePrefix       string
+		jobLabels           map[string]string
+		jobAnnotations      map[string]string
+		triggerIndex        int
+		expectedName        string
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:                "defaults",
+			triggerIndex:        -1,
+			expectedName:        "azure-storage-queue-consumer-",
+			expectedLabels:      map[string]string{"team": "storage", "scaledjob.keda.sh/name": "azure-storage-queue-consumer"},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name:                "templated",
+			jobNamePrefix:       "queue-worker",
+			jobLabels:           map[string]string{"team": "queues", "tier": "batch", "scaledjob.keda.sh/name": "other"},
+			jobAnnotations:      map[string]string{"owner": "queues"},
+			triggerIndex:        1,
+			expectedName:        "queue-worker-",
+			expectedLabels:      map[string]string{"team": "queues", "tier": "batch", "scaledjob.keda.sh/name": "azure-storage-queue-consumer"},
+			expectedAnnotations: map[string]string{"owner": "queues", "scaledjob.keda.sh/trigger-index": "1"},
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			scaledJob := getMockScaledJobWithDefault()
+			scaledJob.ObjectMeta.Namespace = "default"
+			scaledJob.ObjectMeta.Labels = map[string]string{"team": "storage"}
+			scaledJob.Spec.JobTargetRef = &batchv1.JobSpec{}
+			scaledJob.Spec.JobNamePrefix = data.jobNamePrefix
+			scaledJob.Spec.JobLabels = data.jobLabels
+			scaledJob.Spec.JobAnnotations = data.jobAnnotations
+
+			var createdJobs []*batchv1.Job
+			client := mock_client.NewMockClient(ctrl)
+			client.EXPECT().
+				Create(gomock.Any(), gomock.Any()).Do(func(_ context.Context, obj runtimeclient.Object, _ ...runtimeclient.CreateOption) {
+				createdJobs = append(createdJobs, obj.(*batchv1.Job))
+			}).
+				Return(nil).Times(2)
+
+			scaleExecutor := NewScaleExecutor(client, nil, scheme, record.NewFakeRecorder(1)).(*scaleExecutor)
+			scaleExecutor.createJobs(context.Background(), logf.Log.WithName("ScaledJobTest"), scaledJob, 2, 5, data.triggerIndex)
+
+			assert.Len(t, createdJobs, 2)
+			for _, job := range createdJobs {
+				assert.Equal(t, data.expectedName, job.GenerateName)
+				assert.Equal(t, "default", job.Namespace)
+				for key, value := range data.expectedLabels {
+					assert.Equal(t, value, job.Labels[key], "label %s", key)
+				}
+				assert.Equal(t, "keda-operator", job.Labels["app.kubernetes.io/managed-by"])
+				assert.Equal(t, data.expectedAnnotations, job.Annotations)
+				assert.Equal(t, v1.RestartPolicyOnFailure, job.Spec.Template.Spec.RestartPolicy)
+				assert.Equal(t, "azure-storage-queue-consumer", job.OwnerReferences[0].Name)
+			}
+		})
+	}
+}
+
 type mockJobParameter struct {
 	Name             string
 	CompletionTime   string
@@ -24,6 +24,7 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,10 +32,11 @@ import (
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
-func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool) {
+func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool, options *ScaleExecutorOptions) {
 	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
 		"scaledObject.Namespace", scaledObject.Namespace,
 		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
@@ -43,6 +45,8 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 	// to reduce API calls. Everything else uses the scale subresource.
 	var currentScale *autoscalingv1.Scale
 	var currentReplicas int32
+	// only the rollouts of Deployments and StatefulSets are detected
+	var rollingOut bool
 	targetName := scaledObject.Spec.ScaleTargetRef.Name
 	targetGVKR := scaledObject.Status.ScaleTargetGVKR
 	switch {
@@ -54,6 +58,7 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 			return
 		}
 		currentReplicas = *deployment.Spec.Replicas
+		rollingOut = isDeploymentRollingOut(deployment)
 	case targetGVKR.Group == "apps" && targetGVKR.Kind == "StatefulSet":
 		statefulSet := &appsv1.StatefulSet{}
 		err := e.client.Get(ctx, client.ObjectKey{Name: targetName, Namespace: scaledObject.Namespace}, statefulSet)
@@ -62,6 +67,7 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 			return
 		}
 		currentReplicas = *statefulSet.Spec.Replicas
+		rollingOut = isStatefulSetRollingOut(statefulSet)
 	default:
 		var err error
 		currentScale, err = e.getScaleTargetScale(ctx, scaledObject)
@@ -82,6 +88,15 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 		}
 	}
 
+	e.updateTriggersLastActiveTime(ctx, logger, scaledObject, options)
+
+	// In dry-run mode the scale target isn't scaled at all, we only report what would be done
+	if kedacontrollerutil.IsDryRun(scaledObject) {
+		e.dryRunScale(ctx, logger, scaledObject, currentReplicas, isActive, isError, options)
+		e.updateActiveCondition(ctx, logger, scaledObject, isActive)
+		return
+	}
+
 	// Check if we are paused, and if we are then update the scale to the desired count.
 	pausedCount, err := GetPausedReplicaCount(scaledObject)
 	if err != nil {
@@ -117,6 +132,14 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 		return
 	}
 
+	rolloutPaused := e.updateRolloutPause(ctx, logger, scaledObject, rollingOut)
+
+	if scaledObject.IsActivationOnly() {
+		e.activationOnlyScale(ctx, logger, scaledObject, currentScale, currentReplicas, isActive, isError, rolloutPaused)
+		e.updateActiveCondition(ctx, logger, scaledObject, isActive)
+		return
+	}
+
 	// if scaledObject.Spec.MinReplicaCount is not set, then set the default value (0)
 	minReplicas := int32(0)
 	if scaledObject.Spec.MinReplicaCount != nil {
@@ -135,8 +158,12 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 			// AND
 			// replica count is equal to 0
 
-			// Scale the ScaleTarget up
-			e.scaleFromZeroOrIdle(ctx, logger, scaledObject, currentScale)
+			// Scale the ScaleTarget up, unless it's being rolled out
+			if rolloutPaused {
+				e.holdDuringRollout(ctx, logger, scaledObject, isActive)
+				break
+			}
+			e.scaleFromZeroOrIdle(ctx, logger, scaledObject, currentScale, options)
 		case isError:
 			// some triggers are active, but some responded with error
 
@@ -150,6 +177,7 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 			}
 		default:
 			// triggers are active, but we didn't need to scale (replica count > 0)
+			e.checkHPAPolicyOverride(ctx, logger, scaledObject, currentReplicas, options)
 
 			// update LastActiveTime to now
 			err := e.updateLastActiveTime(ctx, logger, scaledObject)
@@ -191,7 +219,12 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 			// AND
 			// there is no minimum configured or minimum is set to ZERO
 
-			// Try to scale the deployment down, HPA will handle other scale in operations
+			// Try to scale the deployment down, HPA will handle other scale in operations,
+			// unless it's being rolled out
+			if rolloutPaused {
+				e.holdDuringRollout(ctx, logger, scaledObject, isActive)
+				break
+			}
 			e.scaleToZeroOrIdle(ctx, logger, scaledObject, currentScale)
 		case currentReplicas < minReplicas && scaledObject.Spec.IdleReplicaCount == nil:
 			// there are no active triggers
@@ -215,22 +248,139 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 		}
 	}
 
+	e.updateActiveCondition(ctx, logger, scaledObject, isActive)
+}
+
+// activationOnlyScale sets the replicas of the scale target of an activation only ScaledObject, there is no HPA
+// so it's activationReplicaCount while the triggers are active and 0 once they have been inactive for the cooldownPeriod
+func (e *scaleExecutor) activationOnlyScale(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale, currentReplicas int32, isActive bool, isError bool, rolloutPaused bool) {
+	switch {
+	case rolloutPaused && !isError && (isActive || currentReplicas > 0):
+		e.holdDuringRollout(ctx, logger, scaledObject, isActive)
+	case isActive && currentReplicas != getActivationReplicaCount(scaledObject, nil):
+		e.scaleFromZeroOrIdle(ctx, logger, scaledObject, scale, nil)
+	case isActive:
+		if err := e.updateLastActiveTime(ctx, logger, scaledObject); err != nil {
+			logger.Error(err, "Error updating last active time")
+		}
+	case isError:
+		// the scale target is left as it is while the activity of the triggers is unknown
+		msg := "Triggers defined in ScaledObject are not working correctly"
+		logger.V(1).Info(msg)
+		if readyCondition := scaledObject.Status.Conditions.GetReadyCondition(); !readyCondition.IsFalse() {
+			if err := e.setReadyCondition(ctx, logger, scaledObject, metav1.ConditionFalse, "TriggerError", msg); err != nil {
+				logger.Error(err, "error setting ready condition")
+			}
+		}
+	case currentReplicas > 0:
+		e.scaleToZeroOrIdle(ctx, logger, scaledObject, scale)
+	default:
+		logger.V(1).Info("ScaleTarget no change")
+	}
+}
+
+// updateRolloutPause returns whether the scaling to and from zero is paused because the scale target is being rolled
+// out with pauseDuringRollout, and reports the start and the end of the pause in the status of the ScaledObject
+func (e *scaleExecutor) updateRolloutPause(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, rollingOut bool) bool {
+	current := scaledObject.Status.RolloutPause
+	if !scaledObject.IsPausedDuringRollout() {
+		if current != nil {
+			if err := e.setRolloutPause(ctx, logger, scaledObject, nil); err != nil {
+				logger.Error(err, "Error clearing the rollout pause")
+			}
+		}
+		return false
+	}
+	if current != nil && current.Active == rollingOut {
+		return rollingOut
+	}
+
+	if err := e.setRolloutPause(ctx, logger, scaledObject, &kedav1alpha1.RolloutPauseStatus{Active: rollingOut, LastTransitionTime: metav1.Now()}); err != nil {
+		logger.Error(err, "Error updating the rollout pause")
+	}
+	switch {
+	case rollingOut:
+		logger.Info("Pausing the scaling to and from zero during the rollout of the ScaleTarget")
+		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.RolloutPauseStarted, "Paused the scaling to and from zero during the rollout of %s %s/%s", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name)
+	case current != nil:
+		logger.Info("Resuming the scaling to and from zero after the rollout of the ScaleTarget")
+		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.RolloutPauseEnded, "Resumed the scaling to and from zero after the rollout of %s %s/%s", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name)
+	}
+	return rollingOut
+}
+
+// holdDuringRollout leaves the scale target at its replica count while it's being rolled out, the activity of the
+// triggers still counts so the cooldown period starts from the end of their activity once the rollout is complete
+func (e *scaleExecutor) holdDuringRollout(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, isActive bool) {
+	logger.V(1).Info("ScaleTarget held during its rollout", "isActive", isActive)
+	if isActive {
+		if err := e.updateLastActiveTime(ctx, logger, scaledObject); err != nil {
+			logger.Error(err, "Error updating last active time")
+		}
+	}
+}
+
+// isDeploymentRollingOut returns true while the pods of the previous replica sets of the Deployment are being replaced,
+// a rollout which exceeded its progress deadline isn't in progress anymore
+func isDeploymentRollingOut(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type != appsv1.DeploymentProgressing {
+			continue
+		}
+		if condition.Status == corev1.ConditionFalse {
+			return false
+		}
+		// the deployment controller reports NewReplicaSetAvailable once the rollout is complete
+		if condition.Status == corev1.ConditionTrue && condition.Reason != "NewReplicaSetAvailable" {
+			return true
+		}
+	}
+	return deployment.Status.UpdatedReplicas < deployment.Status.Replicas
+}
+
+// isStatefulSetRollingOut returns true while the pods of the StatefulSet aren't all at its update revision
+func isStatefulSetRollingOut(statefulSet *appsv1.StatefulSet) bool {
+	if statefulSet.Status.UpdateRevision != "" && statefulSet.Status.CurrentRevision != statefulSet.Status.UpdateRevision {
+		return true
+	}
+	return statefulSet.Status.UpdatedReplicas < statefulSet.Status.Replicas
+}
+
+// updateActiveCondition sets the active condition of the ScaledObject if the activity of the triggers changed
+func (e *scaleExecutor) updateActiveCondition(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, isActive bool) {
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
 	if condition.IsUnknown() || condition.IsTrue() != isActive {
 		if isActive {
 			if err := e.setActiveCondition(ctx, logger, scaledObject, metav1.ConditionTrue, "ScalerActive", "Scaling is performed because triggers are active"); err != nil {
 				logger.Error(err, "Error setting active condition when triggers are active")
-				return
 			}
 		} else {
 			if err := e.setActiveCondition(ctx, logger, scaledObject, metav1.ConditionFalse, "ScalerNotActive", "Scaling is not performed because triggers are not active"); err != nil {
 				logger.Error(err, "Error setting active condition when triggers are not active")
-				return
 			}
 		}
 	}
 }
 
+// dryRunScale reports the replica count the scale target would be scaled to by KEDA and the HPA, without scaling it
+func (e *scaleExecutor) dryRunScale(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, currentReplicas int32, isActive bool, isError bool, options *ScaleExecutorOptions) {
+	replicas := getDryRunDesiredReplicas(scaledObject, currentReplicas, isActive, isError, options)
+	prommetrics.RecordScaledObjectDryRunDesiredReplicas(scaledObject.Namespace, scaledObject.Name, replicas)
+
+	if replicas != currentReplicas {
+		logger.Info("Not scaling ScaleTarget in dry-run mode",
+			"Current Replicas Count", currentReplicas,
+			"Desired Replicas Count", replicas)
+		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.DryRunScaleTarget, "DryRun: would scale %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, replicas)
+	}
+
+	if isActive {
+		if err := e.updateLastActiveTime(ctx, logger, scaledObject); err != nil {
+			logger.Error(err, "Error updating last active time")
+		}
+	}
+}
+
 func (e *scaleExecutor) doFallbackScaling(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, currentScale *autoscalingv1.Scale, logger logr.Logger, currentReplicas int32) {
 	_, err := e.updateScaleOnScaleTarget(ctx, scaledObject, currentScale, scaledObject.Spec.Fallback.Replicas)
 	if err == nil {
@@ -246,20 +396,18 @@ func (e *scaleExecutor) doFallbackScaling(ctx context.Context, scaledObject *ked
 // An object will be scaled down to 0 only if it's passed its cooldown period
 // or if LastActiveTime is nil
 func (e *scaleExecutor) scaleToZeroOrIdle(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale) {
-	var cooldownPeriod time.Duration
-
-	if scaledObject.Spec.CooldownPeriod != nil {
-		cooldownPeriod = time.Second * time.Duration(*scaledObject.Spec.CooldownPeriod)
-	} else {
-		cooldownPeriod = time.Second * time.Duration(defaultCooldownPeriod)
-	}
-
 	// LastActiveTime can be nil if the ScaleTarget was scaled outside of KEDA.
 	// In this case we will ignore the cooldown period and scale it down
-	if scaledObject.Status.LastActiveTime == nil ||
-		scaledObject.Status.LastActiveTime.Add(cooldownPeriod).Before(time.Now()) {
+	cooldownEnd := getCooldownEnd(scaledObject)
+	if cooldownEnd == nil ||
+		cooldownEnd.Before(time.Now()) {
 		// or last time a trigger was active was > cooldown period, so scale in.
 
+		// the scale target activated from zero is given time to get a ready pod
+		if e.holdForActivationAttempt(ctx, logger, scaledObject) {
+			return
+		}
+
 		idleValue, scaleToReplicas := getIdleOrMinimumReplicaCount(scaledObject)
 
 		currentReplicas, err := e.updateScaleOnScaleTarget(ctx, scaledObject, scale, scaleToReplicas)
@@ -271,6 +419,10 @@ func (e *scaleExecutor) scaleToZeroOrIdle(ctx context.Context, logger logr.Logge
 				msg += " minReplicaCount"
 			}
 			logger.Info(msg, "Original Replicas Count", currentReplicas, "New Replicas Count", scaleToReplicas)
+			if tracker := firstPodReady.Load(); tracker != nil && scaleToReplicas == 0 {
+				tracker.deactivated(scaledObject)
+			}
+			e.forgetActivationAttempt(scaledObject)
 
 			e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAScaleTargetDeactivated,
 				"Deactivated %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, scaleToReplicas)
@@ -285,7 +437,7 @@ func (e *scaleExecutor) scaleToZeroOrIdle(ctx context.Context, logger logr.Logge
 	} else {
 		logger.V(1).Info("ScaleTarget cooling down",
 			"LastActiveTime", scaledObject.Status.LastActiveTime,
-			"CoolDownPeriod", cooldownPeriod)
+			"CooldownEnd", cooldownEnd)
 
 		activeCondition := scaledObject.Status.Conditions.GetActiveCondition()
 		if !activeCondition.IsFalse() || activeCondition.Reason != "ScalerCooldown" {
@@ -297,13 +449,29 @@ func (e *scaleExecutor) scaleToZeroOrIdle(ctx context.Context, logger logr.Logge
 	}
 }
 
-func (e *scaleExecutor) scaleFromZeroOrIdle(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale) {
+func (e *scaleExecutor) scaleFromZeroOrIdle(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale, options *ScaleExecutorOptions) {
 	var replicas int32
 	if scaledObject.Spec.MinReplicaCount != nil && *scaledObject.Spec.MinReplicaCount > 0 {
 		replicas = *scaledObject.Spec.MinReplicaCount
 	} else {
 		replicas = 1
 	}
+	if activationReplicas := getActivationReplicaCount(scaledObject, options); activationReplicas > replicas {
+		replicas = activationReplicas
+	}
+	if scaledObject.IsActivationOnly() {
+		// neither the minReplicaCount nor the metric values apply without an HPA
+		replicas = getActivationReplicaCount(scaledObject, nil)
+	}
+
+	tracker := firstPodReady.Load()
+	gracePeriod := getActivationFailureGracePeriod(scaledObject)
+	if (tracker != nil || gracePeriod > 0) && scale == nil {
+		// the pods of the scale target are found with the selector of its scale subresource
+		if targetScale, err := e.getScaleTargetScale(ctx, scaledObject); err == nil {
+			scale = targetScale
+		}
+	}
 
 	currentReplicas, err := e.updateScaleOnScaleTarget(ctx, scaledObject, scale, replicas)
 
@@ -311,6 +479,12 @@ func (e *scaleExecutor) scaleFromZeroOrIdle(ctx context.Context, logger logr.Log
 		logger.Info("Successfully updated ScaleTarget",
 			"Original Replicas Count", currentReplicas,
 			"New Replicas Count", replicas)
+		if tracker != nil && currentReplicas == 0 && scale != nil {
+			tracker.activated(scaledObject, scale.Status.Selector)
+		}
+		if gracePeriod > 0 && currentReplicas == 0 && scale != nil {
+			e.startActivationAttempt(logger, scaledObject, scale.Status.Selector)
+		}
 		e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAScaleTargetActivated, "Scaled %s %s/%s from %d to %d", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, replicas)
 
 		// Scale was successful. Update lastScaleTime and lastActiveTime on the scaledObject
@@ -348,6 +522,11 @@ func (e *scaleExecutor) updateScaleOnScaleTarget(ctx context.Context, scaledObje
 // getIdleOrMinimumReplicaCount returns true if the second value returned is from IdleReplicaCount
 // it returns false if it is from MinReplicaCount followed by the actual value
 func getIdleOrMinimumReplicaCount(scaledObject *kedav1alpha1.ScaledObject) (bool, int32) {
+	// the minimum of an activation only ScaledObject is always 0
+	if scaledObject.IsActivationOnly() {
+		return false, 0
+	}
+
 	if scaledObject.Spec.IdleReplicaCount != nil {
 		return true, *scaledObject.Spec.IdleReplicaCount
 	}
@@ -359,6 +538,119 @@ func getIdleOrMinimumReplicaCount(scaledObject *kedav1alpha1.ScaledObject) (bool
 	return false, *scaledObject.Spec.MinReplicaCount
 }
 
+// checkHPAPolicyOverride counts the polls where the scale target doesn't have the replica count needed by the metric values
+// because the HPA is holding it back with its stabilization window or scaling policies
+func (e *scaleExecutor) checkHPAPolicyOverride(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, currentReplicas int32, options *ScaleExecutorOptions) {
+	if options == nil || options.DesiredReplicas <= 0 || scaledObject.Status.HpaName == "" {
+		return
+	}
+
+	// the HPA keeps the replica count within its bounds regardless of its behavior
+	desiredReplicas := options.DesiredReplicas
+	if scaledObject.Spec.MinReplicaCount != nil && desiredReplicas < *scaledObject.Spec.MinReplicaCount {
+		desiredReplicas = *scaledObject.Spec.MinReplicaCount
+	}
+	maxReplicas := scaledObject.GetMaxReplicaCount()
+	if desiredReplicas > maxReplicas {
+		desiredReplicas = maxReplicas
+	}
+	if desiredReplicas == currentReplicas {
+		return
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	if err := e.client.Get(ctx, client.ObjectKey{Name: scaledObject.Status.HpaName, Namespace: scaledObject.Namespace}, hpa); err != nil {
+		logger.V(1).Info("Error getting the HPA to check its behavior", "HPA.Name", scaledObject.Status.HpaName, "error", err.Error())
+		return
+	}
+	if isHPALimitedByBehavior(hpa) {
+		logger.V(1).Info("HPA behavior overrides the replica count needed by the metric values", "currentReplicas", currentReplicas, "desiredReplicas", desiredReplicas)
+		prommetrics.RecordScaledObjectHPAPolicyOverride(scaledObject.Namespace, scaledObject.Name)
+	}
+}
+
+// isHPALimitedByBehavior returns true if the last recommendation of the HPA was changed by its stabilization window or scaling policies
+func isHPALimitedByBehavior(hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	for _, condition := range hpa.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch {
+		case condition.Type == autoscalingv2.AbleToScale && (condition.Reason == "ScaleDownStabilized" || condition.Reason == "ScaleUpStabilized"):
+			return true
+		case condition.Type == autoscalingv2.ScalingLimited && (condition.Reason == "ScaleUpLimit" || condition.Reason == "ScaleDownLimit"):
+			return true
+		}
+	}
+	return false
+}
+
+// getActivationReplicaCount returns the replica count the scale target is activated to from zero or idle,
+// advanced.activationReplicaCount bounded by maxReplicaCount and the replica count desired by the metric values
+func getActivationReplicaCount(scaledObject *kedav1alpha1.ScaledObject, options *ScaleExecutorOptions) int32 {
+	if scaledObject.Spec.Advanced == nil || scaledObject.Spec.Advanced.ActivationReplicaCount == nil {
+		return 1
+	}
+
+	replicas := *scaledObject.Spec.Advanced.ActivationReplicaCount
+	maxReplicas := scaledObject.GetMaxReplicaCount()
+	if replicas > maxReplicas {
+		replicas = maxReplicas
+	}
+	if options != nil && options.DesiredReplicas > 0 && replicas > options.DesiredReplicas {
+		replicas = options.DesiredReplicas
+	}
+	return replicas
+}
+
+// getDryRunDesiredReplicas returns the replica count the scale target would have if the ScaledObject wasn't in dry-run mode
+func getDryRunDesiredReplicas(scaledObject *kedav1alpha1.ScaledObject, currentReplicas int32, isActive bool, isError bool, options *ScaleExecutorOptions) int32 {
+	minReplicas := int32(0)
+	if scaledObject.Spec.MinReplicaCount != nil {
+		minReplicas = *scaledObject.Spec.MinReplicaCount
+	}
+	maxReplicas := scaledObject.GetMaxReplicaCount()
+
+	if !isActive {
+		if isError {
+			if scaledObject.Spec.Fallback != nil && scaledObject.Spec.Fallback.Replicas != 0 {
+				return scaledObject.Spec.Fallback.Replicas
+			}
+			return currentReplicas
+		}
+
+		_, replicas := getIdleOrMinimumReplicaCount(scaledObject)
+		if replicas >= currentReplicas {
+			return replicas
+		}
+		// the scale target is scaled to zero or idle only after the cooldown period
+		if cooldownEnd := getCooldownEnd(scaledObject); (replicas == 0 || scaledObject.Spec.IdleReplicaCount != nil) &&
+			cooldownEnd != nil && cooldownEnd.After(time.Now()) {
+			return currentReplicas
+		}
+		return replicas
+	}
+
+	replicas := currentReplicas
+	switch {
+	case options != nil && options.DesiredReplicas > 0:
+		// the HPA scales to the replica count needed by the metric values
+		replicas = options.DesiredReplicas
+	case currentReplicas == 0 || (scaledObject.Spec.IdleReplicaCount != nil && currentReplicas < minReplicas):
+		replicas = getActivationReplicaCount(scaledObject, options)
+	}
+	if replicas < minReplicas {
+		replicas = minReplicas
+	}
+	if replicas < 1 {
+		replicas = 1
+	}
+	if replicas > maxReplicas {
+		replicas = maxReplicas
+	}
+	return replicas
+}
+
 // GetPausedReplicaCount returns the paused replica count of the ScaledObject.
 // If not paused, it returns nil.
 func GetPausedReplicaCount(scaledObject *kedav1alpha1.ScaledObject) (*int32, error) {
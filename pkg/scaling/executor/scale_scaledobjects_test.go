@@ -18,20 +18,34 @@ package executor
 
 import (
 	"context"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_scale"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 )
 
+func TestMain(m *testing.M) {
+	// the scaling metrics are only gathered on the leader
+	prommetrics.RecordOperatorLeader(true)
+	os.Exit(m.Run())
+}
+
 func TestScaleToFallbackReplicasWhenNotActiveAndIsError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	client := mock_client.NewMockClient(ctrl)
@@ -87,7 +101,7 @@ func TestScaleToFallbackReplicasWhenNotActiveAndIsError(t *testing.T) {
 	client.EXPECT().Status().Times(2).Return(statusWriter)
 	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
-	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, true)
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, true, nil)
 
 	assert.Equal(t, int32(5), scale.Spec.Replicas)
 	condition := scaledObject.Status.Conditions.GetFallbackCondition()
@@ -148,7 +162,7 @@ func TestScaleToMinReplicasWhenNotActive(t *testing.T) {
 	client.EXPECT().Status().Return(statusWriter).Times(2)
 	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
-	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, false)
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, false, nil)
 
 	assert.Equal(t, minReplicas, scale.Spec.Replicas)
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
@@ -209,7 +223,7 @@ func TestScaleToMinReplicasFromLowerInitialReplicaCount(t *testing.T) {
 	client.EXPECT().Status().Return(statusWriter).Times(2)
 	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
-	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, false)
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, false, nil)
 
 	assert.Equal(t, minReplicas, scale.Spec.Replicas)
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
@@ -268,7 +282,7 @@ func TestScaleFromMinReplicasWhenActive(t *testing.T) {
 	client.EXPECT().Status().Times(2).Return(statusWriter).Times(3)
 	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 
-	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false)
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false, nil)
 
 	assert.Equal(t, int32(1), scale.Spec.Replicas)
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
@@ -331,7 +345,7 @@ func TestScaleToIdleReplicasWhenNotActive(t *testing.T) {
 	client.EXPECT().Status().Return(statusWriter).Times(2)
 	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
-	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, false)
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, false, nil)
 
 	assert.Equal(t, idleReplicas, scale.Spec.Replicas)
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
@@ -392,7 +406,7 @@ func TestScaleFromIdleToMinReplicasWhenActive(t *testing.T) {
 	client.EXPECT().Status().Times(2).Return(statusWriter).Times(3)
 	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 
-	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false)
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false, nil)
 
 	assert.Equal(t, minReplicas, scale.Spec.Replicas)
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
@@ -454,9 +468,881 @@ func TestScaleToPausedReplicasCount(t *testing.T) {
 	client.EXPECT().Status().Return(statusWriter).Times(2)
 	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 
-	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false)
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false, nil)
 
 	assert.Equal(t, pausedReplicaCount, scale.Spec.Replicas)
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
 	assert.Equal(t, false, condition.IsTrue())
 }
+
+func TestScaleFromZeroToActivationReplicasWhenActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder)
+
+	activationReplicas := int32(5)
+	currentReplicas := int32(0)
+
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+			Advanced: &v1alpha1.AdvancedConfig{
+				ActivationReplicaCount: &activationReplicas,
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+
+	client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &currentReplicas,
+		},
+	})
+
+	scale := &autoscalingv1.Scale{
+		Spec: autoscalingv1.ScaleSpec{
+			Replicas: currentReplicas,
+		},
+	}
+
+	mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).Times(2)
+	mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(scale, nil)
+	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Eq(scale), gomock.Any())
+
+	client.EXPECT().Status().Return(statusWriter).Times(3)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	// the metric values need 3 replicas
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false, &ScaleExecutorOptions{DesiredReplicas: 3})
+
+	assert.Equal(t, int32(3), scale.Spec.Replicas)
+	condition := scaledObject.Status.Conditions.GetActiveCondition()
+	assert.Equal(t, true, condition.IsTrue())
+}
+
+func TestActivationReplicasNotAppliedWhenRunning(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder)
+
+	activationReplicas := int32(5)
+	currentReplicas := int32(2)
+
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+			Advanced: &v1alpha1.AdvancedConfig{
+				ActivationReplicaCount: &activationReplicas,
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+
+	client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &currentReplicas,
+		},
+	})
+
+	// the scale target is never updated, only the status of the ScaledObject
+	client.EXPECT().Status().Return(statusWriter).Times(3)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false, &ScaleExecutorOptions{DesiredReplicas: 10})
+
+	condition := scaledObject.Status.Conditions.GetActiveCondition()
+	assert.Equal(t, true, condition.IsTrue())
+}
+
+func TestGetActivationReplicaCount(t *testing.T) {
+	int32Ptr := func(value int32) *int32 {
+		return &value
+	}
+
+	tests := []struct {
+		name               string
+		activationReplicas *int32
+		maxReplicas        *int32
+		options            *ScaleExecutorOptions
+		expected           int32
+	}{
+		{name: "not set", expected: 1},
+		{name: "not set with desired replicas", options: &ScaleExecutorOptions{DesiredReplicas: 8}, expected: 1},
+		{name: "no desired replicas", activationReplicas: int32Ptr(5), expected: 5},
+		{name: "unknown desired replicas", activationReplicas: int32Ptr(5), options: &ScaleExecutorOptions{}, expected: 5},
+		{name: "more desired replicas", activationReplicas: int32Ptr(5), options: &ScaleExecutorOptions{DesiredReplicas: 8}, expected: 5},
+		{name: "less desired replicas", activationReplicas: int32Ptr(5), options: &ScaleExecutorOptions{DesiredReplicas: 2}, expected: 2},
+		{name: "capped by maxReplicaCount", activationReplicas: int32Ptr(5), maxReplicas: int32Ptr(4), options: &ScaleExecutorOptions{DesiredReplicas: 8}, expected: 4},
+		{name: "capped by maxReplicaCount and desired replicas", activationReplicas: int32Ptr(5), maxReplicas: int32Ptr(4), options: &ScaleExecutorOptions{DesiredReplicas: 3}, expected: 3},
+		{name: "capped by default maxReplicaCount", activationReplicas: int32Ptr(500), expected: v1alpha1.DefaultMaxReplicaCount},
+	}
+
+	for _, test := range tests {
+		scaledObject := &v1alpha1.ScaledObject{
+			Spec: v1alpha1.ScaledObjectSpec{
+				MaxReplicaCount: test.maxReplicas,
+				Advanced:        &v1alpha1.AdvancedConfig{ActivationReplicaCount: test.activationReplicas},
+			},
+		}
+		assert.Equal(t, test.expected, getActivationReplicaCount(scaledObject, test.options), test.name)
+	}
+}
+
+func TestDryRunDoesNotScaleFromZeroWhenActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	// no scale subresource call is expected in dry-run mode
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder)
+
+	currentReplicas := int32(0)
+
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "dry-run-active",
+			Namespace:   "namespace",
+			Annotations: map[string]string{"autoscaling.keda.sh/dry-run": "true"},
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetKind: "apps/v1.Deployment",
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+
+	client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &currentReplicas,
+		},
+	})
+
+	// ready condition, last active time and active condition
+	client.EXPECT().Status().Return(statusWriter).Times(3)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false, &ScaleExecutorOptions{DesiredReplicas: 3})
+
+	condition := scaledObject.Status.Conditions.GetActiveCondition()
+	assert.Equal(t, true, condition.IsTrue())
+	assert.NotNil(t, scaledObject.Status.LastActiveTime)
+	assert.Equal(t, float64(3), getDryRunDesiredReplicasMetric(t, "namespace", "dry-run-active"))
+	assert.Equal(t, "Normal DryRunScaleTarget DryRun: would scale apps/v1.Deployment namespace/name from 0 to 3", <-recorder.Events)
+}
+
+func TestDryRunDoesNotScaleToZeroWhenNotActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	// no scale subresource call is expected in dry-run mode
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder)
+
+	currentReplicas := int32(2)
+
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "dry-run-not-active",
+			Namespace:   "namespace",
+			Annotations: map[string]string{"autoscaling.keda.sh/dry-run": "true"},
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetKind: "apps/v1.Deployment",
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+
+	client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &currentReplicas,
+		},
+	})
+
+	// ready condition and active condition
+	client.EXPECT().Status().Return(statusWriter).Times(2)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+	scaleExecutor.RequestScale(context.TODO(), &scaledObject, false, false, nil)
+
+	condition := scaledObject.Status.Conditions.GetActiveCondition()
+	assert.Equal(t, true, condition.IsFalse())
+	assert.Equal(t, float64(0), getDryRunDesiredReplicasMetric(t, "namespace", "dry-run-not-active"))
+	assert.Equal(t, "Normal DryRunScaleTarget DryRun: would scale apps/v1.Deployment namespace/name from 2 to 0", <-recorder.Events)
+}
+
+func TestGetDryRunDesiredReplicas(t *testing.T) {
+	int32Ptr := func(value int32) *int32 {
+		return &value
+	}
+	justActive := v1.Now()
+
+	tests := []struct {
+		name            string
+		spec            v1alpha1.ScaledObjectSpec
+		lastActiveTime  *v1.Time
+		currentReplicas int32
+		isActive        bool
+		isError         bool
+		options         *ScaleExecutorOptions
+		expected        int32
+	}{
+		{name: "activated from zero", isActive: true, expected: 1},
+		{name: "activated to minReplicaCount", spec: v1alpha1.ScaledObjectSpec{MinReplicaCount: int32Ptr(2)}, isActive: true, expected: 2},
+		{name: "scaled to desired replicas", currentReplicas: 2, isActive: true, options: &ScaleExecutorOptions{DesiredReplicas: 6}, expected: 6},
+		{name: "desired replicas capped by maxReplicaCount", spec: v1alpha1.ScaledObjectSpec{MaxReplicaCount: int32Ptr(4)}, currentReplicas: 2, isActive: true, options: &ScaleExecutorOptions{DesiredReplicas: 6}, expected: 4},
+		{name: "kept without desired replicas", currentReplicas: 3, isActive: true, expected: 3},
+		{name: "scaled to zero", currentReplicas: 3, expected: 0},
+		{name: "cooling down", currentReplicas: 3, lastActiveTime: &justActive, expected: 3},
+		{name: "scaled to idle", spec: v1alpha1.ScaledObjectSpec{IdleReplicaCount: int32Ptr(0), MinReplicaCount: int32Ptr(2)}, currentReplicas: 3, expected: 0},
+		{name: "scaled up to minReplicaCount", spec: v1alpha1.ScaledObjectSpec{MinReplicaCount: int32Ptr(2)}, currentReplicas: 1, expected: 2},
+		{name: "fallback", spec: v1alpha1.ScaledObjectSpec{Fallback: &v1alpha1.Fallback{FailureThreshold: 3, Replicas: 5}}, currentReplicas: 1, isError: true, expected: 5},
+		{name: "error without fallback", currentReplicas: 1, isError: true, expected: 1},
+	}
+
+	for _, test := range tests {
+		scaledObject := &v1alpha1.ScaledObject{
+			Spec:   test.spec,
+			Status: v1alpha1.ScaledObjectStatus{LastActiveTime: test.lastActiveTime},
+		}
+		assert.Equal(t, test.expected, getDryRunDesiredReplicas(scaledObject, test.currentReplicas, test.isActive, test.isError, test.options), test.name)
+	}
+}
+
+func getDryRunDesiredReplicasMetric(t *testing.T, namespace, scaledObject string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_dry_run_desired_replicas" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["scaledObject"] == scaledObject {
+				return metric.GetGauge().GetValue()
+			}
+		}
+	}
+	t.Fatalf("no dry-run desired replicas for %s/%s", namespace, scaledObject)
+	return 0
+}
+
+func TestHPAPolicyOverrideCounted(t *testing.T) {
+	tests := []struct {
+		name            string
+		currentReplicas int32
+		desiredReplicas int32
+		conditions      []autoscalingv2.HorizontalPodAutoscalerCondition
+		expected        float64
+	}{
+		{
+			name:            "scale down stabilized",
+			currentReplicas: 5,
+			desiredReplicas: 2,
+			conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.AbleToScale, Status: corev1.ConditionTrue, Reason: "ScaleDownStabilized"},
+				{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionFalse, Reason: "DesiredWithinRange"},
+			},
+			expected: 1,
+		},
+		{
+			name:            "scale up limited by policy",
+			currentReplicas: 2,
+			desiredReplicas: 8,
+			conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.AbleToScale, Status: corev1.ConditionTrue, Reason: "ReadyForNewScale"},
+				{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionTrue, Reason: "ScaleUpLimit"},
+			},
+			expected: 1,
+		},
+		{
+			name:            "not scaled yet without behavior",
+			currentReplicas: 2,
+			desiredReplicas: 8,
+			conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.AbleToScale, Status: corev1.ConditionTrue, Reason: "ReadyForNewScale"},
+				{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionFalse, Reason: "DesiredWithinRange"},
+			},
+			expected: 0,
+		},
+		{
+			name:            "limited by max replicas",
+			currentReplicas: 10,
+			desiredReplicas: 20,
+			conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionTrue, Reason: "TooManyReplicas"},
+			},
+			expected: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mock_client.NewMockClient(ctrl)
+			recorder := record.NewFakeRecorder(1)
+			mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+			statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+			scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder)
+
+			name := "hpa-policy-" + strings.ReplaceAll(test.name, " ", "-")
+			maxReplicas := int32(10)
+			scaledObject := v1alpha1.ScaledObject{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      name,
+					Namespace: "namespace",
+				},
+				Spec: v1alpha1.ScaledObjectSpec{
+					ScaleTargetRef: &v1alpha1.ScaleTarget{
+						Name: "name",
+					},
+					MaxReplicaCount: &maxReplicas,
+				},
+				Status: v1alpha1.ScaledObjectStatus{
+					HpaName:         "keda-hpa-" + name,
+					ScaleTargetKind: "apps/v1.Deployment",
+					ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+						Group: "apps",
+						Kind:  "Deployment",
+					},
+				},
+			}
+			scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+
+			currentReplicas := test.currentReplicas
+			client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).SetArg(2, appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &currentReplicas,
+				},
+			})
+			client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&autoscalingv2.HorizontalPodAutoscaler{})).SetArg(2, autoscalingv2.HorizontalPodAutoscaler{
+				Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+					CurrentReplicas: test.currentReplicas,
+					DesiredReplicas: test.currentReplicas,
+					Conditions:      test.conditions,
+				},
+			}).MaxTimes(1)
+
+			// ready condition, last active time and active condition
+			client.EXPECT().Status().Return(statusWriter).Times(3)
+			statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+			// the counter is process global, so only the increment of this request is asserted
+			before := getHPAPolicyOverridesMetric(t, "namespace", name)
+			scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false, &ScaleExecutorOptions{DesiredReplicas: test.desiredReplicas})
+
+			assert.Equal(t, before+test.expected, getHPAPolicyOverridesMetric(t, "namespace", name))
+		})
+	}
+}
+
+func getHPAPolicyOverridesMetric(t *testing.T, namespace, scaledObject string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_hpa_policy_overrides_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["scaledObject"] == scaledObject {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func newPerTriggerCooldownScaledObject(lastActiveTime time.Time, triggersLastActiveTime map[string]v1.Time) *v1alpha1.ScaledObject {
+	minReplicas := int32(0)
+	cooldownPeriod := int32(300)
+	scaledObject := &v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+			MinReplicaCount: &minReplicas,
+			CooldownPeriod:  &cooldownPeriod,
+			Advanced: &v1alpha1.AdvancedConfig{
+				CooldownPolicy: v1alpha1.CooldownPolicyPerTrigger,
+			},
+			Triggers: []v1alpha1.ScaleTriggers{{Type: "cron"}, {Type: "rabbitmq"}},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+			LastActiveTime:         &v1.Time{Time: lastActiveTime},
+			TriggersLastActiveTime: triggersLastActiveTime,
+			ExternalMetricNames:    []string{"s0-cron", "s1-queue"},
+		},
+	}
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+	return scaledObject
+}
+
+func TestPerTriggerCooldownStaggeredDeactivations(t *testing.T) {
+	now := time.Now()
+	inactiveOptions := &ScaleExecutorOptions{TriggersActivity: map[string]bool{"s0-cron": false, "s1-queue": false}}
+
+	t.Run("cooling down until the last deactivated trigger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		client := mock_client.NewMockClient(ctrl)
+		statusWriter := mock_client.NewMockStatusWriter(ctrl)
+		scaleExecutor := NewScaleExecutor(client, mock_scale.NewMockScalesGetter(ctrl), nil, record.NewFakeRecorder(1))
+
+		// the queue has been inactive for an hour but the cron window closed 2 minutes ago
+		scaledObject := newPerTriggerCooldownScaledObject(now.Add(-2*time.Minute), map[string]v1.Time{
+			"s0-cron":  {Time: now.Add(-2 * time.Minute)},
+			"s1-queue": {Time: now.Add(-time.Hour)},
+		})
+		currentReplicas := int32(2)
+		client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{Replicas: &currentReplicas},
+		})
+		// the scale target isn't scaled, only the ready and active conditions are set
+		client.EXPECT().Status().Return(statusWriter).Times(2)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+		scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, inactiveOptions)
+
+		condition := scaledObject.Status.Conditions.GetActiveCondition()
+		assert.Equal(t, "ScalerCooldown", condition.Reason)
+	})
+
+	t.Run("scaled to zero once the cooldown of the trigger with its own cooldown period has passed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		client := mock_client.NewMockClient(ctrl)
+		statusWriter := mock_client.NewMockStatusWriter(ctrl)
+		mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+		mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+		scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, record.NewFakeRecorder(1))
+
+		// the cron window closed 2 minutes ago but the cron trigger has a cooldown period of a minute
+		scaledObject := newPerTriggerCooldownScaledObject(now.Add(-2*time.Minute), map[string]v1.Time{
+			"s0-cron":  {Time: now.Add(-2 * time.Minute)},
+			"s1-queue": {Time: now.Add(-time.Hour)},
+		})
+		cronCooldownPeriod := int32(60)
+		scaledObject.Spec.Triggers[0].CooldownPeriod = &cronCooldownPeriod
+		currentReplicas := int32(2)
+		client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{Replicas: &currentReplicas},
+		})
+		scale := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: currentReplicas}}
+		mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).Times(2)
+		mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(scale, nil)
+		mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Eq(scale), gomock.Any())
+		client.EXPECT().Status().Return(statusWriter).Times(2)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+		scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, inactiveOptions)
+
+		assert.Equal(t, int32(0), scale.Spec.Replicas)
+	})
+
+	t.Run("scaled to zero once every trigger has been inactive for the cooldown period", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		client := mock_client.NewMockClient(ctrl)
+		statusWriter := mock_client.NewMockStatusWriter(ctrl)
+		mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+		mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+		scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, record.NewFakeRecorder(1))
+
+		// a removed trigger was active a minute ago, which is still the global last active time
+		scaledObject := newPerTriggerCooldownScaledObject(now.Add(-time.Minute), map[string]v1.Time{
+			"s0-cron":    {Time: now.Add(-10 * time.Minute)},
+			"s1-queue":   {Time: now.Add(-time.Hour)},
+			"s2-removed": {Time: now.Add(-time.Minute)},
+		})
+		currentReplicas := int32(2)
+		client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{Replicas: &currentReplicas},
+		})
+		scale := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: currentReplicas}}
+		mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).Times(2)
+		mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(scale, nil)
+		mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Eq(scale), gomock.Any())
+		client.EXPECT().Status().Return(statusWriter).Times(3)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+		scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, inactiveOptions)
+
+		assert.Equal(t, int32(0), scale.Spec.Replicas)
+		assert.Len(t, scaledObject.Status.TriggersLastActiveTime, 2)
+		assert.NotContains(t, scaledObject.Status.TriggersLastActiveTime, "s2-removed")
+	})
+
+	t.Run("the last active time of the triggers is updated when their activity changes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		client := mock_client.NewMockClient(ctrl)
+		statusWriter := mock_client.NewMockStatusWriter(ctrl)
+		scaleExecutor := NewScaleExecutor(client, mock_scale.NewMockScalesGetter(ctrl), nil, record.NewFakeRecorder(1)).(*scaleExecutor)
+
+		cronLastActiveTime := v1.Time{Time: now.Add(-10 * time.Minute)}
+		scaledObject := newPerTriggerCooldownScaledObject(now.Add(-10*time.Minute), map[string]v1.Time{"s0-cron": cronLastActiveTime})
+		currentReplicas := int32(2)
+		client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{Replicas: &currentReplicas},
+		}).AnyTimes()
+		client.EXPECT().Status().Return(statusWriter).AnyTimes()
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+		queueActive := &ScaleExecutorOptions{TriggersActivity: map[string]bool{"s0-cron": false, "s1-queue": true}}
+		activatedTime := now.Truncate(time.Second)
+		scaleExecutor.now = func() time.Time { return activatedTime }
+		scaleExecutor.RequestScale(context.TODO(), scaledObject, true, false, queueActive)
+		assert.Equal(t, cronLastActiveTime, scaledObject.Status.TriggersLastActiveTime["s0-cron"])
+		assert.Equal(t, activatedTime, scaledObject.Status.TriggersLastActiveTime["s1-queue"].Time)
+
+		// the time isn't updated while the trigger stays active
+		scaleExecutor.now = func() time.Time { return activatedTime.Add(time.Minute) }
+		scaleExecutor.RequestScale(context.TODO(), scaledObject, true, false, queueActive)
+		assert.Equal(t, activatedTime, scaledObject.Status.TriggersLastActiveTime["s1-queue"].Time)
+
+		// it is set again when the trigger deactivates
+		deactivatedTime := activatedTime.Add(2 * time.Minute)
+		scaleExecutor.now = func() time.Time { return deactivatedTime }
+		scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, inactiveOptions)
+		assert.Equal(t, cronLastActiveTime, scaledObject.Status.TriggersLastActiveTime["s0-cron"])
+		assert.Equal(t, deactivatedTime, scaledObject.Status.TriggersLastActiveTime["s1-queue"].Time)
+	})
+}
+
+func TestGetCooldownEnd(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	scaledObject := newPerTriggerCooldownScaledObject(now, map[string]v1.Time{
+		"s0-cron":  {Time: now.Add(-2 * time.Minute)},
+		"s1-queue": {Time: now.Add(-time.Hour)},
+	})
+	assert.Equal(t, now.Add(3*time.Minute), *getCooldownEnd(scaledObject))
+
+	// the cooldown period of a trigger overrides the one of the ScaledObject
+	cronCooldownPeriod := int32(60)
+	scaledObject.Spec.Triggers[0].CooldownPeriod = &cronCooldownPeriod
+	assert.Equal(t, now.Add(-time.Minute), *getCooldownEnd(scaledObject))
+	queueCooldownPeriod := int32(7200)
+	scaledObject.Spec.Triggers[1].CooldownPeriod = &queueCooldownPeriod
+	assert.Equal(t, now.Add(time.Hour), *getCooldownEnd(scaledObject))
+
+	// the global last active time is used until a trigger has been active
+	scaledObject.Status.TriggersLastActiveTime = nil
+	assert.Equal(t, now.Add(5*time.Minute), *getCooldownEnd(scaledObject))
+
+	scaledObject.Spec.Advanced.CooldownPolicy = v1alpha1.CooldownPolicyGlobal
+	scaledObject.Status.TriggersLastActiveTime = map[string]v1.Time{"s0-cron": {Time: now.Add(-2 * time.Minute)}}
+	assert.Equal(t, now.Add(5*time.Minute), *getCooldownEnd(scaledObject))
+
+	scaledObject.Status.LastActiveTime = nil
+	assert.Nil(t, getCooldownEnd(scaledObject))
+}
+
+func TestActivationOnlyLifecycle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, record.NewFakeRecorder(10))
+
+	activationReplicas := int32(2)
+	minReplicas := int32(1)
+	cooldownPeriod := int32(300)
+	scaledObject := &v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{Name: "name", Namespace: "namespace"},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{Name: "name"},
+			// the minReplicaCount doesn't apply without an HPA
+			MinReplicaCount: &minReplicas,
+			CooldownPeriod:  &cooldownPeriod,
+			Advanced: &v1alpha1.AdvancedConfig{
+				ActivationOnly:         true,
+				ActivationReplicaCount: &activationReplicas,
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{Group: "example.com", Kind: "Toggle"},
+			Conditions:      *v1alpha1.GetInitializedConditions(),
+		},
+	}
+
+	// the custom resource is read and updated through its scale subresource
+	replicas := int32(0)
+	updates := 0
+	mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).AnyTimes()
+	mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, interface{}, string, v1.GetOptions) (*autoscalingv1.Scale, error) {
+			return &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: replicas}}, nil
+		}).AnyTimes()
+	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ interface{}, scale *autoscalingv1.Scale, _ v1.UpdateOptions) (*autoscalingv1.Scale, error) {
+			replicas = scale.Spec.Replicas
+			updates++
+			return scale, nil
+		}).AnyTimes()
+	client.EXPECT().Status().Return(statusWriter).AnyTimes()
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	// inactive at zero, nothing to do
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(0), replicas)
+	assert.Equal(t, 0, updates)
+
+	// activated to the activationReplicaCount regardless of the replica count needed by the metric values
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, true, false, &ScaleExecutorOptions{DesiredReplicas: 10})
+	assert.Equal(t, activationReplicas, replicas)
+	assert.NotNil(t, scaledObject.Status.LastActiveTime)
+	activeCondition := scaledObject.Status.Conditions.GetActiveCondition()
+	assert.True(t, activeCondition.IsTrue())
+
+	// still active, the replicas are kept
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, true, false, &ScaleExecutorOptions{DesiredReplicas: 10})
+	assert.Equal(t, 1, updates)
+
+	// inactive within the cooldown period
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, &ScaleExecutorOptions{})
+	assert.Equal(t, activationReplicas, replicas)
+	assert.Equal(t, "ScalerCooldown", scaledObject.Status.Conditions.GetActiveCondition().Reason)
+
+	// the trigger error leaves the scale target as it is
+	scaledObject.Status.LastActiveTime = &v1.Time{Time: time.Now().Add(-time.Hour)}
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, false, true, &ScaleExecutorOptions{})
+	assert.Equal(t, activationReplicas, replicas)
+	readyCondition := scaledObject.Status.Conditions.GetReadyCondition()
+	assert.True(t, readyCondition.IsFalse())
+
+	// deactivated to zero, not to the minReplicaCount, after the cooldown period
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(0), replicas)
+	assert.Equal(t, 2, updates)
+}
+
+func TestPauseDuringRollout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+	recorder := record.NewFakeRecorder(10)
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder)
+
+	minReplicas := int32(0)
+	cooldownPeriod := int32(300)
+	scaledObject := &v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{Name: "name", Namespace: "namespace"},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef:  &v1alpha1.ScaleTarget{Name: "name"},
+			MinReplicaCount: &minReplicas,
+			CooldownPeriod:  &cooldownPeriod,
+			Advanced:        &v1alpha1.AdvancedConfig{PauseDuringRollout: true},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{Group: "apps", Kind: "Deployment"},
+			ScaleTargetKind: "apps/v1.Deployment",
+			LastActiveTime:  &v1.Time{Time: time.Now().Add(-time.Hour)},
+			Conditions:      *v1alpha1.GetInitializedConditions(),
+		},
+	}
+
+	// a new replica set with maxSurge 1 is replacing 3 replicas
+	replicas := int32(3)
+	deployment := appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			Replicas:        4,
+			UpdatedReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "ReplicaSetUpdated"},
+			},
+		},
+	}
+	client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ interface{}, obj *appsv1.Deployment, _ ...interface{}) error {
+			deployment.DeepCopyInto(obj)
+			obj.Spec.Replicas = &replicas
+			return nil
+		}).AnyTimes()
+	updates := 0
+	mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).AnyTimes()
+	mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, interface{}, string, v1.GetOptions) (*autoscalingv1.Scale, error) {
+			return &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: replicas}}, nil
+		}).AnyTimes()
+	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ interface{}, scale *autoscalingv1.Scale, _ v1.UpdateOptions) (*autoscalingv1.Scale, error) {
+			replicas = scale.Spec.Replicas
+			updates++
+			return scale, nil
+		}).AnyTimes()
+	client.EXPECT().Status().Return(statusWriter).AnyTimes()
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	// the scale to zero is suppressed during the rollout
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(3), replicas)
+	assert.Equal(t, 0, updates)
+	assert.True(t, scaledObject.Status.RolloutPause.Active)
+	assert.Contains(t, <-recorder.Events, "RolloutPauseStarted")
+
+	// the scaling resumes once the new replica set is available
+	deployment.Status = appsv1.DeploymentStatus{
+		Replicas:        3,
+		UpdatedReplicas: 3,
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+		},
+	}
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, false, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(0), replicas)
+	assert.False(t, scaledObject.Status.RolloutPause.Active)
+	assert.Contains(t, <-recorder.Events, "RolloutPauseEnded")
+	assert.Contains(t, <-recorder.Events, "KEDAScaleTargetDeactivated")
+
+	// the activation is frozen during a rollout of the scaled in Deployment
+	deployment.Status = appsv1.DeploymentStatus{
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "ReplicaSetUpdated"},
+		},
+	}
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, true, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(0), replicas)
+	assert.Equal(t, 1, updates)
+	assert.True(t, scaledObject.Status.RolloutPause.Active)
+	assert.WithinDuration(t, time.Now(), scaledObject.Status.LastActiveTime.Time, time.Minute)
+	assert.Contains(t, <-recorder.Events, "RolloutPauseStarted")
+
+	// a rollout which exceeded its progress deadline doesn't hold the scaling
+	deployment.Status.Conditions = []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+	}
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, true, false, &ScaleExecutorOptions{})
+	assert.Equal(t, int32(1), replicas)
+	assert.False(t, scaledObject.Status.RolloutPause.Active)
+
+	// the status is cleared once the pause is disabled
+	scaledObject.Spec.Advanced.PauseDuringRollout = false
+	scaleExecutor.RequestScale(context.TODO(), scaledObject, true, false, &ScaleExecutorOptions{})
+	assert.Nil(t, scaledObject.Status.RolloutPause)
+}
+
+func TestIsStatefulSetRollingOut(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{Replicas: 3, UpdatedReplicas: 3, CurrentRevision: "web-1", UpdateRevision: "web-1"}}
+	assert.False(t, isStatefulSetRollingOut(statefulSet))
+
+	statefulSet.Status.UpdateRevision = "web-2"
+	statefulSet.Status.UpdatedReplicas = 1
+	assert.True(t, isStatefulSetRollingOut(statefulSet))
+}
+
+func TestConditionMetricsRecordedByScaleLoop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+	client.EXPECT().Status().Return(statusWriter).AnyTimes()
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	scaleExecutor := NewScaleExecutor(client, nil, nil, record.NewFakeRecorder(1)).(*scaleExecutor)
+
+	scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "condition-metrics", Namespace: "namespace"}}
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+	// the reconciliation recorded the ScaledObject before its triggers became active
+	prommetrics.RecordScaledObjectConditions("namespace", "condition-metrics", "ScaledObjectReady", "ScalerNotActive")
+	t.Cleanup(func() { prommetrics.DeleteScaledObjectConditions("namespace", "condition-metrics") })
+
+	scaleExecutor.updateActiveCondition(context.TODO(), logr.Discard(), scaledObject, true)
+	assert.Equal(t, float64(1), getScaledObjectsByCondition(t, "Active", "ScalerActive"))
+	assert.Equal(t, float64(0), getScaledObjectsByCondition(t, "Active", "ScalerNotActive"))
+
+	assert.NoError(t, scaleExecutor.setReadyCondition(context.TODO(), logr.Discard(), scaledObject, v1.ConditionFalse, "TriggerError", "error"))
+	assert.Equal(t, float64(1), getScaledObjectsByCondition(t, "Ready", "TriggerError"))
+	assert.Equal(t, float64(0), getScaledObjectsByCondition(t, "Ready", "ScaledObjectReady"))
+}
+
+func getScaledObjectsByCondition(t *testing.T, condition, reason string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobjects_by_condition" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["condition"] == condition && labels["reason"] == reason {
+				return metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func isPerTriggerCooldown(scaledObject *kedav1alpha1.ScaledObject) bool {
+	return scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.CooldownPolicy == kedav1alpha1.CooldownPolicyPerTrigger
+}
+
+// updateTriggersLastActiveTime sets the last active time of the triggers becoming active or inactive with the
+// perTrigger cooldownPolicy and removes the triggers which aren't metrics of the ScaledObject anymore, the status
+// isn't patched while the activity of the triggers doesn't change. The times are cleared with the global policy
+func (e *scaleExecutor) updateTriggersLastActiveTime(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, options *ScaleExecutorOptions) {
+	key := types.NamespacedName{Namespace: scaledObject.Namespace, Name: scaledObject.Name}
+	if !isPerTriggerCooldown(scaledObject) {
+		e.triggersActivityLock.Lock()
+		delete(e.triggersActivity, key)
+		e.triggersActivityLock.Unlock()
+		if scaledObject.Status.TriggersLastActiveTime != nil {
+			if err := e.setTriggersLastActiveTime(ctx, logger, scaledObject, nil); err != nil {
+				logger.Error(err, "Error clearing the last active time of the triggers")
+			}
+		}
+		return
+	}
+
+	metricNames := map[string]bool{}
+	for _, metricName := range scaledObject.Status.ExternalMetricNames {
+		metricNames[metricName] = true
+	}
+
+	changed := false
+	lastActiveTimes := map[string]metav1.Time{}
+	for metricName, lastActiveTime := range scaledObject.Status.TriggersLastActiveTime {
+		// the metric names aren't known until the HPA is created
+		if len(metricNames) > 0 && !metricNames[metricName] {
+			changed = true
+			continue
+		}
+		lastActiveTimes[metricName] = lastActiveTime
+	}
+
+	e.triggersActivityLock.Lock()
+	if e.triggersActivity == nil {
+		e.triggersActivity = map[types.NamespacedName]map[string]bool{}
+	}
+	previous := e.triggersActivity[key]
+	current := map[string]bool{}
+	if options != nil {
+		now := metav1.NewTime(e.now())
+		for metricName, isActive := range options.TriggersActivity {
+			current[metricName] = isActive
+			wasActive, known := previous[metricName]
+			_, recorded := lastActiveTimes[metricName]
+			// an active trigger is last active until it's seen inactive, its time is set again when it deactivates
+			if (isActive && (!known || !wasActive || !recorded)) || (!isActive && wasActive) {
+				lastActiveTimes[metricName] = now
+				changed = true
+			}
+		}
+	}
+	e.triggersActivity[key] = current
+	e.triggersActivityLock.Unlock()
+
+	if changed {
+		if err := e.setTriggersLastActiveTime(ctx, logger, scaledObject, lastActiveTimes); err != nil {
+			logger.Error(err, "Error updating the last active time of the triggers")
+		}
+	}
+}
+
+// getCooldownEnd returns the time the cooldown of the ScaledObject ends, nil if it has never been active. With the
+// perTrigger cooldownPolicy it's the latest end of the cooldowns of the triggers, each from its last active time
+// with its own cooldownPeriod, so a trigger stops holding the replicas once its cooldown has passed. The global
+// cooldown is used until a trigger has been active
+func getCooldownEnd(scaledObject *kedav1alpha1.ScaledObject) *time.Time {
+	if !isPerTriggerCooldown(scaledObject) || len(scaledObject.Status.TriggersLastActiveTime) == 0 {
+		if scaledObject.Status.LastActiveTime == nil {
+			return nil
+		}
+		end := scaledObject.Status.LastActiveTime.Add(getCooldownPeriod(scaledObject, nil))
+		return &end
+	}
+
+	var cooldownEnd *time.Time
+	for metricName, lastActiveTime := range scaledObject.Status.TriggersLastActiveTime {
+		end := lastActiveTime.Add(getCooldownPeriod(scaledObject, getMetricTrigger(scaledObject, metricName)))
+		if cooldownEnd == nil || end.After(*cooldownEnd) {
+			cooldownEnd = &end
+		}
+	}
+	return cooldownEnd
+}
+
+// getCooldownPeriod returns the cooldownPeriod of the trigger, the one of the ScaledObject if the trigger is nil or
+// doesn't set it
+func getCooldownPeriod(scaledObject *kedav1alpha1.ScaledObject, trigger *kedav1alpha1.ScaleTriggers) time.Duration {
+	switch {
+	case trigger != nil && trigger.CooldownPeriod != nil:
+		return time.Second * time.Duration(*trigger.CooldownPeriod)
+	case scaledObject.Spec.CooldownPeriod != nil:
+		return time.Second * time.Duration(*scaledObject.Spec.CooldownPeriod)
+	default:
+		return time.Second * time.Duration(defaultCooldownPeriod)
+	}
+}
+
+// getMetricTrigger returns the trigger of a metric from the index prefix of its name, nil if it isn't found
+func getMetricTrigger(scaledObject *kedav1alpha1.ScaledObject, metricName string) *kedav1alpha1.ScaleTriggers {
+	var index int
+	if _, err := fmt.Sscanf(metricName, "s%d-", &index); err != nil || index < 0 || index >= len(scaledObject.Spec.Triggers) {
+		return nil
+	}
+	return &scaledObject.Spec.Triggers[index]
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+// NamespaceReplicasAggregator periodically sums the current replicas of the scale targets and the maximum replica
+// counts of the ScaledObjects of each namespace, for capacity planning
+type NamespaceReplicasAggregator struct {
+	client   client.Client
+	interval time.Duration
+	logger   logr.Logger
+
+	// namespaces are the namespaces with recorded metrics
+	namespaces map[string]bool
+}
+
+// NewNamespaceReplicasAggregator creates an aggregator recording the replicas of the namespaces every interval, the
+// client should read from the cache of the manager, which already watches the ScaledObjects and their HPAs
+func NewNamespaceReplicasAggregator(client client.Client, interval time.Duration, logger logr.Logger) (*NamespaceReplicasAggregator, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("the interval of the namespace replicas aggregation must be positive, %s given", interval)
+	}
+	return &NamespaceReplicasAggregator{
+		client:     client,
+		interval:   interval,
+		logger:     logger,
+		namespaces: map[string]bool{},
+	}, nil
+}
+
+// Start records the replicas every interval until the context is done, this implements the Runnable interface
+// of controller-runtime Manager, so we can use mgr.Add() to start this component.
+func (a *NamespaceReplicasAggregator) Start(ctx context.Context) error {
+	a.logger.Info("Starting namespace replicas aggregation", "interval", a.interval)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		if err := a.Aggregate(ctx); err != nil {
+			a.logger.Error(err, "error aggregating the replicas of the namespaces")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection returns true, like the other ScaledObject metrics the replicas are only recorded by the leader
+func (a *NamespaceReplicasAggregator) NeedLeaderElection() bool {
+	return true
+}
+
+// Aggregate records the replicas of the namespaces with ScaledObjects and deletes the metrics of the other ones.
+// The current replicas are read from the status of the HPAs of the ScaledObjects, the ScaledObjects without an HPA,
+// e.g. the activation-only ones, are left out of them
+func (a *NamespaceReplicasAggregator) Aggregate(ctx context.Context) error {
+	scaledObjects := &kedav1alpha1.ScaledObjectList{}
+	if err := a.client.List(ctx, scaledObjects); err != nil {
+		return fmt.Errorf("error listing the ScaledObjects: %w", err)
+	}
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := a.client.List(ctx, hpas); err != nil {
+		return fmt.Errorf("error listing the HPAs: %w", err)
+	}
+	hpaReplicas := make(map[types.NamespacedName]int32, len(hpas.Items))
+	for _, hpa := range hpas.Items {
+		hpaReplicas[types.NamespacedName{Namespace: hpa.Namespace, Name: hpa.Name}] = hpa.Status.CurrentReplicas
+	}
+
+	currentReplicas := map[string]int64{}
+	maxReplicas := map[string]int64{}
+	for i := range scaledObjects.Items {
+		scaledObject := &scaledObjects.Items[i]
+		namespace := scaledObject.Namespace
+
+		maxReplicas[namespace] += int64(scaledObject.GetMaxReplicaCount())
+		if scaledObject.Status.HpaName == "" {
+			continue
+		}
+		if replicas, found := hpaReplicas[types.NamespacedName{Namespace: namespace, Name: scaledObject.Status.HpaName}]; found {
+			currentReplicas[namespace] += int64(replicas)
+		}
+	}
+
+	for namespace := range a.namespaces {
+		if _, found := maxReplicas[namespace]; !found {
+			prommetrics.DeleteNamespaceManagedReplicas(namespace)
+			delete(a.namespaces, namespace)
+		}
+	}
+	for namespace, replicas := range maxReplicas {
+		prommetrics.RecordNamespaceManagedReplicas(namespace, currentReplicas[namespace], replicas)
+		a.namespaces[namespace] = true
+	}
+	return nil
+}
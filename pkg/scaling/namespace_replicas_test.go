@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func newNamespaceReplicasTestScaledObject(namespace, name string, maxReplicaCount *int32, withHPA bool) *kedav1alpha1.ScaledObject {
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef:  &kedav1alpha1.ScaleTarget{Name: name},
+			MaxReplicaCount: maxReplicaCount,
+		},
+	}
+	if withHPA {
+		scaledObject.Status.HpaName = "keda-hpa-" + name
+	}
+	return scaledObject
+}
+
+func newNamespaceReplicasTestHPA(namespace, name string, currentReplicas int32) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "keda-hpa-" + name},
+		Status:     autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: currentReplicas},
+	}
+}
+
+func TestNamespaceReplicasAggregator(t *testing.T) {
+	maxReplicaCount := func(count int32) *int32 {
+		return &count
+	}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+	assert.NoError(t, autoscalingv2.AddToScheme(scheme))
+	firstHPA := newNamespaceReplicasTestHPA("replicas-a", "first", 3)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newNamespaceReplicasTestScaledObject("replicas-a", "first", maxReplicaCount(10), true),
+		newNamespaceReplicasTestScaledObject("replicas-a", "second", nil, true),
+		firstHPA,
+		newNamespaceReplicasTestHPA("replicas-a", "second", 5),
+		// the ScaledObject without an HPA and the one whose HPA isn't in the cache only count in the maximum replicas
+		newNamespaceReplicasTestScaledObject("replicas-b", "activation-only", maxReplicaCount(4), false),
+		newNamespaceReplicasTestScaledObject("replicas-b", "missing", maxReplicaCount(6), true),
+	).Build()
+
+	aggregator, err := NewNamespaceReplicasAggregator(kubeClient, 1, logr.Discard())
+	assert.NoError(t, err)
+
+	assert.NoError(t, aggregator.Aggregate(context.Background()))
+	assertNamespaceReplicas(t, "replicas-a", 8, 110)
+	assertNamespaceReplicas(t, "replicas-b", 0, 10)
+
+	// the series of the namespaces without ScaledObjects are deleted
+	firstHPA.Status.CurrentReplicas = 7
+	assert.NoError(t, kubeClient.Update(context.Background(), firstHPA))
+	for _, name := range []string{"activation-only", "missing"} {
+		assert.NoError(t, kubeClient.Delete(context.Background(), &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Namespace: "replicas-b", Name: name}}))
+	}
+	assert.NoError(t, aggregator.Aggregate(context.Background()))
+	assertNamespaceReplicas(t, "replicas-a", 12, 110)
+	_, found := getNamespaceReplicasMetric(t, "keda_namespace_managed_replicas_current", "replicas-b")
+	assert.False(t, found)
+	_, found = getNamespaceReplicasMetric(t, "keda_namespace_managed_replicas_max", "replicas-b")
+	assert.False(t, found)
+
+	assert.NoError(t, kubeClient.DeleteAllOf(context.Background(), &kedav1alpha1.ScaledObject{}, client.InNamespace("replicas-a")))
+	assert.NoError(t, aggregator.Aggregate(context.Background()))
+	_, found = getNamespaceReplicasMetric(t, "keda_namespace_managed_replicas_max", "replicas-a")
+	assert.False(t, found)
+}
+
+func TestNewNamespaceReplicasAggregatorInterval(t *testing.T) {
+	_, err := NewNamespaceReplicasAggregator(nil, 0, logr.Discard())
+	assert.Error(t, err)
+}
+
+func assertNamespaceReplicas(t *testing.T, namespace string, currentReplicas, maxReplicas float64) {
+	t.Helper()
+	value, found := getNamespaceReplicasMetric(t, "keda_namespace_managed_replicas_current", namespace)
+	assert.True(t, found)
+	assert.Equal(t, currentReplicas, value)
+	value, found = getNamespaceReplicasMetric(t, "keda_namespace_managed_replicas_max", namespace)
+	assert.True(t, found)
+	assert.Equal(t, maxReplicas, value)
+}
+
+func getNamespaceReplicasMetric(t *testing.T, name, namespace string) (float64, bool) {
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "namespace" && label.GetValue() == namespace {
+					return metric.GetGauge().GetValue(), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
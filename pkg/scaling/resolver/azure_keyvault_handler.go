@@ -18,6 +18,8 @@ package resolver
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"strings"
 
@@ -25,6 +27,7 @@ import (
 	az "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/go-logr/logr"
+	"golang.org/x/crypto/pkcs12"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,6 +35,12 @@ import (
 	"github.com/kedacore/keda/v2/pkg/scalers/azure"
 )
 
+const (
+	// content types of the secrets backing Azure Key Vault certificates
+	azureKeyVaultPKCS12ContentType = "application/x-pkcs12"
+	azureKeyVaultPEMContentType    = "application/x-pem-file"
+)
+
 type AzureKeyVaultHandler struct {
 	vault          *kedav1alpha1.AzureKeyVault
 	keyvaultClient *keyvault.BaseClient
@@ -67,13 +76,103 @@ func (vh *AzureKeyVaultHandler) Initialize(ctx context.Context, client client.Cl
 	return nil
 }
 
-func (vh *AzureKeyVaultHandler) Read(ctx context.Context, secretName string, version string) (string, error) {
+// Read returns the value of the secret and its content type
+func (vh *AzureKeyVaultHandler) Read(ctx context.Context, secretName string, version string) (string, string, error) {
 	result, err := vh.keyvaultClient.GetSecret(ctx, vh.vault.VaultURI, secretName, version)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return *result.Value, nil
+	contentType := ""
+	if result.ContentType != nil {
+		contentType = *result.ContentType
+	}
+	return *result.Value, contentType, nil
+}
+
+// ResolveSecret returns the auth params of the secret, the certificate and the private key
+// are extracted from certificate secrets as PEM when the secret specifies extract
+func (vh *AzureKeyVaultHandler) ResolveSecret(ctx context.Context, secret kedav1alpha1.AzureKeyVaultSecret) (map[string]string, error) {
+	value, contentType, err := vh.Read(ctx, secret.Name, secret.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.Extract == "" {
+		return map[string]string{secret.Parameter: value}, nil
+	}
+	return extractAzureKeyVaultCertificate(secret, value, contentType)
+}
+
+func extractAzureKeyVaultCertificate(secret kedav1alpha1.AzureKeyVaultSecret, value, contentType string) (map[string]string, error) {
+	switch secret.Extract {
+	case kedav1alpha1.AzureKeyVaultExtractCert, kedav1alpha1.AzureKeyVaultExtractKey:
+	case kedav1alpha1.AzureKeyVaultExtractBoth:
+		if secret.KeyParameter == "" {
+			return nil, fmt.Errorf("keyParameter is required to extract both the certificate and the private key of secret %s", secret.Name)
+		}
+	default:
+		return nil, fmt.Errorf("extract must be one of %s, %s or %s, got %s", kedav1alpha1.AzureKeyVaultExtractCert,
+			kedav1alpha1.AzureKeyVaultExtractKey, kedav1alpha1.AzureKeyVaultExtractBoth, secret.Extract)
+	}
+
+	var blocks []*pem.Block
+	switch strings.ToLower(contentType) {
+	case azureKeyVaultPKCS12ContentType:
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding PKCS12 secret %s: %w", secret.Name, err)
+		}
+		// certificates exported by Azure Key Vault have no password
+		blocks, err = pkcs12.ToPEM(data, "")
+		if err != nil {
+			return nil, fmt.Errorf("error parsing PKCS12 secret %s: %w", secret.Name, err)
+		}
+	case azureKeyVaultPEMContentType:
+		rest := []byte(value)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			blocks = append(blocks, block)
+		}
+	default:
+		return nil, fmt.Errorf("secret %s has content type %q, extract requires a certificate with content type %s or %s",
+			secret.Name, contentType, azureKeyVaultPKCS12ContentType, azureKeyVaultPEMContentType)
+	}
+
+	var cert, key []byte
+	for _, block := range blocks {
+		// the attributes of the PKCS12 bags are dropped, they aren't expected by the scalers
+		encoded := pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: block.Bytes})
+		switch {
+		case block.Type == "CERTIFICATE":
+			cert = append(cert, encoded...)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			if key != nil {
+				return nil, fmt.Errorf("secret %s contains more than one private key", secret.Name)
+			}
+			key = encoded
+		}
+	}
+
+	if cert == nil && secret.Extract != kedav1alpha1.AzureKeyVaultExtractKey {
+		return nil, fmt.Errorf("secret %s doesn't contain a certificate", secret.Name)
+	}
+	if key == nil && secret.Extract != kedav1alpha1.AzureKeyVaultExtractCert {
+		return nil, fmt.Errorf("secret %s doesn't contain a private key", secret.Name)
+	}
+
+	switch secret.Extract {
+	case kedav1alpha1.AzureKeyVaultExtractCert:
+		return map[string]string{secret.Parameter: string(cert)}, nil
+	case kedav1alpha1.AzureKeyVaultExtractKey:
+		return map[string]string{secret.Parameter: string(key)}, nil
+	default:
+		return map[string]string{secret.Parameter: string(cert), secret.KeyParameter: string(key)}, nil
+	}
 }
 
 func (vh *AzureKeyVaultHandler) getPropertiesForCloud() (string, string, error) {
@@ -95,7 +194,11 @@ func (vh *AzureKeyVaultHandler) getPropertiesForCloud() (string, string, error)
 
 	env, err := az.EnvironmentFromName(cloud.Type)
 	if err != nil {
-		return "", "", err
+		// allow the short names like AzurePublic or AzureChina
+		var suffixErr error
+		if env, suffixErr = az.EnvironmentFromName(cloud.Type + "Cloud"); suffixErr != nil {
+			return "", "", err
+		}
 	}
 
 	return env.ResourceIdentifiers.KeyVault, env.ActiveDirectoryEndpoint, nil
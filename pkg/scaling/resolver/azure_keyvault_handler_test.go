@@ -17,9 +17,23 @@ limitations under the License.
 package resolver
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	az "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 )
@@ -49,6 +63,28 @@ var testDataset = []testData{
 		expectedKVResourceURL: az.PublicCloud.ResourceIdentifiers.KeyVault,
 		expectedADEndpoint:    az.PublicCloud.ActiveDirectoryEndpoint,
 	},
+	{
+		name:    "short Azure cloud name",
+		isError: false,
+		vault: kedav1alpha1.AzureKeyVault{
+			Cloud: &kedav1alpha1.AzureKeyVaultCloudInfo{
+				Type: "AzureChina",
+			},
+		},
+		expectedKVResourceURL: az.ChinaCloud.ResourceIdentifiers.KeyVault,
+		expectedADEndpoint:    az.ChinaCloud.ActiveDirectoryEndpoint,
+	},
+	{
+		name:    "US government cloud",
+		isError: false,
+		vault: kedav1alpha1.AzureKeyVault{
+			Cloud: &kedav1alpha1.AzureKeyVaultCloudInfo{
+				Type: "AzureUSGovernment",
+			},
+		},
+		expectedKVResourceURL: az.USGovernmentCloud.ResourceIdentifiers.KeyVault,
+		expectedADEndpoint:    az.USGovernmentCloud.ActiveDirectoryEndpoint,
+	},
 	{
 		name:    "private cloud",
 		isError: false,
@@ -133,3 +169,173 @@ func TestGetPropertiesForCloud(t *testing.T) {
 		}
 	}
 }
+
+// testPKCS12Certificate is a self-signed certificate (CN=keda-test) with its EC private key,
+// exported without password like the secrets backing the Azure Key Vault certificates
+const testPKCS12Certificate = `MIIDggIBAzCCA0gGCSqGSIb3DQEHAaCCAzkEggM1MIIDMTCCAicGCSqGSIb3DQEHBqCCAhgwggIU
+AgEAMIICDQYJKoZIhvcNAQcBMBwGCiqGSIb3DQEMAQYwDgQIOZiYqQ7OS0oCAggAgIIB4OCxSgnc
+iIUjC3V2IxqZ9wMJbM7B4fuRmWCrsVk8TGwGnVxfabLEI6iKfZGZHqimuHrej7vAawSkWS/Tu9p5
+R4wD5ZJgW8v8kfeFNdwjkeLOGNaIqO8yY1ImLiUQRpleFC6JqpT7nh8NC58Py9RiDYo+MhjuppUl
+2UbQGCJ+TWgwHwgRd4Ck05RMETI5CZVOHM31G/tZ5IjkqsvYMBwv1u1Qftmxu2A7mdNl+PQ8ZlYB
+hdTzOWf1EroDaqofJfQW9fr9LVq/8rkWZ5+qY2BVnwNSkPy5JBGrRngIVChmGDMI9bCNqEpsmvMv
+l4FWXSqvkvc9hdOkhfrLTp8eByZWZnEFok3AdrQM8udTWsN0+/6bB1gbXTMToeTtbDSkYsu679Q6
+HoZ8KBuSjV72kgoEa7g2urz2e9lKpNzrkBnb/3jsN140yMQ3fPhvf5+hhMOb5o0xpFdeJmUeqagm
+9GIGRTTKeo3icFqccH3vAU+k6Z40JVU/VSxTythANWMODDG1wxF6+WZrKuGURN/TTugj2ehnqv6N
+MSSK6CxXHCxopeLzgF7jsfiFAJq5/tZsogK6mlekkSyTUDs07D7yDX3e7G8GxSEDt4c7jl5/1pKm
+akljAXOLWXx+/eQDOYdqNDwolDCCAQIGCSqGSIb3DQEHAaCB9ASB8TCB7jCB6wYLKoZIhvcNAQwK
+AQKggbQwgbEwHAYKKoZIhvcNAQwBAzAOBAhTPK5p3FYw4gICCAAEgZCsXpII7iQoYtfs+3lT2ak/
++mZg0XmOWIbLM4a4WTpgpo9MTJGmtkoHMAOuYFCRSX2VPqa45/TNfjvQ8QNGKmJhtYl9RyBk2eOi
+KOIwC3LC5x9XKM3OuBZroDU3pzUO7e3sXoTUZP73iYHKd3hjR40h7dD5FfYWhqaS+9jdYCJQ9+Le
+ZlyA8pyXDAsfr/rsaGExJTAjBgkqhkiG9w0BCRUxFgQUu+rvq5oi4V9tfm/vDg0oywlZd6swMTAh
+MAkGBSsOAwIaBQAEFFlxudQwK5/GD7EN/dyzUA64Mo1KBAgsMZZK7z4WigICCAA=`
+
+// newTestPEMCertificate returns a self-signed certificate and its private key as PEM
+func newTestPEMCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "keda-test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestExtractAzureKeyVaultCertificate(t *testing.T) {
+	pkcs12Value := strings.ReplaceAll(testPKCS12Certificate, "\n", "")
+	pemCert, pemKey := newTestPEMCertificate(t)
+	pemValue := pemKey + pemCert
+
+	tests := []struct {
+		name        string
+		secret      kedav1alpha1.AzureKeyVaultSecret
+		value       string
+		contentType string
+		params      []string
+		isError     bool
+	}{
+		{
+			name:        "PKCS12 certificate and key",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "cert", KeyParameter: "key", Extract: "both"},
+			value:       pkcs12Value,
+			contentType: "application/x-pkcs12",
+			params:      []string{"cert", "key"},
+		},
+		{
+			name:        "PKCS12 certificate",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "cert", Extract: "cert"},
+			value:       pkcs12Value,
+			contentType: "application/x-pkcs12",
+			params:      []string{"cert"},
+		},
+		{
+			name:        "PKCS12 key",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "key", Extract: "key"},
+			value:       pkcs12Value,
+			contentType: "application/x-pkcs12",
+			params:      []string{"key"},
+		},
+		{
+			name:        "PEM certificate and key",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "tlsCert", KeyParameter: "tlsKey", Extract: "both"},
+			value:       pemValue,
+			contentType: "application/x-pem-file",
+			params:      []string{"tlsCert", "tlsKey"},
+		},
+		{
+			name:        "both without keyParameter",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "cert", Extract: "both"},
+			value:       pkcs12Value,
+			contentType: "application/x-pkcs12",
+			isError:     true,
+		},
+		{
+			name:        "unknown extract",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "cert", Extract: "chain"},
+			value:       pkcs12Value,
+			contentType: "application/x-pkcs12",
+			isError:     true,
+		},
+		{
+			name:        "not a certificate",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "password", Parameter: "cert", Extract: "cert"},
+			value:       "password",
+			contentType: "text/plain",
+			isError:     true,
+		},
+		{
+			name:        "invalid base64",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "cert", Extract: "cert"},
+			value:       "not base64!",
+			contentType: "application/x-pkcs12",
+			isError:     true,
+		},
+		{
+			name:        "invalid PKCS12",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "cert", Extract: "cert"},
+			value:       "a2VkYQ==",
+			contentType: "application/x-pkcs12",
+			isError:     true,
+		},
+		{
+			name:        "PEM without key",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "key", Extract: "key"},
+			value:       pemCert,
+			contentType: "application/x-pem-file",
+			isError:     true,
+		},
+		{
+			name:        "PEM without certificate",
+			secret:      kedav1alpha1.AzureKeyVaultSecret{Name: "cert", Parameter: "cert", Extract: "cert"},
+			value:       pemKey,
+			contentType: "application/x-pem-file",
+			isError:     true,
+		},
+	}
+
+	for _, test := range tests {
+		params, err := extractAzureKeyVaultCertificate(test.secret, test.value, test.contentType)
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Len(t, params, len(test.params), test.name)
+		for _, param := range test.params {
+			assert.NotEmpty(t, params[param], test.name)
+		}
+
+		if test.secret.Extract == "both" {
+			// the extracted certificate and key must be usable together
+			_, err := tls.X509KeyPair([]byte(params[test.secret.Parameter]), []byte(params[test.secret.KeyParameter]))
+			assert.NoError(t, err, test.name)
+		}
+		if test.secret.Extract != "key" {
+			block, _ := pem.Decode([]byte(params[test.secret.Parameter]))
+			assert.NotNil(t, block, test.name)
+			cert, err := x509.ParseCertificate(block.Bytes)
+			assert.NoError(t, err, test.name)
+			assert.Equal(t, "keda-test", cert.Subject.CommonName, test.name)
+		}
+	}
+}
+
+func TestGetAuthConfigHonorsIdentityID(t *testing.T) {
+	vh := NewAzureKeyVaultHandler(&kedav1alpha1.AzureKeyVault{
+		PodIdentity: &kedav1alpha1.AuthPodIdentity{Provider: kedav1alpha1.PodIdentityProviderAzure, IdentityID: "vault-client-id"},
+	})
+
+	config, err := vh.getAuthConfig(context.TODO(), nil, logr.Discard(), "default", testResourceURL, testActiveDirectoryEndpoint, nil)
+	assert.NoError(t, err)
+	msiConfig, ok := config.(auth.MSIConfig)
+	assert.True(t, ok)
+	assert.Equal(t, "vault-client-id", msiConfig.ClientID)
+	assert.Equal(t, testResourceURL, msiConfig.Resource)
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/go-logr/logr"
 	vaultapi "github.com/hashicorp/vault/api"
@@ -29,9 +30,12 @@ import (
 
 // HashicorpVaultHandler is specification of Hashi Corp Vault
 type HashicorpVaultHandler struct {
-	vault  *kedav1alpha1.HashiCorpVault
-	client *vaultapi.Client
-	stopCh chan struct{}
+	vault *kedav1alpha1.HashiCorpVault
+	// serviceAccountToken is the JWT of the kubernetes authentication, the file of the credential is read if it's empty
+	serviceAccountToken string
+	client              *vaultapi.Client
+	stopCh              chan struct{}
+	stopOnce            sync.Once
 }
 
 // NewHashicorpVaultHandler creates a HashicorpVaultHandler object
@@ -41,6 +45,15 @@ func NewHashicorpVaultHandler(v *kedav1alpha1.HashiCorpVault) *HashicorpVaultHan
 	}
 }
 
+// NewHashicorpVaultHandlerWithServiceAccountToken creates a HashicorpVaultHandler object logging in with the given
+// service account token with the kubernetes authentication, no file is read
+func NewHashicorpVaultHandlerWithServiceAccountToken(v *kedav1alpha1.HashiCorpVault, serviceAccountToken string) *HashicorpVaultHandler {
+	return &HashicorpVaultHandler{
+		vault:               v,
+		serviceAccountToken: serviceAccountToken,
+	}
+}
+
 // Initialize the Vault client
 func (vh *HashicorpVaultHandler) Initialize(logger logr.Logger) error {
 	config := vaultapi.DefaultConfig()
@@ -73,14 +86,14 @@ func (vh *HashicorpVaultHandler) Initialize(logger logr.Logger) error {
 		return err
 	}
 
-	renew := lookup.Data["renewable"].(bool)
-	if renew {
+	vh.client = client
+
+	// the client must be set before the renewal is started as it uses the client
+	if renew, _ := lookup.Data["renewable"].(bool); renew {
 		vh.stopCh = make(chan struct{})
 		go vh.renewToken(logger)
 	}
 
-	vh.client = client
-
 	return nil
 }
 
@@ -107,17 +120,21 @@ func (vh *HashicorpVaultHandler) token(client *vaultapi.Client) (string, error)
 			return token, errors.New("k8s role not in config")
 		}
 
-		if len(vh.vault.Credential.ServiceAccount) == 0 {
-			return token, errors.New("k8s SA file not in config")
-		}
+		jwt := vh.serviceAccountToken
+		if len(jwt) == 0 {
+			if vh.vault.Credential == nil || len(vh.vault.Credential.ServiceAccount) == 0 {
+				return token, errors.New("k8s SA file not in config")
+			}
 
-		// Get the JWT from POD
-		jwt, err := os.ReadFile(vh.vault.Credential.ServiceAccount)
-		if err != nil {
-			return token, err
+			// Get the JWT from POD
+			content, err := os.ReadFile(vh.vault.Credential.ServiceAccount)
+			if err != nil {
+				return token, err
+			}
+			jwt = string(content)
 		}
 
-		data := map[string]interface{}{"jwt": string(jwt), "role": vh.vault.Role}
+		data := map[string]interface{}{"jwt": jwt, "role": vh.vault.Role}
 		secret, err := client.Logical().Write(fmt.Sprintf("auth/%s/login", vh.vault.Mount), data)
 		if err != nil {
 			return token, err
@@ -135,6 +152,7 @@ func (vh *HashicorpVaultHandler) renewToken(logger logr.Logger) {
 	secret, err := vh.client.Auth().Token().RenewSelf(0)
 	if err != nil {
 		logger.Error(err, "Vault renew token: failed to create the payload")
+		return
 	}
 
 	renewer, err := vh.client.NewLifetimeWatcher(&vaultapi.RenewerInput{
@@ -144,13 +162,11 @@ func (vh *HashicorpVaultHandler) renewToken(logger logr.Logger) {
 	})
 	if err != nil {
 		logger.Error(err, "Vault renew token: cannot create the renewer")
+		return
 	}
 
 	go renewer.Renew()
-	defer func() {
-		renewer.Stop()
-		close(vh.stopCh)
-	}()
+	defer renewer.Stop()
 
 RenewWatcherLoop:
 	for {
@@ -170,9 +186,12 @@ func (vh *HashicorpVaultHandler) Read(path string) (*vaultapi.Secret, error) {
 	return vh.client.Logical().Read(path)
 }
 
-// Stop is responsible for stoping the renew token process
+// Stop is responsible for stoping the renew token process, it can be called more than once and after the renewal
+// stopped on its own
 func (vh *HashicorpVaultHandler) Stop() {
 	if vh.stopCh != nil {
-		vh.stopCh <- struct{}{}
+		vh.stopOnce.Do(func() {
+			close(vh.stopCh)
+		})
 	}
 }
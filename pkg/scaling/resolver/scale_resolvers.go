@@ -25,6 +25,7 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	corev1listers "k8s.io/client-go/listers/core/v1"
@@ -245,12 +246,14 @@ func resolveAuthRef(ctx context.Context, client client.Client, logger logr.Logge
 					logger.Error(err, "error authenticating to Azure Key Vault", "triggerAuthRef.Name", triggerAuthRef.Name)
 				} else {
 					for _, secret := range triggerAuthSpec.AzureKeyVault.Secrets {
-						res, err := vaultHandler.Read(ctx, secret.Name, secret.Version)
+						res, err := vaultHandler.ResolveSecret(ctx, secret)
 						if err != nil {
 							logger.Error(err, "error trying to read secret from Azure Key Vault", "triggerAuthRef.Name", triggerAuthRef.Name,
 								"secret.Name", secret.Name, "secret.Version", secret.Version)
 						} else {
-							result[secret.Parameter] = res
+							for parameter, value := range res {
+								result[parameter] = value
+							}
 						}
 					}
 				}
@@ -284,6 +287,44 @@ func getTriggerAuthSpec(ctx context.Context, client client.Client, triggerAuthRe
 	return nil, "", fmt.Errorf("unknown trigger auth kind %s", triggerAuthRef.Kind)
 }
 
+// GetAuthSecretNames returns the names of the secrets referenced by the TriggerAuthentication spec, the empty names
+// are left out as no secret can be resolved from them
+func GetAuthSecretNames(triggerAuthSpec *kedav1alpha1.TriggerAuthenticationSpec) []string {
+	var names []string
+	for _, e := range triggerAuthSpec.SecretTargetRef {
+		if e.Name != "" {
+			names = append(names, e.Name)
+		}
+	}
+	if triggerAuthSpec.AzureKeyVault != nil && triggerAuthSpec.AzureKeyVault.Credentials != nil && triggerAuthSpec.AzureKeyVault.Credentials.ClientSecret != nil &&
+		triggerAuthSpec.AzureKeyVault.Credentials.ClientSecret.ValueFrom.SecretKeyRef.Name != "" {
+		names = append(names, triggerAuthSpec.AzureKeyVault.Credentials.ClientSecret.ValueFrom.SecretKeyRef.Name)
+	}
+	return names
+}
+
+// GetMissingAuthSecrets returns the names of the secrets referenced by the TriggerAuthentication spec which don't exist,
+// the secrets are looked up in the namespace of the (Cluster)TriggerAuthentication the same way as when the trigger is resolved.
+// The secrets are read from the API reader, so no informer is started on the Secrets of the cluster
+func GetMissingAuthSecrets(ctx context.Context, apiReader client.Reader, logger logr.Logger, triggerAuthSpec *kedav1alpha1.TriggerAuthenticationSpec,
+	namespace string, secretsLister corev1listers.SecretLister) ([]string, error) {
+	var missing []string
+	for _, name := range GetAuthSecretNames(triggerAuthSpec) {
+		var err error
+		if isSecretAccessRestricted(logger) {
+			_, err = secretsLister.Secrets(kedaNamespace).Get(name)
+		} else {
+			err = apiReader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &corev1.Secret{})
+		}
+		if errors.IsNotFound(err) {
+			missing = append(missing, name)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
 func resolveEnv(ctx context.Context, client client.Client, logger logr.Logger, container *corev1.Container, namespace string, secretsLister corev1listers.SecretLister) (map[string]string, error) {
 	resolved := make(map[string]string)
 
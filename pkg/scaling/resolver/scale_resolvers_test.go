@@ -426,6 +426,70 @@ func TestResolveAuthRef(t *testing.T) {
 	}
 }
 
+func TestGetMissingAuthSecrets(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: secretName},
+		Data:       map[string][]byte{secretKey: []byte(secretData)},
+	}
+	tests := []struct {
+		name     string
+		spec     kedav1alpha1.TriggerAuthenticationSpec
+		expected []string
+	}{
+		{
+			name:     "no secret references",
+			spec:     kedav1alpha1.TriggerAuthenticationSpec{Env: []kedav1alpha1.AuthEnvironment{{Parameter: "host", Name: envKey}}},
+			expected: nil,
+		},
+		{
+			name:     "existing secret",
+			spec:     kedav1alpha1.TriggerAuthenticationSpec{SecretTargetRef: []kedav1alpha1.AuthSecretTargetRef{{Parameter: "host", Name: secretName, Key: secretKey}}},
+			expected: nil,
+		},
+		{
+			name: "missing secret",
+			spec: kedav1alpha1.TriggerAuthenticationSpec{SecretTargetRef: []kedav1alpha1.AuthSecretTargetRef{
+				{Parameter: "host", Name: secretName, Key: secretKey},
+				{Parameter: "password", Name: "missing", Key: secretKey},
+			}},
+			expected: []string{"missing"},
+		},
+		{
+			name: "missing azure key vault client secret",
+			spec: kedav1alpha1.TriggerAuthenticationSpec{AzureKeyVault: &kedav1alpha1.AzureKeyVault{
+				Credentials: &kedav1alpha1.AzureKeyVaultCredentials{
+					ClientSecret: &kedav1alpha1.AzureKeyVaultClientSecret{ValueFrom: kedav1alpha1.ValueFromSecret{SecretKeyRef: kedav1alpha1.SecretKeyRef{Name: "missing-client-secret", Key: secretKey}}},
+				},
+			}},
+			expected: []string{"missing-client-secret"},
+		},
+		{
+			name:     "empty secret name",
+			spec:     kedav1alpha1.TriggerAuthenticationSpec{SecretTargetRef: []kedav1alpha1.AuthSecretTargetRef{{Parameter: "host", Name: "", Key: secretKey}}},
+			expected: nil,
+		},
+	}
+	var secretsLister corev1listers.SecretLister
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			missing, err := GetMissingAuthSecrets(
+				context.Background(),
+				fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(secret).Build(),
+				logf.Log.WithName("test"),
+				&test.spec,
+				namespace,
+				secretsLister)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(missing, test.expected); diff != "" {
+				t.Errorf("Returned missing secrets are different: %s", diff)
+			}
+		})
+	}
+}
+
 func TestResolveDependentEnv(t *testing.T) {
 	tests := []struct {
 		name      string
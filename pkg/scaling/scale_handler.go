@@ -18,12 +18,17 @@ package scaling
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
+	v2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	corev1listers "k8s.io/client-go/listers/core/v1"
@@ -42,6 +47,7 @@ import (
 	"github.com/kedacore/keda/v2/pkg/scaling/cache/metricscache"
 	"github.com/kedacore/keda/v2/pkg/scaling/executor"
 	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 var log = logf.Log.WithName("scale_handler")
@@ -52,6 +58,7 @@ type ScaleHandler interface {
 	HandleScalableObject(ctx context.Context, scalableObject interface{}) error
 	DeleteScalableObject(ctx context.Context, scalableObject interface{}) error
 	GetScalersCache(ctx context.Context, scalableObject interface{}) (*cache.ScalersCache, error)
+	GetBuiltScalersCache(scalableObject interface{}) (*cache.ScalersCache, bool)
 	ClearScalersCache(ctx context.Context, scalableObject interface{}) error
 
 	GetScaledObjectMetrics(ctx context.Context, scaledObjectName, scaledObjectNamespace, metricName string) (*external_metrics.ExternalMetricValueList, error)
@@ -66,6 +73,8 @@ type scaleHandler struct {
 	scalerCaches             map[string]*cache.ScalersCache
 	scalerCachesLock         *sync.RWMutex
 	scaledObjectsMetricCache metricscache.MetricsCache
+	metricsStaleness         metricscache.MetricsStaleness
+	drivingTriggers          drivingTriggers
 	secretsLister            corev1listers.SecretLister
 }
 
@@ -142,6 +151,8 @@ func (h *scaleHandler) DeleteScalableObject(ctx context.Context, scalableObject
 			cancel()
 		}
 		h.scaleLoopContexts.Delete(key)
+		h.metricsStaleness.Delete(key)
+		h.drivingTriggers.delete(key)
 		err := h.ClearScalersCache(ctx, scalableObject)
 		if err != nil {
 			log.Error(err, "error clearing scalers cache", "scalableObject", scalableObject, "key", key)
@@ -161,9 +172,10 @@ func (h *scaleHandler) startScaleLoop(ctx context.Context, withTriggers *kedav1a
 	pollingInterval := withTriggers.GetPollingInterval()
 	logger.V(1).Info("Watching with pollingInterval", "PollingInterval", pollingInterval)
 
+	loopCtx := cache.ContextWithScaleLoop(ctx)
 	for {
 		tmr := time.NewTimer(pollingInterval)
-		h.checkScalers(ctx, scalableObject, scalingMutex)
+		h.checkScalers(loopCtx, scalableObject, scalingMutex)
 
 		select {
 		case <-tmr.C:
@@ -201,7 +213,7 @@ func (h *scaleHandler) startPushScalers(ctx context.Context, withTriggers *kedav
 					scalingMutex.Lock()
 					switch obj := scalableObject.(type) {
 					case *kedav1alpha1.ScaledObject:
-						h.scaleExecutor.RequestScale(ctx, obj, active, false)
+						h.scaleExecutor.RequestScale(ctx, obj, active, false, nil)
 					case *kedav1alpha1.ScaledJob:
 						logger.Info("Warning: External Push Scaler does not support ScaledJob", "object", scalableObject)
 					}
@@ -224,13 +236,16 @@ func (h *scaleHandler) checkScalers(ctx context.Context, scalableObject interfac
 			log.Error(err, "error getting scaledObject", "object", scalableObject)
 			return
 		}
-		isActive, isError, metricsRecords, err := h.getScaledObjectState(ctx, obj)
+		if err := applySnapshotScalingDefaults(obj); err != nil {
+			log.Error(err, "error applying the scaling defaults", "scaledObject.Namespace", obj.Namespace, "scaledObject.Name", obj.Name)
+		}
+		isActive, isError, options, metricsRecords, err := h.getScaledObjectState(ctx, obj)
 		if err != nil {
 			log.Error(err, "error getting state of scaledObject", "scaledObject.Namespace", obj.Namespace, "scaledObject.Name", obj.Name)
 			return
 		}
 
-		h.scaleExecutor.RequestScale(ctx, obj, isActive, isError)
+		h.scaleExecutor.RequestScale(ctx, obj, isActive, isError, options)
 
 		if len(metricsRecords) > 0 {
 			log.V(1).Info("Storing metrics to cache", "scaledObject.Namespace", obj.Namespace, "scaledObject.Name", obj.Name, "metricsRecords", metricsRecords)
@@ -248,9 +263,12 @@ func (h *scaleHandler) checkScalers(ctx context.Context, scalableObject interfac
 			log.Error(err, "error getting scaledJob", "scaledJob.Namespace", obj.Namespace, "scaledJob.Name", obj.Name)
 			return
 		}
+		if err := applySnapshotScalingDefaults(obj); err != nil {
+			log.Error(err, "error applying the scaling defaults", "scaledJob.Namespace", obj.Namespace, "scaledJob.Name", obj.Name)
+		}
 
-		isActive, scaleTo, maxScale := cache.IsScaledJobActive(ctx, obj)
-		h.scaleExecutor.RequestJobScale(ctx, obj, isActive, scaleTo, maxScale)
+		isActive, scaleTo, maxScale, triggerIndex := cache.IsScaledJobActive(ctx, obj)
+		h.scaleExecutor.RequestJobScale(ctx, obj, isActive, scaleTo, maxScale, triggerIndex)
 	}
 }
 
@@ -272,6 +290,24 @@ func (h *scaleHandler) GetScalersCache(ctx context.Context, scalableObject inter
 	return h.performGetScalersCache(ctx, key, scalableObject, &generation, "", "", "")
 }
 
+// GetBuiltScalersCache returns the cache of the scalable object if its scalers are already built for its generation,
+// unlike GetScalersCache it never builds them
+func (h *scaleHandler) GetBuiltScalersCache(scalableObject interface{}) (*cache.ScalersCache, bool) {
+	withTriggers, err := kedav1alpha1.AsDuckWithTriggers(scalableObject)
+	if err != nil {
+		return nil, false
+	}
+	generation := withTriggers.Generation
+
+	h.scalerCachesLock.RLock()
+	defer h.scalerCachesLock.RUnlock()
+	cache, ok := h.scalerCaches[withTriggers.GenerateIdentifier()]
+	if !ok || !isScalersCacheCurrent(cache, &generation) {
+		return nil, false
+	}
+	return cache, true
+}
+
 // getScalersCacheForScaledObject returns cache for input ScaledObject, referenced by name and namespace
 // we don't need to compare the Generation, because this method should be called only inside scale loop, where we have up to date object.
 func (h *scaleHandler) getScalersCacheForScaledObject(ctx context.Context, scaledObjectName, scaledObjectNamespace string) (*cache.ScalersCache, error) {
@@ -283,34 +319,23 @@ func (h *scaleHandler) getScalersCacheForScaledObject(ctx context.Context, scale
 // performGetScalersCache returns cache for input scalableObject, it is common code used by GetScalersCache() and getScalersCacheForScaledObject() methods
 func (h *scaleHandler) performGetScalersCache(ctx context.Context, key string, scalableObject interface{}, scalableObjectGeneration *int64, scalableObjectKind, scalableObjectNamespace, scalableObjectName string) (*cache.ScalersCache, error) {
 	h.scalerCachesLock.RLock()
-	if cache, ok := h.scalerCaches[key]; ok {
-		// generation was specified -> let's include it in the check as well
-		if scalableObjectGeneration != nil {
-			if cache.ScalableObjectGeneration == *scalableObjectGeneration {
-				h.scalerCachesLock.RUnlock()
-				return cache, nil
-			}
-		} else {
-			h.scalerCachesLock.RUnlock()
-			return cache, nil
-		}
+	if cache, ok := h.scalerCaches[key]; ok && isScalersCacheCurrent(cache, scalableObjectGeneration) {
+		h.scalerCachesLock.RUnlock()
+		return cache, nil
 	}
 	h.scalerCachesLock.RUnlock()
 
 	h.scalerCachesLock.Lock()
 	defer h.scalerCachesLock.Unlock()
+	specChanged := false
 	if cache, ok := h.scalerCaches[key]; ok {
-		// generation was specified -> let's include it in the check as well
-		if scalableObjectGeneration != nil {
-			if cache.ScalableObjectGeneration == *scalableObjectGeneration {
-				return cache, nil
-			}
-			// object was found in cache, but the generation is not correct,
-			// let's close scalers in the cache and proceed further to recreate the cache
-			cache.Close(ctx)
-		} else {
+		if isScalersCacheCurrent(cache, scalableObjectGeneration) {
 			return cache, nil
 		}
+		// object was found in cache, but the generation or the root CAs are not correct,
+		// let's close scalers in the cache and proceed further to recreate the cache
+		cache.Close(ctx)
+		specChanged = scalableObjectGeneration != nil && cache.ScalableObjectGeneration != *scalableObjectGeneration
 	}
 
 	if scalableObject == nil {
@@ -348,19 +373,37 @@ func (h *scaleHandler) performGetScalersCache(ctx context.Context, key string, s
 		return nil, err
 	}
 
-	scalers, err := h.buildScalers(ctx, withTriggers, podTemplateSpec, containerName)
+	scalers, failedTriggers, err := h.buildScalers(ctx, withTriggers, podTemplateSpec, containerName)
 	if err != nil {
 		return nil, err
 	}
 
 	newCache := &cache.ScalersCache{
 		Scalers:                  scalers,
+		FailedTriggers:           failedTriggers,
 		ScalableObjectGeneration: withTriggers.Generation,
 		Recorder:                 h.recorder,
+		PollingInterval:          withTriggers.GetPollingInterval(),
+		RootCAsGeneration:        kedautil.GetRootCAsGeneration(),
 	}
 	switch obj := scalableObject.(type) {
 	case *kedav1alpha1.ScaledObject:
 		newCache.ScaledObject = obj
+		for _, builder := range scalers {
+			scalerName := strings.Replace(fmt.Sprintf("%T", builder.Scaler), "*scalers.", "", 1)
+			if builder.ScalerConfig.TriggerName != "" {
+				scalerName = builder.ScalerConfig.TriggerName
+			}
+			if specChanged {
+				prommetrics.RecordScalerRebuild(obj.Namespace, obj.Name, scalerName)
+			}
+			// the CA bundle is read again on every rebuild, so a rotated CA is picked up with its secret
+			if notAfter, found := getScalerCAExpiry(&builder.ScalerConfig); found {
+				prommetrics.RecordScalerCAExpiry(obj.Namespace, obj.Name, scalerName, notAfter)
+			} else {
+				prommetrics.DeleteScalerCAExpiry(obj.Namespace, obj.Name, scalerName)
+			}
+		}
 	default:
 	}
 
@@ -369,6 +412,15 @@ func (h *scaleHandler) performGetScalersCache(ctx context.Context, key string, s
 	return h.scalerCaches[key], nil
 }
 
+// isScalersCacheCurrent returns true if the cache was built with the current root CAs and, when the generation was
+// specified, for that generation of the scalable object
+func isScalersCacheCurrent(cache *cache.ScalersCache, scalableObjectGeneration *int64) bool {
+	if cache.RootCAsGeneration != kedautil.GetRootCAsGeneration() {
+		return false
+	}
+	return scalableObjectGeneration == nil || cache.ScalableObjectGeneration == *scalableObjectGeneration
+}
+
 // ClearScalersCache invalidates chache for the input scalableObject
 func (h *scaleHandler) ClearScalersCache(ctx context.Context, scalableObject interface{}) error {
 	withTriggers, err := kedav1alpha1.AsDuckWithTriggers(scalableObject)
@@ -403,6 +455,12 @@ func (h *scaleHandler) GetScaledObjectMetrics(ctx context.Context, scaledObjectN
 
 	var matchingMetrics []external_metrics.ExternalMetricValue
 
+	// the metrics server sends the ID it logs the request with, it's missing for the direct calls
+	correlationID := kedautil.CorrelationIDFromContext(ctx)
+	if correlationID == "" {
+		correlationID = kedautil.NewCorrelationID()
+	}
+
 	cache, err := h.getScalersCacheForScaledObject(ctx, scaledObjectName, scaledObjectNamespace)
 	prommetrics.RecordScaledObjectError(scaledObjectNamespace, scaledObjectName, err)
 
@@ -420,12 +478,15 @@ func (h *scaleHandler) GetScaledObjectMetrics(ctx context.Context, scaledObjectN
 	}
 
 	isScalerError := false
+	// the triggers of the metric which failed and the ones replaced by their fallback value
+	var failedScalers, fallbackScalers []string
+	var scalerErrs []error
 	scaledObjectIdentifier := scaledObject.GenerateIdentifier()
 
 	// let's check metrics for all scalers in a ScaledObject
-	scalers, scalerConfigs := cache.GetScalers()
-	for scalerIndex := 0; scalerIndex < len(scalers); scalerIndex++ {
-		scalerName := strings.Replace(fmt.Sprintf("%T", scalers[scalerIndex]), "*scalers.", "", 1)
+	allScalers, scalerConfigs := cache.GetScalers()
+	for scalerIndex := 0; scalerIndex < len(allScalers); scalerIndex++ {
+		scalerName := strings.Replace(fmt.Sprintf("%T", allScalers[scalerIndex]), "*scalers.", "", 1)
 		if scalerConfigs[scalerIndex].TriggerName != "" {
 			scalerName = scalerConfigs[scalerIndex].TriggerName
 		}
@@ -460,27 +521,43 @@ func (h *scaleHandler) GetScaledObjectMetrics(ctx context.Context, scaledObjectN
 
 				if !metricsFoundInCache {
 					var latency int64
+					callStart := time.Now()
 					metrics, _, latency, err = cache.GetMetricsAndActivityForScaler(ctx, scalerIndex, metricName)
+					logScalerCall(log, scaledObject, scalerConfigs[scalerIndex], metricName, correlationID, time.Since(callStart), err)
+					clampNegativeMetrics(scaledObject, allScalers[scalerIndex], scalerName, scalerIndex, metrics, false)
 					if latency != -1 {
-						prommetrics.RecordScalerLatency(scaledObjectNamespace, scaledObject.Name, scalerName, scalerIndex, metricName, float64(latency))
+						prommetrics.RecordScalerLatency(scaledObjectNamespace, scaledObject.Name, string(scaledObject.UID), scalerName, scalerIndex, metricName, float64(latency))
+					}
+					if err == nil {
+						err = h.checkMetricsStaleness(scaledObject, scalerName, scalerIndex, scalerConfigs[scalerIndex].TriggerMaxStaleness, metrics, time.Now())
 					}
 					logger.V(1).Info("Getting metrics from scaler", "scaler", scalerName, "metricName", spec.External.Metric.Name, "metrics", metrics, "scalerError", err)
 				}
 
 				// check if we need to set a fallback
+				scalerErr := err
 				metrics, err = fallback.GetMetricsWithFallback(ctx, h.client, metrics, err, metricName, scaledObject, spec)
 
 				if err != nil {
 					isScalerError = true
+					failedScalers = append(failedScalers, scalerName)
+					scalerErrs = append(scalerErrs, fmt.Errorf("scaler %s: %w", scalerName, err))
 					logger.Error(err, "error getting metric for scaler", "scaler", scalerName)
 				} else {
+					if scalerErr != nil {
+						fallbackScalers = append(fallbackScalers, scalerName)
+					}
+					metrics = h.applyScaleDownTrendGuard(ctx, logger, cache, scaledObject, spec, metrics)
+					unit := scalers.GetMetricUnit(allScalers[scalerIndex])
 					for _, metric := range metrics {
-						metricValue := metric.Value.AsApproximateFloat64()
-						prommetrics.RecordScalerMetric(scaledObjectNamespace, scaledObjectName, scalerName, scalerIndex, metric.MetricName, metricValue)
+						prommetrics.RecordScalerMetric(scaledObjectNamespace, scaledObjectName, string(scaledObject.UID), scalerName, scalerIndex, metric, metricsFoundInCache, unit)
 					}
 					matchingMetrics = append(matchingMetrics, metrics...)
+					if ratio, ok := getMetricTargetRatio(spec, metrics); ok {
+						h.updateDrivingTrigger(ctx, logger, scaledObject, scalerConfigs[scalerIndex], spec.External.Metric.Name, ratio)
+					}
 				}
-				prommetrics.RecordScalerError(scaledObjectNamespace, scaledObjectName, scalerName, scalerIndex, metricName, err)
+				prommetrics.RecordScalerError(scaledObjectNamespace, scaledObjectName, string(scaledObject.UID), scalerName, scalerIndex, metricName, err, scalers.GetErrorType(err))
 			}
 		}
 	}
@@ -496,31 +573,64 @@ func (h *scaleHandler) GetScaledObjectMetrics(ctx context.Context, scaledObjectN
 	}
 
 	if len(matchingMetrics) == 0 {
+		if len(scalerErrs) > 0 {
+			return nil, fmt.Errorf("no usable value for metric %s: %w", metricName, errors.Join(scalerErrs...))
+		}
 		return nil, fmt.Errorf("no matching metrics found for " + metricName)
 	}
 
+	// the HPA only sees the values, the degraded ones are logged and counted
+	if len(fallbackScalers) > 0 {
+		logger.Info("Serving fallback values for failing scalers", "metricName", metricName, "fallbackScalers", fallbackScalers)
+		prommetrics.RecordScaledObjectDegradedMetrics(scaledObjectNamespace, scaledObjectName, metricName, prommetrics.DegradedMetricsReasonFallback)
+	}
+	if len(failedScalers) > 0 {
+		logger.Info("Serving partial values, the values of the failing scalers are left out", "metricName", metricName, "failedScalers", failedScalers)
+		prommetrics.RecordScaledObjectDegradedMetrics(scaledObjectNamespace, scaledObjectName, metricName, prommetrics.DegradedMetricsReasonPartial)
+	}
+
 	return &external_metrics.ExternalMetricValueList{
 		Items: matchingMetrics,
 	}, nil
 }
 
+// countExternalMetricNames returns the number of distinct external metric names in the metric specs
+func countExternalMetricNames(metricSpecs []v2.MetricSpec) int {
+	names := map[string]struct{}{}
+	for _, spec := range metricSpecs {
+		if spec.External != nil {
+			names[spec.External.Metric.Name] = struct{}{}
+		}
+	}
+	return len(names)
+}
+
 // getScaledObjectState returns whether the input ScaledObject:
 // is active as the first return value,
 // the second return value indicates whether there was any error during quering scalers,
-// the third return value is a map of metrics record - a metric value for each scaler and it's metric
-// the fourth return value contains error if is not able access scalers cache
-func (h *scaleHandler) getScaledObjectState(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (bool, bool, map[string]metricscache.MetricsRecord, error) {
+// the third return value is the replica count needed by the metric values with an AverageValue target, 0 if there is none
+// the fourth return value is a map of metrics record - a metric value for each scaler and it's metric
+// the fifth return value contains error if is not able access scalers cache
+func (h *scaleHandler) getScaledObjectState(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (bool, bool, *executor.ScaleExecutorOptions, map[string]metricscache.MetricsRecord, error) {
 	logger := log.WithValues("scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
 
 	isScaledObjectActive := false
 	isScalerError := false
+	options := &executor.ScaleExecutorOptions{TriggersActivity: map[string]bool{}}
 	metricsRecord := map[string]metricscache.MetricsRecord{}
+	// the desired replica count is only known with AverageValue targets
+	desiredReplicasComputed := false
+	// compositeRatio is the highest ratio of the metric values to their targets, the one the HPA scales on
+	compositeRatio, compositeRatioKnown := float64(0), false
 
 	cache, err := h.GetScalersCache(ctx, scaledObject)
 	prommetrics.RecordScaledObjectError(scaledObject.Namespace, scaledObject.Name, err)
 	if err != nil {
-		return false, true, map[string]metricscache.MetricsRecord{}, fmt.Errorf("error getting scalers cache %w", err)
+		return false, true, options, map[string]metricscache.MetricsRecord{}, fmt.Errorf("error getting scalers cache %w", err)
 	}
+	start := time.Now()
+	defer h.checkReconcileBudget(logger, scaledObject, start)
+	correlationID := kedautil.NewCorrelationID()
 
 	// count the number of non-external triggers (cpu/mem) in order to check for
 	// scale to zero requirements if atleast one cpu/mem trigger is given.
@@ -534,18 +644,23 @@ func (h *scaleHandler) getScaledObjectState(ctx context.Context, scaledObject *k
 	}
 
 	// Let's collect status of all scalers, no matter if any scaler raises error or is active
-	scalers, scalerConfigs := cache.GetScalers()
-	for scalerIndex := 0; scalerIndex < len(scalers); scalerIndex++ {
-		scalerName := strings.Replace(fmt.Sprintf("%T", scalers[scalerIndex]), "*scalers.", "", 1)
+	allScalers, scalerConfigs := cache.GetScalers()
+	for scalerIndex := 0; scalerIndex < len(allScalers); scalerIndex++ {
+		scalerName := strings.Replace(fmt.Sprintf("%T", allScalers[scalerIndex]), "*scalers.", "", 1)
 		if scalerConfigs[scalerIndex].TriggerName != "" {
 			scalerName = scalerConfigs[scalerIndex].TriggerName
 		}
 
+		// the scaler fails its health check when any of its metrics can't be read
+		isScalerHealthy := true
 		metricSpecs, err := cache.GetMetricSpecForScalingForScaler(ctx, scalerIndex)
 		if err != nil {
 			isScalerError = true
+			isScalerHealthy = false
 			logger.Error(err, "error getting metric spec for the scaler", "scaler", scalerName)
 			cache.Recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
+		} else {
+			prommetrics.RecordScalerExposedMetrics(scaledObject.Namespace, scaledObject.Name, scalerName, countExternalMetricNames(metricSpecs))
 		}
 
 		for _, spec := range metricSpecs {
@@ -560,10 +675,28 @@ func (h *scaleHandler) getScaledObjectState(ctx context.Context, scaledObject *k
 
 			metricName := spec.External.Metric.Name
 
-			var latency int64
-			metrics, isMetricActive, latency, err := cache.GetMetricsAndActivityForScaler(ctx, scalerIndex, metricName)
-			if latency != -1 {
-				prommetrics.RecordScalerLatency(scaledObject.Namespace, scaledObject.Name, scalerName, scalerIndex, metricName, float64(latency))
+			// the triggers with their own pollingInterval are only queried when it has elapsed, the last values
+			// are used in between and their age keeps growing in keda_scaler_metrics_value_age_seconds
+			var err error
+			metrics, isMetricActive, reused := cache.GetTriggerPoll(scalerIndex, metricName, time.Now())
+			if reused {
+				logger.V(1).Info("Reusing the last metrics of the trigger until its pollingInterval elapses", "scaler", scalerName, "metricName", metricName,
+					"pollingInterval", scalerConfigs[scalerIndex].TriggerPollingInterval)
+			} else {
+				var latency int64
+				callStart := time.Now()
+				metrics, isMetricActive, latency, err = cache.GetMetricsAndActivityForScaler(ctx, scalerIndex, metricName)
+				logScalerCall(log, scaledObject, scalerConfigs[scalerIndex], metricName, correlationID, time.Since(callStart), err)
+				isMetricActive = clampNegativeMetrics(scaledObject, allScalers[scalerIndex], scalerName, scalerIndex, metrics, isMetricActive)
+				if latency != -1 {
+					prommetrics.RecordScalerLatency(scaledObject.Namespace, scaledObject.Name, string(scaledObject.UID), scalerName, scalerIndex, metricName, float64(latency))
+				}
+				if err == nil {
+					cache.RecordTriggerPoll(scalerIndex, metricName, metrics, isMetricActive, scalerConfigs[scalerIndex].TriggerPollingInterval, callStart)
+				}
+			}
+			if err == nil {
+				err = h.checkMetricsStaleness(scaledObject, scalerName, scalerIndex, scalerConfigs[scalerIndex].TriggerMaxStaleness, metrics, time.Now())
 			}
 			logger.V(1).Info("Getting metrics and activity from scaler", "scaler", scalerName, "metricName", metricName, "metrics", metrics, "activity", isMetricActive, "scalerError", err)
 
@@ -577,13 +710,28 @@ func (h *scaleHandler) getScaledObjectState(ctx context.Context, scaledObject *k
 
 			if err != nil {
 				isScalerError = true
+				isScalerHealthy = false
 				logger.Error(err, "error getting scale decision", "scaler", scalerName)
 				cache.Recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
 			} else {
+				metricsSum := float64(0)
+				unit := scalers.GetMetricUnit(allScalers[scalerIndex])
 				for _, metric := range metrics {
-					metricValue := metric.Value.AsApproximateFloat64()
-					prommetrics.RecordScalerMetric(scaledObject.Namespace, scaledObject.Name, scalerName, scalerIndex, metric.MetricName, metricValue)
+					prommetrics.RecordScalerMetric(scaledObject.Namespace, scaledObject.Name, string(scaledObject.UID), scalerName, scalerIndex, metric, reused, unit)
+					metricsSum += metric.Value.AsApproximateFloat64()
+				}
+				if ratio, ok := getMetricTargetRatio(spec, metrics); ok && (!compositeRatioKnown || ratio > compositeRatio) {
+					compositeRatio, compositeRatioKnown = ratio, true
 				}
+				replicas := getDesiredReplicas(spec, metricsSum)
+				if replicas > options.DesiredReplicas {
+					options.DesiredReplicas = replicas
+				}
+				if spec.External != nil && spec.External.Target.AverageValue != nil {
+					desiredReplicasComputed = true
+					prommetrics.RecordScaledObjectTriggerContribution(scaledObject.Namespace, scaledObject.Name, string(scaledObject.UID), scalerName, scalerIndex, metricName, replicas)
+				}
+				options.TriggersActivity[metricName] = isMetricActive
 
 				if isMetricActive {
 					isScaledObjectActive = true
@@ -595,9 +743,26 @@ func (h *scaleHandler) getScaledObjectState(ctx context.Context, scaledObject *k
 					}
 				}
 			}
-			prommetrics.RecordScalerError(scaledObject.Namespace, scaledObject.Name, scalerName, scalerIndex, metricName, err)
-			prommetrics.RecordScalerActive(scaledObject.Namespace, scaledObject.Name, scalerName, scalerIndex, metricName, isMetricActive)
+			prommetrics.RecordScalerError(scaledObject.Namespace, scaledObject.Name, string(scaledObject.UID), scalerName, scalerIndex, metricName, err, scalers.GetErrorType(err))
+			prommetrics.RecordScalerActive(scaledObject.Namespace, scaledObject.Name, string(scaledObject.UID), scalerName, scalerIndex, metricName, isMetricActive)
+		}
+
+		// the streaming scalers observe their partitions while reading the metrics above
+		if partitionScaler, ok := allScalers[scalerIndex].(scalers.PartitionScaler); ok {
+			if count, observed := partitionScaler.GetPartitionCount(); observed {
+				prommetrics.RecordScalerPartitions(scaledObject.Namespace, scaledObject.Name, scalerName, count)
+			}
 		}
+		prommetrics.RecordScalerBackendHealth(scaledObject.Namespace, scaledObject.Name, scalerIndex, scalers.GetBackendHost(&scalerConfigs[scalerIndex]), isScalerHealthy)
+	}
+
+	if desiredReplicasComputed {
+		prommetrics.RecordScaledObjectDesiredReplicas(scaledObject.Namespace, scaledObject.Name, options.DesiredReplicas)
+	}
+
+	// a failing trigger would drop out of the composite value, the trend is only recorded from complete polls
+	if compositeRatioKnown && !isScalerError && scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.ScaleDownTrendGuard != nil {
+		cache.RecordMetricTrend(compositeRatio, int(scaledObject.Spec.Advanced.ScaleDownTrendGuard.Window))
 	}
 
 	// invalidate the cache for the ScaledObject, if we hit an error in any scaler
@@ -610,5 +775,130 @@ func (h *scaleHandler) getScaledObjectState(ctx context.Context, scaledObject *k
 		logger.V(1).Info("scaler error encountered, clearing scaler cache")
 	}
 
-	return isScaledObjectActive, isScalerError, metricsRecord, nil
+	return isScaledObjectActive, isScalerError, options, metricsRecord, nil
+}
+
+// clampNegativeMetrics clamps to 0 the negative metric values of the count scalers before they are used for the
+// activity and the HPA, each clamped value is counted. A count of 0 is never above the activation threshold,
+// the returned activity is false when all the metrics have been clamped
+func clampNegativeMetrics(scaledObject *kedav1alpha1.ScaledObject, scaler scalers.Scaler, scalerName string, scalerIndex int,
+	metrics []external_metrics.ExternalMetricValue, isActive bool) bool {
+	if !scalers.ClampsNegativeMetrics(scaler) {
+		return isActive
+	}
+	clamped := 0
+	for i := range metrics {
+		if metrics[i].Value.Sign() < 0 {
+			metrics[i].Value = *resource.NewQuantity(0, metrics[i].Value.Format)
+			prommetrics.RecordScaledObjectNegativeValue(scaledObject.Namespace, scaledObject.Name, string(scaledObject.UID), scalerName, scalerIndex, metrics[i].MetricName)
+			clamped++
+		}
+	}
+	return isActive && clamped < len(metrics)
+}
+
+// checkReconcileBudget counts the queries of the scalers taking longer than the pollingInterval of the scaled object,
+// a single slow scaler delays the next poll of all its triggers
+func (h *scaleHandler) checkReconcileBudget(logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, start time.Time) {
+	withTriggers, err := kedav1alpha1.AsDuckWithTriggers(scaledObject)
+	if err != nil {
+		logger.Error(err, "error getting the pollingInterval")
+		return
+	}
+	if elapsed, budget := time.Since(start), withTriggers.GetPollingInterval(); elapsed > budget {
+		logger.Info("Querying the scalers took longer than the pollingInterval", "duration", elapsed, "pollingInterval", budget)
+		prommetrics.RecordScaledObjectReconcileBudgetExceeded(scaledObject.Namespace, scaledObject.Name)
+	}
+}
+
+// getDesiredReplicas returns the replica count the HPA computes for the metric value when the target is an
+// AverageValue, the replica count for a Value target depends on the current one so it returns 0
+func getDesiredReplicas(spec v2.MetricSpec, value float64) int32 {
+	if spec.External == nil || spec.External.Target.AverageValue == nil {
+		return 0
+	}
+	target := spec.External.Target.AverageValue.AsApproximateFloat64()
+	if target <= 0 || value <= 0 {
+		return 0
+	}
+	replicas := math.Ceil(value / target)
+	if replicas > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(replicas)
+}
+
+// checkMetricsStaleness records for how long the metric values haven't changed, it returns an error
+// if a value hasn't changed for longer than maxStaleness so the stale metric is handled as a scaler error
+func (h *scaleHandler) checkMetricsStaleness(scaledObject *kedav1alpha1.ScaledObject, scalerName string, scalerIndex int, maxStaleness time.Duration,
+	metrics []external_metrics.ExternalMetricValue, now time.Time) error {
+	var err error
+	for _, metric := range metrics {
+		series := fmt.Sprintf("%d/%s", scalerIndex, metric.MetricName)
+		age := h.metricsStaleness.Record(scaledObject.GenerateIdentifier(), series, metric.Value, now)
+		prommetrics.RecordScalerMetricAge(scaledObject.Namespace, scaledObject.Name, string(scaledObject.UID), scalerName, scalerIndex, metric.MetricName, age)
+		if err == nil && maxStaleness > 0 && age > maxStaleness {
+			err = fmt.Errorf("value of metric %s hasn't changed for %s, more than maxStaleness %s", metric.MetricName, age, maxStaleness)
+		}
+	}
+	return err
+}
+
+// applyScaleDownTrendGuard raises the metric values below the target up to the target while the composite value
+// of the ScaledObject recorded in the last polls is still rising, so the HPA holds the current replicas instead of
+// scaling down
+func (h *scaleHandler) applyScaleDownTrendGuard(ctx context.Context, logger logr.Logger, cache *cache.ScalersCache, scaledObject *kedav1alpha1.ScaledObject,
+	spec v2.MetricSpec, metrics []external_metrics.ExternalMetricValue) []external_metrics.ExternalMetricValue {
+	if scaledObject.Spec.Advanced == nil || scaledObject.Spec.Advanced.ScaleDownTrendGuard == nil || spec.External == nil {
+		return metrics
+	}
+	guard := scaledObject.Spec.Advanced.ScaleDownTrendGuard
+
+	minSlope := float64(0)
+	if guard.MinSlope != nil {
+		minSlope = guard.MinSlope.AsApproximateFloat64()
+	}
+	if !cache.IsMetricTrendRising(minSlope) {
+		return metrics
+	}
+
+	var holdValue resource.Quantity
+	target := spec.External.Target
+	switch {
+	case target.Type == v2.ValueMetricType && target.Value != nil:
+		holdValue = target.Value.DeepCopy()
+	case target.Type == v2.AverageValueMetricType && target.AverageValue != nil:
+		// the HPA divides AverageValue metrics by the current replicas
+		replicas := h.getCurrentReplicas(ctx, scaledObject)
+		holdValue = *resource.NewMilliQuantity(target.AverageValue.MilliValue()*int64(replicas), resource.DecimalSI)
+	default:
+		return metrics
+	}
+
+	result := make([]external_metrics.ExternalMetricValue, 0, len(metrics))
+	for _, metric := range metrics {
+		if metric.Value.Cmp(holdValue) < 0 {
+			logger.V(1).Info("Metric trend is rising, holding replicas", "metricName", metric.MetricName, "value", metric.Value.String(), "reportedValue", holdValue.String())
+			metric.Value = holdValue.DeepCopy()
+		}
+		result = append(result, metric)
+	}
+	return result
+}
+
+// getCurrentReplicas returns the current replicas reported by the HPA of the ScaledObject, 1 if it isn't known.
+// The HPA is read from the cache of the manager, which already watches the HPAs owned by the ScaledObjects
+func (h *scaleHandler) getCurrentReplicas(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) int32 {
+	if scaledObject.Status.HpaName == "" {
+		return 1
+	}
+	hpa := &v2.HorizontalPodAutoscaler{}
+	if err := h.client.Get(ctx, types.NamespacedName{Name: scaledObject.Status.HpaName, Namespace: scaledObject.Namespace}, hpa); err != nil {
+		log.V(1).Info("Unable to get HPA of the ScaledObject", "scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name, "error", err.Error())
+		return 1
+	}
+	if hpa.Status.CurrentReplicas < 1 {
+		return 1
+	}
+	return hpa.Status.CurrentReplicas
 }
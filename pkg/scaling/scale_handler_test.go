@@ -18,28 +18,51 @@ package scaling
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"math/big"
+	"os"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	v2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
 	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_scaling/mock_executor"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
 	"github.com/kedacore/keda/v2/pkg/scalers"
 	"github.com/kedacore/keda/v2/pkg/scaling/cache"
 	"github.com/kedacore/keda/v2/pkg/scaling/cache/metricscache"
 )
 
+func TestMain(m *testing.M) {
+	// the scaling metrics are only gathered on the leader
+	prommetrics.RecordOperatorLeader(true)
+	os.Exit(m.Run())
+}
+
 func TestGetScaledObjectMetrics_DirectCall(t *testing.T) {
 	scaledObjectName := "testName"
 	scaledObjectNamespace := "testNamespace"
@@ -101,23 +124,19 @@ func TestGetScaledObjectMetrics_DirectCall(t *testing.T) {
 	caches := map[string]*cache.ScalersCache{}
 	caches[scaledObject.GenerateIdentifier()] = &scalerCache
 
-	sh := scaleHandler{
-		client:                   mockClient,
-		scaleLoopContexts:        &sync.Map{},
-		scaleExecutor:            mockExecutor,
-		globalHTTPTimeout:        time.Duration(1000),
-		recorder:                 recorder,
-		scalerCaches:             caches,
-		scalerCachesLock:         &sync.RWMutex{},
-		scaledObjectsMetricCache: metricscache.NewMetricsCache(),
-	}
+	sh := newTestScaleHandler(mockClient, recorder, caches)
+	sh.scaleExecutor = mockExecutor
 
 	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return(metricsSpecs)
 	scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{metricValue}, true, nil)
-	mockExecutor.EXPECT().RequestScale(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+	mockExecutor.EXPECT().RequestScale(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 	sh.checkScalers(context.TODO(), &scaledObject, &sync.RWMutex{})
+	assert.Equal(t, []string{"false"}, getScalerMetricCachedLabels(t, scaledObjectName, metricName))
 
+	mockClient.EXPECT().Status().Return(mockStatusWriter)
+	mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	// the first metric served sets the driving trigger
 	mockClient.EXPECT().Status().Return(mockStatusWriter)
 	mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return(metricsSpecs)
@@ -126,6 +145,7 @@ func TestGetScaledObjectMetrics_DirectCall(t *testing.T) {
 	metrics, err := sh.GetScaledObjectMetrics(context.TODO(), scaledObjectName, scaledObjectNamespace, metricName)
 	assert.NotNil(t, metrics)
 	assert.Nil(t, err)
+	assert.Equal(t, []string{"false"}, getScalerMetricCachedLabels(t, scaledObjectName, metricName))
 
 	scaler.EXPECT().Close(gomock.Any())
 	scalerCache.Close(context.Background())
@@ -192,23 +212,19 @@ func TestGetScaledObjectMetrics_FromCache(t *testing.T) {
 	caches := map[string]*cache.ScalersCache{}
 	caches[scaledObject.GenerateIdentifier()] = &scalerCache
 
-	sh := scaleHandler{
-		client:                   mockClient,
-		scaleLoopContexts:        &sync.Map{},
-		scaleExecutor:            mockExecutor,
-		globalHTTPTimeout:        time.Duration(1000),
-		recorder:                 recorder,
-		scalerCaches:             caches,
-		scalerCachesLock:         &sync.RWMutex{},
-		scaledObjectsMetricCache: metricscache.NewMetricsCache(),
-	}
+	sh := newTestScaleHandler(mockClient, recorder, caches)
+	sh.scaleExecutor = mockExecutor
 
 	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return(metricsSpecs)
 	scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{metricValue}, true, nil)
-	mockExecutor.EXPECT().RequestScale(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+	mockExecutor.EXPECT().RequestScale(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 	sh.checkScalers(context.TODO(), &scaledObject, &sync.RWMutex{})
+	assert.Equal(t, []string{"false"}, getScalerMetricCachedLabels(t, scaledObjectName, metricName))
 
+	mockClient.EXPECT().Status().Return(mockStatusWriter)
+	mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	// the first metric served sets the driving trigger
 	mockClient.EXPECT().Status().Return(mockStatusWriter)
 	mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return(metricsSpecs)
@@ -216,6 +232,8 @@ func TestGetScaledObjectMetrics_FromCache(t *testing.T) {
 	metrics, err := sh.GetScaledObjectMetrics(context.TODO(), scaledObjectName, scaledObjectNamespace, metricName)
 	assert.NotNil(t, metrics)
 	assert.Nil(t, err)
+	// the value read from the cache replaces the series of the live value
+	assert.Equal(t, []string{"true"}, getScalerMetricCachedLabels(t, scaledObjectName, metricName))
 
 	scaler.EXPECT().Close(gomock.Any())
 	scalerCache.Close(context.Background())
@@ -264,18 +282,10 @@ func TestCheckScaledObjectScalersWithError(t *testing.T) {
 	caches := map[string]*cache.ScalersCache{}
 	caches[scaledObject.GenerateIdentifier()] = &scalerCache
 
-	sh := scaleHandler{
-		client:                   mockClient,
-		scaleLoopContexts:        &sync.Map{},
-		scaleExecutor:            mockExecutor,
-		globalHTTPTimeout:        time.Duration(1000),
-		recorder:                 recorder,
-		scalerCaches:             caches,
-		scalerCachesLock:         &sync.RWMutex{},
-		scaledObjectsMetricCache: metricscache.NewMetricsCache(),
-	}
+	sh := newTestScaleHandler(mockClient, recorder, caches)
+	sh.scaleExecutor = mockExecutor
 
-	isActive, isError, _, _ := sh.getScaledObjectState(context.TODO(), &scaledObject)
+	isActive, isError, _, _, _ := sh.getScaledObjectState(context.TODO(), &scaledObject)
 	scalerCache.Close(context.Background())
 
 	assert.Equal(t, false, isActive)
@@ -339,24 +349,614 @@ func TestCheckScaledObjectFindFirstActiveNotIgnoreOthers(t *testing.T) {
 	caches := map[string]*cache.ScalersCache{}
 	caches[scaledObject.GenerateIdentifier()] = &scalerCache
 
-	sh := scaleHandler{
-		client:                   mockClient,
-		scaleLoopContexts:        &sync.Map{},
-		scaleExecutor:            mockExecutor,
-		globalHTTPTimeout:        time.Duration(1000),
-		recorder:                 recorder,
-		scalerCaches:             caches,
-		scalerCachesLock:         &sync.RWMutex{},
-		scaledObjectsMetricCache: metricscache.NewMetricsCache(),
-	}
+	sh := newTestScaleHandler(mockClient, recorder, caches)
+	sh.scaleExecutor = mockExecutor
 
-	isActive, isError, _, _ := sh.getScaledObjectState(context.TODO(), &scaledObject)
+	isActive, isError, _, _, _ := sh.getScaledObjectState(context.TODO(), &scaledObject)
 	scalerCache.Close(context.Background())
 
 	assert.Equal(t, true, isActive)
 	assert.Equal(t, true, isError)
 }
 
+func TestScalerExposedMetricsCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_client.NewMockClient(ctrl)
+	mockExecutor := mock_executor.NewMockScaleExecutor(ctrl)
+	recorder := record.NewFakeRecorder(1)
+
+	// the scaler exposes two distinct metric names, one of them twice, plus a resource metric
+	metricsSpecs := []v2.MetricSpec{
+		createMetricSpec(1, "s0-query-a"),
+		createMetricSpec(1, "s0-query-b"),
+		createMetricSpec(1, "s0-query-a"),
+		{Resource: &v2.ResourceMetricSource{Name: "cpu"}},
+	}
+
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return(metricsSpecs)
+	scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{}, false, nil).Times(3)
+	scaler.EXPECT().Close(gomock.Any())
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "multi-metric",
+			Namespace: "test-exposed-metrics",
+		},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+				Name: "test",
+			},
+		},
+	}
+
+	scalerCache := cache.ScalersCache{
+		Scalers: []cache.ScalerBuilder{{
+			Scaler: scaler,
+			Factory: func() (scalers.Scaler, *scalers.ScalerConfig, error) {
+				return scaler, &scalers.ScalerConfig{TriggerName: "prometheus-queries"}, nil
+			},
+			ScalerConfig: scalers.ScalerConfig{TriggerName: "prometheus-queries"},
+		}},
+		Recorder: recorder,
+	}
+
+	caches := map[string]*cache.ScalersCache{}
+	caches[scaledObject.GenerateIdentifier()] = &scalerCache
+
+	sh := newTestScaleHandler(mockClient, recorder, caches)
+	sh.scaleExecutor = mockExecutor
+
+	_, isError, _, _, err := sh.getScaledObjectState(context.TODO(), &scaledObject)
+	scalerCache.Close(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, isError)
+
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	found := false
+	for _, family := range families {
+		if family.GetName() != "keda_scaler_exposed_metrics" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "test-exposed-metrics" && labels["scaledObject"] == "multi-metric" && labels["scaler"] == "prometheus-queries" {
+				found = true
+				assert.Equal(t, float64(2), metric.GetGauge().GetValue())
+			}
+		}
+	}
+	assert.True(t, found, "keda_scaler_exposed_metrics not recorded for the scaler")
+}
+
+// fakeStreamingScaler reports the partitions it reads, like the kafka or kinesis scalers
+type fakeStreamingScaler struct {
+	partitions int
+	polled     bool
+}
+
+func (s *fakeStreamingScaler) GetMetricsAndActivity(_ context.Context, metricName string) ([]external_metrics.ExternalMetricValue, bool, error) {
+	s.polled = true
+	return []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(metricName, 10)}, true, nil
+}
+
+func (s *fakeStreamingScaler) GetMetricSpecForScaling(context.Context) []v2.MetricSpec {
+	return []v2.MetricSpec{createMetricSpec(5, "s0-lag")}
+}
+
+func (s *fakeStreamingScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *fakeStreamingScaler) GetPartitionCount() (int, bool) {
+	return s.partitions, s.polled
+}
+
+func TestScalerPartitions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_client.NewMockClient(ctrl)
+	mockExecutor := mock_executor.NewMockScaleExecutor(ctrl)
+	recorder := record.NewFakeRecorder(1)
+
+	scaler := &fakeStreamingScaler{partitions: 12}
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stream",
+			Namespace: "test-partitions",
+		},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+				Name: "test",
+			},
+		},
+	}
+
+	scalerCache := cache.ScalersCache{
+		Scalers: []cache.ScalerBuilder{{
+			Scaler: scaler,
+			Factory: func() (scalers.Scaler, *scalers.ScalerConfig, error) {
+				return scaler, &scalers.ScalerConfig{TriggerName: "orders-topic"}, nil
+			},
+			ScalerConfig: scalers.ScalerConfig{TriggerName: "orders-topic"},
+		}},
+		Recorder: recorder,
+	}
+
+	caches := map[string]*cache.ScalersCache{}
+	caches[scaledObject.GenerateIdentifier()] = &scalerCache
+
+	sh := newTestScaleHandler(mockClient, recorder, caches)
+	sh.scaleExecutor = mockExecutor
+
+	isActive, isError, _, _, err := sh.getScaledObjectState(context.TODO(), &scaledObject)
+	assert.Nil(t, err)
+	assert.True(t, isActive)
+	assert.False(t, isError)
+
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	found := false
+	for _, family := range families {
+		if family.GetName() != "keda_scaler_partitions" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "test-partitions" && labels["scaledObject"] == "stream" && labels["scaler"] == "orders-topic" {
+				found = true
+				assert.Equal(t, float64(12), metric.GetGauge().GetValue())
+			}
+		}
+	}
+	assert.True(t, found, "keda_scaler_partitions not recorded for the scaler")
+}
+
+func TestScalerUnhealthyByBackend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_client.NewMockClient(ctrl)
+	mockExecutor := mock_executor.NewMockScaleExecutor(ctrl)
+	recorder := record.NewFakeRecorder(10)
+
+	metricsSpecs := []v2.MetricSpec{createMetricSpec(1, "metric-name")}
+	// a failing scaler is rebuilt by the cache and queried once more
+	builder := func(host string, err error) cache.ScalerBuilder {
+		config := scalers.ScalerConfig{TriggerMetadata: map[string]string{"host": host}}
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return(metricsSpecs)
+		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{}, false, err)
+		scaler.EXPECT().Close(gomock.Any())
+		return cache.ScalerBuilder{
+			Scaler: scaler,
+			Factory: func() (scalers.Scaler, *scalers.ScalerConfig, error) {
+				scaler := mock_scalers.NewMockScaler(ctrl)
+				scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{}, false, err)
+				scaler.EXPECT().Close(gomock.Any())
+				return scaler, &config, nil
+			},
+			ScalerConfig: config,
+		}
+	}
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "orders",
+			Namespace: "test-backend-health",
+		},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+				Name: "test",
+			},
+		},
+	}
+
+	backendErr := errors.New("dial tcp: connection refused")
+	scalerCache := cache.ScalersCache{
+		Scalers: []cache.ScalerBuilder{
+			builder("amqp://rabbitmq.test-backend-health:5672/orders", backendErr),
+			builder("amqp://rabbitmq.test-backend-health:5672/invoices", backendErr),
+			builder("postgres.test-backend-health:5432", nil),
+		},
+		Recorder: recorder,
+	}
+
+	caches := map[string]*cache.ScalersCache{}
+	caches[scaledObject.GenerateIdentifier()] = &scalerCache
+
+	sh := newTestScaleHandler(mockClient, recorder, caches)
+	sh.scaleExecutor = mockExecutor
+
+	_, isError, _, _, err := sh.getScaledObjectState(context.TODO(), &scaledObject)
+	assert.Nil(t, err)
+	assert.True(t, isError)
+
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	unhealthy := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "keda_scaler_unhealthy_by_backend" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "backend" {
+					unhealthy[label.GetValue()] = metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	assert.Equal(t, float64(2), unhealthy["rabbitmq.test-backend-health:5672"], "both scalers of the failing backend are unhealthy")
+	assert.Contains(t, unhealthy, "postgres.test-backend-health:5432")
+	assert.Equal(t, float64(0), unhealthy["postgres.test-backend-health:5432"])
+}
+
+func TestScaledObjectDesiredReplicasDistribution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_client.NewMockClient(ctrl)
+	mockExecutor := mock_executor.NewMockScaleExecutor(ctrl)
+	recorder := record.NewFakeRecorder(1)
+
+	// a lag of 10 for an average value of 5 needs 2 replicas
+	scaler := &fakeStreamingScaler{}
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "planned",
+			Namespace: "test-desired-replicas",
+		},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{
+				Name: "test",
+			},
+		},
+	}
+
+	scalerCache := cache.ScalersCache{
+		Scalers: []cache.ScalerBuilder{{
+			Scaler: scaler,
+			Factory: func() (scalers.Scaler, *scalers.ScalerConfig, error) {
+				return scaler, &scalers.ScalerConfig{}, nil
+			},
+		}},
+		Recorder: recorder,
+	}
+
+	caches := map[string]*cache.ScalersCache{}
+	caches[scaledObject.GenerateIdentifier()] = &scalerCache
+
+	sh := newTestScaleHandler(mockClient, recorder, caches)
+	sh.scaleExecutor = mockExecutor
+
+	countBefore, sumBefore, _ := getDesiredReplicasDistribution(t, "test-desired-replicas", "planned")
+	for i := 0; i < 2; i++ {
+		_, _, options, _, err := sh.getScaledObjectState(context.TODO(), &scaledObject)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), options.DesiredReplicas)
+	}
+
+	count, sum, found := getDesiredReplicasDistribution(t, "test-desired-replicas", "planned")
+	assert.True(t, found, "keda_scaledobject_desired_replicas_distribution not recorded for the scaled object")
+	assert.Equal(t, uint64(2), count-countBefore)
+	assert.Equal(t, float64(4), sum-sumBefore)
+}
+
+func getDesiredReplicasDistribution(t *testing.T, namespace, scaledObject string) (uint64, float64, bool) {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_desired_replicas_distribution" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["scaledObject"] == scaledObject {
+				return metric.GetHistogram().GetSampleCount(), metric.GetHistogram().GetSampleSum(), true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func TestScalerRebuildOnSpecChange(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme))
+	assert.Nil(t, kedav1alpha1.AddToScheme(scheme))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-rebuilds"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}},
+			},
+		},
+	}
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "rebuilt", Namespace: "test-rebuilds", Generation: 1},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{Type: "cpu", MetricType: v2.UtilizationMetricType, Metadata: map[string]string{"value": "50"}},
+				{Type: "memory", Name: "memory-trigger", MetricType: v2.UtilizationMetricType, Metadata: map[string]string{"value": "50"}},
+			},
+		},
+		Status: kedav1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &kedav1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"},
+		},
+	}
+
+	sh := newTestScaleHandler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment).Build(), record.NewFakeRecorder(1), map[string]*cache.ScalersCache{})
+
+	// the counter is process global, so only the increment of this test is asserted
+	typeBefore := getScalerRebuilds(t, "rebuilt", "cpuMemoryScaler")
+	nameBefore := getScalerRebuilds(t, "rebuilt", "memory-trigger")
+
+	_, err := sh.GetScalersCache(context.TODO(), scaledObject)
+	assert.Nil(t, err)
+	// the cache is reused while the spec doesn't change
+	_, err = sh.GetScalersCache(context.TODO(), scaledObject)
+	assert.Nil(t, err)
+	assert.Equal(t, typeBefore, getScalerRebuilds(t, "rebuilt", "cpuMemoryScaler"))
+	assert.Equal(t, nameBefore, getScalerRebuilds(t, "rebuilt", "memory-trigger"))
+
+	scaledObject.Spec.Triggers[0].Metadata["value"] = "60"
+	scaledObject.Generation = 2
+	_, err = sh.GetScalersCache(context.TODO(), scaledObject)
+	assert.Nil(t, err)
+	assert.Equal(t, typeBefore+1, getScalerRebuilds(t, "rebuilt", "cpuMemoryScaler"))
+	assert.Equal(t, nameBefore+1, getScalerRebuilds(t, "rebuilt", "memory-trigger"))
+}
+
+func TestGetScalersCacheWithFailedTrigger(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme))
+	assert.Nil(t, kedav1alpha1.AddToScheme(scheme))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-partial"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}},
+			},
+		},
+	}
+	newScaledObject := func(triggers ...kedav1alpha1.ScaleTriggers) *kedav1alpha1.ScaledObject {
+		return &kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Name: "partial", Namespace: "test-partial", Generation: 1},
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+				Triggers:       triggers,
+			},
+			Status: kedav1alpha1.ScaledObjectStatus{
+				ScaleTargetGVKR: &kedav1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"},
+			},
+		}
+	}
+	validTrigger := kedav1alpha1.ScaleTriggers{Type: "cpu", MetricType: v2.UtilizationMetricType, Metadata: map[string]string{"value": "50"}}
+	invalidTrigger := kedav1alpha1.ScaleTriggers{Type: "cpu", MetricType: v2.UtilizationMetricType, Metadata: map[string]string{"value": "fifty"}}
+
+	testCases := []struct {
+		name            string
+		allowPartialHPA bool
+		scaledObject    *kedav1alpha1.ScaledObject
+		expectedScalers int
+		expectedFailed  []int
+		isError         bool
+	}{
+		{
+			name:         "a failed trigger fails the ScaledObject by default",
+			scaledObject: newScaledObject(validTrigger, invalidTrigger),
+			isError:      true,
+		},
+		{
+			name:            "a failed trigger is left out with partial HPAs",
+			allowPartialHPA: true,
+			scaledObject:    newScaledObject(invalidTrigger, validTrigger, validTrigger),
+			expectedScalers: 2,
+			expectedFailed:  []int{0},
+		},
+		{
+			name:            "all valid triggers with partial HPAs",
+			allowPartialHPA: true,
+			scaledObject:    newScaledObject(validTrigger, validTrigger),
+			expectedScalers: 2,
+		},
+		{
+			name:            "all failed triggers fail the ScaledObject with partial HPAs",
+			allowPartialHPA: true,
+			scaledObject:    newScaledObject(invalidTrigger, invalidTrigger),
+			isError:         true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			SetAllowPartialHPA(testCase.allowPartialHPA)
+			t.Cleanup(func() { SetAllowPartialHPA(false) })
+			recorder := record.NewFakeRecorder(10)
+			sh := newTestScaleHandler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment).Build(), recorder, map[string]*cache.ScalersCache{})
+
+			scalersCache, err := sh.GetScalersCache(context.TODO(), testCase.scaledObject)
+			if testCase.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, scalersCache.Scalers, testCase.expectedScalers)
+			assert.Len(t, scalersCache.FailedTriggers, len(testCase.expectedFailed))
+			for i, index := range testCase.expectedFailed {
+				assert.Equal(t, index, scalersCache.FailedTriggers[i].Index)
+				assert.Error(t, scalersCache.FailedTriggers[i].Err)
+				assert.Contains(t, <-recorder.Events, fmt.Sprintf("trigger %d (cpu) is left out of the HPA", index))
+			}
+			assert.Empty(t, recorder.Events)
+		})
+	}
+}
+
+// getScalerMetricCachedLabels returns the cached label of each keda_scaler_metrics_value series of the metric
+func getScalerMetricCachedLabels(t *testing.T, scaledObject, metric string) []string {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	var cached []string
+	for _, family := range families {
+		if family.GetName() != "keda_scaler_metrics_value" {
+			continue
+		}
+		for _, series := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range series.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["scaledObject"] == scaledObject && labels["metric"] == metric {
+				cached = append(cached, labels["cached"])
+			}
+		}
+	}
+	return cached
+}
+
+func getScalerRebuilds(t *testing.T, scaledObject, scaler string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaler_rebuilds_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "test-rebuilds" && labels["scaledObject"] == scaledObject && labels["scaler"] == scaler {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestScalerCAExpiry(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme))
+	assert.Nil(t, kedav1alpha1.AddToScheme(scheme))
+
+	notAfter := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-ca-expiry"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "test-ca-expiry"},
+		Data: map[string][]byte{
+			"ca.crt":     newTestCA(t, notAfter.AddDate(1, 0, 0)),
+			"bundle.crt": append(newTestCA(t, notAfter.AddDate(1, 0, 0)), newTestCA(t, notAfter)...),
+		},
+	}
+	triggerAuth := &kedav1alpha1.TriggerAuthentication{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "test-ca-expiry"},
+		Spec: kedav1alpha1.TriggerAuthenticationSpec{
+			SecretTargetRef: []kedav1alpha1.AuthSecretTargetRef{{Parameter: "ca", Name: "ca", Key: "ca.crt"}},
+		},
+	}
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom-ca", Namespace: "test-ca-expiry", Generation: 1},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{
+					Type:              "metrics-api",
+					Metadata:          map[string]string{"url": "https://localhost/api", "valueLocation": "value", "targetValue": "1"},
+					AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "ca"},
+				},
+				{Type: "cpu", MetricType: v2.UtilizationMetricType, Metadata: map[string]string{"value": "50"}},
+			},
+		},
+		Status: kedav1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &kedav1alpha1.GroupVersionKindResource{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"},
+		},
+	}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, secret, triggerAuth).Build()
+	sh := newTestScaleHandler(kubeClient, record.NewFakeRecorder(1), map[string]*cache.ScalersCache{})
+
+	_, err := sh.GetScalersCache(context.TODO(), scaledObject)
+	assert.Nil(t, err)
+	expiry, found := getScalerCAExpiryMetric(t, "metricsAPIScaler")
+	assert.True(t, found)
+	assert.Equal(t, float64(notAfter.AddDate(1, 0, 0).Unix()), expiry)
+	_, found = getScalerCAExpiryMetric(t, "cpuMemoryScaler")
+	assert.False(t, found, "the expiry is only set for the scalers with a custom CA")
+
+	// the soonest certificate of the bundle is reported after the CA is rotated
+	triggerAuth.Spec.SecretTargetRef[0].Key = "bundle.crt"
+	assert.Nil(t, kubeClient.Update(context.TODO(), triggerAuth))
+	scaledObject.Generation = 2
+	_, err = sh.GetScalersCache(context.TODO(), scaledObject)
+	assert.Nil(t, err)
+	expiry, _ = getScalerCAExpiryMetric(t, "metricsAPIScaler")
+	assert.Equal(t, float64(notAfter.Unix()), expiry)
+
+	// the expiry is removed with the custom CA
+	scaledObject.Spec.Triggers[0].AuthenticationRef = nil
+	scaledObject.Generation = 3
+	_, err = sh.GetScalersCache(context.TODO(), scaledObject)
+	assert.Nil(t, err)
+	_, found = getScalerCAExpiryMetric(t, "metricsAPIScaler")
+	assert.False(t, found)
+}
+
+func getScalerCAExpiryMetric(t *testing.T, scaler string) (float64, bool) {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaler_ca_expiry_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "test-ca-expiry" && labels["scaledObject"] == "custom-ca" && labels["scaler"] == scaler {
+				return metric.GetGauge().GetValue(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// newTestCA returns a PEM encoded self signed CA expiring at notAfter
+func newTestCA(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})
+}
+
 func createMetricSpec(averageValue int64, metricName string) v2.MetricSpec {
 	qty := resource.NewQuantity(averageValue, resource.DecimalSI)
 	return v2.MetricSpec{
@@ -370,3 +970,636 @@ func createMetricSpec(averageValue int64, metricName string) v2.MetricSpec {
 		},
 	}
 }
+
+func TestApplyScaleDownTrendGuard(t *testing.T) {
+	metricName := "test-metric-name"
+	cases := []struct {
+		name          string
+		series        []float64
+		expectedValue float64
+	}{
+		{name: "rising", series: []float64{10, 20, 30}, expectedValue: 50},
+		{name: "falling", series: []float64{30, 20, 10}, expectedValue: 10},
+		{name: "flat", series: []float64{10, 10, 10}, expectedValue: 10},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			scaledObject := &kedav1alpha1.ScaledObject{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+				Spec: kedav1alpha1.ScaledObjectSpec{
+					Advanced: &kedav1alpha1.AdvancedConfig{
+						ScaleDownTrendGuard: &kedav1alpha1.ScaleDownTrendGuard{Window: 3},
+					},
+				},
+			}
+			scalerCache := &cache.ScalersCache{ScaledObject: scaledObject}
+			// the trend follows the ratio of the values to the target of 50
+			for _, value := range c.series {
+				scalerCache.RecordMetricTrend(value/50, 3)
+			}
+
+			sh := scaleHandler{}
+			spec := v2.MetricSpec{
+				External: &v2.ExternalMetricSource{
+					Metric: v2.MetricIdentifier{Name: metricName},
+					Target: v2.MetricTarget{Type: v2.ValueMetricType, Value: resource.NewQuantity(50, resource.DecimalSI)},
+				},
+			}
+			last := scalers.GenerateMetricInMili(metricName, c.series[len(c.series)-1])
+			metrics := sh.applyScaleDownTrendGuard(context.TODO(), log, scalerCache, scaledObject, spec, []external_metrics.ExternalMetricValue{last})
+
+			assert.Equal(t, c.expectedValue, metrics[0].Value.AsApproximateFloat64())
+		})
+	}
+}
+
+func TestApplyScaleDownTrendGuardAverageValue(t *testing.T) {
+	metricName := "test-metric-name"
+	ctrl := gomock.NewController(t)
+	mockClient := mock_client.NewMockClient(ctrl)
+
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			Advanced: &kedav1alpha1.AdvancedConfig{
+				ScaleDownTrendGuard: &kedav1alpha1.ScaleDownTrendGuard{Window: 2},
+			},
+		},
+		Status: kedav1alpha1.ScaledObjectStatus{HpaName: "keda-hpa-test"},
+	}
+	scalerCache := &cache.ScalersCache{ScaledObject: scaledObject}
+	scalerCache.RecordMetricTrend(0.5, 2)
+	scalerCache.RecordMetricTrend(0.8, 2)
+
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ interface{}, hpa *v2.HorizontalPodAutoscaler, _ ...interface{}) error {
+			hpa.Status.CurrentReplicas = 3
+			return nil
+		})
+
+	spec := createMetricSpec(10, metricName)
+	spec.External.Target.Type = v2.AverageValueMetricType
+
+	sh := scaleHandler{client: mockClient}
+	metrics := sh.applyScaleDownTrendGuard(context.TODO(), log, scalerCache, scaledObject, spec,
+		[]external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(metricName, 8)})
+
+	// the HPA holds 3 replicas with an average value of 10
+	assert.Equal(t, float64(30), metrics[0].Value.AsApproximateFloat64())
+}
+
+func TestGetScaledObjectStateRecordsCompositeTrend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	recorder := record.NewFakeRecorder(1)
+
+	newBuilder := func(spec v2.MetricSpec, values ...float64) cache.ScalerBuilder {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{spec}).AnyTimes()
+		for _, value := range values {
+			scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(spec.External.Metric.Name, value)}, true, nil)
+		}
+		scaler.EXPECT().Close(gomock.Any())
+		return cache.ScalerBuilder{Scaler: scaler}
+	}
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "composite", Namespace: "test"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+			Advanced: &kedav1alpha1.AdvancedConfig{
+				ScaleDownTrendGuard: &kedav1alpha1.ScaleDownTrendGuard{Window: 2},
+			},
+		},
+	}
+
+	// the ratio of the stream falls from 2 to 1 but the queue drives the ScaledObject from 1 to 4
+	scalerCache := cache.ScalersCache{
+		Scalers: []cache.ScalerBuilder{
+			newBuilder(createMetricSpec(2, "s0-queue"), 2, 8),
+			newBuilder(createMetricSpec(5, "s1-stream"), 10, 5),
+		},
+		Recorder: recorder,
+	}
+
+	sh := newTestScaleHandler(nil, nil, map[string]*cache.ScalersCache{scaledObject.GenerateIdentifier(): &scalerCache})
+
+	for i := 0; i < 2; i++ {
+		_, _, _, _, err := sh.getScaledObjectState(context.TODO(), &scaledObject)
+		assert.NoError(t, err)
+	}
+	scalerCache.Close(context.Background())
+
+	assert.True(t, scalerCache.IsMetricTrendRising(0))
+	assert.False(t, scalerCache.IsMetricTrendRising(2))
+}
+
+func TestCheckMetricsStaleness(t *testing.T) {
+	metricName := "s0-test-metric-name"
+	scaledObject := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "test"}}
+	start := time.Now()
+
+	cases := []struct {
+		name         string
+		value        float64
+		elapsed      time.Duration
+		maxStaleness time.Duration
+		isError      bool
+	}{
+		{name: "first value", value: 5, elapsed: 0, maxStaleness: 30 * time.Minute},
+		{name: "unchanged within maxStaleness", value: 5, elapsed: 20 * time.Minute, maxStaleness: 30 * time.Minute},
+		{name: "unchanged beyond maxStaleness", value: 5, elapsed: 31 * time.Minute, maxStaleness: 30 * time.Minute, isError: true},
+		{name: "unchanged without maxStaleness", value: 5, elapsed: 40 * time.Minute},
+		{name: "changed value", value: 6, elapsed: 41 * time.Minute, maxStaleness: 30 * time.Minute},
+		{name: "unchanged since the change", value: 6, elapsed: 70 * time.Minute, maxStaleness: 30 * time.Minute},
+		{name: "stale again", value: 6, elapsed: 72 * time.Minute, maxStaleness: 30 * time.Minute, isError: true},
+	}
+
+	sh := scaleHandler{}
+	for _, c := range cases {
+		metrics := []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(metricName, c.value)}
+		err := sh.checkMetricsStaleness(scaledObject, "test-scaler", 0, c.maxStaleness, metrics, start.Add(c.elapsed))
+		if c.isError {
+			assert.Error(t, err, c.name)
+		} else {
+			assert.NoError(t, err, c.name)
+		}
+	}
+
+	// the tracking of the deleted ScaledObject starts again
+	sh.metricsStaleness.Delete(scaledObject.GenerateIdentifier())
+	metrics := []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(metricName, 6)}
+	assert.NoError(t, sh.checkMetricsStaleness(scaledObject, "test-scaler", 0, 30*time.Minute, metrics, start.Add(80*time.Minute)))
+}
+
+func TestGetTriggerMaxStaleness(t *testing.T) {
+	cases := []struct {
+		metadata     map[string]string
+		maxStaleness time.Duration
+		isError      bool
+	}{
+		{metadata: map[string]string{}, maxStaleness: 0},
+		{metadata: map[string]string{"maxStaleness": ""}, maxStaleness: 0},
+		{metadata: map[string]string{"maxStaleness": "30m"}, maxStaleness: 30 * time.Minute},
+		{metadata: map[string]string{"maxStaleness": "30"}, isError: true},
+		{metadata: map[string]string{"maxStaleness": "-1m"}, isError: true},
+	}
+
+	for _, c := range cases {
+		maxStaleness, err := getTriggerMaxStaleness(c.metadata)
+		if c.isError {
+			assert.Error(t, err, c.metadata)
+			continue
+		}
+		assert.NoError(t, err, c.metadata)
+		assert.Equal(t, c.maxStaleness, maxStaleness, c.metadata)
+	}
+}
+
+func TestGetTriggerPollingInterval(t *testing.T) {
+	cases := []struct {
+		metadata        map[string]string
+		pollingInterval time.Duration
+		isError         bool
+	}{
+		{metadata: map[string]string{}, pollingInterval: 0},
+		{metadata: map[string]string{"pollingInterval": ""}, pollingInterval: 0},
+		{metadata: map[string]string{"pollingInterval": "300"}, pollingInterval: 5 * time.Minute},
+		{metadata: map[string]string{"pollingInterval": "5m"}, pollingInterval: 5 * time.Minute},
+		{metadata: map[string]string{"pollingInterval": "0"}, isError: true},
+		{metadata: map[string]string{"pollingInterval": "-1m"}, isError: true},
+		{metadata: map[string]string{"pollingInterval": "often"}, isError: true},
+	}
+
+	for _, c := range cases {
+		pollingInterval, err := getTriggerPollingInterval(c.metadata)
+		if c.isError {
+			assert.Error(t, err, c.metadata)
+			continue
+		}
+		assert.NoError(t, err, c.metadata)
+		assert.Equal(t, c.pollingInterval, pollingInterval, c.metadata)
+	}
+}
+
+func TestGetScaledObjectStateTriggerPollingInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	recorder := record.NewFakeRecorder(1)
+
+	// the cron trigger is queried on every poll of the ScaledObject, the expensive one only once in 5 minutes
+	cronScaler := mock_scalers.NewMockScaler(ctrl)
+	cronScaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{createMetricSpec(1, "s0-cron")}).Times(2)
+	gomock.InOrder(
+		cronScaler.EXPECT().GetMetricsAndActivity(gomock.Any(), "s0-cron").Return([]external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili("s0-cron", 1)}, true, nil),
+		cronScaler.EXPECT().GetMetricsAndActivity(gomock.Any(), "s0-cron").Return([]external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili("s0-cron", 0)}, false, nil),
+	)
+	cronScaler.EXPECT().Close(gomock.Any())
+
+	expensiveScaler := mock_scalers.NewMockScaler(ctrl)
+	expensiveScaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{createMetricSpec(2, "s1-bigquery")}).Times(2)
+	expensiveScaler.EXPECT().GetMetricsAndActivity(gomock.Any(), "s1-bigquery").Return([]external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili("s1-bigquery", 7)}, true, nil).Times(1)
+	expensiveScaler.EXPECT().Close(gomock.Any())
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-trigger-polling-interval", Namespace: "test"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+		},
+	}
+	scalerCache := cache.ScalersCache{
+		Scalers: []cache.ScalerBuilder{
+			{Scaler: cronScaler},
+			{Scaler: expensiveScaler, ScalerConfig: scalers.ScalerConfig{TriggerPollingInterval: 5 * time.Minute}},
+		},
+		Recorder: recorder,
+	}
+	sh := newTestScaleHandler(nil, nil, map[string]*cache.ScalersCache{scaledObject.GenerateIdentifier(): &scalerCache})
+
+	isActive, isError, options, _, err := sh.getScaledObjectState(context.TODO(), &scaledObject)
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+	assert.False(t, isError)
+	assert.Equal(t, int32(4), options.DesiredReplicas)
+	assert.Equal(t, map[string]bool{"s0-cron": true, "s1-bigquery": true}, options.TriggersActivity)
+
+	// the last value of the expensive trigger is reused, the ScaledObject stays active through it
+	isActive, isError, options, _, err = sh.getScaledObjectState(context.TODO(), &scaledObject)
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+	assert.False(t, isError)
+	assert.Equal(t, int32(4), options.DesiredReplicas)
+	assert.Equal(t, map[string]bool{"s0-cron": false, "s1-bigquery": true}, options.TriggersActivity)
+
+	scalerCache.Close(context.Background())
+}
+
+func TestGetScaledObjectStateDesiredReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	recorder := record.NewFakeRecorder(1)
+
+	newScaler := func(averageValue int64, metricName string, value float64) *mock_scalers.MockScaler {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{createMetricSpec(averageValue, metricName)})
+		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(metricName, value)}, true, nil)
+		scaler.EXPECT().Close(gomock.Any())
+		return scaler
+	}
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+		},
+	}
+
+	// 7 / 2 needs 4 replicas and 30 / 5 needs 6 replicas
+	scalerCache := cache.ScalersCache{
+		Scalers: []cache.ScalerBuilder{
+			{Scaler: newScaler(2, "s0-queue", 7)},
+			{Scaler: newScaler(5, "s1-stream", 30)},
+		},
+		Recorder: recorder,
+	}
+
+	sh := newTestScaleHandler(nil, nil, map[string]*cache.ScalersCache{scaledObject.GenerateIdentifier(): &scalerCache})
+
+	isActive, isError, options, _, err := sh.getScaledObjectState(context.TODO(), &scaledObject)
+	scalerCache.Close(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+	assert.False(t, isError)
+	assert.Equal(t, int32(6), options.DesiredReplicas)
+	assert.Equal(t, map[string]bool{"s0-queue": true, "s1-stream": true}, options.TriggersActivity)
+}
+
+func TestScaledObjectTriggerContribution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	recorder := record.NewFakeRecorder(1)
+
+	newBuilder := func(triggerName string, spec v2.MetricSpec, value float64) cache.ScalerBuilder {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{spec})
+		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili(spec.External.Metric.Name, value)}, true, nil)
+		scaler.EXPECT().Close(gomock.Any())
+		return cache.ScalerBuilder{Scaler: scaler, ScalerConfig: scalers.ScalerConfig{TriggerName: triggerName}}
+	}
+	valueSpec := v2.MetricSpec{External: &v2.ExternalMetricSource{
+		Metric: v2.MetricIdentifier{Name: "s2-latency"},
+		Target: v2.MetricTarget{Type: v2.ValueMetricType, Value: resource.NewQuantity(100, resource.DecimalSI)},
+	}}
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "combined", Namespace: "test-contribution"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+		},
+	}
+
+	// 7 / 2 needs 4 replicas, 30 / 5 needs 6 replicas and the Value target of the latency is left to the HPA
+	scalerCache := cache.ScalersCache{
+		Scalers: []cache.ScalerBuilder{
+			newBuilder("queue", createMetricSpec(2, "s0-queue"), 7),
+			newBuilder("stream", createMetricSpec(5, "s1-stream"), 30),
+			newBuilder("latency", valueSpec, 250),
+		},
+		Recorder: recorder,
+	}
+
+	sh := newTestScaleHandler(nil, nil, map[string]*cache.ScalersCache{scaledObject.GenerateIdentifier(): &scalerCache})
+
+	_, _, options, _, err := sh.getScaledObjectState(context.TODO(), &scaledObject)
+	scalerCache.Close(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), options.DesiredReplicas)
+
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	contributions := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_trigger_contribution" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "test-contribution" && labels["scaledObject"] == "combined" {
+				contributions[labels["scaler"]+"/"+labels["scalerIndex"]+"/"+labels["metric"]] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"queue/0/s0-queue": 4, "stream/1/s1-stream": 6}, contributions)
+}
+
+func TestGetDesiredReplicas(t *testing.T) {
+	valueSpec := createMetricSpec(10, "metric")
+	valueSpec.External.Target = v2.MetricTarget{Type: v2.ValueMetricType, Value: resource.NewQuantity(10, resource.DecimalSI)}
+
+	cases := []struct {
+		name     string
+		spec     v2.MetricSpec
+		value    float64
+		replicas int32
+	}{
+		{name: "exact", spec: createMetricSpec(10, "metric"), value: 30, replicas: 3},
+		{name: "rounded up", spec: createMetricSpec(10, "metric"), value: 31, replicas: 4},
+		{name: "below target", spec: createMetricSpec(10, "metric"), value: 0.5, replicas: 1},
+		{name: "zero value", spec: createMetricSpec(10, "metric"), value: 0, replicas: 0},
+		{name: "zero target", spec: createMetricSpec(0, "metric"), value: 30, replicas: 0},
+		{name: "value target", spec: valueSpec, value: 30, replicas: 0},
+		{name: "resource metric", spec: v2.MetricSpec{Resource: &v2.ResourceMetricSource{}}, value: 30, replicas: 0},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.replicas, getDesiredReplicas(c.spec, c.value), c.name)
+	}
+}
+
+func TestReconcileBudgetExceededBySlowScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	recorder := record.NewFakeRecorder(1)
+	pollingInterval := int32(1)
+
+	newScaledObject := func(name string) kedav1alpha1.ScaledObject {
+		return kedav1alpha1.ScaledObject{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-reconcile-budget"},
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				ScaleTargetRef:  &kedav1alpha1.ScaleTarget{Name: name},
+				PollingInterval: &pollingInterval,
+			},
+		}
+	}
+	newScaler := func(delay time.Duration) *mock_scalers.MockScaler {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{createMetricSpec(1, "metric-name")})
+		scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, string) ([]external_metrics.ExternalMetricValue, bool, error) {
+				time.Sleep(delay)
+				return []external_metrics.ExternalMetricValue{scalers.GenerateMetricInMili("metric-name", 1)}, true, nil
+			})
+		scaler.EXPECT().Close(gomock.Any())
+		return scaler
+	}
+
+	slow := newScaledObject("slow")
+	fast := newScaledObject("fast")
+	// the slow scaler takes longer than the pollingInterval by itself, the other scalers are fast
+	slowCache := cache.ScalersCache{
+		Scalers:  []cache.ScalerBuilder{{Scaler: newScaler(0)}, {Scaler: newScaler(1100 * time.Millisecond)}},
+		Recorder: recorder,
+	}
+	fastCache := cache.ScalersCache{
+		Scalers:  []cache.ScalerBuilder{{Scaler: newScaler(0)}},
+		Recorder: recorder,
+	}
+
+	sh := newTestScaleHandler(nil, nil, map[string]*cache.ScalersCache{
+		slow.GenerateIdentifier(): &slowCache,
+		fast.GenerateIdentifier(): &fastCache,
+	})
+
+	// the counter is process global, so only the increment of this test is asserted
+	slowBefore := getReconcileBudgetExceeded(t, "slow")
+	fastBefore := getReconcileBudgetExceeded(t, "fast")
+
+	_, isError, _, _, err := sh.getScaledObjectState(context.TODO(), &slow)
+	assert.NoError(t, err)
+	assert.False(t, isError)
+	_, isError, _, _, err = sh.getScaledObjectState(context.TODO(), &fast)
+	assert.NoError(t, err)
+	assert.False(t, isError)
+	slowCache.Close(context.Background())
+	fastCache.Close(context.Background())
+
+	assert.Equal(t, slowBefore+1, getReconcileBudgetExceeded(t, "slow"))
+	assert.Equal(t, fastBefore, getReconcileBudgetExceeded(t, "fast"))
+}
+
+func getReconcileBudgetExceeded(t *testing.T, scaledObject string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_reconcile_budget_exceeded_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "test-reconcile-budget" && labels["scaledObject"] == scaledObject {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestGetScaledObjectMetricsOneOfThreeFailing(t *testing.T) {
+	metricName := "test-metric-name"
+	cases := []struct {
+		name           string
+		fallback       *kedav1alpha1.Fallback
+		expectedValues []float64
+		expectedReason string
+	}{
+		{name: "without-fallback", expectedValues: []float64{10, 30}, expectedReason: "partial"},
+		// the fallback value is the target of 5 times the 4 fallback replicas
+		{name: "with-fallback", fallback: &kedav1alpha1.Fallback{FailureThreshold: 0, Replicas: 4}, expectedValues: []float64{10, 20, 30}, expectedReason: "fallback"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockClient := mock_client.NewMockClient(ctrl)
+			mockStatusWriter := mock_client.NewMockStatusWriter(ctrl)
+			mockClient.EXPECT().Status().Return(mockStatusWriter).AnyTimes()
+			mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+			scaledObject := kedav1alpha1.ScaledObject{
+				ObjectMeta: metav1.ObjectMeta{Name: c.name, Namespace: "test-degraded-metrics"},
+				Spec: kedav1alpha1.ScaledObjectSpec{
+					ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+					Fallback:       c.fallback,
+				},
+			}
+			sh := newDegradedMetricsTestHandler(ctrl, mockClient, &scaledObject, metricName, []error{nil, errors.New("backend is down"), nil})
+			// the counter is process global, so only the increment of this test is asserted
+			before := getDegradedMetrics(t, c.name, c.expectedReason)
+
+			metrics, err := sh.GetScaledObjectMetrics(context.TODO(), scaledObject.Name, scaledObject.Namespace, metricName)
+			assert.NoError(t, err)
+			var values []float64
+			for _, metric := range metrics.Items {
+				values = append(values, metric.Value.AsApproximateFloat64())
+			}
+			assert.ElementsMatch(t, c.expectedValues, values)
+			assert.Equal(t, before+1, getDegradedMetrics(t, c.name, c.expectedReason))
+		})
+	}
+}
+
+func TestGetScaledObjectMetricsAllFailing(t *testing.T) {
+	metricName := "test-metric-name"
+	ctrl := gomock.NewController(t)
+	mockClient := mock_client.NewMockClient(ctrl)
+	mockStatusWriter := mock_client.NewMockStatusWriter(ctrl)
+	mockClient.EXPECT().Status().Return(mockStatusWriter).AnyTimes()
+	mockStatusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	scaledObject := kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "all-failing", Namespace: "test-degraded-metrics"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "test"},
+		},
+	}
+	backendErr := errors.New("backend is down")
+	sh := newDegradedMetricsTestHandler(ctrl, mockClient, &scaledObject, metricName, []error{backendErr, backendErr, backendErr})
+	before := getDegradedMetrics(t, "all-failing", "partial")
+
+	_, err := sh.GetScaledObjectMetrics(context.TODO(), scaledObject.Name, scaledObject.Namespace, metricName)
+	assert.ErrorIs(t, err, backendErr)
+	assert.Equal(t, before, getDegradedMetrics(t, "all-failing", "partial"))
+}
+
+// newTestScaleHandler returns a scale handler with the scaler caches, the tests set the other fields they need
+func newTestScaleHandler(kubeClient client.Client, recorder record.EventRecorder, scalerCaches map[string]*cache.ScalersCache) *scaleHandler {
+	return &scaleHandler{
+		client:                   kubeClient,
+		scaleLoopContexts:        &sync.Map{},
+		globalHTTPTimeout:        time.Duration(1000),
+		recorder:                 recorder,
+		scalerCaches:             scalerCaches,
+		scalerCachesLock:         &sync.RWMutex{},
+		scaledObjectsMetricCache: metricscache.NewMetricsCache(),
+	}
+}
+
+// newDegradedMetricsTestHandler returns a scale handler with a scaler per error exposing the same metric, the
+// scaler of index i returns the value 10*(i+1) unless its error is set
+func newDegradedMetricsTestHandler(ctrl *gomock.Controller, mockClient *mock_client.MockClient, scaledObject *kedav1alpha1.ScaledObject, metricName string, errs []error) *scaleHandler {
+	spec := createMetricSpec(5, metricName)
+	spec.External.Target.Type = v2.AverageValueMetricType
+
+	scalerCache := cache.ScalersCache{
+		ScaledObject: scaledObject,
+		Recorder:     record.NewFakeRecorder(10),
+	}
+	for i, err := range errs {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2.MetricSpec{spec}).AnyTimes()
+		if err != nil {
+			scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return(nil, false, err).AnyTimes()
+		} else {
+			value := scalers.GenerateMetricInMili(metricName, float64(10*(i+1)))
+			scaler.EXPECT().GetMetricsAndActivity(gomock.Any(), gomock.Any()).Return([]external_metrics.ExternalMetricValue{value}, true, nil)
+		}
+		scaler.EXPECT().Close(gomock.Any()).AnyTimes()
+		scalerConfig := scalers.ScalerConfig{TriggerName: fmt.Sprintf("trigger-%d", i)}
+		scalerCache.Scalers = append(scalerCache.Scalers, cache.ScalerBuilder{
+			Scaler:       scaler,
+			ScalerConfig: scalerConfig,
+			Factory: func() (scalers.Scaler, *scalers.ScalerConfig, error) {
+				return scaler, &scalerConfig, nil
+			},
+		})
+	}
+
+	return newTestScaleHandler(mockClient, nil, map[string]*cache.ScalersCache{scaledObject.GenerateIdentifier(): &scalerCache})
+}
+
+func getDegradedMetrics(t *testing.T, scaledObject, reason string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaledobject_degraded_metrics_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == "test-degraded-metrics" && labels["scaledObject"] == scaledObject && labels["reason"] == reason {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+type countScaler struct {
+	scalers.Scaler
+	clamp bool
+}
+
+func (s *countScaler) ClampNegativeMetrics() bool {
+	return s.clamp
+}
+
+func TestClampNegativeMetrics(t *testing.T) {
+	scaledObject := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Name: "clamp-so", Namespace: "test-clamp"}}
+	newMetrics := func(values ...int64) []external_metrics.ExternalMetricValue {
+		metrics := []external_metrics.ExternalMetricValue{}
+		for _, value := range values {
+			metrics = append(metrics, external_metrics.ExternalMetricValue{MetricName: "s0-queue", Value: *resource.NewQuantity(value, resource.DecimalSI)})
+		}
+		return metrics
+	}
+
+	// the scalers which don't opt in keep their negative values
+	metrics := newMetrics(-2)
+	assert.True(t, clampNegativeMetrics(scaledObject, &countScaler{}, "queueScaler", 0, metrics, true))
+	assert.Equal(t, int64(-2), metrics[0].Value.Value())
+
+	metrics = newMetrics(-3)
+	assert.False(t, clampNegativeMetrics(scaledObject, &countScaler{clamp: true}, "queueScaler", 0, metrics, true))
+	assert.Equal(t, int64(0), metrics[0].Value.Value())
+
+	metrics = newMetrics(-3, 5)
+	assert.True(t, clampNegativeMetrics(scaledObject, &countScaler{clamp: true}, "queueScaler", 0, metrics, true))
+	assert.Equal(t, int64(0), metrics[0].Value.Value())
+	assert.Equal(t, int64(5), metrics[1].Value.Value())
+}
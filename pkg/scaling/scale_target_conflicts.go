@@ -0,0 +1,126 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+// scaleTarget identifies the workload referenced by the scaleTargetRef of a ScaledObject
+type scaleTarget struct {
+	namespace string
+	kind      string
+	name      string
+}
+
+// ScaleTargetConflictDetector periodically counts the ScaledObjects referencing each scale target. The admission
+// webhooks reject a second ScaledObject for the same target, but not when they are disabled or the objects were
+// created before
+type ScaleTargetConflictDetector struct {
+	client   client.Client
+	interval time.Duration
+	logger   logr.Logger
+
+	// targets are the scale targets with a recorded count
+	targets map[scaleTarget]int
+}
+
+// NewScaleTargetConflictDetector creates a detector counting the ScaledObjects of the scale targets every interval
+func NewScaleTargetConflictDetector(client client.Client, interval time.Duration, logger logr.Logger) (*ScaleTargetConflictDetector, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("the interval of the scale target conflict detection must be positive, %s given", interval)
+	}
+	return &ScaleTargetConflictDetector{
+		client:   client,
+		interval: interval,
+		logger:   logger,
+		targets:  map[scaleTarget]int{},
+	}, nil
+}
+
+// Start counts the ScaledObjects every interval until the context is done, this implements the Runnable interface
+// of controller-runtime Manager, so we can use mgr.Add() to start this component.
+func (d *ScaleTargetConflictDetector) Start(ctx context.Context) error {
+	d.logger.Info("Starting scale target conflict detection", "interval", d.interval)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		if err := d.Detect(ctx); err != nil {
+			d.logger.Error(err, "error detecting the scale targets referenced by several ScaledObjects")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection returns true, like the other ScaledObject metrics the counts are only recorded by the leader
+func (d *ScaleTargetConflictDetector) NeedLeaderElection() bool {
+	return true
+}
+
+// Detect records the number of ScaledObjects of every scale target and counts the targets newly referenced by
+// more than one of them, the metrics of the targets without ScaledObjects are deleted
+func (d *ScaleTargetConflictDetector) Detect(ctx context.Context) error {
+	scaledObjects := &kedav1alpha1.ScaledObjectList{}
+	if err := d.client.List(ctx, scaledObjects); err != nil {
+		return fmt.Errorf("error listing the ScaledObjects: %w", err)
+	}
+
+	counts := map[scaleTarget]int{}
+	names := map[scaleTarget][]string{}
+	for i := range scaledObjects.Items {
+		scaledObject := &scaledObjects.Items[i]
+		if scaledObject.Spec.ScaleTargetRef == nil {
+			continue
+		}
+		kind := scaledObject.Spec.ScaleTargetRef.Kind
+		if kind == "" {
+			kind = "Deployment"
+		}
+		target := scaleTarget{namespace: scaledObject.Namespace, kind: kind, name: scaledObject.Spec.ScaleTargetRef.Name}
+		counts[target]++
+		names[target] = append(names[target], scaledObject.Name)
+	}
+
+	for target := range d.targets {
+		if _, found := counts[target]; !found {
+			prommetrics.DeleteScaleTargetScaledObjects(target.namespace, target.kind, target.name)
+			delete(d.targets, target)
+		}
+	}
+	for target, count := range counts {
+		if count > 1 && d.targets[target] <= 1 {
+			d.logger.Info("Scale target referenced by several ScaledObjects, their HPAs fight over the replicas",
+				"namespace", target.namespace, "kind", target.kind, "name", target.name, "scaledObjects", names[target])
+			prommetrics.RecordScaleTargetConflict()
+		}
+		prommetrics.RecordScaleTargetScaledObjects(target.namespace, target.kind, target.name, count)
+		d.targets[target] = count
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func newConflictTestScaledObject(namespace, name, kind, target string) *kedav1alpha1.ScaledObject {
+	return &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Kind: kind, Name: target},
+		},
+	}
+}
+
+func TestScaleTargetConflictDetector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, kedav1alpha1.AddToScheme(scheme))
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		// the kind defaults to Deployment
+		newConflictTestScaledObject("conflicts-a", "first", "", "app"),
+		newConflictTestScaledObject("conflicts-a", "second", "Deployment", "app"),
+		// the same name in another kind or namespace is another target
+		newConflictTestScaledObject("conflicts-a", "third", "StatefulSet", "app"),
+		newConflictTestScaledObject("conflicts-b", "first", "Deployment", "app"),
+	).Build()
+
+	detector, err := NewScaleTargetConflictDetector(kubeClient, 1, logr.Discard())
+	require.NoError(t, err)
+
+	conflicts := getScaleTargetConflicts(t)
+	require.NoError(t, detector.Detect(context.Background()))
+	assertScaleTargetScaledObjects(t, "conflicts-a", "Deployment", "app", 2)
+	assertScaleTargetScaledObjects(t, "conflicts-a", "StatefulSet", "app", 1)
+	assertScaleTargetScaledObjects(t, "conflicts-b", "Deployment", "app", 1)
+	assert.Equal(t, conflicts+1, getScaleTargetConflicts(t))
+
+	// an ongoing conflict is only counted once
+	require.NoError(t, kubeClient.Create(context.Background(), newConflictTestScaledObject("conflicts-a", "fourth", "Deployment", "app")))
+	require.NoError(t, detector.Detect(context.Background()))
+	assertScaleTargetScaledObjects(t, "conflicts-a", "Deployment", "app", 3)
+	assert.Equal(t, conflicts+1, getScaleTargetConflicts(t))
+
+	// the targets without ScaledObjects are deleted, a resolved conflict is counted again when it reappears
+	for _, name := range []string{"second", "fourth"} {
+		require.NoError(t, kubeClient.Delete(context.Background(), &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Namespace: "conflicts-a", Name: name}}))
+	}
+	require.NoError(t, kubeClient.Delete(context.Background(), &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Namespace: "conflicts-b", Name: "first"}}))
+	require.NoError(t, detector.Detect(context.Background()))
+	assertScaleTargetScaledObjects(t, "conflicts-a", "Deployment", "app", 1)
+	_, found := getScaleTargetScaledObjects(t, "conflicts-b", "Deployment", "app")
+	assert.False(t, found)
+
+	require.NoError(t, kubeClient.Create(context.Background(), newConflictTestScaledObject("conflicts-b", "first", "Deployment", "app")))
+	require.NoError(t, kubeClient.Create(context.Background(), newConflictTestScaledObject("conflicts-b", "second", "Deployment", "app")))
+	require.NoError(t, detector.Detect(context.Background()))
+	assertScaleTargetScaledObjects(t, "conflicts-b", "Deployment", "app", 2)
+	assert.Equal(t, conflicts+2, getScaleTargetConflicts(t))
+}
+
+func TestNewScaleTargetConflictDetectorInterval(t *testing.T) {
+	_, err := NewScaleTargetConflictDetector(nil, 0, logr.Discard())
+	assert.Error(t, err)
+}
+
+func assertScaleTargetScaledObjects(t *testing.T, namespace, kind, name string, expected float64) {
+	t.Helper()
+	value, found := getScaleTargetScaledObjects(t, namespace, kind, name)
+	assert.True(t, found)
+	assert.Equal(t, expected, value)
+}
+
+func getScaleTargetScaledObjects(t *testing.T, namespace, kind, name string) (float64, bool) {
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "keda_scaletarget_scaledobject_count" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["kind"] == kind && labels["name"] == name {
+				return metric.GetGauge().GetValue(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func getScaleTargetConflicts(t *testing.T) float64 {
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "keda_scaletarget_conflicts_total" {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// logScalerCalls is set by the --log-scaler-calls flag of the operator
+var logScalerCalls atomic.Bool
+
+// SetLogScalerCalls enables logging every metrics query of the scalers, the entries carry the correlation ID of the
+// poll or of the metrics server request, so a decision of the HPA can be matched with the scaler calls behind it
+func SetLogScalerCalls(enabled bool) {
+	logScalerCalls.Store(enabled)
+}
+
+// logScalerCall logs a GetMetricsAndActivity call of the scaler built from scalerConfig, if enabled
+func logScalerCall(logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, scalerConfig scalers.ScalerConfig, metricName, correlationID string, duration time.Duration, err error) {
+	if !logScalerCalls.Load() {
+		return
+	}
+
+	triggerType := ""
+	if scalerConfig.ScalerIndex >= 0 && scalerConfig.ScalerIndex < len(scaledObject.Spec.Triggers) {
+		triggerType = scaledObject.Spec.Triggers[scalerConfig.ScalerIndex].Type
+	}
+	keysAndValues := []interface{}{
+		"scaledObject", scaledObject.Name,
+		"namespace", scaledObject.Namespace,
+		"triggerType", triggerType,
+		"triggerIndex", scalerConfig.ScalerIndex,
+		"metricName", metricName,
+		"durationMs", duration.Milliseconds(),
+		"correlationID", correlationID,
+	}
+	if err != nil {
+		keysAndValues = append(keysAndValues, "error", err.Error())
+	}
+	logger.Info("Scaler call", keysAndValues...)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestLogScalerCall(t *testing.T) {
+	var entries []map[string]interface{}
+	logger := funcr.NewJSON(func(obj string) {
+		entry := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(obj), &entry))
+		entries = append(entries, entry)
+	}, funcr.Options{})
+
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "name"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			Triggers: []kedav1alpha1.ScaleTriggers{{Type: "cpu"}, {Type: "kafka"}},
+		},
+	}
+	scalerConfig := scalers.ScalerConfig{ScalerIndex: 1}
+
+	// disabled by default
+	logScalerCall(logger, scaledObject, scalerConfig, "s1-kafka-topic", "correlation", time.Second, nil)
+	assert.Empty(t, entries)
+
+	SetLogScalerCalls(true)
+	t.Cleanup(func() { SetLogScalerCalls(false) })
+
+	logScalerCall(logger, scaledObject, scalerConfig, "s1-kafka-topic", "correlation", 1500*time.Millisecond, nil)
+	logScalerCall(logger, scaledObject, scalers.ScalerConfig{ScalerIndex: 2}, "s2-unknown", "correlation", 0, errors.New("failed"))
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "Scaler call", entries[0]["msg"])
+	assert.Equal(t, "name", entries[0]["scaledObject"])
+	assert.Equal(t, "namespace", entries[0]["namespace"])
+	assert.Equal(t, "kafka", entries[0]["triggerType"])
+	assert.Equal(t, 1.0, entries[0]["triggerIndex"])
+	assert.Equal(t, "s1-kafka-topic", entries[0]["metricName"])
+	assert.Equal(t, 1500.0, entries[0]["durationMs"])
+	assert.Equal(t, "correlation", entries[0]["correlationID"])
+	assert.NotContains(t, entries[0], "error")
+
+	// the trigger type is empty when the index doesn't match a trigger
+	assert.Equal(t, "", entries[1]["triggerType"])
+	assert.Equal(t, "failed", entries[1]["error"])
+}
@@ -19,6 +19,9 @@ package scaling
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -28,18 +31,36 @@ import (
 	"github.com/kedacore/keda/v2/pkg/scalers"
 	"github.com/kedacore/keda/v2/pkg/scaling/cache"
 	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 /// --------------------------------------------------------------------------- ///
 /// ----------            Scaler-Building related methods             --------- ///
 /// --------------------------------------------------------------------------- ///
 
-// buildScalers returns list of Scalers for the specified triggers
-func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers, podTemplateSpec *corev1.PodTemplateSpec, containerName string) ([]cache.ScalerBuilder, error) {
+// allowPartialHPA is set when the triggers of a ScaledObject whose scaler can't be built are left out of its HPA,
+// instead of failing the whole ScaledObject
+var allowPartialHPA atomic.Bool
+
+// SetAllowPartialHPA sets whether the ScaledObjects with failing triggers are scaled on their remaining triggers
+func SetAllowPartialHPA(allowed bool) {
+	allowPartialHPA.Store(allowed)
+}
+
+// IsPartialHPAAllowed returns whether the ScaledObjects with failing triggers are scaled on their remaining triggers
+func IsPartialHPAAllowed() bool {
+	return allowPartialHPA.Load()
+}
+
+// buildScalers returns list of Scalers for the specified triggers. When partial HPAs are allowed, the triggers of
+// a ScaledObject whose scaler can't be built are returned as failed as long as one of its triggers can be built
+func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers, podTemplateSpec *corev1.PodTemplateSpec, containerName string) ([]cache.ScalerBuilder, []cache.FailedTrigger, error) {
 	logger := log.WithValues("type", withTriggers.Kind, "namespace", withTriggers.Namespace, "name", withTriggers.Name)
 	var err error
 	resolvedEnv := make(map[string]string)
 	result := make([]cache.ScalerBuilder, 0, len(withTriggers.Spec.Triggers))
+	var failedTriggers []cache.FailedTrigger
+	partial := withTriggers.InternalKind == "ScaledObject" && allowPartialHPA.Load()
 
 	for i, t := range withTriggers.Spec.Triggers {
 		triggerIndex, trigger := i, t
@@ -56,6 +77,7 @@ func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alp
 				ScalableObjectNamespace: withTriggers.Namespace,
 				ScalableObjectType:      withTriggers.Kind,
 				TriggerName:             trigger.Name,
+				TriggerType:             trigger.Type,
 				TriggerMetadata:         trigger.Metadata,
 				TriggerUseCachedMetrics: trigger.UseCachedMetrics,
 				ResolvedEnv:             resolvedEnv,
@@ -71,11 +93,38 @@ func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alp
 			}
 			config.AuthParams = authParams
 			config.PodIdentity = podIdentity
+			config.HTTPProxy, err = scalers.GetHTTPProxy(config)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error parsing proxy for trigger: %w", err)
+			}
+			config.TriggerMaxStaleness, err = getTriggerMaxStaleness(trigger.Metadata)
+			if err != nil {
+				return nil, nil, err
+			}
+			config.TriggerPollingInterval, err = getTriggerPollingInterval(trigger.Metadata)
+			if err != nil {
+				return nil, nil, err
+			}
+			if withTriggers.InternalKind == "ScaledJob" {
+				if _, err := cache.GetTriggerWeight(trigger.Metadata); err != nil {
+					return nil, nil, err
+				}
+			}
 			scaler, err := buildScaler(ctx, h.client, trigger.Type, config)
 			return scaler, config, err
 		}
 
 		scaler, config, err := factory()
+		if err != nil && partial {
+			msg := fmt.Sprintf("trigger %d (%s) is left out of the HPA: %s", triggerIndex, trigger.Type, err)
+			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, msg)
+			logger.Error(err, "error building scaler, the trigger is left out of the HPA", "scalerIndex", triggerIndex)
+			if scaler != nil {
+				scaler.Close(ctx)
+			}
+			failedTriggers = append(failedTriggers, cache.FailedTrigger{Index: triggerIndex, Name: trigger.Name, Type: trigger.Type, Err: err})
+			continue
+		}
 		if err != nil {
 			h.recorder.Event(withTriggers, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
 			logger.Error(err, "error resolving auth params", "scalerIndex", triggerIndex)
@@ -85,7 +134,7 @@ func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alp
 			for _, builder := range result {
 				builder.Scaler.Close(ctx)
 			}
-			return nil, err
+			return nil, nil, err
 		}
 
 		result = append(result, cache.ScalerBuilder{
@@ -95,7 +144,63 @@ func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alp
 		})
 	}
 
-	return result, nil
+	if len(result) == 0 && len(failedTriggers) > 0 {
+		return nil, nil, fmt.Errorf("no trigger of %s could be built: %w", withTriggers.Kind, failedTriggers[0].Err)
+	}
+	return result, failedTriggers, nil
+}
+
+// getTriggerMaxStaleness parses the optional maxStaleness duration of the trigger metadata
+func getTriggerMaxStaleness(metadata map[string]string) (time.Duration, error) {
+	val, ok := metadata["maxStaleness"]
+	if !ok || val == "" {
+		return 0, nil
+	}
+	maxStaleness, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing maxStaleness: %w", err)
+	}
+	if maxStaleness < 0 {
+		return 0, fmt.Errorf("maxStaleness must not be negative, got %s", val)
+	}
+	return maxStaleness, nil
+}
+
+// getTriggerPollingInterval parses the optional pollingInterval of the trigger metadata, a number of seconds like
+// the pollingInterval of the ScaledObject or a duration
+func getTriggerPollingInterval(metadata map[string]string) (time.Duration, error) {
+	val, ok := metadata["pollingInterval"]
+	if !ok || val == "" {
+		return 0, nil
+	}
+	var pollingInterval time.Duration
+	if seconds, err := strconv.Atoi(val); err == nil {
+		pollingInterval = time.Duration(seconds) * time.Second
+	} else if pollingInterval, err = time.ParseDuration(val); err != nil {
+		return 0, fmt.Errorf("error parsing pollingInterval: %w", err)
+	}
+	if pollingInterval <= 0 {
+		return 0, fmt.Errorf("pollingInterval must be positive, got %s", val)
+	}
+	return pollingInterval, nil
+}
+
+// getScalerCAExpiry returns the soonest expiry of the custom CA bundle given to the scaler in its ca or caCert
+// auth parameter, if any
+func getScalerCAExpiry(config *scalers.ScalerConfig) (time.Time, bool) {
+	for _, param := range []string{"ca", "caCert"} {
+		ca := config.AuthParams[param]
+		if ca == "" {
+			continue
+		}
+		notAfter, err := kedautil.GetCertificatesNotAfter(ca)
+		if err != nil {
+			log.V(1).Info("unable to read the expiry of the custom CA", "namespace", config.ScalableObjectNamespace, "name", config.ScalableObjectName, "scalerIndex", config.ScalerIndex, "error", err.Error())
+			return time.Time{}, false
+		}
+		return notAfter, true
+	}
+	return time.Time{}, false
 }
 
 // buildScaler builds a scaler form input config and trigger type
@@ -122,6 +227,8 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewAzureAppInsightsScaler(config)
 	case "azure-blob":
 		return scalers.NewAzureBlobScaler(config)
+	case "azure-cosmosdb":
+		return scalers.NewAzureCosmosDBScaler(config)
 	case "azure-data-explorer":
 		return scalers.NewAzureDataExplorerScaler(config)
 	case "azure-eventhub":
@@ -138,6 +245,8 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewAzureServiceBusScaler(ctx, config)
 	case "cassandra":
 		return scalers.NewCassandraScaler(config)
+	case "clickhouse":
+		return scalers.NewClickHouseScaler(config)
 	case "couchdb":
 		return scalers.NewCouchDBScaler(ctx, config)
 	case "cpu":
@@ -157,6 +266,8 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewExternalMockScaler(config)
 	case "external-push":
 		return scalers.NewExternalPushScaler(config)
+	case "gcp-bigquery":
+		return scalers.NewBigQueryScaler(ctx, config)
 	case "gcp-pubsub":
 		return scalers.NewPubSubScaler(config)
 	case "gcp-stackdriver":
@@ -167,6 +278,8 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewGitHubRunnerScaler(config)
 	case "graphite":
 		return scalers.NewGraphiteScaler(config)
+	case "hazelcast":
+		return scalers.NewHazelcastScaler(config)
 	case "huawei-cloudeye":
 		return scalers.NewHuaweiCloudeyeScaler(config)
 	case "ibmmq":
@@ -175,6 +288,8 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewInfluxDBScaler(config)
 	case "kafka":
 		return scalers.NewKafkaScaler(config)
+	case "kubernetes-resource":
+		return scalers.NewKubernetesResourceScaler(cache.GetResourceInformers(), config)
 	case "kubernetes-workload":
 		return scalers.NewKubernetesWorkloadScaler(client, config)
 	case "liiklus":
@@ -205,8 +320,12 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewPredictKubeScaler(ctx, config)
 	case "prometheus":
 		return scalers.NewPrometheusScaler(config)
+	case "proxy-stats":
+		return scalers.NewProxyStatsScaler(config)
 	case "pulsar":
 		return scalers.NewPulsarScaler(config)
+	case "pvc-usage":
+		return scalers.NewPVCUsageScaler(client, config)
 	case "rabbitmq":
 		return scalers.NewRabbitMQScaler(config)
 	case "redis":
@@ -223,12 +342,16 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 		return scalers.NewRedisStreamsScaler(ctx, false, false, config)
 	case "selenium-grid":
 		return scalers.NewSeleniumGridScaler(config)
+	case "sftp":
+		return scalers.NewSFTPScaler(config)
 	case "solace-event-queue":
 		return scalers.NewSolaceScaler(config)
 	case "solr":
 		return scalers.NewSolrScaler(config)
 	case "stan":
 		return scalers.NewStanScaler(config)
+	case "vault":
+		return scalers.NewVaultScaler(config)
 	default:
 		return nil, fmt.Errorf("no scaler found for type: %s", triggerType)
 	}
@@ -0,0 +1,337 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// ScalingDefaultsConfigMapName is the name of the ConfigMaps with the scaling defaults and caps, the one in the KEDA
+// namespace applies to the whole cluster and the one of another namespace to the ScaledObjects and ScaledJobs in it
+const ScalingDefaultsConfigMapName = "keda-scaling-defaults"
+
+// scalingDefaultsConfig is where the scaling defaults are read from, the namespace of the cluster scaling defaults
+// is empty when they are disabled
+type scalingDefaultsConfig struct {
+	namespace string
+	reader    client.Reader
+}
+
+var scalingDefaults atomic.Value
+
+// scalingDefaultsSnapshot holds the scaling defaults of the namespaces with a ConfigMap, it's resolved again on every
+// change of one of the ConfigMaps so the scale loops neither read nor parse them on every poll
+type scalingDefaultsSnapshot struct {
+	defaults map[string]ScalingDefaults
+	// errs holds the errors of the ConfigMaps which can't be parsed by namespace
+	errs map[string]error
+}
+
+var (
+	// scalingDefaultsSnapshotLock serializes the changes of the snapshot, it's read without lock
+	scalingDefaultsSnapshotLock sync.Mutex
+	currentScalingDefaults      atomic.Pointer[scalingDefaultsSnapshot]
+)
+
+// EnableScalingDefaults applies the scaling defaults and caps of the ConfigMaps named ScalingDefaultsConfigMapName,
+// the cluster ones are read from the given namespace. The ConfigMaps are read with reader, the cache of the manager
+// which only holds these ConfigMaps, and the handler of ScalingDefaultsEventHandler keeps the snapshot of the scale
+// loops up to date
+func EnableScalingDefaults(clusterNamespace string, reader client.Reader) {
+	scalingDefaults.Store(scalingDefaultsConfig{namespace: clusterNamespace, reader: reader})
+	currentScalingDefaults.Store(nil)
+}
+
+// ScalingDefaultsEventHandler returns the handler to add to the ConfigMap informer, it resolves the snapshot of the
+// scaling defaults read by the scale loops
+func ScalingDefaultsEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			updateScalingDefaultsSnapshot(obj, false)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			updateScalingDefaultsSnapshot(newObj, false)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			updateScalingDefaultsSnapshot(obj, true)
+		},
+	}
+}
+
+// updateScalingDefaultsSnapshot replaces the snapshot with one holding the scaling defaults of the ConfigMap, or
+// without them when it's deleted
+func updateScalingDefaultsSnapshot(obj interface{}, deleted bool) {
+	configMap, ok := obj.(*corev1.ConfigMap)
+	if !ok || configMap.Name != ScalingDefaultsConfigMapName {
+		return
+	}
+
+	scalingDefaultsSnapshotLock.Lock()
+	defer scalingDefaultsSnapshotLock.Unlock()
+
+	snapshot := &scalingDefaultsSnapshot{defaults: map[string]ScalingDefaults{}, errs: map[string]error{}}
+	if previous := currentScalingDefaults.Load(); previous != nil {
+		for namespace, defaults := range previous.defaults {
+			snapshot.defaults[namespace] = defaults
+		}
+		for namespace, err := range previous.errs {
+			snapshot.errs[namespace] = err
+		}
+	}
+	delete(snapshot.defaults, configMap.Namespace)
+	delete(snapshot.errs, configMap.Namespace)
+	if !deleted {
+		defaults, err := ParseScalingDefaults(configMap.Data)
+		if err != nil {
+			snapshot.errs[configMap.Namespace] = fmt.Errorf("invalid scaling defaults in ConfigMap %s/%s: %w", configMap.Namespace, ScalingDefaultsConfigMapName, err)
+		} else {
+			snapshot.defaults[configMap.Namespace] = defaults
+		}
+	}
+	currentScalingDefaults.Store(snapshot)
+}
+
+// getScalingDefaults returns the scaling defaults of a namespace in the snapshot, they are empty when it
+// has no ConfigMap
+func (s *scalingDefaultsSnapshot) getScalingDefaults(namespace string) (ScalingDefaults, error) {
+	if s == nil {
+		return ScalingDefaults{}, nil
+	}
+	if err, ok := s.errs[namespace]; ok {
+		return ScalingDefaults{}, err
+	}
+	return s.defaults[namespace], nil
+}
+
+// GetScalingDefaultsNamespace returns the namespace of the cluster scaling defaults, or an empty string when the
+// scaling defaults are disabled
+func GetScalingDefaultsNamespace() string {
+	config, _ := scalingDefaults.Load().(scalingDefaultsConfig)
+	return config.namespace
+}
+
+// ScalingDefaults are the values applied to the unset fields of the ScaledObjects and ScaledJobs, and the caps
+// enforced on all of them
+type ScalingDefaults struct {
+	PollingInterval *int32
+	CooldownPeriod  *int32
+	MaxReplicaCount *int32
+
+	// MaxReplicaCountCap is the ceiling of maxReplicaCount
+	MaxReplicaCountCap *int32
+	// MinPollingInterval is the floor of pollingInterval
+	MinPollingInterval *int32
+}
+
+// ParseScalingDefaults reads the scaling defaults from the data of a ConfigMap, the missing keys are left unset
+func ParseScalingDefaults(data map[string]string) (ScalingDefaults, error) {
+	defaults := ScalingDefaults{}
+	fields := []struct {
+		key   string
+		value **int32
+		min   int32
+	}{
+		{"pollingInterval", &defaults.PollingInterval, 1},
+		{"cooldownPeriod", &defaults.CooldownPeriod, 0},
+		{"maxReplicaCount", &defaults.MaxReplicaCount, 1},
+		{"maxReplicaCountCap", &defaults.MaxReplicaCountCap, 1},
+		{"minPollingInterval", &defaults.MinPollingInterval, 1},
+	}
+	for _, field := range fields {
+		raw, ok := data[field.key]
+		if !ok || raw == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || int32(value) < field.min {
+			return ScalingDefaults{}, fmt.Errorf("%s must be a number greater than or equal to %d, got %s", field.key, field.min, raw)
+		}
+		v := int32(value)
+		*field.value = &v
+	}
+	return defaults, nil
+}
+
+// getScalingDefaults reads the scaling defaults of a namespace, they are empty when it has no ConfigMap
+func getScalingDefaults(ctx context.Context, c client.Reader, namespace string) (ScalingDefaults, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ScalingDefaultsConfigMapName}, configMap)
+	if errors.IsNotFound(err) {
+		return ScalingDefaults{}, nil
+	}
+	if err != nil {
+		return ScalingDefaults{}, fmt.Errorf("error reading the scaling defaults of namespace %s: %w", namespace, err)
+	}
+	defaults, err := ParseScalingDefaults(configMap.Data)
+	if err != nil {
+		return ScalingDefaults{}, fmt.Errorf("invalid scaling defaults in ConfigMap %s/%s: %w", namespace, ScalingDefaultsConfigMapName, err)
+	}
+	return defaults, nil
+}
+
+// ApplyScalingDefaults sets the unset pollingInterval, cooldownPeriod and maxReplicaCount of the ScaledObject or
+// ScaledJob from the defaults of its namespace, or else from the cluster defaults, and clamps them to the strictest
+// caps of both. The spec is only changed in memory, the returned messages explain the values of the spec which were
+// clamped. It does nothing when the scaling defaults are disabled. The ConfigMaps are read from the cache, so the
+// reconciliations triggered by their changes see them
+func ApplyScalingDefaults(ctx context.Context, scalableObject interface{}) ([]string, error) {
+	config, _ := scalingDefaults.Load().(scalingDefaultsConfig)
+	clusterNamespace, c := config.namespace, config.reader
+	if clusterNamespace == "" {
+		return nil, nil
+	}
+	namespace, err := getScalableObjectNamespace(scalableObject)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceDefaults, err := getScalingDefaults(ctx, c, namespace)
+	if err != nil {
+		return nil, err
+	}
+	clusterDefaults, err := getScalingDefaults(ctx, c, clusterNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return applyScalableObjectScalingDefaults(scalableObject, namespaceDefaults, clusterDefaults), nil
+}
+
+// applySnapshotScalingDefaults applies the scaling defaults like ApplyScalingDefaults, from the snapshot resolved by
+// the watch of the ConfigMaps instead of reading them. The scale loops apply them on every poll
+func applySnapshotScalingDefaults(scalableObject interface{}) error {
+	config, _ := scalingDefaults.Load().(scalingDefaultsConfig)
+	if config.namespace == "" {
+		return nil
+	}
+	namespace, err := getScalableObjectNamespace(scalableObject)
+	if err != nil {
+		return err
+	}
+
+	snapshot := currentScalingDefaults.Load()
+	namespaceDefaults, err := snapshot.getScalingDefaults(namespace)
+	if err != nil {
+		return err
+	}
+	clusterDefaults, err := snapshot.getScalingDefaults(config.namespace)
+	if err != nil {
+		return err
+	}
+	applyScalableObjectScalingDefaults(scalableObject, namespaceDefaults, clusterDefaults)
+	return nil
+}
+
+func getScalableObjectNamespace(scalableObject interface{}) (string, error) {
+	switch obj := scalableObject.(type) {
+	case *kedav1alpha1.ScaledObject:
+		return obj.Namespace, nil
+	case *kedav1alpha1.ScaledJob:
+		return obj.Namespace, nil
+	default:
+		return "", fmt.Errorf("unknown scalable object type %T", scalableObject)
+	}
+}
+
+func applyScalableObjectScalingDefaults(scalableObject interface{}, namespaceDefaults, clusterDefaults ScalingDefaults) []string {
+	switch obj := scalableObject.(type) {
+	case *kedav1alpha1.ScaledObject:
+		return applyScalingDefaults(&obj.Spec.PollingInterval, &obj.Spec.CooldownPeriod, &obj.Spec.MaxReplicaCount, namespaceDefaults, clusterDefaults)
+	case *kedav1alpha1.ScaledJob:
+		return applyScalingDefaults(&obj.Spec.PollingInterval, nil, &obj.Spec.MaxReplicaCount, namespaceDefaults, clusterDefaults)
+	}
+	return nil
+}
+
+// applyScalingDefaults applies the defaults and the caps to the fields, cooldownPeriod is nil for the ScaledJobs.
+// The fields are given new pointers, the values they pointed to are left unchanged
+func applyScalingDefaults(pollingInterval, cooldownPeriod, maxReplicaCount **int32, namespaceDefaults, clusterDefaults ScalingDefaults) []string {
+	setScalingDefault(pollingInterval, namespaceDefaults.PollingInterval, clusterDefaults.PollingInterval)
+	if cooldownPeriod != nil {
+		setScalingDefault(cooldownPeriod, namespaceDefaults.CooldownPeriod, clusterDefaults.CooldownPeriod)
+	}
+	setScalingDefault(maxReplicaCount, namespaceDefaults.MaxReplicaCount, clusterDefaults.MaxReplicaCount)
+
+	var clamps []string
+	if ceiling := strictestCap(namespaceDefaults.MaxReplicaCountCap, clusterDefaults.MaxReplicaCountCap, func(a, b int32) bool { return a < b }); ceiling != nil {
+		switch {
+		case *maxReplicaCount == nil:
+			if kedav1alpha1.DefaultMaxReplicaCount > *ceiling {
+				*maxReplicaCount = int32Ptr(*ceiling)
+			}
+		case **maxReplicaCount > *ceiling:
+			clamps = append(clamps, fmt.Sprintf("maxReplicaCount %d is above the cap of %d", **maxReplicaCount, *ceiling))
+			*maxReplicaCount = int32Ptr(*ceiling)
+		}
+	}
+	if floor := strictestCap(namespaceDefaults.MinPollingInterval, clusterDefaults.MinPollingInterval, func(a, b int32) bool { return a > b }); floor != nil {
+		switch {
+		case *pollingInterval == nil:
+			if kedav1alpha1.DefaultPollingInterval < *floor {
+				*pollingInterval = int32Ptr(*floor)
+			}
+		case **pollingInterval < *floor:
+			clamps = append(clamps, fmt.Sprintf("pollingInterval %d is below the minimum of %d", **pollingInterval, *floor))
+			*pollingInterval = int32Ptr(*floor)
+		}
+	}
+	return clamps
+}
+
+// setScalingDefault sets the unset field to the first default which is set
+func setScalingDefault(field **int32, defaults ...*int32) {
+	if *field != nil {
+		return
+	}
+	for _, value := range defaults {
+		if value != nil {
+			*field = int32Ptr(*value)
+			return
+		}
+	}
+}
+
+// strictestCap returns the cap which is set, or the stricter of both when both are set
+func strictestCap(a, b *int32, stricter func(a, b int32) bool) *int32 {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case stricter(*b, *a):
+		return b
+	default:
+		return a
+	}
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
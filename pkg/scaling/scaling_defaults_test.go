@@ -0,0 +1,220 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestParseScalingDefaults(t *testing.T) {
+	defaults, err := ParseScalingDefaults(map[string]string{
+		"pollingInterval":    "60",
+		"cooldownPeriod":     "0",
+		"maxReplicaCountCap": "20",
+		"minPollingInterval": "",
+		"unknown":            "ignored",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32Ptr(60), defaults.PollingInterval)
+	assert.Equal(t, int32Ptr(0), defaults.CooldownPeriod)
+	assert.Nil(t, defaults.MaxReplicaCount)
+	assert.Equal(t, int32Ptr(20), defaults.MaxReplicaCountCap)
+	assert.Nil(t, defaults.MinPollingInterval)
+
+	for _, data := range []map[string]string{
+		{"pollingInterval": "0"},
+		{"cooldownPeriod": "-1"},
+		{"maxReplicaCount": "ten"},
+		{"maxReplicaCountCap": "0"},
+		{"minPollingInterval": "4294967296"},
+	} {
+		_, err := ParseScalingDefaults(data)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestApplyScalingDefaultsDefaulting(t *testing.T) {
+	var pollingInterval, cooldownPeriod, maxReplicaCount *int32
+	clamps := applyScalingDefaults(&pollingInterval, &cooldownPeriod, &maxReplicaCount,
+		ScalingDefaults{PollingInterval: int32Ptr(15)},
+		ScalingDefaults{CooldownPeriod: int32Ptr(60), MaxReplicaCount: int32Ptr(10)})
+	assert.Empty(t, clamps)
+	assert.Equal(t, int32Ptr(15), pollingInterval)
+	assert.Equal(t, int32Ptr(60), cooldownPeriod)
+	assert.Equal(t, int32Ptr(10), maxReplicaCount)
+
+	// without defaults the fields stay unset
+	pollingInterval, cooldownPeriod, maxReplicaCount = nil, nil, nil
+	clamps = applyScalingDefaults(&pollingInterval, &cooldownPeriod, &maxReplicaCount, ScalingDefaults{}, ScalingDefaults{})
+	assert.Empty(t, clamps)
+	assert.Nil(t, pollingInterval)
+	assert.Nil(t, cooldownPeriod)
+	assert.Nil(t, maxReplicaCount)
+}
+
+func TestApplyScalingDefaultsPrecedence(t *testing.T) {
+	namespaceDefaults := ScalingDefaults{PollingInterval: int32Ptr(15), MaxReplicaCount: int32Ptr(10)}
+	clusterDefaults := ScalingDefaults{PollingInterval: int32Ptr(45), CooldownPeriod: int32Ptr(60), MaxReplicaCount: int32Ptr(50)}
+
+	// the spec wins over the namespace defaults, which win over the cluster defaults
+	pollingInterval, cooldownPeriod := int32Ptr(5), (*int32)(nil)
+	var maxReplicaCount *int32
+	clamps := applyScalingDefaults(&pollingInterval, &cooldownPeriod, &maxReplicaCount, namespaceDefaults, clusterDefaults)
+	assert.Empty(t, clamps)
+	assert.Equal(t, int32Ptr(5), pollingInterval)
+	assert.Equal(t, int32Ptr(60), cooldownPeriod)
+	assert.Equal(t, int32Ptr(10), maxReplicaCount)
+
+	// the strictest cap wins, whichever sets it
+	maxReplicaCount, pollingInterval = int32Ptr(40), int32Ptr(5)
+	clamps = applyScalingDefaults(&pollingInterval, nil, &maxReplicaCount,
+		ScalingDefaults{MaxReplicaCountCap: int32Ptr(30), MinPollingInterval: int32Ptr(10)},
+		ScalingDefaults{MaxReplicaCountCap: int32Ptr(20), MinPollingInterval: int32Ptr(8)})
+	assert.Equal(t, []string{"maxReplicaCount 40 is above the cap of 20", "pollingInterval 5 is below the minimum of 10"}, clamps)
+	assert.Equal(t, int32Ptr(20), maxReplicaCount)
+	assert.Equal(t, int32Ptr(10), pollingInterval)
+}
+
+func TestApplyScalingDefaultsClamping(t *testing.T) {
+	caps := ScalingDefaults{MaxReplicaCountCap: int32Ptr(20), MinPollingInterval: int32Ptr(60)}
+
+	// the caps win over the defaults as well
+	spec := int32(100)
+	pollingInterval, maxReplicaCount := (*int32)(nil), &spec
+	clamps := applyScalingDefaults(&pollingInterval, nil, &maxReplicaCount, ScalingDefaults{PollingInterval: int32Ptr(10)}, caps)
+	assert.Equal(t, []string{"maxReplicaCount 100 is above the cap of 20", "pollingInterval 10 is below the minimum of 60"}, clamps)
+	assert.Equal(t, int32Ptr(20), maxReplicaCount)
+	assert.Equal(t, int32Ptr(60), pollingInterval)
+	assert.Equal(t, int32(100), spec, "the value of the spec must not be changed")
+
+	// the unset fields are clamped from the built-in defaults without a message
+	pollingInterval, maxReplicaCount = nil, nil
+	clamps = applyScalingDefaults(&pollingInterval, nil, &maxReplicaCount, ScalingDefaults{}, caps)
+	assert.Empty(t, clamps)
+	assert.Equal(t, int32Ptr(20), maxReplicaCount)
+	assert.Equal(t, int32Ptr(60), pollingInterval)
+
+	// the values within the caps are kept
+	pollingInterval, maxReplicaCount = int32Ptr(60), int32Ptr(20)
+	clamps = applyScalingDefaults(&pollingInterval, nil, &maxReplicaCount, ScalingDefaults{}, caps)
+	assert.Empty(t, clamps)
+	assert.Equal(t, int32Ptr(20), maxReplicaCount)
+	assert.Equal(t, int32Ptr(60), pollingInterval)
+}
+
+func TestApplyScalingDefaultsFromConfigMaps(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, kedav1alpha1.AddToScheme(scheme))
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "keda", Name: ScalingDefaultsConfigMapName},
+			Data:       map[string]string{"cooldownPeriod": "120", "maxReplicaCountCap": "10"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: ScalingDefaultsConfigMapName},
+			Data:       map[string]string{"pollingInterval": "60", "maxReplicaCount": "5"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "invalid", Name: ScalingDefaultsConfigMapName},
+			Data:       map[string]string{"pollingInterval": "often"},
+		},
+	).Build()
+
+	maxReplicaCount := int32(50)
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "so"},
+		Spec:       kedav1alpha1.ScaledObjectSpec{MaxReplicaCount: &maxReplicaCount},
+	}
+
+	// the scaling defaults are disabled by default
+	clamps, err := ApplyScalingDefaults(context.Background(), scaledObject)
+	require.NoError(t, err)
+	assert.Empty(t, clamps)
+	assert.Nil(t, scaledObject.Spec.PollingInterval)
+
+	EnableScalingDefaults("keda", kubeClient)
+	t.Cleanup(func() { EnableScalingDefaults("", nil) })
+
+	clamps, err = ApplyScalingDefaults(context.Background(), scaledObject)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"maxReplicaCount 50 is above the cap of 10"}, clamps)
+	assert.Equal(t, int32Ptr(60), scaledObject.Spec.PollingInterval)
+	assert.Equal(t, int32Ptr(120), scaledObject.Spec.CooldownPeriod)
+	assert.Equal(t, int32Ptr(10), scaledObject.Spec.MaxReplicaCount)
+
+	// a namespace without ConfigMap gets the cluster defaults
+	scaledJob := &kedav1alpha1.ScaledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "sj"}}
+	clamps, err = ApplyScalingDefaults(context.Background(), scaledJob)
+	require.NoError(t, err)
+	assert.Empty(t, clamps)
+	assert.Nil(t, scaledJob.Spec.PollingInterval)
+	assert.Equal(t, int32Ptr(10), scaledJob.Spec.MaxReplicaCount)
+
+	scaledJob.Namespace = "invalid"
+	_, err = ApplyScalingDefaults(context.Background(), scaledJob)
+	assert.ErrorContains(t, err, "invalid scaling defaults in ConfigMap invalid/keda-scaling-defaults")
+}
+
+func TestApplySnapshotScalingDefaults(t *testing.T) {
+	newConfigMap := func(namespace string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: ScalingDefaultsConfigMapName}, Data: data}
+	}
+	// the ConfigMaps aren't read on the poll path, only the snapshot resolved by the watch
+	EnableScalingDefaults("keda", nil)
+	t.Cleanup(func() { EnableScalingDefaults("", nil) })
+	handler := ScalingDefaultsEventHandler()
+
+	scaledObject := &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "so"}}
+	require.NoError(t, applySnapshotScalingDefaults(scaledObject))
+	assert.Nil(t, scaledObject.Spec.PollingInterval)
+
+	handler.OnAdd(newConfigMap("keda", map[string]string{"cooldownPeriod": "120", "pollingInterval": "45"}), false)
+	tenant := newConfigMap("tenant", map[string]string{"pollingInterval": "60"})
+	handler.OnAdd(tenant, false)
+	// the other ConfigMaps of the informer are ignored
+	handler.OnAdd(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "other"}, Data: map[string]string{"pollingInterval": "1"}}, false)
+
+	scaledObject = &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "so"}}
+	require.NoError(t, applySnapshotScalingDefaults(scaledObject))
+	assert.Equal(t, int32Ptr(60), scaledObject.Spec.PollingInterval)
+	assert.Equal(t, int32Ptr(120), scaledObject.Spec.CooldownPeriod)
+
+	// an invalid ConfigMap fails until it's fixed
+	invalid := newConfigMap("tenant", map[string]string{"pollingInterval": "often"})
+	handler.OnUpdate(tenant, invalid)
+	scaledObject = &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "so"}}
+	assert.ErrorContains(t, applySnapshotScalingDefaults(scaledObject), "invalid scaling defaults in ConfigMap tenant/keda-scaling-defaults")
+
+	// the cluster defaults apply once the namespace ConfigMap is deleted
+	handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "tenant/keda-scaling-defaults", Obj: invalid})
+	scaledJob := &kedav1alpha1.ScaledJob{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "sj"}}
+	require.NoError(t, applySnapshotScalingDefaults(scaledJob))
+	assert.Equal(t, int32Ptr(45), scaledJob.Spec.PollingInterval)
+}
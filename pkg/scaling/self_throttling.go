@@ -0,0 +1,261 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+// DefaultCgroupRoot is where the cgroup filesystem of the operator container is mounted
+const DefaultCgroupRoot = "/sys/fs/cgroup"
+
+// cgroupV1NoLimit is the limit above which the cgroup v1 memory limit is considered unset, the kernel reports
+// the largest page aligned int64 when there is no limit
+const cgroupV1NoLimit = int64(1) << 62
+
+// cgroupUsage is the resource usage and limits of the cgroup of the operator, a limit of 0 means there is no limit
+type cgroupUsage struct {
+	memoryUsage int64
+	memoryLimit int64
+	// cpuUsage is the total CPU time used by the cgroup
+	cpuUsage time.Duration
+	// cpuLimit is the number of CPUs the cgroup can use
+	cpuLimit float64
+}
+
+// ResourcePressureDetector periodically compares the memory and CPU usage of the operator cgroup to its limits,
+// and reports the operator under resource pressure in keda_operator_self_throttling while the usage of either is
+// above the threshold. The scale loops aren't slowed down, the gauge only helps to correlate the scaling lag
+type ResourcePressureDetector struct {
+	cgroupRoot string
+	threshold  float64
+	interval   time.Duration
+	logger     logr.Logger
+	now        func() time.Time
+
+	lastCPUUsage time.Duration
+	lastCheck    time.Time
+	pressure     bool
+}
+
+// NewResourcePressureDetector creates a detector checking the cgroup mounted on cgroupRoot every interval, the
+// threshold is the ratio of the limits above which the operator is under resource pressure
+func NewResourcePressureDetector(cgroupRoot string, threshold float64, interval time.Duration, logger logr.Logger) (*ResourcePressureDetector, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("the self throttling threshold must be greater than 0 and at most 1, %g given", threshold)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("the interval of the resource pressure checks must be positive, %s given", interval)
+	}
+	return &ResourcePressureDetector{
+		cgroupRoot: cgroupRoot,
+		threshold:  threshold,
+		interval:   interval,
+		logger:     logger,
+		now:        time.Now,
+	}, nil
+}
+
+// Start checks the resource pressure every interval until the context is done, this implements the Runnable
+// interface of controller-runtime Manager, so we can use mgr.Add() to start this component.
+func (d *ResourcePressureDetector) Start(ctx context.Context) error {
+	d.logger.Info("Starting resource pressure detection", "threshold", d.threshold, "interval", d.interval)
+	prommetrics.RecordOperatorSelfThrottling(false)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		if err := d.Check(); err != nil {
+			d.logger.Error(err, "error checking the resource pressure of the operator")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection returns false as every replica reports its own resource pressure
+func (d *ResourcePressureDetector) NeedLeaderElection() bool {
+	return false
+}
+
+// Check reads the cgroup usage and records whether the operator is under resource pressure. The CPU usage is averaged since the
+// previous check, so it's only considered from the second check on
+func (d *ResourcePressureDetector) Check() error {
+	usage, err := readCgroupUsage(d.cgroupRoot)
+	if err != nil {
+		return err
+	}
+	now := d.now()
+
+	pressure := false
+	if usage.memoryLimit > 0 && float64(usage.memoryUsage) >= d.threshold*float64(usage.memoryLimit) {
+		pressure = true
+	}
+	if usage.cpuLimit > 0 && !d.lastCheck.IsZero() {
+		if elapsed := now.Sub(d.lastCheck); elapsed > 0 {
+			cpus := float64(usage.cpuUsage-d.lastCPUUsage) / float64(elapsed)
+			if cpus >= d.threshold*usage.cpuLimit {
+				pressure = true
+			}
+		}
+	}
+	d.lastCPUUsage = usage.cpuUsage
+	d.lastCheck = now
+
+	if d.pressure != pressure {
+		d.pressure = pressure
+		d.logger.Info("Operator resource pressure changed", "pressure", pressure, "memoryUsage", usage.memoryUsage, "memoryLimit", usage.memoryLimit, "cpuLimit", usage.cpuLimit)
+	}
+	prommetrics.RecordOperatorSelfThrottling(pressure)
+	return nil
+}
+
+// readCgroupUsage reads the usage and limits from the cgroup v2 unified hierarchy, or from the memory, cpu and
+// cpuacct controllers of cgroup v1
+func readCgroupUsage(root string) (cgroupUsage, error) {
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return readCgroupV2Usage(root)
+	}
+	return readCgroupV1Usage(root)
+}
+
+func readCgroupV2Usage(root string) (cgroupUsage, error) {
+	usage := cgroupUsage{}
+	var err error
+	if usage.memoryUsage, err = readCgroupInt(filepath.Join(root, "memory.current")); err != nil {
+		return usage, err
+	}
+	memoryMax, err := readCgroupFile(filepath.Join(root, "memory.max"))
+	if err != nil {
+		return usage, err
+	}
+	if memoryMax != "max" {
+		if usage.memoryLimit, err = strconv.ParseInt(memoryMax, 10, 64); err != nil {
+			return usage, fmt.Errorf("error parsing memory.max: %w", err)
+		}
+	}
+
+	// cpu.max is "<quota> <period>" in microseconds, the quota is "max" without limit
+	cpuMax, err := readCgroupFile(filepath.Join(root, "cpu.max"))
+	if err != nil {
+		return usage, err
+	}
+	if quota, period, _ := strings.Cut(cpuMax, " "); quota != "max" {
+		if usage.cpuLimit, err = parseCPULimit(quota, period); err != nil {
+			return usage, fmt.Errorf("error parsing cpu.max: %w", err)
+		}
+	}
+
+	cpuStat, err := readCgroupFile(filepath.Join(root, "cpu.stat"))
+	if err != nil {
+		return usage, err
+	}
+	for _, line := range strings.Split(cpuStat, "\n") {
+		if value, found := strings.CutPrefix(line, "usage_usec "); found {
+			usec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return usage, fmt.Errorf("error parsing usage_usec of cpu.stat: %w", err)
+			}
+			usage.cpuUsage = time.Duration(usec) * time.Microsecond
+			return usage, nil
+		}
+	}
+	return usage, errors.New("no usage_usec in cpu.stat")
+}
+
+func readCgroupV1Usage(root string) (cgroupUsage, error) {
+	usage := cgroupUsage{}
+	var err error
+	if usage.memoryUsage, err = readCgroupInt(filepath.Join(root, "memory", "memory.usage_in_bytes")); err != nil {
+		return usage, err
+	}
+	if usage.memoryLimit, err = readCgroupInt(filepath.Join(root, "memory", "memory.limit_in_bytes")); err != nil {
+		return usage, err
+	}
+	if usage.memoryLimit >= cgroupV1NoLimit {
+		usage.memoryLimit = 0
+	}
+
+	quota, err := readCgroupFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return usage, err
+	}
+	if quota != "-1" {
+		period, err := readCgroupFile(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+		if err != nil {
+			return usage, err
+		}
+		if usage.cpuLimit, err = parseCPULimit(quota, period); err != nil {
+			return usage, fmt.Errorf("error parsing the cfs quota: %w", err)
+		}
+	}
+
+	nsec, err := readCgroupInt(filepath.Join(root, "cpuacct", "cpuacct.usage"))
+	if err != nil {
+		return usage, err
+	}
+	usage.cpuUsage = time.Duration(nsec)
+	return usage, nil
+}
+
+func parseCPULimit(quota, period string) (float64, error) {
+	quotaValue, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, err
+	}
+	periodValue, err := strconv.ParseFloat(period, 64)
+	if err != nil {
+		return 0, err
+	}
+	if quotaValue <= 0 || periodValue <= 0 {
+		return 0, fmt.Errorf("invalid quota %s and period %s", quota, period)
+	}
+	return quotaValue / periodValue, nil
+}
+
+func readCgroupFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func readCgroupInt(path string) (int64, error) {
+	content, err := readCgroupFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseInt(content, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func writeCgroupFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content+"\n"), 0600))
+	}
+}
+
+func newTestResourcePressureDetector(t *testing.T, root string, now *time.Time) *ResourcePressureDetector {
+	t.Helper()
+	detector, err := NewResourcePressureDetector(root, 0.9, time.Second, logr.Discard())
+	require.NoError(t, err)
+	detector.now = func() time.Time { return *now }
+	return detector
+}
+
+func TestResourcePressureDetectorCgroupV2(t *testing.T) {
+	root := t.TempDir()
+	now := time.Unix(1000, 0)
+	detector := newTestResourcePressureDetector(t, root, &now)
+
+	// 2 CPUs and 100MiB
+	writeCgroupFiles(t, root, map[string]string{
+		"cgroup.controllers": "cpu memory",
+		"memory.current":     "52428800",
+		"memory.max":         "104857600",
+		"cpu.max":            "200000 100000",
+		"cpu.stat":           "usage_usec 1000000\nuser_usec 800000\nsystem_usec 200000",
+	})
+	require.NoError(t, detector.Check())
+	assertSelfThrottling(t, false)
+
+	// the memory usage is above 90% of the limit
+	writeCgroupFiles(t, root, map[string]string{"memory.current": "99614720"})
+	now = now.Add(10 * time.Second)
+	require.NoError(t, detector.Check())
+	assertSelfThrottling(t, true)
+
+	// 19s of CPU time in 10s is 1.9 CPUs, above 90% of the 2 CPUs
+	writeCgroupFiles(t, root, map[string]string{"memory.current": "52428800", "cpu.stat": "usage_usec 20000000"})
+	now = now.Add(10 * time.Second)
+	require.NoError(t, detector.Check())
+	assertSelfThrottling(t, true)
+
+	// 5s of CPU time in 10s is 0.5 CPU
+	writeCgroupFiles(t, root, map[string]string{"cpu.stat": "usage_usec 25000000"})
+	now = now.Add(10 * time.Second)
+	require.NoError(t, detector.Check())
+	assertSelfThrottling(t, false)
+
+	// without limits there is no pressure
+	writeCgroupFiles(t, root, map[string]string{"memory.current": "104857600", "memory.max": "max", "cpu.max": "max 100000", "cpu.stat": "usage_usec 125000000"})
+	now = now.Add(10 * time.Second)
+	require.NoError(t, detector.Check())
+	assertSelfThrottling(t, false)
+}
+
+func TestResourcePressureDetectorCgroupV1(t *testing.T) {
+	root := t.TempDir()
+	now := time.Unix(1000, 0)
+	detector := newTestResourcePressureDetector(t, root, &now)
+
+	// 1 CPU and no memory limit
+	writeCgroupFiles(t, root, map[string]string{
+		"memory/memory.usage_in_bytes": "104857600",
+		"memory/memory.limit_in_bytes": "9223372036854771712",
+		"cpu/cpu.cfs_quota_us":         "100000",
+		"cpu/cpu.cfs_period_us":        "100000",
+		"cpuacct/cpuacct.usage":        "1000000000",
+	})
+	require.NoError(t, detector.Check())
+	assertSelfThrottling(t, false)
+
+	// 9.5s of CPU time in 10s
+	writeCgroupFiles(t, root, map[string]string{"cpuacct/cpuacct.usage": "10500000000"})
+	now = now.Add(10 * time.Second)
+	require.NoError(t, detector.Check())
+	assertSelfThrottling(t, true)
+
+	writeCgroupFiles(t, root, map[string]string{"cpuacct/cpuacct.usage": "11500000000"})
+	now = now.Add(10 * time.Second)
+	require.NoError(t, detector.Check())
+	assertSelfThrottling(t, false)
+}
+
+func TestResourcePressureDetectorErrors(t *testing.T) {
+	_, err := NewResourcePressureDetector(DefaultCgroupRoot, 0, time.Second, logr.Discard())
+	assert.Error(t, err)
+	_, err = NewResourcePressureDetector(DefaultCgroupRoot, 1.5, time.Second, logr.Discard())
+	assert.Error(t, err)
+	_, err = NewResourcePressureDetector(DefaultCgroupRoot, 0.9, 0, logr.Discard())
+	assert.Error(t, err)
+
+	root := t.TempDir()
+	now := time.Unix(1000, 0)
+	detector := newTestResourcePressureDetector(t, root, &now)
+	assert.Error(t, detector.Check())
+
+	writeCgroupFiles(t, root, map[string]string{
+		"cgroup.controllers": "cpu memory",
+		"memory.current":     "52428800",
+		"memory.max":         "104857600",
+		"cpu.max":            "max 100000",
+		"cpu.stat":           "user_usec 800000",
+	})
+	assert.Error(t, detector.Check())
+}
+
+func assertSelfThrottling(t *testing.T, expected bool) {
+	t.Helper()
+	expectedValue := 0.0
+	if expected {
+		expectedValue = 1
+	}
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "keda_operator_self_throttling" {
+			assert.Equal(t, expectedValue, family.GetMetric()[0].GetGauge().GetValue())
+			return
+		}
+	}
+	t.Error("keda_operator_self_throttling not found")
+}
@@ -18,12 +18,15 @@ package util
 
 import (
 	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path"
 	"strings"
+	"sync"
+	"time"
 
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -32,47 +35,149 @@ const customCAPath = "/custom/ca"
 
 var logger = logf.Log.WithName("certificates")
 
-var rootCAs *x509.CertPool
+var (
+	rootCAs           *x509.CertPool
+	rootCAsGeneration uint64
+	caCertDirs        []string
+	rootCAsLock       sync.RWMutex
+)
+
+// SetCACertDirs sets the directories whose PEM files are added to the root CAs, next to the ones of /custom/ca
+func SetCACertDirs(dirs []string) error {
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("error reading the CA cert dir %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("the CA cert dir %s isn't a directory", dir)
+		}
+	}
+
+	rootCAsLock.Lock()
+	defer rootCAsLock.Unlock()
+	caCertDirs = dirs
+	rootCAs = nil
+	return nil
+}
+
+// GetRootCAsGeneration returns how many times the root CAs have been reloaded, the clients created before a reload
+// don't trust the CAs it added
+func GetRootCAsGeneration() uint64 {
+	rootCAsLock.RLock()
+	defer rootCAsLock.RUnlock()
+	return rootCAsGeneration
+}
 
 func getRootCAs() *x509.CertPool {
+	rootCAsLock.RLock()
 	if rootCAs != nil {
+		defer rootCAsLock.RUnlock()
 		return rootCAs.Clone()
 	}
+	rootCAsLock.RUnlock()
 
-	rootCAs, _ = x509.SystemCertPool()
+	rootCAsLock.Lock()
+	defer rootCAsLock.Unlock()
 	if rootCAs == nil {
-		rootCAs = x509.NewCertPool()
+		// the certificates which can't be read are logged and left out
+		rootCAs, _ = loadRootCAs(caCertDirs)
 	}
+	return rootCAs.Clone()
+}
 
-	if _, err := os.Stat(customCAPath); errors.Is(err, fs.ErrNotExist) {
-		logger.V(1).Info(fmt.Sprintf("the path %s doesn't exist, skipping custom CA registrations", customCAPath))
-		return rootCAs.Clone()
+// reloadRootCAs reads the CA cert dirs again and bumps the generation of the root CAs. The previous root CAs are
+// kept if a certificate of the CA cert dirs can't be read
+func reloadRootCAs() error {
+	rootCAsLock.Lock()
+	defer rootCAsLock.Unlock()
+	pool, err := loadRootCAs(caCertDirs)
+	if err != nil {
+		return fmt.Errorf("error reloading the root CAs: %w", err)
+	}
+	rootCAs = pool
+	rootCAsGeneration++
+	return nil
+}
+
+// loadRootCAs returns the system pool with the certificates of /custom/ca and of the CA cert dirs, and the errors
+// of the certificates of the CA cert dirs which can't be read
+func loadRootCAs(dirs []string) (*x509.CertPool, error) {
+	pool, _ := x509.SystemCertPool()
+	if pool == nil {
+		pool = x509.NewCertPool()
 	}
 
-	files, err := os.ReadDir(customCAPath)
+	_ = appendCertsFromDir(pool, customCAPath)
+	var errs []error
+	for _, dir := range dirs {
+		if err := appendCertsFromDir(pool, dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return pool, errors.Join(errs...)
+}
+
+func appendCertsFromDir(pool *x509.CertPool, dir string) error {
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		logger.V(1).Info(fmt.Sprintf("the path %s doesn't exist, skipping custom CA registrations", dir))
+		return nil
+	}
+
+	files, err := os.ReadDir(dir)
 	if err != nil {
-		logger.Error(err, fmt.Sprintf("unable to read %s", customCAPath))
-		return rootCAs.Clone()
+		logger.Error(err, fmt.Sprintf("unable to read %s", dir))
+		return fmt.Errorf("unable to read %s: %w", dir, err)
 	}
 
+	var errs []error
+
 	for _, file := range files {
 		if file.IsDir() || strings.HasPrefix(file.Name(), "..") {
 			logger.V(1).Info(fmt.Sprintf("%s isn't a valid certificate", file.Name()))
 			continue
 		}
 
-		certs, err := os.ReadFile(path.Join(customCAPath, file.Name()))
+		certs, err := os.ReadFile(path.Join(dir, file.Name()))
 		if err != nil {
 			logger.Error(err, fmt.Sprintf("error reading %q", file.Name()))
+			errs = append(errs, fmt.Errorf("error reading %q: %w", file.Name(), err))
 			continue
 		}
 
-		if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
+		if ok := pool.AppendCertsFromPEM(certs); !ok {
 			logger.Error(fmt.Errorf("no certs appended"), fmt.Sprintf("the certificate %s hasn't been added to the pool", file.Name()))
+			errs = append(errs, fmt.Errorf("no certs appended from %q", file.Name()))
 			continue
 		}
 		logger.V(1).Info(fmt.Sprintf("the certificate %s has been added to the pool", file.Name()))
 	}
+	return errors.Join(errs...)
+}
 
-	return rootCAs.Clone()
+// GetCertificatesNotAfter returns the soonest expiry of the PEM encoded certificates of a CA bundle
+func GetCertificatesNotAfter(pemCerts string) (time.Time, error) {
+	var notAfter time.Time
+	rest := []byte(pemCerts)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error parsing certificate: %w", err)
+		}
+		if notAfter.IsZero() || cert.NotAfter.Before(notAfter) {
+			notAfter = cert.NotAfter
+		}
+	}
+	if notAfter.IsZero() {
+		return time.Time{}, fmt.Errorf("no certificate found")
+	}
+	return notAfter, nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package util
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -29,6 +30,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,27 +41,153 @@ var (
 )
 
 func TestCustomCAsAreRegistered(t *testing.T) {
+	resetRootCAs(t)
 	defer os.Remove(caCrtPath)
 	generateCA(t)
 
-	rootCAs := getRootCAs()
-	//nolint:staticcheck // func (s *CertPool) Subjects was deprecated if s was returned by SystemCertPool, Subjects
-	subjects := rootCAs.Subjects()
-	var rdnSequence pkix.RDNSequence
-	_, err := asn1.Unmarshal(subjects[len(subjects)-1], &rdnSequence)
-	if err != nil {
-		t.Fatal("could not unmarshal der formatted subject")
+	assert.Contains(t, rootCACommonNames(t), certCommonName, "certificate not found")
+}
+
+func TestGetCertificatesNotAfter(t *testing.T) {
+	soonest := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	bundle := string(newTestCA(t, soonest.AddDate(1, 0, 0))) + string(newTestCA(t, soonest))
+
+	notAfter, err := GetCertificatesNotAfter(bundle)
+	assert.NoError(t, err)
+	assert.Equal(t, soonest, notAfter.UTC())
+
+	_, err = GetCertificatesNotAfter("not a certificate")
+	assert.Error(t, err)
+	_, err = GetCertificatesNotAfter(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("invalid")})))
+	assert.Error(t, err)
+}
+
+func TestRootCAsFromCACertDirs(t *testing.T) {
+	resetRootCAs(t)
+	dirA, dirB := t.TempDir(), t.TempDir()
+	writeTestCA(t, dirA, "ca.crt", "dir-a-ca")
+	writeTestCA(t, dirB, "ca.crt", "dir-b-ca")
+	// the hidden files and the subdirectories of a mounted secret are skipped
+	require.NoError(t, os.Mkdir(path.Join(dirB, "..data"), os.ModePerm))
+	writeTestCA(t, path.Join(dirB, "..data"), "ca.crt", "hidden-ca")
+
+	require.NoError(t, SetCACertDirs([]string{dirA, dirB}))
+
+	commonNames := rootCACommonNames(t)
+	assert.Contains(t, commonNames, "dir-a-ca")
+	assert.Contains(t, commonNames, "dir-b-ca")
+	assert.NotContains(t, commonNames, "hidden-ca")
+}
+
+func TestSetCACertDirsValidatesDirs(t *testing.T) {
+	resetRootCAs(t)
+	dir := t.TempDir()
+	file := path.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(file, []byte{}, 0600))
+
+	assert.Error(t, SetCACertDirs([]string{path.Join(dir, "missing")}))
+	assert.Error(t, SetCACertDirs([]string{file}))
+	assert.NoError(t, SetCACertDirs([]string{dir}))
+}
+
+func TestCACertDirsWatcherReloadsRootCAs(t *testing.T) {
+	resetRootCAs(t)
+	defaultDelay := caCertDirsReloadDelay
+	caCertDirsReloadDelay = 10 * time.Millisecond
+	defer func() { caCertDirsReloadDelay = defaultDelay }()
+
+	dir := t.TempDir()
+	writeTestCA(t, dir, "ca.crt", "initial-ca")
+	require.NoError(t, SetCACertDirs([]string{dir}))
+	assert.Contains(t, rootCACommonNames(t), "initial-ca")
+	generation := GetRootCAsGeneration()
+
+	reloads := make(chan error, 100)
+	watcher, err := NewCACertDirsWatcher(logr.Discard(), func(err error) { reloads <- err })
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Start(ctx) }()
+
+	writeTestCA(t, dir, "ca.crt", "rotated-ca")
+	// the file may be read while it's written, so the reload is awaited until the rotated CA is found
+	require.Eventually(t, func() bool {
+		for _, commonName := range rootCACommonNames(t) {
+			if commonName == "rotated-ca" {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond, "the root CAs haven't been reloaded")
+	assert.Greater(t, GetRootCAsGeneration(), generation)
+	assert.NotContains(t, rootCACommonNames(t), "initial-ca")
+	assert.NotEmpty(t, reloads)
+
+	// a reload with a certificate which can't be read fails and keeps the previous root CAs
+	require.NoError(t, os.WriteFile(path.Join(dir, "invalid.crt"), []byte("not a certificate"), 0600))
+	require.Eventually(t, func() bool {
+		select {
+		case err := <-reloads:
+			return err != nil
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond, "the reload hasn't failed")
+	generation = GetRootCAsGeneration()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, generation, GetRootCAsGeneration())
+	assert.Contains(t, rootCACommonNames(t), "rotated-ca")
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+// resetRootCAs drops the CA cert dirs and the loaded root CAs before and after the test, the root CAs are loaded
+// again from the certificates of the test and another test can't find them
+func resetRootCAs(t *testing.T) {
+	reset := func() {
+		rootCAsLock.Lock()
+		defer rootCAsLock.Unlock()
+		caCertDirs = nil
+		rootCAs = nil
 	}
-	var name pkix.Name
-	name.FillFromRDNSequence(&rdnSequence)
+	reset()
+	t.Cleanup(reset)
+}
 
-	assert.Equal(t, certCommonName, name.CommonName, "certificate not found")
+func writeTestCA(t *testing.T, dir, name, commonName string) {
+	err := os.WriteFile(path.Join(dir, name), newTestCAWithCommonName(t, commonName, time.Now().AddDate(1, 0, 0)), 0600)
+	require.NoErrorf(t, err, "error writing CA file - %s", err)
+}
+
+func rootCACommonNames(t *testing.T) []string {
+	var commonNames []string
+	//nolint:staticcheck // func (s *CertPool) Subjects was deprecated if s was returned by SystemCertPool, Subjects
+	for _, subject := range getRootCAs().Subjects() {
+		var rdnSequence pkix.RDNSequence
+		_, err := asn1.Unmarshal(subject, &rdnSequence)
+		require.NoError(t, err, "could not unmarshal der formatted subject")
+		var name pkix.Name
+		name.FillFromRDNSequence(&rdnSequence)
+		commonNames = append(commonNames, name.CommonName)
+	}
+	return commonNames
 }
 
 func generateCA(t *testing.T) {
 	err := os.MkdirAll(customCAPath, os.ModePerm)
 	require.NoErrorf(t, err, "error generating the custom ca folder - %s", err)
 
+	err = os.WriteFile(caCrtPath, newTestCA(t, time.Now().AddDate(10, 0, 0)), 0600)
+	require.NoErrorf(t, err, "error writing custom CA file - %s", err)
+}
+
+// newTestCA returns a PEM encoded self signed CA expiring at notAfter
+func newTestCA(t *testing.T, notAfter time.Time) []byte {
+	return newTestCAWithCommonName(t, certCommonName, notAfter)
+}
+
+func newTestCAWithCommonName(t *testing.T, commonName string, notAfter time.Time) []byte {
 	ca := &x509.Certificate{
 		SerialNumber: big.NewInt(2019),
 		Subject: pkix.Name{
@@ -69,10 +197,10 @@ func generateCA(t *testing.T) {
 			Locality:      []string{"San Francisco"},
 			StreetAddress: []string{"Golden Gate Bridge"},
 			PostalCode:    []string{"94016"},
-			CommonName:    certCommonName,
+			CommonName:    commonName,
 		},
 		NotBefore:             time.Now(),
-		NotAfter:              time.Now().AddDate(10, 0, 0),
+		NotAfter:              notAfter,
 		IsCA:                  true,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
@@ -88,11 +216,8 @@ func generateCA(t *testing.T) {
 	require.NoErrorf(t, err, "error generating custom CA - %s", err)
 
 	// pem encode
-	crtFile, err := os.OpenFile(caCrtPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	require.NoErrorf(t, err, "error opening custom CA file - %s", err)
-	err = pem.Encode(crtFile, &pem.Block{
+	return pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
 		Bytes: caBytes,
 	})
-	require.NoErrorf(t, err, "error opening custom CA file - %s", err)
 }
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+)
+
+// caCertDirsReloadDelay is how long the watcher waits for the other changes of a rotation before reloading the
+// root CAs, the kubelet updates a mounted secret by swapping its ..data symlink, which fires several events
+var caCertDirsReloadDelay = time.Second
+
+// CACertDirsWatcher reloads the root CAs when the files of the CA cert dirs change, the scalers built afterwards
+// trust the rotated CAs
+type CACertDirsWatcher struct {
+	dirs     []string
+	watcher  *fsnotify.Watcher
+	logger   logr.Logger
+	onReload func(error)
+}
+
+// NewCACertDirsWatcher creates a watcher of the CA cert dirs set with SetCACertDirs, onReload is called with the
+// result of each reload
+func NewCACertDirsWatcher(logger logr.Logger, onReload func(error)) (*CACertDirsWatcher, error) {
+	rootCAsLock.RLock()
+	dirs := caCertDirs
+	rootCAsLock.RUnlock()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating the CA cert dirs watcher: %w", err)
+	}
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("error watching the CA cert dir %s: %w", dir, err)
+		}
+	}
+	return &CACertDirsWatcher{
+		dirs:     dirs,
+		watcher:  watcher,
+		logger:   logger,
+		onReload: onReload,
+	}, nil
+}
+
+// Start reloads the root CAs on the changes of the CA cert dirs until the context is done, this implements the
+// Runnable interface of controller-runtime Manager, so we can use mgr.Add() to start this component.
+func (w *CACertDirsWatcher) Start(ctx context.Context) error {
+	w.logger.Info("Starting CA cert dirs watcher", "dirs", w.dirs)
+	defer w.watcher.Close()
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			w.logger.V(1).Info("CA cert dir changed", "event", event.String())
+			reload = time.After(caCertDirsReloadDelay)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.Error(err, "error watching the CA cert dirs")
+		case <-reload:
+			reload = nil
+			err := reloadRootCAs()
+			if err != nil {
+				w.logger.Error(err, "error reloading the root CAs, the previous ones are kept", "dirs", w.dirs)
+			} else {
+				w.logger.Info("Reloaded the root CAs", "dirs", w.dirs, "generation", GetRootCAsGeneration())
+			}
+			if w.onReload != nil {
+				w.onReload(err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection returns false as every replica builds its own scalers
+func (w *CACertDirsWatcher) NeedLeaderElection() bool {
+	return false
+}
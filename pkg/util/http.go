@@ -17,8 +17,14 @@ limitations under the License.
 package util
 
 import (
+	"context"
 	"crypto/tls"
+	"io"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -46,18 +52,158 @@ type HTTPDoer interface {
 // timeoutMS milliseconds, or 300 milliseconds if timeoutMS <= 0.
 // unsafeSsl parameter allows to avoid tls cert validation if it's required
 func CreateHTTPClient(timeout time.Duration, unsafeSsl bool) *http.Client {
+	return CreateHTTPClientWithProxy(timeout, unsafeSsl, nil)
+}
+
+// CreateHTTPClientWithProxy returns a new HTTP client like CreateHTTPClient
+// which sends the requests through the given proxy, a nil proxy means the global one
+func CreateHTTPClientWithProxy(timeout time.Duration, unsafeSsl bool, proxy *url.URL) *http.Client {
 	// default the timeout to 300ms
 	if timeout <= 0 {
 		timeout = 300 * time.Millisecond
 	}
-	transport := CreateHTTPTransport(unsafeSsl)
+	transport := CreateHTTPTransportWithProxy(CreateTLSClientConfig(unsafeSsl), proxy)
 	httpClient := &http.Client{
 		Timeout:   timeout,
-		Transport: transport,
+		Transport: &responseMetricsRoundTripper{next: transport},
 	}
 	return httpClient
 }
 
+type triggerTypeContextKey struct{}
+
+// ContextWithTriggerType returns a copy of the context carrying the type of the trigger
+// the HTTP requests are made for, it's used to label the HTTP response metrics
+func ContextWithTriggerType(ctx context.Context, triggerType string) context.Context {
+	return context.WithValue(ctx, triggerTypeContextKey{}, triggerType)
+}
+
+// HTTPMetricsRecorder records the metrics of the HTTP requests made by the clients of CreateHTTPClient, by the
+// trigger type of the request context
+type HTTPMetricsRecorder interface {
+	RecordHTTPResponse(triggerType string, statusCode int)
+	RecordResponseBytes(triggerType string, size int64)
+	RecordConnectDuration(triggerType string, duration time.Duration)
+	RecordQueryDuration(triggerType string, duration time.Duration)
+}
+
+// httpMetricsRecorderHolder holds the recorder, atomic.Value requires the same concrete type for every store
+type httpMetricsRecorderHolder struct {
+	recorder HTTPMetricsRecorder
+}
+
+var httpMetricsRecorder atomic.Value
+
+// SetHTTPMetricsRecorder sets the recorder of the HTTP request metrics, the metrics aren't recorded until it's set
+func SetHTTPMetricsRecorder(recorder HTTPMetricsRecorder) {
+	httpMetricsRecorder.Store(httpMetricsRecorderHolder{recorder: recorder})
+}
+
+func getHTTPMetricsRecorder() HTTPMetricsRecorder {
+	holder, _ := httpMetricsRecorder.Load().(httpMetricsRecorderHolder)
+	return holder.recorder
+}
+
+// responseMetricsRoundTripper counts the status codes of the responses by the trigger type of the request context,
+// observes the size of the response payloads read by the scalers and splits the time of the requests between the
+// connection setup and the query
+type responseMetricsRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt *responseMetricsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := getHTTPMetricsRecorder()
+	if recorder == nil {
+		return rt.next.RoundTrip(req)
+	}
+
+	triggerType, ok := req.Context().Value(triggerTypeContextKey{}).(string)
+	if !ok || triggerType == "" {
+		triggerType = "unknown"
+	}
+	timings := &requestTimings{recorder: recorder, triggerType: triggerType}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timings.clientTrace()))
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	recorder.RecordHTTPResponse(triggerType, resp.StatusCode)
+	if resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = &responseSizeBody{ReadCloser: resp.Body, recorder: recorder, triggerType: triggerType}
+	}
+	return resp, nil
+}
+
+// requestTimings observes the connection setup time of the requests getting a new connection, and the time from
+// getting the connection to the first response byte of all the requests
+type requestTimings struct {
+	recorder    HTTPMetricsRecorder
+	triggerType string
+
+	lock    sync.Mutex
+	getConn time.Time
+	gotConn time.Time
+}
+
+func (r *requestTimings) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			r.getConn = time.Now()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			r.gotConn = time.Now()
+			if !info.Reused && !r.getConn.IsZero() {
+				r.recorder.RecordConnectDuration(r.triggerType, r.gotConn.Sub(r.getConn))
+			}
+		},
+		GotFirstResponseByte: func() {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			if r.gotConn.IsZero() {
+				return
+			}
+			r.recorder.RecordQueryDuration(r.triggerType, time.Since(r.gotConn))
+		},
+	}
+}
+
+// responseSizeBody counts the bytes of the response body read by the scaler, the size is observed
+// once when the body is read to the end or closed, bodies closed without being read aren't observed
+type responseSizeBody struct {
+	io.ReadCloser
+	recorder    HTTPMetricsRecorder
+	triggerType string
+	size        int64
+	observed    sync.Once
+}
+
+func (b *responseSizeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if err == io.EOF {
+		b.observe()
+	}
+	return n, err
+}
+
+func (b *responseSizeBody) Close() error {
+	b.observe()
+	return b.ReadCloser.Close()
+}
+
+func (b *responseSizeBody) observe() {
+	b.observed.Do(func() {
+		if b.size > 0 {
+			b.recorder.RecordResponseBytes(b.triggerType, b.size)
+		}
+	})
+}
+
 // CreateHTTPTransport returns a new HTTP Transport with Proxy, Keep alives
 // unsafeSsl parameter allows to avoid tls cert validation if it's required
 func CreateHTTPTransport(unsafeSsl bool) *http.Transport {
@@ -67,9 +213,15 @@ func CreateHTTPTransport(unsafeSsl bool) *http.Transport {
 // CreateHTTPTransportWithTLSConfig returns a new HTTP Transport with Proxy, Keep alives
 // using given tls.Config
 func CreateHTTPTransportWithTLSConfig(config *tls.Config) *http.Transport {
+	return CreateHTTPTransportWithProxy(config, nil)
+}
+
+// CreateHTTPTransportWithProxy returns a new HTTP Transport with Keep alives
+// using given tls.Config and proxy, a nil proxy means the global one
+func CreateHTTPTransportWithProxy(config *tls.Config, proxy *url.URL) *http.Transport {
 	transport := &http.Transport{
 		TLSClientConfig: config,
-		Proxy:           http.ProxyFromEnvironment,
+		Proxy:           ProxyFunc(proxy),
 	}
 	if disableKeepAlives {
 		// disable keep http connection alive
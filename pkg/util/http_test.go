@@ -17,6 +17,12 @@ limitations under the License.
 package util
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -34,3 +40,172 @@ func TestCreateHTTPClientWhenValidTimeout(t *testing.T) {
 
 	assert.Equal(t, 1*time.Minute, client.Timeout)
 }
+
+// testHTTPMetricsRecorder keeps the HTTP request metrics recorded for each trigger type
+type testHTTPMetricsRecorder struct {
+	lock             sync.Mutex
+	statusCodes      map[string][]int
+	responseBytes    map[string][]int64
+	connectDurations map[string][]time.Duration
+	queryDurations   map[string][]time.Duration
+}
+
+func newTestHTTPMetricsRecorder(t *testing.T) *testHTTPMetricsRecorder {
+	recorder := &testHTTPMetricsRecorder{
+		statusCodes:      map[string][]int{},
+		responseBytes:    map[string][]int64{},
+		connectDurations: map[string][]time.Duration{},
+		queryDurations:   map[string][]time.Duration{},
+	}
+	SetHTTPMetricsRecorder(recorder)
+	t.Cleanup(func() { SetHTTPMetricsRecorder(nil) })
+	return recorder
+}
+
+func (r *testHTTPMetricsRecorder) RecordHTTPResponse(triggerType string, statusCode int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.statusCodes[triggerType] = append(r.statusCodes[triggerType], statusCode)
+}
+
+func (r *testHTTPMetricsRecorder) RecordResponseBytes(triggerType string, size int64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.responseBytes[triggerType] = append(r.responseBytes[triggerType], size)
+}
+
+func (r *testHTTPMetricsRecorder) RecordConnectDuration(triggerType string, duration time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.connectDurations[triggerType] = append(r.connectDurations[triggerType], duration)
+}
+
+func (r *testHTTPMetricsRecorder) RecordQueryDuration(triggerType string, duration time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.queryDurations[triggerType] = append(r.queryDurations[triggerType], duration)
+}
+
+func TestCreateHTTPClientRecordsResponseStatusCodes(t *testing.T) {
+	recorder := newTestHTTPMetricsRecorder(t)
+	statusCodes := []int{http.StatusOK, http.StatusNoContent, http.StatusNotFound, http.StatusServiceUnavailable}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCodes[requests])
+		requests++
+	}))
+	defer server.Close()
+
+	client := CreateHTTPClient(time.Second, false)
+	ctx := ContextWithTriggerType(context.Background(), "prometheus")
+	for range statusCodes {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, statusCodes, recorder.statusCodes["prometheus"])
+}
+
+func TestCreateHTTPClientRecordsUnknownTriggerType(t *testing.T) {
+	recorder := newTestHTTPMetricsRecorder(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := CreateHTTPClient(time.Second, false).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []int{http.StatusOK}, recorder.statusCodes["unknown"])
+}
+
+func TestCreateHTTPClientRecordsResponseBytes(t *testing.T) {
+	recorder := newTestHTTPMetricsRecorder(t)
+	payloadSizes := []int{100, 2000, 50000}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", payloadSizes[requests])))
+		requests++
+	}))
+	defer server.Close()
+
+	client := CreateHTTPClient(time.Second, false)
+	ctx := ContextWithTriggerType(context.Background(), "metrics-api")
+	for range payloadSizes {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Len(t, body, payloadSizes[requests-1])
+	}
+
+	assert.Equal(t, []int64{100, 2000, 50000}, recorder.responseBytes["metrics-api"])
+}
+
+func TestCreateHTTPClientSkipsUnreadResponseBytes(t *testing.T) {
+	recorder := newTestHTTPMetricsRecorder(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("unread payload"))
+	}))
+	defer server.Close()
+
+	client := CreateHTTPClient(time.Second, false)
+	ctx := ContextWithTriggerType(context.Background(), "metrics-api")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Empty(t, recorder.responseBytes["metrics-api"])
+}
+
+func TestCreateHTTPClientRecordsConnectAndQueryDurations(t *testing.T) {
+	recorder := newTestHTTPMetricsRecorder(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte("slow backend"))
+	}))
+	defer server.Close()
+
+	client := CreateHTTPClient(time.Second, true)
+	ctx := ContextWithTriggerType(context.Background(), "elasticsearch")
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		_, err = io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// the second request reuses the connection of the first one
+	assert.Len(t, recorder.connectDurations["elasticsearch"], 1)
+	assert.Greater(t, recorder.connectDurations["elasticsearch"][0], time.Duration(0))
+	assert.Len(t, recorder.queryDurations["elasticsearch"], 2)
+	for _, duration := range recorder.queryDurations["elasticsearch"] {
+		assert.GreaterOrEqual(t, duration, 50*time.Millisecond)
+	}
+}
+
+func TestCreateHTTPClientWithoutRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer server.Close()
+
+	resp, err := CreateHTTPClient(time.Second, false).Get(server.URL)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "payload", string(body))
+}
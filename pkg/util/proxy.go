@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// inClusterNoProxy lists the in-cluster addresses which are never sent through
+// the scalers proxy, NO_PROXY env entries are added on top of them
+const inClusterNoProxy = ".svc,.cluster.local,localhost,127.0.0.1,::1"
+
+var globalHTTPProxy *url.URL
+
+// SetGlobalHTTPProxy sets the proxy used by all the scalers which don't
+// define their own proxy, an empty value keeps the proxy from environment
+func SetGlobalHTTPProxy(proxy string) error {
+	if proxy == "" {
+		globalHTTPProxy = nil
+		return nil
+	}
+	proxyURL, err := ParseProxyURL(proxy)
+	if err != nil {
+		return err
+	}
+	globalHTTPProxy = proxyURL
+	return nil
+}
+
+// ParseProxyURL parses and validates a proxy address
+func ParseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing proxy %s: %w", proxy, err)
+	}
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported proxy scheme %q, only http and https are supported", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy %s doesn't contain a host", proxy)
+	}
+	return proxyURL, nil
+}
+
+// ProxyFunc returns the proxy selection function for the given proxy,
+// falling back to the global proxy and then to the proxy from environment.
+// In-cluster addresses and NO_PROXY entries bypass the proxy
+func ProxyFunc(proxy *url.URL) func(*http.Request) (*url.URL, error) {
+	if proxy == nil {
+		proxy = globalHTTPProxy
+	}
+	if proxy == nil {
+		return http.ProxyFromEnvironment
+	}
+
+	config := &httpproxy.Config{
+		HTTPProxy:  proxy.String(),
+		HTTPSProxy: proxy.String(),
+		NoProxy:    getNoProxy(),
+	}
+	proxyFunc := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+func getNoProxy() string {
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+	if noProxy == "" {
+		return inClusterNoProxy
+	}
+	return inClusterNoProxy + "," + noProxy
+}
+
+// ProxyDialer returns a dialer which tunnels the connections through the proxy
+// using HTTP CONNECT, it's meant to be used for gRPC connections. It returns nil
+// if neither the given proxy nor the global proxy is set, so the default dialer
+// (which honors the proxy from environment) can be kept
+func ProxyDialer(proxy *url.URL) func(context.Context, string) (net.Conn, error) {
+	if proxy == nil && globalHTTPProxy == nil {
+		return nil
+	}
+	proxyFunc := ProxyFunc(proxy)
+	dialer := &net.Dialer{}
+
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		proxyURL, err := proxyFunc(&http.Request{URL: &url.URL{Scheme: "https", Host: addr}})
+		if err != nil {
+			return nil, err
+		}
+		if proxyURL == nil {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}
+
+		conn, err := dialer.DialContext(ctx, "tcp", canonicalProxyAddr(proxyURL))
+		if err != nil {
+			return nil, fmt.Errorf("error dialing proxy %s: %w", proxyURL.Host, err)
+		}
+		if proxyURL.Scheme == "https" {
+			conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname(), MinVersion: tls.VersionTLS12})
+		}
+		tunnel, err := doProxyConnect(ctx, conn, proxyURL, addr)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tunnel, nil
+	}
+}
+
+func canonicalProxyAddr(proxyURL *url.URL) string {
+	if proxyURL.Port() != "" {
+		return proxyURL.Host
+	}
+	if proxyURL.Scheme == "https" {
+		return net.JoinHostPort(proxyURL.Hostname(), "443")
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), "80")
+}
+
+// bufferedConn keeps the bytes the proxy may have sent right after the CONNECT response
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func doProxyConnect(ctx context.Context, conn net.Conn, proxyURL *url.URL, addr string) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+		defer conn.SetDeadline(time.Time{}) //nolint:errcheck
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("error sending CONNECT request to proxy %s: %w", proxyURL.Host, err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CONNECT response from proxy %s: %w", proxyURL.Host, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy %s refused CONNECT to %s: %s", proxyURL.Host, addr, strings.TrimSpace(resp.Status))
+	}
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingProxy answers proxied HTTP requests itself and tunnels
+// CONNECT requests to upstream, recording the requested hosts
+type recordingProxy struct {
+	server   *httptest.Server
+	upstream string
+
+	mu    sync.Mutex
+	hosts []string
+	auth  []string
+}
+
+func newRecordingProxy(t *testing.T, upstream string) *recordingProxy {
+	p := &recordingProxy{upstream: upstream}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.hosts = append(p.hosts, r.Host)
+		p.auth = append(p.auth, r.Header.Get("Proxy-Authorization"))
+		p.mu.Unlock()
+
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		upstreamConn, err := net.Dial("tcp", p.upstream)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() {
+			_, _ = io.Copy(upstreamConn, conn)
+			upstreamConn.Close()
+		}()
+		go func() {
+			_, _ = io.Copy(conn, upstreamConn)
+			conn.Close()
+		}()
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *recordingProxy) url(t *testing.T) *url.URL {
+	proxyURL, err := url.Parse(p.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proxyURL
+}
+
+func (p *recordingProxy) recordedHosts() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string{}, p.hosts...)
+}
+
+func TestParseProxyURL(t *testing.T) {
+	_, err := ParseProxyURL("http://proxy.example.com:3128")
+	assert.NoError(t, err)
+
+	_, err = ParseProxyURL("socks5://proxy.example.com:1080")
+	assert.Error(t, err)
+
+	_, err = ParseProxyURL("http://")
+	assert.Error(t, err)
+
+	_, err = ParseProxyURL("://proxy")
+	assert.Error(t, err)
+}
+
+func TestCreateHTTPClientWithProxy(t *testing.T) {
+	proxy := newRecordingProxy(t, "")
+
+	client := CreateHTTPClientWithProxy(time.Second, false, proxy.url(t))
+	resp, err := client.Get("http://scaler.example.com/metrics")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"scaler.example.com"}, proxy.recordedHosts())
+}
+
+func TestGlobalHTTPProxyIsOverriddenByTriggerProxy(t *testing.T) {
+	globalProxy := newRecordingProxy(t, "")
+	triggerProxy := newRecordingProxy(t, "")
+
+	assert.NoError(t, SetGlobalHTTPProxy(globalProxy.server.URL))
+	defer func() { assert.NoError(t, SetGlobalHTTPProxy("")) }()
+
+	resp, err := CreateHTTPClient(time.Second, false).Get("http://global.example.com/")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = CreateHTTPClientWithProxy(time.Second, false, triggerProxy.url(t)).Get("http://trigger.example.com/")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"global.example.com"}, globalProxy.recordedHosts())
+	assert.Equal(t, []string{"trigger.example.com"}, triggerProxy.recordedHosts())
+}
+
+func TestProxyFuncBypassesInClusterAddresses(t *testing.T) {
+	t.Setenv("NO_PROXY", "internal.example.com,10.0.0.0/8")
+	proxyURL, _ := url.Parse("http://proxy.example.com:3128")
+	proxyFunc := ProxyFunc(proxyURL)
+
+	cases := map[string]bool{
+		"http://prometheus.monitoring.svc:9090":               false,
+		"http://prometheus.monitoring.svc.cluster.local:9090": false,
+		"https://api.internal.example.com":                    false,
+		"http://10.1.2.3:8080":                                false,
+		"http://localhost:8080":                               false,
+		"https://api.example.com":                             true,
+	}
+	for target, proxied := range cases {
+		targetURL, _ := url.Parse(target)
+		got, err := proxyFunc(&http.Request{URL: targetURL})
+		assert.NoError(t, err)
+		if proxied {
+			assert.Equal(t, proxyURL.String(), got.String(), target)
+		} else {
+			assert.Nil(t, got, target)
+		}
+	}
+}
+
+func TestProxyDialer(t *testing.T) {
+	assert.Nil(t, ProxyDialer(nil))
+
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer upstream.Close()
+	go func() {
+		conn, err := upstream.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		_, _ = conn.Write([]byte("echo: " + line))
+	}()
+
+	proxy := newRecordingProxy(t, upstream.Addr().String())
+	proxyURL := proxy.url(t)
+	proxyURL.User = url.UserPassword("user", "pass")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := ProxyDialer(proxyURL)(ctx, "external-scaler.example.com:6000")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping\n"))
+	assert.NoError(t, err)
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: ping\n", reply)
+
+	assert.Equal(t, []string{"external-scaler.example.com:6000"}, proxy.recordedHosts())
+	assert.Equal(t, []string{"Basic dXNlcjpwYXNz"}, proxy.auth)
+}
+
+func TestProxyDialerBypassesInClusterAddresses(t *testing.T) {
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer upstream.Close()
+	go func() {
+		if conn, err := upstream.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	proxy := newRecordingProxy(t, "")
+	conn, err := ProxyDialer(proxy.url(t))(context.Background(), upstream.Addr().String())
+	assert.NoError(t, err)
+	conn.Close()
+
+	assert.Empty(t, proxy.recordedHosts())
+}
@@ -17,11 +17,14 @@ limitations under the License.
 package util
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/youmark/pkcs8"
@@ -81,6 +84,59 @@ func CreateTLSClientConfig(unsafeSsl bool) *tls.Config {
 	}
 }
 
+// PinServerPublicKey makes the TLS config skip the verification of the certificate chain of the server and require
+// the public key of its leaf certificate to match one of the pins instead. The pins are comma separated base64
+// encoded SHA-256 hashes of SubjectPublicKeyInfo, so the new key can be pinned along the old one while it's rotated.
+// Empty pins leave the config unchanged.
+func PinServerPublicKey(config *tls.Config, pins string) error {
+	if pins == "" {
+		return nil
+	}
+	pinned, err := parseServerPublicKeyPins(pins)
+	if err != nil {
+		return err
+	}
+
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("server public key pin mismatch: the server presented no certificate")
+		}
+		observed := GetServerPublicKeyPin(state.PeerCertificates[0])
+		if !pinned[observed] {
+			return fmt.Errorf("server public key pin mismatch: expected one of %s, observed %s", pins, observed)
+		}
+		return nil
+	}
+	return nil
+}
+
+// ValidateServerPublicKeyPin returns an error when the comma separated pins aren't base64 encoded SHA-256 hashes
+func ValidateServerPublicKeyPin(pins string) error {
+	_, err := parseServerPublicKeyPins(pins)
+	return err
+}
+
+// GetServerPublicKeyPin returns the pin of the public key of the certificate, the base64 encoded SHA-256 hash of its
+// SubjectPublicKeyInfo
+func GetServerPublicKeyPin(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func parseServerPublicKeyPins(pins string) (map[string]bool, error) {
+	pinned := map[string]bool{}
+	for _, pin := range strings.Split(pins, ",") {
+		pin = strings.TrimSpace(pin)
+		hash, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid tlsServerPublicKeyPin %q, expected the base64 encoded SHA-256 hash of a SubjectPublicKeyInfo", pin)
+		}
+		pinned[pin] = true
+	}
+	return pinned, nil
+}
+
 // GetMinTLSVersion return the minTLSVersion based on configurations
 func GetMinTLSVersion() uint16 {
 	return minTLSVersion
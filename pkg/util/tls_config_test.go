@@ -17,13 +17,26 @@ limitations under the License.
 package util
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var randomCACert = `-----BEGIN CERTIFICATE-----
@@ -302,3 +315,84 @@ func TestResolveMinTLSVersion(t *testing.T) {
 		}
 	}
 }
+
+// newTestPinnedServer starts a TLS server with a self-signed certificate of a new key, not trusted by the clients, and
+// returns it with the pin of the key
+func newTestPinnedServer(t *testing.T) (*httptest.Server, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "internal.keda.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	// the handshakes failing on purpose aren't logged
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, GetServerPublicKeyPin(cert)
+}
+
+func getWithPin(t *testing.T, url string, pins string) error {
+	config, err := NewTLSConfig("", "", "", false)
+	require.NoError(t, err)
+	require.NoError(t, PinServerPublicKey(config, pins))
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}, Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestPinServerPublicKey(t *testing.T) {
+	server, pin := newTestPinnedServer(t)
+	otherServer, otherPin := newTestPinnedServer(t)
+
+	// the chain isn't trusted without the pin
+	config, err := NewTLSConfig("", "", "", false)
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: &http.Transport{TLSClientConfig: config}}).Get(server.URL)
+	assert.Error(t, err)
+
+	// match
+	assert.NoError(t, getWithPin(t, server.URL, pin))
+
+	// mismatch, the error has the observed pin
+	err = getWithPin(t, otherServer.URL, pin)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server public key pin mismatch")
+	assert.Contains(t, err.Error(), otherPin)
+
+	// rotation, both keys are accepted while they're pinned together
+	assert.NoError(t, getWithPin(t, server.URL, pin+","+otherPin))
+	assert.NoError(t, getWithPin(t, otherServer.URL, pin+", "+otherPin))
+	// and the old one is rejected once it's unpinned
+	assert.Error(t, getWithPin(t, server.URL, otherPin))
+}
+
+func TestPinServerPublicKeyInvalid(t *testing.T) {
+	for _, pins := range []string{"not-base64!", "c2hvcnQ=", "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=,"} {
+		config := CreateTLSClientConfig(false)
+		assert.Error(t, PinServerPublicKey(config, pins), pins)
+		assert.False(t, config.InsecureSkipVerify, pins)
+		assert.Error(t, ValidateServerPublicKeyPin(pins), pins)
+	}
+
+	// empty pins leave the config unchanged
+	config := CreateTLSClientConfig(false)
+	require.NoError(t, PinServerPublicKey(config, ""))
+	assert.False(t, config.InsecureSkipVerify)
+	assert.Nil(t, config.VerifyConnection)
+}
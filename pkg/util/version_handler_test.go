@@ -0,0 +1,48 @@
+/*
+Copyright 2023 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kedacore/keda/v2/pkg/prommetrics"
+)
+
+func TestVersionHandler(t *testing.T) {
+	handler := NewVersionHandler(prommetrics.RuntimeInfo{
+		Version:           "2.10.0",
+		GitCommit:         "abc123",
+		GoVersion:         "go1.20",
+		CertRotation:      true,
+		KubernetesVersion: "1.26",
+		AutoscalingAPI:    "autoscaling/v2",
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"version":"2.10.0","gitCommit":"abc123","goVersion":"go1.20","certRotation":true,"scaledObjectGeneration":false,"kubernetesVersion":"1.26","autoscalingAPI":"autoscaling/v2"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/version", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,260 @@
+//go:build e2e
+// +build e2e
+
+package hazelcast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+
+	. "github.com/kedacore/keda/v2/tests/helper"
+)
+
+// Load environment variables from .env file
+var _ = godotenv.Load("../../.env")
+
+const (
+	testName = "hazelcast-test"
+)
+
+var (
+	testNamespace    = fmt.Sprintf("%s-ns", testName)
+	deploymentName   = fmt.Sprintf("%s-deployment", testName)
+	scaledObjectName = fmt.Sprintf("%s-so", testName)
+	queueName        = "keda-queue"
+	maxReplicaCount  = 4
+	minReplicaCount  = 0
+)
+
+type templateData struct {
+	TestNamespace    string
+	DeploymentName   string
+	ScaledObjectName string
+	QueueName        string
+	JobName          string
+	ItemsToWrite     int
+	MinReplicaCount  int
+	MaxReplicaCount  int
+}
+
+const (
+	hazelcastDeploymentTemplate = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hazelcast
+  namespace: {{.TestNamespace}}
+  labels:
+    app: hazelcast
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: hazelcast
+  template:
+    metadata:
+      labels:
+        app: hazelcast
+    spec:
+      containers:
+      - name: hazelcast
+        image: hazelcast/hazelcast:5.3.1
+        env:
+        - name: HZ_CLUSTERNAME
+          value: dev
+        - name: HZ_NETWORK_RESTAPI_ENABLED
+          value: "true"
+        - name: HZ_NETWORK_RESTAPI_ENDPOINTGROUPS_DATA_ENABLED
+          value: "true"
+        - name: HZ_NETWORK_RESTAPI_ENDPOINTGROUPS_HEALTHCHECK_ENABLED
+          value: "true"
+        ports:
+        - containerPort: 5701
+        readinessProbe:
+          httpGet:
+            path: /hazelcast/health/ready
+            port: 5701
+`
+
+	hazelcastServiceTemplate = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: hazelcast
+  namespace: {{.TestNamespace}}
+spec:
+  ports:
+  - port: 5701
+    targetPort: 5701
+  selector:
+    app: hazelcast
+`
+
+	deploymentTemplate = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.DeploymentName}}
+  namespace: {{.TestNamespace}}
+  labels:
+    app: {{.DeploymentName}}
+spec:
+  replicas: 0
+  selector:
+    matchLabels:
+      app: {{.DeploymentName}}
+  template:
+    metadata:
+      labels:
+        app: {{.DeploymentName}}
+    spec:
+      containers:
+      - name: nginx
+        image: nginxinc/nginx-unprivileged
+        ports:
+        - containerPort: 80
+`
+
+	scaledObjectTemplate = `
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: {{.ScaledObjectName}}
+  namespace: {{.TestNamespace}}
+spec:
+  scaleTargetRef:
+    name: {{.DeploymentName}}
+  pollingInterval: 5
+  cooldownPeriod: 10
+  minReplicaCount: {{.MinReplicaCount}}
+  maxReplicaCount: {{.MaxReplicaCount}}
+  triggers:
+  - type: hazelcast
+    metadata:
+      addresses: hazelcast.{{.TestNamespace}}.svc.cluster.local:5701
+      structureType: queue
+      structureName: {{.QueueName}}
+      targetValue: "10"
+      activationTargetValue: "10"
+`
+
+	// the items are offered through the REST API of the member, the scaler reads the size with the client protocol
+	offerItemsJobTemplate = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{.JobName}}
+  namespace: {{.TestNamespace}}
+spec:
+  template:
+    spec:
+      containers:
+      - name: offer
+        image: curlimages/curl
+        command:
+        - sh
+        - -c
+        - |
+          for i in $(seq 1 {{.ItemsToWrite}}); do curl -sf -X POST -H "Content-Type: text/plain" -d "item-$i" http://hazelcast.{{.TestNamespace}}.svc.cluster.local:5701/hazelcast/rest/queues/{{.QueueName}} || exit 1; done
+      restartPolicy: Never
+  backoffLimit: 4
+`
+
+	// the REST API polls an item with DELETE and answers 204 once the queue is empty
+	pollItemsJobTemplate = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{.JobName}}
+  namespace: {{.TestNamespace}}
+spec:
+  template:
+    spec:
+      containers:
+      - name: poll
+        image: curlimages/curl
+        command:
+        - sh
+        - -c
+        - |
+          while [ "$(curl -s -o /dev/null -w '%{http_code}' -X DELETE http://hazelcast.{{.TestNamespace}}.svc.cluster.local:5701/hazelcast/rest/queues/{{.QueueName}}/1)" = "200" ]; do :; done
+      restartPolicy: Never
+  backoffLimit: 4
+`
+)
+
+func TestHazelcastScaler(t *testing.T) {
+	// setup
+	t.Log("--- setting up ---")
+	kc := GetKubernetesClient(t)
+	data, templates := getTemplateData()
+	CreateNamespace(t, kc, testNamespace)
+
+	KubectlApplyWithTemplate(t, data, "hazelcastDeploymentTemplate", hazelcastDeploymentTemplate)
+	KubectlApplyWithTemplate(t, data, "hazelcastServiceTemplate", hazelcastServiceTemplate)
+	assert.True(t, WaitForDeploymentReplicaReadyCount(t, kc, "hazelcast", testNamespace, 1, 60, 3),
+		"hazelcast should be ready after 3 minutes")
+
+	KubectlApplyMultipleWithTemplate(t, data, templates)
+	assert.True(t, WaitForDeploymentReplicaReadyCount(t, kc, deploymentName, testNamespace, minReplicaCount, 60, 1),
+		"replica count should be %d after 1 minute", minReplicaCount)
+
+	// test scaling
+	testActivation(t, kc, data)
+	testScaleOut(t, kc, data)
+	testScaleIn(t, kc, data)
+
+	// cleanup
+	KubectlDeleteWithTemplate(t, data, "hazelcastServiceTemplate", hazelcastServiceTemplate)
+	KubectlDeleteWithTemplate(t, data, "hazelcastDeploymentTemplate", hazelcastDeploymentTemplate)
+	DeleteKubernetesResources(t, testNamespace, data, templates)
+}
+
+func testActivation(t *testing.T, kc *kubernetes.Clientset, data templateData) {
+	t.Log("--- testing activation ---")
+	data.JobName = "offer-activation"
+	data.ItemsToWrite = 5
+	KubectlApplyWithTemplate(t, data, "offerItemsJobTemplate", offerItemsJobTemplate)
+	assert.True(t, WaitForJobSuccess(t, kc, data.JobName, testNamespace, 30, 2), "the items should be offered")
+
+	AssertReplicaCountNotChangeDuringTimePeriod(t, kc, deploymentName, testNamespace, minReplicaCount, 60)
+}
+
+func testScaleOut(t *testing.T, kc *kubernetes.Clientset, data templateData) {
+	t.Log("--- testing scale out ---")
+	data.JobName = "offer-scale-out"
+	data.ItemsToWrite = 50
+	KubectlApplyWithTemplate(t, data, "offerItemsJobTemplate", offerItemsJobTemplate)
+	assert.True(t, WaitForJobSuccess(t, kc, data.JobName, testNamespace, 30, 2), "the items should be offered")
+
+	assert.True(t, WaitForDeploymentReplicaReadyCount(t, kc, deploymentName, testNamespace, maxReplicaCount, 60, 3),
+		"replica count should be %d after 3 minutes", maxReplicaCount)
+}
+
+func testScaleIn(t *testing.T, kc *kubernetes.Clientset, data templateData) {
+	t.Log("--- testing scale in ---")
+	data.JobName = "poll-scale-in"
+	KubectlApplyWithTemplate(t, data, "pollItemsJobTemplate", pollItemsJobTemplate)
+	assert.True(t, WaitForJobSuccess(t, kc, data.JobName, testNamespace, 30, 2), "the items should be polled")
+
+	assert.True(t, WaitForDeploymentReplicaReadyCount(t, kc, deploymentName, testNamespace, minReplicaCount, 60, 3),
+		"replica count should be %d after 3 minutes", minReplicaCount)
+}
+
+func getTemplateData() (templateData, []Template) {
+	return templateData{
+		TestNamespace:    testNamespace,
+		DeploymentName:   deploymentName,
+		ScaledObjectName: scaledObjectName,
+		QueueName:        queueName,
+		MinReplicaCount:  minReplicaCount,
+		MaxReplicaCount:  maxReplicaCount,
+	}, []Template{
+		{Name: "deploymentTemplate", Config: deploymentTemplate},
+		{Name: "scaledObjectTemplate", Config: scaledObjectTemplate},
+	}
+}
@@ -430,15 +430,18 @@ func getErrorMetricsValue(val *prommodel.MetricFamily) float64 {
 			}
 		}
 	case "keda_scaler_errors":
+		// the errors of the scaler are split by errorType
+		var total float64
 		metrics := val.GetMetric()
 		for _, metric := range metrics {
 			labels := metric.GetLabel()
 			for _, label := range labels {
 				if *label.Name == "scaler" && *label.Value == wrongScalerName {
-					return *metric.Counter.Value
+					total += *metric.Counter.Value
 				}
 			}
 		}
+		return total
 	}
 	return 0
 }